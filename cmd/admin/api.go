@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+)
+
+// apiTimeout is how long a request to the API may take
+const apiTimeout = 30 * time.Second
+
+// errNeedsStores is returned by the operations the API doesn't offer
+var errNeedsStores = errors.New("only available on the database; run without --api")
+
+// apiBackend works through the HTTP API at baseURL, as the tenant and user of its token
+type apiBackend struct {
+	baseURL string
+	token   string
+	userID  string
+	tenant  string
+	client  *http.Client
+}
+
+// newAPIBackend creates a backend calling the API served at baseURL, such as
+// https://periodic.example.com. Requests carry token as their bearer token and userID as
+// X-User-ID when they are set; tenant names the token's tenant for the admin endpoints.
+func newAPIBackend(baseURL, token, userID, tenant string) *apiBackend {
+	return &apiBackend{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		token:   token,
+		userID:  userID,
+		tenant:  tenant,
+		client:  &http.Client{Timeout: apiTimeout},
+	}
+}
+
+// do sends a request with body encoded as JSON, when it isn't nil, and decodes the
+// response into result, when it isn't nil. Problem responses become errors.
+func (b *apiBackend) do(ctx context.Context, method, path, contentType string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	if b.userID != "" {
+		req.Header.Set("X-User-ID", b.userID)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var details problem.Details
+		if err := json.NewDecoder(resp.Body).Decode(&details); err != nil || details.Title == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		message := details.Title
+		if details.Detail != "" {
+			message = details.Detail
+		}
+		for _, fieldErr := range details.Errors {
+			message += fmt.Sprintf("; %s %s", fieldErr.Field, fieldErr.Message)
+		}
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, message)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// ListItems gets every scheduled item the token's user can see
+func (b *apiBackend) ListItems(ctx context.Context) ([]models.ScheduledItem, error) {
+	var items []models.ScheduledItem
+	err := b.do(ctx, http.MethodGet, "/scheduled-items", "", nil, &items)
+	return items, err
+}
+
+// CreateItem creates a scheduled item, which the API validates
+func (b *apiBackend) CreateItem(ctx context.Context, item models.ScheduledItem) (models.ScheduledItem, error) {
+	var created models.ScheduledItem
+	err := b.do(ctx, http.MethodPost, "/scheduled-items", "application/json", item, &created)
+	return created, err
+}
+
+// DeleteItem deletes a scheduled item
+func (b *apiBackend) DeleteItem(ctx context.Context, id int64) error {
+	return b.do(ctx, http.MethodDelete, fmt.Sprintf("/scheduled-items/%d", id), "", nil, nil)
+}
+
+// ListExecutionLogs gets the execution logs and keeps the newest, since the API doesn't
+// filter them
+func (b *apiBackend) ListExecutionLogs(ctx context.Context, itemID int64, limit int) ([]models.ExecutionLog, error) {
+	var logs []models.ExecutionLog
+	if err := b.do(ctx, http.MethodGet, "/execution-logs", "", nil, &logs); err != nil {
+		return nil, err
+	}
+	return newestExecutionLogs(logs, itemID, limit), nil
+}
+
+// ListTodos gets every todo item
+func (b *apiBackend) ListTodos(ctx context.Context) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	err := b.do(ctx, http.MethodGet, "/todo-items", "", nil, &todos)
+	return todos, err
+}
+
+// SetTodoChecked checks or unchecks a todo item with a merge patch
+func (b *apiBackend) SetTodoChecked(ctx context.Context, id int64, checked bool) (models.TodoItem, error) {
+	var todo models.TodoItem
+	err := b.do(ctx, http.MethodPatch, fmt.Sprintf("/todo-items/%d", id), "application/merge-patch+json", map[string]bool{"checked": checked}, &todo)
+	return todo, err
+}
+
+// ListUsers gets the users of the token's tenant
+func (b *apiBackend) ListUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := b.do(ctx, http.MethodGet, "/users", "", nil, &users)
+	return users, err
+}
+
+// CreateUser creates a user
+func (b *apiBackend) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	var created models.User
+	err := b.do(ctx, http.MethodPost, "/users", "application/json", user, &created)
+	return created, err
+}
+
+// DeleteUser deletes a user
+func (b *apiBackend) DeleteUser(ctx context.Context, id int64) error {
+	return b.do(ctx, http.MethodDelete, fmt.Sprintf("/users/%d", id), "", nil, nil)
+}
+
+// SetUserDeactivated deactivates or reactivates a user of the tenant through the admin
+// endpoints, which need a token with the admin claim when tokens are checked
+func (b *apiBackend) SetUserDeactivated(ctx context.Context, id int64, deactivated bool) (models.User, error) {
+	action := "reactivate"
+	if deactivated {
+		action = "deactivate"
+	}
+	var user models.User
+	err := b.do(ctx, http.MethodPost, fmt.Sprintf("/admin/tenants/%s/users/%d/%s", url.PathEscape(b.tenant), id, action), "", nil, &user)
+	return user, err
+}
+
+// SetUserPassword fails: the API never sets password hashes
+func (b *apiBackend) SetUserPassword(ctx context.Context, id int64, hash []byte) (models.User, error) {
+	return models.User{}, errNeedsStores
+}
+
+// Close does nothing; the API client holds nothing open
+func (b *apiBackend) Close() error {
+	return nil
+}
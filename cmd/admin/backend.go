@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+)
+
+// backend is what the commands operate on: the stores directly, or the HTTP API
+type backend interface {
+	ListItems(ctx context.Context) ([]models.ScheduledItem, error)
+	CreateItem(ctx context.Context, item models.ScheduledItem) (models.ScheduledItem, error)
+	DeleteItem(ctx context.Context, id int64) error
+	// ListExecutionLogs returns the newest execution logs, of one scheduled item when
+	// itemID isn't 0
+	ListExecutionLogs(ctx context.Context, itemID int64, limit int) ([]models.ExecutionLog, error)
+	ListTodos(ctx context.Context) ([]models.TodoItem, error)
+	SetTodoChecked(ctx context.Context, id int64, checked bool) (models.TodoItem, error)
+	ListUsers(ctx context.Context) ([]models.User, error)
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	DeleteUser(ctx context.Context, id int64) error
+	SetUserDeactivated(ctx context.Context, id int64, deactivated bool) (models.User, error)
+	// SetUserPassword replaces a user's password hash; only the stores can
+	SetUserPassword(ctx context.Context, id int64, hash []byte) (models.User, error)
+	Close() error
+}
+
+// actor is who changes made through the stores are audited as
+const actor = "admin-cli"
+
+// storeBackend works on the PostgreSQL database or DynamoDB table selected by --store or
+// like the server's, in one tenant. Its changes are audited as admin-cli's.
+type storeBackend struct {
+	tenant string
+	items  store.ScheduledItemStore
+	todos  store.TodoItemStore
+	users  store.UserStore
+	logs   store.ExecutionLogStore
+	close  func() error
+}
+
+// newStoreBackend opens the stores named by storeName or selected by USE_POSTGRES_DB or
+// USE_DYNAMODB
+func newStoreBackend(ctx context.Context, storeName, tenant string) (*storeBackend, error) {
+	kind, err := storage.Select(storeName)
+	if err != nil {
+		return nil, err
+	}
+	if kind == storage.Memory {
+		return nil, fmt.Errorf("use --store or set USE_POSTGRES_DB or USE_DYNAMODB to work on a database, or --api to work through the API")
+	}
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &storeBackend{tenant: tenant, logs: stores.ExecutionLogs, close: stores.Close}
+	audit := stores.AuditLog
+	b.items = store.NewAuditingScheduledItemStore(stores.ScheduledItems, audit)
+	b.todos = store.NewAuditingTodoItemStore(stores.TodoItems, audit)
+	b.users = store.NewAuditingUserStore(stores.Users, audit)
+	return b, nil
+}
+
+// context confines ctx to the backend's tenant and audits changes as admin-cli's
+func (b *storeBackend) context(ctx context.Context) context.Context {
+	return store.WithActor(store.WithTenant(ctx, b.tenant), actor)
+}
+
+// ListItems returns every scheduled item of the tenant
+func (b *storeBackend) ListItems(ctx context.Context) ([]models.ScheduledItem, error) {
+	return b.items.GetAllScheduledItems(b.context(ctx)), nil
+}
+
+// CreateItem stores an item with its next execution time worked out like the API does
+func (b *storeBackend) CreateItem(ctx context.Context, item models.ScheduledItem) (models.ScheduledItem, error) {
+	next := utils.CalculateNextExecution(item.StartsAt, item.Repeats, item.CronExpression, item.Expiration, item.JitterSeconds)
+	if next == nil {
+		return models.ScheduledItem{}, fmt.Errorf("cannot determine the item's next execution time")
+	}
+	item.NextExecutionAt = *next
+	return b.items.CreateScheduledItem(b.context(ctx), item), nil
+}
+
+// DeleteItem deletes a scheduled item
+func (b *storeBackend) DeleteItem(ctx context.Context, id int64) error {
+	if !b.items.DeleteScheduledItem(b.context(ctx), id) {
+		return fmt.Errorf("scheduled item %d not found", id)
+	}
+	return nil
+}
+
+// ListExecutionLogs returns the newest execution logs of the tenant
+func (b *storeBackend) ListExecutionLogs(ctx context.Context, itemID int64, limit int) ([]models.ExecutionLog, error) {
+	if itemID != 0 {
+		logs, _, err := b.logs.GetExecutionLogsByScheduledItemID(b.context(ctx), itemID, limit, nil)
+		return logs, err
+	}
+	return newestExecutionLogs(b.logs.GetAllExecutionLogs(b.context(ctx)), 0, limit), nil
+}
+
+// ListTodos returns every todo item of the tenant
+func (b *storeBackend) ListTodos(ctx context.Context) ([]models.TodoItem, error) {
+	return b.todos.GetAllTodoItems(b.context(ctx)), nil
+}
+
+// SetTodoChecked checks or unchecks a todo item
+func (b *storeBackend) SetTodoChecked(ctx context.Context, id int64, checked bool) (models.TodoItem, error) {
+	ctx = b.context(ctx)
+	todo, found := b.todos.GetTodoItem(ctx, id)
+	if !found {
+		return models.TodoItem{}, fmt.Errorf("todo item %d not found", id)
+	}
+	todo.Checked = checked
+	if todo, found = b.todos.UpdateTodoItem(ctx, id, todo); !found {
+		return models.TodoItem{}, fmt.Errorf("todo item %d not found", id)
+	}
+	return todo, nil
+}
+
+// ListUsers returns the users of the tenant
+func (b *storeBackend) ListUsers(ctx context.Context) ([]models.User, error) {
+	return b.users.GetAllUsers(b.context(ctx)), nil
+}
+
+// CreateUser creates a user in the tenant
+func (b *storeBackend) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	return b.users.CreateUser(b.context(ctx), user), nil
+}
+
+// DeleteUser deletes a user
+func (b *storeBackend) DeleteUser(ctx context.Context, id int64) error {
+	if !b.users.DeleteUser(b.context(ctx), id) {
+		return fmt.Errorf("user %d not found", id)
+	}
+	return nil
+}
+
+// SetUserDeactivated deactivates a user as of now, or reactivates them
+func (b *storeBackend) SetUserDeactivated(ctx context.Context, id int64, deactivated bool) (models.User, error) {
+	var deactivatedAt *time.Time
+	if deactivated {
+		now := time.Now().UTC()
+		deactivatedAt = &now
+	}
+	user, found := b.users.SetUserDeactivated(b.context(ctx), id, deactivatedAt)
+	if !found {
+		return models.User{}, fmt.Errorf("user %d not found", id)
+	}
+	return user, nil
+}
+
+// SetUserPassword replaces a user's password hash
+func (b *storeBackend) SetUserPassword(ctx context.Context, id int64, hash []byte) (models.User, error) {
+	ctx = b.context(ctx)
+	user, found := b.users.GetUser(ctx, id)
+	if !found {
+		return models.User{}, fmt.Errorf("user %d not found", id)
+	}
+	user.PasswordHash = hash
+	if user, found = b.users.UpdateUser(ctx, id, user); !found {
+		return models.User{}, fmt.Errorf("user %d not found", id)
+	}
+	return user, nil
+}
+
+// Close closes the database connection
+func (b *storeBackend) Close() error {
+	return b.close()
+}
+
+// newestExecutionLogs returns up to limit of the logs of a scheduled item, or of every
+// item when itemID is 0, newest first
+func newestExecutionLogs(logs []models.ExecutionLog, itemID int64, limit int) []models.ExecutionLog {
+	newest := make([]models.ExecutionLog, 0, len(logs))
+	for _, log := range logs {
+		if itemID == 0 || log.ScheduledItemID == itemID {
+			newest = append(newest, log)
+		}
+	}
+	slices.SortFunc(newest, func(a, b models.ExecutionLog) int {
+		return b.ExecutedAt.Compare(a.ExecutedAt)
+	})
+	if limit > 0 && len(newest) > limit {
+		newest = newest[:limit]
+	}
+	return newest
+}
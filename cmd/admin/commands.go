@@ -0,0 +1,471 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"periodic-api/internal/handlers"
+	"periodic-api/internal/models"
+	"periodic-api/internal/utils"
+)
+
+// itemCommand lists, creates and deletes scheduled items
+func (c *cli) itemCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "item",
+		Aliases: []string{"items"},
+		Short:   "List, create and delete scheduled items",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List scheduled items",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := c.backend.ListItems(cmd.Context())
+			if err != nil {
+				return err
+			}
+			rows := make([]string, 0, len(items))
+			for _, item := range items {
+				schedule := "once"
+				if item.Repeats && item.CronExpression != nil {
+					schedule = *item.CronExpression
+				}
+				rows = append(rows, fmt.Sprintf("%d\t%s\t%s\t%s", item.ID, item.Title, schedule, item.NextExecutionAt.Format(time.RFC3339)))
+			}
+			return c.print(items, "ID\tTITLE\tSCHEDULE\tNEXT EXECUTION", rows)
+		},
+	}
+
+	var (
+		description string
+		startsAt    string
+		cron        string
+		expiration  string
+		jitter      int
+	)
+	create := &cobra.Command{
+		Use:   "create TITLE",
+		Short: "Create a scheduled item, repeating when --cron is given",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			item := models.ScheduledItem{Title: args[0], Description: description, StartsAt: time.Now().UTC(), JitterSeconds: jitter}
+			if startsAt != "" {
+				parsed, err := time.Parse(time.RFC3339, startsAt)
+				if err != nil {
+					return fmt.Errorf("invalid --starts-at: %w", err)
+				}
+				item.StartsAt = parsed
+			}
+			if cron != "" {
+				item.Repeats = true
+				item.CronExpression = &cron
+			}
+			if expiration != "" {
+				parsed, err := time.Parse(time.RFC3339, expiration)
+				if err != nil {
+					return fmt.Errorf("invalid --expires: %w", err)
+				}
+				item.Expiration = &parsed
+			}
+
+			created, err := c.backend.CreateItem(cmd.Context(), item)
+			if err != nil {
+				return err
+			}
+			return c.print(created, "ID\tTITLE\tNEXT EXECUTION", []string{
+				fmt.Sprintf("%d\t%s\t%s", created.ID, created.Title, created.NextExecutionAt.Format(time.RFC3339)),
+			})
+		},
+	}
+	create.Flags().StringVar(&description, "description", "", "Description of the item")
+	create.Flags().StringVar(&startsAt, "starts-at", "", "When the item starts, in RFC 3339 (default now)")
+	create.Flags().StringVar(&cron, "cron", "", "Cron expression the item repeats on")
+	create.Flags().StringVar(&expiration, "expires", "", "When a repeating item stops, in RFC 3339")
+	create.Flags().IntVar(&jitter, "jitter", 0, "Seconds of random delay added to each execution")
+
+	remove := &cobra.Command{
+		Use:     "delete ID",
+		Aliases: []string{"rm"},
+		Short:   "Delete a scheduled item",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			if err := c.backend.DeleteItem(cmd.Context(), id); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted scheduled item %d\n", id)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(list, create, remove)
+	return cmd
+}
+
+// logCommand lists execution logs
+func (c *cli) logCommand() *cobra.Command {
+	var (
+		itemID int64
+		limit  int
+	)
+	cmd := &cobra.Command{
+		Use:     "log",
+		Aliases: []string{"logs"},
+		Short:   "List the newest execution logs, of every item or of one with --item",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 {
+				return fmt.Errorf("--limit must be at least 1, got %d", limit)
+			}
+			logs, err := c.backend.ListExecutionLogs(cmd.Context(), itemID, limit)
+			if err != nil {
+				return err
+			}
+			rows := make([]string, 0, len(logs))
+			for _, log := range logs {
+				detail := ""
+				if log.ErrorMessage != nil {
+					detail = *log.ErrorMessage
+				} else if log.TodoItemID != nil {
+					detail = fmt.Sprintf("todo %d", *log.TodoItemID)
+				}
+				rows = append(rows, fmt.Sprintf("%d\t%d\t%s\t%s\t%s", log.ID, log.ScheduledItemID, log.ExecutedAt.Format(time.RFC3339), log.Status, detail))
+			}
+			return c.print(logs, "ID\tITEM\tEXECUTED AT\tSTATUS\tDETAIL", rows)
+		},
+	}
+	cmd.Flags().Int64Var(&itemID, "item", 0, "ID of the scheduled item whose executions to list")
+	cmd.Flags().IntVar(&limit, "limit", 20, "Number of execution logs to list")
+	return cmd
+}
+
+// todoCommand lists todos and checks them off
+func (c *cli) todoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "todo",
+		Aliases: []string{"todos"},
+		Short:   "List todos and check them off",
+	}
+
+	var open bool
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List todos",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			todos, err := c.backend.ListTodos(cmd.Context())
+			if err != nil {
+				return err
+			}
+			shown := make([]models.TodoItem, 0, len(todos))
+			rows := make([]string, 0, len(todos))
+			for _, todo := range todos {
+				if open && todo.Checked {
+					continue
+				}
+				shown = append(shown, todo)
+				mark := " "
+				if todo.Checked {
+					mark = "x"
+				}
+				rows = append(rows, fmt.Sprintf("%d\t[%s]\t%s", todo.ID, mark, todo.Text))
+			}
+			return c.print(shown, "ID\tDONE\tTEXT", rows)
+		},
+	}
+	list.Flags().BoolVar(&open, "open", false, "Only list todos not checked off")
+
+	setChecked := func(use, short, done string, checked bool) *cobra.Command {
+		return &cobra.Command{
+			Use:   use + " ID...",
+			Short: short,
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				for _, arg := range args {
+					id, err := parseID(arg)
+					if err != nil {
+						return err
+					}
+					todo, err := c.backend.SetTodoChecked(cmd.Context(), id, checked)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("%s todo %d: %s\n", done, todo.ID, todo.Text)
+				}
+				return nil
+			},
+		}
+	}
+
+	cmd.AddCommand(list, setChecked("check", "Check off todos", "Checked off", true), setChecked("uncheck", "Reopen checked todos", "Reopened", false))
+	return cmd
+}
+
+// userCommand lists, creates, deletes, deactivates and reactivates users
+func (c *cli) userCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "user",
+		Aliases: []string{"users"},
+		Short:   "Manage users",
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			users, err := c.backend.ListUsers(cmd.Context())
+			if err != nil {
+				return err
+			}
+			rows := make([]string, 0, len(users))
+			for i, user := range users {
+				// Password hashes are never printed
+				users[i].PasswordHash = nil
+				status := "active"
+				if user.DeactivatedAt != nil {
+					status = "deactivated " + user.DeactivatedAt.Format(time.RFC3339)
+				}
+				rows = append(rows, fmt.Sprintf("%d\t%s\t%s\t%s", user.ID, user.Username, user.Email, status))
+			}
+			return c.print(users, "ID\tUSERNAME\tEMAIL\tSTATUS", rows)
+		},
+	}
+
+	var (
+		email         string
+		admin         bool
+		passwordStdin bool
+		tokenTTL      time.Duration
+	)
+	create := &cobra.Command{
+		Use:   "create USERNAME",
+		Short: "Create a user",
+		Long: "Create a user. With --password-stdin the user's password is read from stdin; with --admin a password is generated " +
+			"when none is given, and a token with the admin claim is issued for the user, signed with AUTH_TOKEN_SECRET, " +
+			"for bootstrapping a fresh deployment.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (passwordStdin || admin) && c.apiURL != "" {
+				return fmt.Errorf("setting passwords is %w", errNeedsStores)
+			}
+			user := models.User{Username: args[0], Email: email}
+			var generated string
+			if passwordStdin || admin {
+				password, err := readPassword(cmd, passwordStdin)
+				if err != nil {
+					return err
+				}
+				if !passwordStdin {
+					generated = password
+				}
+				if user.PasswordHash, err = utils.HashPassword(password); err != nil {
+					return err
+				}
+			}
+
+			user, err := c.backend.CreateUser(cmd.Context(), user)
+			if err != nil {
+				return err
+			}
+			if user.ID == 0 {
+				return fmt.Errorf("could not create user %s", args[0])
+			}
+			fmt.Printf("Created user %d: %s\n", user.ID, user.Username)
+			if generated != "" {
+				fmt.Printf("Password: %s\n", generated)
+			}
+			if admin {
+				return printAdminToken(c.tenant, user.ID, tokenTTL)
+			}
+			return nil
+		},
+	}
+	create.Flags().StringVar(&email, "email", "", "Email address notifications are sent to")
+	create.Flags().BoolVar(&admin, "admin", false, "Issue an admin token for the user, and give them a password")
+	create.Flags().BoolVar(&passwordStdin, "password-stdin", false, "Read the user's password from stdin")
+	create.Flags().DurationVar(&tokenTTL, "token-ttl", 0, "How long the admin token is valid (default: no expiry)")
+
+	var resetStdin bool
+	resetPassword := &cobra.Command{
+		Use:   "reset-password ID",
+		Short: "Set a new password for a user, generated unless read from stdin with --password-stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			password, err := readPassword(cmd, resetStdin)
+			if err != nil {
+				return err
+			}
+			hash, err := utils.HashPassword(password)
+			if err != nil {
+				return err
+			}
+			if _, err := c.backend.SetUserPassword(cmd.Context(), id, hash); err != nil {
+				return err
+			}
+			fmt.Printf("Reset the password of user %d\n", id)
+			if !resetStdin {
+				fmt.Printf("Password: %s\n", password)
+			}
+			return nil
+		},
+	}
+	resetPassword.Flags().BoolVar(&resetStdin, "password-stdin", false, "Read the new password from stdin")
+
+	byID := func(use, short string, run func(cmd *cobra.Command, id int64) error) *cobra.Command {
+		return &cobra.Command{
+			Use:   use + " ID",
+			Short: short,
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				id, err := parseID(args[0])
+				if err != nil {
+					return err
+				}
+				return run(cmd, id)
+			},
+		}
+	}
+	remove := byID("delete", "Delete a user", func(cmd *cobra.Command, id int64) error {
+		if err := c.backend.DeleteUser(cmd.Context(), id); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted user %d\n", id)
+		return nil
+	})
+	deactivate := byID("deactivate", "Deactivate a user, who can then no longer make requests or be notified", func(cmd *cobra.Command, id int64) error {
+		if _, err := c.backend.SetUserDeactivated(cmd.Context(), id, true); err != nil {
+			return err
+		}
+		fmt.Printf("Deactivated user %d\n", id)
+		return nil
+	})
+	reactivate := byID("reactivate", "Reactivate a deactivated user", func(cmd *cobra.Command, id int64) error {
+		if _, err := c.backend.SetUserDeactivated(cmd.Context(), id, false); err != nil {
+			return err
+		}
+		fmt.Printf("Reactivated user %d\n", id)
+		return nil
+	})
+
+	cmd.AddCommand(list, create, resetPassword, remove, deactivate, reactivate)
+	return cmd
+}
+
+// readPassword reads a password from the first line of the command's stdin, or generates
+// one when fromStdin is false
+func readPassword(cmd *cobra.Command, fromStdin bool) (string, error) {
+	if !fromStdin {
+		return utils.GeneratePassword()
+	}
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("could not read the password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// printAdminToken prints a token with the admin claim for a user of tenant, valid for ttl
+// or indefinitely when it is 0
+func printAdminToken(tenant string, userID int64, ttl time.Duration) error {
+	secret := handlers.TenantTokenSecretFromEnv()
+	if len(secret) == 0 {
+		fmt.Println("No admin token issued: AUTH_TOKEN_SECRET isn't set, so tokens aren't checked and every request may manage the tenants")
+		return nil
+	}
+	var expiresAt *time.Time
+	if ttl > 0 {
+		at := time.Now().Add(ttl)
+		expiresAt = &at
+	}
+	token, err := handlers.IssueAdminToken(secret, tenant, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Admin token: %s\n", token)
+	return nil
+}
+
+// parseID parses the ID argument of a command
+func parseID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid ID %q", arg)
+	}
+	return id, nil
+}
+
+// cronCommand checks cron expressions, without a backend
+func (c *cli) cronCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cron",
+		Short: "Check cron expressions",
+		// Checking an expression needs neither the database nor the API
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	var (
+		timezone string
+		count    int
+	)
+	check := &cobra.Command{
+		Use:   "check EXPRESSION",
+		Short: "Validate a cron expression, describe it and list its next occurrences",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expression := args[0]
+			description, err := utils.DescribeCronExpression(expression)
+			if err != nil {
+				return fmt.Errorf("invalid cron expression %q: %w", expression, err)
+			}
+			location, err := time.LoadLocation(timezone)
+			if err != nil {
+				return fmt.Errorf("invalid --timezone: %w", err)
+			}
+
+			now := time.Now().In(location)
+			occurrences := utils.Occurrences(now, true, &expression, nil, now, now.AddDate(100, 0, 0), count)
+			if c.json {
+				return c.print(struct {
+					Expression  string      `json:"expression"`
+					Description string      `json:"description"`
+					Timezone    string      `json:"timezone"`
+					Occurrences []time.Time `json:"occurrences"`
+				}{expression, description, location.String(), occurrences}, "", nil)
+			}
+
+			fmt.Printf("%s\n%s\n\n", expression, description)
+			if len(occurrences) == 0 {
+				fmt.Println("It never occurs")
+				return nil
+			}
+			fmt.Printf("Next %d occurrences (%s):\n", len(occurrences), location)
+			for _, occurrence := range occurrences {
+				fmt.Printf("  %s\n", occurrence.Format("Mon 2006-01-02 15:04 MST"))
+			}
+			return nil
+		},
+	}
+	check.Flags().StringVar(&timezone, "timezone", "Local", "IANA time zone to list the occurrences in, such as America/New_York")
+	check.Flags().IntVar(&count, "count", 10, "Number of occurrences to list")
+
+	cmd.AddCommand(check)
+	return cmd
+}
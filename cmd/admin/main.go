@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+)
+
+// Manages scheduled items, execution logs, todos and users from a terminal, either on the
+// database selected by --store or like the server's, or through the HTTP API with --api
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// cli holds the global flags and the backend the commands work on
+type cli struct {
+	configFile string
+	storeName  string
+	apiURL     string
+	token      string
+	userID     string
+	tenant     string
+	json       bool
+
+	backend backend
+}
+
+// newRootCommand creates the admin command with its subcommands
+func newRootCommand() *cobra.Command {
+	c := &cli{}
+	root := &cobra.Command{
+		Use:          "admin",
+		Short:        "Manage scheduled items, execution logs, todos and users",
+		Long:         "Manage scheduled items, execution logs, todos and users on the PostgreSQL database or DynamoDB table selected with --store or like the server's (USE_POSTGRES_DB or USE_DYNAMODB), or through the HTTP API with --api.",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			if c.backend == nil {
+				return nil
+			}
+			return c.backend.Close()
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&c.configFile, "config", "", "YAML config file with settings the environment can override (or CONFIG_FILE)")
+	flags.StringVar(&c.storeName, "store", "", storage.FlagUsage)
+	flags.StringVar(&c.apiURL, "api", os.Getenv("PERIODIC_API_URL"), "Base URL of the API to work through instead of the database (or PERIODIC_API_URL)")
+	flags.StringVar(&c.token, "token", os.Getenv("PERIODIC_API_TOKEN"), "Bearer token for the API (or PERIODIC_API_TOKEN)")
+	flags.StringVar(&c.userID, "user", "", "ID of the user to make API requests as")
+	flags.StringVar(&c.tenant, "tenant", store.DefaultTenant, "Tenant to work in; through the API, the tenant of the token")
+	flags.BoolVar(&c.json, "json", false, "Print JSON instead of tables")
+
+	root.AddCommand(c.itemCommand(), c.logCommand(), c.todoCommand(), c.userCommand(), c.cronCommand())
+	return root
+}
+
+// open loads the configuration and connects to the backend the flags select
+func (c *cli) open(ctx context.Context) error {
+	if err := config.Load(c.configFile); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		return fmt.Errorf("invalid logging configuration: %w", err)
+	}
+	logging.Setup(logConfig)
+
+	if c.apiURL != "" {
+		c.backend = newAPIBackend(c.apiURL, c.token, c.userID, c.tenant)
+		return nil
+	}
+	c.backend, err = newStoreBackend(ctx, c.storeName, c.tenant)
+	return err
+}
+
+// print writes v as indented JSON with --json, and otherwise as a table with a row per
+// element of rows, under header
+func (c *cli) print(v any, header string, rows []string) error {
+	if c.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
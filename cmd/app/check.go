@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/db"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/utils"
+	"periodic-api/internal/webhooks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// checkTimeout bounds all of the --check-config checks together
+const checkTimeout = 30 * time.Second
+
+// checkWarning is a problem found by a check that is reported without failing it
+type checkWarning struct {
+	message string
+}
+
+func (w *checkWarning) Error() string {
+	return w.message
+}
+
+// check is one of the checks run by --check-config. It returns a description of what
+// was checked, or an error describing the problem.
+type check struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// checkConfig loads the configuration and checks that the server could start with it,
+// printing a line per check. It returns the exit code: 0 if every check passed, or 1.
+func checkConfig(configFile string) int {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	checks := []check{
+		{"config", func(ctx context.Context) (string, error) { return checkSettings(configFile) }},
+		{"storage", checkStorage},
+		{"migrations", checkMigrations},
+		{"llm", checkLLM},
+		{"webhooks", checkWebhooks},
+		{"alerts", checkAlerts},
+		{"templates", checkTemplates},
+	}
+
+	failed := false
+	for _, c := range checks {
+		detail, err := c.run(ctx)
+		var warning *checkWarning
+		switch {
+		case errors.As(err, &warning):
+			fmt.Printf("WARN  %s: %v\n", c.name, warning)
+		case err != nil:
+			fmt.Printf("FAIL  %s: %v\n", c.name, err)
+			failed = true
+		default:
+			fmt.Printf("OK    %s: %s\n", c.name, detail)
+		}
+
+		// The remaining checks read the settings, so stop if they couldn't be loaded
+		if err != nil && c.name == "config" {
+			break
+		}
+	}
+
+	if failed {
+		fmt.Println("Configuration check failed")
+		return 1
+	}
+	fmt.Println("Configuration check passed")
+	return 0
+}
+
+// warning returns a check result that is reported without failing the check
+func warning(format string, args ...any) error {
+	return &checkWarning{message: fmt.Sprintf(format, args...)}
+}
+
+// checkSettings loads .env, the config file and the flags, and validates the logging settings
+func checkSettings(configFile string) (string, error) {
+	if err := config.Load(configFile); err != nil {
+		return "", err
+	}
+	if err := applyFlags(); err != nil {
+		return "", err
+	}
+	if _, err := logging.ConfigFromEnv(); err != nil {
+		return "", err
+	}
+	if path := config.Path(); path != "" {
+		return "loaded " + path, nil
+	}
+	return "loaded from the environment", nil
+}
+
+// checkStorage connects to the configured database
+func checkStorage(ctx context.Context) (string, error) {
+	kind := storage.FromEnv()
+	if kind == storage.Memory {
+		return "using in-memory storage; data is lost on restart", nil
+	}
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		return "", err
+	}
+	defer stores.Close()
+
+	switch kind {
+	case storage.Postgres:
+		return fmt.Sprintf("connected to PostgreSQL database %s on %s", getenv("DB_NAME", "periodic_db"), getenv("DB_HOST", "localhost")), nil
+	default:
+		table := stores.Table
+		output, err := stores.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			if autoMigrate() {
+				return fmt.Sprintf("DynamoDB table %s will be created at startup", table), nil
+			}
+			return "", fmt.Errorf("DynamoDB table %s does not exist and AUTO_MIGRATE is off", table)
+		}
+		if err != nil {
+			return "", fmt.Errorf("describing DynamoDB table %s: %w", table, err)
+		}
+		return fmt.Sprintf("DynamoDB table %s is %s", table, output.Table.TableStatus), nil
+	}
+}
+
+// checkMigrations compares the PostgreSQL schema version with the migrations directory and
+// the version this build expects
+func checkMigrations(ctx context.Context) (string, error) {
+	if storage.FromEnv() != storage.Postgres {
+		return "not needed without PostgreSQL", nil
+	}
+
+	dialect, err := migrations.DialectFromEnv()
+	if err != nil {
+		return "", err
+	}
+	path, err := dialect.Path(migrationsPath())
+	if err != nil {
+		if autoMigrate() {
+			return "", err
+		}
+		return "", warning("%v; can't compare with the database", err)
+	}
+	latest, err := migrations.LatestVersion(path)
+	if err != nil {
+		return "", err
+	}
+	if latest < migrations.SchemaVersion {
+		return "", fmt.Errorf("the newest migration in %s is %d but this build expects version %d", path, latest, migrations.SchemaVersion)
+	}
+	if _, _, err := migrations.SnapshotConfigFromEnv(); err != nil {
+		return "", err
+	}
+
+	database, err := db.InitDB()
+	if err != nil {
+		return "", err
+	}
+	defer database.Close()
+	version, dirty, err := migrations.MigrateStatus(database, dialect, path)
+	if err != nil {
+		return "", err
+	}
+
+	if err := verifyMigrationChecksums(database); err != nil {
+		return "", err
+	}
+
+	switch {
+	case dirty:
+		return "", fmt.Errorf("version %d is dirty; fix the schema and force the version with cmd/migrate", version)
+	case version > latest:
+		return "", fmt.Errorf("database is at version %d, newer than the latest migration %d", version, latest)
+	case version < latest && autoMigrate():
+		return fmt.Sprintf("at version %d; migrations up to %d will run at startup", version, latest), nil
+	case version < latest:
+		return "", fmt.Errorf("database is at version %d but the latest migration is %d and AUTO_MIGRATE is off", version, latest)
+	default:
+		return fmt.Sprintf("up to date at version %d", version), nil
+	}
+}
+
+// checkLLM validates the model settings and the provider's credentials
+func checkLLM(ctx context.Context) (string, error) {
+	llmConfig, err := utils.LLMConfigFromEnv()
+	if err != nil {
+		return "", err
+	}
+	if _, err := utils.NewLLMProvider(ctx, llmConfig); err != nil {
+		return "", warning("scheduled item generation will be unavailable: %v", err)
+	}
+	if err := utils.CheckLLMCredentials(ctx, llmConfig); err != nil {
+		return "", fmt.Errorf("%s: %w", llmConfig.Provider, err)
+	}
+	return fmt.Sprintf("%s accepted the credentials for model %s", llmConfig.Provider, llmConfig.ModelID), nil
+}
+
+// checkWebhooks validates the webhook delivery settings
+func checkWebhooks(ctx context.Context) (string, error) {
+	if err := webhooks.CheckConfigEnv(); err != nil {
+		return "", err
+	}
+	webhookConfig := webhooks.ConfigFromEnv()
+	return fmt.Sprintf("%d attempts, backoff %v to %v, timeout %v",
+		webhookConfig.MaxAttempts, webhookConfig.InitialBackoff, webhookConfig.MaxBackoff, webhookConfig.Timeout), nil
+}
+
+// checkAlerts validates the operator alert settings
+func checkAlerts(ctx context.Context) (string, error) {
+	if err := notifications.CheckAlertConfigEnv(); err != nil {
+		return "", err
+	}
+	alertConfig, enabled := notifications.AlertConfigFromEnv()
+	if !enabled {
+		return "disabled", nil
+	}
+	if _, channels := notifications.ConfigFromEnv(); !channels {
+		return "", warning("alerts will not be sent: no notification channel is configured")
+	}
+	return fmt.Sprintf("error rate %.0f%% of at least %d items, throttled to every %v",
+		alertConfig.ErrorRate*100, alertConfig.MinBatch, alertConfig.Throttle), nil
+}
+
+// checkTemplates loads the notification templates, checking that the overrides render
+func checkTemplates(ctx context.Context) (string, error) {
+	notificationsConfig, _ := notifications.ConfigFromEnv()
+	if _, err := notifications.LoadTemplates(notificationsConfig.TemplatesDir, notificationsConfig.BaseURL); err != nil {
+		return "", err
+	}
+	if notificationsConfig.TemplatesDir == "" {
+		return "built-in", nil
+	}
+	return "loaded from " + notificationsConfig.TemplatesDir, nil
+}
+
+// getenv returns the environment variable, or def if it isn't set
+func getenv(name, def string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return def
+}
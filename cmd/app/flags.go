@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/storage"
+)
+
+// Command-line flags, each taking precedence over the environment variables and config
+// file settings it mirrors
+var (
+	portFlag         = flag.String("port", "", "Port to listen on, on all interfaces (overrides HTTP_PORT and HTTP_ADDR)")
+	storeFlag        = storage.Flag()
+	migrateFlag      = flag.String("migrate", "", "Migrate the database at startup: auto or off (overrides AUTO_MIGRATE)")
+	runSchedulerFlag = flag.Bool("run-scheduler", false, "Run the scheduler loop in this process (overrides RUN_SCHEDULER)")
+	versionFlag      = flag.Bool("version", false, "Print the build version and exit")
+	configFileFlag   = config.FileFlag()
+	checkConfigFlag  = flag.Bool("check-config", false, "Check the configuration, database, migrations, LLM credentials, webhook and alert settings and notification templates, then exit non-zero on problems")
+)
+
+func init() {
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintln(out, "Usage: periodic-api [command] [flags]")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  serve      Run the API server (the default)")
+		fmt.Fprintln(out, "  migrate    Change or report on the database schema: migrate up|down|status|... (see migrate -h)")
+		fmt.Fprintln(out, "  scheduler  Process due scheduled items as a daemon, or once with -once (see scheduler -h)")
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "Flags of serve:")
+		flag.PrintDefaults()
+	}
+}
+
+// applyFlags sets the environment variables for the flags given on the command line, so
+// the rest of the server reads a single source of settings
+func applyFlags() error {
+	settings := map[string]string{}
+	if isFlagSet("port") {
+		port, err := strconv.Atoi(*portFlag)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid --port %q: must be a port number", *portFlag)
+		}
+		settings["HTTP_PORT"] = *portFlag
+		settings["HTTP_ADDR"] = ""
+	}
+	if isFlagSet("store") {
+		kind, err := storage.Parse(*storeFlag)
+		if err != nil {
+			return fmt.Errorf("invalid --store: %w", err)
+		}
+		maps.Copy(settings, kind.Env())
+	}
+	if isFlagSet("migrate") {
+		switch *migrateFlag {
+		case "auto":
+			settings["AUTO_MIGRATE"] = "true"
+		case "off":
+			settings["AUTO_MIGRATE"] = "false"
+		default:
+			return fmt.Errorf("invalid --migrate %q: must be auto or off", *migrateFlag)
+		}
+	}
+	if isFlagSet("run-scheduler") {
+		settings["RUN_SCHEDULER"] = strconv.FormatBool(*runSchedulerFlag)
+	}
+
+	for name, value := range settings {
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// isFlagSet reports whether the named flag was given on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
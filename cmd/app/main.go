@@ -8,23 +8,41 @@
 // @license.name MIT
 // @license.url https://opensource.org/licenses/MIT
 // @host localhost:8080
-// @BasePath /
+// @BasePath /api/v1
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	_ "periodic-api/docs"
+	"periodic-api/docs"
+	"periodic-api/internal/cli"
+	"periodic-api/internal/cloudevents"
+	"periodic-api/internal/config"
 	"periodic-api/internal/db"
+	"periodic-api/internal/events"
 	"periodic-api/internal/handlers"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/middleware"
 	"periodic-api/internal/migrations"
+	"periodic-api/internal/mqtt"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/openapi"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/seed"
+	"periodic-api/internal/storage"
 	"periodic-api/internal/store"
+	"periodic-api/internal/tracing"
+	"periodic-api/internal/utils"
+	"periodic-api/internal/version"
+	"periodic-api/internal/webhooks"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -35,85 +53,504 @@ func init() {
 	time.Local = time.UTC
 }
 
+// Runs a subcommand: serve (the default) runs the API server, migrate changes the
+// database schema and scheduler processes due items, so one image can run every role
 func main() {
-	var itemStore store.ScheduledItemStore
-	var todoStore store.TodoItemStore
-	var userStore store.UserStore
-	// var executionLogStore store.ExecutionLogStore // Will be used in future chunks
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	// Check environment variable to determine which store to use
-	usePostgres := os.Getenv("USE_POSTGRES_DB")
+	switch command {
+	case "serve":
+		runServer(args)
+	case "migrate":
+		os.Exit(cli.Migrate("periodic-api migrate", args))
+	case "scheduler":
+		os.Exit(cli.Scheduler("periodic-api scheduler", args))
+	case "help":
+		flag.Usage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
 
-	if strings.ToLower(usePostgres) == "true" {
-		// Initialize database connection for PostgreSQL
-		database, err := db.InitDB()
-		if err != nil {
-			log.Fatalf("Failed to initialize database: %v", err)
+// runServer runs the API server with the serve command's arguments until it fails
+func runServer(args []string) {
+	flag.CommandLine.Parse(args)
+
+	if *versionFlag {
+		fmt.Println("periodic-api", version.String())
+		return
+	}
+
+	if *checkConfigFlag {
+		os.Exit(checkConfig(*configFileFlag))
+	}
+
+	if err := config.Load(*configFileFlag); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+	if err := applyFlags(); err != nil {
+		logging.Fatalf("Invalid flags: %v", err)
+	}
+	logging.Infof("Starting periodic-api %s", version.String())
+
+	// Export traces when an OTLP collector is configured
+	if tracingConfig, enabled := tracing.ConfigFromEnv("periodic-api"); enabled {
+		defer tracing.Setup(tracingConfig)()
+		logging.Infof("Exporting traces to %s", tracingConfig.Endpoint)
+	}
+
+	var databaseHandler *handlers.DatabaseHandler
+	var migrationHandler *handlers.MigrationHandler
+	healthHandler := handlers.NewHealthHandler(nil)
+
+	stores, err := storage.Open(context.Background(), storage.FromEnv())
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer stores.Close()
+	logging.Infof("Using %s for storage", stores.Description())
+
+	if database := stores.DB; database != nil {
+		store.SetSlowQueryThreshold(store.SlowQueryThresholdFromEnv())
+		store.SetPreparedStatements(store.PreparedStatementsFromEnv())
+
+		// Refuse to run against migrations edited since they were applied, which leave the
+		// schema different from what the files say
+		if err := verifyMigrationChecksums(database); err != nil {
+			logging.Fatalf("Failed to verify migration checksums: %v", err)
 		}
-		defer database.Close()
 
 		// Run migrations if auto-migration is enabled
-		autoMigrate := os.Getenv("AUTO_MIGRATE")
-		if autoMigrate == "" || strings.ToLower(autoMigrate) == "true" {
-			log.Println("Running database migrations...")
-
-			// Get migrations directory path
-			migrationsPath := "migrations"
-			if customPath := os.Getenv("MIGRATIONS_PATH"); customPath != "" {
-				migrationsPath = customPath
-			}
+		if autoMigrate() {
+			logging.Infof("Running database migrations...")
 
-			absPath, err := filepath.Abs(migrationsPath)
+			dialect, err := migrations.DialectFromEnv()
+			if err != nil {
+				logging.Fatalf("Invalid dialect: %v", err)
+			}
+			absPath, err := filepath.Abs(migrationsPath())
 			if err != nil {
-				log.Fatalf("Failed to get absolute path for migrations: %v", err)
+				logging.Fatalf("Failed to get absolute path for migrations: %v", err)
 			}
 
 			// Check if migrations directory exists
 			if _, err := os.Stat(absPath); os.IsNotExist(err) {
-				log.Printf("Migrations directory does not exist: %s. Skipping auto-migration.", absPath)
+				logging.Warnf("Migrations directory does not exist: %s. Skipping auto-migration.", absPath)
 			} else {
-				if err := migrations.MigrateUp(database, absPath); err != nil {
-					log.Fatalf("Failed to run migrations: %v", err)
+				if absPath, err = dialect.Path(absPath); err != nil {
+					logging.Fatalf("Failed to find migrations: %v", err)
 				}
-				log.Println("Database migrations completed successfully")
+				snapshotter, err := snapshotterFromEnv(context.Background())
+				if err != nil {
+					logging.Fatalf("Invalid migration snapshot configuration: %v", err)
+				}
+				if err := migrations.MigrateUp(database, dialect, absPath, snapshotter); err != nil {
+					logging.Fatalf("Failed to run migrations: %v", err)
+				}
+				logging.Infof("Database migrations completed successfully")
 			}
 		}
 
-		// Create PostgreSQL store instances
-		itemStore = store.NewPostgresScheduledItemStore(database)
-		todoStore = store.NewPostgresTodoItemStore(database)
-		userStore = store.NewPostgresUserStore(database)
-		// executionLogStore = store.NewPostgresExecutionLogStore(database) // Will be used in future chunks
-		log.Println("Using PostgreSQL database for storage")
-	} else {
-		// Create in-memory store instances
-		itemStore = store.NewMemoryScheduledItemStore()
-		todoStore = store.NewMemoryTodoItemStore()
-		userStore = store.NewMemoryUserStore()
-		// executionLogStore = store.NewMemoryExecutionLogStore() // Will be used in future chunks
-		log.Println("Using in-memory database for storage")
+		// Hold back API requests while the schema is behind this build, e.g. with
+		// AUTO_MIGRATE off and the migrations not yet run
+		schemaStatus := func(ctx context.Context) (migrations.SchemaStatus, error) {
+			return migrations.ReadSchemaStatus(ctx, database)
+		}
+		if status, err := schemaStatus(context.Background()); err != nil {
+			logging.Warnf("Failed to check the database schema version: %v", err)
+		} else if !status.Ready() {
+			logging.Errorf("Not serving API requests until the database is migrated: %s", status)
+		}
+		healthHandler = handlers.NewHealthHandler(schemaStatus)
+		migrationHandler = handlers.NewMigrationHandler(func(ctx context.Context) (migrations.Report, error) {
+			dialect, err := migrations.DialectFromEnv()
+			if err != nil {
+				return migrations.Report{}, err
+			}
+			path, err := dialect.Path(migrationsPath())
+			if err != nil {
+				return migrations.Report{}, err
+			}
+			return migrations.ReadReport(ctx, database, path)
+		})
+
+		databaseHandler = handlers.NewDatabaseHandler(database)
+
+		// Send lookups and listings to the read replica, if there is one, while it keeps up
+		readDatabase, err := db.InitReadDB()
+		if err != nil {
+			logging.Fatalf("Failed to initialize read replica: %v", err)
+		}
+		if readDatabase != nil {
+			defer readDatabase.Close()
+			replica := store.NewReadReplica(readDatabase, store.ReplicaMaxLagFromEnv())
+			store.SetReadReplica(replica)
+			go replica.Monitor(context.Background())
+			logging.Infof("Reading from the PostgreSQL read replica at %s", os.Getenv("DB_READ_HOST"))
+		}
+	} else if stores.Dynamo != nil && autoMigrate() {
+		// Create the table if auto-migration is enabled
+		if err := store.EnsureDynamoTable(context.Background(), stores.Dynamo, stores.Table); err != nil {
+			logging.Fatalf("Failed to create DynamoDB table: %v", err)
+		}
+	}
+	itemStore := stores.ScheduledItems
+	todoStore := stores.TodoItems
+	userStore := stores.Users
+	executionLogStore := stores.ExecutionLogs
+
+	// Publish changes made through the item stores to real-time clients
+	bus := events.NewBus()
+	itemStore = store.NewPublishingScheduledItemStore(itemStore, bus)
+	todoStore = store.NewPublishingTodoItemStore(todoStore, bus)
+	executionLogStore = store.NewPublishingExecutionLogStore(executionLogStore, bus)
+
+	// Record the changes made through the API in the audit log
+	itemStore = store.NewAuditingScheduledItemStore(itemStore, stores.AuditLog)
+	todoStore = store.NewAuditingTodoItemStore(todoStore, stores.AuditLog)
+	userStore = store.NewAuditingUserStore(userStore, stores.AuditLog)
+
+	// Optionally cache reads in front of the item stores
+	var cacheHandler *handlers.CacheHandler
+	if cacheConfig, enabled := store.CacheConfigFromEnv(); enabled {
+		cachedItemStore := store.NewCachedScheduledItemStore(itemStore, cacheConfig)
+		cachedTodoStore := store.NewCachedTodoItemStore(todoStore, cacheConfig)
+		itemStore = cachedItemStore
+		todoStore = cachedTodoStore
+
+		cacheHandler = handlers.NewCacheHandler(map[string]handlers.CacheStatsSource{
+			"scheduledItems":     cachedItemStore,
+			"todoItems":          cachedTodoStore,
+			"scheduledItemsNext": handlers.CacheStatsFunc(cachedItemStore.NextStats),
+			"todoItemsListing":   handlers.CacheStatsFunc(cachedTodoStore.ListingStats),
+		})
+		logging.Infof("Caching store reads with TTL %v (polled reads %v) and size %d", cacheConfig.TTL, cacheConfig.PollTTL, cacheConfig.Size)
+	}
+
+	// Confine the items and execution logs read and changed while handling a request to
+	// those the requesting user may see, whichever handler makes the call
+	scopedItemStore := store.NewScopedScheduledItemStore(itemStore, stores.ItemShares)
+	itemStore = scopedItemStore
+	executionLogStore = store.NewScopedExecutionLogStore(executionLogStore, scopedItemStore)
+
+	ctx := context.Background()
+
+	// Seed the in-memory stores, which start empty with every run, with development data.
+	// Persistent stores are only seeded on demand with cmd/seed.
+	if stores.Kind == storage.Memory {
+		seedMemoryStores(ctx, seed.Stores{Users: userStore, TodoItems: todoStore, ScheduledItems: itemStore})
+	}
+
+	// Deliver published events to the registered webhooks
+	webhookConfig := webhooks.ConfigFromEnv()
+	go webhooks.NewDispatcher(stores.Webhooks, scopedItemStore, webhookConfig).Run(ctx, bus)
+
+	// Optionally publish executions to SNS or EventBridge as CloudEvents
+	if cloudEventsConfig, enabled := cloudevents.ConfigFromEnv(); enabled {
+		publisher, err := cloudevents.NewPublisherFromConfig(ctx, cloudEventsConfig)
+		if err != nil {
+			logging.Fatalf("Failed to initialize CloudEvents publisher: %v", err)
+		}
+		go publisher.Run(ctx, bus)
+		logging.Infof("Publishing scheduled item executions as CloudEvents")
+	}
+
+	// Create the scheduler service used to run items on demand
+	schedulerService := scheduler.NewService(itemStore, todoStore, executionLogStore)
+	schedulerService.EnableTransactions(stores.Transactor)
+	if webhookConfig.AllowPrivateNetworks {
+		schedulerService.AllowPrivateNetworks()
+	}
+
+	// Load the notification templates, which admins can preview even when notifications are off
+	notificationsConfig, notificationsEnabled := notifications.ConfigFromEnv()
+	notificationTemplates, err := notifications.LoadTemplates(notificationsConfig.TemplatesDir, notificationsConfig.BaseURL)
+	if err != nil {
+		logging.Fatalf("Failed to load notification templates: %v", err)
+	}
+
+	// Optionally email people or post to Slack about executions run on demand, following the items' notification settings
+	var digester *notifications.Digester
+	if notificationsEnabled {
+		dispatcher, err := notifications.NewDispatcherFromConfig(ctx, notificationsConfig, userStore, stores.NotificationPreferences, stores.DeviceTokens)
+		if err != nil {
+			logging.Fatalf("Failed to initialize notifications: %v", err)
+		}
+		schedulerService.EnableNotifications(dispatcher)
+		if notificationsConfig.DigestSchedule != "" {
+			digester, err = notifications.NewDigester(notificationsConfig.DigestSchedule, dispatcher, itemStore, todoStore, userStore)
+			if err != nil {
+				logging.Fatalf("Failed to initialize digests: %v", err)
+			}
+		}
+		// Alert operators when batches or items fail
+		if alertConfig, enabled := notifications.AlertConfigFromEnv(); enabled {
+			alerter := notifications.NewAlerter(alertConfig, dispatcher)
+			schedulerService.EnableAlerts(alerter)
+			logging.Infof("Alerting about scheduler failures, at most every %v per alert", alertConfig.Throttle)
+		}
+		logging.Infof("Sending execution notifications by %s", notificationsConfig.Channels())
 	}
 
-	// Add sample data for stores
-	todoStore.AddSampleData()
-	userStore.AddSampleData()
+	// Optionally enable the mqtt action for publishing to a broker
+	if mqttConfig, enabled := mqtt.ConfigFromEnv(); enabled {
+		schedulerService.RegisterAction(scheduler.ActionTypeMQTT, scheduler.NewMQTTAction(mqtt.NewClient(mqttConfig)))
+		logging.Infof("MQTT action enabled with topic prefix %s", mqttConfig.Topic)
+	}
+
+	// Load the documented schemas, which request bodies and generated items are validated against
+	validator, err := openapi.NewValidator(docs.OpenAPI)
+	if err != nil {
+		logging.Fatalf("Failed to load OpenAPI document: %v", err)
+	}
 
 	// Create handler instances
-	itemHandler := handlers.NewScheduledItemHandler(itemStore)
+	itemHandler := handlers.NewScheduledItemHandler(itemStore, schedulerService)
+	itemHandler.EnableOrganizations(stores.Organizations)
+	itemHandler.EnableSharing(stores.ItemShares, userStore)
+	tenantLimitHandler := handlers.NewTenantLimitHandler(stores.TenantLimits, handlers.TenantLimitsFromEnv())
+	itemHandler.EnableTenantLimits(tenantLimitHandler)
+
+	// Validate the model settings up front, then enable generation if the provider is configured
+	llmConfig, err := utils.LLMConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid LLM configuration: %v", err)
+	}
+	var reloadableProvider *utils.ReloadableLLMProvider
+	if llmProvider, err := utils.NewLLMProvider(ctx, llmConfig); err != nil {
+		logging.Warnf("Scheduled item generation unavailable: %v", err)
+	} else {
+		reloadableProvider = utils.NewReloadableLLMProvider(llmProvider)
+		itemHandler.EnableGeneration(reloadableProvider, validator)
+		itemHandler.EnableGenerationSessions(stores.GenerationSessions)
+		itemHandler.EnableUsageAccounting(stores.LLMUsage, handlers.LLMQuota{
+			DailyRequests: llmConfig.DailyRequestQuota,
+			DailyTokens:   llmConfig.DailyTokenQuota,
+		})
+		logging.Infof("Generating scheduled items with %s model %s", llmConfig.Provider, llmConfig.ModelID)
+	}
 	todoHandler := handlers.NewTodoItemHandler(todoStore)
+	todoHandler.EnableOrganizations(stores.Organizations)
 	userHandler := handlers.NewUserHandler(userStore)
+	organizationHandler := handlers.NewOrganizationHandler(stores.Organizations, userStore, itemStore, todoStore)
+	notificationPreferenceHandler := handlers.NewNotificationPreferenceHandler(stores.NotificationPreferences, userStore)
+	deviceTokenHandler := handlers.NewDeviceTokenHandler(stores.DeviceTokens, userStore)
+	executionLogHandler := handlers.NewExecutionLogHandler(executionLogStore)
+	webhookHandler := handlers.NewWebhookHandler(stores.Webhooks)
+	webhookHandler.EnableTenantLimits(tenantLimitHandler)
+	if webhookConfig.AllowPrivateNetworks {
+		webhookHandler.AllowPrivateNetworks()
+	}
+	llmUsageHandler := handlers.NewLLMUsageHandler(stores.LLMUsage)
+	auditLogHandler := handlers.NewAuditLogHandler(stores.AuditLog)
+	tenantSecret := handlers.TenantTokenSecretFromEnv()
+	adminHandler := handlers.NewAdminHandler(userStore, itemStore, todoStore, stores.Webhooks, stores.LLMUsage, tenantLimitHandler, tenantSecret)
+	schedulerInstanceHandler := handlers.NewSchedulerInstanceHandler(stores.Heartbeats)
+	notificationTemplateHandler := handlers.NewNotificationTemplateHandler(notificationTemplates, itemStore)
+	corsConfig := middleware.CORSConfigFromEnv()
+	eventHandler := handlers.NewEventHandler(bus, scopedItemStore, corsConfig.AllowsOrigin)
 
-	// Set up routes
-	itemHandler.SetupRoutes()
-	todoHandler.SetupRoutes()
-	userHandler.SetupRoutes()
+	apiRoutes := []handlers.RouteRegistrar{itemHandler, todoHandler, userHandler, organizationHandler, notificationPreferenceHandler, deviceTokenHandler, executionLogHandler, eventHandler, webhookHandler, llmUsageHandler, auditLogHandler, tenantLimitHandler, adminHandler, schedulerInstanceHandler, notificationTemplateHandler}
+	if cacheHandler != nil {
+		apiRoutes = append(apiRoutes, cacheHandler)
+	}
+	if databaseHandler != nil {
+		apiRoutes = append(apiRoutes, databaseHandler)
+	}
+	if migrationHandler != nil {
+		apiRoutes = append(apiRoutes, migrationHandler)
+	}
+
+	// Serve the API under its version prefixes, keeping the unversioned paths as deprecated
+	// aliases; v2 wraps responses in an envelope with paging metadata. Request bodies that
+	// don't match the documented schemas are rejected before they reach the handlers, and
+	// the changes the handlers make are audited as the requesting user's. The stores are
+	// scoped to the requesting user once the token has named them. Requests are
+	// refused with 503 while the database schema is behind this build or dirty (except the
+	// migration report), are
+	// confined to the tenant of their bearer token when AUTH_TOKEN_SECRET is set, and are
+	// refused with 403 when made as a deactivated user. With TENANT_RATE_LIMIT set, tenants
+	// making requests faster than it allows are refused with 429; the limiter is always in
+	// place so reloading the configuration can set or change the limit.
+	api := handlers.RejectDeactivatedUsers(userStore,
+		validator.ValidateRequests(handlers.RecordActor(handlers.ScopeToUser(handlers.NewRouter(apiRoutes...)))))
+	tenantRateLimit, _ := handlers.TenantRateLimitFromEnv()
+	tenantRateLimiter := handlers.RateLimitTenants(tenantRateLimit, api)
+	api = tenantRateLimiter
+	// Without tenant tokens nobody may use the admin endpoints, unless local development
+	// opts in
+	if len(tenantSecret) == 0 && handlers.UnauthenticatedAdminFromEnv() {
+		api = handlers.AllowUnauthenticatedAdmin(api)
+		logging.Warnf("ALLOW_UNAUTHENTICATED_ADMIN is set: every request may manage every tenant")
+	}
+	api = healthHandler.RequireReady(handlers.AuthenticateTenant(tenantSecret, api))
+	routes := []handlers.RouteRegistrar{
+		handlers.Mount(handlers.APIPrefix, api),
+		handlers.MountEnveloped(handlers.APIV2Prefix, api),
+		handlers.DeprecatedAlias(api),
+		healthHandler,
+	}
+
+	// Optionally run the scheduler loop in this process, sharing the same stores
+	runScheduler := strings.ToLower(os.Getenv("RUN_SCHEDULER")) == "true"
+	if runScheduler {
+		interval := scheduler.IntervalFromEnv()
+		schedulerService.EnableHeartbeat(stores.Heartbeats, scheduler.InstanceID())
+		go schedulerService.Run(ctx, interval)
+		if digester != nil {
+			go digester.Run(ctx)
+			logging.Infof("Sending digests on schedule %s", os.Getenv("NOTIFICATIONS_DIGEST_SCHEDULE"))
+		}
+
+		// Expose the scheduler health endpoints alongside the API
+		routes = append(routes, handlers.RouteFunc(func(mux *http.ServeMux) {
+			mux.Handle("/scheduler/", http.StripPrefix("/scheduler", schedulerService.HealthHandler()))
+		}))
+		logging.Infof("Running embedded scheduler with interval: %v", interval)
+	}
+
+	// Apply the settings that can change at runtime when the configuration is reloaded
+	go config.WatchReloads(ctx, func() {
+		logging.ReloadLevel()
+		if runScheduler {
+			schedulerService.SetInterval(scheduler.IntervalFromEnv())
+		}
+		tenantRateLimit, _ := handlers.TenantRateLimitFromEnv()
+		tenantRateLimiter.SetLimit(tenantRateLimit)
+		if reloadableProvider == nil {
+			return
+		}
+		llmConfig, err := utils.LLMConfigFromEnv()
+		if err != nil {
+			logging.Warnf("Keeping the previous LLM configuration: %v", err)
+			return
+		}
+		llmProvider, err := utils.NewLLMProvider(ctx, llmConfig)
+		if err != nil {
+			logging.Warnf("Keeping the previous LLM provider: %v", err)
+			return
+		}
+		reloadableProvider.Swap(llmProvider)
+		itemHandler.SetLLMQuota(handlers.LLMQuota{
+			DailyRequests: llmConfig.DailyRequestQuota,
+			DailyTokens:   llmConfig.DailyTokenQuota,
+		})
+		logging.Infof("Generating scheduled items with %s model %s", llmConfig.Provider, llmConfig.ModelID)
+	})
 
 	// Add Swagger documentation endpoint
-	http.HandleFunc("/swagger/", httpSwagger.WrapHandler)
+	routes = append(routes, handlers.RouteFunc(func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
+		mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(docs.OpenAPI)
+		})
+	}))
+
+	// Apply the standard middleware to every route
+	router := middleware.Chain(handlers.NewRouter(routes...),
+		middleware.RequestID,
+		middleware.Tracing,
+		middleware.Logging,
+		middleware.Recovery,
+		middleware.CORS(corsConfig),
+		middleware.Compress,
+		middleware.ETag,
+		middleware.BodyLogging(middleware.BodyLoggingConfigFromEnv()),
+	)
 
 	// Start the server
-	port := ":8080"
-	fmt.Printf("Server starting on port %s...\n", port)
-	fmt.Printf("API documentation available at: http://localhost%s/swagger/\n", port)
-	log.Fatal(http.ListenAndServe(port, nil))
+	logging.Fatalf("Server failed: %v", serve(router))
+}
+
+// seedMemoryStores loads the fixtures of the SEED_ENV environment, development by default,
+// from the seeds directory into the in-memory stores. SEED_ENV=none leaves them empty.
+func seedMemoryStores(ctx context.Context, stores seed.Stores) {
+	environment := os.Getenv("SEED_ENV")
+	if environment == "" {
+		environment = "development"
+	}
+	if environment == "none" {
+		return
+	}
+
+	fixtures, err := seed.Load("seeds", environment)
+	if err != nil {
+		logging.Warnf("Not seeding the in-memory stores: %v", err)
+		return
+	}
+	seed.Apply(ctx, fixtures, stores)
+}
+
+// autoMigrate reports whether the schema is migrated at startup, which AUTO_MIGRATE
+// turns off when set to anything but true
+func autoMigrate() bool {
+	value := os.Getenv("AUTO_MIGRATE")
+	return value == "" || strings.ToLower(value) == "true"
+}
+
+// verifyMigrationChecksums compares the applied migrations with their files as
+// MIGRATION_CHECKSUMS says: with enforce, an edited or removed file is an error; with
+// warn, it is logged.
+func verifyMigrationChecksums(database *sql.DB) error {
+	mode, err := migrations.ChecksumModeFromEnv()
+	if err != nil || mode == migrations.ChecksumsOff {
+		return err
+	}
+	dialect, err := migrations.DialectFromEnv()
+	if err != nil || dialect != migrations.DialectPostgres {
+		return err
+	}
+	path, err := dialect.Path(migrationsPath())
+	if err != nil {
+		return nil
+	}
+
+	mismatches, err := migrations.VerifyChecksums(context.Background(), database, path)
+	if err != nil {
+		logging.Warnf("Failed to verify migration checksums: %v", err)
+		return nil
+	}
+	for _, mismatch := range mismatches {
+		logging.Errorf("Schema drift: %s", mismatch)
+	}
+	if len(mismatches) > 0 && mode == migrations.ChecksumsEnforce {
+		return fmt.Errorf("%d applied migration(s) differ from their files; restore them, or accept them with cmd/migrate -action=rehash", len(mismatches))
+	}
+	return nil
+}
+
+// snapshotterFromEnv returns the snapshotter backing up the database before migrations
+// as configured by MIGRATION_SNAPSHOT, or nil when snapshots are off
+func snapshotterFromEnv(ctx context.Context) (migrations.Snapshotter, error) {
+	cfg, enabled, err := migrations.SnapshotConfigFromEnv()
+	if err != nil || !enabled {
+		return nil, err
+	}
+	var dumpEnv []string
+	if cfg.Method == migrations.SnapshotPgDump {
+		if dumpEnv, err = db.DumpEnv(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return migrations.NewSnapshotter(ctx, cfg, dumpEnv)
+}
+
+// migrationsPath returns the migrations directory from MIGRATIONS_PATH, defaulting to
+// migrations. The migrations of each dialect are in a directory of its own inside it.
+func migrationsPath() string {
+	if customPath := os.Getenv("MIGRATIONS_PATH"); customPath != "" {
+		return customPath
+	}
+	return "migrations"
 }
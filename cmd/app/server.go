@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// listenAddress returns the address to serve on from HTTP_ADDR, or HTTP_PORT on all
+// interfaces, defaulting to :8080
+func listenAddress() string {
+	if addr := os.Getenv("HTTP_ADDR"); addr != "" {
+		return addr
+	}
+	port := os.Getenv("HTTP_PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return ":" + port
+}
+
+// serve runs the API server until it fails. TLS is enabled when TLS_CERT_FILE and
+// TLS_KEY_FILE point at a certificate and key; otherwise the server speaks plain HTTP.
+func serve(handler http.Handler) error {
+	server := &http.Server{
+		Addr:              listenAddress(),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	certFile := os.Getenv("TLS_CERT_FILE")
+	keyFile := os.Getenv("TLS_KEY_FILE")
+	if certFile == "" && keyFile == "" {
+		logStartup("http", server.Addr)
+		return server.ListenAndServe()
+	}
+	if certFile == "" || keyFile == "" {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	logStartup("https", server.Addr)
+	return server.ListenAndServeTLS(certFile, keyFile)
+}
+
+// logStartup reports where the server and its documentation can be reached
+func logStartup(scheme, addr string) {
+	host := addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	logging.Infof("Server starting on %s...", addr)
+	logging.Infof("API documentation available at: %s://%s/swagger/", scheme, host)
+}
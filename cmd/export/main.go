@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"periodic-api/internal/bundle"
+	"periodic-api/internal/config"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+)
+
+// Exports the users, todo items, scheduled items and execution logs of a tenant from the
+// PostgreSQL database or DynamoDB table selected like the server's to a bundle, for
+// backups and for moving data to another backend with cmd/import
+func main() {
+	var (
+		format             = flag.String("format", bundle.FormatJSON, "Bundle format: json or ndjson")
+		output             = flag.String("out", "", "File to write the bundle to (default stdout)")
+		tenant             = flag.String("tenant", store.DefaultTenant, "Tenant whose data to export")
+		omitPasswordHashes = flag.Bool("omit-password-hashes", false, "Leave the users' password hashes out of the bundle")
+		configFile         = config.FileFlag()
+		storeName          = storage.Flag()
+	)
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	kind, err := storage.Select(*storeName)
+	if err != nil {
+		logging.Fatalf("Invalid --store: %v", err)
+	}
+	if kind == storage.Memory {
+		fmt.Fprintln(os.Stderr, "The in-memory stores live in the server; use --store or set USE_POSTGRES_DB or USE_DYNAMODB to export a database")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	opened, err := storage.Open(ctx, kind)
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer opened.Close()
+	stores := bundle.Stores{
+		Users:          opened.Users,
+		TodoItems:      opened.TodoItems,
+		ScheduledItems: opened.ScheduledItems,
+		ExecutionLogs:  opened.ExecutionLogs,
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			logging.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		out = file
+		defer file.Close()
+	}
+
+	counts, err := bundle.Export(ctx, out, stores, bundle.Options{
+		Format:             *format,
+		Tenant:             *tenant,
+		OmitPasswordHashes: *omitPasswordHashes,
+	})
+	if err != nil {
+		if *output != "" {
+			os.Remove(*output)
+		}
+		logging.Fatalf("Failed to export: %v", err)
+	}
+	logging.Infof("Exported %d users, %d todo items, %d scheduled items and %d execution logs of tenant %s",
+		counts.Users, counts.TodoItems, counts.ScheduledItems, counts.ExecutionLogs, *tenant)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/storage"
+)
+
+// Checks the health of the container it runs in and exits 0 when healthy or 1 when not,
+// for Docker HEALTHCHECK and ECS container health checks in images without curl. By
+// default it asks the API server for /readyz; with -db it checks the database the
+// scheduler works on instead.
+func main() {
+	var (
+		url        = flag.String("url", "", "URL to check (default /readyz on the server's HTTP_ADDR or HTTP_PORT)")
+		checkDB    = flag.Bool("db", false, "Check the database selected by --store, USE_POSTGRES_DB or USE_DYNAMODB instead of the API")
+		timeout    = flag.Duration("timeout", 5*time.Second, "How long the check may take")
+		configFile = config.FileFlag()
+		storeName  = storage.Flag()
+	)
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		fail("Failed to load configuration: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var err error
+	if *checkDB {
+		err = checkDatabase(ctx, *storeName)
+	} else {
+		if *url == "" {
+			*url = readyzURL()
+		}
+		err = checkURL(ctx, *url)
+	}
+	if err != nil {
+		fail("Unhealthy: %v", err)
+	}
+}
+
+// fail reports why the check failed and exits 1
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+// readyzURL returns the URL of the local server's readiness endpoint, listening where
+// cmd/app does and speaking HTTPS when it has a TLS certificate
+func readyzURL() string {
+	host, port := "localhost", os.Getenv("HTTP_PORT")
+	if addr := os.Getenv("HTTP_ADDR"); addr != "" {
+		if h, p, err := net.SplitHostPort(addr); err == nil {
+			port = p
+			if h != "" && h != "0.0.0.0" && h != "::" {
+				host = h
+			}
+		}
+	}
+	if port == "" {
+		port = "8080"
+	}
+	scheme := "http"
+	if os.Getenv("TLS_CERT_FILE") != "" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/readyz", scheme, net.JoinHostPort(host, port))
+}
+
+// checkURL fails unless a GET of url answers with a 2xx status
+func checkURL(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			// The certificate names the public host, not the container's loopback address
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// checkDatabase fails unless the PostgreSQL database answers a ping or the DynamoDB table
+// can be described, of the backend named by storeName or selected by the environment
+func checkDatabase(ctx context.Context, storeName string) error {
+	kind, err := storage.Select(storeName)
+	if err != nil {
+		return err
+	}
+	if kind == storage.Memory {
+		return fmt.Errorf("no database to check: use --store or set USE_POSTGRES_DB or USE_DYNAMODB")
+	}
+
+	// Opening PostgreSQL pings the database
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		return fmt.Errorf("could not reach the database: %w", err)
+	}
+	defer stores.Close()
+	if stores.Dynamo != nil {
+		if _, err := stores.Dynamo.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(stores.Table)}); err != nil {
+			return fmt.Errorf("could not describe table %s: %w", stores.Table, err)
+		}
+	}
+	return nil
+}
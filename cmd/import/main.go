@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"periodic-api/internal/bundle"
+	"periodic-api/internal/config"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/storage"
+)
+
+// Imports a bundle written by cmd/export into the PostgreSQL database or DynamoDB table
+// selected like the server's, for cloning environments and restoring backups
+func main() {
+	var (
+		input      = flag.String("in", "", "File to read the bundle from (default stdin)")
+		tenant     = flag.String("tenant", "", "Tenant to import into (default the bundle's)")
+		ids        = flag.String("ids", "reassign", "reassign to give the records new IDs, or preserve to keep their exported IDs (PostgreSQL only)")
+		configFile = config.FileFlag()
+		storeName  = storage.Flag()
+	)
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	if *ids != "reassign" && *ids != "preserve" {
+		logging.Fatalf("Unknown -ids %q, use reassign or preserve", *ids)
+	}
+
+	kind, err := storage.Select(*storeName)
+	if err != nil {
+		logging.Fatalf("Invalid --store: %v", err)
+	}
+	if kind == storage.Memory {
+		fmt.Fprintln(os.Stderr, "The in-memory stores live in the server; use --store or set USE_POSTGRES_DB or USE_DYNAMODB to import into a database")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	opened, err := storage.Open(ctx, kind)
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer opened.Close()
+
+	if opened.DB != nil {
+		// The records are loaded as the current models, which need the current schema
+		status, err := migrations.ReadSchemaStatus(ctx, opened.DB)
+		if err != nil {
+			logging.Fatalf("Failed to read the schema version: %v", err)
+		}
+		if !status.Ready() {
+			logging.Fatalf("Cannot import: %s", status)
+		}
+	} else {
+		logging.Warnf("DynamoDB imports aren't transactional: the records loaded before a failure stay")
+	}
+	stores := bundle.Stores{
+		Users:          opened.Users,
+		TodoItems:      opened.TodoItems,
+		ScheduledItems: opened.ScheduledItems,
+		ExecutionLogs:  opened.ExecutionLogs,
+		Transactor:     opened.Transactor,
+		Restorer:       opened.Restorer,
+	}
+
+	var in io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			logging.Fatalf("Failed to open %s: %v", *input, err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	header, counts, err := bundle.Import(ctx, in, stores, bundle.ImportOptions{
+		Tenant:      *tenant,
+		PreserveIDs: *ids == "preserve",
+	})
+	if err != nil {
+		logging.Fatalf("Failed to import: %v", err)
+	}
+	into := *tenant
+	if into == "" {
+		into = header.Tenant
+	}
+	logging.Infof("Imported %d users, %d todo items, %d scheduled items and %d execution logs exported from tenant %s at %s into tenant %s",
+		counts.Users, counts.TodoItems, counts.ScheduledItems, counts.ExecutionLogs, header.Tenant, header.ExportedAt.Format("2006-01-02T15:04:05Z07:00"), into)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiPaths are the list endpoints whose latency is measured, relative to /api/v1
+var apiPaths = []string{
+	"/scheduled-items/next?limit=50",
+	"/scheduled-items",
+	"/todo-items",
+}
+
+// apiOptions describes the requests measureAPI sends
+type apiOptions struct {
+	baseURL     string
+	token       string
+	userID      string
+	requests    int
+	concurrency int
+}
+
+// apiResult is the latency of the requests sent to one endpoint
+type apiResult struct {
+	path      string
+	latencies latencies
+	failures  int
+	// err is the first failure, if any
+	err     error
+	elapsed time.Duration
+}
+
+// measureAPI sends the requests to each of the apiPaths in turn, from concurrency
+// workers at a time, and collects their latencies. Responses other than 200 count as
+// failures and aren't timed.
+func measureAPI(ctx context.Context, opts apiOptions) []apiResult {
+	client := &http.Client{Timeout: 30 * time.Second}
+	baseURL := strings.TrimSuffix(opts.baseURL, "/") + "/api/v1"
+
+	results := make([]apiResult, 0, len(apiPaths))
+	for _, path := range apiPaths {
+		result := apiResult{path: path}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		requests := make(chan struct{})
+
+		startedAt := time.Now()
+		for range opts.concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range requests {
+					took, err := get(ctx, client, baseURL+path, opts)
+					mu.Lock()
+					if err != nil {
+						result.failures++
+						if result.err == nil {
+							result.err = err
+						}
+					} else {
+						result.latencies = append(result.latencies, took)
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for range opts.requests {
+			requests <- struct{}{}
+		}
+		close(requests)
+		wg.Wait()
+		result.elapsed = time.Since(startedAt)
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// get sends one request and returns how long the complete response took
+func get(ctx context.Context, client *http.Client, url string, opts apiOptions) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if opts.token != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.token)
+	}
+	if opts.userID != "" {
+		req.Header.Set("X-User-ID", opts.userID)
+	}
+
+	startedAt := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return 0, err
+	}
+	took := time.Since(startedAt)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return took, nil
+}
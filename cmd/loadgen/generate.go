@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+)
+
+// weekdayRanges are the day-of-week fields random cron expressions pick from
+var weekdayRanges = []string{"1-5", "0,6", "1", "3", "5"}
+
+// randomCronExpression returns a cron expression of one of the shapes people schedule
+// with: every few minutes, hourly, daily, on some weekdays or monthly
+func randomCronExpression(rng *rand.Rand) string {
+	minute, hour := rng.Intn(60), rng.Intn(24)
+	switch rng.Intn(6) {
+	case 0:
+		return fmt.Sprintf("*/%d * * * *", []int{1, 5, 10, 15, 30}[rng.Intn(5)])
+	case 1:
+		return fmt.Sprintf("%d * * * *", minute)
+	case 2:
+		return fmt.Sprintf("%d */%d * * *", minute, 2+rng.Intn(5))
+	case 3:
+		return fmt.Sprintf("%d %d * * *", minute, hour)
+	case 4:
+		return fmt.Sprintf("%d %d * * %s", minute, hour, weekdayRanges[rng.Intn(len(weekdayRanges))])
+	default:
+		return fmt.Sprintf("%d %d %d * *", minute, hour, 1+rng.Intn(28))
+	}
+}
+
+// seedOptions describes the records seedRecords creates
+type seedOptions struct {
+	items      int
+	todos      int
+	dueRatio   float64
+	actionType string
+}
+
+// seedRecords creates the todo items and the repeating scheduled items, with random cron
+// expressions, in the context's tenant. A dueRatio share of the items is due already,
+// up to an hour late; the others are next executed when their expression says.
+func seedRecords(ctx context.Context, rng *rand.Rand, items store.ScheduledItemStore, todos store.TodoItemStore, opts seedOptions) error {
+	now := time.Now()
+	for i := range opts.todos {
+		todo := todos.CreateTodoItem(ctx, models.TodoItem{Text: fmt.Sprintf("Load test todo %d", i+1)})
+		if todo.ID == 0 {
+			return fmt.Errorf("could not create todo item %d", i+1)
+		}
+	}
+
+	startsAt := now.Add(-24 * time.Hour)
+	for i := range opts.items {
+		expression := randomCronExpression(rng)
+		item := models.ScheduledItem{
+			Title:          fmt.Sprintf("Load test item %d", i+1),
+			Description:    "Created by cmd/loadgen",
+			StartsAt:       startsAt,
+			Repeats:        true,
+			CronExpression: &expression,
+			ActionType:     opts.actionType,
+		}
+		if rng.Float64() < opts.dueRatio {
+			item.NextExecutionAt = now.Add(-time.Duration(rng.Int63n(int64(time.Hour))))
+		} else {
+			next := utils.CalculateNextExecution(startsAt, true, &expression, nil, 0)
+			if next == nil {
+				return fmt.Errorf("cron expression %q has no next execution", expression)
+			}
+			item.NextExecutionAt = *next
+		}
+		if created := items.CreateScheduledItem(ctx, item); created.ID == 0 {
+			return fmt.Errorf("could not create scheduled item %d", i+1)
+		}
+	}
+	return nil
+}
+
+// share returns how many of total records the i-th of n tenants is seeded with, the first
+// ones taking one more each until the remainder is used up
+func share(total, n, i int) int {
+	count := total / n
+	if i < total%n {
+		count++
+	}
+	return count
+}
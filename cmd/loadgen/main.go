@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+)
+
+// Seeds scheduled items with random cron expressions and todo items, then measures how
+// fast the due ones are listed and processed by the scheduler and, with -api, the latency
+// of the API's lists, so performance regressions show up as numbers. It uses the
+// PostgreSQL database or DynamoDB table selected by --store or like the server's, or
+// in-memory stores.
+func main() {
+	var (
+		itemCount   = flag.Int("items", 1000, "Number of scheduled items to seed")
+		todoCount   = flag.Int("todos", 1000, "Number of todo items to seed")
+		dueRatio    = flag.Float64("due", 0.5, "Share of the items seeded already due, from 0 to 1")
+		actionType  = flag.String("action", scheduler.ActionTypeTodo, "Action of the seeded items: todo or log")
+		tenant      = flag.String("tenant", "loadgen", "Tenant to seed into")
+		tenants     = flag.Int("tenants", 1, "Number of tenants to spread the seeded items over, named <tenant>-1 and so on when more than one")
+		explain     = flag.Bool("explain", false, "Print the PostgreSQL plan of the scheduler's claim of due items before processing them")
+		queries     = flag.Int("queries", 200, "Number of GetNextScheduledItems calls to time")
+		limit       = flag.Int("limit", 50, "Limit of each GetNextScheduledItems call")
+		apiURL      = flag.String("api", "", "Base URL of a running API to measure, such as http://localhost:8080 (default: skip the API)")
+		token       = flag.String("token", os.Getenv("PERIODIC_API_TOKEN"), "Bearer token for the API (or PERIODIC_API_TOKEN)")
+		userID      = flag.String("user", "", "ID of the user to make API requests as")
+		requests    = flag.Int("requests", 200, "Number of requests to send to each API endpoint")
+		concurrency = flag.Int("concurrency", 8, "Number of API requests in flight at once")
+		randomSeed  = flag.Int64("seed", 1, "Seed of the random cron expressions and due times, for repeatable runs")
+		configFile  = config.FileFlag()
+		storeName   = storage.Flag()
+	)
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	// The scheduler logs every item it processes, which would dominate the timings
+	if os.Getenv("LOG_LEVEL") == "" {
+		logConfig.Level = slog.LevelWarn
+	}
+	logging.Setup(logConfig)
+
+	if *itemCount < 0 || *todoCount < 0 || *queries < 0 || *requests < 0 {
+		logging.Fatalf("-items, -todos, -queries and -requests can't be negative")
+	}
+	if *dueRatio < 0 || *dueRatio > 1 {
+		logging.Fatalf("-due must be between 0 and 1")
+	}
+	if *limit <= 0 || *concurrency <= 0 || *tenants <= 0 {
+		logging.Fatalf("-limit, -concurrency and -tenants must be positive")
+	}
+	if *actionType != scheduler.ActionTypeTodo && *actionType != scheduler.ActionTypeLog {
+		logging.Fatalf("Unknown -action %q, use todo or log", *actionType)
+	}
+
+	kind, err := storage.Select(*storeName)
+	if err != nil {
+		logging.Fatalf("Invalid --store: %v", err)
+	}
+	ctx := context.Background()
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer stores.Close()
+	backend := stores.Description()
+	if kind == storage.Memory {
+		if *apiURL != "" {
+			logging.Warnf("The API doesn't see the in-memory stores seeded here; its lists are measured with its own data")
+		}
+	} else {
+		logging.Warnf("The scheduler processes the due items of every tenant in the %s, not only those seeded into %s", backend, *tenant)
+	}
+	itemStore, todoStore := stores.ScheduledItems, stores.TodoItems
+
+	tenantNames := []string{*tenant}
+	if *tenants > 1 {
+		tenantNames = make([]string, *tenants)
+		for i := range tenantNames {
+			tenantNames[i] = fmt.Sprintf("%s-%d", *tenant, i+1)
+		}
+	}
+	ctx = store.WithActor(store.WithTenant(ctx, tenantNames[0]), "loadgen")
+	fmt.Printf("Load test on %s, tenant %s\n\n", backend, strings.Join(tenantNames, ", "))
+
+	// Seed, splitting the records evenly between the tenants
+	rng := rand.New(rand.NewSource(*randomSeed))
+	startedAt := time.Now()
+	for i, name := range tenantNames {
+		err = seedRecords(store.WithTenant(ctx, name), rng, itemStore, todoStore, seedOptions{
+			items:      share(*itemCount, len(tenantNames), i),
+			todos:      share(*todoCount, len(tenantNames), i),
+			dueRatio:   *dueRatio,
+			actionType: *actionType,
+		})
+		if err != nil {
+			logging.Fatalf("Failed to seed %s: %v", name, err)
+		}
+	}
+	elapsed := time.Since(startedAt)
+	fmt.Printf("Seeded %d scheduled items and %d todo items in %v (%.0f records/s)\n",
+		*itemCount, *todoCount, round(elapsed), rate(*itemCount+*todoCount, elapsed))
+
+	// List the next items like GET /scheduled-items/next does
+	var nextLatencies latencies
+	for range *queries {
+		startedAt := time.Now()
+		if _, err := itemStore.GetNextScheduledItems(ctx, *limit, 0); err != nil {
+			logging.Fatalf("GetNextScheduledItems failed: %v", err)
+		}
+		nextLatencies = append(nextLatencies, time.Since(startedAt))
+	}
+	fmt.Printf("GetNextScheduledItems(limit %d): %s\n", *limit, nextLatencies.summary())
+
+	if *explain {
+		explainer, ok := itemStore.(interface {
+			ExplainClaimDueItems(ctx context.Context, limit int, lease time.Duration) (string, error)
+		})
+		if !ok {
+			logging.Fatalf("-explain needs the PostgreSQL store")
+		}
+		// Claim a batch like the scheduler does
+		plan, err := explainer.ExplainClaimDueItems(ctx, 100, 5*time.Minute)
+		if err != nil {
+			logging.Fatalf("Failed to explain the claim of due items: %v", err)
+		}
+		fmt.Printf("\nClaimDueItems plan:\n%s\n", plan)
+	}
+
+	// Process the due items in batches until none are left
+	service := scheduler.NewService(itemStore, todoStore, stores.ExecutionLogs)
+	service.EnableTransactions(stores.Transactor)
+	var (
+		batchLatencies latencies
+		processed      int
+		failed         int
+	)
+	startedAt = time.Now()
+	for {
+		batchStartedAt := time.Now()
+		result := service.ProcessScheduledItems(ctx)
+		if result.ClaimErr != nil {
+			logging.Fatalf("Failed to claim due items: %v", result.ClaimErr)
+		}
+		count := result.Succeeded + result.Failed + result.Skipped
+		if count == 0 {
+			break
+		}
+		batchLatencies = append(batchLatencies, time.Since(batchStartedAt))
+		processed += count
+		failed += result.Failed
+	}
+	elapsed = time.Since(startedAt)
+	fmt.Printf("Scheduler: processed %d items (%d failed) in %d batches in %v (%.0f items/s)\n",
+		processed, failed, len(batchLatencies), round(elapsed), rate(processed, elapsed))
+	fmt.Printf("Scheduler batches: %s\n", batchLatencies.summary())
+
+	if *apiURL == "" {
+		return
+	}
+	fmt.Println()
+	results := measureAPI(context.Background(), apiOptions{
+		baseURL:     *apiURL,
+		token:       *token,
+		userID:      *userID,
+		requests:    *requests,
+		concurrency: *concurrency,
+	})
+	for _, result := range results {
+		fmt.Printf("GET %s: %s (%.0f requests/s)\n", result.path, result.latencies.summary(), rate(len(result.latencies), result.elapsed))
+		if result.failures > 0 {
+			fmt.Printf("  %d requests failed, the first with: %v\n", result.failures, result.err)
+		}
+	}
+}
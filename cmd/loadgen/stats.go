@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// latencies collects the durations of repeated operations
+type latencies []time.Duration
+
+// percentile returns the duration below which p percent of the operations finished,
+// by the nearest-rank method. The latencies must be sorted.
+func (l latencies) percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(l))+0.999999) - 1
+	return l[min(max(rank, 0), len(l)-1)]
+}
+
+// summary returns the count, mean and percentiles of the latencies
+func (l latencies) summary() string {
+	if len(l) == 0 {
+		return "no samples"
+	}
+	sorted := append(latencies(nil), l...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return fmt.Sprintf("n=%d mean=%v p50=%v p90=%v p99=%v max=%v",
+		len(sorted), round(total/time.Duration(len(sorted))), round(sorted.percentile(50)),
+		round(sorted.percentile(90)), round(sorted.percentile(99)), round(sorted[len(sorted)-1]))
+}
+
+// round shortens a duration to a readable precision
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+// rate returns how many operations per second count operations in elapsed amount to
+func rate(count int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(count) / elapsed.Seconds()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"periodic-api/internal/openapi"
+)
+
+// Converts the Swagger 2.0 document generated by swag into the OpenAPI 3 document
+// served at /openapi.json
+func main() {
+	var (
+		in  = flag.String("in", "docs/swagger.json", "Path to the Swagger 2.0 document")
+		out = flag.String("out", "docs/openapi.json", "Path to write the OpenAPI 3 document to")
+	)
+	flag.Parse()
+
+	swagger, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *in, err)
+	}
+
+	document, err := openapi.Convert(swagger)
+	if err != nil {
+		log.Fatalf("Failed to convert %s: %v", *in, err)
+	}
+
+	if err := os.WriteFile(*out, document, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+	log.Printf("Wrote %s", *out)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/seed"
+	"periodic-api/internal/storage"
+)
+
+// Seeds the PostgreSQL database or DynamoDB table selected like the server's with the
+// fixtures of an environment. It only runs when asked to, and never for production.
+func main() {
+	var (
+		environment = flag.String("env", "development", "Environment whose fixtures to seed, read from <path>/<env>.yaml")
+		seedsDir    = flag.String("path", "seeds", "Path to the seed fixtures directory")
+		configFile  = config.FileFlag()
+		storeName   = storage.Flag()
+	)
+	flag.Parse()
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	fixtures, err := seed.Load(*seedsDir, *environment)
+	if err != nil {
+		logging.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	kind, err := storage.Select(*storeName)
+	if err != nil {
+		logging.Fatalf("Invalid --store: %v", err)
+	}
+	if kind == storage.Memory {
+		fmt.Println("The in-memory stores are seeded when the server starts; use --store or set USE_POSTGRES_DB or USE_DYNAMODB to seed a database")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer stores.Close()
+
+	seed.Apply(ctx, fixtures, seed.Stores{
+		Users:          stores.Users,
+		TodoItems:      stores.TodoItems,
+		ScheduledItems: stores.ScheduledItems,
+	})
+	fmt.Printf("Seeded the %s fixtures\n", *environment)
+}
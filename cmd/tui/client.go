@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+)
+
+// requestTimeout is how long a request to the API may take
+const requestTimeout = 15 * time.Second
+
+// client calls the HTTP API as the user and tenant of its token
+type client struct {
+	baseURL string
+	token   string
+	userID  string
+	http    *http.Client
+}
+
+// newClient creates a client for the API served at baseURL, such as
+// https://periodic.example.com. Requests carry token as their bearer token and userID as
+// X-User-ID when they are set.
+func newClient(baseURL, token, userID string) *client {
+	return &client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		token:   token,
+		userID:  userID,
+		http:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// do sends a request with body encoded as JSON, when it isn't nil, and decodes the
+// response into result, when it isn't nil. Problem responses become errors.
+func (c *client) do(ctx context.Context, method, path, contentType string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userID != "" {
+		req.Header.Set("X-User-ID", c.userID)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var details problem.Details
+		if err := json.NewDecoder(resp.Body).Decode(&details); err != nil || details.Title == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		if details.Detail != "" {
+			return fmt.Errorf("%s %s: %s", method, path, details.Detail)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, details.Title)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// upcomingItems gets the scheduled items in the order they are next executed
+func (c *client) upcomingItems(ctx context.Context) ([]models.ScheduledItem, error) {
+	var items []models.ScheduledItem
+	err := c.do(ctx, http.MethodGet, "/scheduled-items?sort=nextExecutionAt", "", nil, &items)
+	return items, err
+}
+
+// recentExecutionLogs gets up to limit execution logs, newest first
+func (c *client) recentExecutionLogs(ctx context.Context, limit int) ([]models.ExecutionLog, error) {
+	var logs []models.ExecutionLog
+	if err := c.do(ctx, http.MethodGet, "/execution-logs?sort=-executedAt", "", nil, &logs); err != nil {
+		return nil, err
+	}
+	if len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+// pendingTodos gets the todo items not checked off yet
+func (c *client) pendingTodos(ctx context.Context) ([]models.TodoItem, error) {
+	var todos []models.TodoItem
+	if err := c.do(ctx, http.MethodGet, "/todo-items?sort=createdAt", "", nil, &todos); err != nil {
+		return nil, err
+	}
+	pending := todos[:0]
+	for _, todo := range todos {
+		if !todo.Checked {
+			pending = append(pending, todo)
+		}
+	}
+	return pending, nil
+}
+
+// setPaused pauses or resumes a scheduled item with a merge patch
+func (c *client) setPaused(ctx context.Context, id int64, paused bool) (models.ScheduledItem, error) {
+	var item models.ScheduledItem
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/scheduled-items/%d", id), "application/merge-patch+json", map[string]bool{"paused": paused}, &item)
+	return item, err
+}
+
+// runNow executes a scheduled item's action immediately
+func (c *client) runNow(ctx context.Context, id int64) (models.ExecutionLog, error) {
+	var log models.ExecutionLog
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/scheduled-items/%d/run", id), "", nil, &log)
+	return log, err
+}
+
+// checkOff checks off a todo item with a merge patch
+func (c *client) checkOff(ctx context.Context, id int64) (models.TodoItem, error) {
+	var todo models.TodoItem
+	err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/todo-items/%d", id), "application/merge-patch+json", map[string]bool{"checked": true}, &todo)
+	return todo, err
+}
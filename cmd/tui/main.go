@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Browses the upcoming scheduled items, recent executions and pending todos of the API's
+// user in the terminal, pausing and running items and checking off todos through the API
+func main() {
+	var (
+		apiURL  = flag.String("api", os.Getenv("PERIODIC_API_URL"), "Base URL of the API (or PERIODIC_API_URL)")
+		token   = flag.String("token", os.Getenv("PERIODIC_API_TOKEN"), "Bearer token for the API (or PERIODIC_API_TOKEN)")
+		userID  = flag.String("user", "", "ID of the user to make requests as")
+		refresh = flag.Duration("refresh", 10*time.Second, "How often to reload the lists")
+	)
+	flag.Parse()
+
+	if *apiURL == "" {
+		fmt.Fprintln(os.Stderr, "Set --api or PERIODIC_API_URL to the API to browse, such as http://localhost:8080")
+		os.Exit(1)
+	}
+	if *refresh <= 0 {
+		fmt.Fprintln(os.Stderr, "--refresh must be positive")
+		os.Exit(1)
+	}
+
+	program := tea.NewProgram(newModel(newClient(*apiURL, *token, *userID), *refresh), tea.WithAltScreen())
+	if _, err := program.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"periodic-api/internal/models"
+)
+
+// logLimit is how many of the newest execution logs are shown
+const logLimit = 50
+
+// pane is one of the lists the TUI switches between
+type pane int
+
+const (
+	itemsPane pane = iota
+	logsPane
+	todosPane
+	paneCount
+)
+
+// paneTitles are the titles of the panes, in their order
+var paneTitles = [paneCount]string{"Upcoming items", "Recent executions", "Pending todos"}
+
+var (
+	activeTabStyle = lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1)
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	selectedStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// model is the TUI's state
+type model struct {
+	client  *client
+	refresh time.Duration
+
+	pane   pane
+	cursor [paneCount]int
+	items  []models.ScheduledItem
+	logs   []models.ExecutionLog
+	todos  []models.TodoItem
+
+	// status reports the outcome of the last action, err the last failure
+	status string
+	err    error
+	loaded bool
+	height int
+}
+
+// dataMsg carries the lists loaded from the API
+type dataMsg struct {
+	items []models.ScheduledItem
+	logs  []models.ExecutionLog
+	todos []models.TodoItem
+	err   error
+}
+
+// actionMsg reports the outcome of an action taken on an item or todo
+type actionMsg struct {
+	status string
+	err    error
+}
+
+// tickMsg asks for the lists to be reloaded
+type tickMsg time.Time
+
+// newModel creates the TUI's state, reloading the lists every refresh
+func newModel(client *client, refresh time.Duration) model {
+	return model{client: client, refresh: refresh}
+}
+
+// Init loads the lists and schedules their reload
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+// load fetches the lists from the API
+func (m model) load() tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		var msg dataMsg
+		if msg.items, msg.err = m.client.upcomingItems(ctx); msg.err != nil {
+			return msg
+		}
+		if msg.logs, msg.err = m.client.recentExecutionLogs(ctx, logLimit); msg.err != nil {
+			return msg
+		}
+		msg.todos, msg.err = m.client.pendingTodos(ctx)
+		return msg
+	}
+}
+
+// tick reloads the lists after the refresh interval
+func (m model) tick() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// act runs an action against the API and reports its outcome
+func (m model) act(action func(ctx context.Context) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		status, err := action(ctx)
+		return actionMsg{status: status, err: err}
+	}
+}
+
+// Update handles keys, window sizes and loaded data
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tickMsg:
+		return m, tea.Batch(m.load(), m.tick())
+	case dataMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.items, m.logs, m.todos = msg.items, msg.logs, msg.todos
+		m.loaded = true
+		m.err = nil
+		for p := range paneCount {
+			m.cursor[p] = min(m.cursor[p], max(m.length(p)-1, 0))
+		}
+	case actionMsg:
+		m.status, m.err = msg.status, msg.err
+		return m, m.load()
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey handles a key press
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab", "right", "l":
+		m.pane = (m.pane + 1) % paneCount
+	case "shift+tab", "left", "h":
+		m.pane = (m.pane + paneCount - 1) % paneCount
+	case "1", "2", "3":
+		m.pane = pane(msg.String()[0] - '1')
+	case "up", "k":
+		m.cursor[m.pane] = max(m.cursor[m.pane]-1, 0)
+	case "down", "j":
+		m.cursor[m.pane] = min(m.cursor[m.pane]+1, max(m.length(m.pane)-1, 0))
+	case "ctrl+r", "f5":
+		return m, m.load()
+	case "p":
+		if item, ok := m.selectedItem(); ok {
+			return m, m.act(func(ctx context.Context) (string, error) {
+				updated, err := m.client.setPaused(ctx, item.ID, !item.Paused)
+				if err != nil {
+					return "", err
+				}
+				if updated.Paused {
+					return fmt.Sprintf("Paused %q", updated.Title), nil
+				}
+				return fmt.Sprintf("Resumed %q", updated.Title), nil
+			})
+		}
+	case "r", "enter":
+		if item, ok := m.selectedItem(); ok {
+			return m, m.act(func(ctx context.Context) (string, error) {
+				log, err := m.client.runNow(ctx, item.ID)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Ran %q: %s", item.Title, log.Status), nil
+			})
+		}
+	case "x", " ":
+		if m.pane == todosPane && len(m.todos) > 0 {
+			todo := m.todos[m.cursor[todosPane]]
+			return m, m.act(func(ctx context.Context) (string, error) {
+				if _, err := m.client.checkOff(ctx, todo.ID); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Checked off %q", todo.Text), nil
+			})
+		}
+	}
+	return m, nil
+}
+
+// selectedItem returns the scheduled item under the cursor when the items are shown
+func (m model) selectedItem() (models.ScheduledItem, bool) {
+	if m.pane != itemsPane || len(m.items) == 0 {
+		return models.ScheduledItem{}, false
+	}
+	return m.items[m.cursor[itemsPane]], true
+}
+
+// length returns the number of rows of a pane
+func (m model) length(p pane) int {
+	switch p {
+	case itemsPane:
+		return len(m.items)
+	case logsPane:
+		return len(m.logs)
+	default:
+		return len(m.todos)
+	}
+}
+
+// rows returns the rows of a pane
+func (m model) rows(p pane) []string {
+	var rows []string
+	switch p {
+	case itemsPane:
+		for _, item := range m.items {
+			schedule := "once"
+			if item.Repeats && item.CronExpression != nil {
+				schedule = *item.CronExpression
+			}
+			if item.Paused {
+				schedule += " (paused)"
+			}
+			rows = append(rows, fmt.Sprintf("%-6d %-36s %-18s %s", item.ID, truncate(item.Title, 36), item.NextExecutionAt.Local().Format("Mon Jan 02 15:04"), schedule))
+		}
+	case logsPane:
+		for _, log := range m.logs {
+			detail := ""
+			if log.ErrorMessage != nil {
+				detail = *log.ErrorMessage
+			} else if log.TodoItemID != nil {
+				detail = fmt.Sprintf("todo %d", *log.TodoItemID)
+			}
+			rows = append(rows, fmt.Sprintf("%-18s item %-6d %-8s %s", log.ExecutedAt.Local().Format("Mon Jan 02 15:04"), log.ScheduledItemID, log.Status, detail))
+		}
+	default:
+		for _, todo := range m.todos {
+			rows = append(rows, fmt.Sprintf("%-6d %s", todo.ID, todo.Text))
+		}
+	}
+	return rows
+}
+
+// View renders the tabs, the rows of the current pane around the cursor, the status and
+// the key bindings
+func (m model) View() string {
+	var b strings.Builder
+
+	for p := range paneCount {
+		title := fmt.Sprintf("%d %s (%d)", p+1, paneTitles[p], m.length(p))
+		if p == m.pane {
+			b.WriteString(activeTabStyle.Render(title))
+		} else {
+			b.WriteString(tabStyle.Render(title))
+		}
+	}
+	b.WriteString("\n\n")
+
+	rows := m.rows(m.pane)
+	visible := len(rows)
+	if m.height > 0 {
+		// Leave room for the tabs, the status and the key bindings
+		visible = min(visible, max(m.height-6, 1))
+	}
+	cursor := m.cursor[m.pane]
+	first := max(0, min(cursor-visible/2, len(rows)-visible))
+	switch {
+	case !m.loaded && m.err == nil:
+		b.WriteString(dimStyle.Render("Loading...") + "\n")
+	case len(rows) == 0:
+		b.WriteString(dimStyle.Render("Nothing here") + "\n")
+	}
+	for i := first; i < first+visible && i < len(rows); i++ {
+		if i == cursor {
+			b.WriteString(selectedStyle.Render("> "+rows[i]) + "\n")
+		} else {
+			b.WriteString("  " + rows[i] + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(m.err.Error()))
+	} else {
+		b.WriteString(m.status)
+	}
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render("tab switch • ↑/↓ select • p pause/resume • r run now • x check off • ctrl+r refresh • q quit"))
+	return b.String()
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -23,82 +23,137 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/generate-scheduled-item": {
-            "post": {
-                "description": "Use AI to generate a scheduled item from a natural language prompt",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/audit-log": {
+            "get": {
+                "description": "Retrieve the changes made to scheduled items, todo items and users through the API, newest first: who made each change and the entity before and after it. Changes made by the scheduler are not audited.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "generation"
+                    "admin"
                 ],
-                "summary": "Generate a scheduled item from a text prompt",
+                "summary": "Get the audit log",
                 "parameters": [
                     {
-                        "description": "Generation request with prompt and timezone",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_handlers.GeneratePromptRequest"
-                        }
+                        "type": "string",
+                        "description": "Only return changes made by this user",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "scheduled_item",
+                            "todo_item",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "Only return changes to this type of entity",
+                        "name": "entityType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return changes to the entity with this ID",
+                        "name": "entityId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return changes made at or after this time (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return changes made before this time (RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Maximum number of entries to return (at most 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.AuditLogEntry"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid query parameter",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/migrations": {
+            "get": {
+                "description": "Get the schema version recorded by the migrations, whether the last migration failed partway (dirty), the version this build expects, and the migrations in the migrations directory applied up to that version and still pending. Served while the schema isn't ready, unlike the rest of the API. Only available when USE_POSTGRES_DB is enabled. Requires a tenant token with the admin claim when tenant tokens are checked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get migration status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_migrations.Report"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "503": {
-                        "description": "AWS LLM service not available",
+                    "500": {
+                        "description": "The migrations couldn't be read",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/scheduled-items": {
+        "/admin/notification-templates": {
             "get": {
-                "description": "Retrieve all scheduled items from the store",
+                "description": "List the Go text/template subject and body of each kind of notification: execution emails and Slack messages, pushed todos and digests. They are the built-in templates unless overridden by files in NOTIFICATIONS_TEMPLATES_DIR; items and users can still set their own execution templates.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-items"
+                    "admin"
                 ],
-                "summary": "Get all scheduled items",
+                "summary": "List the notification templates",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                                "$ref": "#/definitions/periodic-api_internal_handlers.NotificationTemplate"
                             }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/notification-templates/preview": {
             "post": {
-                "description": "Create a new scheduled item with the given details",
+                "description": "Render a kind of notification with the configured templates, or with the subject and body given to try out changes. Execution and push notifications are about a sample item unless scheduledItemId names one; digests always list sample items.",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,89 +161,86 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-items"
+                    "admin"
                 ],
-                "summary": "Create a scheduled item",
+                "summary": "Preview a notification template",
                 "parameters": [
                     {
-                        "description": "Scheduled item to create",
-                        "name": "item",
+                        "description": "Kind of notification, optional templates, item and execution outcome",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            "$ref": "#/definitions/periodic-api_internal_handlers.NotificationTemplatePreviewRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            "$ref": "#/definitions/periodic-api_internal_handlers.NotificationTemplatePreview"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad request, or the template doesn't render",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/scheduled-items/next": {
+        "/admin/tenant-limits": {
             "get": {
-                "description": "Retrieve the next scheduled items ordered by execution time",
+                "description": "List the limits of every tenant that has limits of its own, ordered by tenant. Other tenants get the deployment's defaults. Requires a tenant token with the admin claim when tenant tokens are checked.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-items"
-                ],
-                "summary": "Get next scheduled items",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Maximum number of items to return",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "List tenant limits",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                                "$ref": "#/definitions/periodic-api_internal_models.TenantLimits"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/scheduled-items/{id}": {
+        "/admin/tenant-limits/{tenant}": {
             "get": {
-                "description": "Get a specific scheduled item by its ID",
+                "description": "Get the limits a tenant is held to: its own, or the deployment's defaults, which have no updatedAt. 0 means unlimited.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-items"
+                    "admin"
                 ],
-                "summary": "Get a scheduled item by ID",
+                "summary": "Get the limits of a tenant",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Scheduled item ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
                         "in": "path",
                         "required": true
                     }
@@ -197,34 +249,85 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            "$ref": "#/definitions/periodic-api_internal_models.TenantLimits"
+                        }
+                    },
+                    "403": {
+                        "description": "Only administrators can manage tenants",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the limits of a tenant: the scheduled items and webhooks it may have and the generation requests it may make per UTC day. 0 means unlimited. Existing items and webhooks over a lowered limit are kept, but no more can be created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Adjust the limits of a tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limits of the tenant",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TenantLimits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TenantLimits"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Bad request",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "Scheduled item not found",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save tenant limits",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Delete a scheduled item by its ID",
+                "description": "Remove the limits of a tenant's own, so it gets the deployment's defaults",
                 "tags": [
-                    "scheduled-items"
+                    "admin"
                 ],
-                "summary": "Delete a scheduled item",
+                "summary": "Reset the limits of a tenant",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Scheduled item ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
                         "in": "path",
                         "required": true
                     }
@@ -233,261 +336,351 @@ const docTemplate = `{
                     "204": {
                         "description": "No content"
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
                     "404": {
-                        "description": "Scheduled item not found",
+                        "description": "Tenant has no limits of its own",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/todo-items": {
+        "/admin/tenants": {
             "get": {
-                "description": "Retrieve all todo items from the store",
+                "description": "List the tenants that have users or limits of their own, ordered by tenant, with the number of users, scheduled items, todo items and webhooks they have, the generation requests they made today and their limits. Requires a tenant token with the admin claim when tenant tokens are checked.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo-items"
+                    "admin"
                 ],
-                "summary": "Get all todo items",
+                "summary": "List tenants",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                                "$ref": "#/definitions/periodic-api_internal_models.TenantSummary"
                             }
                         }
+                    },
+                    "403": {
+                        "description": "Only administrators can manage tenants",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new todo item with the given details",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/tenants/{tenant}": {
+            "get": {
+                "description": "Get the number of users, scheduled items, todo items and webhooks a tenant has, the generation requests it made today and its limits. Tenants that have nothing are counted as empty.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo-items"
+                    "admin"
                 ],
-                "summary": "Create a todo item",
+                "summary": "Get a tenant",
                 "parameters": [
                     {
-                        "description": "Todo item to create",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
-                        }
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                            "$ref": "#/definitions/periodic-api_internal_models.TenantSummary"
                         }
                     },
-                    "400": {
-                        "description": "Bad request",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/todo-items/{id}": {
+        "/admin/tenants/{tenant}/users": {
             "get": {
-                "description": "Get a specific todo item by its ID",
+                "description": "List the users of a tenant, deactivated or not",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo-items"
+                    "admin"
                 ],
-                "summary": "Get a todo item by ID",
+                "summary": "List the users of a tenant",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Todo item ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by id, createdAt or updatedAt; prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.User"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid sort field",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "Todo item not found",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a todo item by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/tenants/{tenant}/users/{id}/deactivate": {
+            "post": {
+                "description": "Deactivate a user of a tenant. Requests made as the user are refused with 403 and they are no longer notified, but their items and todos are kept. Deactivating a deactivated user keeps the time they were first deactivated.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo-items"
+                    "admin"
                 ],
-                "summary": "Update a todo item",
+                "summary": "Deactivate a user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Todo item ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated todo item",
-                        "name": "item",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "Todo item not found",
+                    "403": {
+                        "description": "Only administrators can manage tenants",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete a todo item by its ID",
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenants/{tenant}/users/{id}/impersonate": {
+            "post": {
+                "description": "Issue a support token for making requests as a user of a tenant, valid for an hour, to reproduce what they see. The token has no admin claim, and the changes made with it are audited as the user's, impersonated by the administrator. Only available when tenant tokens are checked.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "todo-items"
+                    "admin"
                 ],
-                "summary": "Delete a todo item",
+                "summary": "Impersonate a user",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "Todo item ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the administrator making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.ImpersonationToken"
+                        }
                     },
                     "400": {
                         "description": "Invalid ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only administrators can manage tenants",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
                     "404": {
-                        "description": "Todo item not found",
+                        "description": "User not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "User is deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Impersonation not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
             }
         },
-        "/users": {
-            "get": {
-                "description": "Retrieve all users from the store",
+        "/admin/tenants/{tenant}/users/{id}/reactivate": {
+            "post": {
+                "description": "Reactivate a deactivated user of a tenant, so they can make requests and be notified again",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "summary": "Reactivate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant",
+                        "name": "tenant",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/periodic-api_internal_models.User"
-                            }
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only administrators can manage tenants",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new user with the given details",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/cache/stats": {
+            "get": {
+                "description": "Get hit and miss counts, the hit rate and the number of cached entries for each store cache, and for the caches of the polled reads on their own. Only available when USE_CACHE is enabled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "cache"
                 ],
-                "summary": "Create a user",
-                "parameters": [
-                    {
-                        "description": "User to create",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get cache statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/periodic-api_internal_store.CacheStats"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/db/metrics": {
+            "get": {
+                "description": "Connection pool statistics and slow query counts in the Prometheus text format. db_pool_open, db_pool_in_use, db_pool_idle and db_pool_max_open report the connections, and db_pool_wait_count_total and db_pool_wait_seconds_total how often and how long callers waited for one. db_slow_queries_total and db_slow_query_seconds_total count the statements that took longer than DB_SLOW_QUERY_THRESHOLD, by the store operation that ran them. With DB_READ_HOST, db_replica_lag_seconds, db_replica_reads_total and db_replica_fallbacks_total report the read replica's lag and the queries sent to it or back to the primary. Only available when USE_POSTGRES_DB is enabled.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "database"
                 ],
+                "summary": "Get database metrics",
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.User"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request",
+                    "200": {
+                        "description": "Prometheus metrics",
                         "schema": {
                             "type": "string"
                         }
@@ -495,48 +688,134 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/db/stats": {
             "get": {
-                "description": "Get a specific user by their ID",
+                "description": "Get open, in-use and idle connection counts and how often callers waited for a connection. Only available when USE_POSTGRES_DB is enabled.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "database"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Get database connection pool statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_db.PoolStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/execution-logs": {
+            "get": {
+                "description": "Retrieve the execution logs of every scheduled item, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per log. With the cursor parameter, the logs are returned a page at a time, newest first with ties broken by descending ID, and the cursor of the next page is sent in the X-Next-Cursor header. Pages leave out the logs of items the user can't see, so they can be shorter than the limit before the last one.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "execution-logs"
+                ],
+                "summary": "Get all execution logs",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort by id or executedAt; prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page by cursor: empty for the first page, or the cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 50,
+                        "description": "Number of logs per page when paging by cursor",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, overriding the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request, who only sees the executions of the items they can see",
+                        "name": "X-User-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.ExecutionLog"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid sort field, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "500": {
+                        "description": "Failed to retrieve page",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update a user by their ID",
+            }
+        },
+        "/execution-logs/stream": {
+            "get": {
+                "description": "Stream the execution log entries of the items the caller may see as they are created using server-sent events. Each entry is sent as an \"execution-log\" event with the log as JSON data.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "execution-logs"
+                ],
+                "summary": "Stream execution logs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ExecutionLog"
+                        }
+                    },
+                    "500": {
+                        "description": "Streaming not supported",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/generate-scheduled-item": {
+            "post": {
+                "description": "Use AI to generate a scheduled item from a natural language prompt. By default the item is only returned, for the client to review and create; with save=true it is validated and created like POST /scheduled-items, and returned with 201 Created.",
                 "consumes": [
                     "application/json"
                 ],
@@ -544,100 +823,3820 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "generation"
                 ],
-                "summary": "Update a user",
+                "summary": "Generate a scheduled item from a text prompt",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated user data",
-                        "name": "user",
+                        "description": "Generation request with prompt and timezone",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                            "$ref": "#/definitions/periodic-api_internal_handlers.GeneratePromptRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Create the generated item instead of only returning it",
+                        "name": "save",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the request is accounted to; defaults to the client address",
+                        "name": "X-User-ID",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "201": {
+                        "description": "Generated item was created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Bad request, or the generated item is invalid",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "403": {
+                        "description": "Tenant limit of scheduled items reached, when saving",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete a user by their ID",
-                "tags": [
-                    "users"
-                ],
-                "summary": "Delete a user",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No content"
                     },
-                    "400": {
-                        "description": "Invalid ID",
+                    "429": {
+                        "description": "Daily LLM quota used up",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
                         }
-                    }
+                    },
+                    "502": {
+                        "description": "LLM service returned an error, or output that was still invalid after one repair",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "LLM service not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "504": {
+                        "description": "LLM service timed out",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/generation-sessions": {
+            "post": {
+                "description": "Generate a scheduled item from a prompt like POST /generate-scheduled-item, and keep the exchange so the item can be refined with further messages before it is committed. Sessions expire an hour after their last message.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Start a generation session",
+                "parameters": [
+                    {
+                        "description": "Generation request with prompt and timezone",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.GeneratePromptRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the request is accounted to; defaults to the client address",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.GenerationSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "429": {
+                        "description": "Daily LLM quota used up",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "502": {
+                        "description": "LLM service returned an error, or output that was still invalid after one repair",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "LLM service or generation sessions not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "504": {
+                        "description": "LLM service timed out",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/generation-sessions/{id}": {
+            "get": {
+                "description": "Retrieve a generation session with its messages and latest item",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Get a generation session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Generation session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.GenerationSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Generation session not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Generation sessions not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a generation session without creating its item",
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Discard a generation session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Generation session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Generation session not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Generation sessions not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/generation-sessions/{id}/commit": {
+            "post": {
+                "description": "Validate and create the session's latest item like POST /scheduled-items, then end the session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Commit a generation session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Generation session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, or the generated item is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Tenant limit of scheduled items reached",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Generation session not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Generation sessions not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/generation-sessions/{id}/messages": {
+            "post": {
+                "description": "Ask for a change to the session's item, such as \"actually make it biweekly\". The earlier messages are sent to the model as context, and the session is returned with the revised item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Refine the item of a generation session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Generation session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Requested change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.GenerationMessageRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the request is accounted to; defaults to the client address",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.GenerationSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Generation session not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Generation session has reached its message limit",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "429": {
+                        "description": "Daily LLM quota used up",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "502": {
+                        "description": "LLM service returned an error, or output that was still invalid after one repair",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "LLM service or generation sessions not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "504": {
+                        "description": "LLM service timed out",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/accept": {
+            "post": {
+                "description": "Join the organization an invitation is for, with the invitation's role. The user accepting it is named by the X-User-ID header and must have the email address the invitation was sent to. Each invitation can be accepted once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Accept an invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of the user accepting the invitation",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitation token",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.AcceptInvitationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.OrganizationMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "The invitation was sent to another email address",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Invitation not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Invitation already accepted",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "410": {
+                        "description": "Invitation expired",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add member",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/llm-usage": {
+            "get": {
+                "description": "Retrieve the usage recorded for each generation request, oldest first: the requesting user, model, tokens and latency. Defaults to the current UTC day. Administrators see every user's usage; everyone else only their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Get LLM usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return the usage of this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, inclusive (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive (RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.LLMUsage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Another user's usage was asked for by a non-administrator",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/llm-usage/summary": {
+            "get": {
+                "description": "Total the generation requests and tokens of each user over a period. Defaults to the current UTC day, the period quotas apply to. Administrators see every user's totals; everyone else only their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Get LLM usage per user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only total the usage of this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, inclusive (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive (RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.LLMUsageTotals"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid period",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Another user's usage was asked for by a non-administrator",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "description": "Retrieve all organizations in ID order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get all organizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a team, such as a household or an on-call rotation, that scheduled items and todos can belong to",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization to create",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create organization",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}": {
+            "get": {
+                "description": "Get a specific organization by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get an organization by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the name and description of an organization",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Update an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated organization",
+                        "name": "organization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an organization along with its memberships. Organizations that scheduled items or todos still belong to can't be deleted; move or delete those first.",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled items or todos still belong to the organization",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/invitations": {
+            "get": {
+                "description": "List the invitations to an organization, accepted or not, in the order they were created. Tokens aren't included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List an organization's invitations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.OrganizationInvitation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only owners can manage the organization's invitations",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Invite whoever owns an email address to join an organization with a role. The response holds the invitation's token, which is only returned once; send it to the invited user so they can accept it. Invitations expire after 7 days unless expiresAt says otherwise. When the request names a user in the X-User-ID header, they must be an owner of the organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Invite a user to an organization",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Invitation with the email, role and optional expiry",
+                        "name": "invitation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.OrganizationInvitation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.OrganizationInvitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only owners can manage the organization's invitations",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create invitation",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/invitations/{invitationId}": {
+            "delete": {
+                "description": "Delete an invitation so its token can no longer be accepted. Users who already accepted it stay members.",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Revoke an invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Invitation ID",
+                        "name": "invitationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only owners can manage the organization's invitations",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or invitation not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members": {
+            "get": {
+                "description": "List the users that belong to an organization and their roles, in the order they joined",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List an organization's members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.OrganizationMember"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{userId}": {
+            "put": {
+                "description": "Add a user to an organization with the given role, owner or member, or change the role of a user who already belongs to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Add or update a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Membership with the user's role",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.OrganizationMember"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.OrganizationMember"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to set member",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a user from an organization",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Remove a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or member not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items": {
+            "get": {
+                "description": "Retrieve all scheduled items from the store, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per item. With the cursor parameter, the items are returned a page at a time in next execution order, ties broken by ID, and the cursor of the next page is sent in the X-Next-Cursor header. Pages leave out the items the user can't see, so they can be shorter than the limit before the last one.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Get all scheduled items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort by id, createdAt, updatedAt or nextExecutionAt; prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page by cursor: empty for the first page, or the cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page when paging by cursor",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, overriding the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only list the scheduled items of this organization",
+                        "name": "organizationId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request, who only sees their own items, those shared with them and those without an owner",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort field, organization ID, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve page",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new scheduled item with the given details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Create a scheduled item",
+                "parameters": [
+                    {
+                        "description": "Scheduled item to create",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request, who owns the item",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Tenant limit of scheduled items reached",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/events": {
+            "get": {
+                "description": "Stream the changes and executions of the scheduled items the caller may see using server-sent events. Each event's SSE type is the event type (scheduled_item.created, updated, rescheduled, deleted, executed, failed or skipped) and its data is the event as JSON. Reconnecting clients send Last-Event-ID to receive the recent events they missed.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream scheduled item events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the last event received, to resume after a disconnect",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Last-Event-ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Streaming not supported",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/next": {
+            "get": {
+                "description": "Retrieve the next scheduled items ordered by execution time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Get next scheduled items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Maximum number of items to return",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/{id}": {
+            "get": {
+                "description": "Get a specific scheduled item by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Get a scheduled item by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a scheduled item by its ID. The request must include the version it was based on; if the item has changed since, the update is rejected with 409 Conflict.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Update a scheduled item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated scheduled item",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Scheduled item is only shared with the user as a viewer",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled item was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a scheduled item by its ID",
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Delete a scheduled item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner can delete the item",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7386) to a scheduled item: only the fields in the patch change, and null clears a field. The next execution time is recalculated when startsAt, repeats, cronExpression, expiration or jitterSeconds change. Include version to reject the patch with 409 Conflict if the item has changed since it was read.",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Partially update a scheduled item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON Merge Patch of scheduled item fields",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Scheduled item is only shared with the user as a viewer",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled item was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/{id}/modify-from-prompt": {
+            "post": {
+                "description": "Use AI to turn an instruction such as \"move it to Fridays at 6pm\" into a JSON Merge Patch of the item's title, description, schedule and action, and apply it like PATCH /scheduled-items/{id}. The response shows the item before and after so the change can be confirmed; with dryRun=true the change is only previewed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "generation"
+                ],
+                "summary": "Modify a scheduled item from a text prompt",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Modification request with instruction and timezone",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.ModifyPromptRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview the modification without applying it",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the request is accounted to; defaults to the client address",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.ModifyPromptResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request, or the generated modification is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Scheduled item is only shared with the user as a viewer",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled item was modified concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "429": {
+                        "description": "Daily LLM quota used up",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "502": {
+                        "description": "LLM service returned an error, or output that was still invalid after one repair",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "LLM service not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "504": {
+                        "description": "LLM service timed out",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/{id}/run": {
+            "post": {
+                "description": "Execute a scheduled item's action immediately and record an execution log without changing its next execution time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Run a scheduled item now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ExecutionLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Scheduled item is only shared with the user as a viewer",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/{id}/shares": {
+            "get": {
+                "description": "List the users a scheduled item is shared with and their roles, in the order they were granted access",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "List a scheduled item's shares",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.ItemShare"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Sharing not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-items/{id}/shares/{userId}": {
+            "put": {
+                "description": "Share a scheduled item with a user as a viewer, who can read it, or an editor, who can also change and run it, or change the role of a user it is already shared with. Only the item's owner can share it, and only items with an owner can be shared.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Share a scheduled item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share with the user's role",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ItemShare"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.ItemShare"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner can share the item",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "409": {
+                        "description": "Scheduled item has no owner",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to share scheduled item",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Sharing not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke a user's access to a scheduled item. The owner can revoke anyone's access, and users can remove items shared with them.",
+                "tags": [
+                    "scheduled-items"
+                ],
+                "summary": "Stop sharing a scheduled item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the user making the request",
+                        "name": "X-User-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Only the owner can revoke the access of others",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Scheduled item or share not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "503": {
+                        "description": "Sharing not available",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduler-instances": {
+            "get": {
+                "description": "List the scheduler instances that have sent heartbeats, marking those that have gone three intervals without a successful tick as stalled. Responds with 503 when no instance is ticking, so a crashed or wedged scheduler can be alerted on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Get scheduler instances",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.SchedulerInstancesStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "No scheduler instance is ticking",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_handlers.SchedulerInstancesStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduler-instances/metrics": {
+            "get": {
+                "description": "Scheduler health in the Prometheus text format: scheduler_up is 1 while at least one instance is ticking, and each instance reports scheduler_instance_stalled and the time of its last successful tick.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Get scheduler instance metrics",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo-items": {
+            "get": {
+                "description": "Retrieve all todo items from the store, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per item. With the cursor parameter, the items are returned a page at a time in ID order, and the cursor of the next page is sent in the X-Next-Cursor header.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Get all todo items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort by id, createdAt or updatedAt; prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only list the items that are checked (true) or open (false)",
+                        "name": "checked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page by cursor: empty for the first page, or the cursor of the page to return",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items per page when paging by cursor",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, overriding the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only list the todo items of this organization",
+                        "name": "organizationId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort field, checked filter, organization ID, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve page",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new todo item with the given details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Create a todo item",
+                "parameters": [
+                    {
+                        "description": "Todo item to create",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo-items/{id}": {
+            "get": {
+                "description": "Get a specific todo item by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Get a todo item by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a todo item by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Update a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated todo item",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a todo item by its ID",
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Delete a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7386) to a todo item, for example {\"checked\": true}; only the fields in the patch change",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo-items"
+                ],
+                "summary": "Partially update a todo item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON Merge Patch of todo item fields",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.TodoItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieve all users from the store",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sort by id, createdAt or updatedAt; prefix with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.User"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort field",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user with the given details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user",
+                "parameters": [
+                    {
+                        "description": "User to create",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Get a specific user by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a user by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user by their ID",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/devices": {
+            "get": {
+                "description": "List the push tokens of the devices a user registered, in registration order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List a user's devices",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.DeviceToken"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register the push token of a user's device, so the user is pushed a notification when the scheduler creates a todo for them. Registering a token again refreshes it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Device platform (fcm or apns) and token",
+                        "name": "device",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.DeviceToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.DeviceToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to register device",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/devices/{token}": {
+            "delete": {
+                "description": "Stop pushing notifications to a device, such as when the user signs out of the app",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Unregister a device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User or device not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notification-preferences": {
+            "get": {
+                "description": "Get the channels, events and quiet hours a user is notified with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a user's notification preferences",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.NotificationPreference"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or no preferences set",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Create or replace the channels (email, slack), events (execution, failure, reminder, digest) and quiet hours a user is notified with. Items listing the user follow these preferences instead of their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set a user's notification preferences",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notification preferences",
+                        "name": "preference",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.NotificationPreference"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.NotificationPreference"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save preferences",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a user's preferences, so items listing the user email them following the items' own settings",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a user's notification preferences",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or no preferences set",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Retrieve all registered webhooks. Secrets are not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get all webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a URL to receive the given event types. Each delivery is a POST of the event as JSON, signed in the X-Periodic-Signature header with \"sha256=\" and the hex HMAC-SHA256 of the body keyed with the webhook secret. A secret is generated when none is given; it is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook to register",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "403": {
+                        "description": "Tenant limit of webhooks reached",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Get a specific webhook by its ID. The secret is not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get a webhook by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a webhook by its ID. The secret is kept when none is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a webhook and its delivery log by its ID",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No content"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Get the most recent delivery attempts of a webhook, newest first. Every retry is a separate entry.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Maximum number of deliveries to return (at most 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/periodic-api_internal_models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_problem.Details"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket that receives a JSON message for each todo item change, including todos created by the scheduler, and each change of the scheduled items the caller may see. Use the types parameter to receive only some event types.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Subscribe to change events over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated event types to receive, such as todo.created,todo.updated",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/periodic-api_internal_events.Event"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "periodic-api_internal_db.PoolStats": {
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "type": "integer"
+                },
+                "inUse": {
+                    "type": "integer"
+                },
+                "maxIdleClosed": {
+                    "type": "integer"
+                },
+                "maxIdleTimeClosed": {
+                    "type": "integer"
+                },
+                "maxLifetimeClosed": {
+                    "type": "integer"
+                },
+                "maxOpenConnections": {
+                    "type": "integer"
+                },
+                "openConnections": {
+                    "type": "integer"
+                },
+                "waitCount": {
+                    "type": "integer"
+                },
+                "waitDuration": {
+                    "type": "string",
+                    "example": "1.5s"
+                }
+            }
+        },
+        "periodic-api_internal_events.Event": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Data is the created or updated model, or {\"id\": ...} for deletions",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "ID increases with every event published on the bus",
+                    "type": "integer",
+                    "example": 42
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-01T09:00:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "todo.created"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.AcceptInvitationRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "6f1c0b9e..."
+                }
+            }
+        },
+        "periodic-api_internal_handlers.GeneratePromptRequest": {
+            "type": "object",
+            "required": [
+                "prompt"
+            ],
+            "properties": {
+                "prompt": {
+                    "type": "string",
+                    "example": "Schedule a weekly team meeting every Tuesday at 2 PM"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.GenerationMessageRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Actually make it biweekly"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.ImpersonationToken": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "periodic-api_internal_handlers.ModifyPromptRequest": {
+            "type": "object",
+            "required": [
+                "instruction"
+            ],
+            "properties": {
+                "instruction": {
+                    "type": "string",
+                    "example": "Move it to Fridays at 6pm"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.ModifyPromptResponse": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                },
+                "applied": {
+                    "description": "Applied is false when the modification was only previewed with dryRun=true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "before": {
+                    "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                },
+                "patch": {
+                    "description": "Patch is the JSON Merge Patch generated from the instruction",
+                    "type": "object"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.NotificationTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Scheduled item \"{{.Item.Title}}\" ran at {{.Execution.ExecutedAt}}."
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "execution"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "{{if .Succeeded}}Executed{{else}}Failed{{end}}: {{.Item.Title}}"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.NotificationTemplatePreview": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Team daily standup meeting to discuss progress"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "Ran Daily standup meeting"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.NotificationTemplatePreviewRequest": {
+            "type": "object",
+            "required": [
+                "kind"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "{{.Item.Description}} {{.Links.Item}}"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "execution"
+                },
+                "scheduledItemId": {
+                    "description": "ScheduledItemID renders an execution or push notification about this item instead of a sample one",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "Status is the outcome of the execution previewed, success or error",
+                    "type": "string",
+                    "example": "success"
+                },
+                "subject": {
+                    "description": "Subject and Body replace the configured templates when set, to try out changes",
+                    "type": "string",
+                    "example": "Ran {{.Item.Title}}"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.SchedulerInstance": {
+            "type": "object",
+            "properties": {
+                "errorCount": {
+                    "type": "integer"
+                },
+                "instanceId": {
+                    "type": "string"
+                },
+                "intervalMs": {
+                    "description": "IntervalMs is the instance's processing interval, which stalls are measured against",
+                    "type": "integer"
+                },
+                "itemsProcessed": {
+                    "type": "integer"
+                },
+                "lastSuccessAt": {
+                    "description": "LastSuccessAt is the last tick that claimed the due items without error",
+                    "type": "string"
+                },
+                "lastTickAt": {
+                    "type": "string"
+                },
+                "stalled": {
+                    "description": "Stalled is set when the instance has gone three intervals without a successful tick",
+                    "type": "boolean",
+                    "example": false
+                },
+                "startedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "periodic-api_internal_handlers.SchedulerInstancesStatus": {
+            "type": "object",
+            "properties": {
+                "healthy": {
+                    "description": "Healthy is set when at least one instance is ticking successfully",
+                    "type": "boolean",
+                    "example": true
+                },
+                "instances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/periodic-api_internal_handlers.SchedulerInstance"
+                    }
+                }
+            }
+        },
+        "periodic-api_internal_migrations.MigrationFile": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Name is the migration's name, such as add_user_deactivated_at",
+                    "type": "string",
+                    "example": "add_user_deactivated_at"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 28
+                }
+            }
+        },
+        "periodic-api_internal_migrations.Report": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/periodic-api_internal_migrations.MigrationFile"
+                    }
+                },
+                "dirty": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "expected": {
+                    "type": "integer",
+                    "example": 19
+                },
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/periodic-api_internal_migrations.MigrationFile"
+                    }
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 19
+                }
+            }
+        },
+        "periodic-api_internal_models.AuditLogEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "actor": {
+                    "description": "Actor identifies the user making the change, from X-User-ID or the client address",
+                    "type": "string",
+                    "example": "42"
+                },
+                "after": {
+                    "description": "After is the entity after the change; it is omitted for deletions",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before is the entity before the change; it is omitted for creations",
+                    "type": "object"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "entityId": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "entityType": {
+                    "type": "string",
+                    "example": "scheduled_item"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "periodic-api_internal_models.DeviceToken": {
+            "type": "object",
+            "required": [
+                "platform",
+                "token"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "Platform is fcm for Firebase Cloud Messaging or apns for the Apple Push Notification service",
+                    "type": "string",
+                    "example": "fcm"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "fMEP0vJqS0:APA91bHqX3"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "periodic-api_internal_models.ExecutionLog": {
+            "type": "object",
+            "properties": {
+                "errorMessage": {
+                    "type": "string"
+                },
+                "executedAt": {
+                    "type": "string"
+                },
+                "executionKey": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "requestId": {
+                    "type": "string"
+                },
+                "scheduledItemId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "todoItemId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "periodic-api_internal_models.GenerationMessage": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Actually make it biweekly"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:05:00Z"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
+        "periodic-api_internal_models.GenerationSession": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "example": "2024-01-01T09:05:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "item": {
+                    "description": "Item is the latest generated item, which committing the session creates",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/periodic-api_internal_models.ScheduledItem"
+                        }
+                    ]
+                },
+                "messages": {
+                    "description": "Messages alternate between the user's requests and the item JSON generated in reply",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/periodic-api_internal_models.GenerationMessage"
+                    }
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:05:00Z"
+                },
+                "userId": {
+                    "description": "UserID identifies the user the session's LLM usage is accounted to",
+                    "type": "string",
+                    "example": "42"
+                }
+            }
+        },
+        "periodic-api_internal_models.ItemShare": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is viewer or editor",
+                    "type": "string",
+                    "example": "viewer"
+                },
+                "scheduledItemId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "periodic-api_internal_models.LLMUsage": {
+            "type": "object",
+            "properties": {
+                "calls": {
+                    "description": "Calls counts the attempts to call the model, including retries and repairs",
+                    "type": "integer",
+                    "example": 1
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "inputTokens": {
+                    "type": "integer",
+                    "example": 850
+                },
+                "latencyMs": {
+                    "type": "integer",
+                    "example": 1450
+                },
+                "model": {
+                    "type": "string",
+                    "example": "us.amazon.nova-lite-v1:0"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "generate"
+                },
+                "outputTokens": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "bedrock"
+                },
+                "succeeded": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "userId": {
+                    "description": "UserID identifies the requesting user, from X-User-ID or the client address",
+                    "type": "string",
+                    "example": "42"
+                }
+            }
+        },
+        "periodic-api_internal_models.LLMUsageTotals": {
+            "type": "object",
+            "properties": {
+                "inputTokens": {
+                    "type": "integer",
+                    "example": 10200
+                },
+                "outputTokens": {
+                    "type": "integer",
+                    "example": 1440
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "42"
+                }
+            }
+        },
+        "periodic-api_internal_models.NotificationPreference": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "{{.Item.Title}}: {{.Error}}"
+                },
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email",
+                        "slack"
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "failure",
+                        "digest"
+                    ]
+                },
+                "quietHoursEnd": {
+                    "type": "string",
+                    "example": "07:00"
+                },
+                "quietHoursStart": {
+                    "description": "QuietHoursStart and QuietHoursEnd are the local times (HH:MM) between which nothing is\nsent; the quiet hours may span midnight",
+                    "type": "string",
+                    "example": "22:00"
+                },
+                "slackUserId": {
+                    "description": "SlackUserID is the Slack member the slack channel sends direct messages to",
+                    "type": "string",
+                    "example": "U0123456789"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "{{.Item.Title}} needs attention"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA time zone of the quiet hours, UTC when empty",
+                    "type": "string",
+                    "example": "Europe/London"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 1
                 }
             }
-        }
-    },
-    "definitions": {
-        "periodic-api_internal_handlers.GeneratePromptRequest": {
+        },
+        "periodic-api_internal_models.NotificationSettings": {
             "type": "object",
             "properties": {
-                "prompt": {
+                "body": {
                     "type": "string",
-                    "example": "Schedule a weekly team meeting every Tuesday at 2 PM"
+                    "example": "{{.Item.Title}} failed at {{.Execution.ExecutedAt}}: {{.Error}}"
                 },
-                "timezone": {
+                "email": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ops@example.com"
+                    ]
+                },
+                "onFailure": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "onSuccess": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "slack": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "slackChannel": {
                     "type": "string",
-                    "example": "America/New_York"
+                    "example": "#ops-alerts"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "{{.Item.Title}} failed"
+                },
+                "userIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "periodic-api_internal_models.Organization": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Platform team on-call"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "On-call rotation"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "periodic-api_internal_models.OrganizationInvitation": {
+            "type": "object",
+            "required": [
+                "email",
+                "role"
+            ],
+            "properties": {
+                "acceptedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "bob@example.com"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "organizationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "description": "Role is owner or member",
+                    "type": "string",
+                    "example": "member"
+                },
+                "token": {
+                    "description": "Token is only returned when the invitation is created; only its hash is stored",
+                    "type": "string",
+                    "example": "6f1c0b9e..."
+                }
+            }
+        },
+        "periodic-api_internal_models.OrganizationMember": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "organizationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "description": "Role is owner or member",
+                    "type": "string",
+                    "example": "member"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
         "periodic-api_internal_models.ScheduledItem": {
             "type": "object",
+            "required": [
+                "startsAt",
+                "title"
+            ],
             "properties": {
+                "actionConfig": {
+                    "type": "object"
+                },
+                "actionType": {
+                    "type": "string",
+                    "example": "todo"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
                 "cronExpression": {
                     "type": "string",
                     "example": "0 9 * * 1-5"
@@ -654,14 +4653,37 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "jitterSeconds": {
+                    "type": "integer",
+                    "example": 300
+                },
                 "nextExecutionAt": {
                     "type": "string",
                     "example": "2024-01-02T09:00:00Z"
                 },
+                "notifications": {
+                    "$ref": "#/definitions/periodic-api_internal_models.NotificationSettings"
+                },
+                "organizationId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "paused": {
+                    "type": "boolean",
+                    "example": false
+                },
                 "repeats": {
                     "type": "boolean",
                     "example": true
                 },
+                "requestId": {
+                    "type": "string",
+                    "example": "3f2b8c1e9a7d4f6012ab34cd56ef7890"
+                },
                 "startsAt": {
                     "type": "string",
                     "example": "2024-01-01T09:00:00Z"
@@ -669,6 +4691,80 @@ const docTemplate = `{
                 "title": {
                     "type": "string",
                     "example": "Daily standup meeting"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "periodic-api_internal_models.TenantLimits": {
+            "type": "object",
+            "properties": {
+                "maxDailyGenerations": {
+                    "description": "MaxDailyGenerations caps the generation requests the tenant's users may make per UTC day",
+                    "type": "integer",
+                    "example": 50
+                },
+                "maxScheduledItems": {
+                    "description": "MaxScheduledItems caps the scheduled items the tenant may have at once",
+                    "type": "integer",
+                    "example": 100
+                },
+                "maxWebhooks": {
+                    "description": "MaxWebhooks caps the webhooks the tenant may have at once",
+                    "type": "integer",
+                    "example": 5
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt is when the limits were last adjusted; it is omitted for the defaults",
+                    "type": "string"
+                }
+            }
+        },
+        "periodic-api_internal_models.TenantSummary": {
+            "type": "object",
+            "properties": {
+                "generationsToday": {
+                    "description": "GenerationsToday counts the generation requests the tenant's users made in the current UTC day",
+                    "type": "integer",
+                    "example": 7
+                },
+                "limits": {
+                    "description": "Limits are the limits the tenant is held to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/periodic-api_internal_models.TenantLimits"
+                        }
+                    ]
+                },
+                "scheduledItems": {
+                    "type": "integer",
+                    "example": 48
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "acme"
+                },
+                "todoItems": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "users": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "webhooks": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
@@ -678,28 +4774,214 @@ const docTemplate = `{
                 "checked": {
                     "type": "boolean"
                 },
+                "createdAt": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "organizationId": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "text": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
         "periodic-api_internal_models.User": {
             "type": "object",
+            "required": [
+                "username"
+            ],
             "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deactivatedAt": {
+                    "description": "DeactivatedAt is when an administrator deactivated the user, who can no longer make\nrequests or be notified; it is omitted for active users and can't be set through /users",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email is where the user is sent notifications about the items that list them",
+                    "type": "string",
+                    "example": "alice@example.com"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "passwordHash": {
+                    "type": "string",
+                    "format": "byte"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "periodic-api_internal_models.Webhook": {
+            "type": "object",
+            "required": [
+                "eventTypes",
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "eventTypes": {
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "type": "string"
+                    },
+                    "example": [
+                        "scheduled_item.executed",
+                        "scheduled_item.failed",
+                        "todo.created"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "ownerId": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "secret": {
+                    "description": "Secret signs deliveries; it is generated when not provided and only returned on creation",
+                    "type": "string",
+                    "example": "3f1c9a..."
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/periodic"
+                }
+            }
+        },
+        "periodic-api_internal_models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "deliveredAt": {
+                    "type": "string",
+                    "example": "2024-01-01T09:00:00Z"
+                },
+                "durationMs": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "eventType": {
+                    "type": "string",
+                    "example": "scheduled_item.executed"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "statusCode": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "succeeded": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "webhookId": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "periodic-api_internal_problem.Details": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "Scheduled item not found"
+                },
+                "errors": {
+                    "description": "Errors lists the invalid fields of a validation problem",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/periodic-api_internal_problem.FieldError"
                     }
                 },
-                "username": {
+                "instance": {
+                    "type": "string",
+                    "example": "/scheduled-items/42"
+                },
+                "requestId": {
+                    "description": "RequestID identifies the request in the server logs",
                     "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "about:blank"
+                }
+            }
+        },
+        "periodic-api_internal_problem.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "jitterSeconds"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "cannot be negative"
+                }
+            }
+        },
+        "periodic-api_internal_store.CacheStats": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "integer"
+                },
+                "hitRate": {
+                    "description": "HitRate is the share of reads answered from the cache, from 0 to 1",
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
                 }
             }
         }
@@ -710,7 +4992,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
 	Title:            "Periodic API",
 	Description:      "A REST API server for managing Periodic items with support for PostgreSQL and in-memory storage.",
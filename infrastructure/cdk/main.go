@@ -86,20 +86,25 @@ func NewInfrastructureStack(scope constructs.Construct, id string, props *Infras
 		}),
 		Environment: &map[string]*string{
 			"USE_POSTGRES_DB": jsii.String("true"),
-		},
-		Secrets: &map[string]awsecs.Secret{
-			"DB_HOST":     awsecs.Secret_FromSecretsManager(dbCluster.Secret(), jsii.String("host")),
-			"DB_PORT":     awsecs.Secret_FromSecretsManager(dbCluster.Secret(), jsii.String("port")),
-			"DB_NAME":     awsecs.Secret_FromSecretsManager(dbCluster.Secret(), jsii.String("dbname")),
-			"DB_USER":     awsecs.Secret_FromSecretsManager(dbCluster.Secret(), jsii.String("username")),
-			"DB_PASSWORD": awsecs.Secret_FromSecretsManager(dbCluster.Secret(), jsii.String("password")),
+			// The app reads the connection details from the secret itself, so rotation needs no redeploy
+			"DB_SECRET_ARN": dbCluster.Secret().SecretArn(),
 		},
 		Logging: awsecs.LogDrivers_AwsLogs(&awsecs.AwsLogDriverProps{
 			StreamPrefix: jsii.String("periodic-api"),
 			LogRetention: awslogs.RetentionDays_ONE_WEEK,
 		}),
+		// The image has no curl, so the container checks /readyz with its own binary
+		HealthCheck: &awsecs.HealthCheck{
+			Command:     jsii.Strings("CMD", "./healthcheck"),
+			Interval:    awscdk.Duration_Seconds(jsii.Number(30)),
+			Timeout:     awscdk.Duration_Seconds(jsii.Number(5)),
+			Retries:     jsii.Number(3),
+			StartPeriod: awscdk.Duration_Seconds(jsii.Number(30)),
+		},
 	})
 
+	dbCluster.Secret().GrantRead(taskDefinition.TaskRole(), nil)
+
 	container.AddPortMappings(&awsecs.PortMapping{
 		ContainerPort: jsii.Number(8080),
 		Protocol:      awsecs.Protocol_TCP,
@@ -132,7 +137,7 @@ func NewInfrastructureStack(scope constructs.Construct, id string, props *Infras
 		Vpc:        vpc,
 		TargetType: awselasticloadbalancingv2.TargetType_IP,
 		HealthCheck: &awselasticloadbalancingv2.HealthCheck{
-			Path:                    jsii.String("/scheduled-items"),
+			Path:                    jsii.String("/readyz"),
 			HealthyHttpCodes:        jsii.String("200"),
 			Interval:                awscdk.Duration_Seconds(jsii.Number(30)),
 			Timeout:                 awscdk.Duration_Seconds(jsii.Number(5)),
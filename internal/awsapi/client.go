@@ -0,0 +1,98 @@
+// Package awsapi makes SigV4-signed requests to AWS service APIs that are small enough to
+// call directly, which keeps their SDK modules out of the build.
+package awsapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Client makes SigV4-signed requests to an AWS service API
+type Client struct {
+	httpClient  *http.Client
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	service     string
+	region      string
+	endpoint    string
+}
+
+// partitionDNSSuffixes are the domains of the public endpoints in each AWS partition
+var partitionDNSSuffixes = map[string]string{
+	"aws":        "amazonaws.com",
+	"aws-cn":     "amazonaws.com.cn",
+	"aws-us-gov": "amazonaws.com",
+	"aws-iso":    "c2s.ic.gov",
+	"aws-iso-b":  "sc2s.sgov.gov",
+}
+
+// PartitionEndpoint returns the public regional endpoint of service in the given partition,
+// as named in ARNs, falling back to the commercial partition's domain for unknown ones
+func PartitionEndpoint(partition string, service string, region string) string {
+	suffix, ok := partitionDNSSuffixes[partition]
+	if !ok {
+		suffix = partitionDNSSuffixes["aws"]
+	}
+	return fmt.Sprintf("https://%s.%s.%s", service, region, suffix)
+}
+
+// NewClient creates a client for the given service, using endpoint when set instead of
+// the public regional endpoint in the commercial partition
+func NewClient(cfg aws.Config, service string, region string, endpoint string) *Client {
+	if endpoint == "" {
+		endpoint = PartitionEndpoint("aws", service, region)
+	}
+	return &Client{
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		credentials: cfg.Credentials,
+		signer:      v4.NewSigner(),
+		service:     service,
+		region:      region,
+		endpoint:    endpoint,
+	}
+}
+
+// Post signs and sends a request and returns the response body, or an error for non-2xx responses
+func (c *Client) Post(ctx context.Context, body []byte, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", c.service, err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	credentials, err := c.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	payloadHash := sha256.Sum256(body)
+	if err := c.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(payloadHash[:]), c.service, c.region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign %s request: %w", c.service, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", c.service, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", c.service, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d: %s", c.service, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
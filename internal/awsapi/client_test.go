@@ -0,0 +1,22 @@
+package awsapi
+
+import "testing"
+
+func TestPartitionEndpoint(t *testing.T) {
+	tests := []struct {
+		partition string
+		region    string
+		want      string
+	}{
+		{partition: "aws", region: "us-east-1", want: "https://secretsmanager.us-east-1.amazonaws.com"},
+		{partition: "aws-cn", region: "cn-north-1", want: "https://secretsmanager.cn-north-1.amazonaws.com.cn"},
+		{partition: "aws-us-gov", region: "us-gov-west-1", want: "https://secretsmanager.us-gov-west-1.amazonaws.com"},
+		{partition: "aws-iso", region: "us-iso-east-1", want: "https://secretsmanager.us-iso-east-1.c2s.ic.gov"},
+		{partition: "aws-future", region: "xx-east-1", want: "https://secretsmanager.xx-east-1.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		if got := PartitionEndpoint(tt.partition, "secretsmanager", tt.region); got != tt.want {
+			t.Errorf("PartitionEndpoint(%q, %q) = %q, want %q", tt.partition, tt.region, got, tt.want)
+		}
+	}
+}
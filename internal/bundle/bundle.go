@@ -0,0 +1,243 @@
+// Package bundle exports a tenant's data to portable bundles and imports them again, for
+// backups, environment clones and moving data between the storage backends
+package bundle
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/store"
+)
+
+// Version is the version of the bundle layout, raised whenever it changes incompatibly
+const Version = 1
+
+// Formats bundles are written in
+const (
+	// FormatJSON is a single JSON document with the header and an array per kind of record
+	FormatJSON = "json"
+	// FormatNDJSON is a JSON object per line: the header first, then a record per line
+	FormatNDJSON = "ndjson"
+)
+
+// Kinds of records in a bundle, in the order they are written. Records only refer to
+// records of kinds written before them, apart from execution logs referring to todo items.
+const (
+	KindHeader        = "header"
+	KindUser          = "user"
+	KindTodoItem      = "todo_item"
+	KindScheduledItem = "scheduled_item"
+	KindExecutionLog  = "execution_log"
+)
+
+// sections are the JSON format's array of each kind of record
+var sections = map[string]string{
+	KindUser:          "users",
+	KindTodoItem:      "todoItems",
+	KindScheduledItem: "scheduledItems",
+	KindExecutionLog:  "executionLogs",
+}
+
+// Header describes a bundle
+type Header struct {
+	Version int `json:"version"`
+	// SchemaVersion is the database schema version of the exporting build
+	SchemaVersion uint      `json:"schemaVersion"`
+	Tenant        string    `json:"tenant"`
+	ExportedAt    time.Time `json:"exportedAt"`
+	// PasswordHashes tells whether the users' password hashes were exported
+	PasswordHashes bool `json:"passwordHashes"`
+}
+
+// Stores are the stores a bundle is exported from or imported into
+type Stores struct {
+	Users          store.UserStore
+	TodoItems      store.TodoItemStore
+	ScheduledItems store.ScheduledItemStore
+	ExecutionLogs  store.ExecutionLogStore
+	// Transactor makes an import all or nothing; without one, records loaded before a
+	// failure stay
+	Transactor store.Transactor
+	// Restorer loads records with their exported IDs, for imports preserving them
+	Restorer store.Restorer
+}
+
+// Options are how a bundle is exported
+type Options struct {
+	// Format is FormatJSON or FormatNDJSON
+	Format string
+	// Tenant is the tenant whose data is exported; DefaultTenant when empty
+	Tenant string
+	// OmitPasswordHashes leaves the users' password hashes out of the bundle
+	OmitPasswordHashes bool
+}
+
+// Counts are the number of records of each kind in a bundle
+type Counts struct {
+	Users          int `json:"users"`
+	TodoItems      int `json:"todoItems"`
+	ScheduledItems int `json:"scheduledItems"`
+	ExecutionLogs  int `json:"executionLogs"`
+}
+
+// Export writes every user, todo item, scheduled item and execution log of a tenant to w
+// as a bundle, record by record, and returns how many of each it wrote
+func Export(ctx context.Context, w io.Writer, stores Stores, opts Options) (Counts, error) {
+	tenant := opts.Tenant
+	if tenant == "" {
+		tenant = store.DefaultTenant
+	}
+	// Export everything whoever the context's user is
+	ctx = store.WithoutUser(store.WithTenant(ctx, tenant))
+
+	writer, err := NewWriter(w, opts.Format, Header{
+		Version:        Version,
+		SchemaVersion:  migrations.SchemaVersion,
+		Tenant:         tenant,
+		ExportedAt:     time.Now().UTC(),
+		PasswordHashes: !opts.OmitPasswordHashes,
+	})
+	if err != nil {
+		return Counts{}, err
+	}
+
+	var counts Counts
+	if err := writer.Section(KindUser); err != nil {
+		return counts, err
+	}
+	for _, user := range stores.Users.GetAllUsers(ctx) {
+		if opts.OmitPasswordHashes {
+			user.PasswordHash = nil
+		}
+		if err := writer.Write(user); err != nil {
+			return counts, err
+		}
+		counts.Users++
+	}
+
+	if err := writer.Section(KindTodoItem); err != nil {
+		return counts, err
+	}
+	for _, todo := range stores.TodoItems.GetAllTodoItems(ctx) {
+		if err := writer.Write(todo); err != nil {
+			return counts, err
+		}
+		counts.TodoItems++
+	}
+
+	if err := writer.Section(KindScheduledItem); err != nil {
+		return counts, err
+	}
+	for _, item := range stores.ScheduledItems.GetAllScheduledItems(ctx) {
+		if err := writer.Write(item); err != nil {
+			return counts, err
+		}
+		counts.ScheduledItems++
+	}
+
+	if err := writer.Section(KindExecutionLog); err != nil {
+		return counts, err
+	}
+	for _, log := range stores.ExecutionLogs.GetAllExecutionLogs(ctx) {
+		if err := writer.Write(log); err != nil {
+			return counts, err
+		}
+		counts.ExecutionLogs++
+	}
+
+	return counts, writer.Close()
+}
+
+// Writer writes a bundle record by record, so bundles of any size can be streamed
+type Writer struct {
+	out     *bufio.Writer
+	format  string
+	section string
+	first   bool
+}
+
+// NewWriter starts a bundle in format on w with its header
+func NewWriter(w io.Writer, format string, header Header) (*Writer, error) {
+	if format != FormatJSON && format != FormatNDJSON {
+		return nil, fmt.Errorf("unknown bundle format %q, use %s or %s", format, FormatJSON, FormatNDJSON)
+	}
+	writer := &Writer{out: bufio.NewWriter(w), format: format}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatNDJSON {
+		return writer, writer.line(KindHeader, data)
+	}
+	writer.out.WriteString(`{"header":`)
+	_, err = writer.out.Write(data)
+	return writer, err
+}
+
+// Section starts the records of a kind, which must not have been started before
+func (w *Writer) Section(kind string) error {
+	name, ok := sections[kind]
+	if !ok {
+		return fmt.Errorf("unknown kind of record %q", kind)
+	}
+	if w.format == FormatJSON {
+		if w.section != "" {
+			w.out.WriteString("]")
+		}
+		if _, err := fmt.Fprintf(w.out, `,%q:[`, name); err != nil {
+			return err
+		}
+	}
+	w.section = kind
+	w.first = true
+	return nil
+}
+
+// Write writes a record of the kind of the current section
+func (w *Writer) Write(record any) error {
+	if w.section == "" {
+		return fmt.Errorf("record written before a section was started")
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %w", w.section, err)
+	}
+	if w.format == FormatNDJSON {
+		return w.line(w.section, data)
+	}
+	if !w.first {
+		w.out.WriteString(",")
+	}
+	w.first = false
+	_, err = w.out.Write(data)
+	return err
+}
+
+// Close ends the bundle and flushes what is buffered
+func (w *Writer) Close() error {
+	if w.format == FormatJSON {
+		if w.section != "" {
+			w.out.WriteString("]")
+		}
+		w.out.WriteString("}\n")
+	}
+	return w.out.Flush()
+}
+
+// line writes an NDJSON line holding a record of kind
+func (w *Writer) line(kind string, data json.RawMessage) error {
+	line, err := json.Marshal(struct {
+		Kind string          `json:"kind"`
+		Data json.RawMessage `json:"data"`
+	}{kind, data})
+	if err != nil {
+		return err
+	}
+	w.out.Write(line)
+	return w.out.WriteByte('\n')
+}
@@ -0,0 +1,113 @@
+package bundle
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// newTestStores returns memory stores with a user, a todo item, a scheduled item and an
+// execution log in tenant, and a scheduled item in another tenant
+func newTestStores(t *testing.T, tenant string) Stores {
+	t.Helper()
+	stores := Stores{
+		Users:          store.NewMemoryUserStore(),
+		TodoItems:      store.NewMemoryTodoItemStore(),
+		ScheduledItems: store.NewMemoryScheduledItemStore(),
+		ExecutionLogs:  store.NewMemoryExecutionLogStore(),
+	}
+	ctx := store.WithTenant(context.Background(), tenant)
+	stores.Users.CreateUser(ctx, models.User{Username: "alice", PasswordHash: []byte("secret-hash")})
+	todo := stores.TodoItems.CreateTodoItem(ctx, models.TodoItem{Text: "Water the plants"})
+	now := time.Now().UTC()
+	item := stores.ScheduledItems.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Plants", StartsAt: now, NextExecutionAt: now.Add(time.Hour)})
+	stores.ExecutionLogs.CreateExecutionLog(ctx, models.ExecutionLog{ScheduledItemID: item.ID, ExecutedAt: now, Status: "success", TodoItemID: &todo.ID})
+
+	other := store.WithTenant(context.Background(), "other")
+	stores.ScheduledItems.CreateScheduledItem(other, models.ScheduledItem{Title: "Elsewhere", StartsAt: now, NextExecutionAt: now.Add(time.Hour)})
+	return stores
+}
+
+func TestExportJSON(t *testing.T) {
+	stores := newTestStores(t, "acme")
+
+	var out bytes.Buffer
+	counts, err := Export(context.Background(), &out, stores, Options{Format: FormatJSON, Tenant: "acme"})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if counts != (Counts{Users: 1, TodoItems: 1, ScheduledItems: 1, ExecutionLogs: 1}) {
+		t.Errorf("Expected one record of each kind from the tenant, got %+v", counts)
+	}
+
+	var bundle struct {
+		Header         Header                 `json:"header"`
+		Users          []models.User          `json:"users"`
+		TodoItems      []models.TodoItem      `json:"todoItems"`
+		ScheduledItems []models.ScheduledItem `json:"scheduledItems"`
+		ExecutionLogs  []models.ExecutionLog  `json:"executionLogs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &bundle); err != nil {
+		t.Fatalf("Expected a JSON document, got %v:\n%s", err, out.String())
+	}
+	if bundle.Header.Version != Version || bundle.Header.Tenant != "acme" || !bundle.Header.PasswordHashes {
+		t.Errorf("Unexpected header %+v", bundle.Header)
+	}
+	if len(bundle.Users) != 1 || string(bundle.Users[0].PasswordHash) != "secret-hash" {
+		t.Errorf("Expected the user with their password hash, got %+v", bundle.Users)
+	}
+	if len(bundle.ScheduledItems) != 1 || bundle.ScheduledItems[0].Title != "Plants" {
+		t.Errorf("Expected only the tenant's scheduled item, got %+v", bundle.ScheduledItems)
+	}
+	if len(bundle.ExecutionLogs) != 1 || bundle.ExecutionLogs[0].TodoItemID == nil {
+		t.Errorf("Expected the execution log with its todo item, got %+v", bundle.ExecutionLogs)
+	}
+}
+
+func TestExportNDJSONWithoutPasswordHashes(t *testing.T) {
+	stores := newTestStores(t, store.DefaultTenant)
+
+	var out bytes.Buffer
+	if _, err := Export(context.Background(), &out, stores, Options{Format: FormatNDJSON, OmitPasswordHashes: true}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if bytes.Contains(out.Bytes(), []byte("secret-hash")) || bytes.Contains(out.Bytes(), []byte("c2VjcmV0LWhhc2g")) {
+		t.Error("Expected the password hash to be omitted")
+	}
+
+	var kinds []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var line struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Expected a JSON object per line, got %v: %s", err, scanner.Text())
+		}
+		kinds = append(kinds, line.Kind)
+	}
+	expected := []string{KindHeader, KindUser, KindTodoItem, KindScheduledItem, KindExecutionLog}
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected lines %v, got %v", expected, kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Errorf("Expected lines %v, got %v", expected, kinds)
+			break
+		}
+	}
+}
+
+func TestExportRejectsUnknownFormats(t *testing.T) {
+	var out bytes.Buffer
+	if _, err := Export(context.Background(), &out, newTestStores(t, store.DefaultTenant), Options{Format: "xml"}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,408 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// kinds are the kinds of record of each of the JSON format's arrays
+var kinds = map[string]string{
+	"users":          KindUser,
+	"todoItems":      KindTodoItem,
+	"scheduledItems": KindScheduledItem,
+	"executionLogs":  KindExecutionLog,
+}
+
+// Reader reads a bundle in either format record by record
+type Reader struct {
+	decoder *json.Decoder
+	format  string
+	header  Header
+	// inSection is set while reading the records of a JSON format array
+	inSection bool
+	section   string
+}
+
+// NewReader reads the header of the bundle on r, telling the formats apart
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{decoder: json.NewDecoder(r)}
+	if err := reader.delim('{'); err != nil {
+		return nil, err
+	}
+	key, err := reader.key()
+	if err != nil {
+		return nil, err
+	}
+
+	switch key {
+	case "header":
+		reader.format = FormatJSON
+		if err := reader.decoder.Decode(&reader.header); err != nil {
+			return nil, fmt.Errorf("invalid bundle header: %w", err)
+		}
+	case "kind":
+		// The first line of an NDJSON bundle is {"kind":"header","data":{...}}
+		reader.format = FormatNDJSON
+		var kind string
+		if err := reader.decoder.Decode(&kind); err != nil || kind != KindHeader {
+			return nil, fmt.Errorf("the bundle doesn't start with its header")
+		}
+		if key, err := reader.key(); err != nil || key != "data" {
+			return nil, fmt.Errorf("the bundle doesn't start with its header")
+		}
+		if err := reader.decoder.Decode(&reader.header); err != nil {
+			return nil, fmt.Errorf("invalid bundle header: %w", err)
+		}
+		if err := reader.delim('}'); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("the bundle doesn't start with its header")
+	}
+	return reader, nil
+}
+
+// Header returns the bundle's header
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Format returns the format the bundle is in
+func (r *Reader) Format() string {
+	return r.format
+}
+
+// Next returns the kind and JSON of the next record, or io.EOF after the last one
+func (r *Reader) Next() (string, json.RawMessage, error) {
+	if r.format == FormatNDJSON {
+		var line struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := r.decoder.Decode(&line); err != nil {
+			if err == io.EOF {
+				return "", nil, io.EOF
+			}
+			return "", nil, fmt.Errorf("invalid bundle line: %w", err)
+		}
+		if _, ok := sections[line.Kind]; !ok {
+			return "", nil, fmt.Errorf("unknown kind of record %q", line.Kind)
+		}
+		return line.Kind, line.Data, nil
+	}
+
+	for {
+		if r.inSection {
+			if r.decoder.More() {
+				var data json.RawMessage
+				if err := r.decoder.Decode(&data); err != nil {
+					return "", nil, fmt.Errorf("invalid %s record: %w", r.section, err)
+				}
+				return r.section, data, nil
+			}
+			if err := r.delim(']'); err != nil {
+				return "", nil, err
+			}
+			r.inSection = false
+		}
+
+		if !r.decoder.More() {
+			if err := r.delim('}'); err != nil {
+				return "", nil, err
+			}
+			return "", nil, io.EOF
+		}
+		key, err := r.key()
+		if err != nil {
+			return "", nil, err
+		}
+		kind, ok := kinds[key]
+		if !ok {
+			return "", nil, fmt.Errorf("unknown bundle section %q", key)
+		}
+		if err := r.delim('['); err != nil {
+			return "", nil, err
+		}
+		r.section = kind
+		r.inSection = true
+	}
+}
+
+// delim reads the JSON delimiter expected next
+func (r *Reader) delim(expected json.Delim) error {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("invalid bundle: expected %q, got %v", expected, token)
+	}
+	return nil
+}
+
+// key reads the object key expected next
+func (r *Reader) key() (string, error) {
+	token, err := r.decoder.Token()
+	if err != nil {
+		return "", fmt.Errorf("invalid bundle: %w", err)
+	}
+	key, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid bundle: expected a key, got %v", token)
+	}
+	return key, nil
+}
+
+// Validate checks that a bundle with header can be imported by this build: its layout
+// must be this build's, and its schema no newer, since records of a newer schema may carry
+// data this build would drop
+func Validate(header Header) error {
+	if header.Version != Version {
+		return fmt.Errorf("bundle version %d can't be imported by this build, which reads version %d", header.Version, Version)
+	}
+	if header.SchemaVersion > migrations.SchemaVersion {
+		return fmt.Errorf("the bundle was exported at schema version %d, newer than this build's %d; import it with a newer build", header.SchemaVersion, migrations.SchemaVersion)
+	}
+	return nil
+}
+
+// ImportOptions are how a bundle is imported
+type ImportOptions struct {
+	// Tenant is the tenant the records are imported into; the bundle's when empty
+	Tenant string
+	// PreserveIDs keeps the records' exported IDs, which the target must not use yet,
+	// through the stores' Restorer. Otherwise the stores assign new IDs and references
+	// between the records follow them.
+	PreserveIDs bool
+}
+
+// Import validates the bundle on r and loads its records into stores, within a
+// transaction of stores.Transactor when there is one, so that a bundle is imported
+// completely or not at all. It returns the bundle's header and how many records of each
+// kind it imported.
+func Import(ctx context.Context, r io.Reader, stores Stores, opts ImportOptions) (Header, Counts, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Header{}, Counts{}, err
+	}
+	header := reader.Header()
+	if err := Validate(header); err != nil {
+		return header, Counts{}, err
+	}
+	if opts.PreserveIDs && stores.Restorer == nil {
+		return header, Counts{}, errors.New("this backend can't preserve IDs; import with new IDs instead")
+	}
+
+	tenant := opts.Tenant
+	if tenant == "" {
+		tenant = header.Tenant
+	}
+	if tenant == "" {
+		tenant = store.DefaultTenant
+	}
+	// Import whoever the context's user is
+	ctx = store.WithoutUser(store.WithTenant(ctx, tenant))
+
+	var transactor store.Transactor = store.NoopTransactor{}
+	if stores.Transactor != nil {
+		transactor = stores.Transactor
+	}
+
+	var counts Counts
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		counts = Counts{}
+		imp := &importer{
+			stores:         stores,
+			preserveIDs:    opts.PreserveIDs,
+			passwordHashes: header.PasswordHashes,
+			users:          map[int64]int64{},
+			todoItems:      map[int64]int64{},
+			scheduledItems: map[int64]int64{},
+		}
+		for {
+			kind, data, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := imp.load(ctx, kind, data, &counts); err != nil {
+				return err
+			}
+		}
+		if opts.PreserveIDs {
+			return stores.Restorer.Finish(ctx)
+		}
+		return nil
+	})
+	if err != nil {
+		return header, Counts{}, err
+	}
+	return header, counts, nil
+}
+
+// importer loads records into the stores, mapping the exported IDs to those assigned
+// when not preserving them
+type importer struct {
+	stores         Stores
+	preserveIDs    bool
+	passwordHashes bool
+	// users, todoItems and scheduledItems map exported IDs to assigned ones
+	users          map[int64]int64
+	todoItems      map[int64]int64
+	scheduledItems map[int64]int64
+}
+
+// decode decodes a record, refusing fields the current models don't have
+func decode(kind string, data json.RawMessage, record any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(record); err != nil {
+		return fmt.Errorf("%s record doesn't match the current schema: %w", kind, err)
+	}
+	return nil
+}
+
+// load stores a record of kind and counts it
+func (i *importer) load(ctx context.Context, kind string, data json.RawMessage, counts *Counts) error {
+	switch kind {
+	case KindUser:
+		var user models.User
+		if err := decode(kind, data, &user); err != nil {
+			return err
+		}
+		if err := i.loadUser(ctx, user); err != nil {
+			return err
+		}
+		counts.Users++
+	case KindTodoItem:
+		var todo models.TodoItem
+		if err := decode(kind, data, &todo); err != nil {
+			return err
+		}
+		if err := i.loadTodoItem(ctx, todo); err != nil {
+			return err
+		}
+		counts.TodoItems++
+	case KindScheduledItem:
+		var item models.ScheduledItem
+		if err := decode(kind, data, &item); err != nil {
+			return err
+		}
+		if err := i.loadScheduledItem(ctx, item); err != nil {
+			return err
+		}
+		counts.ScheduledItems++
+	case KindExecutionLog:
+		var log models.ExecutionLog
+		if err := decode(kind, data, &log); err != nil {
+			return err
+		}
+		if err := i.loadExecutionLog(ctx, log); err != nil {
+			return err
+		}
+		counts.ExecutionLogs++
+	}
+	return nil
+}
+
+// loadUser stores a user, without a password hash when the bundle has none
+func (i *importer) loadUser(ctx context.Context, user models.User) error {
+	if user.Username == "" {
+		return fmt.Errorf("user %d has no username", user.ID)
+	}
+	if !i.passwordHashes {
+		user.PasswordHash = nil
+	}
+	if i.preserveIDs {
+		return i.stores.Restorer.RestoreUser(ctx, user)
+	}
+
+	created := i.stores.Users.CreateUser(ctx, user)
+	if created.ID == 0 {
+		return fmt.Errorf("could not import user %d (%s)", user.ID, user.Username)
+	}
+	// Creating a user always makes them active
+	if user.DeactivatedAt != nil {
+		if _, found := i.stores.Users.SetUserDeactivated(ctx, created.ID, user.DeactivatedAt); !found {
+			return fmt.Errorf("could not deactivate imported user %d (%s)", user.ID, user.Username)
+		}
+	}
+	i.users[user.ID] = created.ID
+	return nil
+}
+
+// loadTodoItem stores a todo item. Organizations aren't exported, so new IDs drop the
+// item's organization, which the target doesn't have.
+func (i *importer) loadTodoItem(ctx context.Context, todo models.TodoItem) error {
+	if i.preserveIDs {
+		return i.stores.Restorer.RestoreTodoItem(ctx, todo)
+	}
+
+	todo.OrganizationID = nil
+	created := i.stores.TodoItems.CreateTodoItem(ctx, todo)
+	if created.ID == 0 {
+		return fmt.Errorf("could not import todo item %d", todo.ID)
+	}
+	i.todoItems[todo.ID] = created.ID
+	return nil
+}
+
+// loadScheduledItem stores a scheduled item, owned by its owner's new ID when there is one
+func (i *importer) loadScheduledItem(ctx context.Context, item models.ScheduledItem) error {
+	if item.Title == "" {
+		return fmt.Errorf("scheduled item %d has no title", item.ID)
+	}
+	if i.preserveIDs {
+		return i.stores.Restorer.RestoreScheduledItem(ctx, item)
+	}
+
+	item.OrganizationID = nil
+	if item.OwnerID != nil {
+		if owner, ok := i.users[*item.OwnerID]; ok {
+			item.OwnerID = &owner
+		} else {
+			item.OwnerID = nil
+		}
+	}
+	created := i.stores.ScheduledItems.CreateScheduledItem(ctx, item)
+	if created.ID == 0 {
+		return fmt.Errorf("could not import scheduled item %d (%s)", item.ID, item.Title)
+	}
+	i.scheduledItems[item.ID] = created.ID
+	return nil
+}
+
+// loadExecutionLog stores an execution log, referring to the new IDs of its scheduled
+// item and todo item
+func (i *importer) loadExecutionLog(ctx context.Context, log models.ExecutionLog) error {
+	if i.preserveIDs {
+		return i.stores.Restorer.RestoreExecutionLog(ctx, log)
+	}
+
+	itemID, ok := i.scheduledItems[log.ScheduledItemID]
+	if !ok {
+		return fmt.Errorf("execution log %d refers to scheduled item %d, which isn't in the bundle", log.ID, log.ScheduledItemID)
+	}
+	log.ScheduledItemID = itemID
+	if log.TodoItemID != nil {
+		if todoID, ok := i.todoItems[*log.TodoItemID]; ok {
+			log.TodoItemID = &todoID
+		} else {
+			log.TodoItemID = nil
+		}
+	}
+	if created := i.stores.ExecutionLogs.CreateExecutionLog(ctx, log); created.ID == 0 {
+		return fmt.Errorf("could not import execution log %d", log.ID)
+	}
+	return nil
+}
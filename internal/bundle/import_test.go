@@ -0,0 +1,147 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// newEmptyStores returns empty memory stores
+func newEmptyStores() Stores {
+	return Stores{
+		Users:          store.NewMemoryUserStore(),
+		TodoItems:      store.NewMemoryTodoItemStore(),
+		ScheduledItems: store.NewMemoryScheduledItemStore(),
+		ExecutionLogs:  store.NewMemoryExecutionLogStore(),
+	}
+}
+
+// recordingRestorer records the IDs of the records it is asked to restore
+type recordingRestorer struct {
+	ids      map[string][]int64
+	finished bool
+}
+
+func (r *recordingRestorer) RestoreUser(ctx context.Context, user models.User) error {
+	r.ids[KindUser] = append(r.ids[KindUser], user.ID)
+	return nil
+}
+
+func (r *recordingRestorer) RestoreTodoItem(ctx context.Context, item models.TodoItem) error {
+	r.ids[KindTodoItem] = append(r.ids[KindTodoItem], item.ID)
+	return nil
+}
+
+func (r *recordingRestorer) RestoreScheduledItem(ctx context.Context, item models.ScheduledItem) error {
+	r.ids[KindScheduledItem] = append(r.ids[KindScheduledItem], item.ID)
+	return nil
+}
+
+func (r *recordingRestorer) RestoreExecutionLog(ctx context.Context, log models.ExecutionLog) error {
+	r.ids[KindExecutionLog] = append(r.ids[KindExecutionLog], log.ID)
+	return nil
+}
+
+func (r *recordingRestorer) Finish(ctx context.Context) error {
+	r.finished = true
+	return nil
+}
+
+// exportTestBundle exports the test stores' tenant acme in format
+func exportTestBundle(t *testing.T, format string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	if _, err := Export(context.Background(), &out, newTestStores(t, "acme"), Options{Format: format, Tenant: "acme"}); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestImportReassignsIDs(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatNDJSON} {
+		target := newEmptyStores()
+		// Records already in the target make the new IDs differ from the exported ones
+		existing := store.WithTenant(context.Background(), "clone")
+		target.ScheduledItems.CreateScheduledItem(existing, models.ScheduledItem{Title: "Existing"})
+		target.TodoItems.CreateTodoItem(existing, models.TodoItem{Text: "Existing"})
+
+		header, counts, err := Import(context.Background(), bytes.NewReader(exportTestBundle(t, format)), target, ImportOptions{Tenant: "clone"})
+		if err != nil {
+			t.Fatalf("Import of %s failed: %v", format, err)
+		}
+		if header.Tenant != "acme" {
+			t.Errorf("Expected the header of tenant acme, got %+v", header)
+		}
+		if counts != (Counts{Users: 1, TodoItems: 1, ScheduledItems: 1, ExecutionLogs: 1}) {
+			t.Errorf("Expected one record of each kind imported from %s, got %+v", format, counts)
+		}
+
+		ctx := store.WithTenant(context.Background(), "clone")
+		items := target.ScheduledItems.GetAllScheduledItems(ctx)
+		todos := target.TodoItems.GetAllTodoItems(ctx)
+		logs := target.ExecutionLogs.GetAllExecutionLogs(ctx)
+		if len(items) != 2 || len(todos) != 2 || len(logs) != 1 {
+			t.Fatalf("Expected the records in tenant clone, got %d items, %d todos and %d logs", len(items), len(todos), len(logs))
+		}
+		if logs[0].ScheduledItemID != 2 || logs[0].TodoItemID == nil || *logs[0].TodoItemID != 2 {
+			t.Errorf("Expected the execution log to refer to the new IDs, got %+v", logs[0])
+		}
+		users := target.Users.GetAllUsers(ctx)
+		if len(users) != 1 || string(users[0].PasswordHash) != "secret-hash" {
+			t.Errorf("Expected the user with their password hash, got %+v", users)
+		}
+	}
+}
+
+func TestImportPreservesIDsThroughTheRestorer(t *testing.T) {
+	target := newEmptyStores()
+	restorer := &recordingRestorer{ids: map[string][]int64{}}
+	target.Restorer = restorer
+
+	if _, _, err := Import(context.Background(), bytes.NewReader(exportTestBundle(t, FormatJSON)), target, ImportOptions{PreserveIDs: true}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	for _, kind := range []string{KindUser, KindTodoItem, KindScheduledItem, KindExecutionLog} {
+		if ids := restorer.ids[kind]; len(ids) != 1 || ids[0] != 1 {
+			t.Errorf("Expected %s 1 to be restored, got %v", kind, ids)
+		}
+	}
+	if !restorer.finished {
+		t.Error("Expected the restorer to be finished")
+	}
+}
+
+func TestImportCannotPreserveIDsWithoutARestorer(t *testing.T) {
+	if _, _, err := Import(context.Background(), bytes.NewReader(exportTestBundle(t, FormatNDJSON)), newEmptyStores(), ImportOptions{PreserveIDs: true}); err == nil {
+		t.Error("Expected preserving IDs without a restorer to be refused")
+	}
+}
+
+func TestImportRejectsBundlesOfANewerSchema(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, FormatNDJSON, Header{Version: Version, SchemaVersion: migrations.SchemaVersion + 1})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	writer.Close()
+
+	_, _, err = Import(context.Background(), &out, newEmptyStores(), ImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected a bundle of a newer schema to be refused, got %v", err)
+	}
+}
+
+func TestImportRejectsUnknownFields(t *testing.T) {
+	bundle := `{"header":{"version":1,"schemaVersion":1,"tenant":"default"},"users":[],"todoItems":[{"id":1,"text":"Hi","priority":3}]}`
+
+	target := newEmptyStores()
+	_, _, err := Import(context.Background(), strings.NewReader(bundle), target, ImportOptions{})
+	if err == nil || !strings.Contains(err.Error(), "current schema") {
+		t.Errorf("Expected an unknown field to be refused, got %v", err)
+	}
+}
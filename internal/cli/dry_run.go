@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+)
+
+// atLayouts are the layouts --at is parsed with, tried in order; times without a zone are UTC
+var atLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// parseAt parses the time a dry run simulates, defaulting to now
+func parseAt(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	for _, layout := range atLayouts {
+		if at, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: use a time such as 2025-03-01T09:00Z", value)
+}
+
+// dryRun prints which items of tenant would be due at the given time, the todo items
+// they would create and when they would be executed next, reading the items from the
+// database selected like the scheduler's without changing anything
+func dryRun(kind storage.Kind, at time.Time, tenant string) int {
+	if kind == storage.Memory {
+		fmt.Fprintln(os.Stderr, "The in-memory stores start empty; use --store or set USE_POSTGRES_DB or USE_DYNAMODB to simulate a database's items")
+		return 1
+	}
+	ctx := context.Background()
+	stores, err := storage.Open(ctx, kind)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open the stores: %v\n", err)
+		return 1
+	}
+	defer stores.Close()
+
+	items := stores.ScheduledItems.GetAllScheduledItems(store.WithTenant(ctx, tenant))
+	simulations := scheduler.Simulate(items, at)
+	if len(simulations) == 0 {
+		fmt.Printf("No items of tenant %s would be due at %s\n", tenant, at.Format(time.RFC3339))
+		return exitOK
+	}
+
+	fmt.Printf("%d items of tenant %s would be due at %s; nothing was changed\n\n", len(simulations), tenant, at.Format(time.RFC3339))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tDUE\tACTION\tNEXT EXECUTION")
+	for _, simulation := range simulations {
+		item := simulation.Item
+		next := "deleted"
+		if simulation.NextExecutionAt != nil {
+			next = simulation.NextExecutionAt.Format(time.RFC3339)
+			if item.JitterSeconds > 0 {
+				next += fmt.Sprintf(" (+ up to %ds jitter)", item.JitterSeconds)
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", item.ID, item.Title, item.NextExecutionAt.Format(time.RFC3339), simulation.Action, next)
+	}
+	w.Flush()
+	return exitOK
+}
@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAt(t *testing.T) {
+	want := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, value := range []string{"2025-03-01T09:00:00Z", "2025-03-01T09:00Z", "2025-03-01T10:00+01:00", "2025-03-01T09:00:00", "2025-03-01T09:00"} {
+		at, err := parseAt(value)
+		if err != nil {
+			t.Errorf("parseAt(%q) failed: %v", value, err)
+		} else if !at.Equal(want) {
+			t.Errorf("parseAt(%q) = %v, expected %v", value, at, want)
+		}
+	}
+
+	if at, err := parseAt("2025-03-01"); err != nil || !at.Equal(want.Truncate(24*time.Hour)) {
+		t.Errorf("Expected a date to mean its midnight in UTC, got %v, %v", at, err)
+	}
+	if at, err := parseAt(""); err != nil || time.Since(at) > time.Minute {
+		t.Errorf("Expected no time to mean now, got %v, %v", at, err)
+	}
+	if _, err := parseAt("tomorrow"); err == nil {
+		t.Error("Expected an error for an unparseable time")
+	}
+}
@@ -0,0 +1,14 @@
+package cli
+
+import "flag"
+
+// isFlagSet reports whether the named flag was given on the command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,312 @@
+// Package cli implements the subcommands shared by the periodic-api binary and the
+// standalone cmd/migrate and cmd/scheduler binaries kept for compatibility. Each takes
+// the name it is run as, for its usage, and its arguments, and returns the exit code.
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"periodic-api/internal/config"
+	"periodic-api/internal/db"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/migrations"
+)
+
+// migrateActions are the actions of the migrate command
+var migrateActions = []string{"up", "down", "status", "version", "force", "create", "plan", "verify", "rehash"}
+
+// Migrate runs the migrate command, which changes or reports on the database schema. The
+// action comes first, as in `migrate down -steps=2`, or from -action, as cmd/migrate
+// has always taken it.
+func Migrate(name string, args []string) int {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	var (
+		action        = flags.String("action", "up", "Migration action: up, down, status, version, force, create, plan, verify, rehash")
+		steps         = flags.Int("steps", 1, "Number of steps for down migration")
+		version       = flags.Uint("version", 0, "Target version for migrate to specific version")
+		forceVer      = flags.Int("force", -1, "Force version (use with caution)")
+		migrationsDir = flags.String("path", "migrations", "Path to migrations directory")
+		migrationName = flags.String("name", "", "Name of the migration to create")
+		numbering     = flags.String("numbering", migrations.NumberingSequence, "Version numbering of created migrations: sequence or timestamp")
+		configFile    = config.FileFlagSet(flags)
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [%s] [flags]\n", name, strings.Join(migrateActions, "|"))
+		flags.PrintDefaults()
+	}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		*action, args = args[0], args[1:]
+	}
+	flags.Parse(args)
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments: %s\n", strings.Join(flags.Args(), " "))
+		flags.Usage()
+		return 2
+	}
+	if !slices.Contains(migrateActions, *action) {
+		fmt.Printf("Unknown action: %s. Use: %s\n", *action, strings.Join(migrateActions, ", "))
+		return 1
+	}
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	// Get absolute path to migrations directory
+	absPath, err := filepath.Abs(*migrationsDir)
+	if err != nil {
+		logging.Fatalf("Failed to get absolute path: %v", err)
+	}
+
+	// Check if migrations directory exists
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		logging.Fatalf("Migrations directory does not exist: %s", absPath)
+	}
+
+	// Use the migrations of the database's dialect
+	dialect, err := migrations.DialectFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid dialect: %v", err)
+	}
+	if absPath, err = dialect.Path(absPath); err != nil {
+		logging.Fatalf("Failed to find migrations: %v", err)
+	}
+
+	// Creating a migration only writes files, so it needs no database
+	if *action == "create" {
+		if err := createMigration(absPath, *migrationName, *numbering); err != nil {
+			logging.Fatalf("Creating migration failed: %v", err)
+		}
+		return 0
+	}
+
+	// Initialize database connection
+	database, err := db.InitDB()
+	if err != nil {
+		logging.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	// Back up the database before changing it, when MIGRATION_SNAPSHOT asks to
+	var snapshotter migrations.Snapshotter
+	switch *action {
+	case "up", "down", "version":
+		if snapshotter, err = snapshotterFromEnv(context.Background()); err != nil {
+			logging.Fatalf("Invalid migration snapshot configuration: %v", err)
+		}
+	}
+
+	switch *action {
+	case "up":
+		if err := runMigrationsUp(database, dialect, absPath, snapshotter); err != nil {
+			logging.Fatalf("Migration up failed: %v", err)
+		}
+		fmt.Println("Migrations applied successfully")
+
+	case "down":
+		if err := runMigrationsDown(database, dialect, absPath, *steps, snapshotter); err != nil {
+			logging.Fatalf("Migration down failed: %v", err)
+		}
+		fmt.Printf("Rolled back %d migration(s) successfully\n", *steps)
+
+	case "status":
+		if err := showMigrationStatus(database, dialect, absPath); err != nil {
+			logging.Fatalf("Failed to show migration status: %v", err)
+		}
+
+	case "version":
+		if *version == 0 {
+			fmt.Println("Please specify a target version with -version flag")
+			return 1
+		}
+		if err := migrateTo(database, dialect, absPath, *version, snapshotter); err != nil {
+			logging.Fatalf("Migration to version %d failed: %v", *version, err)
+		}
+		fmt.Printf("Migrated to version %d successfully\n", *version)
+
+	case "force":
+		if *forceVer < 0 {
+			fmt.Println("Please specify a version to force with -force flag")
+			return 1
+		}
+		if err := forceVersion(database, dialect, absPath, *forceVer); err != nil {
+			logging.Fatalf("Force version %d failed: %v", *forceVer, err)
+		}
+		fmt.Printf("Forced version to %d successfully\n", *forceVer)
+
+	case "plan":
+		if err := planMigrations(database, absPath, *version, *steps, flags); err != nil {
+			logging.Fatalf("Planning migrations failed: %v", err)
+		}
+
+	case "verify":
+		if err := verifyChecksums(database, absPath); err != nil {
+			logging.Fatalf("Verifying migrations failed: %v", err)
+		}
+
+	case "rehash":
+		if err := migrations.RecordChecksums(context.Background(), database, absPath, true); err != nil {
+			logging.Fatalf("Recording migration checksums failed: %v", err)
+		}
+		fmt.Println("Recorded the checksums of the applied migrations as their files are now")
+	}
+	return 0
+}
+
+func runMigrationsUp(database *sql.DB, dialect migrations.Dialect, migrationsPath string, snapshotter migrations.Snapshotter) error {
+	fmt.Println("Running pending migrations...")
+	return migrations.MigrateUp(database, dialect, migrationsPath, snapshotter)
+}
+
+func runMigrationsDown(database *sql.DB, dialect migrations.Dialect, migrationsPath string, steps int, snapshotter migrations.Snapshotter) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be greater than 0 for rollback")
+	}
+
+	fmt.Printf("Rolling back %d migration(s)...\n", steps)
+	return migrations.MigrateDown(database, dialect, migrationsPath, steps, snapshotter)
+}
+
+func showMigrationStatus(database *sql.DB, dialect migrations.Dialect, migrationsPath string) error {
+	version, dirty, err := migrations.MigrateStatus(database, dialect, migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Migration Status:")
+	fmt.Println("================")
+
+	if version == 0 {
+		fmt.Println("Current version: No migrations applied")
+	} else {
+		fmt.Printf("Current version: %d\n", version)
+	}
+
+	if dirty {
+		fmt.Println("Status: DIRTY (migration failed, needs manual intervention)")
+	} else {
+		fmt.Println("Status: CLEAN")
+	}
+
+	return nil
+}
+
+func migrateTo(database *sql.DB, dialect migrations.Dialect, migrationsPath string, version uint, snapshotter migrations.Snapshotter) error {
+	fmt.Printf("Migrating to version %d...\n", version)
+	return migrations.MigrateTo(database, dialect, migrationsPath, version, snapshotter)
+}
+
+func forceVersion(database *sql.DB, dialect migrations.Dialect, migrationsPath string, version int) error {
+	fmt.Printf("Forcing version to %d...\n", version)
+	return migrations.ForceVersion(database, dialect, migrationsPath, version)
+}
+
+// snapshotterFromEnv returns the snapshotter backing up the database before migrations
+// as configured by MIGRATION_SNAPSHOT, or nil when snapshots are off
+func snapshotterFromEnv(ctx context.Context) (migrations.Snapshotter, error) {
+	cfg, enabled, err := migrations.SnapshotConfigFromEnv()
+	if err != nil || !enabled {
+		return nil, err
+	}
+	var dumpEnv []string
+	if cfg.Method == migrations.SnapshotPgDump {
+		if dumpEnv, err = db.DumpEnv(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return migrations.NewSnapshotter(ctx, cfg, dumpEnv)
+}
+
+func createMigration(migrationsPath, name, numbering string) error {
+	if name == "" {
+		return fmt.Errorf("specify the migration's name with -name")
+	}
+
+	paths, err := migrations.Create(migrationsPath, name, numbering, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Printf("Created %s\n", path)
+	}
+	fmt.Println("Remember to bump migrations.SchemaVersion to the new version")
+	return nil
+}
+
+// planMigrations prints the migrations that would run, and their SQL, without running
+// them: those migrating to -version when it is given, those rolling back -steps when it
+// is given, and the pending ones otherwise. The database is only read.
+func planMigrations(database *sql.DB, migrationsPath string, version uint, steps int, flags *flag.FlagSet) error {
+	status, err := migrations.ReadSchemaStatus(context.Background(), database)
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		fmt.Printf("Warning: version %d is dirty; fix it and force the version before migrating\n", status.Version)
+	}
+
+	target := version
+	switch {
+	case isFlagSet(flags, "version"):
+	case isFlagSet(flags, "steps"):
+		if steps <= 0 {
+			return fmt.Errorf("steps must be greater than 0 for rollback")
+		}
+		if target, err = migrations.StepsBack(migrationsPath, status.Version, steps); err != nil {
+			return err
+		}
+	default:
+		if target, err = migrations.LatestVersion(migrationsPath); err != nil {
+			return err
+		}
+	}
+
+	plan, err := migrations.Plan(migrationsPath, status.Version, target)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Printf("Nothing to do: the database is at version %d\n", status.Version)
+		return nil
+	}
+
+	fmt.Printf("Migrating from version %d to %d would run %d migration(s):\n", status.Version, target, len(plan))
+	for _, migration := range plan {
+		direction := "down"
+		if migration.Up {
+			direction = "up"
+		}
+		fmt.Printf("\n-- %d %s (%s)\n%s\n", migration.Version, migration.Identifier, direction, strings.TrimSpace(migration.SQL))
+	}
+	return nil
+}
+
+// verifyChecksums prints the applied migrations whose files have been edited or removed
+// since they were applied, failing when there are any
+func verifyChecksums(database *sql.DB, migrationsPath string) error {
+	mismatches, err := migrations.VerifyChecksums(context.Background(), database, migrationsPath)
+	if err != nil {
+		return err
+	}
+	if len(mismatches) == 0 {
+		fmt.Println("Every applied migration matches its file")
+		return nil
+	}
+	for _, mismatch := range mismatches {
+		fmt.Println(mismatch)
+	}
+	return fmt.Errorf("%d applied migration(s) differ from their files", len(mismatches))
+}
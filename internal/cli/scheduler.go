@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"periodic-api/internal/cloudevents"
+	"periodic-api/internal/config"
+	"periodic-api/internal/db"
+	"periodic-api/internal/events"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/mqtt"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/storage"
+	"periodic-api/internal/store"
+	"periodic-api/internal/tracing"
+	"periodic-api/internal/webhooks"
+
+	"github.com/lib/pq"
+)
+
+// Exit codes reported in run-once mode so cron-style runners can tell outcomes apart
+const (
+	exitOK          = 0
+	exitItemsFailed = 1
+	exitClaimFailed = 2
+)
+
+// Scheduler runs the scheduler command, which processes due items every interval until
+// it is interrupted, or once with -once
+func Scheduler(name string, args []string) int {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	once := flags.Bool("once", false,
+		"Process the items currently due and exit instead of running as a daemon (or SCHEDULER_MODE=oneshot)")
+	dryRunFlag := flags.Bool("dry-run", false, "Print which items would be due --at a time, the todos they would create and their next executions, without changing anything")
+	at := flags.String("at", "", "Time a dry run simulates, such as 2025-03-01T09:00Z (default now)")
+	tenant := flags.String("tenant", store.DefaultTenant, "Tenant whose items a dry run simulates")
+	configFile := config.FileFlagSet(flags)
+	storeName := storage.FlagSet(flags)
+	flags.Parse(args)
+
+	if err := config.Load(*configFile); err != nil {
+		logging.Fatalf("Failed to load configuration: %v", err)
+	}
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		logging.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logging.Setup(logConfig)
+
+	kind, err := storage.Select(*storeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *at != "" && !*dryRunFlag {
+		fmt.Fprintln(os.Stderr, "--at only applies to --dry-run")
+		return 2
+	}
+	if *dryRunFlag {
+		simulatedAt, err := parseAt(*at)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		return dryRun(kind, simulatedAt, *tenant)
+	}
+
+	// SCHEDULER_MODE may come from .env or the config file, so it is only read once they are loaded
+	if !isFlagSet(flags, "once") {
+		*once = strings.ToLower(os.Getenv("SCHEDULER_MODE")) == "oneshot"
+	}
+
+	return runScheduler(kind, *once)
+}
+
+// runScheduler starts the scheduler on the given backend and returns the process exit
+// code. It is separate from Scheduler so deferred cleanup happens before the process exits.
+func runScheduler(kind storage.Kind, once bool) int {
+	var listener *pq.Listener
+
+	// Export traces when an OTLP collector is configured
+	if tracingConfig, enabled := tracing.ConfigFromEnv("periodic-scheduler"); enabled {
+		defer tracing.Setup(tracingConfig)()
+		logging.Infof("Exporting traces to %s", tracingConfig.Endpoint)
+	}
+
+	stores, err := storage.Open(context.Background(), kind)
+	if err != nil {
+		logging.Fatalf("Failed to open the stores: %v", err)
+	}
+	defer stores.Close()
+	logging.Infof("Scheduler using %s for storage", stores.Description())
+
+	if stores.DB != nil {
+		store.SetSlowQueryThreshold(store.SlowQueryThresholdFromEnv())
+		store.SetPreparedStatements(store.PreparedStatementsFromEnv())
+
+		// Listen for item changes so due items are processed without waiting for the next
+		// tick; without change notifications, DynamoDB and the in-memory stores rely on polling
+		if !once {
+			listener, err = db.NewListener(scheduler.NotificationChannel)
+			if err != nil {
+				logging.Warnf("Failed to listen for scheduled item changes, relying on polling only: %v", err)
+			} else {
+				defer listener.Close()
+			}
+		}
+	}
+
+	// Publish executions and the changes they make so webhooks are notified
+	bus := events.NewBus()
+	itemStore := store.NewPublishingScheduledItemStore(stores.ScheduledItems, bus)
+	todoStore := store.NewPublishingTodoItemStore(stores.TodoItems, bus)
+	executionLogStore := store.NewPublishingExecutionLogStore(stores.ExecutionLogs, bus)
+	userStore := stores.Users
+
+	service := scheduler.NewService(itemStore, todoStore, executionLogStore)
+	service.EnableTransactions(stores.Transactor)
+	service.EnableHeartbeat(stores.Heartbeats, scheduler.InstanceID())
+	webhookConfig := webhooks.ConfigFromEnv()
+	if webhookConfig.AllowPrivateNetworks {
+		service.AllowPrivateNetworks()
+	}
+
+	// Optionally enable the mqtt action for publishing to a broker
+	if mqttConfig, enabled := mqtt.ConfigFromEnv(); enabled {
+		service.RegisterAction(scheduler.ActionTypeMQTT, scheduler.NewMQTTAction(mqtt.NewClient(mqttConfig)))
+		logging.Infof("MQTT action enabled with topic prefix %s", mqttConfig.Topic)
+	}
+
+	// Create a context that is cancelled on interrupt signals
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Optionally email people or post to Slack about executions, following the items' notification settings
+	var digester *notifications.Digester
+	if notificationsConfig, enabled := notifications.ConfigFromEnv(); enabled {
+		dispatcher, err := notifications.NewDispatcherFromConfig(ctx, notificationsConfig, userStore, stores.NotificationPreferences, stores.DeviceTokens)
+		if err != nil {
+			logging.Fatalf("Failed to initialize notifications: %v", err)
+		}
+		service.EnableNotifications(dispatcher)
+		if notificationsConfig.DigestSchedule != "" {
+			digester, err = notifications.NewDigester(notificationsConfig.DigestSchedule, dispatcher, itemStore, todoStore, userStore)
+			if err != nil {
+				logging.Fatalf("Failed to initialize digests: %v", err)
+			}
+		}
+		// Alert operators when batches or items fail
+		if alertConfig, enabled := notifications.AlertConfigFromEnv(); enabled {
+			alerter := notifications.NewAlerter(alertConfig, dispatcher)
+			service.EnableAlerts(alerter)
+			defer alerter.Wait()
+			logging.Infof("Alerting about scheduler failures, at most every %v per alert", alertConfig.Throttle)
+		}
+		// Let notifications still being sent finish before the stores are closed
+		defer dispatcher.Wait()
+		logging.Infof("Sending execution notifications by %s", notificationsConfig.Channels())
+	}
+
+	if once {
+		logging.Infof("Running scheduler once")
+		return exitCode(service.ProcessScheduledItems(ctx))
+	}
+
+	// Send digests while running as a daemon
+	if digester != nil {
+		go digester.Run(ctx)
+		logging.Infof("Sending digests on schedule %s", os.Getenv("NOTIFICATIONS_DIGEST_SCHEDULE"))
+	}
+
+	// Deliver webhooks while running as a daemon; a single pass exits before retries could run
+	scopedItemStore := store.NewScopedScheduledItemStore(stores.ScheduledItems, stores.ItemShares)
+	go webhooks.NewDispatcher(stores.Webhooks, scopedItemStore, webhookConfig).Run(ctx, bus)
+
+	// Optionally publish executions to SNS or EventBridge as CloudEvents
+	if cloudEventsConfig, enabled := cloudevents.ConfigFromEnv(); enabled {
+		publisher, err := cloudevents.NewPublisherFromConfig(ctx, cloudEventsConfig)
+		if err != nil {
+			logging.Fatalf("Failed to initialize CloudEvents publisher: %v", err)
+		}
+		go publisher.Run(ctx, bus)
+		logging.Infof("Publishing scheduled item executions as CloudEvents")
+	}
+
+	// Get interval from environment variable, default to 30 seconds
+	interval := scheduler.IntervalFromEnv()
+
+	logging.Infof("Starting scheduler service with interval: %v", interval)
+
+	// Serve health and status endpoints for orchestrators and operators
+	healthPort := os.Getenv("SCHEDULER_HEALTH_PORT")
+	if healthPort == "" {
+		healthPort = "8081"
+	}
+	healthServer := &http.Server{
+		Addr:    ":" + healthPort,
+		Handler: service.HealthHandler(),
+	}
+	go func() {
+		logging.Infof("Scheduler health endpoints listening on port %s", healthPort)
+		if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Errorf("Health server failed: %v", err)
+		}
+	}()
+	defer healthServer.Close()
+
+	if listener != nil {
+		go service.ListenForChanges(ctx, listener)
+	}
+
+	// Pick up a new interval when the configuration is reloaded
+	go config.WatchReloads(ctx, func() {
+		logging.ReloadLevel()
+		service.SetInterval(scheduler.IntervalFromEnv())
+	})
+
+	// Main service loop
+	service.Run(ctx, interval)
+	logging.Infof("Received shutdown signal, stopping scheduler...")
+	return exitOK
+}
+
+// exitCode maps the outcome of a single processing pass to the process exit code
+func exitCode(result scheduler.ProcessResult) int {
+	switch {
+	case result.ClaimErr != nil:
+		return exitClaimFailed
+	case result.Failed > 0:
+		return exitItemsFailed
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,53 @@
+// Package cloudevents publishes scheduled item executions to AWS as CloudEvents, so other
+// services can react to them without polling the API
+package cloudevents
+
+import (
+	"fmt"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"time"
+)
+
+const (
+	// specVersion is the CloudEvents specification version of the events produced
+	specVersion = "1.0"
+	// typePrefix is prepended to bus event types to form CloudEvents types
+	typePrefix = "periodic."
+)
+
+// Event is a CloudEvent in the structured JSON format
+type Event struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// FromExecution converts a scheduled_item.executed or scheduled_item.failed bus event into
+// a CloudEvent, and reports false for any other event. The execution log ID makes the
+// CloudEvent ID unique across restarts, unlike bus event IDs.
+func FromExecution(source string, event events.Event) (Event, bool) {
+	if event.Type != events.ScheduledItemExecuted && event.Type != events.ScheduledItemFailed {
+		return Event{}, false
+	}
+	executionLog, ok := event.Data.(models.ExecutionLog)
+	if !ok {
+		return Event{}, false
+	}
+
+	return Event{
+		SpecVersion:     specVersion,
+		ID:              fmt.Sprintf("execution-log-%d", executionLog.ID),
+		Source:          source,
+		Type:            typePrefix + event.Type,
+		Subject:         fmt.Sprintf("scheduled-items/%d", executionLog.ScheduledItemID),
+		Time:            executionLog.ExecutedAt,
+		DataContentType: "application/json",
+		Data:            executionLog,
+	}, true
+}
@@ -0,0 +1,75 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestFromExecutionOnlyConvertsExecutions(t *testing.T) {
+	executedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	busEvent := events.Event{
+		ID:   7,
+		Type: events.ScheduledItemFailed,
+		Data: models.ExecutionLog{ID: 12, ScheduledItemID: 3, ExecutedAt: executedAt, Status: "error"},
+	}
+
+	event, ok := FromExecution("periodic-api", busEvent)
+	if !ok {
+		t.Fatal("Expected a CloudEvent for a failed execution")
+	}
+	if event.ID != "execution-log-12" || event.Type != "periodic.scheduled_item.failed" ||
+		event.Subject != "scheduled-items/3" || !event.Time.Equal(executedAt) || event.SpecVersion != "1.0" {
+		t.Errorf("Unexpected CloudEvent %+v", event)
+	}
+
+	if _, ok := FromExecution("periodic-api", events.Event{Type: events.TodoCreated, Data: models.TodoItem{}}); ok {
+		t.Error("Expected todo events to be skipped")
+	}
+}
+
+func TestSNSSinkSendsSignedPublishRequest(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "Credential=AKID/") || !strings.Contains(auth, "/us-west-2/sns/aws4_request") {
+			t.Errorf("Expected a SigV4 signature for sns in us-west-2, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.Write([]byte("<PublishResponse/>"))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	sink, err := NewSNSSink(cfg, "arn:aws:sns:us-west-2:123456789012:executions", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create sink: %v", err)
+	}
+
+	event := Event{SpecVersion: specVersion, ID: "execution-log-1", Source: "periodic-api", Type: "periodic.scheduled_item.executed"}
+	if err := sink.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if form.Get("Action") != "Publish" || form.Get("TopicArn") != "arn:aws:sns:us-west-2:123456789012:executions" {
+		t.Errorf("Unexpected Publish request %v", form)
+	}
+	if form.Get("MessageAttributes.entry.1.Value.StringValue") != event.Type {
+		t.Errorf("Expected the event type as a message attribute, got %v", form)
+	}
+	var message Event
+	if err := json.Unmarshal([]byte(form.Get("Message")), &message); err != nil || message.ID != event.ID {
+		t.Errorf("Expected the CloudEvent as the message, got %q", form.Get("Message"))
+	}
+}
@@ -0,0 +1,135 @@
+package cloudevents
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"periodic-api/internal/events"
+	"periodic-api/internal/logging"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+const (
+	// defaultSource is the CloudEvents source when CLOUDEVENTS_SOURCE is not set
+	defaultSource = "periodic-api"
+	// sendAttempts is how many times each sink is tried per event
+	sendAttempts = 3
+	// sendRetryDelay is the wait before the first retry; it doubles for each further retry
+	sendRetryDelay = 500 * time.Millisecond
+)
+
+// Config selects where execution events are published
+type Config struct {
+	// SNSTopicARN publishes to an SNS topic when set
+	SNSTopicARN string
+	// EventBridgeBus puts events on an EventBridge bus, by name or ARN, when set
+	EventBridgeBus string
+	// Source is the CloudEvents source attribute
+	Source string
+	// Endpoint overrides the AWS service endpoint, e.g. to use LocalStack
+	Endpoint string
+}
+
+// ConfigFromEnv returns the publishing configuration from the CLOUDEVENTS_SNS_TOPIC_ARN,
+// CLOUDEVENTS_EVENTBRIDGE_BUS, CLOUDEVENTS_SOURCE and CLOUDEVENTS_AWS_ENDPOINT environment
+// variables, and whether publishing is enabled
+func ConfigFromEnv() (Config, bool) {
+	config := Config{
+		SNSTopicARN:    os.Getenv("CLOUDEVENTS_SNS_TOPIC_ARN"),
+		EventBridgeBus: os.Getenv("CLOUDEVENTS_EVENTBRIDGE_BUS"),
+		Source:         os.Getenv("CLOUDEVENTS_SOURCE"),
+		Endpoint:       os.Getenv("CLOUDEVENTS_AWS_ENDPOINT"),
+	}
+	if config.Source == "" {
+		config.Source = defaultSource
+	}
+	return config, config.SNSTopicARN != "" || config.EventBridgeBus != ""
+}
+
+// Publisher sends scheduled item executions and failures published on a bus to its sinks
+type Publisher struct {
+	source string
+	sinks  []Sink
+}
+
+// NewPublisher creates a publisher sending events with the given source to the given sinks
+func NewPublisher(source string, sinks ...Sink) *Publisher {
+	return &Publisher{
+		source: source,
+		sinks:  sinks,
+	}
+}
+
+// NewPublisherFromConfig creates a publisher for the sinks enabled in config, using the
+// default AWS credential chain
+func NewPublisherFromConfig(ctx context.Context, cfg Config) (*Publisher, error) {
+	awsConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+
+	var sinks []Sink
+	if cfg.SNSTopicARN != "" {
+		sink, err := NewSNSSink(awsConfig, cfg.SNSTopicARN, cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.EventBridgeBus != "" {
+		sink, err := NewEventBridgeSink(awsConfig, cfg.EventBridgeBus, cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return NewPublisher(cfg.Source, sinks...), nil
+}
+
+// Run publishes the executions announced on bus until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context, bus *events.Bus) {
+	subscription, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case busEvent, ok := <-subscription:
+			if !ok {
+				return
+			}
+			event, ok := FromExecution(p.source, busEvent)
+			if !ok {
+				continue
+			}
+			for _, sink := range p.sinks {
+				if err := send(ctx, sink, event); err != nil {
+					logging.Errorf("Failed to publish CloudEvent %s: %v", event.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// send delivers the event to a sink, retrying failures with exponential backoff
+func send(ctx context.Context, sink Sink, event Event) error {
+	delay := sendRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := sink.Send(ctx, event)
+		if err == nil || attempt >= sendAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,124 @@
+package cloudevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"periodic-api/internal/awsapi"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Sink delivers CloudEvents to a destination
+type Sink interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// SNSSink publishes CloudEvents to an SNS topic. The event type is also sent as the "type"
+// message attribute so subscriptions can filter on it.
+type SNSSink struct {
+	client   *awsapi.Client
+	topicARN string
+}
+
+// NewSNSSink creates a sink publishing to the given topic, in the region named by its ARN
+func NewSNSSink(cfg aws.Config, topicARN string, endpoint string) (*SNSSink, error) {
+	parsed, err := arn.Parse(topicARN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SNS topic ARN: %w", err)
+	}
+	return &SNSSink{
+		client:   awsapi.NewClient(cfg, "sns", parsed.Region, endpoint),
+		topicARN: topicARN,
+	}, nil
+}
+
+// Send publishes the event as the message body
+func (s *SNSSink) Send(ctx context.Context, event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	form := url.Values{
+		"Action":                         {"Publish"},
+		"Version":                        {"2010-03-31"},
+		"TopicArn":                       {s.topicARN},
+		"Message":                        {string(message)},
+		"MessageAttributes.entry.1.Name": {"type"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {event.Type},
+	}
+
+	_, err = s.client.Post(ctx, []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+	})
+	return err
+}
+
+// EventBridgeSink puts CloudEvents on an EventBridge event bus. The CloudEvents type is
+// the detail type and the whole CloudEvent is the detail, so rules can match on either.
+type EventBridgeSink struct {
+	client  *awsapi.Client
+	busName string
+}
+
+// NewEventBridgeSink creates a sink putting events on the named bus (or bus ARN) in the configured region
+func NewEventBridgeSink(cfg aws.Config, busName string, endpoint string) (*EventBridgeSink, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("no AWS region configured for EventBridge")
+	}
+	return &EventBridgeSink{
+		client:  awsapi.NewClient(cfg, "events", cfg.Region, endpoint),
+		busName: busName,
+	}, nil
+}
+
+// putEventsResponse is the part of the PutEvents response reporting rejected entries
+type putEventsResponse struct {
+	FailedEntryCount int `json:"FailedEntryCount"`
+	Entries          []struct {
+		ErrorCode    string `json:"ErrorCode"`
+		ErrorMessage string `json:"ErrorMessage"`
+	} `json:"Entries"`
+}
+
+// Send puts the event on the bus
+func (s *EventBridgeSink) Send(ctx context.Context, event Event) error {
+	detail, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"Entries": []map[string]any{{
+			"EventBusName": s.busName,
+			"Source":       event.Source,
+			"DetailType":   event.Type,
+			"Detail":       string(detail),
+			"Time":         event.Time.Unix(),
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode PutEvents request: %w", err)
+	}
+
+	respBody, err := s.client.Post(ctx, body, map[string]string{
+		"Content-Type": "application/x-amz-json-1.1",
+		"X-Amz-Target": "AWSEvents.PutEvents",
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp putEventsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("failed to decode PutEvents response: %w", err)
+	}
+	if resp.FailedEntryCount > 0 && len(resp.Entries) > 0 {
+		return fmt.Errorf("EventBridge rejected event: %s: %s", resp.Entries[0].ErrorCode, resp.Entries[0].ErrorMessage)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DotEnvFile is the file of environment variables loaded for local development, relative
+// to the working directory
+const DotEnvFile = ".env"
+
+// ReadDotEnv reads the .env file at path and returns its variables by name. A missing
+// file has no variables.
+func ReadDotEnv(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return parseDotEnv(file.Name(), bufio.NewScanner(file))
+}
+
+// parseDotEnv reads NAME=value lines, optionally prefixed with export. Blank lines and
+// lines starting with # are skipped. Values may be double quoted, with \n, \t, \" and \\
+// escapes, or single quoted to be taken literally; unquoted values end at a " #" comment.
+func parseDotEnv(path string, scanner *bufio.Scanner) (map[string]string, error) {
+	values := map[string]string{}
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+
+		name, raw, found := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%s:%d: expected NAME=value", path, line)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s %w", path, line, name, err)
+		}
+		values[name] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// parseDotEnvValue unquotes the value of a .env assignment
+func parseDotEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", errors.New("has an unterminated quote")
+		}
+		value, err := strconv.Unquote(raw[:end+1])
+		if err != nil {
+			return "", fmt.Errorf("has an invalid escape: %w", err)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", errors.New("has an unterminated quote")
+		}
+		return raw[1 : end+1], nil
+	default:
+		if comment := strings.Index(raw, " #"); comment >= 0 {
+			raw = raw[:comment]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+// closingQuote returns the index of the double quote ending the string starting at s[0],
+// or -1 if it isn't terminated
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadDotEnv(t *testing.T) {
+	values, err := ReadDotEnv(writeConfig(t, ".env", `
+# Local development
+DB_HOST=localhost
+export DB_PASSWORD="p@ss \"word\"\n"
+LLM_PROVIDER=ollama # no AWS credentials here
+CORS_ALLOWED_ORIGINS='http://localhost:3000,http://localhost:5173'
+`))
+	if err != nil {
+		t.Fatalf("ReadDotEnv failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"DB_HOST":              "localhost",
+		"DB_PASSWORD":          "p@ss \"word\"\n",
+		"LLM_PROVIDER":         "ollama",
+		"CORS_ALLOWED_ORIGINS": "http://localhost:3000,http://localhost:5173",
+	}
+	if !maps.Equal(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+
+	if values, err := ReadDotEnv(filepath.Join(t.TempDir(), ".env")); err != nil || len(values) != 0 {
+		t.Errorf("Expected a missing .env to have no variables, got %v (%v)", values, err)
+	}
+
+	for content, expected := range map[string]string{
+		"DB_HOST\n":             ".env:1: expected NAME=value",
+		"\nDB HOST=localhost\n": ".env:2: expected NAME=value",
+		`DB_HOST="localhost`:    "DB_HOST has an unterminated quote",
+	} {
+		_, err := ReadDotEnv(writeConfig(t, ".env", content))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected an error containing %q for %q, got %v", expected, content, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings lists the environment variables a config file may set. Each is written in the
+// file as its sections and key, lower case: DB_HOST as host under db, or as db_host.
+var Settings = []string{
+	// Storage
+	"USE_POSTGRES_DB", "USE_DYNAMODB", "AUTO_MIGRATE", "MIGRATIONS_PATH", "DB_DIALECT",
+	"MIGRATION_SNAPSHOT", "MIGRATION_SNAPSHOT_TARGET", "MIGRATION_SNAPSHOT_DIR", "MIGRATION_SNAPSHOT_ENDPOINT",
+	"MIGRATION_CHECKSUMS",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_SSL_MODE",
+	"DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME", "DB_CONN_MAX_IDLE_TIME",
+	"DB_RESUME_TIMEOUT", "DB_RESUME_BACKOFF", "DB_READ_HOST", "DB_READ_MAX_LAG",
+	"DB_SECRET_ARN", "DB_SECRET_REFRESH", "DB_SLOW_QUERY_THRESHOLD", "DB_PREPARED_STATEMENTS",
+	"DYNAMODB_TABLE", "DYNAMODB_ENDPOINT",
+	"USE_CACHE", "CACHE_TTL", "CACHE_SIZE", "CACHE_POLL_TTL", "SEED_ENV",
+
+	// HTTP
+	"HTTP_ADDR", "HTTP_PORT", "TLS_CERT_FILE", "TLS_KEY_FILE",
+	"CORS_ALLOWED_ORIGINS", "CORS_ALLOWED_METHODS", "CORS_ALLOWED_HEADERS", "CORS_MAX_AGE",
+
+	// Tenants
+	"AUTH_TOKEN_SECRET", "ALLOW_UNAUTHENTICATED_ADMIN",
+	"TENANT_MAX_SCHEDULED_ITEMS", "TENANT_MAX_DAILY_GENERATIONS", "TENANT_MAX_WEBHOOKS",
+	"TENANT_RATE_LIMIT", "TENANT_RATE_BURST",
+
+	// Logging and tracing
+	"LOG_LEVEL", "LOG_FORMAT", "LOG_BODY_SAMPLE_RATE", "LOG_BODY_MAX_BYTES",
+	"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_HEADERS", "OTEL_SERVICE_NAME",
+
+	// Scheduler
+	"RUN_SCHEDULER", "SCHEDULER_INTERVAL", "SCHEDULER_MODE", "SCHEDULER_HEALTH_PORT",
+
+	// Events
+	"WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_INITIAL_BACKOFF", "WEBHOOK_MAX_BACKOFF", "WEBHOOK_TIMEOUT", "WEBHOOK_ALLOW_PRIVATE_NETWORKS",
+	"MQTT_BROKER_URL", "MQTT_USERNAME", "MQTT_PASSWORD", "MQTT_CLIENT_ID", "MQTT_TOPIC", "MQTT_QOS", "MQTT_TIMEOUT",
+	"CLOUDEVENTS_SNS_TOPIC_ARN", "CLOUDEVENTS_EVENTBRIDGE_BUS", "CLOUDEVENTS_SOURCE", "CLOUDEVENTS_AWS_ENDPOINT",
+	"NOTIFICATIONS_EMAIL_FROM", "NOTIFICATIONS_AWS_ENDPOINT",
+	"NOTIFICATIONS_SLACK_WEBHOOK_URL", "NOTIFICATIONS_SLACK_BOT_TOKEN", "NOTIFICATIONS_SLACK_CHANNEL",
+	"NOTIFICATIONS_DIGEST_SCHEDULE", "NOTIFICATIONS_FCM_CREDENTIALS_FILE",
+	"NOTIFICATIONS_APNS_KEY_FILE", "NOTIFICATIONS_APNS_KEY_ID", "NOTIFICATIONS_APNS_TEAM_ID", "NOTIFICATIONS_APNS_TOPIC", "NOTIFICATIONS_APNS_SANDBOX",
+	"NOTIFICATIONS_TEMPLATES_DIR", "NOTIFICATIONS_BASE_URL",
+	"ALERTS_EMAIL", "ALERTS_SLACK_CHANNEL", "ALERTS_ERROR_RATE", "ALERTS_MIN_BATCH", "ALERTS_THROTTLE",
+
+	// LLM
+	"LLM_PROVIDER", "LLM_API_KEY", "LLM_BASE_URL", "LLM_MODEL_ID",
+	"LLM_MAX_TOKENS", "LLM_TEMPERATURE", "LLM_TOP_P",
+	"LLM_TIMEOUT", "LLM_MAX_RETRIES", "LLM_BREAKER_THRESHOLD", "LLM_BREAKER_COOLDOWN",
+	"LLM_DAILY_REQUEST_QUOTA", "LLM_DAILY_TOKEN_QUOTA",
+}
+
+// FileFlag registers the --config flag, which names the config file to load
+func FileFlag() *string {
+	return FileFlagSet(flag.CommandLine)
+}
+
+// FileFlagSet registers the --config flag on the flags of a subcommand
+func FileFlagSet(flags *flag.FlagSet) *string {
+	return flags.String("config", "", "YAML config file with settings the environment can override (or CONFIG_FILE)")
+}
+
+// ReadFile reads the YAML config file at path and returns its settings by environment
+// variable name. Lists are joined with commas, like the environment variables that take
+// several values.
+func ReadFile(path string) (map[string]string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format %q, use .yaml or .yml", path, ext)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var document map[string]any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	if err := flatten(values, "", document); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten adds the settings in a section of the file to values, naming each by its
+// sections and key
+func flatten(values map[string]string, prefix string, section map[string]any) error {
+	for key, value := range section {
+		name := prefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+
+		if subsection, ok := value.(map[string]any); ok {
+			if err := flatten(values, name+"_", subsection); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !slices.Contains(Settings, name) {
+			return fmt.Errorf("unknown setting %s", name)
+		}
+		if _, duplicate := values[name]; duplicate {
+			return fmt.Errorf("%s is set more than once", name)
+		}
+		formatted, err := formatValue(value)
+		if err != nil {
+			return fmt.Errorf("%s %w", name, err)
+		}
+		values[name] = formatted
+	}
+	return nil
+}
+
+// formatValue formats a value from the file the way it would be written in the environment
+func formatValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			if _, nested := item.([]any); nested {
+				return "", fmt.Errorf("must be a list of values")
+			}
+			formatted, err := formatValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = formatted
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("must be a string, number, boolean or list")
+	}
+}
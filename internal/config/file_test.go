@@ -0,0 +1,82 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file to a temporary directory and returns its path
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	values, err := ReadFile(writeConfig(t, "periodic.yaml", `
+use_postgres_db: true
+db:
+  host: db.internal
+  port: 5433
+  conn-max-lifetime: 30m
+cors:
+  allowed_origins:
+    - https://app.example.com
+    - https://admin.example.com
+llm:
+  provider: ollama
+  temperature: 0.2
+  daily_request_quota: 100
+`))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"USE_POSTGRES_DB":         "true",
+		"DB_HOST":                 "db.internal",
+		"DB_PORT":                 "5433",
+		"DB_CONN_MAX_LIFETIME":    "30m",
+		"CORS_ALLOWED_ORIGINS":    "https://app.example.com,https://admin.example.com",
+		"LLM_PROVIDER":            "ollama",
+		"LLM_TEMPERATURE":         "0.2",
+		"LLM_DAILY_REQUEST_QUOTA": "100",
+	}
+	if !maps.Equal(values, expected) {
+		t.Errorf("Expected %v, got %v", expected, values)
+	}
+}
+
+func TestReadFileRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{"unknown setting", "periodic.yaml", "db:\n  hots: localhost\n", "unknown setting DB_HOTS"},
+		{"set twice", "periodic.yaml", "db_host: a\ndb:\n  host: b\n", "DB_HOST is set more than once"},
+		{"nested list", "periodic.yml", "cors:\n  allowed_origins: [[a]]\n", "must be a list of values"},
+		{"invalid YAML", "periodic.yaml", "db: [", "parsing config file"},
+		{"unsupported format", "periodic.toml", "[db]\nhost = \"a\"\n", "unsupported format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadFile(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	if _, err := ReadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,129 @@
+// Package config loads settings from a .env file and a config file, layered under the
+// environment variables the rest of the application reads
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"periodic-api/internal/logging"
+)
+
+var (
+	// loadMu guards the state of the last Load, which Reload repeats
+	loadMu sync.Mutex
+	// loadedPath is the config file path given to Load
+	loadedPath string
+	// appliedPath is the config file last applied, after falling back to CONFIG_FILE
+	appliedPath string
+	// loaded holds the variables set from the files rather than the environment, which
+	// Reload may change
+	loaded = map[string]bool{}
+)
+
+// Load applies the .env file in the working directory and the config file at path, or
+// named by CONFIG_FILE, setting the variables that aren't set in the environment.
+// Settings come from the environment first, then .env, then the config file.
+func Load(path string) error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	loadedPath = path
+	return apply(path)
+}
+
+// Reload reads the files given to Load again. Variables set from the files take their new
+// values, and are unset if they have been removed; the environment still takes precedence.
+func Reload() error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	return apply(loadedPath)
+}
+
+// Path returns the config file applied by the last Load or Reload, or "" if none was
+// given
+func Path() string {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	return appliedPath
+}
+
+// apply reads .env and the config file and sets the variables from them. It must be
+// called with loadMu held.
+func apply(path string) error {
+	dotEnv, err := ReadDotEnv(DotEnvFile)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		path = lookupEnv("CONFIG_FILE")
+	}
+	if path == "" {
+		path = dotEnv["CONFIG_FILE"]
+	}
+	values := map[string]string{}
+	if path != "" {
+		if values, err = ReadFile(path); err != nil {
+			return err
+		}
+	}
+	for name, value := range dotEnv {
+		values[name] = value
+	}
+	appliedPath = path
+
+	for name := range loaded {
+		if _, ok := values[name]; !ok {
+			os.Unsetenv(name)
+			delete(loaded, name)
+		}
+	}
+	for name, value := range values {
+		if _, set := os.LookupEnv(name); set && !loaded[name] {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+		loaded[name] = true
+	}
+	return nil
+}
+
+// lookupEnv returns a variable set in the environment rather than from the files
+func lookupEnv(name string) string {
+	if loaded[name] {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// WatchReloads calls Reload on every SIGHUP until the context is cancelled, and then
+// reloaded so the caller can apply the settings that may change at runtime. If the files
+// can't be read, the previous settings are kept.
+func WatchReloads(ctx context.Context, reloaded func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-signals:
+			if err := Reload(); err != nil {
+				logging.Warnf("Failed to reload configuration, keeping the previous settings: %v", err)
+				continue
+			}
+			logging.Infof("Reloaded configuration")
+			reloaded()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// clearEnv unsets variables for the test, restoring them afterwards, and forgets the
+// variables earlier tests loaded
+func clearEnv(t *testing.T, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	loaded = map[string]bool{}
+	t.Cleanup(func() { loaded = map[string]bool{} })
+}
+
+func TestLoadLayersEnvironmentOverDotEnvOverConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := writeConfig(t, "periodic.yaml", "db:\n  host: db.internal\n  name: periodic\n  port: 5433\n")
+	if err := os.WriteFile(filepath.Join(dir, DotEnvFile), []byte("DB_HOST=localhost\nDB_PORT=5434\nCONFIG_FILE="+configPath+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	t.Chdir(dir)
+	clearEnv(t, "DB_HOST", "DB_NAME", "CONFIG_FILE")
+	t.Setenv("DB_PORT", "5435")
+
+	// The config file is named in .env, whose settings take precedence over it
+	if err := Load(""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	expected := map[string]string{"DB_HOST": "localhost", "DB_NAME": "periodic", "DB_PORT": "5435"}
+	for name, value := range expected {
+		if got := os.Getenv(name); got != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	t.Chdir(t.TempDir())
+	clearEnv(t, "SCHEDULER_INTERVAL", "LLM_MODEL_ID", "LLM_DAILY_REQUEST_QUOTA", "TENANT_RATE_LIMIT")
+	t.Setenv("LLM_PROVIDER", "ollama")
+
+	path := writeConfig(t, "periodic.yaml", "scheduler:\n  interval: 30s\nllm:\n  provider: openai\n  model_id: llama3.1\n  daily_request_quota: 10\n")
+	if err := Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Changed settings take their new values, removed ones are unset and the environment still wins
+	if err := os.WriteFile(path, []byte("scheduler:\n  interval: 1m\ntenant:\n  rate_limit: 5\nllm:\n  provider: openai\n  model_id: llama3.2\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if os.Getenv("SCHEDULER_INTERVAL") != "1m" || os.Getenv("LLM_MODEL_ID") != "llama3.2" || os.Getenv("LLM_PROVIDER") != "ollama" {
+		t.Errorf("Unexpected settings after reload: interval %q, model %q, provider %q", os.Getenv("SCHEDULER_INTERVAL"), os.Getenv("LLM_MODEL_ID"), os.Getenv("LLM_PROVIDER"))
+	}
+	if os.Getenv("TENANT_RATE_LIMIT") != "5" {
+		t.Errorf("Expected the added tenant rate limit to be set, got %q", os.Getenv("TENANT_RATE_LIMIT"))
+	}
+	if _, set := os.LookupEnv("LLM_DAILY_REQUEST_QUOTA"); set {
+		t.Error("Expected the removed quota to be unset")
+	}
+
+	// An invalid file keeps the previous settings
+	if err := os.WriteFile(path, []byte("scheduler:\n  intervl: 2m\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := Reload(); err == nil || os.Getenv("SCHEDULER_INTERVAL") != "1m" {
+		t.Errorf("Expected the invalid file to be rejected, got %v with interval %q", err, os.Getenv("SCHEDULER_INTERVAL"))
+	}
+}
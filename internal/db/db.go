@@ -1,12 +1,17 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
+
+	"periodic-api/internal/logging"
 )
 
 // getEnvOrDefault returns the environment variable value or a default value
@@ -17,36 +22,206 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
-// InitDB initializes the database connection without running migrations
+// InitDB initializes the database connection without running migrations. When
+// DB_SECRET_ARN is set, the credentials come from that secret and follow its rotation.
+// Queries are recorded as spans of the trace in their context. Connections refused while
+// the database is resuming are retried for up to DB_RESUME_TIMEOUT.
 func InitDB() (*sql.DB, error) {
+	settings, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	db, err := open(settings, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Test the connection
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("db.Ping: %w", err)
+	}
+
+	return db, nil
+}
+
+// InitReadDB connects to the read replica at DB_READ_HOST, with the port, database name,
+// credentials and pool settings of the primary, or returns nil when DB_READ_HOST isn't
+// set. The replica's host is used even when DB_SECRET_ARN's secret names a host. The
+// connection isn't tested, so the API starts while the replica is unavailable and reads
+// from the primary until it can measure the replica's lag.
+func InitReadDB() (*sql.DB, error) {
+	readHost := os.Getenv("DB_READ_HOST")
+	if readHost == "" {
+		return nil, nil
+	}
+
+	settings, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	settings.host = readHost
+	db, err := open(settings, readHost)
+	if err != nil {
+		return nil, fmt.Errorf("read replica %s: %w", readHost, err)
+	}
+	return db, nil
+}
+
+// open returns the connection pool of the database with the given settings, or those of
+// DB_SECRET_ARN when it is set, connecting to host instead of the secret's host when it
+// isn't empty. No connection is opened yet.
+func open(settings dsnSettings, host string) (*sql.DB, error) {
+	// Size the connection pool and the retries while the database resumes
+	pool := PoolConfigFromEnv()
+
+	var db *sql.DB
+	if secretARN := os.Getenv("DB_SECRET_ARN"); secretARN != "" {
+		source, err := newSecretSource(context.Background(), secretARN)
+		if err != nil {
+			return nil, err
+		}
+		db = sql.OpenDB(&tracingConnector{
+			Connector: &resumingConnector{
+				Connector: &secretConnector{
+					source:   source,
+					settings: settings,
+					host:     host,
+					refresh:  durationFromEnv("DB_SECRET_REFRESH", defaultSecretRefresh),
+				},
+				timeout: pool.ResumeTimeout,
+				backoff: pool.ResumeBackoff,
+			},
+			name: settings.name,
+		})
+	} else {
+		// Connect to PostgreSQL
+		connector, err := pq.NewConnector(settings.dsn())
+		if err != nil {
+			return nil, fmt.Errorf("pq.NewConnector: %w", err)
+		}
+		db = sql.OpenDB(&tracingConnector{
+			Connector: &resumingConnector{Connector: connector, timeout: pool.ResumeTimeout, backoff: pool.ResumeBackoff},
+			name:      settings.name,
+		})
+	}
+
+	// Size the connection pool before the first connection is opened
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// NewListener creates a PostgreSQL LISTEN connection subscribed to the given notification
+// channel. With DB_SECRET_ARN, it uses the credentials current when it is created.
+func NewListener(channel string) (*pq.Listener, error) {
+	settings, err := settingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if secretARN := os.Getenv("DB_SECRET_ARN"); secretARN != "" {
+		source, err := newSecretSource(context.Background(), secretARN)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := source.fetch(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("fetching database secret: %w", err)
+		}
+		if settings, err = secret.apply(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	listener := pq.NewListener(settings.dsn(), 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logging.Errorf("Database listener error: %v", err)
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("listener.Listen: %w", err)
+	}
+
+	return listener, nil
+}
+
+// dsnSettings are the details of a PostgreSQL connection
+type dsnSettings struct {
+	host     string
+	port     int
+	user     string
+	password string
+	name     string
+	sslMode  string
+}
+
+// settingsFromEnv reads the connection details from environment variables
+func settingsFromEnv() (dsnSettings, error) {
 	// Get database connection details from environment variables or use defaults
-	dbHost := getEnvOrDefault("DB_HOST", "localhost")
 	dbPortStr := getEnvOrDefault("DB_PORT", "5432")
-	dbUser := getEnvOrDefault("DB_USER", "eldon")
-	dbPass := getEnvOrDefault("DB_PASSWORD", "moron")
-	dbName := getEnvOrDefault("DB_NAME", "periodic_db")
 
 	// Convert port to integer
 	dbPort, err := strconv.Atoi(dbPortStr)
 	if err != nil {
-		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
+		return dsnSettings{}, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
-	// Build connection string with SSL mode based on environment
-	sslMode := getEnvOrDefault("DB_SSL_MODE", "disable")
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPass, dbName, sslMode)
+	return dsnSettings{
+		host:     getEnvOrDefault("DB_HOST", "localhost"),
+		port:     dbPort,
+		user:     getEnvOrDefault("DB_USER", "eldon"),
+		password: getEnvOrDefault("DB_PASSWORD", "moron"),
+		name:     getEnvOrDefault("DB_NAME", "periodic_db"),
+		// SSL mode based on environment
+		sslMode: getEnvOrDefault("DB_SSL_MODE", "disable"),
+	}, nil
+}
+
+// dsn builds the PostgreSQL connection string. Values are quoted, since generated
+// passwords can contain spaces and quotes.
+func (s dsnSettings) dsn() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		quoteDSN(s.host), s.port, quoteDSN(s.user), quoteDSN(s.password), quoteDSN(s.name), quoteDSN(s.sslMode))
+}
+
+// quoteDSN quotes a connection string value, escaping backslashes and single quotes
+func quoteDSN(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
 
-	// Connect to PostgreSQL
-	db, err := sql.Open("postgres", dsn)
+// DumpEnv returns the libpq environment variables (PGHOST, PGPASSWORD and so on) that
+// connect tools such as pg_dump to the database InitDB connects to, with the current
+// credentials of DB_SECRET_ARN when it is set
+func DumpEnv(ctx context.Context) ([]string, error) {
+	settings, err := settingsFromEnv()
 	if err != nil {
-		return nil, fmt.Errorf("sql.Open: %w", err)
+		return nil, err
 	}
-
-	// Test the connection
-	if err = db.Ping(); err != nil {
-		return nil, fmt.Errorf("db.Ping: %w", err)
+	if secretARN := os.Getenv("DB_SECRET_ARN"); secretARN != "" {
+		source, err := newSecretSource(ctx, secretARN)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := source.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if settings, err = secret.apply(settings); err != nil {
+			return nil, err
+		}
 	}
 
-	return db, nil
+	return []string{
+		"PGHOST=" + settings.host,
+		"PGPORT=" + strconv.Itoa(settings.port),
+		"PGUSER=" + settings.user,
+		"PGPASSWORD=" + settings.password,
+		"PGDATABASE=" + settings.name,
+		"PGSSLMODE=" + settings.sslMode,
+	}, nil
 }
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"periodic-api/internal/tracing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// DynamoTableName returns the name of the DynamoDB table from the DYNAMODB_TABLE environment variable
+func DynamoTableName() string {
+	return getEnvOrDefault("DYNAMODB_TABLE", "periodic")
+}
+
+// NewDynamoClient creates a DynamoDB client using the default AWS credential chain, so
+// Lambda and Fargate task roles work without configuration. DYNAMODB_ENDPOINT overrides
+// the service endpoint, e.g. to use DynamoDB Local during development.
+func NewDynamoClient(ctx context.Context) (*dynamodb.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+
+	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		o.TracerProvider = tracing.AWS()
+		if endpoint := getEnvOrDefault("DYNAMODB_ENDPOINT", ""); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	return client, nil
+}
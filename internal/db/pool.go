@@ -0,0 +1,158 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// Pool defaults suit Aurora Serverless, which scales on connection count and can only
+// pause once every connection is closed: keep the pool small and let idle connections go
+const (
+	defaultMaxOpenConns    = 10
+	defaultMaxIdleConns    = 2
+	defaultConnMaxLifetime = 5 * time.Minute
+	defaultConnMaxIdleTime = time.Minute
+	// A paused Aurora Serverless v2 cluster usually resumes within 15 seconds
+	defaultResumeTimeout = 30 * time.Second
+	defaultResumeBackoff = 500 * time.Millisecond
+)
+
+// PoolConfig configures the database connection pool
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ResumeTimeout is how long opening a connection keeps retrying while the database is
+	// resuming, e.g. after Aurora Serverless scaled to zero; zero disables the retries
+	ResumeTimeout time.Duration
+	// ResumeBackoff is the wait before the first retry; it doubles for every further retry
+	ResumeBackoff time.Duration
+}
+
+// PoolConfigFromEnv returns the pool configuration from the DB_MAX_OPEN_CONNS,
+// DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME, DB_CONN_MAX_IDLE_TIME, DB_RESUME_TIMEOUT and
+// DB_RESUME_BACKOFF environment variables
+func PoolConfigFromEnv() PoolConfig {
+	config := PoolConfig{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+		ConnMaxIdleTime: defaultConnMaxIdleTime,
+		ResumeTimeout:   defaultResumeTimeout,
+		ResumeBackoff:   defaultResumeBackoff,
+	}
+
+	config.MaxOpenConns = intFromEnv("DB_MAX_OPEN_CONNS", config.MaxOpenConns)
+	config.MaxIdleConns = intFromEnv("DB_MAX_IDLE_CONNS", config.MaxIdleConns)
+	config.ConnMaxLifetime = durationFromEnv("DB_CONN_MAX_LIFETIME", config.ConnMaxLifetime)
+	config.ConnMaxIdleTime = durationFromEnv("DB_CONN_MAX_IDLE_TIME", config.ConnMaxIdleTime)
+	config.ResumeTimeout = durationFromEnv("DB_RESUME_TIMEOUT", config.ResumeTimeout)
+	config.ResumeBackoff = durationFromEnv("DB_RESUME_BACKOFF", config.ResumeBackoff)
+	if config.ResumeBackoff == 0 {
+		config.ResumeBackoff = defaultResumeBackoff
+	}
+
+	// database/sql lowers the idle limit to the open limit anyway; do it here so it is logged correctly
+	if config.MaxIdleConns > config.MaxOpenConns {
+		config.MaxIdleConns = config.MaxOpenConns
+	}
+	return config
+}
+
+// intFromEnv reads a non-negative integer from the environment, falling back to a default
+func intFromEnv(key string, defaultValue int) int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		logging.Warnf("Invalid %s, using default: %d", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// durationFromEnv reads a non-negative duration from the environment, falling back to a default
+func durationFromEnv(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value < 0 {
+		logging.Warnf("Invalid %s format, using default: %v", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// PoolStats reports the state of the database connection pool
+type PoolStats struct {
+	MaxOpenConnections int    `json:"maxOpenConnections"`
+	OpenConnections    int    `json:"openConnections"`
+	InUse              int    `json:"inUse"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"waitCount"`
+	WaitDuration       string `json:"waitDuration" example:"1.5s"`
+	MaxIdleClosed      int64  `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64  `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`
+}
+
+// NewPoolStats converts database/sql pool statistics for reporting
+func NewPoolStats(stats sql.DBStats) PoolStats {
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// poolMetrics are the pool statistics written by WritePoolMetrics
+var poolMetrics = []struct {
+	name, kind, help string
+	value            func(stats sql.DBStats) float64
+}{
+	{"db_pool_max_open", "gauge", "Maximum number of open connections to the database; 0 when unlimited.",
+		func(stats sql.DBStats) float64 { return float64(stats.MaxOpenConnections) }},
+	{"db_pool_open", "gauge", "Connections to the database, in use or idle.",
+		func(stats sql.DBStats) float64 { return float64(stats.OpenConnections) }},
+	{"db_pool_in_use", "gauge", "Connections currently in use.",
+		func(stats sql.DBStats) float64 { return float64(stats.InUse) }},
+	{"db_pool_idle", "gauge", "Idle connections.",
+		func(stats sql.DBStats) float64 { return float64(stats.Idle) }},
+	{"db_pool_wait_count_total", "counter", "Times a caller waited for a connection.",
+		func(stats sql.DBStats) float64 { return float64(stats.WaitCount) }},
+	{"db_pool_wait_seconds_total", "counter", "Time spent waiting for a connection.",
+		func(stats sql.DBStats) float64 { return stats.WaitDuration.Seconds() }},
+	{"db_pool_max_idle_closed_total", "counter", "Connections closed because too many were idle.",
+		func(stats sql.DBStats) float64 { return float64(stats.MaxIdleClosed) }},
+	{"db_pool_max_idle_time_closed_total", "counter", "Connections closed after being idle for too long.",
+		func(stats sql.DBStats) float64 { return float64(stats.MaxIdleTimeClosed) }},
+	{"db_pool_max_lifetime_closed_total", "counter", "Connections closed for reaching their maximum lifetime.",
+		func(stats sql.DBStats) float64 { return float64(stats.MaxLifetimeClosed) }},
+}
+
+// WritePoolMetrics writes the connection pool statistics in the Prometheus text
+// exposition format
+func WritePoolMetrics(w io.Writer, stats sql.DBStats) {
+	for _, metric := range poolMetrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		fmt.Fprintf(w, "%s %s\n", metric.name, strconv.FormatFloat(metric.value(stats), 'g', -1, 64))
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+
+	"periodic-api/internal/logging"
+)
+
+// maxResumeBackoff caps the wait between connection attempts while the database resumes
+const maxResumeBackoff = 5 * time.Second
+
+// resumingConnector retries connections the database refuses while it is resuming, as
+// Aurora Serverless v2 does for several seconds after scaling to zero capacity, so the
+// first query after an idle period waits for the database instead of failing. Attempts
+// back off exponentially from backoff until timeout has passed or the context is done.
+type resumingConnector struct {
+	driver.Connector
+	timeout time.Duration
+	backoff time.Duration
+}
+
+// Connect opens a connection, retrying while the database is resuming
+func (c *resumingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err == nil || c.timeout <= 0 || !IsResuming(err) {
+		return conn, err
+	}
+
+	startedAt := time.Now()
+	backoff := c.backoff
+	for attempt := 2; ; attempt++ {
+		if time.Since(startedAt)+backoff > c.timeout {
+			return nil, err
+		}
+		logging.Warnf("Database is not accepting connections, retrying in %v (attempt %d): %v", backoff, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+
+		if conn, err = c.Connector.Connect(ctx); err == nil {
+			logging.Infof("Database accepted the connection after %v", time.Since(startedAt).Round(time.Millisecond))
+			return conn, nil
+		}
+		if !IsResuming(err) {
+			return nil, err
+		}
+		backoff = min(backoff*2, maxResumeBackoff)
+	}
+}
+
+// IsResuming reports whether err means the database can't serve connections for now but
+// is expected to shortly: it is resuming from a pause, starting up or shutting down, or
+// the connection was refused, reset or timed out on the way
+func IsResuming(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// cannot_connect_now, admin_shutdown, crash_shutdown and the connection_exception class
+		switch pqErr.Code {
+		case "57P03", "57P01", "57P02":
+			return true
+		}
+		return pqErr.Code.Class() == "08" || strings.Contains(strings.ToLower(pqErr.Message), "resuming")
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "database is resuming")
+}
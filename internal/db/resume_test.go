@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// flakyConnector fails its first connections with err
+type flakyConnector struct {
+	failures int
+	err      error
+	attempts int
+}
+
+func (c *flakyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.attempts++
+	if c.attempts <= c.failures {
+		return nil, c.err
+	}
+	return nil, nil
+}
+
+func (c *flakyConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func TestResumingConnectorRetriesWhileResuming(t *testing.T) {
+	inner := &flakyConnector{failures: 2, err: &pq.Error{Code: "57P03", Message: "the database system is starting up"}}
+	connector := &resumingConnector{Connector: inner, timeout: time.Second, backoff: time.Millisecond}
+
+	if _, err := connector.Connect(context.Background()); err != nil {
+		t.Fatalf("Expected the connection to succeed once the database resumed, got %v", err)
+	}
+	if inner.attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", inner.attempts)
+	}
+}
+
+func TestResumingConnectorGivesUp(t *testing.T) {
+	resuming := fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+	inner := &flakyConnector{failures: 1000, err: resuming}
+	connector := &resumingConnector{Connector: inner, timeout: 20 * time.Millisecond, backoff: time.Millisecond}
+	if _, err := connector.Connect(context.Background()); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("Expected the connection error after the timeout, got %v", err)
+	}
+	if inner.attempts < 2 {
+		t.Errorf("Expected retries before giving up, got %d attempts", inner.attempts)
+	}
+
+	// Other errors, such as rejected credentials, aren't retried
+	inner = &flakyConnector{failures: 1000, err: &pq.Error{Code: "28P01"}}
+	connector = &resumingConnector{Connector: inner, timeout: time.Second, backoff: time.Millisecond}
+	if _, err := connector.Connect(context.Background()); err == nil || inner.attempts != 1 {
+		t.Errorf("Expected a single failed attempt, got %d (%v)", inner.attempts, err)
+	}
+
+	// Nor is anything once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	inner = &flakyConnector{failures: 1000, err: resuming}
+	connector = &resumingConnector{Connector: inner, timeout: time.Minute, backoff: time.Second}
+	if _, err := connector.Connect(ctx); !errors.Is(err, context.Canceled) || inner.attempts != 1 {
+		t.Errorf("Expected the cancellation after one attempt, got %d (%v)", inner.attempts, err)
+	}
+}
+
+func TestIsResuming(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{&pq.Error{Code: "57P03"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "XX000", Message: "Database is resuming"}, true},
+		{&pq.Error{Code: "28P01"}, false},
+		{&pq.Error{Code: "42P01"}, false},
+		{fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED), true},
+		{driver.ErrBadConn, true},
+		{errors.New("pq: database is resuming"), true},
+		{errors.New("syntax error"), false},
+	}
+	for _, test := range tests {
+		if got := IsResuming(test.err); got != test.expected {
+			t.Errorf("IsResuming(%v) = %v, expected %v", test.err, got, test.expected)
+		}
+	}
+}
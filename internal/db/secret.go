@@ -0,0 +1,234 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/lib/pq"
+
+	"periodic-api/internal/awsapi"
+	"periodic-api/internal/logging"
+)
+
+// defaultSecretRefresh is how often credentials are fetched again when DB_SECRET_REFRESH is not set
+const defaultSecretRefresh = 15 * time.Minute
+
+// dbSecret holds the connection details in a Secrets Manager secret or SSM parameter, in
+// the JSON format RDS uses for the secrets it manages. Only the username and password are
+// required; the other fields override the DB_* environment variables when present.
+type dbSecret struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Host     string `json:"host"`
+	// Port is a number in RDS secrets but often a string in hand-written ones
+	Port   json.RawMessage `json:"port"`
+	DBName string          `json:"dbname"`
+}
+
+// apply overrides the connection settings with the details in the secret
+func (s dbSecret) apply(settings dsnSettings) (dsnSettings, error) {
+	settings.user = s.Username
+	settings.password = s.Password
+	if s.Host != "" {
+		settings.host = s.Host
+	}
+	if s.DBName != "" {
+		settings.name = s.DBName
+	}
+	if len(s.Port) > 0 && string(s.Port) != "null" {
+		port, err := strconv.Atoi(strings.Trim(string(s.Port), `"`))
+		if err != nil {
+			return dsnSettings{}, fmt.Errorf("invalid port in database secret: %s", s.Port)
+		}
+		settings.port = port
+	}
+	return settings, nil
+}
+
+// secretSource fetches the database secret named by DB_SECRET_ARN: a Secrets Manager
+// secret or an SSM SecureString parameter. The services' JSON APIs are called directly,
+// signed with the default AWS credential chain, so no further SDK modules are needed.
+type secretSource struct {
+	arn     string
+	service string
+	client  *awsapi.Client
+}
+
+// newSecretSource creates a source for the secret or parameter with the given ARN, calling
+// the service's endpoint in the ARN's partition and region
+func newSecretSource(ctx context.Context, secretARN string) (*secretSource, error) {
+	parsed, err := arn.Parse(secretARN)
+	if err != nil || parsed.Region == "" {
+		return nil, fmt.Errorf("invalid DB_SECRET_ARN %q", secretARN)
+	}
+	switch {
+	case parsed.Service == "secretsmanager" && strings.HasPrefix(parsed.Resource, "secret:"):
+	case parsed.Service == "ssm" && strings.HasPrefix(parsed.Resource, "parameter/"):
+	default:
+		return nil, fmt.Errorf("DB_SECRET_ARN %q is neither a Secrets Manager secret nor an SSM parameter", secretARN)
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(parsed.Region))
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+
+	endpoint := awsapi.PartitionEndpoint(parsed.Partition, parsed.Service, parsed.Region)
+	return &secretSource{
+		arn:     secretARN,
+		service: parsed.Service,
+		client:  awsapi.NewClient(cfg, parsed.Service, parsed.Region, endpoint),
+	}, nil
+}
+
+// fetch retrieves and parses the current value of the secret
+func (s *secretSource) fetch(ctx context.Context) (dbSecret, error) {
+	var target string
+	var input any
+	if s.service == "ssm" {
+		target = "AmazonSSM.GetParameter"
+		input = map[string]any{"Name": s.arn, "WithDecryption": true}
+	} else {
+		target = "secretsmanager.GetSecretValue"
+		input = map[string]any{"SecretId": s.arn}
+	}
+
+	var output struct {
+		SecretString string
+		Parameter    struct{ Value string }
+	}
+	if err := s.call(ctx, target, input, &output); err != nil {
+		return dbSecret{}, err
+	}
+	value := output.SecretString
+	if s.service == "ssm" {
+		value = output.Parameter.Value
+	}
+
+	var secret dbSecret
+	if err := json.Unmarshal([]byte(value), &secret); err != nil {
+		return dbSecret{}, fmt.Errorf("database secret is not a JSON object: %w", err)
+	}
+	if secret.Username == "" || secret.Password == "" {
+		return dbSecret{}, errors.New("database secret has no username or password")
+	}
+	return secret, nil
+}
+
+// call makes a signed request to the service's JSON API
+func (s *secretSource) call(ctx context.Context, target string, input any, output any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	respBody, err := s.client.Post(ctx, body, map[string]string{
+		"Content-Type": "application/x-amz-json-1.1",
+		"X-Amz-Target": target,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", target, err)
+	}
+	if err := json.Unmarshal(respBody, output); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", target, err)
+	}
+	return nil
+}
+
+// secretConnector opens connections with the credentials in the database secret. The
+// secret is fetched again once it is older than the refresh interval, and straight away
+// when the database rejects the credentials, so rotation doesn't need a restart.
+type secretConnector struct {
+	source   *secretSource
+	settings dsnSettings
+	// host replaces the secret's host when set, e.g. to connect to a read replica
+	host    string
+	refresh time.Duration
+
+	mu        sync.Mutex
+	current   dsnSettings
+	fetchedAt time.Time
+}
+
+// Connect opens a connection, fetching the secret again and retrying once if the
+// credentials have been rotated
+func (c *secretConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	settings, err := c.credentials(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := connect(ctx, settings)
+	if !isAuthFailure(err) {
+		return conn, err
+	}
+
+	logging.Warnf("Database rejected the credentials, fetching the secret again: %v", err)
+	if settings, err = c.credentials(ctx, true); err != nil {
+		return nil, err
+	}
+	return connect(ctx, settings)
+}
+
+// Driver returns the PostgreSQL driver
+func (c *secretConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// credentials returns the connection settings from the secret, fetching it if forced or
+// the last fetch is older than the refresh interval
+func (c *secretConnector) credentials(ctx context.Context, force bool) (dsnSettings, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !force && !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.refresh {
+		return c.current, nil
+	}
+
+	secret, err := c.source.fetch(ctx)
+	if err != nil {
+		if !c.fetchedAt.IsZero() && !force {
+			// Keep using the credentials we have; they are likely still valid
+			logging.Warnf("Error refreshing database secret, using the previous credentials: %v", err)
+			return c.current, nil
+		}
+		return dsnSettings{}, fmt.Errorf("fetching database secret: %w", err)
+	}
+	settings, err := secret.apply(c.settings)
+	if err != nil {
+		return dsnSettings{}, err
+	}
+	if c.host != "" {
+		settings.host = c.host
+	}
+
+	c.current = settings
+	c.fetchedAt = time.Now()
+	return settings, nil
+}
+
+// connect opens a single connection with the given settings
+func connect(ctx context.Context, settings dsnSettings) (driver.Conn, error) {
+	connector, err := pq.NewConnector(settings.dsn())
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+// isAuthFailure reports whether the database rejected the user or password
+func isAuthFailure(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	// invalid_password and invalid_authorization_specification
+	return pqErr.Code == "28P01" || pqErr.Code == "28000"
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"periodic-api/internal/awsapi"
+)
+
+// newTestSecretSource returns a source for arn calling the given test server
+func newTestSecretSource(t *testing.T, arn string, handler http.HandlerFunc) *secretSource {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	parts := strings.Split(arn, ":")
+	cfg := aws.Config{Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""))}
+	return &secretSource{
+		arn:     arn,
+		service: parts[2],
+		client:  awsapi.NewClient(cfg, parts[2], parts[3], server.URL),
+	}
+}
+
+func TestSecretSourceFetch(t *testing.T) {
+	arn := "arn:aws:secretsmanager:us-east-1:123456789012:secret:periodic-db-AbCdEf"
+	var target, authorization string
+	var input map[string]any
+	source := newTestSecretSource(t, arn, func(w http.ResponseWriter, r *http.Request) {
+		target = r.Header.Get("X-Amz-Target")
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&input)
+		json.NewEncoder(w).Encode(map[string]string{
+			"SecretString": `{"username":"periodic","password":"p@ss word's","host":"db.internal","port":5433,"dbname":"periodic_api_db","engine":"postgres"}`,
+		})
+	})
+
+	secret, err := source.fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if target != "secretsmanager.GetSecretValue" || input["SecretId"] != arn || !strings.Contains(authorization, "us-east-1/secretsmanager/aws4_request") {
+		t.Errorf("Unexpected request %s %v signed with %q", target, input, authorization)
+	}
+
+	settings, err := secret.apply(dsnSettings{host: "localhost", port: 5432, user: "eldon", password: "moron", name: "periodic_db", sslMode: "require"})
+	if err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	expected := `host='db.internal' port=5433 user='periodic' password='p@ss word\'s' dbname='periodic_api_db' sslmode='require'`
+	if dsn := settings.dsn(); dsn != expected {
+		t.Errorf("Expected DSN %s, got %s", expected, dsn)
+	}
+}
+
+func TestSecretSourceFetchSSMParameter(t *testing.T) {
+	arn := "arn:aws:ssm:eu-west-1:123456789012:parameter/periodic/db"
+	var input map[string]any
+	source := newTestSecretSource(t, arn, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&input)
+		json.NewEncoder(w).Encode(map[string]any{
+			"Parameter": map[string]string{"Value": `{"username":"periodic","password":"secret","port":"5433"}`},
+		})
+	})
+
+	secret, err := source.fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if input["Name"] != arn || input["WithDecryption"] != true {
+		t.Errorf("Expected a decrypted GetParameter request, got %v", input)
+	}
+	settings, err := secret.apply(dsnSettings{host: "localhost", port: 5432})
+	if err != nil || settings.port != 5433 || settings.host != "localhost" || settings.password != "secret" {
+		t.Errorf("Unexpected settings %+v (%v)", settings, err)
+	}
+}
+
+func TestSecretSourceErrors(t *testing.T) {
+	for _, arn := range []string{"periodic-db", "arn:aws:s3:::bucket", "arn:aws:secretsmanager:us-east-1:123456789012:rotation:x"} {
+		if _, err := newSecretSource(context.Background(), arn); err == nil {
+			t.Errorf("Expected %q to be rejected", arn)
+		}
+	}
+
+	arn := "arn:aws:secretsmanager:us-east-1:123456789012:secret:periodic-db-AbCdEf"
+	source := newTestSecretSource(t, arn, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"__type":"AccessDeniedException","Message":"not authorized"}`))
+	})
+	if _, err := source.fetch(context.Background()); err == nil || !strings.Contains(err.Error(), `"__type":"AccessDeniedException","Message":"not authorized"`) {
+		t.Errorf("Expected the service error, got %v", err)
+	}
+
+	source = newTestSecretSource(t, arn, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"username":"periodic"}`})
+	})
+	if _, err := source.fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "no username or password") {
+		t.Errorf("Expected a missing password error, got %v", err)
+	}
+}
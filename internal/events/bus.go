@@ -0,0 +1,153 @@
+// Package events fans out changes made by the stores and scheduler to connected clients
+package events
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// Event types published on the bus
+const (
+	TodoCreated              = "todo.created"
+	TodoUpdated              = "todo.updated"
+	TodoDeleted              = "todo.deleted"
+	ScheduledItemCreated     = "scheduled_item.created"
+	ScheduledItemUpdated     = "scheduled_item.updated"
+	ScheduledItemRescheduled = "scheduled_item.rescheduled"
+	ScheduledItemDeleted     = "scheduled_item.deleted"
+	ScheduledItemExecuted    = "scheduled_item.executed"
+	ScheduledItemFailed      = "scheduled_item.failed"
+	ScheduledItemSkipped     = "scheduled_item.skipped"
+)
+
+// Types lists every event type published on the bus
+var Types = []string{
+	TodoCreated,
+	TodoUpdated,
+	TodoDeleted,
+	ScheduledItemCreated,
+	ScheduledItemUpdated,
+	ScheduledItemRescheduled,
+	ScheduledItemDeleted,
+	ScheduledItemExecuted,
+	ScheduledItemFailed,
+	ScheduledItemSkipped,
+}
+
+const (
+	// subscriberBuffer is the number of events buffered per subscriber before new events
+	// are dropped for that subscriber
+	subscriberBuffer = 64
+	// historySize is the number of recent events kept for subscribers resuming after a disconnect
+	historySize = 1000
+)
+
+// Event is a change published on the bus
+type Event struct {
+	// ID increases with every event published on the bus
+	ID   int64     `json:"id" example:"42"`
+	Type string    `json:"type" example:"todo.created"`
+	Time time.Time `json:"time" example:"2024-01-01T09:00:00Z"`
+	// Data is the created or updated model, or {"id": ...} for deletions
+	Data any `json:"data" swaggertype:"object"`
+	// Tenant owns the changed data; subscribers only pass events on to clients of the
+	// same tenant. Events published without a tenant belong to the default tenant.
+	Tenant string `json:"-"`
+}
+
+// Bus delivers published events to every subscriber in this process, keeping the most
+// recent ones so subscribers can catch up on events they missed
+type Bus struct {
+	mu          sync.Mutex
+	nextID      int64
+	subscribers map[chan Event]struct{}
+	history     []Event
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+// Publish assigns the event an ID and delivers it to every subscriber without blocking
+func (b *Bus) Publish(eventType string, data any) Event {
+	return b.PublishTenant("", eventType, data)
+}
+
+// PublishTenant publishes an event about a change to the data of a tenant
+func (b *Bus) PublishTenant(tenant string, eventType string, data any) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := Event{
+		ID:     b.nextID,
+		Type:   eventType,
+		Time:   time.Now(),
+		Data:   data,
+		Tenant: tenant,
+	}
+
+	b.history = append(b.history, event)
+	if len(b.history) > historySize {
+		b.history = slices.Delete(b.history, 0, len(b.history)-historySize)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logging.Warnf("Event subscriber is falling behind, dropping event ID=%d", event.ID)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a new subscriber and returns its channel along with a
+// function that must be called to unsubscribe and release the channel
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.subscribeLocked()
+}
+
+// SubscribeAfter registers a new subscriber like Subscribe, and also returns the retained
+// events published after the event with ID lastID, so a client reconnecting with the last
+// ID it saw misses nothing. Events older than the retained history can't be replayed.
+func (b *Bus) SubscribeAfter(lastID int64) ([]Event, <-chan Event, func()) {
+	// Collect the replay and register under one lock so no event falls in between
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start, _ := slices.BinarySearchFunc(b.history, lastID, func(event Event, id int64) int {
+		return cmp.Compare(event.ID, id+1)
+	})
+	missed := slices.Clone(b.history[start:])
+	ch, unsubscribe := b.subscribeLocked()
+	return missed, ch, unsubscribe
+}
+
+// subscribeLocked registers a subscriber channel; the caller must hold the lock
+func (b *Bus) subscribeLocked() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
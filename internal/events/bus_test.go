@@ -0,0 +1,36 @@
+package events
+
+import "testing"
+
+func TestSubscribeAfterReplaysMissedEvents(t *testing.T) {
+	bus := NewBus()
+	first := bus.Publish(TodoCreated, nil)
+	bus.Publish(TodoUpdated, nil)
+	bus.Publish(TodoDeleted, nil)
+
+	missed, ch, unsubscribe := bus.SubscribeAfter(first.ID)
+	defer unsubscribe()
+
+	if len(missed) != 2 || missed[0].Type != TodoUpdated || missed[1].Type != TodoDeleted {
+		t.Fatalf("Expected the two events after ID %d, got %+v", first.ID, missed)
+	}
+
+	live := bus.Publish(ScheduledItemExecuted, nil)
+	if event := <-ch; event.ID != live.ID {
+		t.Errorf("Expected live event ID %d, got %d", live.ID, event.ID)
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	bus := NewBus()
+	for range historySize + 10 {
+		bus.Publish(TodoCreated, nil)
+	}
+
+	missed, _, unsubscribe := bus.SubscribeAfter(0)
+	defer unsubscribe()
+
+	if len(missed) != historySize || missed[0].ID != 11 {
+		t.Errorf("Expected the last %d events starting at ID 11, got %d starting at %d", historySize, len(missed), missed[0].ID)
+	}
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// impersonationLifetime is how long support tokens can be used for
+const impersonationLifetime = time.Hour
+
+// ImpersonationToken is a support token for making requests as a user
+type ImpersonationToken struct {
+	Token     string    `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Tenant    string    `json:"tenant" example:"acme"`
+	UserID    int64     `json:"userId" example:"1"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AdminHandler handles HTTP requests for managing tenants and their users
+type AdminHandler struct {
+	users    store.UserStore
+	items    store.ScheduledItemStore
+	todos    store.TodoItemStore
+	webhooks store.WebhookStore
+	usage    store.LLMUsageStore
+	limits   *TenantLimitHandler
+	secret   []byte
+}
+
+// NewAdminHandler creates a new handler with the given stores. Support tokens are signed
+// with secret; without one, requests aren't authenticated and none are issued.
+func NewAdminHandler(users store.UserStore, items store.ScheduledItemStore, todos store.TodoItemStore, webhooks store.WebhookStore, usage store.LLMUsageStore, limits *TenantLimitHandler, secret []byte) *AdminHandler {
+	return &AdminHandler{
+		users:    users,
+		items:    items,
+		todos:    todos,
+		webhooks: webhooks,
+		usage:    usage,
+		limits:   limits,
+		secret:   secret,
+	}
+}
+
+// RejectDeactivatedUsers refuses requests made as a deactivated user with 403
+func RejectDeactivatedUsers(users store.UserStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if userID, ok := requestUser(r); ok {
+			if user, exists := users.GetUser(r.Context(), userID); exists && user.DeactivatedAt != nil {
+				problem.Write(w, r, http.StatusForbidden, "User is deactivated")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// summarize counts what a tenant has
+func (h *AdminHandler) summarize(ctx context.Context, tenant string) models.TenantSummary {
+	ctx = store.WithTenant(store.WithoutUser(ctx), tenant)
+	since, until := usageDay(time.Now())
+	return models.TenantSummary{
+		Tenant:           tenant,
+		Users:            len(h.users.GetAllUsers(ctx)),
+		ScheduledItems:   len(h.items.GetAllScheduledItems(ctx)),
+		TodoItems:        len(h.todos.GetAllTodoItems(ctx)),
+		Webhooks:         len(h.webhooks.GetAllWebhooks(ctx)),
+		GenerationsToday: len(h.usage.GetLLMUsage(ctx, "", since, until)),
+		Limits:           h.limits.limits(ctx, tenant),
+	}
+}
+
+// tenantUser returns the tenant and user named by a request's path, writing the problem
+// and returning false when either is invalid
+func (h *AdminHandler) tenantUser(w http.ResponseWriter, r *http.Request) (context.Context, int64, bool) {
+	tenant := strings.TrimSpace(r.PathValue("tenant"))
+	if tenant == "" {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid tenant")
+		return nil, 0, false
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return nil, 0, false
+	}
+	return store.WithTenant(r.Context(), tenant), id, true
+}
+
+// HandleGetTenants handles GET requests to list the tenants
+// @Summary List tenants
+// @Description List the tenants that have users or limits of their own, ordered by tenant, with the number of users, scheduled items, todo items and webhooks they have, the generation requests they made today and their limits. Requires a tenant token with the admin claim when tenant tokens are checked.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.TenantSummary
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Router /admin/tenants [get]
+func (h *AdminHandler) HandleGetTenants(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	tenants := h.users.GetTenants(r.Context())
+	for _, limits := range h.limits.store.GetAllTenantLimits(r.Context()) {
+		if !slices.Contains(tenants, limits.Tenant) {
+			tenants = append(tenants, limits.Tenant)
+		}
+	}
+	slices.Sort(tenants)
+
+	summaries := make([]models.TenantSummary, 0, len(tenants))
+	for _, tenant := range tenants {
+		summaries = append(summaries, h.summarize(r.Context(), tenant))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
+}
+
+// HandleGetTenant handles GET requests to count what a tenant has
+// @Summary Get a tenant
+// @Description Get the number of users, scheduled items, todo items and webhooks a tenant has, the generation requests it made today and its limits. Tenants that have nothing are counted as empty.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} models.TenantSummary
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Router /admin/tenants/{tenant} [get]
+func (h *AdminHandler) HandleGetTenant(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.summarize(r.Context(), r.PathValue("tenant")))
+}
+
+// HandleGetTenantUsers handles GET requests to list the users of a tenant
+// @Summary List the users of a tenant
+// @Description List the users of a tenant, deactivated or not
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param sort query string false "Sort by id, createdAt or updatedAt; prefix with - for descending order"
+// @Success 200 {array} models.User
+// @Failure 400 {object} problem.Details "Invalid sort field"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Router /admin/tenants/{tenant}/users [get]
+func (h *AdminHandler) HandleGetTenantUsers(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	users := h.users.GetAllUsers(store.WithTenant(r.Context(), r.PathValue("tenant")))
+	if err := sortItems(users, r.URL.Query().Get("sort"), userSortFields); err != nil {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "sort", Message: err.Error()}).Write(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(users)
+}
+
+// HandleDeactivateUser handles POST requests to deactivate a user
+// @Summary Deactivate a user
+// @Description Deactivate a user of a tenant. Requests made as the user are refused with 403 and they are no longer notified, but their items and todos are kept. Deactivating a deactivated user keeps the time they were first deactivated.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 404 {object} problem.Details "User not found"
+// @Router /admin/tenants/{tenant}/users/{id}/deactivate [post]
+func (h *AdminHandler) HandleDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	ctx, id, ok := h.tenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, exists := h.users.GetUser(ctx, id)
+	if exists && user.DeactivatedAt == nil {
+		now := time.Now()
+		user, exists = h.users.SetUserDeactivated(ctx, id, &now)
+	}
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// HandleReactivateUser handles POST requests to reactivate a user
+// @Summary Reactivate a user
+// @Description Reactivate a deactivated user of a tenant, so they can make requests and be notified again
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 404 {object} problem.Details "User not found"
+// @Router /admin/tenants/{tenant}/users/{id}/reactivate [post]
+func (h *AdminHandler) HandleReactivateUser(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	ctx, id, ok := h.tenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, exists := h.users.SetUserDeactivated(ctx, id, nil)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// HandleImpersonateUser handles POST requests for a support token acting as a user
+// @Summary Impersonate a user
+// @Description Issue a support token for making requests as a user of a tenant, valid for an hour, to reproduce what they see. The token has no admin claim, and the changes made with it are audited as the user's, impersonated by the administrator. Only available when tenant tokens are checked.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param id path int true "User ID"
+// @Param X-User-ID header string false "ID of the administrator making the request"
+// @Success 200 {object} ImpersonationToken
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 404 {object} problem.Details "User not found"
+// @Failure 409 {object} problem.Details "User is deactivated"
+// @Failure 503 {object} problem.Details "Impersonation not available"
+// @Router /admin/tenants/{tenant}/users/{id}/impersonate [post]
+func (h *AdminHandler) HandleImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	if len(h.secret) == 0 {
+		problem.Write(w, r, http.StatusServiceUnavailable, "Impersonation not available: requests aren't authenticated, so they can name any user in the X-User-ID header")
+		return
+	}
+	ctx, id, ok := h.tenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	user, exists := h.users.GetUser(ctx, id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	if user.DeactivatedAt != nil {
+		problem.Write(w, r, http.StatusConflict, "User is deactivated")
+		return
+	}
+
+	impersonator := requestUserID(r)
+	expiresAt := time.Now().Add(impersonationLifetime).Truncate(time.Second)
+	expiry := expiresAt.Unix()
+	token, err := signTenantToken(tenantClaims{
+		Tenant:       user.TenantID,
+		Subject:      strconv.FormatInt(user.ID, 10),
+		Impersonator: impersonator,
+		ExpiresAt:    &expiry,
+	}, h.secret)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to issue support token")
+		return
+	}
+	logging.Infof("Issued a support token for user %d of tenant %s to %s", user.ID, user.TenantID, impersonator)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ImpersonationToken{
+		Token:     token,
+		Tenant:    user.TenantID,
+		UserID:    user.ID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// RegisterRoutes registers the tenant and user management routes on the given mux
+func (h *AdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/tenants", h.HandleGetTenants)
+	mux.HandleFunc("GET /admin/tenants/{tenant}", h.HandleGetTenant)
+	mux.HandleFunc("GET /admin/tenants/{tenant}/users", h.HandleGetTenantUsers)
+	mux.HandleFunc("POST /admin/tenants/{tenant}/users/{id}/deactivate", h.HandleDeactivateUser)
+	mux.HandleFunc("POST /admin/tenants/{tenant}/users/{id}/reactivate", h.HandleReactivateUser)
+	mux.HandleFunc("POST /admin/tenants/{tenant}/users/{id}/impersonate", h.HandleImpersonateUser)
+}
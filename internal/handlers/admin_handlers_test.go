@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestAdminsManageTenantsAndUsers(t *testing.T) {
+	const secret = "tenant-secret"
+	userStore := store.NewMemoryUserStore()
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	service := scheduler.NewService(itemStore, todoStore, store.NewMemoryExecutionLogStore())
+	limits := NewTenantLimitHandler(store.NewMemoryTenantLimitStore(), models.TenantLimits{})
+	adminHandler := NewAdminHandler(userStore, itemStore, todoStore, store.NewMemoryWebhookStore(), store.NewMemoryLLMUsageStore(), limits, []byte(secret))
+	router := AuthenticateTenant([]byte(secret), RejectDeactivatedUsers(userStore,
+		NewRouter(NewUserHandler(userStore), NewScheduledItemHandler(itemStore, service), adminHandler)))
+
+	serve := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	admin := tenantToken(secret, map[string]any{"tenant": "ops", "admin": true})
+	acmeAdmin := tenantToken(secret, map[string]any{"tenant": "acme", "admin": true})
+
+	var user models.User
+	json.NewDecoder(serve(acmeAdmin, http.MethodPost, "/users", `{"username":"alice"}`).Body).Decode(&user)
+	acme := tenantToken(secret, map[string]any{"tenant": "acme", "sub": fmt.Sprint(user.ID)})
+	if rec := serve(acme, http.MethodPost, "/scheduled-items", `{"title":"Ship the rockets","startsAt":"2030-01-01T00:00:00Z"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Only administrators can manage tenants
+	if rec := serve(acme, http.MethodGet, "/admin/tenants", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 for a tenant without the admin claim, got %d", rec.Code)
+	}
+	var tenants []models.TenantSummary
+	json.NewDecoder(serve(admin, http.MethodGet, "/admin/tenants", "").Body).Decode(&tenants)
+	if len(tenants) != 1 || tenants[0].Tenant != "acme" || tenants[0].Users != 1 || tenants[0].ScheduledItems != 1 {
+		t.Fatalf("Expected acme with one user and one item, got %+v", tenants)
+	}
+	var users []models.User
+	json.NewDecoder(serve(admin, http.MethodGet, "/admin/tenants/acme/users", "").Body).Decode(&users)
+	if len(users) != 1 || users[0].ID != user.ID {
+		t.Fatalf("Expected acme's user, got %+v", users)
+	}
+
+	// Support tokens act as the user, in the user's tenant
+	userPath := fmt.Sprintf("/admin/tenants/acme/users/%d", user.ID)
+	rec := serve(admin, http.MethodPost, userPath+"/impersonate", "")
+	var impersonation ImpersonationToken
+	json.NewDecoder(rec.Body).Decode(&impersonation)
+	if rec.Code != http.StatusOK || impersonation.Tenant != "acme" || impersonation.UserID != user.ID {
+		t.Fatalf("Expected a support token for alice, got %d: %+v", rec.Code, impersonation)
+	}
+	var items []models.ScheduledItem
+	json.NewDecoder(serve(impersonation.Token, http.MethodGet, "/scheduled-items", "").Body).Decode(&items)
+	if len(items) != 1 {
+		t.Errorf("Expected the support token to see acme's item, got %+v", items)
+	}
+	if rec := serve(impersonation.Token, http.MethodGet, "/admin/tenants", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected support tokens not to be admin tokens, got %d", rec.Code)
+	}
+
+	// Deactivated users can't make requests or be impersonated until they are reactivated
+	json.NewDecoder(serve(admin, http.MethodPost, userPath+"/deactivate", "").Body).Decode(&user)
+	if user.DeactivatedAt == nil {
+		t.Fatalf("Expected the user to be deactivated, got %+v", user)
+	}
+	if rec := serve(impersonation.Token, http.MethodGet, "/scheduled-items", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a deactivated user, got %d", rec.Code)
+	}
+	if rec := serve(admin, http.MethodPost, userPath+"/impersonate", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 when impersonating a deactivated user, got %d", rec.Code)
+	}
+	if rec := serve(admin, http.MethodPost, "/admin/tenants/globex/users/1/deactivate", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a user of another tenant, got %d", rec.Code)
+	}
+	serve(admin, http.MethodPost, userPath+"/reactivate", "")
+	if rec := serve(impersonation.Token, http.MethodGet, "/scheduled-items", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected a reactivated user to be allowed, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultAuditLogLimit is the number of entries listed when no limit is given
+	defaultAuditLogLimit = 100
+	// maxAuditLogLimit caps the number of entries listed per request
+	maxAuditLogLimit = 1000
+)
+
+// auditEntityTypes are the entity types recorded in the audit log
+var auditEntityTypes = []string{models.AuditEntityScheduledItem, models.AuditEntityTodoItem, models.AuditEntityUser}
+
+// AuditLogHandler handles HTTP requests for the audit log
+type AuditLogHandler struct {
+	store store.AuditLogStore
+}
+
+// NewAuditLogHandler creates a new handler with the given store
+func NewAuditLogHandler(store store.AuditLogStore) *AuditLogHandler {
+	return &AuditLogHandler{
+		store: store,
+	}
+}
+
+// HandleGetAuditLog handles GET requests to list the audit log
+// @Summary Get the audit log
+// @Description Retrieve the changes made to scheduled items, todo items and users through the API, newest first: who made each change and the entity before and after it. Changes made by the scheduler are not audited.
+// @Tags admin
+// @Produce json
+// @Param actor query string false "Only return changes made by this user"
+// @Param entityType query string false "Only return changes to this type of entity" Enums(scheduled_item, todo_item, user)
+// @Param entityId query int false "Only return changes to the entity with this ID"
+// @Param since query string false "Only return changes made at or after this time (RFC 3339)"
+// @Param until query string false "Only return changes made before this time (RFC 3339)"
+// @Param limit query int false "Maximum number of entries to return (at most 1000)" default(100)
+// @Success 200 {array} models.AuditLogEntry
+// @Failure 400 {object} problem.Details "Invalid query parameter"
+// @Router /admin/audit-log [get]
+func (h *AuditLogHandler) HandleGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := store.AuditLogFilter{
+		Actor:      query.Get("actor"),
+		EntityType: query.Get("entityType"),
+		Limit:      defaultAuditLogLimit,
+	}
+
+	var errs []problem.FieldError
+	if filter.EntityType != "" && !slices.Contains(auditEntityTypes, filter.EntityType) {
+		errs = append(errs, problem.FieldError{Field: "entityType", Message: "must be scheduled_item, todo_item or user"})
+	}
+	if raw := query.Get("entityId"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			errs = append(errs, problem.FieldError{Field: "entityId", Message: "must be a positive integer"})
+		}
+		filter.EntityID = id
+	}
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if raw := query.Get(param.name); raw != "" {
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				errs = append(errs, problem.FieldError{Field: param.name, Message: "must be an RFC 3339 time such as 2024-01-01T00:00:00Z"})
+			}
+			*param.value = parsed
+		}
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			errs = append(errs, problem.FieldError{Field: "limit", Message: "must be a positive integer"})
+		}
+		filter.Limit = min(limit, maxAuditLogLimit)
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid query parameter", errs...).Write(w, r)
+		return
+	}
+
+	entries := h.store.GetAuditLog(r.Context(), filter)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// RegisterRoutes registers the audit log routes on the given mux
+func (h *AuditLogHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/audit-log", h.HandleGetAuditLog)
+}
+
+// RecordActor makes the changes handled by next auditable, attributing them to the user
+// making the request: the X-User-ID header, or the client address. Changes made with a
+// support token also name the administrator impersonating the user.
+func RecordActor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := requestUserID(r)
+		if impersonator := requestImpersonator(r); impersonator != "" {
+			actor += " (impersonated by " + impersonator + ")"
+		}
+		next.ServeHTTP(w, r.WithContext(store.WithActor(r.Context(), actor)))
+	})
+}
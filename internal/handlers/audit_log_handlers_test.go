@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogRecordsChangesMadeThroughTheAPI(t *testing.T) {
+	auditStore := store.NewMemoryAuditLogStore()
+	todoStore := store.NewAuditingTodoItemStore(store.NewMemoryTodoItemStore(), auditStore)
+	userStore := store.NewAuditingUserStore(store.NewMemoryUserStore(), auditStore)
+	router := RecordActor(NewRouter(NewTodoItemHandler(todoStore), NewUserHandler(userStore), NewAuditLogHandler(auditStore)))
+
+	send := func(method string, path string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User-ID", "alice")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := send(http.MethodPost, "/todo-items", `{"text":"Buy milk"}`)
+	var created models.TodoItem
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode todo item: %v", err)
+	}
+	send(http.MethodPut, fmt.Sprintf("/todo-items/%d", created.ID), `{"text":"Buy oat milk"}`)
+	send(http.MethodDelete, fmt.Sprintf("/todo-items/%d", created.ID), "")
+	send(http.MethodPost, "/users", `{"username":"bob","passwordHash":"c2VjcmV0"}`)
+
+	// Changes made without an actor, such as the scheduler's, are not audited
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Scheduled"})
+
+	rec = send(http.MethodGet, fmt.Sprintf("/admin/audit-log?entityType=todo_item&entityId=%d", created.ID), "")
+	var entries []models.AuditLogEntry
+	if err := json.NewDecoder(rec.Body).Decode(&entries); err != nil {
+		t.Fatalf("Failed to decode audit log: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries for the todo item, got %+v", entries)
+	}
+	for i, action := range []string{models.AuditActionDelete, models.AuditActionUpdate, models.AuditActionCreate} {
+		if entries[i].Action != action || entries[i].Actor != "alice" {
+			t.Errorf("Expected entry %d to be a %s by alice, got %+v", i, action, entries[i])
+		}
+	}
+	update := entries[1]
+	if !strings.Contains(string(update.Before), `"Buy milk"`) || !strings.Contains(string(update.After), `"Buy oat milk"`) {
+		t.Errorf("Expected the update to record the text before and after, got %s and %s", update.Before, update.After)
+	}
+	if entries[0].After != nil || entries[2].Before != nil {
+		t.Errorf("Expected no state after the deletion or before the creation, got %+v", entries)
+	}
+
+	rec = send(http.MethodGet, "/admin/audit-log?entityType=user&limit=1", "")
+	entries = nil
+	json.NewDecoder(rec.Body).Decode(&entries)
+	if len(entries) != 1 || strings.Contains(string(entries[0].After), "c2VjcmV0") {
+		t.Errorf("Expected one user entry without the password hash, got %+v", entries)
+	}
+	if total := len(auditStore.GetAuditLog(context.Background(), store.AuditLogFilter{Limit: 100})); total != 4 {
+		t.Errorf("Expected 4 audited changes, got %d", total)
+	}
+
+	rec = send(http.MethodGet, "/admin/audit-log?entityType=webhook&limit=0", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown entity type and invalid limit, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/store"
+)
+
+// CacheStatsSource is a cache that reports its hit and miss counts
+type CacheStatsSource interface {
+	Stats() store.CacheStats
+}
+
+// CacheStatsFunc adapts a function reporting cache statistics to a CacheStatsSource
+type CacheStatsFunc func() store.CacheStats
+
+// Stats returns f()
+func (f CacheStatsFunc) Stats() store.CacheStats {
+	return f()
+}
+
+// CacheHandler handles HTTP requests for cache metrics
+type CacheHandler struct {
+	caches map[string]CacheStatsSource
+}
+
+// NewCacheHandler creates a new handler reporting on the given caches, keyed by name
+func NewCacheHandler(caches map[string]CacheStatsSource) *CacheHandler {
+	return &CacheHandler{
+		caches: caches,
+	}
+}
+
+// HandleGetCacheStats handles GET requests to retrieve cache hit and miss counts
+// @Summary Get cache statistics
+// @Description Get hit and miss counts, the hit rate and the number of cached entries for each store cache, and for the caches of the polled reads on their own. Only available when USE_CACHE is enabled.
+// @Tags cache
+// @Produce json
+// @Success 200 {object} map[string]store.CacheStats
+// @Router /cache/stats [get]
+func (h *CacheHandler) HandleGetCacheStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]store.CacheStats, len(h.caches))
+	for name, cache := range h.caches {
+		stats[name] = cache.Stats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// RegisterRoutes registers the HTTP routes for cache metrics on the given mux
+func (h *CacheHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /cache/stats", h.HandleGetCacheStats)
+}
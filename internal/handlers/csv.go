@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// csvContentType is the media type of list responses requested as CSV
+const csvContentType = "text/csv"
+
+// listFlushRows is how many rows or entries of a list are written between flushes, so
+// large lists stream to the client instead of being buffered whole
+const listFlushRows = 100
+
+// csvColumn is a column of a CSV list response
+type csvColumn[T any] struct {
+	name  string
+	value func(item T) string
+}
+
+// wantsCSV reports whether a list should be written as CSV, either because the request
+// has ?format=csv or because its Accept header prefers text/csv over JSON. It marks the
+// response as varying by Accept so caches keep both representations apart.
+func wantsCSV(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("Vary", "Accept")
+	return prefersCSV(r)
+}
+
+// prefersCSV reports whether the request asks for a list as CSV rather than JSON
+func prefersCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+
+	csvQuality, jsonQuality := 0.0, -1.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		switch mediaType {
+		case csvContentType:
+			csvQuality = max(csvQuality, quality)
+		case "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+	return csvQuality > 0 && csvQuality > jsonQuality
+}
+
+// writeCSV writes items as a CSV attachment named filename, with a header row of the
+// column names followed by one row per item
+func writeCSV[T any](w http.ResponseWriter, filename string, items []T, columns []csvColumn[T]) {
+	writeCSVStream(w, filename, sliceStream(items), columns)
+}
+
+// writeCSVStream writes the items of stream as a CSV attachment like writeCSV, one row
+// at a time as they are read. A stream error aborts the response, since the status has
+// already been sent.
+func writeCSVStream[T any](w http.ResponseWriter, filename string, stream iter.Seq2[T, error], columns []csvColumn[T]) {
+	w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	flusher, _ := w.(http.Flusher)
+	writer := csv.NewWriter(w)
+	flush := func() bool {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			logging.Errorf("Error writing %s: %v", filename, err)
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	writer.Write(record)
+
+	n := 0
+	for item, err := range stream {
+		if err != nil {
+			flush()
+			abortStream(filename, err)
+		}
+		for i, column := range columns {
+			record[i] = column.value(item)
+		}
+		writer.Write(record)
+
+		n++
+		if n%listFlushRows == 0 && !flush() {
+			return
+		}
+	}
+	// Left unflushed like writeJSONStream's tail, so short lists still get an ETag
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logging.Errorf("Error writing %s: %v", filename, err)
+	}
+}
+
+// csvTime formats a time for CSV, leaving zero times empty
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// csvOptional formats an optional value for CSV, leaving nil empty
+func csvOptional[T any](value *T, format func(T) string) string {
+	if value == nil {
+		return ""
+	}
+	return format(*value)
+}
+
+// formatID formats an ID for CSV
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// csvString returns an optional string for CSV, leaving nil empty
+func csvString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWantsCSV(t *testing.T) {
+	tests := []struct {
+		query  string
+		accept string
+		want   bool
+	}{
+		{"", "", false},
+		{"", "application/json", false},
+		{"", "text/csv", true},
+		{"", "text/csv, application/json;q=0.5", true},
+		{"", "application/json, text/csv;q=0.5", false},
+		{"", "text/csv;q=0", false},
+		{"?format=csv", "application/json", true},
+		{"?format=json", "text/csv", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/todo-items"+tt.query, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		if got := wantsCSV(httptest.NewRecorder(), req); got != tt.want {
+			t.Errorf("wantsCSV(%q, Accept: %q) = %v, want %v", tt.query, tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestListTodoItemsAsCSV(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Buy milk, eggs"})
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Call \"Bob\"", Checked: true})
+	router := NewRouter(NewTodoItemHandler(todoStore))
+
+	req := httptest.NewRequest(http.MethodGet, "/todo-items?sort=id", nil)
+	req.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Expected a CSV content type, got %q", ct)
+	}
+	if vary := rec.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Expected Vary: Accept, got %q", vary)
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %v", records)
+	}
+	if strings.Join(records[0], ",") != "id,text,checked,organizationId,createdAt,updatedAt" {
+		t.Errorf("Unexpected header %v", records[0])
+	}
+	if records[1][1] != "Buy milk, eggs" || records[2][1] != `Call "Bob"` || records[2][2] != "true" {
+		t.Errorf("Unexpected rows %v", records[1:])
+	}
+	if _, err := time.Parse(time.RFC3339, records[1][4]); err != nil {
+		t.Errorf("Expected an RFC 3339 createdAt, got %q", records[1][4])
+	}
+}
+
+func TestListExecutionLogsAsCSV(t *testing.T) {
+	executionLogStore := store.NewMemoryExecutionLogStore()
+	message := "timeout"
+	executionLogStore.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: 7,
+		ExecutedAt:      time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC),
+		Status:          "error",
+		ErrorMessage:    &message,
+	})
+	router := NewRouter(NewExecutionLogHandler(executionLogStore))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/execution-logs?format=csv", nil))
+
+	want := "id,scheduledItemId,executedAt,status,errorMessage,todoItemId,executionKey\n" +
+		"1,7,2030-01-01T09:00:00Z,error,timeout,,\n"
+	if rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("Expected %q, got %d: %q", want, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"periodic-api/internal/problem"
+)
+
+// cursorParam is the query parameter that pages a list by cursor instead of returning it
+// whole: an empty value asks for the first page and the token of a page asks for the one
+// after it
+const cursorParam = "cursor"
+
+// NextCursorHeader carries the cursor of the page after a cursor-paged list, and is
+// absent on the last page
+const NextCursorHeader = "X-Next-Cursor"
+
+// encodeCursor returns the opaque token of a store cursor
+func encodeCursor(cursor any) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the store cursor of a token, or nil for the empty token of the
+// first page
+func decodeCursor[C any](token string) (*C, bool) {
+	if token == "" {
+		return nil, true
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, false
+	}
+	var cursor C
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, false
+	}
+	return &cursor, true
+}
+
+// writeCursorPage serves one page of a list when the request has the cursor parameter,
+// reporting whether it did. fetch reads the page of up to limit entries after a cursor
+// from the store, whose order is the list's; the next page's token is sent in the
+// NextCursorHeader.
+func writeCursorPage[T, C any](w http.ResponseWriter, r *http.Request, filename string, columns []csvColumn[T], fetch func(limit int, cursor *C) ([]T, *C, error)) bool {
+	query := r.URL.Query()
+	if !query.Has(cursorParam) {
+		return false
+	}
+
+	var errs []problem.FieldError
+	limit, limitErr := parseLimit(r)
+	if limitErr != nil {
+		errs = append(errs, *limitErr)
+	}
+	cursor, ok := decodeCursor[C](query.Get(cursorParam))
+	if !ok {
+		errs = append(errs, problem.FieldError{Field: cursorParam, Message: "is not a cursor returned by this list"})
+	}
+	if query.Get("sort") != "" {
+		errs = append(errs, problem.FieldError{Field: "sort", Message: "can't be combined with cursor, whose pages have a fixed order"})
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid query parameter", errs...).Write(w, r)
+		return true
+	}
+
+	items, next, err := fetch(limit, cursor)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to retrieve page: "+err.Error())
+		return true
+	}
+	if items == nil {
+		items = []T{}
+	}
+	if next != nil {
+		w.Header().Set(NextCursorHeader, encodeCursor(next))
+	}
+
+	if wantsCSV(w, r) {
+		writeCSV(w, filename, items, columns)
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+	return true
+}
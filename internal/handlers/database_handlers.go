@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/db"
+	"periodic-api/internal/store"
+)
+
+// PoolStatsSource is a connection pool that reports its statistics, such as *sql.DB
+type PoolStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// DatabaseHandler handles HTTP requests for database connection pool metrics
+type DatabaseHandler struct {
+	pool PoolStatsSource
+}
+
+// NewDatabaseHandler creates a new handler reporting on the given connection pool
+func NewDatabaseHandler(pool PoolStatsSource) *DatabaseHandler {
+	return &DatabaseHandler{
+		pool: pool,
+	}
+}
+
+// HandleGetPoolStats handles GET requests to retrieve connection pool statistics
+// @Summary Get database connection pool statistics
+// @Description Get open, in-use and idle connection counts and how often callers waited for a connection. Only available when USE_POSTGRES_DB is enabled.
+// @Tags database
+// @Produce json
+// @Success 200 {object} db.PoolStats
+// @Router /db/stats [get]
+func (h *DatabaseHandler) HandleGetPoolStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(db.NewPoolStats(h.pool.Stats()))
+}
+
+// HandleGetMetrics handles GET requests to retrieve connection pool statistics and slow query counts
+// @Summary Get database metrics
+// @Description Connection pool statistics and slow query counts in the Prometheus text format. db_pool_open, db_pool_in_use, db_pool_idle and db_pool_max_open report the connections, and db_pool_wait_count_total and db_pool_wait_seconds_total how often and how long callers waited for one. db_slow_queries_total and db_slow_query_seconds_total count the statements that took longer than DB_SLOW_QUERY_THRESHOLD, by the store operation that ran them. With DB_READ_HOST, db_replica_lag_seconds, db_replica_reads_total and db_replica_fallbacks_total report the read replica's lag and the queries sent to it or back to the primary. Only available when USE_POSTGRES_DB is enabled.
+// @Tags database
+// @Produce plain
+// @Success 200 {string} string "Prometheus metrics"
+// @Router /db/metrics [get]
+func (h *DatabaseHandler) HandleGetMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	db.WritePoolMetrics(w, h.pool.Stats())
+	store.WriteSlowQueryMetrics(w)
+	store.WriteReplicaMetrics(w)
+}
+
+// RegisterRoutes registers the HTTP routes for database metrics on the given mux
+func (h *DatabaseHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /db/stats", h.HandleGetPoolStats)
+	mux.HandleFunc("GET /db/metrics", h.HandleGetMetrics)
+}
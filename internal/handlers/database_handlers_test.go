@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedPoolStats is a connection pool with fixed statistics
+type fixedPoolStats sql.DBStats
+
+func (s fixedPoolStats) Stats() sql.DBStats {
+	return sql.DBStats(s)
+}
+
+func TestMetricsIncludePoolStats(t *testing.T) {
+	router := NewRouter(NewDatabaseHandler(fixedPoolStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          7,
+		WaitDuration:       1500 * time.Millisecond,
+	}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/db/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	for _, line := range []string{
+		"# TYPE db_pool_in_use gauge",
+		"db_pool_max_open 10",
+		"db_pool_open 4",
+		"db_pool_in_use 3",
+		"db_pool_idle 1",
+		"# TYPE db_pool_wait_count_total counter",
+		"db_pool_wait_count_total 7",
+		"db_pool_wait_seconds_total 1.5",
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("Expected %q in the metrics, got:\n%s", line, rec.Body.String())
+		}
+	}
+}
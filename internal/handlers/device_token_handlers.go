@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DeviceTokenHandler handles HTTP requests for the push tokens of users' devices
+type DeviceTokenHandler struct {
+	store     store.DeviceTokenStore
+	userStore store.UserStore
+}
+
+// NewDeviceTokenHandler creates a new handler with the given stores
+func NewDeviceTokenHandler(store store.DeviceTokenStore, userStore store.UserStore) *DeviceTokenHandler {
+	return &DeviceTokenHandler{
+		store:     store,
+		userStore: userStore,
+	}
+}
+
+// userID parses the user ID from the path and checks that the user exists, writing the
+// problem and returning false otherwise
+func (h *DeviceTokenHandler) userID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return 0, false
+	}
+	if _, exists := h.userStore.GetUser(r.Context(), id); !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// HandleGetDeviceTokens handles GET requests to list a user's device tokens
+// @Summary List a user's devices
+// @Description List the push tokens of the devices a user registered, in registration order
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} models.DeviceToken
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User not found"
+// @Router /users/{id}/devices [get]
+func (h *DeviceTokenHandler) HandleGetDeviceTokens(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.GetDeviceTokens(r.Context(), id))
+}
+
+// HandleRegisterDeviceToken handles POST requests to register a device's push token
+// @Summary Register a device
+// @Description Register the push token of a user's device, so the user is pushed a notification when the scheduler creates a todo for them. Registering a token again refreshes it.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param device body models.DeviceToken true "Device platform (fcm or apns) and token"
+// @Success 201 {object} models.DeviceToken
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "User not found"
+// @Failure 500 {object} problem.Details "Failed to register device"
+// @Router /users/{id}/devices [post]
+func (h *DeviceTokenHandler) HandleRegisterDeviceToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var device models.DeviceToken
+	if err := json.NewDecoder(r.Body).Decode(&device); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	device.UserID = id
+
+	if errs := validateDeviceToken(device); len(errs) > 0 {
+		problem.Validation("Invalid device", errs...).Write(w, r)
+		return
+	}
+
+	registered, ok := h.store.RegisterDeviceToken(r.Context(), device)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to register device")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(registered)
+}
+
+// HandleDeleteDeviceToken handles DELETE requests to unregister a device
+// @Summary Unregister a device
+// @Description Stop pushing notifications to a device, such as when the user signs out of the app
+// @Tags users
+// @Param id path int true "User ID"
+// @Param token path string true "Device token"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User or device not found"
+// @Router /users/{id}/devices/{token} [delete]
+func (h *DeviceTokenHandler) HandleDeleteDeviceToken(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.store.DeleteDeviceToken(r.Context(), id, r.PathValue("token")) {
+		problem.Write(w, r, http.StatusNotFound, "Device not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateDeviceToken checks the platform and token of a device
+func validateDeviceToken(device models.DeviceToken) []problem.FieldError {
+	var errs []problem.FieldError
+	if !slices.Contains(models.DevicePlatforms, device.Platform) {
+		errs = append(errs, problem.FieldError{
+			Field:   "platform",
+			Message: "unknown platform " + strconv.Quote(device.Platform) + "; expected one of " + strings.Join(models.DevicePlatforms, ", "),
+		})
+	}
+	if device.Token == "" || strings.ContainsAny(device.Token, "/ \t\n") {
+		errs = append(errs, problem.FieldError{Field: "token", Message: "must be a device token without slashes or whitespace"})
+	}
+	return errs
+}
+
+// RegisterRoutes registers the HTTP routes for device tokens on the given mux
+func (h *DeviceTokenHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /users/{id}/devices", h.HandleGetDeviceTokens)
+	mux.HandleFunc("POST /users/{id}/devices", h.HandleRegisterDeviceToken)
+	mux.HandleFunc("DELETE /users/{id}/devices/{token}", h.HandleDeleteDeviceToken)
+}
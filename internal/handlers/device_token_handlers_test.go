@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestDeviceTokensAreRegisteredPerUser(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	user := users.CreateUser(context.Background(), models.User{Username: "alice"})
+	router := NewRouter(NewDeviceTokenHandler(store.NewMemoryDeviceTokenStore(), users))
+	path := fmt.Sprintf("/users/%d/devices", user.ID)
+
+	// Registering the same token twice refreshes it
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"platform":"fcm","token":"fMEP0vJqS0:APA91bH"}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"platform":"webos","token":"a/b"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an unknown platform, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var devices []models.DeviceToken
+	if err := json.NewDecoder(rec.Body).Decode(&devices); err != nil {
+		t.Fatalf("Failed to decode devices: %v", err)
+	}
+	if len(devices) != 1 || devices[0].UserID != user.ID || devices[0].Platform != "fcm" {
+		t.Fatalf("Expected one registered device, got %+v", devices)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, path+"/fMEP0vJqS0:APA91bH", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/users/999/devices", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown user, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"periodic-api/internal/problem"
+)
+
+// APIV2Prefix is the path prefix of the API version that wraps responses in an envelope
+const APIV2Prefix = "/api/v2"
+
+const (
+	// defaultPageLimit is the number of list entries returned when no limit is given
+	defaultPageLimit = 50
+	// maxPageLimit is the largest limit a client may request
+	maxPageLimit = 500
+)
+
+// Envelope wraps a list in its data, with the paging information clients need to fetch
+// the rest of it
+type Envelope struct {
+	Data  any            `json:"data"`
+	Meta  *EnvelopeMeta  `json:"meta,omitempty"`
+	Links *EnvelopeLinks `json:"links,omitempty"`
+}
+
+// EnvelopeMeta describes the page of a list returned in an envelope. Lists paged by offset
+// have a total and offset; lists paged by cursor have the cursor of the next page instead,
+// which is absent on the last page.
+type EnvelopeMeta struct {
+	Total      *int    `json:"total,omitempty" example:"120"`
+	Limit      int     `json:"limit" example:"50"`
+	Offset     *int    `json:"offset,omitempty" example:"50"`
+	NextCursor *string `json:"nextCursor,omitempty" example:"eyJJRCI6NTB9"`
+}
+
+// EnvelopeLinks are the URLs of the neighbouring pages, or null at either end of the list
+type EnvelopeLinks struct {
+	Next *string `json:"next" example:"/api/v2/users?limit=50&offset=100"`
+	Prev *string `json:"prev" example:"/api/v2/users?limit=50&offset=0"`
+}
+
+// MountEnveloped serves handler under the given path prefix like Mount, wrapping its JSON
+// responses in an Envelope: lists become {data, meta, links} and single resources become
+// {data}. The lists that page by cursor are paged by cursor unless they are sorted, so the
+// handler reads a single page from the store; the rest, and sorted lists, are paged with
+// the limit and offset query parameters. Errors, CSV and streams are passed through
+// unchanged.
+func MountEnveloped(prefix string, handler http.Handler) RouteRegistrar {
+	return RouteFunc(func(mux *http.ServeMux) {
+		mux.Handle(prefix+"/", envelopeResponses(prefix, http.StripPrefix(prefix, handler)))
+	})
+}
+
+// cursorLists are the paths of the lists whose handlers page by cursor
+var cursorLists = map[string]bool{
+	"/scheduled-items": true,
+	"/todo-items":      true,
+	"/execution-logs":  true,
+}
+
+// envelopeResponses wraps the JSON responses of next, mounted under prefix, in an Envelope
+func envelopeResponses(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit, offset, errs := parsePage(r)
+		query := r.URL.Query()
+		byCursor := r.Method == http.MethodGet && cursorLists[strings.TrimPrefix(r.URL.Path, prefix)] &&
+			query.Get("sort") == "" && !query.Has(cursorParam) && !prefersCSV(r)
+		if byCursor && query.Get("offset") != "" {
+			errs = append(errs, problem.FieldError{Field: "offset", Message: "only pages sorted lists; page this list by cursor"})
+		}
+		if len(errs) > 0 {
+			problem.Validation("Invalid query parameter", errs...).Write(w, r)
+			return
+		}
+
+		// Unsorted lists are read a page at a time rather than whole
+		if byCursor {
+			query.Set(cursorParam, "")
+			paged := new(http.Request)
+			*paged = *r
+			paged.URL = new(url.URL)
+			*paged.URL = *r.URL
+			paged.URL.RawQuery = query.Encode()
+			r = paged
+		}
+
+		writer := &envelopeResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(writer, r)
+		writer.finish(r, limit, offset, r.URL.Query().Has(cursorParam))
+	})
+}
+
+// parsePage reads the limit and offset query parameters
+func parsePage(r *http.Request) (int, int, []problem.FieldError) {
+	var errs []problem.FieldError
+	offset := 0
+
+	limit, limitErr := parseLimit(r)
+	if limitErr != nil {
+		errs = append(errs, *limitErr)
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			errs = append(errs, problem.FieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else if r.URL.Query().Has(cursorParam) {
+			errs = append(errs, problem.FieldError{Field: "offset", Message: "can't be combined with cursor"})
+		}
+		offset = parsed
+	}
+	return limit, offset, errs
+}
+
+// parseLimit reads the limit query parameter
+func parseLimit(r *http.Request) (int, *problem.FieldError) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return limit, &problem.FieldError{Field: "limit", Message: "must be an integer between 1 and " + strconv.Itoa(maxPageLimit)}
+	}
+	return limit, nil
+}
+
+// envelopeResponseWriter buffers successful JSON responses so they can be wrapped, and
+// passes everything else straight through
+type envelopeResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	decided bool
+	// buffering is set when the response is a successful JSON response
+	buffering bool
+	buf       bytes.Buffer
+}
+
+// decide chooses whether to buffer the response once its status and headers are known
+func (w *envelopeResponseWriter) decide(status int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	w.status = status
+
+	contentType := w.Header().Get("Content-Type")
+	w.buffering = status >= 200 && status < 300 && strings.HasPrefix(contentType, "application/json")
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+// WriteHeader records the status of buffered responses and sends it for the rest
+func (w *envelopeResponseWriter) WriteHeader(status int) {
+	w.decide(status)
+}
+
+// Write buffers JSON bodies and passes the rest through
+func (w *envelopeResponseWriter) Write(b []byte) (int, error) {
+	w.decide(http.StatusOK)
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes through for streamed responses; buffered ones are sent when complete
+func (w *envelopeResponseWriter) Flush() {
+	if w.buffering {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *envelopeResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sends the buffered response wrapped in an envelope. Lists paged by cursor were
+// paged by the handler, which sent the next page's cursor in the NextCursorHeader.
+func (w *envelopeResponseWriter) finish(r *http.Request, limit, offset int, cursor bool) {
+	if !w.decided {
+		w.ResponseWriter.WriteHeader(w.status)
+		return
+	}
+	if !w.buffering {
+		return
+	}
+
+	body := bytes.TrimSpace(w.buf.Bytes())
+	var envelope Envelope
+	if bytes.HasPrefix(body, []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil {
+			w.sendUnwrapped()
+			return
+		}
+		if cursor {
+			envelope = cursorPage(r, items, limit, w.Header().Get(NextCursorHeader))
+		} else {
+			envelope = page(r, items, limit, offset)
+		}
+	} else {
+		envelope.Data = json.RawMessage(body)
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		w.sendUnwrapped()
+		return
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(append(data, '\n'))
+}
+
+// sendUnwrapped sends the buffered response as the handler wrote it
+func (w *envelopeResponseWriter) sendUnwrapped() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// page returns the envelope of one page of a list, linking to the pages around it
+func page(r *http.Request, items []json.RawMessage, limit, offset int) Envelope {
+	total := len(items)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	links := &EnvelopeLinks{}
+	if end < total {
+		links.Next = pageURL(r, limit, end)
+	}
+	if start > 0 {
+		links.Prev = pageURL(r, limit, max(start-limit, 0))
+	}
+
+	return Envelope{
+		Data:  items[start:end],
+		Meta:  &EnvelopeMeta{Total: &total, Limit: limit, Offset: &offset},
+		Links: links,
+	}
+}
+
+// cursorPage returns the envelope of a page of a list paged by cursor, linking to the next
+// page unless it is the last. Cursors only lead forward, so there is no previous link.
+func cursorPage(r *http.Request, items []json.RawMessage, limit int, next string) Envelope {
+	meta := &EnvelopeMeta{Limit: limit}
+	links := &EnvelopeLinks{}
+	if next != "" {
+		meta.NextCursor = &next
+		query := r.URL.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set(cursorParam, next)
+		url := r.URL.Path + "?" + query.Encode()
+		links.Next = &url
+	}
+	return Envelope{Data: items, Meta: meta, Links: links}
+}
+
+// pageURL returns the request's URL with the given limit and offset
+func pageURL(r *http.Request, limit, offset int) *string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	url := r.URL.Path + "?" + query.Encode()
+	return &url
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestMountEnvelopedPagesLists(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	for i := 1; i <= 5; i++ {
+		todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: fmt.Sprintf("Item %d", i)})
+	}
+	router := NewRouter(MountEnveloped(APIV2Prefix, NewRouter(NewTodoItemHandler(todoStore))))
+
+	get := func(path string) (*httptest.ResponseRecorder, Envelope, []models.TodoItem) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var envelope Envelope
+		var items []models.TodoItem
+		if rec.Code == http.StatusOK && rec.Header().Get("Content-Type") == "application/json" {
+			var raw struct {
+				Data json.RawMessage `json:"data"`
+				Envelope
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &raw); err != nil {
+				t.Fatalf("Failed to decode envelope: %v", err)
+			}
+			envelope = raw.Envelope
+			json.Unmarshal(raw.Data, &items)
+		}
+		return rec, envelope, items
+	}
+
+	rec, envelope, items := get("/api/v2/todo-items?sort=id&limit=2&offset=2")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if len(items) != 2 || items[0].Text != "Item 3" || items[1].Text != "Item 4" {
+		t.Errorf("Expected items 3 and 4, got %+v", items)
+	}
+	if envelope.Meta == nil || envelope.Meta.Total == nil || *envelope.Meta.Total != 5 || envelope.Meta.Limit != 2 ||
+		envelope.Meta.Offset == nil || *envelope.Meta.Offset != 2 || envelope.Meta.NextCursor != nil {
+		t.Errorf("Unexpected meta %+v", envelope.Meta)
+	}
+	if envelope.Links == nil || envelope.Links.Next == nil || envelope.Links.Prev == nil ||
+		*envelope.Links.Next != "/api/v2/todo-items?limit=2&offset=4&sort=id" ||
+		*envelope.Links.Prev != "/api/v2/todo-items?limit=2&offset=0&sort=id" {
+		t.Errorf("Unexpected links %+v", envelope.Links)
+	}
+
+	// The last page has no next link
+	_, envelope, items = get("/api/v2/todo-items?sort=id&limit=2&offset=4")
+	if len(items) != 1 || envelope.Links.Next != nil || envelope.Links.Prev == nil {
+		t.Errorf("Unexpected last page %+v with links %+v", items, envelope.Links)
+	}
+
+	// Single resources are wrapped without paging
+	rec, envelope, _ = get("/api/v2/todo-items/1")
+	if rec.Code != http.StatusOK || envelope.Meta != nil || !strings.Contains(rec.Body.String(), `"data":{"id":1`) {
+		t.Errorf("Unexpected single resource response %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Errors and invalid paging are problem details
+	if rec, _, _ := get("/api/v2/todo-items/99"); rec.Code != http.StatusNotFound || strings.Contains(rec.Body.String(), `"data"`) {
+		t.Errorf("Expected an unwrapped 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec, _, _ := get("/api/v2/todo-items?limit=0"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid limit, got %d", rec.Code)
+	}
+
+	// CSV is passed through whole
+	if rec, _, _ := get("/api/v2/todo-items?format=csv&limit=2"); !strings.HasPrefix(rec.Body.String(), "id,text") ||
+		strings.Count(rec.Body.String(), "\n") != 6 {
+		t.Errorf("Expected CSV to pass through, got %q", rec.Body.String())
+	}
+}
+
+func TestMountEnvelopedPagesListsByCursor(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	for i := 1; i <= 5; i++ {
+		todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: fmt.Sprintf("Item %d", i)})
+	}
+	pages := &pageCountingTodoItemStore{TodoItemStore: todoStore}
+	todoHandler := NewTodoItemHandler(pages)
+	router := NewRouter(todoHandler, MountEnveloped(APIV2Prefix, NewRouter(todoHandler)))
+
+	// Unsorted lists are paged by cursor from the first page, without a cursor parameter
+	var texts []string
+	path := "/api/v2/todo-items?limit=2"
+	for pages := 0; path != ""; pages++ {
+		if pages == 5 {
+			t.Fatalf("Expected the pages to end, still at %s", path)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+
+		var envelope struct {
+			Data  []models.TodoItem `json:"data"`
+			Meta  EnvelopeMeta      `json:"meta"`
+			Links EnvelopeLinks     `json:"links"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("Failed to decode envelope: %v", err)
+		}
+		if envelope.Meta.Total != nil || envelope.Meta.Offset != nil || envelope.Meta.Limit != 2 || envelope.Links.Prev != nil {
+			t.Errorf("Unexpected meta %+v and links %+v", envelope.Meta, envelope.Links)
+		}
+		for _, item := range envelope.Data {
+			texts = append(texts, item.Text)
+		}
+
+		path = ""
+		if envelope.Links.Next != nil {
+			if envelope.Meta.NextCursor == nil || !strings.Contains(*envelope.Links.Next, "cursor="+*envelope.Meta.NextCursor) {
+				t.Errorf("Expected the next link to carry the next cursor, got %+v and %+v", envelope.Meta, envelope.Links)
+			}
+			path = *envelope.Links.Next
+		}
+	}
+	if strings.Join(texts, ",") != "Item 1,Item 2,Item 3,Item 4,Item 5" {
+		t.Errorf("Expected every item once in ID order, got %v", texts)
+	}
+	if pages.calls != 3 {
+		t.Errorf("Expected each page to be read from the store by cursor, got %d page reads", pages.calls)
+	}
+
+	// Without the envelope the next cursor is sent in a header
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo-items?limit=3&cursor=", nil))
+	var items []models.TodoItem
+	json.Unmarshal(rec.Body.Bytes(), &items)
+	next := rec.Header().Get(NextCursorHeader)
+	if len(items) != 3 || next == "" {
+		t.Fatalf("Expected 3 items and a next cursor, got %d and %q", len(items), next)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo-items?limit=3&cursor="+next, nil))
+	json.Unmarshal(rec.Body.Bytes(), &items)
+	if len(items) != 2 || items[0].Text != "Item 4" || rec.Header().Get(NextCursorHeader) != "" {
+		t.Errorf("Expected the last 2 items without a next cursor, got %+v", items)
+	}
+
+	// Invalid cursors, sort or offset alongside a cursor, and offsets into unsorted lists,
+	// are rejected
+	for _, path := range []string{
+		"/api/v2/todo-items?offset=2",
+		"/api/v2/todo-items?cursor=not-a-cursor",
+		"/api/v2/todo-items?cursor=&sort=id",
+		"/api/v2/todo-items?cursor=&offset=2",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", path, rec.Code)
+		}
+	}
+}
+
+// pageCountingTodoItemStore counts the pages read from a todo item store
+type pageCountingTodoItemStore struct {
+	store.TodoItemStore
+	calls int
+}
+
+func (s *pageCountingTodoItemStore) GetTodoItemsPage(ctx context.Context, limit int, cursor *store.TodoItemCursor) ([]models.TodoItem, *store.TodoItemCursor, error) {
+	s.calls++
+	return s.TodoItemStore.GetTodoItemsPage(ctx, limit, cursor)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"periodic-api/internal/events"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// EventHandler handles HTTP requests for real-time change events
+type EventHandler struct {
+	bus           *events.Bus
+	items         *store.ScopedScheduledItemStore
+	originAllowed func(origin string) bool
+}
+
+// NewEventHandler creates a new handler streaming events from bus. Users only receive
+// the events about the scheduled items they may see through items, or every event of
+// their tenant when items is nil. Browsers may only connect from origins for which
+// originAllowed returns true.
+func NewEventHandler(bus *events.Bus, items *store.ScopedScheduledItemStore, originAllowed func(origin string) bool) *EventHandler {
+	return &EventHandler{
+		bus:           bus,
+		items:         items,
+		originAllowed: originAllowed,
+	}
+}
+
+// HandleWebSocket handles WebSocket connections that receive change events
+// @Summary Subscribe to change events over WebSocket
+// @Description Upgrade to a WebSocket that receives a JSON message for each todo item change, including todos created by the scheduler, and each change of the scheduled items the caller may see. Use the types parameter to receive only some event types.
+// @Tags events
+// @Produce json
+// @Param types query string false "Comma-separated event types to receive, such as todo.created,todo.updated"
+// @Success 101 {object} events.Event
+// @Router /ws [get]
+func (h *EventHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	server := websocket.Server{
+		Handshake: h.handshake,
+		Handler: func(conn *websocket.Conn) {
+			wanted := eventTypeFilter(r.URL.Query().Get("types"))
+			h.streamEvents(conn, func(event events.Event) bool {
+				return wanted(event.Type) && h.visible(r, event)
+			})
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// handshake rejects connections from browser origins that aren't allowed to call the API;
+// clients that send no Origin header, such as command-line tools, are accepted
+func (h *EventHandler) handshake(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin != "" && !h.originAllowed(origin) {
+		return errors.New("origin not allowed")
+	}
+	return nil
+}
+
+// streamEvents sends the wanted events to the connection until the client disconnects
+func (h *EventHandler) streamEvents(conn *websocket.Conn, wanted func(event events.Event) bool) {
+	defer conn.Close()
+
+	subscription, unsubscribe := h.bus.Subscribe()
+	defer unsubscribe()
+
+	// Clients don't send anything; reading only detects when they disconnect
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var message string
+		for websocket.Message.Receive(conn, &message) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-subscription:
+			if !ok {
+				return
+			}
+			if !wanted(event) {
+				continue
+			}
+			if err := websocket.JSON.Send(conn, event); err != nil {
+				logging.Errorf("Error sending event ID=%d over WebSocket: %v", event.ID, err)
+				return
+			}
+		}
+	}
+}
+
+// visible reports whether the user making a request may receive an event: it must be
+// about their tenant and, when it is about a scheduled item, one they may see
+func (h *EventHandler) visible(r *http.Request, event events.Event) bool {
+	if !sameTenant(r, event.Tenant) {
+		return false
+	}
+	return h.items == nil || h.items.SeesEvent(r.Context(), event)
+}
+
+// eventTypeFilter returns a filter matching the comma-separated event types, or every
+// type when the list is empty
+func eventTypeFilter(types string) func(eventType string) bool {
+	if types == "" {
+		return func(string) bool { return true }
+	}
+
+	wanted := make(map[string]bool)
+	for _, eventType := range strings.Split(types, ",") {
+		wanted[strings.TrimSpace(eventType)] = true
+	}
+	return func(eventType string) bool { return wanted[eventType] }
+}
+
+// HandleStreamScheduledItemEvents handles GET requests to stream scheduled item changes as server-sent events
+// @Summary Stream scheduled item events
+// @Description Stream the changes and executions of the scheduled items the caller may see using server-sent events. Each event's SSE type is the event type (scheduled_item.created, updated, rescheduled, deleted, executed, failed or skipped) and its data is the event as JSON. Reconnecting clients send Last-Event-ID to receive the recent events they missed.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last event received, to resume after a disconnect"
+// @Success 200 {object} events.Event
+// @Failure 400 {object} problem.Details "Invalid Last-Event-ID"
+// @Failure 500 {object} problem.Details "Streaming not supported"
+// @Router /scheduled-items/events [get]
+func (h *EventHandler) HandleStreamScheduledItemEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	// Without Last-Event-ID, only events from now on are sent
+	var missed []events.Event
+	var subscription <-chan events.Event
+	var unsubscribe func()
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		lastID, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Invalid Last-Event-ID")
+			return
+		}
+		missed, subscription, unsubscribe = h.bus.SubscribeAfter(lastID)
+	} else {
+		subscription, unsubscribe = h.bus.Subscribe()
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range missed {
+		if h.visible(r, event) {
+			writeScheduledItemEvent(w, event)
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event, ok := <-subscription:
+			if !ok {
+				return
+			}
+			if h.visible(r, event) && writeScheduledItemEvent(w, event) {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// writeScheduledItemEvent writes a scheduled item event in server-sent event format,
+// skipping other events. It reports whether the event was written.
+func writeScheduledItemEvent(w http.ResponseWriter, event events.Event) bool {
+	if !strings.HasPrefix(event.Type, "scheduled_item.") {
+		return false
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		logging.Errorf("Error encoding event ID=%d: %v", event.ID, err)
+		return false
+	}
+
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return true
+}
+
+// RegisterRoutes registers the HTTP routes for change events on the given mux
+func (h *EventHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /ws", h.HandleWebSocket)
+	mux.HandleFunc("GET /scheduled-items/events", h.HandleStreamScheduledItemEvents)
+}
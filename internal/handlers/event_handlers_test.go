@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"periodic-api/internal/events"
+	"periodic-api/internal/middleware"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketReceivesTodoEvents(t *testing.T) {
+	bus := events.NewBus()
+	todoStore := store.NewPublishingTodoItemStore(store.NewMemoryTodoItemStore(), bus)
+
+	allowOrigin := func(origin string) bool { return origin == "http://localhost:3000" }
+	router := middleware.Chain(NewRouter(NewEventHandler(bus, nil, allowOrigin)),
+		middleware.RequestID, middleware.Logging, middleware.Recovery, middleware.Compress, middleware.ETag)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?types=todo.created"
+	conn, err := websocket.Dial(url, "", "http://localhost:3000")
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	// Wait for the server to subscribe before publishing
+	deadline := time.Now().Add(time.Second)
+	for {
+		todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "From scheduler"})
+
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		var event struct {
+			Type string          `json:"type"`
+			Data models.TodoItem `json:"data"`
+		}
+		if err := websocket.JSON.Receive(conn, &event); err == nil {
+			if event.Type != events.TodoCreated || event.Data.Text != "From scheduler" {
+				t.Errorf("Unexpected event %+v", event)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for todo.created event")
+		}
+	}
+}
+
+func TestWebSocketRejectsDisallowedOrigin(t *testing.T) {
+	handler := NewEventHandler(events.NewBus(), nil, func(string) bool { return false })
+	server := httptest.NewServer(NewRouter(handler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	if conn, err := websocket.Dial(url, "", "http://evil.example"); err == nil {
+		conn.Close()
+		t.Error("Expected connection from a disallowed origin to be rejected")
+	}
+}
+
+func TestScheduledItemEventsResumeAfterLastEventID(t *testing.T) {
+	bus := events.NewBus()
+	bus.Publish(events.ScheduledItemCreated, models.ScheduledItem{ID: 1})
+	bus.Publish(events.TodoCreated, models.TodoItem{ID: 1})
+	bus.Publish(events.ScheduledItemDeleted, map[string]int64{"id": 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("GET", "/scheduled-items/events", nil).WithContext(ctx)
+	req.Header.Set("Last-Event-ID", "1")
+	rec := httptest.NewRecorder()
+
+	NewRouter(NewEventHandler(bus, nil, nil)).ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "id: 3\nevent: scheduled_item.deleted\n") {
+		t.Errorf("Expected missed scheduled_item.deleted event, got %q", body)
+	}
+	if strings.Contains(body, "id: 1\n") || strings.Contains(body, "todo.created") {
+		t.Errorf("Expected only scheduled item events after ID 1, got %q", body)
+	}
+}
+
+func TestScheduledItemEventsRejectsInvalidLastEventID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/scheduled-items/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	rec := httptest.NewRecorder()
+
+	NewRouter(NewEventHandler(events.NewBus(), nil, nil)).ServeHTTP(rec, req)
+
+	if rec.Code != 400 {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strconv"
+	"time"
+)
+
+// streamKeepAliveInterval is how often a comment is sent on idle server-sent event
+// streams so proxies don't close the connection
+const streamKeepAliveInterval = 15 * time.Second
+
+// executionLogSortFields are the fields execution logs can be listed by
+var executionLogSortFields = map[string]func(a, b models.ExecutionLog) int{
+	"id":         func(a, b models.ExecutionLog) int { return cmp.Compare(a.ID, b.ID) },
+	"executedAt": func(a, b models.ExecutionLog) int { return a.ExecutedAt.Compare(b.ExecutedAt) },
+}
+
+// executionLogCSVColumns are the columns of execution logs listed as CSV
+var executionLogCSVColumns = []csvColumn[models.ExecutionLog]{
+	{"id", func(entry models.ExecutionLog) string { return strconv.FormatInt(entry.ID, 10) }},
+	{"scheduledItemId", func(entry models.ExecutionLog) string { return strconv.FormatInt(entry.ScheduledItemID, 10) }},
+	{"executedAt", func(entry models.ExecutionLog) string { return csvTime(entry.ExecutedAt) }},
+	{"status", func(entry models.ExecutionLog) string { return entry.Status }},
+	{"errorMessage", func(entry models.ExecutionLog) string { return csvString(entry.ErrorMessage) }},
+	{"todoItemId", func(entry models.ExecutionLog) string {
+		return csvOptional(entry.TodoItemID, func(id int64) string { return strconv.FormatInt(id, 10) })
+	}},
+	{"executionKey", func(entry models.ExecutionLog) string { return csvString(entry.ExecutionKey) }},
+}
+
+// ExecutionLogHandler handles HTTP requests for execution logs
+type ExecutionLogHandler struct {
+	store store.ExecutionLogStore
+}
+
+// NewExecutionLogHandler creates a new handler with the given store
+func NewExecutionLogHandler(store store.ExecutionLogStore) *ExecutionLogHandler {
+	return &ExecutionLogHandler{
+		store: store,
+	}
+}
+
+// HandleGetAllExecutionLogs handles GET requests to retrieve all execution logs
+// @Summary Get all execution logs
+// @Description Retrieve the execution logs of every scheduled item, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per log. With the cursor parameter, the logs are returned a page at a time, newest first with ties broken by descending ID, and the cursor of the next page is sent in the X-Next-Cursor header. Pages leave out the logs of items the user can't see, so they can be shorter than the limit before the last one.
+// @Tags execution-logs
+// @Produce json,text/csv
+// @Param sort query string false "Sort by id or executedAt; prefix with - for descending order"
+// @Param cursor query string false "Page by cursor: empty for the first page, or the cursor of the page to return"
+// @Param limit query int false "Number of logs per page when paging by cursor" default(50)
+// @Param format query string false "Response format, overriding the Accept header" Enums(json, csv)
+// @Param X-User-ID header string false "ID of the user making the request, who only sees the executions of the items they can see"
+// @Success 200 {array} models.ExecutionLog
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} problem.Details "Invalid sort field, limit or cursor"
+// @Failure 500 {object} problem.Details "Failed to retrieve page"
+// @Router /execution-logs [get]
+func (h *ExecutionLogHandler) HandleGetAllExecutionLogs(w http.ResponseWriter, r *http.Request) {
+	if writeCursorPage(w, r, "execution-logs.csv", executionLogCSVColumns, func(limit int, cursor *store.ExecutionLogCursor) ([]models.ExecutionLog, *store.ExecutionLogCursor, error) {
+		return h.store.GetExecutionLogsPage(r.Context(), limit, cursor)
+	}) {
+		return
+	}
+
+	// Lists in store order are streamed as they are read; sorting needs them whole
+	if r.URL.Query().Get("sort") == "" {
+		writeListStream(w, r, "execution-logs.csv", executionLogCSVColumns, h.store.StreamExecutionLogs(r.Context()))
+		return
+	}
+
+	logs := h.store.GetAllExecutionLogs(r.Context())
+	if err := sortItems(logs, r.URL.Query().Get("sort"), executionLogSortFields); err != nil {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "sort", Message: err.Error()}).Write(w, r)
+		return
+	}
+
+	if wantsCSV(w, r) {
+		writeCSV(w, "execution-logs.csv", logs, executionLogCSVColumns)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logs)
+}
+
+// HandleStreamExecutionLogs handles GET requests to stream new execution logs as server-sent events
+// @Summary Stream execution logs
+// @Description Stream the execution log entries of the items the caller may see as they are created using server-sent events. Each entry is sent as an "execution-log" event with the log as JSON data.
+// @Tags execution-logs
+// @Produce text/event-stream
+// @Success 200 {object} models.ExecutionLog
+// @Failure 500 {object} problem.Details "Streaming not supported"
+// @Router /execution-logs/stream [get]
+func (h *ExecutionLogHandler) HandleStreamExecutionLogs(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	logs, unsubscribe := h.store.Subscribe(r.Context())
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case logEntry, ok := <-logs:
+			if !ok {
+				return
+			}
+			if !sameTenant(r, logEntry.TenantID) {
+				continue
+			}
+
+			data, err := json.Marshal(logEntry)
+			if err != nil {
+				logging.Errorf("Error encoding execution log ID=%d: %v", logEntry.ID, err)
+				continue
+			}
+
+			fmt.Fprintf(w, "id: %d\nevent: execution-log\ndata: %s\n\n", logEntry.ID, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// RegisterRoutes registers the HTTP routes for execution logs on the given mux
+func (h *ExecutionLogHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /execution-logs", h.HandleGetAllExecutionLogs)
+
+	// Live stream of new execution logs
+	mux.HandleFunc("GET /execution-logs/stream", h.HandleStreamExecutionLogs)
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// generationSessionTTL is how long a session lasts after its last message
+	generationSessionTTL = time.Hour
+	// generationSessionMaxMessages limits the conversation sent to the model with each message
+	generationSessionMaxMessages = 20
+)
+
+// GenerationMessageRequest represents the request body for refining a generation session
+type GenerationMessageRequest struct {
+	Message string `json:"message" validate:"required" example:"Actually make it biweekly"`
+}
+
+// HandleCreateGenerationSession handles POST requests to start a generation session
+// @Summary Start a generation session
+// @Description Generate a scheduled item from a prompt like POST /generate-scheduled-item, and keep the exchange so the item can be refined with further messages before it is committed. Sessions expire an hour after their last message.
+// @Tags generation
+// @Accept json
+// @Produce json
+// @Param request body GeneratePromptRequest true "Generation request with prompt and timezone"
+// @Param X-User-ID header string false "User the request is accounted to; defaults to the client address"
+// @Success 201 {object} models.GenerationSession
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 429 {object} problem.Details "Daily LLM quota used up"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Failure 502 {object} problem.Details "LLM service returned an error, or output that was still invalid after one repair"
+// @Failure 503 {object} problem.Details "LLM service or generation sessions not available"
+// @Failure 504 {object} problem.Details "LLM service timed out"
+// @Router /generation-sessions [post]
+func (h *ScheduledItemHandler) HandleCreateGenerationSession(w http.ResponseWriter, r *http.Request) {
+	if !h.generationSessionsAvailable(w, r) {
+		return
+	}
+
+	var req GeneratePromptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		problem.Validation("Invalid request", problem.FieldError{Field: "prompt", Message: "cannot be empty"}).Write(w, r)
+		return
+	}
+	if fieldErr := validateTimezone(req.Timezone); fieldErr != nil {
+		problem.Validation("Invalid request", *fieldErr).Write(w, r)
+		return
+	}
+
+	userID := usageUserID(r)
+	if !h.allowLLMRequest(w, r, userID) {
+		return
+	}
+
+	generatedJSON, errs, err := h.generateWithUsage(r, userID, models.LLMOperationGenerate, func(ctx context.Context) (string, []problem.FieldError, error) {
+		return h.generateValidJSON(ctx, func(ctx context.Context) (string, error) {
+			return h.llm.GenerateScheduledItemJSON(ctx, req.Prompt, req.Timezone)
+		}, h.checkGeneratedItem)
+	})
+	if err != nil {
+		writeLLMError(w, r, "Failed to generate scheduled item", err)
+		return
+	}
+	if len(errs) > 0 {
+		writeInvalidGeneration(w, r, "The LLM generated an invalid scheduled item", errs)
+		return
+	}
+
+	session := models.GenerationSession{
+		UserID:   userID,
+		Timezone: req.Timezone,
+	}
+	if !addGenerationExchange(w, r, &session, req.Prompt, generatedJSON) {
+		return
+	}
+
+	createdSession := h.sessions.CreateGenerationSession(r.Context(), session)
+	if createdSession.ID == 0 {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to create generation session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdSession)
+}
+
+// HandleAddGenerationSessionMessage handles POST requests to refine the item of a generation session
+// @Summary Refine the item of a generation session
+// @Description Ask for a change to the session's item, such as "actually make it biweekly". The earlier messages are sent to the model as context, and the session is returned with the revised item.
+// @Tags generation
+// @Accept json
+// @Produce json
+// @Param id path int true "Generation session ID"
+// @Param request body GenerationMessageRequest true "Requested change"
+// @Param X-User-ID header string false "User the request is accounted to; defaults to the client address"
+// @Success 200 {object} models.GenerationSession
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Generation session not found or expired"
+// @Failure 409 {object} problem.Details "Generation session has reached its message limit"
+// @Failure 429 {object} problem.Details "Daily LLM quota used up"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Failure 502 {object} problem.Details "LLM service returned an error, or output that was still invalid after one repair"
+// @Failure 503 {object} problem.Details "LLM service or generation sessions not available"
+// @Failure 504 {object} problem.Details "LLM service timed out"
+// @Router /generation-sessions/{id}/messages [post]
+func (h *ScheduledItemHandler) HandleAddGenerationSessionMessage(w http.ResponseWriter, r *http.Request) {
+	if !h.generationSessionsAvailable(w, r) {
+		return
+	}
+
+	session, ok := h.liveGenerationSession(w, r)
+	if !ok {
+		return
+	}
+
+	var req GenerationMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		problem.Validation("Invalid request", problem.FieldError{Field: "message", Message: "cannot be empty"}).Write(w, r)
+		return
+	}
+
+	if len(session.Messages)+2 > generationSessionMaxMessages {
+		problem.Write(w, r, http.StatusConflict, fmt.Sprintf("Generation session has reached its limit of %d messages; commit it or start a new one", generationSessionMaxMessages))
+		return
+	}
+
+	userID := usageUserID(r)
+	if !h.allowLLMRequest(w, r, userID) {
+		return
+	}
+
+	conversation := make([]utils.LLMTurn, len(session.Messages))
+	for i, message := range session.Messages {
+		conversation[i] = utils.LLMTurn{Role: message.Role, Content: message.Content}
+	}
+
+	generatedJSON, errs, err := h.generateWithUsage(r, userID, models.LLMOperationRefine, func(ctx context.Context) (string, []problem.FieldError, error) {
+		return h.generateValidJSON(ctx, func(ctx context.Context) (string, error) {
+			return h.llm.RefineScheduledItemJSON(ctx, conversation, req.Message, session.Timezone)
+		}, h.checkGeneratedItem)
+	})
+	if err != nil {
+		writeLLMError(w, r, "Failed to refine scheduled item", err)
+		return
+	}
+	if len(errs) > 0 {
+		writeInvalidGeneration(w, r, "The LLM generated an invalid scheduled item", errs)
+		return
+	}
+
+	if !addGenerationExchange(w, r, &session, req.Message, generatedJSON) {
+		return
+	}
+
+	updatedSession, exists := h.sessions.UpdateGenerationSession(r.Context(), session.ID, session)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Generation session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedSession)
+}
+
+// HandleGetGenerationSession handles GET requests to retrieve a generation session
+// @Summary Get a generation session
+// @Description Retrieve a generation session with its messages and latest item
+// @Tags generation
+// @Produce json
+// @Param id path int true "Generation session ID"
+// @Success 200 {object} models.GenerationSession
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Generation session not found or expired"
+// @Failure 503 {object} problem.Details "Generation sessions not available"
+// @Router /generation-sessions/{id} [get]
+func (h *ScheduledItemHandler) HandleGetGenerationSession(w http.ResponseWriter, r *http.Request) {
+	if !h.generationSessionsAvailable(w, r) {
+		return
+	}
+
+	session, ok := h.liveGenerationSession(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// HandleDeleteGenerationSession handles DELETE requests to discard a generation session
+// @Summary Discard a generation session
+// @Description Delete a generation session without creating its item
+// @Tags generation
+// @Param id path int true "Generation session ID"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Generation session not found"
+// @Failure 503 {object} problem.Details "Generation sessions not available"
+// @Router /generation-sessions/{id} [delete]
+func (h *ScheduledItemHandler) HandleDeleteGenerationSession(w http.ResponseWriter, r *http.Request) {
+	if !h.generationSessionsAvailable(w, r) {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if !h.sessions.DeleteGenerationSession(r.Context(), id) {
+		problem.Write(w, r, http.StatusNotFound, "Generation session not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleCommitGenerationSession handles POST requests to create the item of a generation session
+// @Summary Commit a generation session
+// @Description Validate and create the session's latest item like POST /scheduled-items, then end the session
+// @Tags generation
+// @Produce json
+// @Param id path int true "Generation session ID"
+// @Success 201 {object} models.ScheduledItem
+// @Failure 400 {object} problem.Details "Invalid ID, or the generated item is invalid"
+// @Failure 403 {object} problem.Details "Tenant limit of scheduled items reached"
+// @Failure 404 {object} problem.Details "Generation session not found or expired"
+// @Failure 503 {object} problem.Details "Generation sessions not available"
+// @Router /generation-sessions/{id}/commit [post]
+func (h *ScheduledItemHandler) HandleCommitGenerationSession(w http.ResponseWriter, r *http.Request) {
+	if !h.generationSessionsAvailable(w, r) {
+		return
+	}
+
+	session, ok := h.liveGenerationSession(w, r)
+	if !ok {
+		return
+	}
+	if !h.allowNewScheduledItem(w, r) {
+		return
+	}
+
+	createdItem, errs := h.createGeneratedItem(r, session.Item)
+	if len(errs) > 0 {
+		problem.Validation("Generated scheduled item is invalid", errs...).Write(w, r)
+		return
+	}
+
+	h.sessions.DeleteGenerationSession(r.Context(), session.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdItem)
+}
+
+// generationSessionsAvailable writes a problem and returns false unless generation and
+// sessions are both enabled
+func (h *ScheduledItemHandler) generationSessionsAvailable(w http.ResponseWriter, r *http.Request) bool {
+	if h.llm == nil || h.sessions == nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, "Generation sessions not available")
+		return false
+	}
+	return true
+}
+
+// liveGenerationSession returns the session in the request path. It writes a problem and
+// returns false if the ID is invalid or the session doesn't exist or has expired.
+func (h *ScheduledItemHandler) liveGenerationSession(w http.ResponseWriter, r *http.Request) (models.GenerationSession, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return models.GenerationSession{}, false
+	}
+
+	session, exists := h.sessions.GetGenerationSession(r.Context(), id)
+	if !exists || !session.ExpiresAt.After(time.Now()) {
+		problem.Write(w, r, http.StatusNotFound, "Generation session not found or expired")
+		return models.GenerationSession{}, false
+	}
+	return session, true
+}
+
+// addGenerationExchange appends a user message and the item generated in reply to a
+// session, making the item the session's latest and extending its expiry
+func addGenerationExchange(w http.ResponseWriter, r *http.Request, session *models.GenerationSession, message string, generatedJSON string) bool {
+	var item models.ScheduledItem
+	if err := json.Unmarshal([]byte(generatedJSON), &item); err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to decode generated item: "+err.Error())
+		return false
+	}
+
+	now := time.Now()
+	session.Messages = append(session.Messages,
+		models.GenerationMessage{Role: models.GenerationRoleUser, Content: message, CreatedAt: now},
+		models.GenerationMessage{Role: models.GenerationRoleAssistant, Content: generatedJSON, CreatedAt: now},
+	)
+	session.Item = item
+	session.ExpiresAt = now.Add(generationSessionTTL)
+	return true
+}
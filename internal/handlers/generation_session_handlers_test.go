@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerationSessionRefineAndCommit(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	sessionStore := store.NewMemoryGenerationSessionStore()
+	router := NewRouter(handler)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	// Sessions need both generation and a session store
+	if rec := send(http.MethodPost, "/generation-sessions", `{"prompt":"Water the plants","timezone":"UTC"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status 503 before sessions are enabled, got %d", rec.Code)
+	}
+
+	var conversation []utils.LLMTurn
+	handler.EnableGeneration(fakeLLMProvider{
+		generated:    `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z","repeats":true,"cronExpression":"0 8 * * 1"}`,
+		refined:      `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z","repeats":true,"cronExpression":"0 8 1,15 * *"}`,
+		conversation: &conversation,
+	}, testSchemas(t))
+	handler.EnableGenerationSessions(sessionStore)
+
+	rec := send(http.MethodPost, "/generation-sessions", `{"prompt":"Water the plants every Monday morning","timezone":"UTC"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var session models.GenerationSession
+	json.NewDecoder(rec.Body).Decode(&session)
+	if session.ID == 0 || len(session.Messages) != 2 || session.Item.CronExpression == nil || *session.Item.CronExpression != "0 8 * * 1" {
+		t.Fatalf("Unexpected session %+v", session)
+	}
+
+	// Refining sends the earlier exchange as context and replaces the item
+	path := fmt.Sprintf("/generation-sessions/%d", session.ID)
+	rec = send(http.MethodPost, path+"/messages", `{"message":"Actually make it biweekly"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	json.NewDecoder(rec.Body).Decode(&session)
+	if len(conversation) != 2 || conversation[0].Content != "Water the plants every Monday morning" || conversation[1].Role != utils.LLMRoleAssistant {
+		t.Errorf("Expected the first exchange as context, got %+v", conversation)
+	}
+	if len(session.Messages) != 4 || session.Messages[2].Content != "Actually make it biweekly" || *session.Item.CronExpression != "0 8 1,15 * *" {
+		t.Errorf("Expected the refined item, got %+v", session)
+	}
+
+	if rec := send(http.MethodPost, path+"/messages", `{"message":" "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty message, got %d", rec.Code)
+	}
+
+	// Committing creates the latest item and ends the session
+	rec = send(http.MethodPost, path+"/commit", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.ScheduledItem
+	json.NewDecoder(rec.Body).Decode(&created)
+	if stored, exists := itemStore.GetScheduledItem(context.Background(), created.ID); !exists || *stored.CronExpression != "0 8 1,15 * *" {
+		t.Errorf("Expected the refined item to be created, got %+v", stored)
+	}
+	if rec := send(http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected the committed session to be gone, got %d", rec.Code)
+	}
+}
+
+func TestGenerationSessionExpiryAndMessageLimit(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	sessionStore := store.NewMemoryGenerationSessionStore()
+	router := NewRouter(handler)
+
+	handler.EnableGeneration(fakeLLMProvider{
+		refined: `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`,
+	}, testSchemas(t))
+	handler.EnableGenerationSessions(sessionStore)
+
+	refine := func(id int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		path := fmt.Sprintf("/generation-sessions/%d/messages", id)
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"message":"Make it later"}`)))
+		return rec
+	}
+
+	expired := sessionStore.CreateGenerationSession(context.Background(), models.GenerationSession{
+		Timezone:  "UTC",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if rec := refine(expired.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an expired session, got %d", rec.Code)
+	}
+
+	full := models.GenerationSession{Timezone: "UTC", ExpiresAt: time.Now().Add(time.Hour)}
+	for range generationSessionMaxMessages {
+		full.Messages = append(full.Messages, models.GenerationMessage{Role: models.GenerationRoleUser, Content: "Water the plants"})
+	}
+	full = sessionStore.CreateGenerationSession(context.Background(), full)
+	if rec := refine(full.ID); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 once the message limit is reached, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Creating a session removes expired ones
+	if _, exists := sessionStore.GetGenerationSession(context.Background(), expired.ID); exists {
+		t.Error("Expected the expired session to be removed")
+	}
+}
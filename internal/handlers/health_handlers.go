@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/problem"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// schemaCheckInterval is how long a schema check is reused before the database is asked again,
+// so gating every request on it doesn't add a query to each
+const schemaCheckInterval = 5 * time.Second
+
+// SchemaStatusSource reads the migration state of the database
+type SchemaStatusSource func(ctx context.Context) (migrations.SchemaStatus, error)
+
+// Readiness reports whether the API can serve traffic
+type Readiness struct {
+	Ready bool `json:"ready" example:"true"`
+	// Schema is the migration state of the database, when the storage has one
+	Schema *migrations.SchemaStatus `json:"schema,omitempty"`
+	// Detail explains why the API isn't ready
+	Detail string `json:"detail,omitempty" example:"schema is at version 17 but this build expects 19; run the pending migrations"`
+}
+
+// HealthHandler serves the liveness and readiness probes and holds back API requests while
+// the database schema doesn't match the one this build expects
+type HealthHandler struct {
+	schemaStatus SchemaStatusSource
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	readiness Readiness
+}
+
+// NewHealthHandler creates a new handler checking the schema with the given source, or
+// always ready when the storage has no schema
+func NewHealthHandler(schemaStatus SchemaStatusSource) *HealthHandler {
+	return &HealthHandler{
+		schemaStatus: schemaStatus,
+	}
+}
+
+// check returns whether the API is ready, checking the schema at most once per schemaCheckInterval
+func (h *HealthHandler) check(ctx context.Context) Readiness {
+	if h.schemaStatus == nil {
+		return Readiness{Ready: true}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.checkedAt.IsZero() && time.Since(h.checkedAt) < schemaCheckInterval {
+		return h.readiness
+	}
+
+	status, err := h.schemaStatus(ctx)
+	switch {
+	case err != nil:
+		h.readiness = Readiness{Detail: err.Error()}
+	case !status.Ready():
+		h.readiness = Readiness{Schema: &status, Detail: status.String()}
+	default:
+		h.readiness = Readiness{Ready: true, Schema: &status}
+	}
+	h.checkedAt = time.Now()
+	return h.readiness
+}
+
+// HandleLiveness reports that the process is up
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// HandleReadiness reports whether the API can serve traffic, responding 503 while the
+// database can't be reached or its schema is behind this build or dirty
+func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	readiness := h.check(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(readiness)
+}
+
+// RequireReady rejects requests with 503 Service Unavailable while the API isn't ready,
+// rather than letting them fail on missing tables or columns. The migration report is
+// served regardless.
+func (h *HealthHandler) RequireReady(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == migrationsPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if readiness := h.check(r.Context()); !readiness.Ready {
+			w.Header().Set("Retry-After", strconv.Itoa(int(schemaCheckInterval.Seconds())))
+			problem.Write(w, r, http.StatusServiceUnavailable, "Service not ready: "+readiness.Detail)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RegisterRoutes registers the liveness and readiness probes on the given mux
+func (h *HealthHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", h.HandleLiveness)
+	mux.HandleFunc("GET /readyz", h.HandleReadiness)
+}
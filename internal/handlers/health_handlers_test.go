@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/migrations"
+	"testing"
+)
+
+func TestReadinessRequiresCurrentSchema(t *testing.T) {
+	cases := []struct {
+		name   string
+		status migrations.SchemaStatus
+		ready  bool
+	}{
+		{"up to date", migrations.SchemaStatus{Version: 19, Expected: 19}, true},
+		{"ahead", migrations.SchemaStatus{Version: 20, Expected: 19}, true},
+		{"behind", migrations.SchemaStatus{Version: 17, Expected: 19}, false},
+		{"dirty", migrations.SchemaStatus{Version: 19, Expected: 19, Dirty: true}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			health := NewHealthHandler(func(ctx context.Context) (migrations.SchemaStatus, error) {
+				return c.status, nil
+			})
+			api := health.RequireReady(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			router := NewRouter(health)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			var readiness Readiness
+			if err := json.NewDecoder(rec.Body).Decode(&readiness); err != nil {
+				t.Fatalf("Failed to decode readiness: %v", err)
+			}
+			wantCode := http.StatusOK
+			if !c.ready {
+				wantCode = http.StatusServiceUnavailable
+			}
+			if rec.Code != wantCode || readiness.Ready != c.ready || readiness.Schema == nil || *readiness.Schema != c.status {
+				t.Errorf("Expected %d with ready=%v, got %d %+v", wantCode, c.ready, rec.Code, readiness)
+			}
+
+			// API requests are only served while ready
+			rec = httptest.NewRecorder()
+			api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduled-items", nil))
+			if c.ready && rec.Code != http.StatusNoContent {
+				t.Errorf("Expected the request to be served, got %d", rec.Code)
+			}
+			if !c.ready && (rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "") {
+				t.Errorf("Expected 503 with Retry-After, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestReadinessCachesSchemaChecks(t *testing.T) {
+	checks := 0
+	health := NewHealthHandler(func(ctx context.Context) (migrations.SchemaStatus, error) {
+		checks++
+		return migrations.SchemaStatus{Version: 19, Expected: 19}, nil
+	})
+
+	for range 3 {
+		health.check(context.Background())
+	}
+	if checks != 1 {
+		t.Errorf("Expected the schema to be checked once, got %d", checks)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strings"
+	"time"
+)
+
+// userIDHeader identifies the user making a request
+const userIDHeader = "X-User-ID"
+
+// LLMQuota limits the generation requests and the tokens each user may use per UTC day;
+// 0 means unlimited
+type LLMQuota struct {
+	DailyRequests int
+	DailyTokens   int
+}
+
+// LLMUsageHandler handles HTTP requests for LLM usage
+type LLMUsageHandler struct {
+	store store.LLMUsageStore
+}
+
+// NewLLMUsageHandler creates a new handler with the given store
+func NewLLMUsageHandler(store store.LLMUsageStore) *LLMUsageHandler {
+	return &LLMUsageHandler{
+		store: store,
+	}
+}
+
+// HandleGetLLMUsage handles GET requests to list LLM usage
+// @Summary Get LLM usage
+// @Description Retrieve the usage recorded for each generation request, oldest first: the requesting user, model, tokens and latency. Defaults to the current UTC day. Administrators see every user's usage; everyone else only their own.
+// @Tags generation
+// @Produce json
+// @Param userId query string false "Only return the usage of this user"
+// @Param since query string false "Start of the period, inclusive (RFC 3339)"
+// @Param until query string false "End of the period, exclusive (RFC 3339)"
+// @Success 200 {array} models.LLMUsage
+// @Failure 400 {object} problem.Details "Invalid period"
+// @Failure 403 {object} problem.Details "Another user's usage was asked for by a non-administrator"
+// @Router /llm-usage [get]
+func (h *LLMUsageHandler) HandleGetLLMUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := usageReader(w, r)
+	if !ok {
+		return
+	}
+	since, until, ok := parseUsagePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	usage := h.store.GetLLMUsage(r.Context(), userID, since, until)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// HandleGetLLMUsageSummary handles GET requests to total LLM usage per user
+// @Summary Get LLM usage per user
+// @Description Total the generation requests and tokens of each user over a period. Defaults to the current UTC day, the period quotas apply to. Administrators see every user's totals; everyone else only their own.
+// @Tags generation
+// @Produce json
+// @Param userId query string false "Only total the usage of this user"
+// @Param since query string false "Start of the period, inclusive (RFC 3339)"
+// @Param until query string false "End of the period, exclusive (RFC 3339)"
+// @Success 200 {array} models.LLMUsageTotals
+// @Failure 400 {object} problem.Details "Invalid period"
+// @Failure 403 {object} problem.Details "Another user's usage was asked for by a non-administrator"
+// @Router /llm-usage/summary [get]
+func (h *LLMUsageHandler) HandleGetLLMUsageSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := usageReader(w, r)
+	if !ok {
+		return
+	}
+	since, until, ok := parseUsagePeriod(w, r)
+	if !ok {
+		return
+	}
+
+	totals := store.SumLLMUsage(h.store.GetLLMUsage(r.Context(), userID, since, until))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(totals)
+}
+
+// RegisterRoutes registers the LLM usage routes on the given mux
+func (h *LLMUsageHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /llm-usage", h.HandleGetLLMUsage)
+	mux.HandleFunc("GET /llm-usage/summary", h.HandleGetLLMUsageSummary)
+}
+
+// usageReader returns the user whose usage a request reads: the one named by the userId
+// query parameter, or every user when it is empty, for administrators, and the requesting
+// user for everyone else. It writes a 403 problem and returns false when a non-administrator
+// asks for another user's usage.
+func usageReader(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := r.URL.Query().Get("userId")
+	if tenantAdmin(r) {
+		return userID, true
+	}
+	own := usageUserID(r)
+	if userID != "" && userID != own {
+		problem.Write(w, r, http.StatusForbidden, "Only administrators can see other users' LLM usage")
+		return "", false
+	}
+	return own, true
+}
+
+// parseUsagePeriod reads the since and until query parameters, defaulting to the current
+// UTC day. It writes a validation problem and returns false if they are invalid.
+func parseUsagePeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	since, until := usageDay(time.Now())
+	var errs []problem.FieldError
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"since", &since}, {"until", &until}} {
+		raw := r.URL.Query().Get(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			errs = append(errs, problem.FieldError{Field: param.name, Message: "must be an RFC 3339 time such as 2024-01-01T00:00:00Z"})
+			continue
+		}
+		*param.value = parsed
+	}
+	if len(errs) == 0 && !until.After(since) {
+		errs = append(errs, problem.FieldError{Field: "until", Message: "must be after since"})
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid query parameter", errs...).Write(w, r)
+		return time.Time{}, time.Time{}, false
+	}
+	return since, until, true
+}
+
+// usageDay returns the start and end of the UTC day containing t, the period quotas apply to
+func usageDay(t time.Time) (time.Time, time.Time) {
+	start := t.UTC().Truncate(24 * time.Hour)
+	return start, start.Add(24 * time.Hour)
+}
+
+// requestUserID identifies the user making a request from the X-User-ID header, falling
+// back to the client address until requests are authenticated
+func requestUserID(r *http.Request) string {
+	if userID := strings.TrimSpace(r.Header.Get(userIDHeader)); userID != "" {
+		return userID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// usageUserID identifies who LLM usage is recorded against and quotas are applied to: the
+// subject of the request's verified token, or the tenant of an administrator's token that
+// names no user. Without an auth secret it is the X-User-ID header, trusted like everywhere
+// else in that mode, and the client address only for requests without one.
+func usageUserID(r *http.Request) string {
+	// AuthenticateTenant replaces any X-User-ID header with the token's subject
+	if authenticated(r) && r.Header.Get(userIDHeader) == "" {
+		return "tenant:" + store.TenantFromContext(r.Context())
+	}
+	return requestUserID(r)
+}
+
+// usageTotals returns the usage of a user in the current UTC day
+func usageTotals(r *http.Request, usageStore store.LLMUsageStore, userID string) models.LLMUsageTotals {
+	since, until := usageDay(time.Now())
+	totals := store.SumLLMUsage(usageStore.GetLLMUsage(r.Context(), userID, since, until))
+	if len(totals) == 0 {
+		return models.LLMUsageTotals{UserID: userID}
+	}
+	return totals[0]
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerationUsageAccountingAndQuotas(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	usageStore := store.NewMemoryLLMUsageStore()
+	const secret = "tenant-secret"
+	router := AuthenticateTenant([]byte(secret), NewRouter(handler, NewLLMUsageHandler(usageStore)))
+	admin := tenantToken(secret, map[string]any{"tenant": "acme", "admin": true})
+
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`,
+	}, testSchemas(t))
+	handler.EnableUsageAccounting(usageStore, LLMQuota{DailyRequests: 2})
+
+	generate := func(userID string) *httptest.ResponseRecorder {
+		body := `{"prompt":"Water the plants every morning","timezone":"UTC"}`
+		req := httptest.NewRequest(http.MethodPost, "/generate-scheduled-item", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tenantToken(secret, map[string]any{"tenant": "acme", "sub": userID}))
+		// The header is the client's to choose, so it mustn't decide whose quota is used
+		req.Header.Set(userIDHeader, "someone-else")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+admin)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Each request is recorded against the requesting user
+	for range 2 {
+		if rec := generate("1"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	var usage []models.LLMUsage
+	json.NewDecoder(get("/llm-usage?userId=1").Body).Decode(&usage)
+	if len(usage) != 2 || usage[0].UserID != "1" || usage[0].Operation != models.LLMOperationGenerate || !usage[0].Succeeded {
+		t.Fatalf("Expected two recorded generations, got %+v", usage)
+	}
+
+	// Once the quota is used up requests are refused until the next UTC day
+	rec := generate("1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var details problem.Details
+	json.NewDecoder(rec.Body).Decode(&details)
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if details.Type != problem.TypeQuotaExceeded || err != nil || retryAfter <= 0 || retryAfter > 24*60*60 {
+		t.Errorf("Expected a quota problem retrying before tomorrow, got %+v with Retry-After %q", details, rec.Header().Get("Retry-After"))
+	}
+
+	// Other users have their own quota
+	if rec := generate("2"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another user to be allowed, got %d", rec.Code)
+	}
+
+	// Token quotas count the tokens of earlier requests the same day
+	usageStore.CreateLLMUsage(store.WithTenant(context.Background(), "acme"), models.LLMUsage{UserID: "3", InputTokens: 900, OutputTokens: 100})
+	handler.SetLLMQuota(LLMQuota{DailyTokens: 1000})
+	if rec := generate("3"); rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "1000 LLM tokens") {
+		t.Errorf("Expected the token quota to be used up, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var totals []models.LLMUsageTotals
+	json.NewDecoder(get("/llm-usage/summary").Body).Decode(&totals)
+	if len(totals) != 3 || totals[0].UserID != "1" || totals[0].Requests != 2 || totals[2].Tokens() != 1000 {
+		t.Errorf("Unexpected usage summary %+v", totals)
+	}
+
+	// Usage outside the period isn't returned
+	since := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	until := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	json.NewDecoder(get("/llm-usage?since=" + since + "&until=" + until).Body).Decode(&usage)
+	if len(usage) != 0 {
+		t.Errorf("Expected no usage in the future, got %+v", usage)
+	}
+
+	if rec := get("/llm-usage?since=yesterday"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "since") {
+		t.Errorf("Expected a since validation error, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerationQuotaWithoutSecret(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	router := NewRouter(handler)
+
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`,
+	}, testSchemas(t))
+	usageStore := store.NewMemoryLLMUsageStore()
+	handler.EnableUsageAccounting(usageStore, LLMQuota{DailyRequests: 1})
+
+	generate := func(userHeader, remoteAddr string) int {
+		body := `{"prompt":"Water the plants every morning","timezone":"UTC"}`
+		req := httptest.NewRequest(http.MethodPost, "/generate-scheduled-item", strings.NewReader(body))
+		if userHeader != "" {
+			req.Header.Set(userIDHeader, userHeader)
+		}
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Clients behind the same load balancer have a quota of their own per user
+	if code := generate("alice", "10.0.0.1:1234"); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if code := generate("bob", "10.0.0.1:5678"); code != http.StatusOK {
+		t.Errorf("Expected another user behind the same address to have their own quota, got %d", code)
+	}
+	if code := generate("alice", "10.0.0.2:1234"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected alice's quota to be used up from any address, got %d", code)
+	}
+	if usage := usageStore.GetLLMUsage(context.Background(), "alice", time.Time{}, time.Now().Add(time.Hour)); len(usage) != 1 {
+		t.Errorf("Expected usage to be recorded against the user, got %+v", usage)
+	}
+
+	// Requests naming no user fall back to the client address
+	if code := generate("", "192.0.2.1:1234"); code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	if code := generate("", "192.0.2.1:5678"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the client's quota to be used up, got %d", code)
+	}
+}
+
+func TestLLMUsageIsOnlyReadByItsUserOrAdministrators(t *testing.T) {
+	const secret = "tenant-secret"
+	usageStore := store.NewMemoryLLMUsageStore()
+	ctx := store.WithTenant(context.Background(), "acme")
+	usageStore.CreateLLMUsage(ctx, models.LLMUsage{UserID: "1", InputTokens: 10})
+	usageStore.CreateLLMUsage(ctx, models.LLMUsage{UserID: "2", InputTokens: 20})
+	router := AuthenticateTenant([]byte(secret), NewRouter(NewLLMUsageHandler(usageStore)))
+
+	get := func(claims map[string]any, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tenantToken(secret, claims))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	user := map[string]any{"tenant": "acme", "sub": "1"}
+	admin := map[string]any{"tenant": "acme", "admin": true}
+
+	for _, path := range []string{"/llm-usage", "/llm-usage/summary"} {
+		var usage []struct{ UserID string }
+		json.NewDecoder(get(user, path).Body).Decode(&usage)
+		if len(usage) != 1 || usage[0].UserID != "1" {
+			t.Errorf("Expected %s to return only the user's own usage, got %+v", path, usage)
+		}
+		if rec := get(user, path+"?userId=2"); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for another user's usage at %s, got %d", path, rec.Code)
+		}
+		json.NewDecoder(get(admin, path).Body).Decode(&usage)
+		if len(usage) != 2 {
+			t.Errorf("Expected an administrator to see every user's usage at %s, got %+v", path, usage)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// applyMergePatch applies an RFC 7386 JSON Merge Patch to target: fields present in the
+// patch replace those in target, nested objects are merged, and null removes a field,
+// resetting it to its zero value
+func applyMergePatch[T any](target *T, patch []byte) error {
+	original, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	var document, patchDocument any
+	if err := decodeJSONNumbers(original, &document); err != nil {
+		return err
+	}
+	if err := decodeJSONNumbers(patch, &patchDocument); err != nil {
+		return err
+	}
+	if _, ok := patchDocument.(map[string]any); !ok {
+		return fmt.Errorf("merge patch must be a JSON object")
+	}
+
+	merged, err := json.Marshal(mergePatch(document, patchDocument))
+	if err != nil {
+		return err
+	}
+
+	var result T
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return err
+	}
+	*target = result
+	return nil
+}
+
+// mergePatch implements the MergePatch algorithm from RFC 7386
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+// decodeJSONNumbers decodes data keeping numbers exact, so large IDs survive the round trip
+func decodeJSONNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"periodic-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	cron := "0 9 * * *"
+	item := models.ScheduledItem{
+		ID:             1,
+		Title:          "Original",
+		Description:    "Keep me",
+		StartsAt:       time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC),
+		Repeats:        true,
+		CronExpression: &cron,
+		ActionConfig:   json.RawMessage(`{"url":"https://example.com","method":"POST"}`),
+	}
+
+	patch := `{"title":"Patched","cronExpression":null,"actionConfig":{"method":"PUT"}}`
+	if err := applyMergePatch(&item, []byte(patch)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if item.Title != "Patched" || item.Description != "Keep me" {
+		t.Errorf("Expected only title to change, got title %q description %q", item.Title, item.Description)
+	}
+	if item.CronExpression != nil {
+		t.Errorf("Expected null to clear cronExpression, got %q", *item.CronExpression)
+	}
+
+	var config map[string]string
+	json.Unmarshal(item.ActionConfig, &config)
+	if config["url"] != "https://example.com" || config["method"] != "PUT" {
+		t.Errorf("Expected nested objects to be merged, got %v", config)
+	}
+
+	if err := applyMergePatch(&item, []byte(`["not","an","object"]`)); err == nil {
+		t.Error("Expected an error for a non-object patch")
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/migrations"
+	"periodic-api/internal/problem"
+)
+
+// migrationsPath is the path of the migration report, served while the schema isn't ready
+// so operators can see why
+const migrationsPath = "/admin/migrations"
+
+// MigrationReportSource reads the migration state of the database and the migrations
+// applied to it and pending
+type MigrationReportSource func(ctx context.Context) (migrations.Report, error)
+
+// MigrationHandler handles HTTP requests for the migration state of the database
+type MigrationHandler struct {
+	report MigrationReportSource
+}
+
+// NewMigrationHandler creates a new handler reporting with the given source
+func NewMigrationHandler(report MigrationReportSource) *MigrationHandler {
+	return &MigrationHandler{
+		report: report,
+	}
+}
+
+// HandleGetMigrations handles GET requests for the migration state of the database
+// @Summary Get migration status
+// @Description Get the schema version recorded by the migrations, whether the last migration failed partway (dirty), the version this build expects, and the migrations in the migrations directory applied up to that version and still pending. Served while the schema isn't ready, unlike the rest of the API. Only available when USE_POSTGRES_DB is enabled. Requires a tenant token with the admin claim when tenant tokens are checked.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} migrations.Report
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 500 {object} problem.Details "The migrations couldn't be read"
+// @Router /admin/migrations [get]
+func (h *MigrationHandler) HandleGetMigrations(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	report, err := h.report(r.Context())
+	if err != nil {
+		logging.Errorf("Failed to read the migration status: %v", err)
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to read the migration status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RegisterRoutes registers the HTTP routes for the migration state on the given mux
+func (h *MigrationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+migrationsPath, h.HandleGetMigrations)
+}
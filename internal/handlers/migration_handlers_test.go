@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/migrations"
+	"testing"
+)
+
+func TestMigrationReportIsServedWhileTheSchemaIsBehind(t *testing.T) {
+	behind := migrations.SchemaStatus{Version: 27, Expected: 28}
+	health := NewHealthHandler(func(ctx context.Context) (migrations.SchemaStatus, error) {
+		return behind, nil
+	})
+	migrationHandler := NewMigrationHandler(func(ctx context.Context) (migrations.Report, error) {
+		return migrations.Report{
+			SchemaStatus: behind,
+			Applied:      []migrations.MigrationFile{{Version: 27, Name: "add_tenant_limits"}},
+			Pending:      []migrations.MigrationFile{{Version: 28, Name: "add_user_deactivated_at"}},
+		}, nil
+	})
+	api := health.RequireReady(AllowUnauthenticatedAdmin(NewRouter(migrationHandler)))
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/migrations", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 while the schema is behind, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report migrations.Report
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode the report: %v", err)
+	}
+	if report.Version != 27 || len(report.Applied) != 1 || len(report.Pending) != 1 || report.Pending[0].Name != "add_user_deactivated_at" {
+		t.Errorf("Expected migration 28 pending at version 27, got %+v", report)
+	}
+
+	// Only administrators see it
+	token := tenantToken("tenant-secret", map[string]any{"tenant": "acme", "sub": "1"})
+	req := httptest.NewRequest(http.MethodGet, "/admin/migrations", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	health.RequireReady(AuthenticateTenant([]byte("tenant-secret"), NewRouter(migrationHandler))).ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a tenant without the admin claim, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NotificationPreferenceHandler handles HTTP requests for users' notification preferences
+type NotificationPreferenceHandler struct {
+	store     store.NotificationPreferenceStore
+	userStore store.UserStore
+}
+
+// NewNotificationPreferenceHandler creates a new handler with the given stores
+func NewNotificationPreferenceHandler(store store.NotificationPreferenceStore, userStore store.UserStore) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{
+		store:     store,
+		userStore: userStore,
+	}
+}
+
+// userID parses the user ID from the path and checks that the user exists, writing the
+// problem and returning false otherwise
+func (h *NotificationPreferenceHandler) userID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return 0, false
+	}
+	if _, exists := h.userStore.GetUser(r.Context(), id); !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// HandleGetNotificationPreference handles GET requests to retrieve a user's notification preferences
+// @Summary Get a user's notification preferences
+// @Description Get the channels, events and quiet hours a user is notified with
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.NotificationPreference
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User not found or no preferences set"
+// @Router /users/{id}/notification-preferences [get]
+func (h *NotificationPreferenceHandler) HandleGetNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	preference, exists := h.store.GetNotificationPreference(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Notification preferences not set")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preference)
+}
+
+// HandlePutNotificationPreference handles PUT requests to set a user's notification preferences
+// @Summary Set a user's notification preferences
+// @Description Create or replace the channels (email, slack), events (execution, failure, reminder, digest) and quiet hours a user is notified with. Items listing the user follow these preferences instead of their own.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param preference body models.NotificationPreference true "Notification preferences"
+// @Success 200 {object} models.NotificationPreference
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "User not found"
+// @Failure 500 {object} problem.Details "Failed to save preferences"
+// @Router /users/{id}/notification-preferences [put]
+func (h *NotificationPreferenceHandler) HandlePutNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	var preference models.NotificationPreference
+	if err := json.NewDecoder(r.Body).Decode(&preference); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	preference.UserID = id
+
+	if errs := validateNotificationPreference(preference); len(errs) > 0 {
+		problem.Validation("Invalid notification preferences", errs...).Write(w, r)
+		return
+	}
+
+	saved, ok := h.store.SaveNotificationPreference(r.Context(), preference)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to save notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// HandleDeleteNotificationPreference handles DELETE requests to remove a user's notification preferences
+// @Summary Remove a user's notification preferences
+// @Description Remove a user's preferences, so items listing the user email them following the items' own settings
+// @Tags users
+// @Param id path int true "User ID"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User not found or no preferences set"
+// @Router /users/{id}/notification-preferences [delete]
+func (h *NotificationPreferenceHandler) HandleDeleteNotificationPreference(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.userID(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.store.DeleteNotificationPreference(r.Context(), id) {
+		problem.Write(w, r, http.StatusNotFound, "Notification preferences not set")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateNotificationPreference checks the channels, events, quiet hours and templates of
+// a user's notification preferences
+func validateNotificationPreference(preference models.NotificationPreference) []problem.FieldError {
+	var errs []problem.FieldError
+
+	for _, channel := range preference.Channels {
+		if !slices.Contains(models.NotificationChannels, channel) {
+			errs = append(errs, problem.FieldError{
+				Field:   "channels",
+				Message: "unknown channel " + strconv.Quote(channel) + "; expected one of " + strings.Join(models.NotificationChannels, ", "),
+			})
+		}
+	}
+	if slices.Contains(preference.Channels, models.NotificationChannelSlack) && preference.SlackUserID == "" {
+		errs = append(errs, problem.FieldError{Field: "slackUserId", Message: "is required for the slack channel"})
+	}
+
+	for _, event := range preference.Events {
+		if !slices.Contains(models.NotificationEvents, event) {
+			errs = append(errs, problem.FieldError{
+				Field:   "events",
+				Message: "unknown event " + strconv.Quote(event) + "; expected one of " + strings.Join(models.NotificationEvents, ", "),
+			})
+		}
+	}
+
+	if err := notifications.ValidateQuietHours(preference.QuietHoursStart, preference.QuietHoursEnd, preference.Timezone); err != nil {
+		field := "quietHoursStart"
+		if strings.Contains(err.Error(), "time zone") {
+			field = "timezone"
+		}
+		errs = append(errs, problem.FieldError{Field: field, Message: err.Error()})
+	}
+
+	if err := notifications.ValidateTemplates(preference.Subject, preference.Body); err != nil {
+		field := "subject"
+		if strings.Contains(err.Error(), "body template") {
+			field = "body"
+		}
+		errs = append(errs, problem.FieldError{Field: field, Message: err.Error()})
+	}
+
+	return errs
+}
+
+// RegisterRoutes registers the HTTP routes for notification preferences on the given mux
+func (h *NotificationPreferenceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /users/{id}/notification-preferences", h.HandleGetNotificationPreference)
+	mux.HandleFunc("PUT /users/{id}/notification-preferences", h.HandlePutNotificationPreference)
+	mux.HandleFunc("DELETE /users/{id}/notification-preferences", h.HandleDeleteNotificationPreference)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestNotificationPreferencesAreSavedPerUser(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	user := users.CreateUser(context.Background(), models.User{Username: "alice", Email: "alice@example.com"})
+	router := NewRouter(NewNotificationPreferenceHandler(store.NewMemoryNotificationPreferenceStore(), users))
+	path := fmt.Sprintf("/users/%d/notification-preferences", user.ID)
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 before preferences are set, got %d", rec.Code)
+	}
+
+	body := `{"userId":99,"channels":["email"],"events":["failure","digest"],"quietHoursStart":"22:00","quietHoursEnd":"07:00","timezone":"Europe/London"}`
+	req = httptest.NewRequest(http.MethodPut, path, strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var fetched models.NotificationPreference
+	if err := json.NewDecoder(rec.Body).Decode(&fetched); err != nil {
+		t.Fatalf("Failed to decode preferences: %v", err)
+	}
+	if fetched.UserID != user.ID || len(fetched.Events) != 2 || fetched.Timezone != "Europe/London" {
+		t.Errorf("Expected the saved preferences for user %d, got %+v", user.ID, fetched)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, path, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+}
+
+func TestPutNotificationPreferencesValidates(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	user := users.CreateUser(context.Background(), models.User{Username: "bob"})
+	router := NewRouter(NewNotificationPreferenceHandler(store.NewMemoryNotificationPreferenceStore(), users))
+
+	body := `{"channels":["pager","slack"],"events":["execution"],"quietHoursStart":"22:00","quietHoursEnd":"7am"}`
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/users/%d/notification-preferences", user.ID), strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+	var details problem.Details
+	if err := json.NewDecoder(rec.Body).Decode(&details); err != nil {
+		t.Fatalf("Failed to decode problem details: %v", err)
+	}
+	var fields []string
+	for _, e := range details.Errors {
+		fields = append(fields, e.Field)
+	}
+	if strings.Join(fields, ",") != "channels,slackUserId,quietHoursStart" {
+		t.Errorf("Expected channels, slackUserId and quietHoursStart field errors, got %+v", details.Errors)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NotificationTemplate is the subject and body template of a kind of notification
+type NotificationTemplate struct {
+	Kind    string `json:"kind" example:"execution"`
+	Subject string `json:"subject" example:"{{if .Succeeded}}Executed{{else}}Failed{{end}}: {{.Item.Title}}"`
+	Body    string `json:"body" example:"Scheduled item \"{{.Item.Title}}\" ran at {{.Execution.ExecutedAt}}."`
+}
+
+// NotificationTemplatePreviewRequest represents the request body for previewing a notification template
+type NotificationTemplatePreviewRequest struct {
+	Kind string `json:"kind" validate:"required" example:"execution"`
+	// Subject and Body replace the configured templates when set, to try out changes
+	Subject string `json:"subject" example:"Ran {{.Item.Title}}"`
+	Body    string `json:"body" example:"{{.Item.Description}} {{.Links.Item}}"`
+	// ScheduledItemID renders an execution or push notification about this item instead of a sample one
+	ScheduledItemID int64 `json:"scheduledItemId" example:"1"`
+	// Status is the outcome of the execution previewed, success or error
+	Status string `json:"status" example:"success"`
+}
+
+// NotificationTemplatePreview is a rendered notification
+type NotificationTemplatePreview struct {
+	Subject string `json:"subject" example:"Ran Daily standup meeting"`
+	Body    string `json:"body" example:"Team daily standup meeting to discuss progress"`
+}
+
+// NotificationTemplateHandler handles HTTP requests for the templates notifications are rendered with
+type NotificationTemplateHandler struct {
+	templates *notifications.Templates
+	itemStore store.ScheduledItemStore
+}
+
+// NewNotificationTemplateHandler creates a new handler for the given templates, previewing
+// notifications about the items in itemStore
+func NewNotificationTemplateHandler(templates *notifications.Templates, itemStore store.ScheduledItemStore) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templates: templates,
+		itemStore: itemStore,
+	}
+}
+
+// HandleGetNotificationTemplates handles GET requests to list the notification templates
+// @Summary List the notification templates
+// @Description List the Go text/template subject and body of each kind of notification: execution emails and Slack messages, pushed todos and digests. They are the built-in templates unless overridden by files in NOTIFICATIONS_TEMPLATES_DIR; items and users can still set their own execution templates.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} NotificationTemplate
+// @Router /admin/notification-templates [get]
+func (h *NotificationTemplateHandler) HandleGetNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	templates := make([]NotificationTemplate, 0, len(notifications.TemplateKinds))
+	for _, kind := range notifications.TemplateKinds {
+		templates = append(templates, NotificationTemplate{
+			Kind:    kind,
+			Subject: h.templates.Text(kind, "subject"),
+			Body:    h.templates.Text(kind, "body"),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// HandlePreviewNotificationTemplate handles POST requests to render a notification template
+// @Summary Preview a notification template
+// @Description Render a kind of notification with the configured templates, or with the subject and body given to try out changes. Execution and push notifications are about a sample item unless scheduledItemId names one; digests always list sample items.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body NotificationTemplatePreviewRequest true "Kind of notification, optional templates, item and execution outcome"
+// @Success 200 {object} NotificationTemplatePreview
+// @Failure 400 {object} problem.Details "Bad request, or the template doesn't render"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Router /admin/notification-templates/preview [post]
+func (h *NotificationTemplateHandler) HandlePreviewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	var request NotificationTemplatePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if request.Status == "" {
+		request.Status = "success"
+	}
+
+	var errs []problem.FieldError
+	if !slices.Contains(notifications.TemplateKinds, request.Kind) {
+		errs = append(errs, problem.FieldError{Field: "kind", Message: "must be one of " + strings.Join(notifications.TemplateKinds, ", ")})
+	}
+	if request.Status != "success" && request.Status != "error" {
+		errs = append(errs, problem.FieldError{Field: "status", Message: "must be success or error"})
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid template preview", errs...).Write(w, r)
+		return
+	}
+
+	data := h.templates.SampleData(request.Kind)
+	if request.ScheduledItemID != 0 && request.Kind != notifications.TemplateDigest {
+		item, exists := h.itemStore.GetScheduledItem(r.Context(), request.ScheduledItemID)
+		if !exists {
+			problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+			return
+		}
+		execution := models.ExecutionLog{ScheduledItemID: item.ID, ExecutedAt: time.Now().UTC(), Status: request.Status}
+		data = h.templates.Data(item, execution)
+	}
+	if sample, ok := data.(notifications.TemplateData); ok && request.Status == "error" {
+		sample.Execution.Status = "error"
+		sample.Succeeded = false
+		sample.Error = "sample error"
+		data = sample
+	}
+
+	subject, body, err := h.templates.Render(request.Kind, request.Subject, request.Body, data)
+	if err != nil {
+		field := "subject"
+		if strings.Contains(err.Error(), "body template") {
+			field = "body"
+		}
+		problem.Validation("Invalid template", problem.FieldError{Field: field, Message: err.Error()}).Write(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NotificationTemplatePreview{Subject: subject, Body: body})
+}
+
+// RegisterRoutes registers the notification template routes on the given mux
+func (h *NotificationTemplateHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/notification-templates", h.HandleGetNotificationTemplates)
+	mux.HandleFunc("POST /admin/notification-templates/preview", h.HandlePreviewNotificationTemplate)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestNotificationTemplatePreview(t *testing.T) {
+	items := store.NewMemoryScheduledItemStore()
+	item := items.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Water the plants"})
+	templates, err := notifications.LoadTemplates("", "https://periodic.example.com")
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	router := NewRouter(NewNotificationTemplateHandler(templates, items))
+
+	cases := []struct {
+		name    string
+		body    string
+		status  int
+		subject string
+	}{
+		{"configured templates", fmt.Sprintf(`{"kind":"execution","scheduledItemId":%d,"status":"error"}`, item.ID), http.StatusOK, "Failed: Water the plants"},
+		{"given templates", `{"kind":"push","subject":"Todo: {{.Item.Title}}","body":"{{.Links.Executions}}"}`, http.StatusOK, "Todo: Daily standup meeting"},
+		{"digest", `{"kind":"digest"}`, http.StatusOK, "Your schedule until Wed 3 Jan 08:00"},
+		{"unknown kind", `{"kind":"sms"}`, http.StatusBadRequest, ""},
+		{"broken template", `{"kind":"execution","body":"{{.Item.Colour}}"}`, http.StatusBadRequest, ""},
+		{"unknown item", `{"kind":"execution","scheduledItemId":999}`, http.StatusNotFound, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/notification-templates/preview", strings.NewReader(c.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != c.status {
+				t.Fatalf("Expected status %d, got %d: %s", c.status, rec.Code, rec.Body.String())
+			}
+			if c.status != http.StatusOK {
+				return
+			}
+			var preview NotificationTemplatePreview
+			if err := json.NewDecoder(rec.Body).Decode(&preview); err != nil {
+				t.Fatalf("Failed to decode preview: %v", err)
+			}
+			if preview.Subject != c.subject {
+				t.Errorf("Expected subject %q, got %q", c.subject, preview.Subject)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/notification-templates", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var listed []NotificationTemplate
+	if err := json.NewDecoder(rec.Body).Decode(&listed); err != nil {
+		t.Fatalf("Failed to decode templates: %v", err)
+	}
+	if len(listed) != len(notifications.TemplateKinds) || listed[0].Kind != notifications.TemplateExecution || listed[0].Subject == "" {
+		t.Errorf("Expected every kind of template to be listed, got %+v", listed)
+	}
+}
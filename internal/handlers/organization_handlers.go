@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OrganizationHandler handles HTTP requests for organizations and their members
+type OrganizationHandler struct {
+	store     store.OrganizationStore
+	userStore store.UserStore
+	itemStore store.ScheduledItemStore
+	todoStore store.TodoItemStore
+}
+
+// NewOrganizationHandler creates a new handler with the given stores. Organizations are
+// only deleted once none of the items in itemStore or todos in todoStore belong to them.
+func NewOrganizationHandler(store store.OrganizationStore, userStore store.UserStore, itemStore store.ScheduledItemStore, todoStore store.TodoItemStore) *OrganizationHandler {
+	return &OrganizationHandler{
+		store:     store,
+		userStore: userStore,
+		itemStore: itemStore,
+		todoStore: todoStore,
+	}
+}
+
+// organizationID parses the organization ID from the path and checks that the organization
+// exists, writing the problem and returning false otherwise
+func (h *OrganizationHandler) organizationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return 0, false
+	}
+	if _, exists := h.store.GetOrganization(r.Context(), id); !exists {
+		problem.Write(w, r, http.StatusNotFound, "Organization not found")
+		return 0, false
+	}
+	return id, true
+}
+
+// validateOrganization checks an organization from a request
+func validateOrganization(organization models.Organization) []problem.FieldError {
+	if strings.TrimSpace(organization.Name) == "" {
+		return []problem.FieldError{{Field: "name", Message: "is required"}}
+	}
+	return nil
+}
+
+// HandleCreateOrganization handles POST requests to create an organization
+// @Summary Create an organization
+// @Description Create a team, such as a household or an on-call rotation, that scheduled items and todos can belong to
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body models.Organization true "Organization to create"
+// @Success 201 {object} models.Organization
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 500 {object} problem.Details "Failed to create organization"
+// @Router /organizations [post]
+func (h *OrganizationHandler) HandleCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := validateOrganization(organization); len(errs) > 0 {
+		problem.Validation("Invalid organization", errs...).Write(w, r)
+		return
+	}
+
+	created := h.store.CreateOrganization(r.Context(), organization)
+	if created.ID == 0 {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// HandleGetAllOrganizations handles GET requests to list the organizations
+// @Summary Get all organizations
+// @Description Retrieve all organizations in ID order
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} models.Organization
+// @Router /organizations [get]
+func (h *OrganizationHandler) HandleGetAllOrganizations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.GetAllOrganizations(r.Context()))
+}
+
+// HandleGetOrganization handles GET requests to retrieve an organization by ID
+// @Summary Get an organization by ID
+// @Description Get a specific organization by its ID
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) HandleGetOrganization(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	organization, exists := h.store.GetOrganization(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(organization)
+}
+
+// HandleUpdateOrganization handles PUT requests to update an organization
+// @Summary Update an organization
+// @Description Replace the name and description of an organization
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param organization body models.Organization true "Updated organization"
+// @Success 200 {object} models.Organization
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Router /organizations/{id} [put]
+func (h *OrganizationHandler) HandleUpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var organization models.Organization
+	if err := json.NewDecoder(r.Body).Decode(&organization); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := validateOrganization(organization); len(errs) > 0 {
+		problem.Validation("Invalid organization", errs...).Write(w, r)
+		return
+	}
+
+	updated, exists := h.store.UpdateOrganization(r.Context(), id, organization)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// HandleDeleteOrganization handles DELETE requests to remove an organization
+// @Summary Delete an organization
+// @Description Delete an organization along with its memberships. Organizations that scheduled items or todos still belong to can't be deleted; move or delete those first.
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Failure 409 {object} problem.Details "Scheduled items or todos still belong to the organization"
+// @Router /organizations/{id} [delete]
+func (h *OrganizationHandler) HandleDeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	// Items the user making the request can't see still keep the organization
+	scoped := store.WithOrganization(store.WithoutUser(r.Context()), id)
+	if len(h.itemStore.GetAllScheduledItems(scoped)) > 0 || len(h.todoStore.GetAllTodoItems(scoped)) > 0 {
+		problem.Write(w, r, http.StatusConflict, "Scheduled items or todos still belong to the organization")
+		return
+	}
+
+	if !h.store.DeleteOrganization(r.Context(), id) {
+		problem.Write(w, r, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetMembers handles GET requests to list the members of an organization
+// @Summary List an organization's members
+// @Description List the users that belong to an organization and their roles, in the order they joined
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {array} models.OrganizationMember
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Router /organizations/{id}/members [get]
+func (h *OrganizationHandler) HandleGetMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.GetMembers(r.Context(), id))
+}
+
+// HandleSetMember handles PUT requests to add a user to an organization or change their role
+// @Summary Add or update a member
+// @Description Add a user to an organization with the given role, owner or member, or change the role of a user who already belongs to it
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Param member body models.OrganizationMember true "Membership with the user's role"
+// @Success 200 {object} models.OrganizationMember
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Organization or user not found"
+// @Failure 500 {object} problem.Details "Failed to set member"
+// @Router /organizations/{id}/members/{userId} [put]
+func (h *OrganizationHandler) HandleSetMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("userId"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var member models.OrganizationMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if !slices.Contains(models.OrganizationRoles, member.Role) {
+		problem.Validation("Invalid member", problem.FieldError{Field: "role", Message: "must be owner or member"}).Write(w, r)
+		return
+	}
+	if _, exists := h.userStore.GetUser(r.Context(), userID); !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	member.OrganizationID = id
+	member.UserID = userID
+	saved, ok := h.store.SetMember(r.Context(), member)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to set member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// HandleRemoveMember handles DELETE requests to remove a user from an organization
+// @Summary Remove a member
+// @Description Remove a user from an organization
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Organization or member not found"
+// @Router /organizations/{id}/members/{userId} [delete]
+func (h *OrganizationHandler) HandleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("userId"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if !h.store.RemoveMember(r.Context(), id, userID) {
+		problem.Write(w, r, http.StatusNotFound, "Member not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegisterRoutes registers the organization routes on the given mux
+func (h *OrganizationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /organizations", h.HandleGetAllOrganizations)
+	mux.HandleFunc("POST /organizations", h.HandleCreateOrganization)
+	mux.HandleFunc("GET /organizations/{id}", h.HandleGetOrganization)
+	mux.HandleFunc("PUT /organizations/{id}", h.HandleUpdateOrganization)
+	mux.HandleFunc("DELETE /organizations/{id}", h.HandleDeleteOrganization)
+	mux.HandleFunc("GET /organizations/{id}/members", h.HandleGetMembers)
+	mux.HandleFunc("PUT /organizations/{id}/members/{userId}", h.HandleSetMember)
+	mux.HandleFunc("DELETE /organizations/{id}/members/{userId}", h.HandleRemoveMember)
+	mux.HandleFunc("GET /organizations/{id}/invitations", h.HandleGetInvitations)
+	mux.HandleFunc("POST /organizations/{id}/invitations", h.HandleCreateInvitation)
+	mux.HandleFunc("DELETE /organizations/{id}/invitations/{invitationId}", h.HandleRevokeInvitation)
+	mux.HandleFunc("POST /invitations/accept", h.HandleAcceptInvitation)
+}
+
+// organizationScope returns the request's context scoped to the organization in its
+// organizationId query parameter, if any, so store listings only include the items or
+// todos of that organization. It writes the problem and returns false when the ID is invalid.
+func organizationScope(w http.ResponseWriter, r *http.Request) (context.Context, bool) {
+	raw := r.URL.Query().Get("organizationId")
+	if raw == "" {
+		return r.Context(), true
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "organizationId", Message: "must be a positive integer"}).Write(w, r)
+		return nil, false
+	}
+	return store.WithOrganization(r.Context(), id), true
+}
+
+// organizationErrors checks that the organization an item or todo is assigned to exists.
+// Nothing is checked without an organization store.
+func organizationErrors(ctx context.Context, organizations store.OrganizationStore, organizationID *int64) []problem.FieldError {
+	if organizations == nil || organizationID == nil {
+		return nil
+	}
+	if _, exists := organizations.GetOrganization(ctx, *organizationID); !exists {
+		return []problem.FieldError{{Field: "organizationId", Message: "organization not found"}}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrganizationsShareTodosBetweenMembers(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	alice := users.CreateUser(context.Background(), models.User{Username: "alice"})
+	organizations := store.NewMemoryOrganizationStore()
+	items := store.NewMemoryScheduledItemStore()
+	todos := store.NewMemoryTodoItemStore()
+	todoHandler := NewTodoItemHandler(todos)
+	todoHandler.EnableOrganizations(organizations)
+	router := NewRouter(NewOrganizationHandler(organizations, users, items, todos), todoHandler)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/organizations", `{"name":" "}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 without a name, got %d", rec.Code)
+	}
+	rec := serve(http.MethodPost, "/organizations", `{"name":"Household"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var organization models.Organization
+	if err := json.NewDecoder(rec.Body).Decode(&organization); err != nil {
+		t.Fatalf("Failed to decode organization: %v", err)
+	}
+	path := fmt.Sprintf("/organizations/%d", organization.ID)
+
+	// Members are added with a role, which can then be changed
+	membersPath := fmt.Sprintf("%s/members/%d", path, alice.ID)
+	if rec := serve(http.MethodPut, membersPath, `{"role":"admin"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an unknown role, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodPut, path+"/members/999", `{"role":"member"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 for an unknown user, got %d", rec.Code)
+	}
+	for _, role := range []string{"member", "owner"} {
+		if rec := serve(http.MethodPut, membersPath, `{"role":"`+role+`"}`); rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	var members []models.OrganizationMember
+	if err := json.NewDecoder(serve(http.MethodGet, path+"/members", "").Body).Decode(&members); err != nil {
+		t.Fatalf("Failed to decode members: %v", err)
+	}
+	if len(members) != 1 || members[0].UserID != alice.ID || members[0].Role != models.OrganizationRoleOwner {
+		t.Fatalf("Expected alice to be the only owner, got %+v", members)
+	}
+
+	// Todos can only belong to organizations that exist, and are listed by organization
+	if rec := serve(http.MethodPost, "/todo-items", `{"text":"Buy milk","organizationId":999}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an unknown organization, got %d", rec.Code)
+	}
+	body := fmt.Sprintf(`{"text":"Buy milk","organizationId":%d}`, organization.ID)
+	if rec := serve(http.MethodPost, "/todo-items", body); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	serve(http.MethodPost, "/todo-items", `{"text":"Call mum"}`)
+
+	var scoped []models.TodoItem
+	if err := json.NewDecoder(serve(http.MethodGet, fmt.Sprintf("/todo-items?organizationId=%d", organization.ID), "").Body).Decode(&scoped); err != nil {
+		t.Fatalf("Failed to decode todo items: %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].Text != "Buy milk" {
+		t.Fatalf("Expected only the organization's todo, got %+v", scoped)
+	}
+	if rec := serve(http.MethodGet, "/todo-items?organizationId=abc", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid organization ID, got %d", rec.Code)
+	}
+
+	// Organizations that still own todos can't be deleted
+	if rec := serve(http.MethodDelete, path, ""); rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", rec.Code)
+	}
+	todos.DeleteTodoItem(context.Background(), scoped[0].ID)
+	if rec := serve(http.MethodDelete, path, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodGet, path+"/members", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after deleting the organization, got %d", rec.Code)
+	}
+}
+
+func TestInvitationsAddMembersOnAcceptance(t *testing.T) {
+	ctx := context.Background()
+	users := store.NewMemoryUserStore()
+	alice := users.CreateUser(ctx, models.User{Username: "alice", Email: "alice@example.com"})
+	bob := users.CreateUser(ctx, models.User{Username: "bob", Email: "Bob@Example.com"})
+	organizations := store.NewMemoryOrganizationStore()
+	organization := organizations.CreateOrganization(ctx, models.Organization{Name: "Household"})
+	organizations.SetMember(ctx, models.OrganizationMember{OrganizationID: organization.ID, UserID: alice.ID, Role: models.OrganizationRoleOwner})
+	router := NewRouter(NewOrganizationHandler(organizations, users, store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore()))
+
+	serve := func(userID int64, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if userID != 0 {
+			req.Header.Set("X-User-ID", fmt.Sprint(userID))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	path := fmt.Sprintf("/organizations/%d/invitations", organization.ID)
+
+	// Only owners invite, and invitations need an email address and a role
+	if rec := serve(bob.ID, http.MethodPost, path, `{"email":"bob@example.com","role":"member"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 for a user who isn't an owner, got %d", rec.Code)
+	}
+	if rec := serve(alice.ID, http.MethodPost, path, `{"email":"bob","role":"admin"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an invalid invitation, got %d", rec.Code)
+	}
+	rec := serve(alice.ID, http.MethodPost, path, `{"email":"bob@example.com","role":"member"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var invitation models.OrganizationInvitation
+	if err := json.NewDecoder(rec.Body).Decode(&invitation); err != nil {
+		t.Fatalf("Failed to decode invitation: %v", err)
+	}
+	if invitation.Token == "" || time.Until(invitation.ExpiresAt) < 6*24*time.Hour {
+		t.Fatalf("Expected a token valid for a week, got %+v", invitation)
+	}
+
+	// Tokens are only returned when invitations are created
+	var listed []models.OrganizationInvitation
+	if err := json.NewDecoder(serve(alice.ID, http.MethodGet, path, "").Body).Decode(&listed); err != nil {
+		t.Fatalf("Failed to decode invitations: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != invitation.ID || listed[0].Token != "" {
+		t.Fatalf("Expected the invitation without its token, got %+v", listed)
+	}
+
+	accept := `{"token":"` + invitation.Token + `"}`
+	if rec := serve(alice.ID, http.MethodPost, "/invitations/accept", accept); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 for another user's invitation, got %d", rec.Code)
+	}
+	if rec := serve(bob.ID, http.MethodPost, "/invitations/accept", `{"token":"unknown"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 for an unknown token, got %d", rec.Code)
+	}
+	if rec := serve(bob.ID, http.MethodPost, "/invitations/accept", accept); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(bob.ID, http.MethodPost, "/invitations/accept", accept); rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409 for an accepted invitation, got %d", rec.Code)
+	}
+	members := organizations.GetMembers(ctx, organization.ID)
+	if len(members) != 2 || members[1].UserID != bob.ID || members[1].Role != models.OrganizationRoleMember {
+		t.Fatalf("Expected bob to have joined as a member, got %+v", members)
+	}
+
+	// Expired and revoked invitations can't be accepted
+	expired, _ := organizations.CreateInvitation(ctx, models.OrganizationInvitation{
+		OrganizationID: organization.ID, Email: "bob@example.com", Role: models.OrganizationRoleOwner,
+		Token: "expired", ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if rec := serve(bob.ID, http.MethodPost, "/invitations/accept", `{"token":"expired"}`); rec.Code != http.StatusGone {
+		t.Fatalf("Expected status 410 for an expired invitation, got %d", rec.Code)
+	}
+	if rec := serve(alice.ID, http.MethodDelete, fmt.Sprintf("%s/%d", path, expired.ID), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(bob.ID, http.MethodPost, "/invitations/accept", `{"token":"expired"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 for a revoked invitation, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultInvitationLifetime is how long invitations can be accepted for when they don't say
+const defaultInvitationLifetime = 7 * 24 * time.Hour
+
+// AcceptInvitationRequest represents the request body for accepting an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required" example:"6f1c0b9e..."`
+}
+
+// generateInvitationToken returns a random token for a new invitation
+func generateInvitationToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// authorizeOwner checks that the user making a request, if it names one, is an owner of
+// the organization, writing the problem and returning false otherwise. Until requests are
+// authenticated, requests that don't name a user may manage any organization.
+func (h *OrganizationHandler) authorizeOwner(w http.ResponseWriter, r *http.Request, organizationID int64) bool {
+	userID, ok := requestUser(r)
+	if !ok {
+		return true
+	}
+	for _, member := range h.store.GetMembers(r.Context(), organizationID) {
+		if member.UserID == userID && member.Role == models.OrganizationRoleOwner {
+			return true
+		}
+	}
+	problem.Write(w, r, http.StatusForbidden, "Only owners can manage the organization's invitations")
+	return false
+}
+
+// validateInvitation checks an invitation from a request
+func validateInvitation(invitation models.OrganizationInvitation, now time.Time) []problem.FieldError {
+	var errs []problem.FieldError
+	if _, err := mail.ParseAddress(invitation.Email); err != nil || strings.TrimSpace(invitation.Email) == "" {
+		errs = append(errs, problem.FieldError{Field: "email", Message: "must be an email address"})
+	}
+	if !slices.Contains(models.OrganizationRoles, invitation.Role) {
+		errs = append(errs, problem.FieldError{Field: "role", Message: "must be owner or member"})
+	}
+	if !invitation.ExpiresAt.IsZero() && !invitation.ExpiresAt.After(now) {
+		errs = append(errs, problem.FieldError{Field: "expiresAt", Message: "must be in the future"})
+	}
+	return errs
+}
+
+// HandleCreateInvitation handles POST requests to invite a user to an organization
+// @Summary Invite a user to an organization
+// @Description Invite whoever owns an email address to join an organization with a role. The response holds the invitation's token, which is only returned once; send it to the invited user so they can accept it. Invitations expire after 7 days unless expiresAt says otherwise. When the request names a user in the X-User-ID header, they must be an owner of the organization.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Param invitation body models.OrganizationInvitation true "Invitation with the email, role and optional expiry"
+// @Success 201 {object} models.OrganizationInvitation
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Only owners can manage the organization's invitations"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Failure 500 {object} problem.Details "Failed to create invitation"
+// @Router /organizations/{id}/invitations [post]
+func (h *OrganizationHandler) HandleCreateInvitation(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeOwner(w, r, id) {
+		return
+	}
+
+	var invitation models.OrganizationInvitation
+	if err := json.NewDecoder(r.Body).Decode(&invitation); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	now := time.Now()
+	if errs := validateInvitation(invitation, now); len(errs) > 0 {
+		problem.Validation("Invalid invitation", errs...).Write(w, r)
+		return
+	}
+
+	token, err := generateInvitationToken()
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to create invitation")
+		return
+	}
+	invitation.OrganizationID = id
+	invitation.Email = strings.TrimSpace(invitation.Email)
+	invitation.Token = token
+	if invitation.ExpiresAt.IsZero() {
+		invitation.ExpiresAt = now.Add(defaultInvitationLifetime)
+	}
+
+	created, ok := h.store.CreateInvitation(r.Context(), invitation)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to create invitation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// HandleGetInvitations handles GET requests to list the invitations to an organization
+// @Summary List an organization's invitations
+// @Description List the invitations to an organization, accepted or not, in the order they were created. Tokens aren't included.
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {array} models.OrganizationInvitation
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only owners can manage the organization's invitations"
+// @Failure 404 {object} problem.Details "Organization not found"
+// @Router /organizations/{id}/invitations [get]
+func (h *OrganizationHandler) HandleGetInvitations(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+	if !h.authorizeOwner(w, r, id) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.GetInvitations(r.Context(), id))
+}
+
+// HandleRevokeInvitation handles DELETE requests to revoke an invitation
+// @Summary Revoke an invitation
+// @Description Delete an invitation so its token can no longer be accepted. Users who already accepted it stay members.
+// @Tags organizations
+// @Param id path int true "Organization ID"
+// @Param invitationId path int true "Invitation ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only owners can manage the organization's invitations"
+// @Failure 404 {object} problem.Details "Organization or invitation not found"
+// @Router /organizations/{id}/invitations/{invitationId} [delete]
+func (h *OrganizationHandler) HandleRevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.organizationID(w, r)
+	if !ok {
+		return
+	}
+	invitationID, err := strconv.ParseInt(r.PathValue("invitationId"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+	if !h.authorizeOwner(w, r, id) {
+		return
+	}
+
+	if !h.store.DeleteInvitation(r.Context(), id, invitationID) {
+		problem.Write(w, r, http.StatusNotFound, "Invitation not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAcceptInvitation handles POST requests to accept an invitation
+// @Summary Accept an invitation
+// @Description Join the organization an invitation is for, with the invitation's role. The user accepting it is named by the X-User-ID header and must have the email address the invitation was sent to. Each invitation can be accepted once.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "ID of the user accepting the invitation"
+// @Param invitation body AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} models.OrganizationMember
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "The invitation was sent to another email address"
+// @Failure 404 {object} problem.Details "Invitation not found"
+// @Failure 409 {object} problem.Details "Invitation already accepted"
+// @Failure 410 {object} problem.Details "Invitation expired"
+// @Failure 500 {object} problem.Details "Failed to add member"
+// @Router /invitations/accept [post]
+func (h *OrganizationHandler) HandleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestUser(r)
+	if !ok {
+		problem.Write(w, r, http.StatusBadRequest, "The X-User-ID header must name the user accepting the invitation")
+		return
+	}
+	var request AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(request.Token) == "" {
+		problem.Validation("Invalid invitation", problem.FieldError{Field: "token", Message: "is required"}).Write(w, r)
+		return
+	}
+
+	invitation, exists := h.store.GetInvitationByToken(r.Context(), strings.TrimSpace(request.Token))
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Invitation not found")
+		return
+	}
+	user, exists := h.userStore.GetUser(r.Context(), userID)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(user.Email), invitation.Email) {
+		problem.Write(w, r, http.StatusForbidden, "The invitation was sent to another email address")
+		return
+	}
+	if invitation.AcceptedAt != nil {
+		problem.Write(w, r, http.StatusConflict, "Invitation already accepted")
+		return
+	}
+	now := time.Now()
+	if !now.Before(invitation.ExpiresAt) {
+		problem.Write(w, r, http.StatusGone, "Invitation expired")
+		return
+	}
+
+	// Claiming the invitation first means concurrent acceptances add the member once
+	if !h.store.AcceptInvitation(r.Context(), invitation.OrganizationID, invitation.ID, now) {
+		problem.Write(w, r, http.StatusConflict, "Invitation already accepted")
+		return
+	}
+	member, ok := h.store.SetMember(r.Context(), models.OrganizationMember{
+		OrganizationID: invitation.OrganizationID,
+		UserID:         userID,
+		Role:           invitation.Role,
+	})
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to add member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
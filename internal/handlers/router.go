@@ -0,0 +1,52 @@
+package handlers
+
+import "net/http"
+
+// RouteRegistrar registers a group of related routes on a mux
+type RouteRegistrar interface {
+	RegisterRoutes(mux *http.ServeMux)
+}
+
+// RouteFunc adapts a function to a RouteRegistrar, for routes that don't belong to a handler
+type RouteFunc func(mux *http.ServeMux)
+
+// RegisterRoutes calls f(mux)
+func (f RouteFunc) RegisterRoutes(mux *http.ServeMux) {
+	f(mux)
+}
+
+// NewRouter creates the HTTP handler serving the routes of each registrar. Routes are
+// matched by method and path, so unsupported methods get 405 Method Not Allowed and
+// path parameters such as {id} are available through r.PathValue. Routes aren't scoped to
+// a user: API routes are wrapped in ScopeToUser once the request's token has been checked.
+func NewRouter(registrars ...RouteRegistrar) http.Handler {
+	mux := http.NewServeMux()
+	for _, registrar := range registrars {
+		registrar.RegisterRoutes(mux)
+	}
+	return mux
+}
+
+// APIPrefix is the path prefix of the current API version
+const APIPrefix = "/api/v1"
+
+// Mount serves handler under the given path prefix, which is stripped before the handler
+// sees the request
+func Mount(prefix string, handler http.Handler) RouteRegistrar {
+	return RouteFunc(func(mux *http.ServeMux) {
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, handler))
+	})
+}
+
+// DeprecatedAlias serves handler at the unversioned paths used before the API was
+// versioned. Responses carry a Deprecation header and a Link to the versioned path so
+// clients can migrate before the aliases are removed.
+func DeprecatedAlias(handler http.Handler) RouteRegistrar {
+	return RouteFunc(func(mux *http.ServeMux) {
+		mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", "<"+APIPrefix+r.URL.Path+`>; rel="successor-version"`)
+			handler.ServeHTTP(w, r)
+		}))
+	})
+}
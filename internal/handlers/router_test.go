@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"testing"
+)
+
+func newTestRouter() http.Handler {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	executionLogStore := store.NewMemoryExecutionLogStore()
+	service := scheduler.NewService(itemStore, todoStore, executionLogStore)
+
+	return NewRouter(
+		NewScheduledItemHandler(itemStore, service),
+		NewTodoItemHandler(todoStore),
+		NewUserHandler(store.NewMemoryUserStore()),
+		NewExecutionLogHandler(executionLogStore),
+	)
+}
+
+func TestRouterRoutesByMethodAndPath(t *testing.T) {
+	router := newTestRouter()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{http.MethodGet, "/scheduled-items", http.StatusOK},
+		{http.MethodGet, "/scheduled-items/next", http.StatusOK},
+		{http.MethodGet, "/scheduled-items/abc", http.StatusBadRequest},
+		{http.MethodGet, "/scheduled-items/42", http.StatusNotFound},
+		{http.MethodPost, "/scheduled-items/42/run", http.StatusNotFound},
+		{http.MethodGet, "/scheduled-items/42/run", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/todo-items/1", http.StatusMethodNotAllowed},
+		{http.MethodPatch, "/todo-items/999", http.StatusNotFound},
+		{http.MethodGet, "/users/1/extra", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, rec.Code)
+		}
+	}
+}
+
+func TestVersionedRoutesAndDeprecatedAliases(t *testing.T) {
+	api := newTestRouter()
+	router := NewRouter(Mount(APIPrefix, api), DeprecatedAlias(api))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/scheduled-items", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected versioned route to return 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Deprecation") != "" {
+		t.Errorf("Versioned route should not be marked deprecated")
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduled-items", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected deprecated alias to return 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Deprecation") != "true" {
+		t.Errorf("Expected deprecated alias to set the Deprecation header")
+	}
+	if got := rec.Header().Get("Link"); got != `</api/v1/scheduled-items>; rel="successor-version"` {
+		t.Errorf("Unexpected Link header %q", got)
+	}
+}
@@ -1,37 +1,137 @@
 package handlers
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/middleware"
 	"periodic-api/internal/models"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/openapi"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/scheduler"
 	"periodic-api/internal/store"
 	"periodic-api/internal/utils"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// scheduledItemSortFields are the fields scheduled items can be listed by
+var scheduledItemSortFields = map[string]func(a, b models.ScheduledItem) int{
+	"id":              func(a, b models.ScheduledItem) int { return cmp.Compare(a.ID, b.ID) },
+	"createdAt":       func(a, b models.ScheduledItem) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt":       func(a, b models.ScheduledItem) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"nextExecutionAt": func(a, b models.ScheduledItem) int { return a.NextExecutionAt.Compare(b.NextExecutionAt) },
+}
+
+// scheduledItemCSVColumns are the columns of scheduled items listed as CSV
+var scheduledItemCSVColumns = []csvColumn[models.ScheduledItem]{
+	{"id", func(item models.ScheduledItem) string { return strconv.FormatInt(item.ID, 10) }},
+	{"title", func(item models.ScheduledItem) string { return item.Title }},
+	{"description", func(item models.ScheduledItem) string { return item.Description }},
+	{"startsAt", func(item models.ScheduledItem) string { return csvTime(item.StartsAt) }},
+	{"repeats", func(item models.ScheduledItem) string { return strconv.FormatBool(item.Repeats) }},
+	{"cronExpression", func(item models.ScheduledItem) string { return csvString(item.CronExpression) }},
+	{"expiration", func(item models.ScheduledItem) string { return csvOptional(item.Expiration, csvTime) }},
+	{"nextExecutionAt", func(item models.ScheduledItem) string { return csvTime(item.NextExecutionAt) }},
+	{"actionType", func(item models.ScheduledItem) string { return item.ActionType }},
+	{"actionConfig", func(item models.ScheduledItem) string { return string(item.ActionConfig) }},
+	{"jitterSeconds", func(item models.ScheduledItem) string { return strconv.Itoa(item.JitterSeconds) }},
+	{"paused", func(item models.ScheduledItem) string { return strconv.FormatBool(item.Paused) }},
+	{"organizationId", func(item models.ScheduledItem) string { return csvOptional(item.OrganizationID, formatID) }},
+	{"ownerId", func(item models.ScheduledItem) string { return csvOptional(item.OwnerID, formatID) }},
+	{"version", func(item models.ScheduledItem) string { return strconv.FormatInt(item.Version, 10) }},
+	{"createdAt", func(item models.ScheduledItem) string { return csvTime(item.CreatedAt) }},
+	{"updatedAt", func(item models.ScheduledItem) string { return csvTime(item.UpdatedAt) }},
+}
+
 // ScheduledItemHandler handles HTTP requests for scheduled items
 type ScheduledItemHandler struct {
-	store     store.ScheduledItemStore
-	awsClient *utils.AWSLLMClient
+	store   store.ScheduledItemStore
+	service *scheduler.Service
+	llm     utils.LLMProvider
+	// schemas validates the items and patches the LLM generates
+	schemas *openapi.Validator
+	// usage records every generation request and enforces quota; nil disables accounting
+	usage store.LLMUsageStore
+	// quotaMu guards quota, which SetLLMQuota changes while requests are served
+	quotaMu sync.RWMutex
+	quota   LLMQuota
+	// sessions holds the conversations refining generated items; nil disables them
+	sessions store.GenerationSessionStore
+	// organizations checks the organizations items are assigned to; nil skips the check
+	organizations store.OrganizationStore
+	// shares grants users access to the items of others; nil leaves every item open to everyone
+	shares store.ItemShareStore
+	users  store.UserStore
+	// limits caps the items and daily generation requests of each tenant; nil leaves them unlimited
+	limits *TenantLimitHandler
 }
 
-// NewScheduledItemHandler creates a new handler with the given store
-func NewScheduledItemHandler(store store.ScheduledItemStore) *ScheduledItemHandler {
-	// Initialize AWS client
-	awsClient, err := utils.NewAWSLLMClient(context.Background())
-	if err != nil {
-		// Log error but don't fail - the endpoint will return errors if AWS is not configured
-		awsClient = nil
-	}
-
+// NewScheduledItemHandler creates a new handler with the given store and scheduler service.
+// Generation from prompts is unavailable until EnableGeneration is called.
+func NewScheduledItemHandler(store store.ScheduledItemStore, service *scheduler.Service) *ScheduledItemHandler {
 	return &ScheduledItemHandler{
-		store:     store,
-		awsClient: awsClient,
+		store:   store,
+		service: service,
 	}
 }
 
+// EnableOrganizations checks that the organizations items are assigned to exist in organizations
+func (h *ScheduledItemHandler) EnableOrganizations(organizations store.OrganizationStore) {
+	h.organizations = organizations
+}
+
+// EnableSharing makes the items created by a user identified by X-User-ID theirs, visible
+// to others only once shared with them in shares. Users are looked up in users.
+func (h *ScheduledItemHandler) EnableSharing(shares store.ItemShareStore, users store.UserStore) {
+	h.shares = shares
+	h.users = users
+}
+
+// EnableGeneration makes POST /generate-scheduled-item generate items with the given LLM
+// provider. Its output is checked against the ScheduledItem schema in schemas.
+func (h *ScheduledItemHandler) EnableGeneration(llm utils.LLMProvider, schemas *openapi.Validator) {
+	h.llm = llm
+	h.schemas = schemas
+}
+
+// EnableUsageAccounting records the LLM usage of every generation request in usage and
+// rejects the requests of users who have used up their daily quota
+func (h *ScheduledItemHandler) EnableUsageAccounting(usage store.LLMUsageStore, quota LLMQuota) {
+	h.usage = usage
+	h.SetLLMQuota(quota)
+}
+
+// SetLLMQuota changes the daily quotas, e.g. when the configuration is reloaded
+func (h *ScheduledItemHandler) SetLLMQuota(quota LLMQuota) {
+	h.quotaMu.Lock()
+	defer h.quotaMu.Unlock()
+
+	h.quota = quota
+}
+
+// EnableTenantLimits holds each tenant to its limits in limits on the scheduled items it
+// may have and, with usage accounting enabled, the generation requests it may make per day
+func (h *ScheduledItemHandler) EnableTenantLimits(limits *TenantLimitHandler) {
+	h.limits = limits
+}
+
+// EnableGenerationSessions makes the /generation-sessions endpoints keep their
+// conversations in sessions. Generation must be enabled too.
+func (h *ScheduledItemHandler) EnableGenerationSessions(sessions store.GenerationSessionStore) {
+	h.sessions = sessions
+}
+
 // HandleCreateScheduledItem handles POST requests to create a new scheduled item
 // @Summary Create a scheduled item
 // @Description Create a new scheduled item with the given details
@@ -39,46 +139,239 @@ func NewScheduledItemHandler(store store.ScheduledItemStore) *ScheduledItemHandl
 // @Accept json
 // @Produce json
 // @Param item body models.ScheduledItem true "Scheduled item to create"
+// @Param X-User-ID header string false "ID of the user making the request, who owns the item"
 // @Success 201 {object} models.ScheduledItem
-// @Failure 400 {string} string "Bad request"
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Tenant limit of scheduled items reached"
 // @Router /scheduled-items [post]
 func (h *ScheduledItemHandler) HandleCreateScheduledItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	var item models.ScheduledItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	errs := h.prepareScheduledItem(&item)
+	errs = append(errs, organizationErrors(r.Context(), h.organizations, item.OrganizationID)...)
+	if len(errs) > 0 {
+		problem.Validation("Invalid scheduled item", errs...).Write(w, r)
+		return
+	}
+	if !h.allowNewScheduledItem(w, r) {
+		return
+	}
+
+	createdItem := h.createScheduledItem(r, item)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdItem)
+}
+
+// allowNewScheduledItem reports whether the tenant making a request may create another
+// scheduled item, writing a 403 problem if it already has as many as its limit allows
+func (h *ScheduledItemHandler) allowNewScheduledItem(w http.ResponseWriter, r *http.Request) bool {
+	if h.limits == nil {
+		return true
+	}
+	limit := h.limits.requestLimits(r).MaxScheduledItems
+	if limit == 0 || len(h.store.GetAllScheduledItems(store.WithoutUser(r.Context()))) < limit {
+		return true
+	}
+	writeLimitExceeded(w, r, limit, "scheduled items")
+	return false
+}
+
+// createScheduledItem stores a prepared item, owned by the user making the request if
+// sharing is enabled, and wakes an in-process scheduler in case the item is due before
+// its next tick
+func (h *ScheduledItemHandler) createScheduledItem(r *http.Request, item models.ScheduledItem) models.ScheduledItem {
+	item.RequestID = middleware.RequestIDFromContext(r.Context())
+	item.OwnerID = h.requestOwner(r)
+	createdItem := h.store.CreateScheduledItem(r.Context(), item)
+	h.service.NotifyNextExecution(createdItem.NextExecutionAt)
+	return createdItem
+}
+
+// updateScheduledItem stores a changed item, stamped with the ID of the request that
+// changed it so its executions can be traced back to that request
+func (h *ScheduledItemHandler) updateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	item.RequestID = middleware.RequestIDFromContext(ctx)
+	return h.store.UpdateScheduledItem(ctx, id, item)
+}
+
+// HandleUpdateScheduledItem handles PUT requests to update a scheduled item
+// @Summary Update a scheduled item
+// @Description Replace a scheduled item by its ID. The request must include the version it was based on; if the item has changed since, the update is rejected with 409 Conflict.
+// @Tags scheduled-items
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param item body models.ScheduledItem true "Updated scheduled item"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {object} models.ScheduledItem
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Scheduled item is only shared with the user as a viewer"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Failure 409 {object} problem.Details "Scheduled item was modified concurrently"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Router /scheduled-items/{id} [put]
+func (h *ScheduledItemHandler) HandleUpdateScheduledItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	var item models.ScheduledItem
 	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
-	// Calculate next execution time
-	nextExec := utils.CalculateNextExecution(
-		item.StartsAt,
-		item.Repeats,
-		item.CronExpression,
-		item.Expiration,
-	)
-	if nextExec == nil {
-		// Invalid scheduled item - cannot determine next execution time
-		// This could be due to:
-		// - Non-repeating item scheduled in the past
-		// - Repeating item without valid cron expression
-		// - Item already expired
-		// - Invalid cron expression format
-		http.Error(w, "Invalid scheduled item: cannot determine next execution time", http.StatusBadRequest)
+	errs := h.prepareScheduledItem(&item)
+	errs = append(errs, organizationErrors(r.Context(), h.organizations, item.OrganizationID)...)
+	if item.Version <= 0 {
+		errs = append(errs, problem.FieldError{Field: "version", Message: "is required"})
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid scheduled item", errs...).Write(w, r)
+		return
+	}
+	if !h.authorizeID(w, r, id, store.ItemAccessEdit) {
 		return
 	}
-	
-	item.NextExecutionAt = *nextExec
 
-	createdItem := h.store.CreateScheduledItem(item)
+	updatedItem, err := h.updateScheduledItem(r.Context(), id, item)
+	if err != nil {
+		writeScheduledItemUpdateError(w, r, err)
+		return
+	}
+
+	// Wake an in-process scheduler in case the item is now due before its next tick
+	h.service.NotifyNextExecution(updatedItem.NextExecutionAt)
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(createdItem)
+	json.NewEncoder(w).Encode(updatedItem)
+}
+
+// HandlePatchScheduledItem handles PATCH requests to partially update a scheduled item
+// @Summary Partially update a scheduled item
+// @Description Apply a JSON Merge Patch (RFC 7386) to a scheduled item: only the fields in the patch change, and null clears a field. The next execution time is recalculated when startsAt, repeats, cronExpression, expiration or jitterSeconds change. Include version to reject the patch with 409 Conflict if the item has changed since it was read.
+// @Tags scheduled-items
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param patch body object true "JSON Merge Patch of scheduled item fields"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {object} models.ScheduledItem
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Scheduled item is only shared with the user as a viewer"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Failure 409 {object} problem.Details "Scheduled item was modified concurrently"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Router /scheduled-items/{id} [patch]
+func (h *ScheduledItemHandler) HandlePatchScheduledItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	existing, exists := h.store.GetScheduledItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return
+	}
+	if !h.authorize(w, r, existing, store.ItemAccessEdit) {
+		return
+	}
+
+	item, errs, err := h.patchScheduledItem(existing, patch)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid merge patch: "+err.Error())
+		return
+	}
+	errs = append(errs, organizationErrors(r.Context(), h.organizations, item.OrganizationID)...)
+	if len(errs) > 0 {
+		problem.Validation("Invalid scheduled item", errs...).Write(w, r)
+		return
+	}
+
+	updatedItem, err := h.updateScheduledItem(r.Context(), id, item)
+	if err != nil {
+		writeScheduledItemUpdateError(w, r, err)
+		return
+	}
+
+	// Wake an in-process scheduler in case the item is now due before its next tick
+	h.service.NotifyNextExecution(updatedItem.NextExecutionAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedItem)
+}
+
+// patchScheduledItem applies a merge patch to an existing item and validates the result.
+// It returns an error if the patch can't be applied, or the invalid fields, if any.
+func (h *ScheduledItemHandler) patchScheduledItem(existing models.ScheduledItem, patch []byte) (models.ScheduledItem, []problem.FieldError, error) {
+	item := existing
+	if err := applyMergePatch(&item, patch); err != nil {
+		return item, nil, err
+	}
+
+	// Fields managed by the server can't be patched
+	item.ID = existing.ID
+	item.NextExecutionAt = existing.NextExecutionAt
+	item.CreatedAt = existing.CreatedAt
+	item.UpdatedAt = existing.UpdatedAt
+	item.RequestID = existing.RequestID
+
+	// Only recalculate the next execution when the schedule changes, so patching the
+	// title of an item that is due doesn't skip or reject its pending execution
+	if scheduleChanged(existing, item) {
+		return item, h.prepareScheduledItem(&item), nil
+	}
+	if err := h.service.ValidateAction(item); err != nil {
+		return item, []problem.FieldError{{Field: "actionConfig", Message: err.Error()}}, nil
+	}
+	return item, nil, nil
+}
+
+// scheduleChanged reports whether any field that determines the next execution differs
+func scheduleChanged(a, b models.ScheduledItem) bool {
+	return !a.StartsAt.Equal(b.StartsAt) ||
+		a.Repeats != b.Repeats ||
+		!equalPtr(a.CronExpression, b.CronExpression, func(x, y string) bool { return x == y }) ||
+		!equalPtr(a.Expiration, b.Expiration, time.Time.Equal) ||
+		a.JitterSeconds != b.JitterSeconds
+}
+
+// equalPtr reports whether two optional values are both unset or both set and equal
+func equalPtr[T any](a, b *T, equal func(x, y T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equal(*a, *b)
+}
+
+// writeScheduledItemUpdateError maps an error from updating a scheduled item to a response
+func writeScheduledItemUpdateError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+	case errors.Is(err, store.ErrVersionConflict):
+		conflict := problem.New(http.StatusConflict, "Scheduled item was modified concurrently, reload it and try again")
+		conflict.Type = problem.TypeVersionConflict
+		conflict.Write(w, r)
+	default:
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to update scheduled item: "+err.Error())
+	}
 }
 
 // HandleGetScheduledItem handles GET requests to retrieve a scheduled item by ID
@@ -87,26 +380,24 @@ func (h *ScheduledItemHandler) HandleCreateScheduledItem(w http.ResponseWriter,
 // @Tags scheduled-items
 // @Produce json
 // @Param id path int true "Scheduled item ID"
+// @Param X-User-ID header string false "ID of the user making the request"
 // @Success 200 {object} models.ScheduledItem
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Scheduled item not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
 // @Router /scheduled-items/{id} [get]
 func (h *ScheduledItemHandler) HandleGetScheduledItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/scheduled-items/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	item, exists := h.store.GetScheduledItem(id)
+	item, exists := h.store.GetScheduledItem(r.Context(), id)
 	if !exists {
-		http.Error(w, "Scheduled item not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return
+	}
+	if !h.authorize(w, r, item, store.ItemAccessView) {
 		return
 	}
 
@@ -116,18 +407,47 @@ func (h *ScheduledItemHandler) HandleGetScheduledItem(w http.ResponseWriter, r *
 
 // HandleGetAllScheduledItems handles GET requests to retrieve all scheduled items
 // @Summary Get all scheduled items
-// @Description Retrieve all scheduled items from the store
+// @Description Retrieve all scheduled items from the store, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per item. With the cursor parameter, the items are returned a page at a time in next execution order, ties broken by ID, and the cursor of the next page is sent in the X-Next-Cursor header. Pages leave out the items the user can't see, so they can be shorter than the limit before the last one.
 // @Tags scheduled-items
-// @Produce json
+// @Produce json,text/csv
+// @Param sort query string false "Sort by id, createdAt, updatedAt or nextExecutionAt; prefix with - for descending order"
+// @Param cursor query string false "Page by cursor: empty for the first page, or the cursor of the page to return"
+// @Param limit query int false "Number of items per page when paging by cursor" default(50)
+// @Param format query string false "Response format, overriding the Accept header" Enums(json, csv)
+// @Param organizationId query int false "Only list the scheduled items of this organization"
+// @Param X-User-ID header string false "ID of the user making the request, who only sees their own items, those shared with them and those without an owner"
 // @Success 200 {array} models.ScheduledItem
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} problem.Details "Invalid sort field, organization ID, limit or cursor"
+// @Failure 500 {object} problem.Details "Failed to retrieve page"
 // @Router /scheduled-items [get]
 func (h *ScheduledItemHandler) HandleGetAllScheduledItems(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	ctx, ok := organizationScope(w, r)
+	if !ok {
+		return
+	}
+	if writeCursorPage(w, r, "scheduled-items.csv", scheduledItemCSVColumns, func(limit int, cursor *store.ScheduledItemCursor) ([]models.ScheduledItem, *store.ScheduledItemCursor, error) {
+		return h.store.GetScheduledItemsPage(ctx, limit, cursor)
+	}) {
 		return
 	}
 
-	items := h.store.GetAllScheduledItems()
+	// Lists in store order are streamed as they are read; sorting needs them whole
+	if r.URL.Query().Get("sort") == "" {
+		writeListStream(w, r, "scheduled-items.csv", scheduledItemCSVColumns, h.store.StreamScheduledItems(ctx))
+		return
+	}
+
+	items := h.store.GetAllScheduledItems(ctx)
+	if err := sortItems(items, r.URL.Query().Get("sort"), scheduledItemSortFields); err != nil {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "sort", Message: err.Error()}).Write(w, r)
+		return
+	}
+
+	if wantsCSV(w, r) {
+		writeCSV(w, "scheduled-items.csv", items, scheduledItemCSVColumns)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(items)
@@ -139,15 +459,11 @@ func (h *ScheduledItemHandler) HandleGetAllScheduledItems(w http.ResponseWriter,
 // @Tags scheduled-items
 // @Produce json
 // @Param limit query int false "Maximum number of items to return" default(10)
+// @Param X-User-ID header string false "ID of the user making the request"
 // @Success 200 {array} models.ScheduledItem
-// @Failure 500 {string} string "Internal server error"
+// @Failure 500 {object} problem.Details "Internal server error"
 // @Router /scheduled-items/next [get]
 func (h *ScheduledItemHandler) HandleGetNextScheduledItems(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Parse limit parameter, default to 10
 	limitStr := r.URL.Query().Get("limit")
 	limit := 10
@@ -157,9 +473,9 @@ func (h *ScheduledItemHandler) HandleGetNextScheduledItems(w http.ResponseWriter
 		}
 	}
 
-	items, err := h.store.GetNextScheduledItems(limit, 0)
+	items, err := h.store.GetNextScheduledItems(r.Context(), limit, 0)
 	if err != nil {
-		http.Error(w, "Failed to retrieve scheduled items: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to retrieve scheduled items: "+err.Error())
 		return
 	}
 
@@ -172,127 +488,639 @@ func (h *ScheduledItemHandler) HandleGetNextScheduledItems(w http.ResponseWriter
 // @Description Delete a scheduled item by its ID
 // @Tags scheduled-items
 // @Param id path int true "Scheduled item ID"
+// @Param X-User-ID header string false "ID of the user making the request"
 // @Success 204 "No content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Scheduled item not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only the owner can delete the item"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
 // @Router /scheduled-items/{id} [delete]
 func (h *ScheduledItemHandler) HandleDeleteScheduledItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	idStr := r.URL.Path[len("/scheduled-items/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	if !h.authorizeID(w, r, id, store.ItemAccessOwner) {
 		return
 	}
 
-	if success := h.store.DeleteScheduledItem(id); !success {
-		http.Error(w, "Scheduled item not found", http.StatusNotFound)
+	if success := h.store.DeleteScheduledItem(r.Context(), id); !success {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
 		return
 	}
+	if h.shares != nil {
+		for _, share := range h.shares.GetShares(r.Context(), id) {
+			h.shares.RemoveShare(r.Context(), id, share.UserID)
+		}
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// HandleRunScheduledItem handles POST requests to execute a scheduled item immediately
+// @Summary Run a scheduled item now
+// @Description Execute a scheduled item's action immediately and record an execution log without changing its next execution time
+// @Tags scheduled-items
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {object} models.ExecutionLog
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Scheduled item is only shared with the user as a viewer"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Router /scheduled-items/{id}/run [post]
+func (h *ScheduledItemHandler) HandleRunScheduledItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	item, exists := h.store.GetScheduledItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return
+	}
+	if !h.authorize(w, r, item, store.ItemAccessEdit) {
+		return
+	}
+
+	executionLog, err := h.service.ExecuteScheduledItem(r.Context(), item)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to run scheduled item: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(executionLog)
+}
+
 // GeneratePromptRequest represents the request body for generating scheduled items
 type GeneratePromptRequest struct {
-	Prompt   string `json:"prompt" example:"Schedule a weekly team meeting every Tuesday at 2 PM"`
+	Prompt   string `json:"prompt" validate:"required" example:"Schedule a weekly team meeting every Tuesday at 2 PM"`
 	Timezone string `json:"timezone" example:"America/New_York"`
 }
 
 // HandleGenerateScheduledItem handles POST requests to generate a scheduled item from a prompt
 // @Summary Generate a scheduled item from a text prompt
-// @Description Use AI to generate a scheduled item from a natural language prompt
+// @Description Use AI to generate a scheduled item from a natural language prompt. By default the item is only returned, for the client to review and create; with save=true it is validated and created like POST /scheduled-items, and returned with 201 Created.
 // @Tags generation
 // @Accept json
 // @Produce json
 // @Param request body GeneratePromptRequest true "Generation request with prompt and timezone"
+// @Param save query bool false "Create the generated item instead of only returning it"
+// @Param X-User-ID header string false "User the request is accounted to; defaults to the client address"
 // @Success 200 {object} models.ScheduledItem
-// @Failure 400 {string} string "Bad request"
-// @Failure 500 {string} string "Internal server error"
-// @Failure 503 {string} string "AWS LLM service not available"
+// @Success 201 {object} models.ScheduledItem "Generated item was created"
+// @Failure 400 {object} problem.Details "Bad request, or the generated item is invalid"
+// @Failure 403 {object} problem.Details "Tenant limit of scheduled items reached, when saving"
+// @Failure 429 {object} problem.Details "Daily LLM quota used up"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Failure 502 {object} problem.Details "LLM service returned an error, or output that was still invalid after one repair"
+// @Failure 503 {object} problem.Details "LLM service not available"
+// @Failure 504 {object} problem.Details "LLM service timed out"
 // @Router /generate-scheduled-item [post]
 func (h *ScheduledItemHandler) HandleGenerateScheduledItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	// Check if an LLM provider is configured
+	if h.llm == nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, "LLM service not available")
 		return
 	}
 
-	// Check if AWS client is available
-	if h.awsClient == nil {
-		http.Error(w, "AWS LLM service not available", http.StatusServiceUnavailable)
-		return
+	save := false
+	if saveStr := r.URL.Query().Get("save"); saveStr != "" {
+		parsed, err := strconv.ParseBool(saveStr)
+		if err != nil {
+			problem.Validation("Invalid query parameter", problem.FieldError{Field: "save", Message: "must be true or false"}).Write(w, r)
+			return
+		}
+		save = parsed
 	}
 
 	// Parse request body
 	var req GeneratePromptRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if strings.TrimSpace(req.Prompt) == "" {
-		http.Error(w, "Prompt cannot be empty", http.StatusBadRequest)
+		problem.Validation("Invalid request", problem.FieldError{Field: "prompt", Message: "cannot be empty"}).Write(w, r)
+		return
+	}
+
+	if fieldErr := validateTimezone(req.Timezone); fieldErr != nil {
+		problem.Validation("Invalid request", *fieldErr).Write(w, r)
 		return
 	}
 
-	if strings.TrimSpace(req.Timezone) == "" {
-		http.Error(w, "Timezone is required", http.StatusBadRequest)
+	// Items that couldn't be saved aren't worth generating
+	if save && !h.allowNewScheduledItem(w, r) {
+		return
+	}
+	userID := usageUserID(r)
+	if !h.allowLLMRequest(w, r, userID) {
 		return
 	}
 
-	// Generate JSON from AWS LLM
-	generatedJSON, err := h.awsClient.GenerateScheduledItemJSON(r.Context(), req.Prompt, req.Timezone)
+	// Generate JSON from the LLM, which is checked and repaired once if it's invalid
+	generatedJSON, errs, err := h.generateWithUsage(r, userID, models.LLMOperationGenerate, func(ctx context.Context) (string, []problem.FieldError, error) {
+		return h.generateValidJSON(ctx, func(ctx context.Context) (string, error) {
+			return h.llm.GenerateScheduledItemJSON(ctx, req.Prompt, req.Timezone)
+		}, h.checkGeneratedItem)
+	})
 	if err != nil {
-		http.Error(w, "Failed to generate scheduled item: "+err.Error(), http.StatusInternalServerError)
+		writeLLMError(w, r, "Failed to generate scheduled item", err)
+		return
+	}
+	if len(errs) > 0 {
+		writeInvalidGeneration(w, r, "The LLM generated an invalid scheduled item", errs)
 		return
 	}
 
-	// Validate and parse the generated JSON into ScheduledItem
 	var scheduledItem models.ScheduledItem
 	if err := json.Unmarshal([]byte(generatedJSON), &scheduledItem); err != nil {
-		http.Error(w, "Generated invalid JSON format: "+err.Error(), http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to decode generated item: "+err.Error())
+		return
+	}
+
+	if !save {
+		// Return the generated ScheduledItem as JSON (without storing it)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(scheduledItem)
+		return
+	}
+
+	createdItem, errs := h.createGeneratedItem(r, scheduledItem)
+	if len(errs) > 0 {
+		problem.Validation("Generated scheduled item is invalid", errs...).Write(w, r)
 		return
 	}
 
-	// Return the generated ScheduledItem as JSON (without storing it)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(scheduledItem)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdItem)
 }
 
-// SetupRoutes configures the HTTP routes for scheduled items
-func (h *ScheduledItemHandler) SetupRoutes() {
-	// ScheduledItem collection endpoints
-	http.HandleFunc("/scheduled-items", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetAllScheduledItems(w, r)
-		case http.MethodPost:
-			h.HandleCreateScheduledItem(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// createGeneratedItem validates and creates a generated item. It returns the invalid
+// fields, if any, instead of creating it.
+func (h *ScheduledItemHandler) createGeneratedItem(r *http.Request, generated models.ScheduledItem) (models.ScheduledItem, []problem.FieldError) {
+	// The model only describes the item; the store assigns everything else
+	item := models.ScheduledItem{
+		Title:          generated.Title,
+		Description:    generated.Description,
+		StartsAt:       generated.StartsAt,
+		Repeats:        generated.Repeats,
+		CronExpression: generated.CronExpression,
+		Expiration:     generated.Expiration,
+		ActionType:     generated.ActionType,
+		ActionConfig:   generated.ActionConfig,
+		JitterSeconds:  generated.JitterSeconds,
+	}
+
+	if errs := h.prepareScheduledItem(&item); len(errs) > 0 {
+		return models.ScheduledItem{}, errs
+	}
+
+	return h.createScheduledItem(r, item), nil
+}
+
+// validateTimezone checks the timezone a prompt's dates and times are interpreted in
+func validateTimezone(timezone string) *problem.FieldError {
+	if strings.TrimSpace(timezone) == "" {
+		return &problem.FieldError{Field: "timezone", Message: "is required"}
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return &problem.FieldError{Field: "timezone", Message: "must be an IANA timezone such as America/New_York"}
+	}
+	return nil
+}
+
+// writeLLMError maps an error from the LLM provider to a response. Provider errors are
+// logged rather than returned, since they can include request details from the provider.
+func writeLLMError(w http.ResponseWriter, r *http.Request, action string, err error) {
+	switch {
+	case errors.Is(err, utils.ErrLLMUnavailable):
+		problem.Write(w, r, http.StatusServiceUnavailable, "LLM service is temporarily unavailable, try again later")
+	case errors.Is(err, context.DeadlineExceeded):
+		problem.Write(w, r, http.StatusGatewayTimeout, action+": the LLM service timed out")
+	default:
+		logging.Errorf("%s: %v", action, err)
+		problem.Write(w, r, http.StatusBadGateway, action+": the LLM service returned an error")
+	}
+}
+
+// generatedItemSchema is the schema the LLM's items and patches are checked against
+const generatedItemSchema = "periodic-api_internal_models.ScheduledItem"
+
+// allowLLMRequest reports whether the user may make another generation request today,
+// writing a 429 problem if they or their tenant have used up their quota. Concurrent
+// requests may each be allowed just below the quota, so it can be exceeded by a few requests.
+func (h *ScheduledItemHandler) allowLLMRequest(w http.ResponseWriter, r *http.Request, userID string) bool {
+	h.quotaMu.RLock()
+	quota := h.quota
+	h.quotaMu.RUnlock()
+
+	tenantLimit := 0
+	if h.limits != nil {
+		tenantLimit = h.limits.requestLimits(r).MaxDailyGenerations
+	}
+	if h.usage == nil || (quota.DailyRequests == 0 && quota.DailyTokens == 0 && tenantLimit == 0) {
+		return true
+	}
+
+	totals := usageTotals(r, h.usage, userID)
+	var detail string
+	switch {
+	case tenantLimit > 0 && usageTotals(r, h.usage, "").Requests >= tenantLimit:
+		detail = fmt.Sprintf("Tenant's daily limit of %d generation requests used up", tenantLimit)
+	case quota.DailyRequests > 0 && totals.Requests >= quota.DailyRequests:
+		detail = fmt.Sprintf("Daily quota of %d generation requests used up", quota.DailyRequests)
+	case quota.DailyTokens > 0 && totals.Tokens() >= quota.DailyTokens:
+		detail = fmt.Sprintf("Daily quota of %d LLM tokens used up", quota.DailyTokens)
+	default:
+		return true
+	}
+
+	// Quotas reset at the start of the next UTC day
+	now := time.Now()
+	_, reset := usageDay(now)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(reset.Sub(now).Seconds()))))
+	d := problem.New(http.StatusTooManyRequests, detail)
+	d.Type = problem.TypeQuotaExceeded
+	d.Write(w, r)
+	return false
+}
+
+// generateWithUsage runs generate and records the model calls it made for the user.
+// Requests refused before reaching the model, such as by an open circuit breaker, aren't
+// recorded.
+func (h *ScheduledItemHandler) generateWithUsage(r *http.Request, userID string, operation string, generate func(ctx context.Context) (string, []problem.FieldError, error)) (string, []problem.FieldError, error) {
+	ctx, usage := utils.WithLLMUsage(r.Context())
+	started := time.Now()
+	output, errs, err := generate(ctx)
+	if h.usage == nil || (err != nil && usage.Calls() == 0) {
+		return output, errs, err
+	}
+
+	inputTokens, outputTokens := usage.Tokens()
+	h.usage.CreateLLMUsage(r.Context(), models.LLMUsage{
+		UserID:       userID,
+		Operation:    operation,
+		Provider:     usage.Provider(),
+		Model:        usage.Model(),
+		Calls:        usage.Calls(),
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		LatencyMs:    time.Since(started).Milliseconds(),
+		Succeeded:    err == nil && len(errs) == 0,
+	})
+	return output, errs, err
+}
+
+// generateValidJSON runs generate and checks the JSON extracted from its output. Output
+// that fails the check is sent back to the model once to be repaired; if the repaired
+// output fails too, its problems are returned.
+func (h *ScheduledItemHandler) generateValidJSON(ctx context.Context, generate func(ctx context.Context) (string, error), check func(output string) []problem.FieldError) (string, []problem.FieldError, error) {
+	output, err := generate(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	generated := utils.ExtractJSON(output)
+	errs := check(generated)
+	if len(errs) == 0 {
+		return generated, nil, nil
+	}
+
+	problems := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		problems[i] = fieldErr.Field + " " + fieldErr.Message
+	}
+	logging.Debugf("Asking the LLM to repair invalid output: %s", strings.Join(problems, "; "))
+
+	output, err = h.llm.RepairJSON(ctx, generated, problems)
+	if err != nil {
+		return "", nil, err
+	}
+	generated = utils.ExtractJSON(output)
+	return generated, check(generated), nil
+}
+
+// checkGeneratedItem returns the problems with a scheduled item generated by the LLM
+func (h *ScheduledItemHandler) checkGeneratedItem(output string) []problem.FieldError {
+	if errs := h.checkSchema(output, false); len(errs) > 0 {
+		return errs
+	}
+	var item models.ScheduledItem
+	if err := json.Unmarshal([]byte(output), &item); err != nil {
+		return []problem.FieldError{{Field: "body", Message: "cannot be decoded: " + err.Error()}}
+	}
+	if strings.TrimSpace(item.Title) == "" {
+		return []problem.FieldError{{Field: "title", Message: "cannot be empty"}}
+	}
+	return nil
+}
+
+// checkGeneratedPatch returns the problems with a merge patch generated by the LLM
+func (h *ScheduledItemHandler) checkGeneratedPatch(output string) []problem.FieldError {
+	if errs := h.checkSchema(output, true); len(errs) > 0 {
+		return errs
+	}
+
+	// Only let the model change the fields it was shown
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(output), &fields); err != nil {
+		return []problem.FieldError{{Field: "body", Message: "must be a JSON object"}}
+	}
+	var errs []problem.FieldError
+	for field := range fields {
+		if !slices.Contains(modifiableFields, field) {
+			errs = append(errs, problem.FieldError{Field: field, Message: "cannot be changed"})
+		}
+	}
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b problem.FieldError) int { return cmp.Compare(a.Field, b.Field) })
+		return errs
+	}
+
+	var item models.ScheduledItem
+	if err := json.Unmarshal([]byte(output), &item); err != nil {
+		return []problem.FieldError{{Field: "body", Message: "cannot be decoded: " + err.Error()}}
+	}
+	return nil
+}
+
+// checkSchema validates generated JSON against the ScheduledItem schema, or only checks
+// that it's a JSON object when no schemas were provided
+func (h *ScheduledItemHandler) checkSchema(output string, partial bool) []problem.FieldError {
+	if h.schemas != nil {
+		return h.schemas.ValidateJSON(generatedItemSchema, []byte(output), partial)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(output), &fields); err != nil {
+		return []problem.FieldError{{Field: "body", Message: "must be a JSON object"}}
+	}
+	return nil
+}
+
+// writeInvalidGeneration reports output the LLM couldn't repair, listing its problems
+func writeInvalidGeneration(w http.ResponseWriter, r *http.Request, detail string, errs []problem.FieldError) {
+	d := problem.New(http.StatusBadGateway, detail)
+	d.Type = problem.TypeInvalidGeneration
+	d.Errors = errs
+	d.Write(w, r)
+}
+
+// modifiableFields are the scheduled item fields an instruction may change
+var modifiableFields = []string{
+	"title", "description", "startsAt", "repeats", "cronExpression", "expiration",
+	"actionType", "actionConfig", "jitterSeconds",
+}
+
+// ModifyPromptRequest represents the request body for modifying a scheduled item from a prompt
+type ModifyPromptRequest struct {
+	Instruction string `json:"instruction" validate:"required" example:"Move it to Fridays at 6pm"`
+	Timezone    string `json:"timezone" example:"America/New_York"`
+}
+
+// ModifyPromptResponse shows a scheduled item before and after a modification from a prompt
+type ModifyPromptResponse struct {
+	Before models.ScheduledItem `json:"before"`
+	After  models.ScheduledItem `json:"after"`
+	// Patch is the JSON Merge Patch generated from the instruction
+	Patch json.RawMessage `json:"patch" swaggertype:"object"`
+	// Applied is false when the modification was only previewed with dryRun=true
+	Applied bool `json:"applied" example:"true"`
+}
+
+// HandleModifyScheduledItemFromPrompt handles POST requests to change a scheduled item as instructed in natural language
+// @Summary Modify a scheduled item from a text prompt
+// @Description Use AI to turn an instruction such as "move it to Fridays at 6pm" into a JSON Merge Patch of the item's title, description, schedule and action, and apply it like PATCH /scheduled-items/{id}. The response shows the item before and after so the change can be confirmed; with dryRun=true the change is only previewed.
+// @Tags generation
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param request body ModifyPromptRequest true "Modification request with instruction and timezone"
+// @Param dryRun query bool false "Preview the modification without applying it"
+// @Param X-User-ID header string false "User the request is accounted to; defaults to the client address"
+// @Success 200 {object} ModifyPromptResponse
+// @Failure 400 {object} problem.Details "Bad request, or the generated modification is invalid"
+// @Failure 403 {object} problem.Details "Scheduled item is only shared with the user as a viewer"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Failure 409 {object} problem.Details "Scheduled item was modified concurrently"
+// @Failure 429 {object} problem.Details "Daily LLM quota used up"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Failure 502 {object} problem.Details "LLM service returned an error, or output that was still invalid after one repair"
+// @Failure 503 {object} problem.Details "LLM service not available"
+// @Failure 504 {object} problem.Details "LLM service timed out"
+// @Router /scheduled-items/{id}/modify-from-prompt [post]
+func (h *ScheduledItemHandler) HandleModifyScheduledItemFromPrompt(w http.ResponseWriter, r *http.Request) {
+	if h.llm == nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, "LLM service not available")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dryRun"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			problem.Validation("Invalid query parameter", problem.FieldError{Field: "dryRun", Message: "must be true or false"}).Write(w, r)
+			return
 		}
+		dryRun = parsed
+	}
+
+	var req ModifyPromptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Instruction) == "" {
+		problem.Validation("Invalid request", problem.FieldError{Field: "instruction", Message: "cannot be empty"}).Write(w, r)
+		return
+	}
+	if fieldErr := validateTimezone(req.Timezone); fieldErr != nil {
+		problem.Validation("Invalid request", *fieldErr).Write(w, r)
+		return
+	}
+
+	existing, exists := h.store.GetScheduledItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return
+	}
+	needed := store.ItemAccessEdit
+	if dryRun {
+		needed = store.ItemAccessView
+	}
+	if !h.authorize(w, r, existing, needed) {
+		return
+	}
+
+	itemJSON, err := modifiableFieldsJSON(existing)
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to encode scheduled item: "+err.Error())
+		return
+	}
+
+	userID := usageUserID(r)
+	if !h.allowLLMRequest(w, r, userID) {
+		return
+	}
+
+	generatedPatch, errs, err := h.generateWithUsage(r, userID, models.LLMOperationModify, func(ctx context.Context) (string, []problem.FieldError, error) {
+		return h.generateValidJSON(ctx, func(ctx context.Context) (string, error) {
+			return h.llm.ModifyScheduledItemJSON(ctx, itemJSON, req.Instruction, req.Timezone)
+		}, h.checkGeneratedPatch)
 	})
+	if err != nil {
+		writeLLMError(w, r, "Failed to generate modification", err)
+		return
+	}
+	if len(errs) > 0 {
+		writeInvalidGeneration(w, r, "The LLM generated an invalid modification", errs)
+		return
+	}
+
+	item, errs, err := h.patchScheduledItem(existing, []byte(generatedPatch))
+	if err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Generated invalid merge patch: "+err.Error())
+		return
+	}
+	if len(errs) > 0 {
+		problem.Validation("Generated modification is invalid", errs...).Write(w, r)
+		return
+	}
+
+	response := ModifyPromptResponse{
+		Before: existing,
+		After:  item,
+		Patch:  json.RawMessage(generatedPatch),
+	}
+
+	if !dryRun {
+		// The item keeps the version it was read with, so a concurrent change while the
+		// model was running is reported as a conflict instead of being overwritten
+		updatedItem, err := h.updateScheduledItem(r.Context(), id, item)
+		if err != nil {
+			writeScheduledItemUpdateError(w, r, err)
+			return
+		}
+		h.service.NotifyNextExecution(updatedItem.NextExecutionAt)
+
+		response.After = updatedItem
+		response.Applied = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// modifiableFieldsJSON encodes the fields of an item that an instruction may change
+func modifiableFieldsJSON(item models.ScheduledItem) (string, error) {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return "", err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return "", err
+	}
+	for field := range fields {
+		if !slices.Contains(modifiableFields, field) {
+			delete(fields, field)
+		}
+	}
+	encoded, err = json.Marshal(fields)
+	return string(encoded), err
+}
+
+// prepareScheduledItem validates a scheduled item from a request and calculates its next
+// execution time. It returns the invalid fields, if any.
+func (h *ScheduledItemHandler) prepareScheduledItem(item *models.ScheduledItem) []problem.FieldError {
+	if item.JitterSeconds < 0 {
+		return []problem.FieldError{{Field: "jitterSeconds", Message: "cannot be negative"}}
+	}
+
+	// Calculate next execution time
+	nextExec := utils.CalculateNextExecution(
+		item.StartsAt,
+		item.Repeats,
+		item.CronExpression,
+		item.Expiration,
+		item.JitterSeconds,
+	)
+	if nextExec == nil {
+		// Invalid scheduled item - cannot determine next execution time
+		// This could be due to:
+		// - Non-repeating item scheduled in the past
+		// - Repeating item without valid cron expression
+		// - Item already expired
+		// - Invalid cron expression format
+		field := "startsAt"
+		if item.Repeats {
+			field = "cronExpression"
+		}
+		return []problem.FieldError{{Field: field, Message: "cannot determine next execution time"}}
+	}
+
+	item.NextExecutionAt = *nextExec
+
+	if err := h.service.ValidateAction(*item); err != nil {
+		return []problem.FieldError{{Field: "actionConfig", Message: err.Error()}}
+	}
+
+	if item.Notifications != nil {
+		if err := notifications.ValidateSettings(*item.Notifications); err != nil {
+			return []problem.FieldError{{Field: "notifications", Message: err.Error()}}
+		}
+	}
+
+	return nil
+}
+
+// RegisterRoutes registers the HTTP routes for scheduled items on the given mux
+func (h *ScheduledItemHandler) RegisterRoutes(mux *http.ServeMux) {
+	// ScheduledItem collection endpoints
+	mux.HandleFunc("GET /scheduled-items", h.HandleGetAllScheduledItems)
+	mux.HandleFunc("POST /scheduled-items", h.HandleCreateScheduledItem)
 
 	// Get next scheduled items
-	http.HandleFunc("/scheduled-items/next", h.HandleGetNextScheduledItems)
+	mux.HandleFunc("GET /scheduled-items/next", h.HandleGetNextScheduledItems)
 
 	// Generate scheduled item from prompt
-	http.HandleFunc("/generate-scheduled-item", h.HandleGenerateScheduledItem)
+	mux.HandleFunc("POST /generate-scheduled-item", h.HandleGenerateScheduledItem)
 
 	// ScheduledItem instance endpoints
-	http.HandleFunc("/scheduled-items/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetScheduledItem(w, r)
-		case http.MethodDelete:
-			h.HandleDeleteScheduledItem(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("GET /scheduled-items/{id}", h.HandleGetScheduledItem)
+	mux.HandleFunc("PUT /scheduled-items/{id}", h.HandleUpdateScheduledItem)
+	mux.HandleFunc("PATCH /scheduled-items/{id}", h.HandlePatchScheduledItem)
+	mux.HandleFunc("DELETE /scheduled-items/{id}", h.HandleDeleteScheduledItem)
+
+	// Run a scheduled item immediately
+	mux.HandleFunc("POST /scheduled-items/{id}/run", h.HandleRunScheduledItem)
+
+	// Share a scheduled item with other users
+	mux.HandleFunc("GET /scheduled-items/{id}/shares", h.HandleGetItemShares)
+	mux.HandleFunc("PUT /scheduled-items/{id}/shares/{userId}", h.HandleSetItemShare)
+	mux.HandleFunc("DELETE /scheduled-items/{id}/shares/{userId}", h.HandleRemoveItemShare)
+
+	// Modify a scheduled item from a natural language instruction
+	mux.HandleFunc("POST /scheduled-items/{id}/modify-from-prompt", h.HandleModifyScheduledItemFromPrompt)
+
+	// Refine a generated item over several messages before creating it
+	mux.HandleFunc("POST /generation-sessions", h.HandleCreateGenerationSession)
+	mux.HandleFunc("GET /generation-sessions/{id}", h.HandleGetGenerationSession)
+	mux.HandleFunc("DELETE /generation-sessions/{id}", h.HandleDeleteGenerationSession)
+	mux.HandleFunc("POST /generation-sessions/{id}/messages", h.HandleAddGenerationSessionMessage)
+	mux.HandleFunc("POST /generation-sessions/{id}/commit", h.HandleCommitGenerationSession)
 }
@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/docs"
+	"periodic-api/internal/middleware"
+	"periodic-api/internal/models"
+	"periodic-api/internal/openapi"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateScheduledItemReturnsValidationProblem(t *testing.T) {
+	router := newTestRouter()
+
+	body := `{"title":"Bad Jitter","startsAt":"2030-01-01T00:00:00Z","jitterSeconds":-5}`
+	req := httptest.NewRequest(http.MethodPost, "/scheduled-items", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Expected problem details content type, got %q", ct)
+	}
+
+	var details problem.Details
+	if err := json.NewDecoder(rec.Body).Decode(&details); err != nil {
+		t.Fatalf("Failed to decode problem details: %v", err)
+	}
+	if details.Type != problem.TypeValidation || details.Instance != "/scheduled-items" {
+		t.Errorf("Unexpected problem details %+v", details)
+	}
+	if len(details.Errors) != 1 || details.Errors[0].Field != "jitterSeconds" {
+		t.Errorf("Expected a jitterSeconds field error, got %+v", details.Errors)
+	}
+}
+
+func TestCreateScheduledItemValidatesNotifications(t *testing.T) {
+	router := newTestRouter()
+
+	for body, wantCode := range map[string]int{
+		`{"title":"Bad Address","startsAt":"2030-01-01T00:00:00Z","notifications":{"onFailure":true,"email":["not an address"]}}`: http.StatusBadRequest,
+		`{"title":"Bad Template","startsAt":"2030-01-01T00:00:00Z","notifications":{"onFailure":true,"subject":"{{.Item.Title"}}`: http.StatusBadRequest,
+		`{"title":"Notified","startsAt":"2030-01-01T00:00:00Z","notifications":{"onFailure":true,"email":["ops@example.com"]}}`:   http.StatusCreated,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scheduled-items", strings.NewReader(body)))
+		if rec.Code != wantCode {
+			t.Errorf("Expected %d for %s, got %d: %s", wantCode, body, rec.Code, rec.Body.String())
+			continue
+		}
+		if wantCode != http.StatusBadRequest {
+			continue
+		}
+		var details problem.Details
+		if err := json.NewDecoder(rec.Body).Decode(&details); err != nil {
+			t.Fatalf("Failed to decode problem details: %v", err)
+		}
+		if len(details.Errors) != 1 || details.Errors[0].Field != "notifications" {
+			t.Errorf("Expected a notifications field error, got %+v", details.Errors)
+		}
+	}
+}
+
+func TestPatchScheduledItemKeepsScheduleUnlessChanged(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	router := NewRouter(NewScheduledItemHandler(itemStore, service))
+
+	// A one-off item that is already due can't be rescheduled, but can still be renamed
+	dueAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	existing := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Due",
+		StartsAt:        dueAt,
+		NextExecutionAt: dueAt,
+	})
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/scheduled-items/%d", existing.ID), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := patch(`{"title":"Renamed"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated models.ScheduledItem
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.Title != "Renamed" || !updated.NextExecutionAt.Equal(dueAt) || updated.Version != existing.Version+1 {
+		t.Errorf("Unexpected patched item %+v", updated)
+	}
+
+	// Changing the schedule recalculates the next execution
+	startsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	rec = patch(fmt.Sprintf(`{"startsAt":%q}`, startsAt.Format(time.RFC3339)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	json.NewDecoder(rec.Body).Decode(&updated)
+	if !updated.NextExecutionAt.Equal(startsAt) {
+		t.Errorf("Expected next execution %v, got %v", startsAt, updated.NextExecutionAt)
+	}
+
+	// A stale version is rejected
+	if rec := patch(`{"title":"Stale","version":1}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a stale version, got %d", rec.Code)
+	}
+}
+
+func TestScheduledItemRecordsRequestID(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	router := middleware.RequestID(NewRouter(NewScheduledItemHandler(itemStore, service)))
+
+	send := func(method string, path string, body string, requestID string) models.ScheduledItem {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+			t.Fatalf("%s %s: expected success, got %d: %s", method, path, rec.Code, rec.Body.String())
+		}
+		var item models.ScheduledItem
+		json.NewDecoder(rec.Body).Decode(&item)
+		return item
+	}
+
+	// The ID of the request that creates an item is stored with it
+	created := send(http.MethodPost, "/scheduled-items", `{"title":"Traced","startsAt":"2030-01-01T00:00:00Z","requestId":"spoofed"}`, "create-request")
+	if created.RequestID != "create-request" {
+		t.Errorf("Expected request ID %q, got %q", "create-request", created.RequestID)
+	}
+
+	// Each modification replaces it with the ID of the modifying request
+	patched := send(http.MethodPatch, fmt.Sprintf("/scheduled-items/%d", created.ID), `{"title":"Renamed"}`, "patch-request")
+	if patched.RequestID != "patch-request" {
+		t.Errorf("Expected request ID %q, got %q", "patch-request", patched.RequestID)
+	}
+	if stored, _ := itemStore.GetScheduledItem(context.Background(), created.ID); stored.RequestID != "patch-request" {
+		t.Errorf("Expected stored request ID %q, got %q", "patch-request", stored.RequestID)
+	}
+}
+
+// fakeLLMProvider returns a fixed generated item or modification
+type fakeLLMProvider struct {
+	generated string
+	patch     string
+	// itemJSON records the item passed to ModifyScheduledItemJSON
+	itemJSON *string
+	err      error
+	// repaired is returned by RepairJSON, which otherwise returns the output unchanged
+	repaired string
+	// problems records the problems passed to RepairJSON
+	problems *[]string
+	// refined is returned by RefineScheduledItemJSON
+	refined string
+	// conversation records the conversation passed to RefineScheduledItemJSON
+	conversation *[]utils.LLMTurn
+}
+
+func (p fakeLLMProvider) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	return p.generated, p.err
+}
+
+func (p fakeLLMProvider) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	if p.itemJSON != nil {
+		*p.itemJSON = itemJSON
+	}
+	return p.patch, nil
+}
+
+func (p fakeLLMProvider) RefineScheduledItemJSON(ctx context.Context, conversation []utils.LLMTurn, message string, userTimezone string) (string, error) {
+	if p.conversation != nil {
+		*p.conversation = conversation
+	}
+	return p.refined, p.err
+}
+
+func (p fakeLLMProvider) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	if p.problems != nil {
+		*p.problems = problems
+	}
+	if p.repaired == "" {
+		return output, nil
+	}
+	return p.repaired, nil
+}
+
+// testSchemas loads the validator for the documented schemas
+func testSchemas(t *testing.T) *openapi.Validator {
+	t.Helper()
+	validator, err := openapi.NewValidator(docs.OpenAPI)
+	if err != nil {
+		t.Fatalf("NewValidator failed: %v", err)
+	}
+	return validator
+}
+
+func TestGenerateScheduledItemWithSave(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	router := NewRouter(handler)
+
+	generate := func(query string) *httptest.ResponseRecorder {
+		body := `{"prompt":"Water the plants every morning","timezone":"UTC"}`
+		req := httptest.NewRequest(http.MethodPost, "/generate-scheduled-item"+query, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	schemas := testSchemas(t)
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: `{"id":99,"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z","repeats":true,"cronExpression":"0 8 * * *"}`,
+	}, schemas)
+
+	// Without save the item is only returned
+	if rec := generate(""); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if items := itemStore.GetAllScheduledItems(context.Background()); len(items) != 0 {
+		t.Fatalf("Expected nothing to be stored, got %+v", items)
+	}
+
+	rec := generate("?save=true")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.ScheduledItem
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.ID == 99 || created.Title != "Water the plants" || created.NextExecutionAt.IsZero() {
+		t.Errorf("Unexpected created item %+v", created)
+	}
+	if _, found := itemStore.GetScheduledItem(context.Background(), created.ID); !found {
+		t.Errorf("Expected the generated item to be stored")
+	}
+
+	// Generated items are validated like created ones
+	handler.EnableGeneration(fakeLLMProvider{generated: `{"title":"Water the plants","startsAt":"2000-01-01T08:00:00Z"}`}, schemas)
+	rec = generate("?save=true")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var details problem.Details
+	json.NewDecoder(rec.Body).Decode(&details)
+	if len(details.Errors) != 1 || details.Errors[0].Field != "startsAt" {
+		t.Errorf("Expected a startsAt error, got %+v", details.Errors)
+	}
+
+	if rec := generate("?save=maybe"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid save parameter, got %d", rec.Code)
+	}
+}
+
+func TestModifyScheduledItemFromPrompt(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	router := NewRouter(handler)
+
+	cron := "0 9 * * 1"
+	startsAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	existing := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Team sync",
+		StartsAt:        startsAt,
+		Repeats:         true,
+		CronExpression:  &cron,
+		NextExecutionAt: startsAt,
+	})
+
+	modify := func(query string, patch string) (*httptest.ResponseRecorder, string) {
+		var itemJSON string
+		handler.EnableGeneration(fakeLLMProvider{patch: patch, itemJSON: &itemJSON}, testSchemas(t))
+		body := `{"instruction":"Move it to Fridays at 6pm","timezone":"UTC"}`
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/scheduled-items/%d/modify-from-prompt%s", existing.ID, query), strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec, itemJSON
+	}
+
+	// A dry run previews the change without applying it
+	rec, itemJSON := modify("?dryRun=true", `{"cronExpression":"0 18 * * 5"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(itemJSON, `"id"`) || strings.Contains(itemJSON, `"version"`) || !strings.Contains(itemJSON, `"title":"Team sync"`) {
+		t.Errorf("Expected only the modifiable fields to be sent, got %s", itemJSON)
+	}
+	var response ModifyPromptResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.Applied || *response.Before.CronExpression != cron || *response.After.CronExpression != "0 18 * * 5" {
+		t.Errorf("Unexpected dry run response %+v", response)
+	}
+	if stored, _ := itemStore.GetScheduledItem(context.Background(), existing.ID); *stored.CronExpression != cron {
+		t.Errorf("Expected the dry run to leave the item unchanged, got %+v", stored)
+	}
+
+	// Without dryRun the change goes through the update path
+	rec, _ = modify("", `{"cronExpression":"0 18 * * 5"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	json.NewDecoder(rec.Body).Decode(&response)
+	if !response.Applied || response.After.Version != existing.Version+1 || response.After.NextExecutionAt.Weekday() != time.Friday {
+		t.Errorf("Unexpected applied response %+v", response)
+	}
+
+	// Fields the model wasn't shown can't be changed, even after a repair
+	rec, _ = modify("", `{"version":1,"id":5}`)
+	var details problem.Details
+	json.NewDecoder(rec.Body).Decode(&details)
+	if rec.Code != http.StatusBadGateway || details.Type != problem.TypeInvalidGeneration || len(details.Errors) != 2 || details.Errors[0].Field != "id" || details.Errors[1].Field != "version" {
+		t.Errorf("Expected id and version errors, got %d: %+v", rec.Code, details)
+	}
+
+	// Modifications are validated like patches
+	if rec, _ := modify("", `{"cronExpression":"not a cron"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid cron expression, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerateScheduledItemMapsLLMErrors(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	router := NewRouter(handler)
+
+	generate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/generate-scheduled-item", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	body := `{"prompt":"Standup every weekday at 9","timezone":"Europe/Berlin"}`
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{utils.ErrLLMUnavailable, http.StatusServiceUnavailable},
+		{fmt.Errorf("failed to invoke model: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{errors.New("AccessDeniedException: arn:aws:iam::123456789012:role/secret"), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		handler.EnableGeneration(fakeLLMProvider{err: tt.err}, nil)
+		rec := generate(body)
+		if rec.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), "arn:aws") {
+			t.Errorf("Expected the provider error to be hidden, got %s", rec.Body.String())
+		}
+	}
+
+	// Unknown timezones are rejected before calling the model
+	handler.EnableGeneration(fakeLLMProvider{err: errors.New("should not be called")}, nil)
+	rec := generate(`{"prompt":"Standup","timezone":"Mars/Olympus_Mons"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "timezone") {
+		t.Errorf("Expected a timezone validation error, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerateScheduledItemRepairsInvalidOutput(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	router := NewRouter(handler)
+
+	generate := func() *httptest.ResponseRecorder {
+		body := `{"prompt":"Water the plants every morning","timezone":"UTC"}`
+		req := httptest.NewRequest(http.MethodPost, "/generate-scheduled-item", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Code fences and prose around the JSON are dropped without a repair
+	var problems []string
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: "Here you go:\n```json\n{\"title\":\"Water the plants\",\"startsAt\":\"2030-01-01T08:00:00Z\"}\n```",
+		problems:  &problems,
+	}, testSchemas(t))
+	if rec := generate(); rec.Code != http.StatusOK || problems != nil {
+		t.Fatalf("Expected status 200 without a repair, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Output that doesn't match the schema is sent back once with its problems
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: `{"name":"Water the plants","startsAt":"tomorrow at 8"}`,
+		repaired:  `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`,
+		problems:  &problems,
+	}, testSchemas(t))
+	rec := generate()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 after a repair, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !slices.Contains(problems, "name is not a known field") || !slices.Contains(problems, "title is required") {
+		t.Errorf("Expected the schema problems to be sent for repair, got %q", problems)
+	}
+
+	// Dates the schema can't describe are caught when decoding
+	problems = nil
+	handler.EnableGeneration(fakeLLMProvider{
+		generated: `{"title":"Water the plants","startsAt":"tomorrow at 8"}`,
+		problems:  &problems,
+	}, testSchemas(t))
+	rec = generate()
+	var details problem.Details
+	json.NewDecoder(rec.Body).Decode(&details)
+	if rec.Code != http.StatusBadGateway || details.Type != problem.TypeInvalidGeneration || len(problems) != 1 {
+		t.Errorf("Expected an invalid generation after one repair, got %d: %+v", rec.Code, details)
+	}
+	if len(details.Errors) != 1 || details.Errors[0].Field != "body" {
+		t.Errorf("Expected a body error, got %+v", details.Errors)
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// requestUser returns the ID of the user making a request, or false when the request
+// doesn't name a user by ID. With AUTH_TOKEN_SECRET set the user comes from the bearer
+// token's "sub" claim, which AuthenticateTenant puts in place of any X-User-ID header the
+// client sent; without a secret it comes from the X-User-ID header.
+func requestUser(r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.Header.Get(userIDHeader)), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// ScopeToUser confines the store calls made while handling a request to what the user
+// making it may see (store.WithUser). Requests with a bearer token that names no user are
+// refused with 401 rather than served unscoped, so leaving the user out can't reveal the
+// rest of the tenant; only administrators' tokens, which manage every tenant, may. Without
+// tenant tokens, requests without X-User-ID are served unscoped. Only scheduled items,
+// their execution logs and webhooks belong to users; todo items are shared by the tenant.
+func ScopeToUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := requestUser(r)
+		if !ok && authenticated(r) && !tenantAdmin(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			problem.Write(w, r, http.StatusUnauthorized, "Bearer token names no user")
+			return
+		}
+		if ok {
+			r = r.WithContext(store.WithUser(r.Context(), userID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestOwner returns the user an item created by a request belongs to: the user making
+// it, when sharing is enabled and the user exists
+func (h *ScheduledItemHandler) requestOwner(r *http.Request) *int64 {
+	if h.shares == nil {
+		return nil
+	}
+	userID, ok := requestUser(r)
+	if !ok {
+		return nil
+	}
+	if _, exists := h.users.GetUser(r.Context(), userID); !exists {
+		return nil
+	}
+	return &userID
+}
+
+// authorize checks that the user making a request has the access needed to an item,
+// writing the problem and returning false otherwise. Items the user can't see at all
+// are reported as not found.
+func (h *ScheduledItemHandler) authorize(w http.ResponseWriter, r *http.Request, item models.ScheduledItem, needed store.ItemAccess) bool {
+	access := store.ScheduledItemAccess(r.Context(), h.shares, item)
+	switch {
+	case access >= needed:
+		return true
+	case access == store.ItemAccessNone:
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+	case needed == store.ItemAccessOwner:
+		problem.Write(w, r, http.StatusForbidden, "Only the owner of the scheduled item can do this")
+	default:
+		problem.Write(w, r, http.StatusForbidden, "Scheduled item is only shared with you as a viewer")
+	}
+	return false
+}
+
+// authorizeID looks up an item and checks the access of the user making a request to it
+// like authorize. Nothing is looked up when sharing is disabled.
+func (h *ScheduledItemHandler) authorizeID(w http.ResponseWriter, r *http.Request, id int64, needed store.ItemAccess) bool {
+	if h.shares == nil {
+		return true
+	}
+	item, exists := h.store.GetScheduledItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return false
+	}
+	return h.authorize(w, r, item, needed)
+}
+
+// sharedItem parses the item ID from the path and looks up the item for the share
+// endpoints, writing the problem and returning false when sharing is disabled or the
+// item can't be found
+func (h *ScheduledItemHandler) sharedItem(w http.ResponseWriter, r *http.Request) (models.ScheduledItem, bool) {
+	if h.shares == nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, "Sharing not available")
+		return models.ScheduledItem{}, false
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return models.ScheduledItem{}, false
+	}
+	item, exists := h.store.GetScheduledItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Scheduled item not found")
+		return models.ScheduledItem{}, false
+	}
+	return item, true
+}
+
+// HandleGetItemShares handles GET requests to list the users a scheduled item is shared with
+// @Summary List a scheduled item's shares
+// @Description List the users a scheduled item is shared with and their roles, in the order they were granted access
+// @Tags scheduled-items
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {array} models.ItemShare
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Scheduled item not found"
+// @Failure 503 {object} problem.Details "Sharing not available"
+// @Router /scheduled-items/{id}/shares [get]
+func (h *ScheduledItemHandler) HandleGetItemShares(w http.ResponseWriter, r *http.Request) {
+	item, ok := h.sharedItem(w, r)
+	if !ok || !h.authorize(w, r, item, store.ItemAccessView) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.shares.GetShares(r.Context(), item.ID))
+}
+
+// HandleSetItemShare handles PUT requests to share a scheduled item with a user
+// @Summary Share a scheduled item
+// @Description Share a scheduled item with a user as a viewer, who can read it, or an editor, who can also change and run it, or change the role of a user it is already shared with. Only the item's owner can share it, and only items with an owner can be shared.
+// @Tags scheduled-items
+// @Accept json
+// @Produce json
+// @Param id path int true "Scheduled item ID"
+// @Param userId path int true "User ID"
+// @Param share body models.ItemShare true "Share with the user's role"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 200 {object} models.ItemShare
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Only the owner can share the item"
+// @Failure 404 {object} problem.Details "Scheduled item or user not found"
+// @Failure 409 {object} problem.Details "Scheduled item has no owner"
+// @Failure 500 {object} problem.Details "Failed to share scheduled item"
+// @Failure 503 {object} problem.Details "Sharing not available"
+// @Router /scheduled-items/{id}/shares/{userId} [put]
+func (h *ScheduledItemHandler) HandleSetItemShare(w http.ResponseWriter, r *http.Request) {
+	item, ok := h.sharedItem(w, r)
+	if !ok || !h.authorize(w, r, item, store.ItemAccessOwner) {
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("userId"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var share models.ItemShare
+	if err := json.NewDecoder(r.Body).Decode(&share); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	var errs []problem.FieldError
+	if !slices.Contains(models.ItemShareRoles, share.Role) {
+		errs = append(errs, problem.FieldError{Field: "role", Message: "must be viewer or editor"})
+	}
+	if item.OwnerID != nil && *item.OwnerID == userID {
+		errs = append(errs, problem.FieldError{Field: "userId", Message: "owns the scheduled item"})
+	}
+	if len(errs) > 0 {
+		problem.Validation("Invalid share", errs...).Write(w, r)
+		return
+	}
+	if item.OwnerID == nil {
+		problem.Write(w, r, http.StatusConflict, "Scheduled item has no owner, so everyone can already see it")
+		return
+	}
+	if _, exists := h.users.GetUser(r.Context(), userID); !exists {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
+		return
+	}
+
+	share.ScheduledItemID = item.ID
+	share.UserID = userID
+	saved, ok := h.shares.SetShare(r.Context(), share)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to share scheduled item")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// HandleRemoveItemShare handles DELETE requests to stop sharing a scheduled item with a user
+// @Summary Stop sharing a scheduled item
+// @Description Revoke a user's access to a scheduled item. The owner can revoke anyone's access, and users can remove items shared with them.
+// @Tags scheduled-items
+// @Param id path int true "Scheduled item ID"
+// @Param userId path int true "User ID"
+// @Param X-User-ID header string false "ID of the user making the request"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 403 {object} problem.Details "Only the owner can revoke the access of others"
+// @Failure 404 {object} problem.Details "Scheduled item or share not found"
+// @Failure 503 {object} problem.Details "Sharing not available"
+// @Router /scheduled-items/{id}/shares/{userId} [delete]
+func (h *ScheduledItemHandler) HandleRemoveItemShare(w http.ResponseWriter, r *http.Request) {
+	item, ok := h.sharedItem(w, r)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("userId"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if caller, ok := requestUser(r); !ok || caller != userID {
+		if !h.authorize(w, r, item, store.ItemAccessOwner) {
+			return
+		}
+	}
+
+	if !h.shares.RemoveShare(r.Context(), item.ID, userID) {
+		problem.Write(w, r, http.StatusNotFound, "Share not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
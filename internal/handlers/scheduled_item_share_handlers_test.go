@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestSharedItemsAreVisibleToViewersAndEditableByEditors(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	alice := users.CreateUser(context.Background(), models.User{Username: "alice"})
+	bob := users.CreateUser(context.Background(), models.User{Username: "bob"})
+	carol := users.CreateUser(context.Background(), models.User{Username: "carol"})
+	shareStore := store.NewMemoryItemShareStore()
+	itemStore := store.NewScopedScheduledItemStore(store.NewMemoryScheduledItemStore(), shareStore)
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	handler.EnableSharing(shareStore, users)
+	router := ScopeToUser(NewRouter(handler))
+
+	serve := func(user models.User, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User-ID", strconv.FormatInt(user.ID, 10))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	visible := func(user models.User) int {
+		var items []models.ScheduledItem
+		if err := json.NewDecoder(serve(user, http.MethodGet, "/scheduled-items", "").Body).Decode(&items); err != nil {
+			t.Fatalf("Failed to decode scheduled items: %v", err)
+		}
+		return len(items)
+	}
+
+	rec := serve(alice, http.MethodPost, "/scheduled-items", `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var item models.ScheduledItem
+	if err := json.NewDecoder(rec.Body).Decode(&item); err != nil {
+		t.Fatalf("Failed to decode scheduled item: %v", err)
+	}
+	if item.OwnerID == nil || *item.OwnerID != alice.ID {
+		t.Fatalf("Expected the item to belong to alice, got %v", item.OwnerID)
+	}
+	path := fmt.Sprintf("/scheduled-items/%d", item.ID)
+
+	// Other users can't see the item until it is shared with them
+	if visible(bob) != 0 {
+		t.Fatalf("Expected bob to see no items before sharing")
+	}
+	if rec := serve(bob, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 for an item that isn't shared, got %d", rec.Code)
+	}
+	if rec := serve(bob, http.MethodPut, fmt.Sprintf("%s/shares/%d", path, carol.ID), `{"role":"viewer"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 when sharing someone else's item, got %d", rec.Code)
+	}
+
+	if rec := serve(alice, http.MethodPut, fmt.Sprintf("%s/shares/%d", path, bob.ID), `{"role":"owner"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for an unknown role, got %d", rec.Code)
+	}
+	if rec := serve(alice, http.MethodPut, fmt.Sprintf("%s/shares/%d", path, bob.ID), `{"role":"viewer"}`); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if visible(bob) != 1 {
+		t.Fatalf("Expected bob to see the shared item")
+	}
+
+	// Viewers can read the item but not change it; editors can
+	patch := `{"title":"Water the garden"}`
+	if rec := serve(bob, http.MethodPatch, path, patch); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 for a viewer, got %d", rec.Code)
+	}
+	serve(alice, http.MethodPut, fmt.Sprintf("%s/shares/%d", path, bob.ID), `{"role":"editor"}`)
+	if rec := serve(bob, http.MethodPatch, path, patch); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for an editor, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(bob, http.MethodDelete, path, ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 when an editor deletes the item, got %d", rec.Code)
+	}
+
+	var shares []models.ItemShare
+	if err := json.NewDecoder(serve(bob, http.MethodGet, path+"/shares", "").Body).Decode(&shares); err != nil {
+		t.Fatalf("Failed to decode shares: %v", err)
+	}
+	if len(shares) != 1 || shares[0].UserID != bob.ID || shares[0].Role != models.ItemShareRoleEditor {
+		t.Fatalf("Expected bob to be the only editor, got %+v", shares)
+	}
+
+	// Revoking the share hides the item again
+	if rec := serve(alice, http.MethodDelete, fmt.Sprintf("%s/shares/%d", path, bob.ID), ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+	if visible(bob) != 0 || visible(alice) != 1 {
+		t.Errorf("Expected only alice to see the item after revoking the share")
+	}
+}
+
+func TestNoEndpointBypassesTheOwnerScope(t *testing.T) {
+	users := store.NewMemoryUserStore()
+	alice := users.CreateUser(context.Background(), models.User{Username: "alice"})
+	bob := users.CreateUser(context.Background(), models.User{Username: "bob"})
+	shareStore := store.NewMemoryItemShareStore()
+	itemStore := store.NewScopedScheduledItemStore(store.NewMemoryScheduledItemStore(), shareStore)
+	logStore := store.NewScopedExecutionLogStore(store.NewMemoryExecutionLogStore(), itemStore)
+	todoStore := store.NewMemoryTodoItemStore()
+	handler := NewScheduledItemHandler(itemStore, scheduler.NewService(itemStore, todoStore, logStore))
+	handler.EnableSharing(shareStore, users)
+	api := ScopeToUser(NewRouter(handler, NewExecutionLogHandler(logStore)))
+	router := NewRouter(Mount(APIPrefix, api), MountEnveloped(APIV2Prefix, api))
+
+	serve := func(user models.User, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User-ID", strconv.FormatInt(user.ID, 10))
+		if strings.Contains(path, "format=csv") {
+			req.Header.Set("Accept", "text/csv")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(alice, http.MethodPost, "/api/v1/scheduled-items", `{"title":"Alice's secret plan","startsAt":"2030-01-01T08:00:00Z"}`)
+	var item models.ScheduledItem
+	if err := json.NewDecoder(rec.Body).Decode(&item); err != nil || item.OwnerID == nil {
+		t.Fatalf("Failed to create alice's item: %d %s", rec.Code, rec.Body.String())
+	}
+	logStore.CreateExecutionLog(context.Background(), models.ExecutionLog{ScheduledItemID: item.ID, Status: "success"})
+	path := fmt.Sprintf("/scheduled-items/%d", item.ID)
+	itemID := fmt.Sprintf(`"scheduledItemId":%d`, item.ID)
+
+	// No listing or lookup shows bob the item or its executions, in any version or format
+	for _, prefix := range []string{"/api/v1", "/api/v2"} {
+		for _, read := range []string{"/scheduled-items", "/scheduled-items?format=csv", "/scheduled-items/next", path, path + "/shares", "/execution-logs", "/execution-logs?format=csv"} {
+			rec := serve(bob, http.MethodGet, prefix+read, "")
+			body := rec.Body.String()
+			if strings.Contains(body, "secret plan") || strings.Contains(body, itemID) || strings.Contains(body, fmt.Sprintf("\n%d,", item.ID)) {
+				t.Errorf("Expected GET %s%s not to show alice's item to bob, got %d: %s", prefix, read, rec.Code, body)
+			}
+		}
+	}
+
+	// Nor can bob change, run, share or delete it
+	for _, write := range []struct{ method, path, body string }{
+		{http.MethodPut, path, `{"title":"Bob's plan","startsAt":"2030-01-01T08:00:00Z"}`},
+		{http.MethodPatch, path, `{"title":"Bob's plan"}`},
+		{http.MethodPost, path + "/run", ""},
+		{http.MethodPut, fmt.Sprintf("%s/shares/%d", path, bob.ID), `{"role":"editor"}`},
+		{http.MethodDelete, path, ""},
+	} {
+		if rec := serve(bob, write.method, "/api/v1"+write.path, write.body); rec.Code < 400 {
+			t.Errorf("Expected %s %s to be refused for bob, got %d", write.method, write.path, rec.Code)
+		}
+	}
+
+	// The stores refuse bob even when called directly
+	ctx := store.WithUser(context.Background(), bob.ID)
+	if _, err := itemStore.UpdateScheduledItem(ctx, item.ID, item); err != store.ErrNotFound {
+		t.Errorf("Expected the store to refuse bob's update, got %v", err)
+	}
+	if itemStore.DeleteScheduledItem(ctx, item.ID) {
+		t.Errorf("Expected the store to refuse bob's delete")
+	}
+	if logs, _, _ := logStore.GetExecutionLogsByScheduledItemID(ctx, item.ID, 10, nil); len(logs) != 0 {
+		t.Errorf("Expected bob to see no executions of the item, got %+v", logs)
+	}
+
+	var unchanged models.ScheduledItem
+	json.NewDecoder(serve(alice, http.MethodGet, "/api/v1"+path, "").Body).Decode(&unchanged)
+	if unchanged.Title != item.Title {
+		t.Errorf("Expected alice's item to be unchanged, got %+v", unchanged)
+	}
+	var logs []models.ExecutionLog
+	json.NewDecoder(serve(alice, http.MethodGet, "/api/v1/execution-logs", "").Body).Decode(&logs)
+	if len(logs) != 1 {
+		t.Errorf("Expected alice to see the item's execution, got %+v", logs)
+	}
+}
+
+// Test that with tokens checked the user comes only from the token, so leaving it out or
+// naming someone else in X-User-ID can't reveal another user's items
+func TestScopeToUserCantBeBypassedWithTokens(t *testing.T) {
+	const secret = "tenant-secret"
+	users := store.NewMemoryUserStore()
+	alice := users.CreateUser(store.WithTenant(context.Background(), "acme"), models.User{Username: "alice"})
+	bob := users.CreateUser(store.WithTenant(context.Background(), "acme"), models.User{Username: "bob"})
+	shareStore := store.NewMemoryItemShareStore()
+	itemStore := store.NewScopedScheduledItemStore(store.NewMemoryScheduledItemStore(), shareStore)
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	handler := NewScheduledItemHandler(itemStore, service)
+	handler.EnableSharing(shareStore, users)
+	router := AuthenticateTenant([]byte(secret), ScopeToUser(NewRouter(handler)))
+
+	serve := func(claims map[string]any, userHeader, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+tenantToken(secret, claims))
+		if userHeader != "" {
+			req.Header.Set("X-User-ID", userHeader)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	aliceClaims := map[string]any{"tenant": "acme", "sub": strconv.FormatInt(alice.ID, 10)}
+	bobClaims := map[string]any{"tenant": "acme", "sub": strconv.FormatInt(bob.ID, 10)}
+
+	rec := serve(aliceClaims, "", http.MethodPost, "/scheduled-items", `{"title":"Water the plants","startsAt":"2030-01-01T08:00:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A token without a user is refused, even when the request names one
+	for _, header := range []string{"", strconv.FormatInt(alice.ID, 10)} {
+		if rec := serve(map[string]any{"tenant": "acme"}, header, http.MethodGet, "/scheduled-items", ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for a token without a user and X-User-ID %q, got %d: %s", header, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Naming alice in X-User-ID doesn't make bob's request hers
+	var items []models.ScheduledItem
+	rec = serve(bobClaims, strconv.FormatInt(alice.ID, 10), http.MethodGet, "/scheduled-items", "")
+	if err := json.NewDecoder(rec.Body).Decode(&items); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected bob's listing, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(items) != 0 {
+		t.Errorf("Expected bob to see none of alice's items, got %+v", items)
+	}
+
+	// Mounting the API doesn't scope requests by the X-User-ID the client sent before the
+	// token replaced it: an administrator's token without a user sees the whole tenant
+	router = NewRouter(Mount(APIPrefix, router))
+	rec = serve(map[string]any{"tenant": "acme", "admin": true}, strconv.FormatInt(bob.ID, 10), http.MethodGet, APIPrefix+"/scheduled-items", "")
+	if err := json.NewDecoder(rec.Body).Decode(&items); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected the administrator's listing, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(items) != 1 {
+		t.Errorf("Expected the administrator to see alice's item, got %+v", items)
+	}
+}
+
+// Test that the live streams only carry the changes and executions of the items their
+// user may see
+func TestLiveStreamsOnlyCarryTheItemsTheirUserMaySee(t *testing.T) {
+	bus := events.NewBus()
+	shareStore := store.NewMemoryItemShareStore()
+	itemStore := store.NewScopedScheduledItemStore(store.NewPublishingScheduledItemStore(store.NewMemoryScheduledItemStore(), bus), shareStore)
+	logStore := store.NewScopedExecutionLogStore(store.NewPublishingExecutionLogStore(store.NewMemoryExecutionLogStore(), bus), itemStore)
+	todoStore := store.NewPublishingTodoItemStore(store.NewMemoryTodoItemStore(), bus)
+	server := httptest.NewServer(ScopeToUser(NewRouter(NewEventHandler(bus, itemStore, func(string) bool { return true }), NewExecutionLogHandler(logStore))))
+	// Cleanups run last first, so the streams are closed before the server waits for them
+	t.Cleanup(server.Close)
+	alice, bob := int64(1), int64(2)
+
+	// The streams report the type of each event they receive and the item it is about
+	type streamed struct {
+		eventType string
+		itemID    int64
+	}
+	itemOf := func(data map[string]any) int64 {
+		if id, ok := data["scheduledItemId"].(float64); ok {
+			return int64(id)
+		}
+		id, _ := data["id"].(float64)
+		return int64(id)
+	}
+	serverSent := func(user int64, path string) <-chan streamed {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("X-User-ID", strconv.FormatInt(user, 10))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to connect to %s: %v", path, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+
+		received := make(chan streamed, 100)
+		go func() {
+			var eventType string
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				line := scanner.Text()
+				if value, ok := strings.CutPrefix(line, "event: "); ok {
+					eventType = value
+				}
+				if value, ok := strings.CutPrefix(line, "data: "); ok {
+					var data map[string]any
+					json.Unmarshal([]byte(value), &data)
+					if event, ok := data["data"].(map[string]any); ok {
+						data = event
+					}
+					received <- streamed{eventType, itemOf(data)}
+				}
+			}
+		}()
+		return received
+	}
+	webSocket := func(user int64) <-chan streamed {
+		config, _ := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", server.URL)
+		config.Header.Set("X-User-ID", strconv.FormatInt(user, 10))
+		conn, err := websocket.DialConfig(config)
+		if err != nil {
+			t.Fatalf("Failed to connect to /ws: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		received := make(chan streamed, 100)
+		go func() {
+			var event struct {
+				Type string         `json:"type"`
+				Data map[string]any `json:"data"`
+			}
+			for websocket.JSON.Receive(conn, &event) == nil {
+				received <- streamed{event.Type, itemOf(event.Data)}
+			}
+		}()
+
+		// The connection subscribes once it is upgraded, so wait for it to receive events
+		for deadline := time.Now().Add(time.Second); ; {
+			todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Ping"})
+			select {
+			case <-received:
+				return received
+			case <-time.After(20 * time.Millisecond):
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Timed out waiting for the WebSocket to subscribe")
+			}
+		}
+	}
+	// until returns the scheduled items of the events received before the given one
+	until := func(received <-chan streamed, eventType string, itemID int64) []int64 {
+		var items []int64
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-received:
+				if event.eventType == eventType && event.itemID == itemID {
+					return items
+				}
+				if !strings.HasPrefix(event.eventType, "todo.") {
+					items = append(items, event.itemID)
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for %s of item %d", eventType, itemID)
+				return nil
+			}
+		}
+	}
+
+	bobEvents := serverSent(bob, "/scheduled-items/events")
+	bobLogs := serverSent(bob, "/execution-logs/stream")
+	bobWebSocket := webSocket(bob)
+	aliceEvents := serverSent(alice, "/scheduled-items/events")
+
+	aliceCtx := store.WithUser(context.Background(), alice)
+	secret := itemStore.CreateScheduledItem(aliceCtx, models.ScheduledItem{Title: "Alice's secret plan", OwnerID: &alice})
+	logStore.CreateExecutionLog(aliceCtx, models.ExecutionLog{ScheduledItemID: secret.ID, Status: "success"})
+	itemStore.UpdateNextExecutionAt(aliceCtx, secret.ID, time.Now().Add(time.Hour))
+	public := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Water the plants"})
+	logStore.CreateExecutionLog(context.Background(), models.ExecutionLog{ScheduledItemID: public.ID, Status: "success"})
+
+	for name, items := range map[string][]int64{
+		"/scheduled-items/events": until(bobEvents, events.ScheduledItemExecuted, public.ID),
+		"/execution-logs/stream":  until(bobLogs, "execution-log", public.ID),
+		"/ws":                     until(bobWebSocket, events.ScheduledItemExecuted, public.ID),
+	} {
+		if slices.Contains(items, secret.ID) {
+			t.Errorf("Expected %s not to send bob alice's item, got events about items %v", name, items)
+		}
+	}
+	if items := until(aliceEvents, events.ScheduledItemExecuted, public.ID); len(items) != 4 || items[0] != secret.ID {
+		t.Errorf("Expected alice to receive the creation, execution and rescheduling of her item, got events about items %v", items)
+	}
+
+	// Only its owner learns that the item is deleted
+	itemStore.DeleteScheduledItem(aliceCtx, secret.ID)
+	itemStore.DeleteScheduledItem(context.Background(), public.ID)
+	for name, items := range map[string][]int64{
+		"/scheduled-items/events": until(bobEvents, events.ScheduledItemDeleted, public.ID),
+		"/ws":                     until(bobWebSocket, events.ScheduledItemDeleted, public.ID),
+	} {
+		if slices.Contains(items, secret.ID) {
+			t.Errorf("Expected %s not to send bob the deletion of alice's item, got events about items %v", name, items)
+		}
+	}
+	if items := until(aliceEvents, events.ScheduledItemDeleted, public.ID); !slices.Equal(items, []int64{secret.ID}) {
+		t.Errorf("Expected alice to receive the deletion of her item, got events about items %v", items)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"periodic-api/internal/models"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"time"
+)
+
+// SchedulerInstance is the latest heartbeat of a scheduler instance and whether it has stalled
+type SchedulerInstance struct {
+	models.SchedulerHeartbeat
+	// Stalled is set when the instance has gone three intervals without a successful tick
+	Stalled bool `json:"stalled" example:"false"`
+}
+
+// SchedulerInstancesStatus reports the scheduler instances that have sent heartbeats
+type SchedulerInstancesStatus struct {
+	// Healthy is set when at least one instance is ticking successfully
+	Healthy   bool                `json:"healthy" example:"true"`
+	Instances []SchedulerInstance `json:"instances"`
+}
+
+// SchedulerInstanceHandler handles HTTP requests for the health of the scheduler instances,
+// as recorded in their heartbeats
+type SchedulerInstanceHandler struct {
+	store store.SchedulerHeartbeatStore
+}
+
+// NewSchedulerInstanceHandler creates a new handler reporting on the heartbeats in the given store
+func NewSchedulerInstanceHandler(store store.SchedulerHeartbeatStore) *SchedulerInstanceHandler {
+	return &SchedulerInstanceHandler{
+		store: store,
+	}
+}
+
+// status returns the instances in the store and whether any of them is healthy
+func (h *SchedulerInstanceHandler) status(r *http.Request) SchedulerInstancesStatus {
+	now := time.Now()
+	status := SchedulerInstancesStatus{Instances: []SchedulerInstance{}}
+	for _, heartbeat := range h.store.GetAllHeartbeats(r.Context()) {
+		stalled := scheduler.Stalled(heartbeat, now)
+		status.Instances = append(status.Instances, SchedulerInstance{SchedulerHeartbeat: heartbeat, Stalled: stalled})
+		status.Healthy = status.Healthy || !stalled
+	}
+	return status
+}
+
+// HandleGetSchedulerInstances handles GET requests to check that the scheduler is running
+// @Summary Get scheduler instances
+// @Description List the scheduler instances that have sent heartbeats, marking those that have gone three intervals without a successful tick as stalled. Responds with 503 when no instance is ticking, so a crashed or wedged scheduler can be alerted on.
+// @Tags scheduler
+// @Produce json
+// @Success 200 {object} SchedulerInstancesStatus
+// @Failure 503 {object} SchedulerInstancesStatus "No scheduler instance is ticking"
+// @Router /scheduler-instances [get]
+func (h *SchedulerInstanceHandler) HandleGetSchedulerInstances(w http.ResponseWriter, r *http.Request) {
+	status := h.status(r)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// HandleGetSchedulerInstanceMetrics handles GET requests to scrape the scheduler instance health
+// @Summary Get scheduler instance metrics
+// @Description Scheduler health in the Prometheus text format: scheduler_up is 1 while at least one instance is ticking, and each instance reports scheduler_instance_stalled and the time of its last successful tick.
+// @Tags scheduler
+// @Produce plain
+// @Success 200 {string} string "Prometheus metrics"
+// @Router /scheduler-instances/metrics [get]
+func (h *SchedulerInstanceHandler) HandleGetSchedulerInstanceMetrics(w http.ResponseWriter, r *http.Request) {
+	status := h.status(r)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprintf(w, "# HELP scheduler_up Whether at least one scheduler instance is ticking successfully.\n# TYPE scheduler_up gauge\nscheduler_up %d\n", boolGauge(status.Healthy))
+	fmt.Fprintf(w, "# HELP scheduler_instance_stalled Whether a scheduler instance has gone three intervals without a successful tick.\n# TYPE scheduler_instance_stalled gauge\n")
+	for _, instance := range status.Instances {
+		fmt.Fprintf(w, "scheduler_instance_stalled{instance=%q} %d\n", instance.InstanceID, boolGauge(instance.Stalled))
+	}
+	fmt.Fprintf(w, "# HELP scheduler_instance_last_success_timestamp_seconds When a scheduler instance last ticked successfully.\n# TYPE scheduler_instance_last_success_timestamp_seconds gauge\n")
+	for _, instance := range status.Instances {
+		if instance.LastSuccessAt != nil {
+			fmt.Fprintf(w, "scheduler_instance_last_success_timestamp_seconds{instance=%q} %d\n", instance.InstanceID, instance.LastSuccessAt.Unix())
+		}
+	}
+}
+
+// RegisterRoutes registers the scheduler instance routes on the given mux
+func (h *SchedulerInstanceHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /scheduler-instances", h.HandleGetSchedulerInstances)
+	mux.HandleFunc("GET /scheduler-instances/metrics", h.HandleGetSchedulerInstanceMetrics)
+}
+
+// boolGauge returns 1 for true and 0 for false
+func boolGauge(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
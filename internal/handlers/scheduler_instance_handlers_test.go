@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchedulerInstancesReportStalledSchedulers(t *testing.T) {
+	heartbeatStore := store.NewMemorySchedulerHeartbeatStore()
+	router := NewRouter(NewSchedulerInstanceHandler(heartbeatStore))
+
+	// The last successful tick was more than three 30 second intervals ago
+	lastSuccess := time.Now().Add(-2 * time.Minute)
+	heartbeatStore.SaveHeartbeat(context.Background(), models.SchedulerHeartbeat{
+		InstanceID:    "crashed",
+		LastTickAt:    time.Now(),
+		LastSuccessAt: &lastSuccess,
+		IntervalMs:    (30 * time.Second).Milliseconds(),
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduler-instances", nil))
+	var status SchedulerInstancesStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if rec.Code != http.StatusServiceUnavailable || status.Healthy || len(status.Instances) != 1 || !status.Instances[0].Stalled {
+		t.Errorf("Expected 503 with the stalled instance, got %d %+v", rec.Code, status)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduler-instances/metrics", nil))
+	for _, line := range []string{"scheduler_up 0", `scheduler_instance_stalled{instance="crashed"} 1`} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, rec.Body.String())
+		}
+	}
+
+	// A second instance ticking within its interval makes the scheduler healthy again
+	now := time.Now()
+	heartbeatStore.SaveHeartbeat(context.Background(), models.SchedulerHeartbeat{
+		InstanceID:    "running",
+		LastTickAt:    now,
+		LastSuccessAt: &now,
+		IntervalMs:    (30 * time.Second).Milliseconds(),
+	})
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduler-instances", nil))
+	status = SchedulerInstancesStatus{}
+	json.NewDecoder(rec.Body).Decode(&status)
+	if rec.Code != http.StatusOK || !status.Healthy || len(status.Instances) != 2 {
+		t.Errorf("Expected 200 with both instances, got %d %+v", rec.Code, status)
+	}
+}
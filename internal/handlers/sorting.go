@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// sortItems orders items by the field named in a sort query parameter, such as
+// "createdAt" for ascending or "-createdAt" for descending order. An empty parameter
+// leaves the items in store order.
+func sortItems[T any](items []T, sortParam string, fields map[string]func(a, b T) int) error {
+	if sortParam == "" {
+		return nil
+	}
+
+	descending := strings.HasPrefix(sortParam, "-")
+	field := strings.TrimPrefix(sortParam, "-")
+	compare, ok := fields[field]
+	if !ok {
+		return fmt.Errorf("invalid sort field '%s'", field)
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		if descending {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+	return nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"strings"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/problem"
+)
+
+// writeListStream writes the entries of stream as they are read from the store, as a JSON
+// array or, when the request asks for it, as CSV, so lists are never held in memory whole.
+// A store error before the first entry is reported as a problem; a later one aborts the
+// response, since the status has already been sent.
+func writeListStream[T any](w http.ResponseWriter, r *http.Request, filename string, columns []csvColumn[T], stream iter.Seq2[T, error]) {
+	next, stop := iter.Pull2(stream)
+	defer stop()
+
+	name := strings.TrimSuffix(filename, ".csv")
+	first, err, ok := next()
+	if ok && err != nil {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to retrieve "+name+": "+err.Error())
+		return
+	}
+	rest := func(yield func(T, error) bool) {
+		for item, err, ok := first, error(nil), ok; ok; item, err, ok = next() {
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+
+	if wantsCSV(w, r) {
+		writeCSVStream(w, filename, rest, columns)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	writeJSONStream(w, name, rest)
+}
+
+// writeJSONStream writes the items of stream as a JSON array, one entry at a time as they
+// are read, flushing every listFlushRows entries. The rest is only written, not flushed,
+// so lists shorter than that are sent whole and still get an ETag.
+func writeJSONStream[T any](w http.ResponseWriter, name string, stream iter.Seq2[T, error]) {
+	flusher, _ := w.(http.Flusher)
+	buffered := bufio.NewWriter(w)
+	flush := func() bool {
+		if err := buffered.Flush(); err != nil {
+			logging.Errorf("Error writing %s: %v", name, err)
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	buffered.WriteByte('[')
+	n := 0
+	for item, err := range stream {
+		if err == nil {
+			var data []byte
+			data, err = json.Marshal(item)
+			if n > 0 {
+				buffered.WriteByte(',')
+			}
+			buffered.Write(data)
+		}
+		if err != nil {
+			flush()
+			abortStream(name, err)
+		}
+
+		n++
+		if n%listFlushRows == 0 && !flush() {
+			return
+		}
+	}
+	buffered.WriteString("]\n")
+	if err := buffered.Flush(); err != nil {
+		logging.Errorf("Error writing %s: %v", name, err)
+	}
+}
+
+// abortStream logs why a streamed list couldn't be finished and aborts the response, so
+// the client sees a broken connection rather than a list that looks complete
+func abortStream(name string, err error) {
+	logging.Errorf("Error streaming %s: %v", name, err)
+	panic(http.ErrAbortHandler)
+}
+
+// sliceStream streams the items of a slice
+func sliceStream[T any](items []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// filterStream streams the items of stream for which keep returns true, along with any error
+func filterStream[T any](stream iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range stream {
+			if err == nil && !keep(item) {
+				continue
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/middleware"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"testing"
+)
+
+// countingStream streams n todo items, failing with err after failAfter of them when err is set
+func countingStream(n int, failAfter int, err error) iter.Seq2[models.TodoItem, error] {
+	return func(yield func(models.TodoItem, error) bool) {
+		for i := range n {
+			if err != nil && i == failAfter {
+				yield(models.TodoItem{}, err)
+				return
+			}
+			if !yield(models.TodoItem{ID: int64(i + 1)}, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestWriteListStreamWritesJSONArray(t *testing.T) {
+	for _, n := range []int{0, 1, listFlushRows + 1} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/todo-items", nil)
+		writeListStream(rec, req, "todo-items.csv", todoItemCSVColumns, countingStream(n, 0, nil))
+
+		var items []models.TodoItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+			t.Fatalf("Expected a JSON array of %d items, got %q: %v", n, rec.Body.String(), err)
+		}
+		if items == nil || len(items) != n || (n > 0 && items[n-1].ID != int64(n)) {
+			t.Errorf("Expected %d items in order, got %+v", n, items)
+		}
+		if n > listFlushRows && !rec.Flushed {
+			t.Errorf("Expected a list of %d items to be flushed while writing", n)
+		}
+	}
+}
+
+func TestWriteListStreamReportsErrors(t *testing.T) {
+	failure := errors.New("connection reset")
+
+	// Errors before the first entry are still problems
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/todo-items", nil)
+	writeListStream(rec, req, "todo-items.csv", todoItemCSVColumns, countingStream(5, 0, failure))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Later ones abort the response
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("Expected the response to be aborted, got %v", recovered)
+		}
+	}()
+	writeListStream(httptest.NewRecorder(), req, "todo-items.csv", todoItemCSVColumns, countingStream(5, 3, failure))
+}
+
+func TestListAnswersIfNoneMatchWithNotModified(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Water the plants"})
+	router := middleware.ETag(NewRouter(NewTodoItemHandler(todoStore)))
+
+	for _, accept := range []string{"application/json", "text/csv"} {
+		req := httptest.NewRequest(http.MethodGet, "/todo-items", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		etag := rec.Header().Get("ETag")
+		if rec.Code != http.StatusOK || etag == "" {
+			t.Fatalf("Expected a %s list with an ETag, got status %d and ETag %q", accept, rec.Code, etag)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/todo-items", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("If-None-Match", etag)
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("Expected 304 for an unchanged %s list, got %d", accept, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TenantTokenSecretFromEnv returns the secret tenant tokens are signed with, from the
+// AUTH_TOKEN_SECRET environment variable. Without a secret, tokens aren't checked and
+// every request is made in the default tenant.
+func TenantTokenSecretFromEnv() []byte {
+	return []byte(os.Getenv("AUTH_TOKEN_SECRET"))
+}
+
+type tenantAdminContextKey struct{}
+
+type unauthenticatedAdminContextKey struct{}
+
+type impersonatorContextKey struct{}
+
+// AuthenticateTenant confines each request to the tenant named in its bearer token, an
+// HS256-signed JWT with a "tenant" claim and optional "exp", "admin" and "sub" claims. A
+// "sub" claim names the user the request is made as: an X-User-ID header sent by the
+// client is dropped and replaced by the claim, so only the token decides who the user is.
+// Requests without a valid token are refused with 401. Without a secret every request is
+// in the default tenant, and X-User-ID names the user.
+func AuthenticateTenant(secret []byte, next http.Handler) http.Handler {
+	if len(secret) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			problem.Write(w, r, http.StatusUnauthorized, "Missing bearer token")
+			return
+		}
+
+		claims, err := parseTenantToken(strings.TrimSpace(token), secret, time.Now())
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			problem.Write(w, r, http.StatusUnauthorized, "Invalid bearer token: "+err.Error())
+			return
+		}
+
+		ctx := store.WithTenant(r.Context(), claims.Tenant)
+		ctx = context.WithValue(ctx, tenantAdminContextKey{}, claims.Admin)
+		if claims.Impersonator != "" {
+			ctx = context.WithValue(ctx, impersonatorContextKey{}, claims.Impersonator)
+		}
+		r = r.WithContext(ctx)
+		r.Header = r.Header.Clone()
+		r.Header.Del(userIDHeader)
+		if claims.Subject != "" {
+			r.Header.Set(userIDHeader, claims.Subject)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// tenantClaims are the claims of a tenant token that requests are authorized by
+type tenantClaims struct {
+	Tenant string `json:"tenant"`
+	// Admin lets the token's holder manage every tenant, such as adjusting their limits
+	Admin bool `json:"admin"`
+	// Subject is the user the token's holder acts as
+	Subject string `json:"sub,omitempty"`
+	// Impersonator is the administrator a support token was issued to, acting as Subject
+	Impersonator string `json:"impersonator,omitempty"`
+	ExpiresAt    *int64 `json:"exp"`
+}
+
+// parseTenantToken verifies a tenant token signed with secret and returns its claims
+func parseTenantToken(token string, secret []byte, now time.Time) (tenantClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return tenantClaims{}, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return tenantClaims{}, errors.New("unsupported signing algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return tenantClaims{}, errors.New("malformed signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return tenantClaims{}, errors.New("signature mismatch")
+	}
+
+	var claims tenantClaims
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return tenantClaims{}, errors.New("malformed claims")
+	}
+	if claims.ExpiresAt != nil && !now.Before(time.Unix(*claims.ExpiresAt, 0)) {
+		return tenantClaims{}, errors.New("token expired")
+	}
+	if strings.TrimSpace(claims.Tenant) == "" {
+		return tenantClaims{}, errors.New("no tenant claim")
+	}
+	return claims, nil
+}
+
+// signTenantToken returns a tenant token with the claims, signed with secret
+func signTenantToken(claims tenantClaims, secret []byte) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// IssueAdminToken returns a token with the admin claim for making requests as a user of
+// tenant, signed with secret, for bootstrapping a deployment's first administrator. It
+// expires at expiresAt, or never when that is nil.
+func IssueAdminToken(secret []byte, tenant string, userID int64, expiresAt *time.Time) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("no secret to sign the token with")
+	}
+	claims := tenantClaims{
+		Tenant:  tenant,
+		Admin:   true,
+		Subject: strconv.FormatInt(userID, 10),
+	}
+	if expiresAt != nil {
+		expiry := expiresAt.Unix()
+		claims.ExpiresAt = &expiry
+	}
+	return signTenantToken(claims, secret)
+}
+
+// decodeTokenPart decodes a base64url-encoded JSON part of a token
+func decodeTokenPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// UnauthenticatedAdminFromEnv reports whether ALLOW_UNAUTHENTICATED_ADMIN lets requests
+// manage every tenant when AUTH_TOKEN_SECRET isn't set, for local development
+func UnauthenticatedAdminFromEnv() bool {
+	return strings.ToLower(os.Getenv("ALLOW_UNAUTHENTICATED_ADMIN")) == "true"
+}
+
+// AllowUnauthenticatedAdmin lets every request manage every tenant, for local development
+// without tenant tokens. It must not wrap AuthenticateTenant's handler with a secret, as
+// tokens without the admin claim would then manage every tenant too.
+func AllowUnauthenticatedAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), unauthenticatedAdminContextKey{}, true)))
+	})
+}
+
+// authenticated reports whether a request was made with a valid tenant token
+func authenticated(r *http.Request) bool {
+	_, ok := r.Context().Value(tenantAdminContextKey{}).(bool)
+	return ok
+}
+
+// tenantAdmin reports whether a request may manage every tenant: when its token has the
+// admin claim, or when AllowUnauthenticatedAdmin lets it without tokens. Requests aren't
+// administrators otherwise, so the admin endpoints stay closed when tokens aren't checked.
+func tenantAdmin(r *http.Request) bool {
+	if admin, _ := r.Context().Value(tenantAdminContextKey{}).(bool); admin {
+		return true
+	}
+	allowed, _ := r.Context().Value(unauthenticatedAdminContextKey{}).(bool)
+	return allowed
+}
+
+// authorizeAdmin checks that a request may manage every tenant, writing the problem and
+// returning false otherwise
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if tenantAdmin(r) {
+		return true
+	}
+	problem.Write(w, r, http.StatusForbidden, "Only administrators can manage tenants")
+	return false
+}
+
+// requestImpersonator returns the administrator a request is made by on behalf of its
+// user, or "" when it isn't made with a support token
+func requestImpersonator(r *http.Request) string {
+	impersonator, _ := r.Context().Value(impersonatorContextKey{}).(string)
+	return impersonator
+}
+
+// sameTenant reports whether data of tenant may be sent in response to a request. Data
+// without a tenant belongs to the default tenant.
+func sameTenant(r *http.Request, tenant string) bool {
+	if tenant == "" {
+		tenant = store.DefaultTenant
+	}
+	return tenant == store.TenantFromContext(r.Context())
+}
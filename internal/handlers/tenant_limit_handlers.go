@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strconv"
+	"strings"
+)
+
+// TenantLimitsFromEnv returns the limits of tenants without limits of their own, from the
+// TENANT_MAX_SCHEDULED_ITEMS, TENANT_MAX_DAILY_GENERATIONS and TENANT_MAX_WEBHOOKS
+// environment variables. Unset and invalid values mean unlimited; invalid ones are logged.
+func TenantLimitsFromEnv() models.TenantLimits {
+	limit := func(name string) int {
+		value := os.Getenv(name)
+		if value == "" {
+			return 0
+		}
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			logging.Warnf("Invalid %s %q, using no limit", name, value)
+			return 0
+		}
+		return limit
+	}
+	return models.TenantLimits{
+		MaxScheduledItems:   limit("TENANT_MAX_SCHEDULED_ITEMS"),
+		MaxDailyGenerations: limit("TENANT_MAX_DAILY_GENERATIONS"),
+		MaxWebhooks:         limit("TENANT_MAX_WEBHOOKS"),
+	}
+}
+
+// TenantLimitHandler handles HTTP requests for the limits of tenants, and looks them up
+// for the handlers that enforce them
+type TenantLimitHandler struct {
+	store    store.TenantLimitStore
+	defaults models.TenantLimits
+}
+
+// NewTenantLimitHandler creates a new handler with the given store. Tenants without limits
+// of their own in the store get defaults.
+func NewTenantLimitHandler(store store.TenantLimitStore, defaults models.TenantLimits) *TenantLimitHandler {
+	return &TenantLimitHandler{
+		store:    store,
+		defaults: defaults,
+	}
+}
+
+// limits returns the limits of a tenant: its own, or the defaults
+func (h *TenantLimitHandler) limits(ctx context.Context, tenant string) models.TenantLimits {
+	if limits, exists := h.store.GetTenantLimits(ctx, tenant); exists {
+		return limits
+	}
+	limits := h.defaults
+	limits.Tenant = tenant
+	return limits
+}
+
+// requestLimits returns the limits of the tenant making a request
+func (h *TenantLimitHandler) requestLimits(r *http.Request) models.TenantLimits {
+	return h.limits(r.Context(), store.TenantFromContext(r.Context()))
+}
+
+// writeLimitExceeded writes the 403 problem for a tenant that already has as many of
+// something as its limit allows
+func writeLimitExceeded(w http.ResponseWriter, r *http.Request, limit int, what string) {
+	d := problem.New(http.StatusForbidden, fmt.Sprintf("Tenant limit of %d %s reached", limit, what))
+	d.Type = problem.TypeLimitExceeded
+	d.Write(w, r)
+}
+
+// validateTenantLimits checks the limits from a request
+func validateTenantLimits(limits models.TenantLimits) []problem.FieldError {
+	var errs []problem.FieldError
+	for field, limit := range map[string]int{
+		"maxScheduledItems":   limits.MaxScheduledItems,
+		"maxDailyGenerations": limits.MaxDailyGenerations,
+		"maxWebhooks":         limits.MaxWebhooks,
+	} {
+		if limit < 0 {
+			errs = append(errs, problem.FieldError{Field: field, Message: "must be 0 (unlimited) or more"})
+		}
+	}
+	return errs
+}
+
+// HandleGetAllTenantLimits handles GET requests to list the limits adjusted for tenants
+// @Summary List tenant limits
+// @Description List the limits of every tenant that has limits of its own, ordered by tenant. Other tenants get the deployment's defaults. Requires a tenant token with the admin claim when tenant tokens are checked.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.TenantLimits
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Router /admin/tenant-limits [get]
+func (h *TenantLimitHandler) HandleGetAllTenantLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.GetAllTenantLimits(r.Context()))
+}
+
+// HandleGetTenantLimits handles GET requests to view the limits of a tenant
+// @Summary Get the limits of a tenant
+// @Description Get the limits a tenant is held to: its own, or the deployment's defaults, which have no updatedAt. 0 means unlimited.
+// @Tags admin
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Success 200 {object} models.TenantLimits
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Router /admin/tenant-limits/{tenant} [get]
+func (h *TenantLimitHandler) HandleGetTenantLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.limits(r.Context(), r.PathValue("tenant")))
+}
+
+// HandleSaveTenantLimits handles PUT requests to adjust the limits of a tenant
+// @Summary Adjust the limits of a tenant
+// @Description Replace the limits of a tenant: the scheduled items and webhooks it may have and the generation requests it may make per UTC day. 0 means unlimited. Existing items and webhooks over a lowered limit are kept, but no more can be created.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tenant path string true "Tenant"
+// @Param limits body models.TenantLimits true "Limits of the tenant"
+// @Success 200 {object} models.TenantLimits
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 500 {object} problem.Details "Failed to save tenant limits"
+// @Router /admin/tenant-limits/{tenant} [put]
+func (h *TenantLimitHandler) HandleSaveTenantLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+	tenant := strings.TrimSpace(r.PathValue("tenant"))
+	if tenant == "" {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid tenant")
+		return
+	}
+
+	var limits models.TenantLimits
+	if err := json.NewDecoder(r.Body).Decode(&limits); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := validateTenantLimits(limits); len(errs) > 0 {
+		problem.Validation("Invalid tenant limits", errs...).Write(w, r)
+		return
+	}
+
+	limits.Tenant = tenant
+	saved, ok := h.store.SaveTenantLimits(r.Context(), limits)
+	if !ok {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to save tenant limits")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// HandleDeleteTenantLimits handles DELETE requests to return a tenant to the default limits
+// @Summary Reset the limits of a tenant
+// @Description Remove the limits of a tenant's own, so it gets the deployment's defaults
+// @Tags admin
+// @Param tenant path string true "Tenant"
+// @Success 204 "No content"
+// @Failure 403 {object} problem.Details "Only administrators can manage tenants"
+// @Failure 404 {object} problem.Details "Tenant has no limits of its own"
+// @Router /admin/tenant-limits/{tenant} [delete]
+func (h *TenantLimitHandler) HandleDeleteTenantLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	if !h.store.DeleteTenantLimits(r.Context(), r.PathValue("tenant")) {
+		problem.Write(w, r, http.StatusNotFound, "Tenant has no limits of its own")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegisterRoutes registers the tenant limit routes on the given mux
+func (h *TenantLimitHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /admin/tenant-limits", h.HandleGetAllTenantLimits)
+	mux.HandleFunc("GET /admin/tenant-limits/{tenant}", h.HandleGetTenantLimits)
+	mux.HandleFunc("PUT /admin/tenant-limits/{tenant}", h.HandleSaveTenantLimits)
+	mux.HandleFunc("DELETE /admin/tenant-limits/{tenant}", h.HandleDeleteTenantLimits)
+}
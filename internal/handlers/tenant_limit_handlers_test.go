@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/scheduler"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestTenantLimitsAreEnforcedAndAdjustable(t *testing.T) {
+	const secret = "tenant-secret"
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := scheduler.NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	itemHandler := NewScheduledItemHandler(itemStore, service)
+	webhookHandler := NewWebhookHandler(store.NewMemoryWebhookStore())
+	limitHandler := NewTenantLimitHandler(store.NewMemoryTenantLimitStore(), models.TenantLimits{MaxScheduledItems: 5})
+	itemHandler.EnableTenantLimits(limitHandler)
+	webhookHandler.EnableTenantLimits(limitHandler)
+	router := AuthenticateTenant([]byte(secret), NewRouter(itemHandler, webhookHandler, limitHandler))
+
+	serve := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	admin := tenantToken(secret, map[string]any{"tenant": "acme", "admin": true})
+	acme := tenantToken(secret, map[string]any{"tenant": "acme", "sub": "1"})
+	globex := tenantToken(secret, map[string]any{"tenant": "globex", "sub": "2"})
+
+	// Only administrators can view or adjust limits
+	if rec := serve(acme, http.MethodPut, "/admin/tenant-limits/acme", `{"maxScheduledItems":100}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected status 403 for a tenant without the admin claim, got %d", rec.Code)
+	}
+	var limits models.TenantLimits
+	json.NewDecoder(serve(admin, http.MethodGet, "/admin/tenant-limits/acme", "").Body).Decode(&limits)
+	if limits.Tenant != "acme" || limits.MaxScheduledItems != 5 || limits.UpdatedAt != nil {
+		t.Fatalf("Expected the default limits, got %+v", limits)
+	}
+	if rec := serve(admin, http.MethodPut, "/admin/tenant-limits/acme", `{"maxScheduledItems":-1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a negative limit, got %d", rec.Code)
+	}
+	rec := serve(admin, http.MethodPut, "/admin/tenant-limits/acme", `{"maxScheduledItems":1,"maxWebhooks":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Creating more than the limit allows is refused
+	item := `{"title":"Ship the rockets","startsAt":"2030-01-01T00:00:00Z"}`
+	if rec := serve(acme, http.MethodPost, "/scheduled-items", item); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serve(acme, http.MethodPost, "/scheduled-items", item)
+	var details problem.Details
+	json.NewDecoder(rec.Body).Decode(&details)
+	if rec.Code != http.StatusForbidden || details.Type != problem.TypeLimitExceeded {
+		t.Errorf("Expected a limit problem, got %d: %+v", rec.Code, details)
+	}
+	webhook := `{"url":"https://example.com/hooks","eventTypes":["todo.created"]}`
+	if rec := serve(acme, http.MethodPost, "/webhooks", webhook); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(acme, http.MethodPost, "/webhooks", webhook); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a webhook over the limit, got %d", rec.Code)
+	}
+
+	// Other tenants have their own limits
+	if rec := serve(globex, http.MethodPost, "/scheduled-items", item); rec.Code != http.StatusCreated {
+		t.Errorf("Expected another tenant to be allowed, got %d", rec.Code)
+	}
+
+	// Resetting the limits returns the tenant to the defaults
+	if rec := serve(admin, http.MethodDelete, "/admin/tenant-limits/acme", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+	if rec := serve(acme, http.MethodPost, "/scheduled-items", item); rec.Code != http.StatusCreated {
+		t.Errorf("Expected the default limit to allow another item, got %d", rec.Code)
+	}
+}
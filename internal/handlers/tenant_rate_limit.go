@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"os"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TenantRateLimit is how many API requests each tenant may make
+type TenantRateLimit struct {
+	// PerSecond is the sustained rate of requests a tenant may make
+	PerSecond float64
+	// Burst is how many requests a tenant may make at once after being idle
+	Burst int
+}
+
+// TenantRateLimitFromEnv returns the rate limit of each tenant from the TENANT_RATE_LIMIT
+// (requests per second) and TENANT_RATE_BURST environment variables, and whether requests
+// are limited. The burst defaults to one second's worth of requests. Invalid values are
+// logged and leave requests unlimited.
+func TenantRateLimitFromEnv() (TenantRateLimit, bool) {
+	value := os.Getenv("TENANT_RATE_LIMIT")
+	if value == "" {
+		return TenantRateLimit{}, false
+	}
+	perSecond, err := strconv.ParseFloat(value, 64)
+	if err != nil || perSecond <= 0 || math.IsInf(perSecond, 0) {
+		logging.Warnf("Invalid TENANT_RATE_LIMIT %q, not limiting requests", value)
+		return TenantRateLimit{}, false
+	}
+
+	limit := TenantRateLimit{PerSecond: perSecond, Burst: max(1, int(math.Ceil(perSecond)))}
+	if value := os.Getenv("TENANT_RATE_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil || burst < 1 {
+			logging.Warnf("Invalid TENANT_RATE_BURST %q, using %d", value, limit.Burst)
+		} else {
+			limit.Burst = burst
+		}
+	}
+	return limit, true
+}
+
+// tenantBucket is the token bucket of a tenant's requests
+type tenantBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// TenantRateLimiter keeps a token bucket per tenant, so a tenant making too many requests
+// is slowed down without affecting the others. Its limit can be changed while it serves
+// requests, as when the configuration is reloaded.
+type TenantRateLimiter struct {
+	sync.Mutex
+	limit   TenantRateLimit
+	buckets map[string]*tenantBucket
+	now     func() time.Time
+	next    http.Handler
+	// taken counts the requests since idle buckets were last dropped
+	taken int
+}
+
+// SetLimit changes the rate limit of each tenant; a zero limit stops limiting requests.
+// Tenants keep the tokens they have left, up to the new burst.
+func (l *TenantRateLimiter) SetLimit(limit TenantRateLimit) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.limit = limit
+	if limit.PerSecond <= 0 {
+		clear(l.buckets)
+	}
+}
+
+// take spends one of the tenant's tokens, returning how long to wait before retrying
+// when there are none left
+func (l *TenantRateLimiter) take(tenant string) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.limit.PerSecond <= 0 {
+		return true, 0
+	}
+	now := l.now()
+	if l.taken++; l.taken >= 1000 {
+		l.forget(now)
+		l.taken = 0
+	}
+
+	bucket, exists := l.buckets[tenant]
+	if !exists {
+		bucket = &tenantBucket{tokens: float64(l.limit.Burst), updatedAt: now}
+		l.buckets[tenant] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+elapsed*l.limit.PerSecond)
+	bucket.updatedAt = now
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / l.limit.PerSecond * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// forget drops the buckets that have refilled, since those tenants are back where they
+// started, so the buckets don't grow with every tenant seen
+func (l *TenantRateLimiter) forget(now time.Time) {
+	for tenant, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*l.limit.PerSecond >= float64(l.limit.Burst) {
+			delete(l.buckets, tenant)
+		}
+	}
+}
+
+// ServeHTTP refuses the request with 429 and a Retry-After header when its tenant has no
+// tokens left, and passes it on otherwise
+func (l *TenantRateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	allowed, wait := l.take(store.TenantFromContext(r.Context()))
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		details := problem.New(http.StatusTooManyRequests, "Too many requests for this tenant, retry later")
+		details.Type = problem.TypeRateLimited
+		details.Write(w, r)
+		return
+	}
+	l.next.ServeHTTP(w, r)
+}
+
+// RateLimitTenants refuses the requests of a tenant making more than the limit allows with
+// 429 and a Retry-After header, so one busy tenant can't crowd out the others. A zero limit
+// lets every request through until SetLimit sets one. It must run after
+// AuthenticateTenant, which sets the tenant of each request.
+func RateLimitTenants(limit TenantRateLimit, next http.Handler) *TenantRateLimiter {
+	return rateLimitTenants(limit, time.Now, next)
+}
+
+// rateLimitTenants is RateLimitTenants with the clock the buckets fill by
+func rateLimitTenants(limit TenantRateLimit, now func() time.Time, next http.Handler) *TenantRateLimiter {
+	return &TenantRateLimiter{
+		limit:   limit,
+		buckets: make(map[string]*tenantBucket),
+		now:     now,
+		next:    next,
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTenantsSlowsDownOnlyTheBusyTenant(t *testing.T) {
+	const secret = "tenant-secret"
+	now := time.Now()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router := AuthenticateTenant([]byte(secret), rateLimitTenants(TenantRateLimit{PerSecond: 1, Burst: 2}, func() time.Time { return now }, ok))
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/scheduled-items", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	acme := tenantToken(secret, map[string]any{"tenant": "acme"})
+	globex := tenantToken(secret, map[string]any{"tenant": "globex"})
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(acme); rec.Code != http.StatusOK {
+			t.Fatalf("Expected the burst to be allowed, got %d", rec.Code)
+		}
+	}
+	rec := serve(acme)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Expected status 429 retrying after a second, got %d with Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := serve(globex); rec.Code != http.StatusOK {
+		t.Errorf("Expected another tenant to be unaffected, got %d", rec.Code)
+	}
+
+	now = now.Add(time.Second)
+	if rec := serve(acme); rec.Code != http.StatusOK {
+		t.Errorf("Expected a request to be allowed once the bucket refilled, got %d", rec.Code)
+	}
+}
+
+func TestTenantRateLimiterFollowsReloadedLimit(t *testing.T) {
+	const secret = "tenant-secret"
+	now := time.Now()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	t.Setenv("TENANT_RATE_LIMIT", "")
+	limit, _ := TenantRateLimitFromEnv()
+	limiter := rateLimitTenants(limit, func() time.Time { return now }, ok)
+	router := AuthenticateTenant([]byte(secret), limiter)
+	acme := tenantToken(secret, map[string]any{"tenant": "acme"})
+
+	serve := func(requests int) int {
+		allowed := 0
+		for i := 0; i < requests; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/scheduled-items", nil)
+			req.Header.Set("Authorization", "Bearer "+acme)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code == http.StatusOK {
+				allowed++
+			}
+		}
+		return allowed
+	}
+
+	// Unset, requests aren't limited until a reload sets the limit
+	if allowed := serve(10); allowed != 10 {
+		t.Errorf("Expected every request to be allowed without a limit, got %d of 10", allowed)
+	}
+	t.Setenv("TENANT_RATE_LIMIT", "2")
+	t.Setenv("TENANT_RATE_BURST", "3")
+	limit, _ = TenantRateLimitFromEnv()
+	limiter.SetLimit(limit)
+	if allowed := serve(10); allowed != 3 {
+		t.Errorf("Expected the reloaded burst of 3 to be allowed, got %d of 10", allowed)
+	}
+
+	// Removing the limit lets requests through again
+	t.Setenv("TENANT_RATE_LIMIT", "")
+	limit, _ = TenantRateLimitFromEnv()
+	limiter.SetLimit(limit)
+	if allowed := serve(10); allowed != 10 {
+		t.Errorf("Expected every request to be allowed once the limit was removed, got %d of 10", allowed)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tenantToken signs a tenant token like an identity provider would
+func tenantToken(secret string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestTenantsOnlySeeTheirOwnData(t *testing.T) {
+	const secret = "tenant-secret"
+	router := AuthenticateTenant([]byte(secret), NewRouter(NewTodoItemHandler(store.NewMemoryTodoItemStore())))
+
+	serve := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	acme := tenantToken(secret, map[string]any{"tenant": "acme", "sub": "1", "exp": time.Now().Add(time.Hour).Unix()})
+	globex := tenantToken(secret, map[string]any{"tenant": "globex", "sub": "2"})
+
+	// Requests without a valid token are refused
+	for name, token := range map[string]string{
+		"missing":        "",
+		"malformed":      "not-a-token",
+		"wrong secret":   tenantToken("other-secret", map[string]any{"tenant": "acme"}),
+		"expired":        tenantToken(secret, map[string]any{"tenant": "acme", "exp": time.Now().Add(-time.Minute).Unix()}),
+		"without tenant": tenantToken(secret, map[string]any{"sub": "alice"}),
+	} {
+		if rec := serve(token, http.MethodGet, "/todo-items", ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for a %s token, got %d", name, rec.Code)
+		}
+	}
+
+	rec := serve(acme, http.MethodPost, "/todo-items", `{"text":"Ship the rockets"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var todo models.TodoItem
+	if err := json.NewDecoder(rec.Body).Decode(&todo); err != nil {
+		t.Fatalf("Failed to decode todo item: %v", err)
+	}
+	path := fmt.Sprintf("/todo-items/%d", todo.ID)
+
+	// Another tenant can neither list, read, change nor delete the todo
+	var listed []models.TodoItem
+	if err := json.NewDecoder(serve(globex, http.MethodGet, "/todo-items", "").Body).Decode(&listed); err != nil {
+		t.Fatalf("Failed to decode todo items: %v", err)
+	}
+	if len(listed) != 0 {
+		t.Fatalf("Expected globex to see no todos, got %+v", listed)
+	}
+	if rec := serve(globex, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another tenant's todo, got %d", rec.Code)
+	}
+	if rec := serve(globex, http.MethodPut, path, `{"text":"Sabotage","checked":true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 when updating another tenant's todo, got %d", rec.Code)
+	}
+	if rec := serve(globex, http.MethodDelete, path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 when deleting another tenant's todo, got %d", rec.Code)
+	}
+
+	if rec := serve(acme, http.MethodGet, path, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected acme to still see its todo, got %d", rec.Code)
+	}
+}
+
+func TestIssueAdminToken(t *testing.T) {
+	secret := []byte("tenant-secret")
+	expiresAt := time.Now().Add(time.Hour)
+
+	token, err := IssueAdminToken(secret, "acme", 7, &expiresAt)
+	if err != nil {
+		t.Fatalf("IssueAdminToken failed: %v", err)
+	}
+	claims, err := parseTenantToken(token, secret, time.Now())
+	if err != nil {
+		t.Fatalf("Expected the token to be valid: %v", err)
+	}
+	if claims.Tenant != "acme" || !claims.Admin || claims.Subject != "7" {
+		t.Errorf("Expected an admin token for user 7 of acme, got %+v", claims)
+	}
+	if claims.ExpiresAt == nil || *claims.ExpiresAt != expiresAt.Unix() {
+		t.Errorf("Expected the token to expire at %d, got %v", expiresAt.Unix(), claims.ExpiresAt)
+	}
+	if _, err := parseTenantToken(token, secret, expiresAt.Add(time.Second)); err == nil {
+		t.Error("Expected the token to be refused once expired")
+	}
+
+	token, err = IssueAdminToken(secret, "acme", 7, nil)
+	if err != nil {
+		t.Fatalf("IssueAdminToken failed: %v", err)
+	}
+	if claims, err := parseTenantToken(token, secret, time.Now().AddDate(10, 0, 0)); err != nil || claims.ExpiresAt != nil {
+		t.Errorf("Expected a token without expiry, got %+v, %v", claims, err)
+	}
+
+	if _, err := IssueAdminToken(nil, "acme", 7, nil); err == nil {
+		t.Error("Expected an error without a secret")
+	}
+}
+
+func TestAdminEndpointsAreClosedWithoutTokens(t *testing.T) {
+	limits := NewTenantLimitHandler(store.NewMemoryTenantLimitStore(), models.TenantLimits{})
+	router := NewRouter(limits)
+	get := func(handler http.Handler) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/tenant-limits/acme", nil))
+		return rec.Code
+	}
+
+	// Without a secret nobody is an administrator...
+	if code := get(AuthenticateTenant(nil, router)); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 without tenant tokens, got %d", code)
+	}
+	// ...unless local development opts in
+	if code := get(AllowUnauthenticatedAdmin(AuthenticateTenant(nil, router))); code != http.StatusOK {
+		t.Errorf("Expected status 200 with unauthenticated administration allowed, got %d", code)
+	}
+}
@@ -1,16 +1,39 @@
 package handlers
 
 import (
+	"cmp"
 	"encoding/json"
+	"io"
 	"net/http"
 	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
 	"periodic-api/internal/store"
+	"slices"
 	"strconv"
 )
 
+// todoItemSortFields are the fields todo items can be listed by
+var todoItemSortFields = map[string]func(a, b models.TodoItem) int{
+	"id":        func(a, b models.TodoItem) int { return cmp.Compare(a.ID, b.ID) },
+	"createdAt": func(a, b models.TodoItem) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt": func(a, b models.TodoItem) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// todoItemCSVColumns are the columns of todo items listed as CSV
+var todoItemCSVColumns = []csvColumn[models.TodoItem]{
+	{"id", func(item models.TodoItem) string { return strconv.FormatInt(item.ID, 10) }},
+	{"text", func(item models.TodoItem) string { return item.Text }},
+	{"checked", func(item models.TodoItem) string { return strconv.FormatBool(item.Checked) }},
+	{"organizationId", func(item models.TodoItem) string { return csvOptional(item.OrganizationID, formatID) }},
+	{"createdAt", func(item models.TodoItem) string { return csvTime(item.CreatedAt) }},
+	{"updatedAt", func(item models.TodoItem) string { return csvTime(item.UpdatedAt) }},
+}
+
 // TodoItemHandler handles HTTP requests for todo items
 type TodoItemHandler struct {
 	store store.TodoItemStore
+	// organizations checks the organizations todos are assigned to; nil skips the check
+	organizations store.OrganizationStore
 }
 
 // NewTodoItemHandler creates a new handler with the given store
@@ -20,6 +43,11 @@ func NewTodoItemHandler(store store.TodoItemStore) *TodoItemHandler {
 	}
 }
 
+// EnableOrganizations checks that the organizations todos are assigned to exist in organizations
+func (h *TodoItemHandler) EnableOrganizations(organizations store.OrganizationStore) {
+	h.organizations = organizations
+}
+
 // HandleCreateTodoItem handles POST requests to create a new todo item
 // @Summary Create a todo item
 // @Description Create a new todo item with the given details
@@ -28,21 +56,21 @@ func NewTodoItemHandler(store store.TodoItemStore) *TodoItemHandler {
 // @Produce json
 // @Param item body models.TodoItem true "Todo item to create"
 // @Success 201 {object} models.TodoItem
-// @Failure 400 {string} string "Bad request"
+// @Failure 400 {object} problem.Details "Bad request"
 // @Router /todo-items [post]
 func (h *TodoItemHandler) HandleCreateTodoItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	var item models.TodoItem
+	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
-	var item models.TodoItem
-	if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if errs := organizationErrors(r.Context(), h.organizations, item.OrganizationID); len(errs) > 0 {
+		problem.Validation("Invalid todo item", errs...).Write(w, r)
 		return
 	}
 
-	createdItem := h.store.CreateTodoItem(item)
+	createdItem := h.store.CreateTodoItem(r.Context(), item)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -56,25 +84,19 @@ func (h *TodoItemHandler) HandleCreateTodoItem(w http.ResponseWriter, r *http.Re
 // @Produce json
 // @Param id path int true "Todo item ID"
 // @Success 200 {object} models.TodoItem
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Todo item not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Todo item not found"
 // @Router /todo-items/{id} [get]
 func (h *TodoItemHandler) HandleGetTodoItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/todo-items/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	item, exists := h.store.GetTodoItem(id)
+	item, exists := h.store.GetTodoItem(r.Context(), id)
 	if !exists {
-		http.Error(w, "Todo item not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, "Todo item not found")
 		return
 	}
 
@@ -84,18 +106,60 @@ func (h *TodoItemHandler) HandleGetTodoItem(w http.ResponseWriter, r *http.Reque
 
 // HandleGetAllTodoItems handles GET requests to retrieve all todo items
 // @Summary Get all todo items
-// @Description Retrieve all todo items from the store
+// @Description Retrieve all todo items from the store, as JSON or, with Accept: text/csv or ?format=csv, as CSV with one row per item. With the cursor parameter, the items are returned a page at a time in ID order, and the cursor of the next page is sent in the X-Next-Cursor header.
 // @Tags todo-items
-// @Produce json
+// @Produce json,text/csv
+// @Param sort query string false "Sort by id, createdAt or updatedAt; prefix with - for descending order"
+// @Param checked query bool false "Only list the items that are checked (true) or open (false)"
+// @Param cursor query string false "Page by cursor: empty for the first page, or the cursor of the page to return"
+// @Param limit query int false "Number of items per page when paging by cursor" default(50)
+// @Param format query string false "Response format, overriding the Accept header" Enums(json, csv)
+// @Param organizationId query int false "Only list the todo items of this organization"
 // @Success 200 {array} models.TodoItem
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} problem.Details "Invalid sort field, checked filter, organization ID, limit or cursor"
+// @Failure 500 {object} problem.Details "Failed to retrieve page"
 // @Router /todo-items [get]
 func (h *TodoItemHandler) HandleGetAllTodoItems(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	ctx, ok := organizationScope(w, r)
+	if !ok {
 		return
 	}
+	// Dashboards poll for the open items, which the cached listing serves
+	keep := func(models.TodoItem) bool { return true }
+	if value := r.URL.Query().Get("checked"); value != "" {
+		checked, err := strconv.ParseBool(value)
+		if err != nil {
+			problem.Validation("Invalid query parameter", problem.FieldError{Field: "checked", Message: "must be true or false"}).Write(w, r)
+			return
+		}
+		keep = func(item models.TodoItem) bool { return item.Checked == checked }
+	}
+	discard := func(item models.TodoItem) bool { return !keep(item) }
 
-	items := h.store.GetAllTodoItems()
+	if writeCursorPage(w, r, "todo-items.csv", todoItemCSVColumns, func(limit int, cursor *store.TodoItemCursor) ([]models.TodoItem, *store.TodoItemCursor, error) {
+		items, next, err := h.store.GetTodoItemsPage(ctx, limit, cursor)
+		return slices.DeleteFunc(items, discard), next, err
+	}) {
+		return
+	}
+
+	// Lists in store order are streamed as they are read; sorting needs them whole
+	if r.URL.Query().Get("sort") == "" {
+		writeListStream(w, r, "todo-items.csv", todoItemCSVColumns, filterStream(h.store.StreamTodoItems(ctx), keep))
+		return
+	}
+
+	items := slices.DeleteFunc(h.store.GetAllTodoItems(ctx), discard)
+	if err := sortItems(items, r.URL.Query().Get("sort"), todoItemSortFields); err != nil {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "sort", Message: err.Error()}).Write(w, r)
+		return
+	}
+
+	if wantsCSV(w, r) {
+		writeCSV(w, "todo-items.csv", items, todoItemCSVColumns)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(items)
@@ -110,31 +174,82 @@ func (h *TodoItemHandler) HandleGetAllTodoItems(w http.ResponseWriter, r *http.R
 // @Param id path int true "Todo item ID"
 // @Param item body models.TodoItem true "Updated todo item"
 // @Success 200 {object} models.TodoItem
-// @Failure 400 {string} string "Bad request"
-// @Failure 404 {string} string "Todo item not found"
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Todo item not found"
 // @Router /todo-items/{id} [put]
 func (h *TodoItemHandler) HandleUpdateTodoItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var updatedItem models.TodoItem
+	if err := json.NewDecoder(r.Body).Decode(&updatedItem); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
-	idStr := r.URL.Path[len("/todo-items/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	if errs := organizationErrors(r.Context(), h.organizations, updatedItem.OrganizationID); len(errs) > 0 {
+		problem.Validation("Invalid todo item", errs...).Write(w, r)
+		return
+	}
+
+	item, exists := h.store.UpdateTodoItem(r.Context(), id, updatedItem)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Todo item not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// HandlePatchTodoItem handles PATCH requests to partially update a todo item
+// @Summary Partially update a todo item
+// @Description Apply a JSON Merge Patch (RFC 7386) to a todo item, for example {"checked": true}; only the fields in the patch change
+// @Tags todo-items
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path int true "Todo item ID"
+// @Param patch body object true "JSON Merge Patch of todo item fields"
+// @Success 200 {object} models.TodoItem
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Todo item not found"
+// @Router /todo-items/{id} [patch]
+func (h *TodoItemHandler) HandlePatchTodoItem(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	var updatedItem models.TodoItem
-	if err := json.NewDecoder(r.Body).Decode(&updatedItem); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	existing, exists := h.store.GetTodoItem(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Todo item not found")
+		return
+	}
+
+	updatedItem := existing
+	if err := applyMergePatch(&updatedItem, patch); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid merge patch: "+err.Error())
+		return
+	}
+	updatedItem.ID = existing.ID
+	if errs := organizationErrors(r.Context(), h.organizations, updatedItem.OrganizationID); len(errs) > 0 {
+		problem.Validation("Invalid todo item", errs...).Write(w, r)
 		return
 	}
 
-	item, exists := h.store.UpdateTodoItem(id, updatedItem)
+	item, exists := h.store.UpdateTodoItem(r.Context(), id, updatedItem)
 	if !exists {
-		http.Error(w, "Todo item not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, "Todo item not found")
 		return
 	}
 
@@ -148,55 +263,33 @@ func (h *TodoItemHandler) HandleUpdateTodoItem(w http.ResponseWriter, r *http.Re
 // @Tags todo-items
 // @Param id path int true "Todo item ID"
 // @Success 204 "No content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Todo item not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Todo item not found"
 // @Router /todo-items/{id} [delete]
 func (h *TodoItemHandler) HandleDeleteTodoItem(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/todo-items/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	if success := h.store.DeleteTodoItem(id); !success {
-		http.Error(w, "Todo item not found", http.StatusNotFound)
+	if success := h.store.DeleteTodoItem(r.Context(), id); !success {
+		problem.Write(w, r, http.StatusNotFound, "Todo item not found")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// SetupRoutes configures the HTTP routes for todo items
-func (h *TodoItemHandler) SetupRoutes() {
+// RegisterRoutes registers the HTTP routes for todo items on the given mux
+func (h *TodoItemHandler) RegisterRoutes(mux *http.ServeMux) {
 	// TodoItem collection endpoints
-	http.HandleFunc("/todo-items", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetAllTodoItems(w, r)
-		case http.MethodPost:
-			h.HandleCreateTodoItem(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("GET /todo-items", h.HandleGetAllTodoItems)
+	mux.HandleFunc("POST /todo-items", h.HandleCreateTodoItem)
 
 	// TodoItem instance endpoints
-	http.HandleFunc("/todo-items/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetTodoItem(w, r)
-		case http.MethodPut:
-			h.HandleUpdateTodoItem(w, r)
-		case http.MethodDelete:
-			h.HandleDeleteTodoItem(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("GET /todo-items/{id}", h.HandleGetTodoItem)
+	mux.HandleFunc("PUT /todo-items/{id}", h.HandleUpdateTodoItem)
+	mux.HandleFunc("PATCH /todo-items/{id}", h.HandlePatchTodoItem)
+	mux.HandleFunc("DELETE /todo-items/{id}", h.HandleDeleteTodoItem)
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"testing"
+)
+
+func TestGetAllTodoItemsFiltersByChecked(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Open"})
+	todoStore.CreateTodoItem(context.Background(), models.TodoItem{Text: "Done", Checked: true})
+	router := NewRouter(NewTodoItemHandler(todoStore))
+
+	for query, want := range map[string]string{"?checked=false": "Open", "?checked=true": "Done"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo-items"+query, nil))
+
+		var items []models.TodoItem
+		if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+			t.Fatalf("Failed to decode %s: %v", query, err)
+		}
+		if len(items) != 1 || items[0].Text != want {
+			t.Errorf("Expected only %q for %s, got %+v", want, query, items)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo-items?checked=maybe", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid checked filter, got %d", rec.Code)
+	}
+}
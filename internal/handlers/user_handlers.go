@@ -1,13 +1,23 @@
 package handlers
 
 import (
+	"cmp"
 	"encoding/json"
 	"net/http"
 	"periodic-api/internal/models"
+	"periodic-api/internal/notifications"
+	"periodic-api/internal/problem"
 	"periodic-api/internal/store"
 	"strconv"
 )
 
+// userSortFields are the fields users can be listed by
+var userSortFields = map[string]func(a, b models.User) int{
+	"id":        func(a, b models.User) int { return cmp.Compare(a.ID, b.ID) },
+	"createdAt": func(a, b models.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt": func(a, b models.User) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
 // UserHandler handles HTTP requests for users
 type UserHandler struct {
 	store store.UserStore
@@ -28,21 +38,20 @@ func NewUserHandler(store store.UserStore) *UserHandler {
 // @Produce json
 // @Param user body models.User true "User to create"
 // @Success 201 {object} models.User
-// @Failure 400 {string} string "Bad request"
+// @Failure 400 {object} problem.Details "Bad request"
 // @Router /users [post]
 func (h *UserHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	var user models.User
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := validateUser(user); len(errs) > 0 {
+		problem.Validation("Invalid user", errs...).Write(w, r)
 		return
 	}
 
-	createdUser := h.store.CreateUser(user)
+	createdUser := h.store.CreateUser(r.Context(), user)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -56,25 +65,19 @@ func (h *UserHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path int true "User ID"
 // @Success 200 {object} models.User
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "User not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User not found"
 // @Router /users/{id} [get]
 func (h *UserHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/users/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	user, exists := h.store.GetUser(id)
+	user, exists := h.store.GetUser(r.Context(), id)
 	if !exists {
-		http.Error(w, "User not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
@@ -87,16 +90,17 @@ func (h *UserHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
 // @Description Retrieve all users from the store
 // @Tags users
 // @Produce json
+// @Param sort query string false "Sort by id, createdAt or updatedAt; prefix with - for descending order"
 // @Success 200 {array} models.User
+// @Failure 400 {object} problem.Details "Invalid sort field"
 // @Router /users [get]
 func (h *UserHandler) HandleGetAllUsers(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	users := h.store.GetAllUsers(r.Context())
+	if err := sortItems(users, r.URL.Query().Get("sort"), userSortFields); err != nil {
+		problem.Validation("Invalid query parameter", problem.FieldError{Field: "sort", Message: err.Error()}).Write(w, r)
 		return
 	}
 
-	users := h.store.GetAllUsers()
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(users)
 }
@@ -110,31 +114,29 @@ func (h *UserHandler) HandleGetAllUsers(w http.ResponseWriter, r *http.Request)
 // @Param id path int true "User ID"
 // @Param user body models.User true "Updated user data"
 // @Success 200 {object} models.User
-// @Failure 400 {string} string "Bad request"
-// @Failure 404 {string} string "User not found"
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "User not found"
 // @Router /users/{id} [put]
 func (h *UserHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/users/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
 	var updatedUser models.User
 	if err := json.NewDecoder(r.Body).Decode(&updatedUser); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := validateUser(updatedUser); len(errs) > 0 {
+		problem.Validation("Invalid user", errs...).Write(w, r)
 		return
 	}
 
-	user, exists := h.store.UpdateUser(id, updatedUser)
+	user, exists := h.store.UpdateUser(r.Context(), id, updatedUser)
 	if !exists {
-		http.Error(w, "User not found", http.StatusNotFound)
+		problem.Write(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
@@ -148,55 +150,43 @@ func (h *UserHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
 // @Tags users
 // @Param id path int true "User ID"
 // @Success 204 "No content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "User not found"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "User not found"
 // @Router /users/{id} [delete]
 func (h *UserHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	idStr := r.URL.Path[len("/users/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
 		return
 	}
 
-	if success := h.store.DeleteUser(id); !success {
-		http.Error(w, "User not found", http.StatusNotFound)
+	if success := h.store.DeleteUser(r.Context(), id); !success {
+		problem.Write(w, r, http.StatusNotFound, "User not found")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// SetupRoutes configures the HTTP routes for users
-func (h *UserHandler) SetupRoutes() {
-	// User collection endpoints
-	http.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetAllUsers(w, r)
-		case http.MethodPost:
-			h.HandleCreateUser(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// validateUser checks the email address of a user
+func validateUser(user models.User) []problem.FieldError {
+	var errs []problem.FieldError
+	if user.Email != "" {
+		if err := notifications.ValidateAddress(user.Email); err != nil {
+			errs = append(errs, problem.FieldError{Field: "email", Message: err.Error()})
 		}
-	})
+	}
+	return errs
+}
+
+// RegisterRoutes registers the HTTP routes for users on the given mux
+func (h *UserHandler) RegisterRoutes(mux *http.ServeMux) {
+	// User collection endpoints
+	mux.HandleFunc("GET /users", h.HandleGetAllUsers)
+	mux.HandleFunc("POST /users", h.HandleCreateUser)
 
 	// User instance endpoints
-	http.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			h.HandleGetUser(w, r)
-		case http.MethodPut:
-			h.HandleUpdateUser(w, r)
-		case http.MethodDelete:
-			h.HandleDeleteUser(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("GET /users/{id}", h.HandleGetUser)
+	mux.HandleFunc("PUT /users/{id}", h.HandleUpdateUser)
+	mux.HandleFunc("DELETE /users/{id}", h.HandleDeleteUser)
 }
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"periodic-api/internal/webhooks"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultWebhookDeliveryLimit is the number of deliveries listed when no limit is given
+	defaultWebhookDeliveryLimit = 50
+	// maxWebhookDeliveryLimit caps the number of deliveries listed per request
+	maxWebhookDeliveryLimit = 500
+)
+
+// WebhookHandler handles HTTP requests for webhooks
+type WebhookHandler struct {
+	store store.WebhookStore
+	// limits caps the webhooks each tenant may have; nil leaves them unlimited
+	limits *TenantLimitHandler
+	// allowPrivateNetworks accepts URLs on loopback, private and link-local addresses
+	allowPrivateNetworks bool
+}
+
+// NewWebhookHandler creates a new handler with the given store
+func NewWebhookHandler(store store.WebhookStore) *WebhookHandler {
+	return &WebhookHandler{
+		store: store,
+	}
+}
+
+// EnableTenantLimits refuses to create webhooks for tenants that already have as many as
+// their limits in limits allow
+func (h *WebhookHandler) EnableTenantLimits(limits *TenantLimitHandler) {
+	h.limits = limits
+}
+
+// AllowPrivateNetworks accepts webhook URLs whose addresses aren't public, such as
+// receivers on localhost during development
+func (h *WebhookHandler) AllowPrivateNetworks() {
+	h.allowPrivateNetworks = true
+}
+
+// HandleCreateWebhook handles POST requests to register a new webhook
+// @Summary Register a webhook
+// @Description Register a URL to receive the given event types. Each delivery is a POST of the event as JSON, signed in the X-Periodic-Signature header with "sha256=" and the hex HMAC-SHA256 of the body keyed with the webhook secret. A secret is generated when none is given; it is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body models.Webhook true "Webhook to register"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 403 {object} problem.Details "Tenant limit of webhooks reached"
+// @Failure 500 {object} problem.Details "Internal server error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	// Webhooks are active unless the request says otherwise
+	webhook := models.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if errs := h.validateWebhook(r.Context(), webhook); len(errs) > 0 {
+		problem.Validation("Invalid webhook", errs...).Write(w, r)
+		return
+	}
+	if h.limits != nil {
+		if limit := h.limits.requestLimits(r).MaxWebhooks; limit > 0 && len(h.store.GetAllWebhooks(r.Context())) >= limit {
+			writeLimitExceeded(w, r, limit, "webhooks")
+			return
+		}
+	}
+
+	if webhook.Secret == "" {
+		secret, err := webhooks.GenerateSecret()
+		if err != nil {
+			problem.Write(w, r, http.StatusInternalServerError, "Failed to generate webhook secret")
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	// The webhook is only sent events about the items its owner may see
+	webhook.OwnerID = nil
+	if userID, ok := store.UserFromContext(r.Context()); ok {
+		webhook.OwnerID = &userID
+	}
+
+	createdWebhook := h.store.CreateWebhook(r.Context(), webhook)
+	if createdWebhook.ID == 0 {
+		problem.Write(w, r, http.StatusInternalServerError, "Failed to create webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(createdWebhook)
+}
+
+// HandleGetWebhook handles GET requests to retrieve a webhook by ID
+// @Summary Get a webhook by ID
+// @Description Get a specific webhook by its ID. The secret is not included.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Webhook not found"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) HandleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	webhook, exists := h.store.GetWebhook(r.Context(), id)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withoutSecret(webhook))
+}
+
+// HandleGetAllWebhooks handles GET requests to retrieve all webhooks
+// @Summary Get all webhooks
+// @Description Retrieve all registered webhooks. Secrets are not included.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Router /webhooks [get]
+func (h *WebhookHandler) HandleGetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	allWebhooks := h.store.GetAllWebhooks(r.Context())
+	for i := range allWebhooks {
+		allWebhooks[i] = withoutSecret(allWebhooks[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allWebhooks)
+}
+
+// HandleUpdateWebhook handles PUT requests to update a webhook
+// @Summary Update a webhook
+// @Description Replace a webhook by its ID. The secret is kept when none is given.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param webhook body models.Webhook true "Updated webhook"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} problem.Details "Bad request"
+// @Failure 404 {object} problem.Details "Webhook not found"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) HandleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	updatedWebhook := models.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&updatedWebhook); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if errs := h.validateWebhook(r.Context(), updatedWebhook); len(errs) > 0 {
+		problem.Validation("Invalid webhook", errs...).Write(w, r)
+		return
+	}
+
+	if updatedWebhook.Secret == "" {
+		existing, exists := h.store.GetWebhook(r.Context(), id)
+		if !exists {
+			problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+			return
+		}
+		updatedWebhook.Secret = existing.Secret
+	}
+
+	webhook, exists := h.store.UpdateWebhook(r.Context(), id, updatedWebhook)
+	if !exists {
+		problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withoutSecret(webhook))
+}
+
+// HandleDeleteWebhook handles DELETE requests to remove a webhook
+// @Summary Delete a webhook
+// @Description Delete a webhook and its delivery log by its ID
+// @Tags webhooks
+// @Param id path int true "Webhook ID"
+// @Success 204 "No content"
+// @Failure 400 {object} problem.Details "Invalid ID"
+// @Failure 404 {object} problem.Details "Webhook not found"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if success := h.store.DeleteWebhook(r.Context(), id); !success {
+		problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetWebhookDeliveries handles GET requests to retrieve the delivery log of a webhook
+// @Summary Get webhook deliveries
+// @Description Get the most recent delivery attempts of a webhook, newest first. Every retry is a separate entry.
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param limit query int false "Maximum number of deliveries to return (at most 500)" default(50)
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} problem.Details "Invalid ID or limit"
+// @Failure 404 {object} problem.Details "Webhook not found"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) HandleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	limit := defaultWebhookDeliveryLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 {
+			problem.Validation("Invalid query parameter", problem.FieldError{Field: "limit", Message: "must be a positive integer"}).Write(w, r)
+			return
+		}
+		limit = min(parsedLimit, maxWebhookDeliveryLimit)
+	}
+
+	if _, exists := h.store.GetWebhook(r.Context(), id); !exists {
+		problem.Write(w, r, http.StatusNotFound, "Webhook not found")
+		return
+	}
+
+	deliveries := h.store.GetWebhookDeliveries(r.Context(), id, limit)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// validateWebhook checks that a webhook has an absolute HTTP(S) URL on a public address,
+// unless private networks are allowed, and subscribes to known event types
+func (h *WebhookHandler) validateWebhook(ctx context.Context, webhook models.Webhook) []problem.FieldError {
+	var errs []problem.FieldError
+
+	if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs = append(errs, problem.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	} else if !h.allowPrivateNetworks {
+		if err := webhooks.CheckURL(ctx, webhook.URL); errors.Is(err, webhooks.ErrPrivateAddress) {
+			errs = append(errs, problem.FieldError{Field: "url", Message: "must not point to a loopback, private or link-local address"})
+		}
+	}
+
+	if len(webhook.EventTypes) == 0 {
+		errs = append(errs, problem.FieldError{Field: "eventTypes", Message: "must contain at least one event type"})
+	}
+	for _, eventType := range webhook.EventTypes {
+		if !slices.Contains(events.Types, eventType) {
+			errs = append(errs, problem.FieldError{
+				Field:   "eventTypes",
+				Message: "unknown event type " + strconv.Quote(eventType) + "; expected one of " + strings.Join(events.Types, ", "),
+			})
+		}
+	}
+
+	return errs
+}
+
+// withoutSecret returns the webhook with its secret removed for responses
+func withoutSecret(webhook models.Webhook) models.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
+
+// RegisterRoutes registers the HTTP routes for webhooks on the given mux
+func (h *WebhookHandler) RegisterRoutes(mux *http.ServeMux) {
+	// Webhook collection endpoints
+	mux.HandleFunc("GET /webhooks", h.HandleGetAllWebhooks)
+	mux.HandleFunc("POST /webhooks", h.HandleCreateWebhook)
+
+	// Webhook instance endpoints
+	mux.HandleFunc("GET /webhooks/{id}", h.HandleGetWebhook)
+	mux.HandleFunc("PUT /webhooks/{id}", h.HandleUpdateWebhook)
+	mux.HandleFunc("DELETE /webhooks/{id}", h.HandleDeleteWebhook)
+
+	// Delivery log of a webhook
+	mux.HandleFunc("GET /webhooks/{id}/deliveries", h.HandleGetWebhookDeliveries)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/models"
+	"periodic-api/internal/problem"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+)
+
+func TestWebhookSecretIsOnlyReturnedOnCreate(t *testing.T) {
+	router := NewRouter(NewWebhookHandler(store.NewMemoryWebhookStore()))
+
+	body := `{"url":"https://example.com/hooks","eventTypes":["scheduled_item.executed","todo.created"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.Webhook
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode webhook: %v", err)
+	}
+	if created.Secret == "" || !created.Active {
+		t.Errorf("Expected an active webhook with a generated secret, got %+v", created)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d", created.ID), nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var fetched models.Webhook
+	if err := json.NewDecoder(rec.Body).Decode(&fetched); err != nil {
+		t.Fatalf("Failed to decode webhook: %v", err)
+	}
+	if fetched.Secret != "" || fetched.URL != created.URL {
+		t.Errorf("Expected the webhook without its secret, got %+v", fetched)
+	}
+}
+
+func TestCreateWebhookRejectsUnknownEventTypes(t *testing.T) {
+	router := NewRouter(NewWebhookHandler(store.NewMemoryWebhookStore()))
+
+	body := `{"url":"ftp://example.com","eventTypes":["item.exploded"]}`
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+	var details problem.Details
+	if err := json.NewDecoder(rec.Body).Decode(&details); err != nil {
+		t.Fatalf("Failed to decode problem details: %v", err)
+	}
+	if len(details.Errors) != 2 || details.Errors[0].Field != "url" || details.Errors[1].Field != "eventTypes" {
+		t.Errorf("Expected url and eventTypes field errors, got %+v", details.Errors)
+	}
+}
+
+func TestCreateWebhookRejectsPrivateAddresses(t *testing.T) {
+	handler := NewWebhookHandler(store.NewMemoryWebhookStore())
+	router := NewRouter(handler)
+
+	create := func(url string) *httptest.ResponseRecorder {
+		body := `{"url":"` + url + `","eventTypes":["todo.created"]}`
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(body)))
+		return rec
+	}
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:8080/hooks", "http://192.168.0.10/hooks"} {
+		if rec := create(url); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "link-local") {
+			t.Errorf("Expected %s to be rejected, got %d: %s", url, rec.Code, rec.Body.String())
+		}
+	}
+
+	handler.AllowPrivateNetworks()
+	if rec := create("http://127.0.0.1:8080/hooks"); rec.Code != http.StatusCreated {
+		t.Errorf("Expected a local receiver to be allowed in development, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,121 @@
+// Package logging configures the level and format of the application's logs. Messages
+// are written with the printf-style helpers here; anything still written with the
+// standard log package is logged at the info level.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config configures the logs
+type Config struct {
+	Level  slog.Level
+	Format string
+}
+
+// level is the minimum level logged, which SetLevel can change at runtime
+var level = new(slog.LevelVar)
+
+// ConfigFromEnv returns the logging configuration from LOG_LEVEL (debug, info, warn or
+// error; default info) and LOG_FORMAT (text or json; default text)
+func ConfigFromEnv() (Config, error) {
+	config := Config{Level: slog.LevelInfo, Format: FormatText}
+
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := config.Level.UnmarshalText([]byte(value)); err != nil {
+			return Config{}, fmt.Errorf("invalid LOG_LEVEL %q: must be debug, info, warn or error", value)
+		}
+	}
+	if value := os.Getenv("LOG_FORMAT"); value != "" {
+		config.Format = strings.ToLower(value)
+		if config.Format != FormatText && config.Format != FormatJSON {
+			return Config{}, fmt.Errorf("invalid LOG_FORMAT %q: must be text or json", value)
+		}
+	}
+	return config, nil
+}
+
+// Setup writes the logs to stderr in the configured level and format
+func Setup(config Config) {
+	slog.SetDefault(slog.New(newHandler(os.Stderr, config)))
+}
+
+// SetLevel changes the minimum level logged
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// ReloadLevel applies LOG_LEVEL after the configuration is reloaded, keeping the current
+// level if it is invalid. The format only changes on restart.
+func ReloadLevel() {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		Warnf("Keeping the previous log level: %v", err)
+		return
+	}
+	SetLevel(config.Level)
+}
+
+// newHandler returns a handler writing to w in the configured format
+func newHandler(w io.Writer, config Config) slog.Handler {
+	level.Set(config.Level)
+	options := &slog.HandlerOptions{Level: level}
+	if config.Format == FormatJSON {
+		return slog.NewJSONHandler(w, options)
+	}
+	return slog.NewTextHandler(w, options)
+}
+
+// Debugf logs detail that is only useful when diagnosing a problem, such as LLM requests
+func Debugf(format string, args ...any) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs normal operation
+func Infof(format string, args ...any) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a problem the application recovers from, such as falling back to a default
+func Warnf(format string, args ...any) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a failed operation
+func Errorf(format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+}
+
+// Fatalf logs an error and exits, for failures during startup
+func Fatalf(format string, args ...any) {
+	logf(slog.LevelError, format, args...)
+	os.Exit(1)
+}
+
+// DebugEnabled reports whether debug messages are logged, so callers can skip costly work
+// that only feeds them
+func DebugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// logf formats and logs the message if its level is enabled
+func logf(l slog.Level, format string, args ...any) {
+	ctx := context.Background()
+	logger := slog.Default()
+	if !logger.Enabled(ctx, l) {
+		return
+	}
+	logger.Handler().Handle(ctx, slog.NewRecord(time.Now(), l, fmt.Sprintf(format, args...), 0))
+}
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		level, format string
+		expected      Config
+		valid         bool
+	}{
+		{"", "", Config{Level: slog.LevelInfo, Format: FormatText}, true},
+		{"debug", "JSON", Config{Level: slog.LevelDebug, Format: FormatJSON}, true},
+		{"WARN", "text", Config{Level: slog.LevelWarn, Format: FormatText}, true},
+		{"verbose", "", Config{}, false},
+		{"", "xml", Config{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LOG_LEVEL", tt.level)
+		t.Setenv("LOG_FORMAT", tt.format)
+		config, err := ConfigFromEnv()
+		if (err == nil) != tt.valid || config != tt.expected {
+			t.Errorf("LOG_LEVEL=%q LOG_FORMAT=%q: expected %+v (valid %v), got %+v (%v)", tt.level, tt.format, tt.expected, tt.valid, config, err)
+		}
+	}
+}
+
+func TestLevelAndFormat(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var out bytes.Buffer
+	slog.SetDefault(slog.New(newHandler(&out, Config{Level: slog.LevelWarn, Format: FormatJSON})))
+
+	Debugf("request payload %s", "{}")
+	Infof("processed %d items", 3)
+	log.Printf("standard log message")
+	Warnf("using default %v", "30s")
+	Errorf("error saving item: %v", "timeout")
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected JSON logs, got %q", line)
+		}
+		messages = append(messages, entry["level"].(string)+" "+entry["msg"].(string))
+	}
+	expected := "WARN using default 30s,ERROR error saving item: timeout"
+	if got := strings.Join(messages, ","); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	out.Reset()
+	SetLevel(slog.LevelDebug)
+	Debugf("request payload %s", "{}")
+	log.Printf("standard log message")
+	if got := out.String(); !strings.Contains(got, `"level":"DEBUG","msg":"request payload {}"`) || !strings.Contains(got, `"level":"INFO","msg":"standard log message"`) {
+		t.Errorf("Expected debug and standard log messages after SetLevel, got %s", got)
+	}
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultBodyLogSampleRate is the fraction of requests whose bodies are logged at debug level
+	defaultBodyLogSampleRate = 0.1
+	// defaultBodyLogMaxBytes bounds how much of each body is captured
+	defaultBodyLogMaxBytes = 4096
+	// redacted replaces the values of sensitive fields and headers
+	redacted = "[REDACTED]"
+)
+
+// redactedFields are the JSON fields whose values are never logged, compared in lower
+// case without underscores or dashes so passwordHash matches password_hash
+var redactedFields = map[string]bool{
+	"password":      true,
+	"passwordhash":  true,
+	"secret":        true,
+	"token":         true,
+	"apikey":        true,
+	"authorization": true,
+}
+
+// redactedHeaders are the headers whose values are never logged
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// BodyLoggingConfig configures the request and response bodies logged at debug level
+type BodyLoggingConfig struct {
+	// SampleRate is the fraction of requests logged, from 0 to 1
+	SampleRate float64
+	// MaxBytes bounds how much of each body is captured; larger bodies are not logged
+	MaxBytes int
+}
+
+// BodyLoggingConfigFromEnv returns the body logging configuration from LOG_BODY_SAMPLE_RATE
+// and LOG_BODY_MAX_BYTES, falling back to the defaults when they are unset or invalid
+func BodyLoggingConfigFromEnv() BodyLoggingConfig {
+	config := BodyLoggingConfig{
+		SampleRate: defaultBodyLogSampleRate,
+		MaxBytes:   defaultBodyLogMaxBytes,
+	}
+	if rate, err := strconv.ParseFloat(os.Getenv("LOG_BODY_SAMPLE_RATE"), 64); err == nil && rate >= 0 && rate <= 1 {
+		config.SampleRate = rate
+	}
+	if maxBytes, err := strconv.Atoi(os.Getenv("LOG_BODY_MAX_BYTES")); err == nil && maxBytes > 0 {
+		config.MaxBytes = maxBytes
+	}
+	return config
+}
+
+// BodyLogging logs the headers and bodies of a sample of requests and their responses,
+// for troubleshooting client integrations. It only runs while the log level is debug, so
+// it can be switched on at runtime by reloading LOG_LEVEL. Passwords, secrets and
+// credentials are redacted; bodies that aren't JSON or are too large are summarized.
+func BodyLogging(config BodyLoggingConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !logging.DebugEnabled() || isUpgrade(r) || rand.Float64() >= config.SampleRate {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Capture the start of the request body and hand the handler all of it
+			requestBody, _ := io.ReadAll(io.LimitReader(r.Body, int64(config.MaxBytes)+1))
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), r.Body), r.Body}
+
+			recorder := &bodyRecorder{responseRecorder: newResponseRecorder(w), maxBytes: config.MaxBytes}
+			next.ServeHTTP(recorder, r)
+
+			requestID := RequestIDFromContext(r.Context())
+			logging.Debugf("Request %s %s request_id=%s headers=%s body=%s",
+				r.Method, r.URL.RequestURI(), requestID, formatHeaders(r.Header),
+				formatBody(requestBody, r.Header.Get("Content-Type"), config.MaxBytes))
+			logging.Debugf("Response %s %s %d request_id=%s headers=%s body=%s",
+				r.Method, r.URL.Path, recorder.status, requestID, formatHeaders(recorder.Header()),
+				formatBody(recorder.body.Bytes(), recorder.Header().Get("Content-Type"), config.MaxBytes))
+		})
+	}
+}
+
+// bodyRecorder captures the status and the start of the body written by a handler
+type bodyRecorder struct {
+	*responseRecorder
+	body     bytes.Buffer
+	maxBytes int
+}
+
+// Write keeps up to one byte more than maxBytes, enough to tell the body was cut short
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	if remaining := r.maxBytes + 1 - r.body.Len(); remaining > 0 {
+		r.body.Write(b[:min(len(b), remaining)])
+	}
+	return r.responseRecorder.Write(b)
+}
+
+// formatHeaders formats headers for the log with credentials redacted
+func formatHeaders(header http.Header) string {
+	header = header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, redacted)
+		}
+	}
+	return fmt.Sprint(map[string][]string(header))
+}
+
+// formatBody formats a captured body for the log. JSON is logged with sensitive fields
+// redacted; anything else, or a body longer than maxBytes, is only described.
+func formatBody(body []byte, contentType string, maxBytes int) string {
+	if len(body) == 0 {
+		return "(empty)"
+	}
+	if len(body) > maxBytes {
+		return fmt.Sprintf("(more than %d bytes, not logged)", maxBytes)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return fmt.Sprintf("(%d bytes of %s, not logged)", len(body), cmp.Or(mediaType, "unknown type"))
+	}
+
+	var document any
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Sprintf("(%d bytes of invalid JSON, not logged)", len(body))
+	}
+	output, _ := json.Marshal(redactJSON(document))
+	return string(output)
+}
+
+// redactJSON replaces the values of sensitive fields anywhere in a decoded JSON document
+func redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+			if redactedFields[normalized] {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
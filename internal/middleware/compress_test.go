@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressGzipsWhenAccepted(t *testing.T) {
+	body := strings.Repeat(`{"title":"Scheduled Item"}`, 100)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/scheduled-items", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected gzip encoding, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Invalid gzip body: %v", err)
+	}
+	decoded, _ := io.ReadAll(reader)
+	if string(decoded) != body {
+		t.Errorf("Decompressed body does not match")
+	}
+
+	// Clients that don't accept gzip get the plain body
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scheduled-items", nil))
+	if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != body {
+		t.Errorf("Expected uncompressed body without Accept-Encoding")
+	}
+}
+
+func TestETagReturnsNotModified(t *testing.T) {
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[{"id":1}]`)
+	}), Compress, ETag)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/todo-items", nil))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d %q", rec.Code, etag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/todo-items", nil)
+	req.Header.Set("If-None-Match", etag)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("Expected empty 304, got %d with %d bytes", rec.Code, rec.Body.Len())
+	}
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("304 responses should not be compressed")
+	}
+}
+
+func TestETagPassesThroughStreams(t *testing.T) {
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "data: hello\n\n")
+	}), Compress, ETag)
+
+	req := httptest.NewRequest(http.MethodGet, "/execution-logs/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Streams should be neither tagged nor compressed")
+	}
+	if !rec.Flushed || rec.Body.String() != "data: hello\n\n" {
+		t.Errorf("Expected streamed body to be flushed through, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults allow the usual local frontend dev servers to call the API
+var (
+	defaultCORSAllowedOrigins = []string{"http://localhost:3000", "http://localhost:5173"}
+	defaultCORSAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSAllowedHeaders = []string{"Content-Type", "Authorization", "If-None-Match", RequestIDHeader, "X-User-ID"}
+)
+
+// defaultCORSMaxAge is how long browsers may cache a preflight response
+const defaultCORSMaxAge = 10 * time.Minute
+
+// CORSConfig configures which browser origins may call the API
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to make requests; "*" allows any origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	MaxAge         time.Duration
+}
+
+// CORSConfigFromEnv returns the CORS configuration from the CORS_ALLOWED_ORIGINS,
+// CORS_ALLOWED_METHODS and CORS_ALLOWED_HEADERS environment variables (comma separated)
+// and CORS_MAX_AGE, falling back to defaults suitable for local development
+func CORSConfigFromEnv() CORSConfig {
+	config := CORSConfig{
+		AllowedOrigins: listFromEnv("CORS_ALLOWED_ORIGINS", defaultCORSAllowedOrigins),
+		AllowedMethods: listFromEnv("CORS_ALLOWED_METHODS", defaultCORSAllowedMethods),
+		AllowedHeaders: listFromEnv("CORS_ALLOWED_HEADERS", defaultCORSAllowedHeaders),
+		MaxAge:         defaultCORSMaxAge,
+	}
+	if maxAge, err := time.ParseDuration(os.Getenv("CORS_MAX_AGE")); err == nil && maxAge >= 0 {
+		config.MaxAge = maxAge
+	}
+	return config
+}
+
+// listFromEnv splits a comma-separated environment variable, falling back to a default
+func listFromEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+// AllowsOrigin reports whether browsers on origin may call the API
+func (c CORSConfig) AllowsOrigin(origin string) bool {
+	return slices.Contains(c.AllowedOrigins, "*") || slices.Contains(c.AllowedOrigins, origin)
+}
+
+// CORS adds cross-origin resource sharing headers for allowed origins and answers
+// preflight requests, which browsers send before cross-origin PUT and DELETE requests
+func CORS(config CORSConfig) Middleware {
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Responses differ by origin, so shared caches must key on it
+			w.Header().Add("Vary", "Origin")
+			if !config.AllowsOrigin(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader+", ETag, Retry-After")
+
+			// Answer preflight requests here; the router doesn't register OPTIONS routes
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
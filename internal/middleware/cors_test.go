@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	called := false
+	handler := CORS(CORSConfig{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	// Preflight for a cross-origin DELETE is answered without reaching the handler
+	req := httptest.NewRequest(http.MethodOptions, "/scheduled-items/1", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent || called {
+		t.Errorf("Expected preflight to return 204 without calling handler, got %d (called=%v)", rec.Code, called)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, PUT, DELETE" {
+		t.Errorf("Unexpected allowed methods %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "60" {
+		t.Errorf("Expected max age 60, got %q", got)
+	}
+
+	// Simple requests from allowed origins get the allow header
+	req = httptest.NewRequest(http.MethodGet, "/scheduled-items", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" || !called {
+		t.Errorf("Expected allowed origin header, got %q", got)
+	}
+
+	// Other origins are served without CORS headers, so the browser blocks the response
+	req = httptest.NewRequest(http.MethodGet, "/scheduled-items", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no allow origin header for disallowed origin, got %q", got)
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// Logging logs the method, path, status and duration of each request
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		logging.Infof("%s %s %d %v request_id=%s",
+			r.Method, r.URL.Path, recorder.status, time.Since(start), RequestIDFromContext(r.Context()))
+	})
+}
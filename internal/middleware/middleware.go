@@ -0,0 +1,82 @@
+// Package middleware provides HTTP middleware applied to every API route
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Middleware wraps an HTTP handler with additional behavior
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with the given middleware. The first middleware is the outermost,
+// so it sees each request first and each response last.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// responseRecorder captures the status code written by a handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// newResponseRecorder wraps w, defaulting the status to 200 as net/http does
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// WriteHeader records the status code before writing it
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write marks the header as written before writing the body
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming handlers such as the execution log stream
+func (r *responseRecorder) Flush() {
+	r.wroteHeader = true
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack lets WebSocket handlers take over the connection
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+	return hijack(r.ResponseWriter)
+}
+
+// hijack takes over the connection of w, if its server supports it
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("connection hijacking not supported")
+	}
+	return hijacker.Hijack()
+}
+
+// isUpgrade reports whether the request asks to switch protocols, as WebSocket handshakes do
+func isUpgrade(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != ""
+}
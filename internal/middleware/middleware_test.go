@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/problem"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDHonorsHeader(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "client-id")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen != "client-id" {
+		t.Errorf("Expected handler to see client request ID, got %q", seen)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "client-id" {
+		t.Errorf("Expected response request ID client-id, got %q", got)
+	}
+
+	// Without the header a new ID is generated
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if seen == "" || seen == "client-id" || rec.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("Expected a generated request ID, got %q", seen)
+	}
+}
+
+func TestRecoveryReturnsProblemDetails(t *testing.T) {
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), RequestID, Logging, Recovery)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "panic-id")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Expected problem details content type, got %q", ct)
+	}
+
+	var body problem.Details
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body.Status != http.StatusInternalServerError || body.RequestID != "panic-id" {
+		t.Errorf("Expected request ID in body, got %v", body)
+	}
+}
+
+func TestBodyLoggingRedactsAtDebugLevel(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	var received string
+	handler := BodyLogging(BodyLoggingConfig{SampleRate: 1, MaxBytes: 1024})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"username":"bob","passwordHash":"c2VjcmV0"}`))
+	}))
+	send := func() {
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"username":"bob","password_hash":"c2VjcmV0"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer abc123")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Nothing is logged above debug level
+	send()
+	if logs.Len() != 0 {
+		t.Fatalf("Expected no body logs at info level, got %s", logs.String())
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	send()
+	if received != `{"username":"bob","password_hash":"c2VjcmV0"}` {
+		t.Errorf("Expected the handler to read the whole body, got %q", received)
+	}
+	output := logs.String()
+	if strings.Contains(output, "c2VjcmV0") || strings.Contains(output, "abc123") {
+		t.Errorf("Expected credentials to be redacted, got %s", output)
+	}
+	if !strings.Contains(output, `username\":\"bob`) || strings.Count(output, "[REDACTED]") != 3 {
+		t.Errorf("Expected both bodies and the Authorization header with redactions, got %s", output)
+	}
+}
+
+func TestFormatBodySummarizesUnloggableBodies(t *testing.T) {
+	tests := []struct {
+		body        string
+		contentType string
+		expected    string
+	}{
+		{"", "", "(empty)"},
+		{"password=secret", "application/x-www-form-urlencoded", "(15 bytes of application/x-www-form-urlencoded, not logged)"},
+		{`{"title":`, "application/json", "(9 bytes of invalid JSON, not logged)"},
+		{`{"title":"a long title"}`, "application/json", "(more than 16 bytes, not logged)"},
+		{`[{"secret":"s"}]`, "application/merge-patch+json", `[{"secret":"[REDACTED]"}]`},
+	}
+	for _, tt := range tests {
+		if got := formatBody([]byte(tt.body), tt.contentType, 16); got != tt.expected {
+			t.Errorf("formatBody(%q) = %q, want %q", tt.body, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/problem"
+	"runtime/debug"
+)
+
+// Recovery turns a panicking handler into a 500 problem details response, rather than
+// letting net/http drop the connection
+func Recovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := newResponseRecorder(w)
+
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			// http.ErrAbortHandler is how handlers deliberately abort a response
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			requestID := RequestIDFromContext(r.Context())
+			logging.Errorf("Panic serving %s %s request_id=%s: %v\n%s", r.Method, r.URL.Path, requestID, err, debug.Stack())
+
+			// Too late to change the response once the handler has started writing it
+			if recorder.wroteHeader {
+				return
+			}
+			details := problem.New(http.StatusInternalServerError, "The server hit an unexpected error")
+			details.RequestID = requestID
+			details.Write(w, r)
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
@@ -0,0 +1,179 @@
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Ways of handling applied migrations whose files have changed since they were applied
+const (
+	ChecksumsEnforce = "enforce"
+	ChecksumsWarn    = "warn"
+	ChecksumsOff     = "off"
+)
+
+// ChecksumModeFromEnv returns how MIGRATION_CHECKSUMS says to handle applied migrations
+// whose files have changed, enforce by default
+func ChecksumModeFromEnv() (string, error) {
+	switch mode := strings.ToLower(os.Getenv("MIGRATION_CHECKSUMS")); mode {
+	case "":
+		return ChecksumsEnforce, nil
+	case ChecksumsEnforce, ChecksumsWarn, ChecksumsOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown MIGRATION_CHECKSUMS %q, use %s, %s or %s", mode, ChecksumsEnforce, ChecksumsWarn, ChecksumsOff)
+	}
+}
+
+// ChecksumMismatch is an applied migration whose up file no longer hashes to the checksum
+// recorded when it was applied
+type ChecksumMismatch struct {
+	Version uint
+	Name    string
+	// Recorded is the checksum of the file that was applied
+	Recorded string
+	// Current is the checksum of the file now, empty when the file is gone
+	Current string
+}
+
+// String describes the mismatch
+func (m ChecksumMismatch) String() string {
+	if m.Current == "" {
+		return fmt.Sprintf("migration %d (%s) was applied but its file is missing", m.Version, m.Name)
+	}
+	return fmt.Sprintf("migration %d (%s) was edited after it was applied: checksum %s, applied as %s", m.Version, m.Name, m.Current, m.Recorded)
+}
+
+// migrationChecksum is the checksum of a migration's up file
+type migrationChecksum struct {
+	name     string
+	checksum string
+}
+
+// fileChecksums returns the SHA-256 checksum of the up file of every migration in
+// migrationsPath, by version
+func fileChecksums(migrationsPath string) (map[uint]migrationChecksum, error) {
+	src, err := source.Open(buildFileURL(migrationsPath))
+	if err != nil {
+		return nil, fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return nil, err
+	}
+	checksums := make(map[uint]migrationChecksum, len(versions))
+	for _, version := range versions {
+		body, name, err := src.ReadUp(version)
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %d: %w", version, err)
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, body)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read migration %d: %w", version, err)
+		}
+		checksums[version] = migrationChecksum{name: name, checksum: hex.EncodeToString(hash.Sum(nil))}
+	}
+	return checksums, nil
+}
+
+// RecordChecksums records the checksums of the migrations applied to db, up to its
+// version, in schema_migration_checksums, and forgets those of rolled back migrations.
+// Migrations already recorded keep their checksum unless rehash is set, which accepts the
+// files as they are now.
+func RecordChecksums(ctx context.Context, db *sql.DB, migrationsPath string, rehash bool) error {
+	status, err := ReadSchemaStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+	checksums, err := fileChecksums(migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	// Created here rather than by a migration, since it records the migrations themselves
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migration_checksums (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("could not create schema_migration_checksums: %w", err)
+	}
+
+	conflict := `DO NOTHING`
+	if rehash {
+		conflict = `DO UPDATE SET name = EXCLUDED.name, checksum = EXCLUDED.checksum, recorded_at = NOW()`
+	}
+	for version, file := range checksums {
+		if version > status.Version {
+			continue
+		}
+		_, err := db.ExecContext(ctx, `
+			INSERT INTO schema_migration_checksums (version, name, checksum)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (version) `+conflict,
+			version, file.name, file.checksum)
+		if err != nil {
+			return fmt.Errorf("could not record the checksum of migration %d: %w", version, err)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM schema_migration_checksums WHERE version > $1`, status.Version); err != nil {
+		return fmt.Errorf("could not forget the checksums of rolled back migrations: %w", err)
+	}
+	return nil
+}
+
+// VerifyChecksums compares the checksums recorded for the migrations applied to db with
+// their files in migrationsPath, returning the migrations edited or removed since they
+// were applied, oldest first. Nothing is compared before checksums have been recorded.
+func VerifyChecksums(ctx context.Context, db *sql.DB, migrationsPath string) ([]ChecksumMismatch, error) {
+	var table sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migration_checksums')::text`).Scan(&table); err != nil {
+		return nil, fmt.Errorf("could not look up schema_migration_checksums: %w", err)
+	}
+	if !table.Valid {
+		return nil, nil
+	}
+
+	checksums, err := fileChecksums(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, `SELECT version, name, checksum FROM schema_migration_checksums ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("could not read migration checksums: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []ChecksumMismatch
+	for rows.Next() {
+		var recorded ChecksumMismatch
+		if err := rows.Scan(&recorded.Version, &recorded.Name, &recorded.Recorded); err != nil {
+			return nil, fmt.Errorf("could not read migration checksums: %w", err)
+		}
+		if file := checksums[recorded.Version]; file.checksum != recorded.Recorded {
+			recorded.Current = file.checksum
+			mismatches = append(mismatches, recorded)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read migration checksums: %w", err)
+	}
+	return mismatches, nil
+}
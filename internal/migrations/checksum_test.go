@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChecksumModeFromEnv(t *testing.T) {
+	t.Setenv("MIGRATION_CHECKSUMS", "")
+	if mode, err := ChecksumModeFromEnv(); err != nil || mode != ChecksumsEnforce {
+		t.Errorf("Expected enforce by default, got %q: %v", mode, err)
+	}
+	t.Setenv("MIGRATION_CHECKSUMS", "Warn")
+	if mode, err := ChecksumModeFromEnv(); err != nil || mode != ChecksumsWarn {
+		t.Errorf("Expected warn, got %q: %v", mode, err)
+	}
+	t.Setenv("MIGRATION_CHECKSUMS", "sometimes")
+	if _, err := ChecksumModeFromEnv(); err == nil {
+		t.Error("Expected an unknown mode to be refused")
+	}
+}
+
+func TestFileChecksumsChangeWithTheUpFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, sql string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(sql), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	write("000001_add_tags.up.sql", "CREATE TABLE tags (id SERIAL);")
+	write("000001_add_tags.down.sql", "DROP TABLE tags;")
+
+	before, err := fileChecksums(dir)
+	if err != nil {
+		t.Fatalf("fileChecksums failed: %v", err)
+	}
+	if before[1].name != "add_tags" || len(before[1].checksum) != 64 {
+		t.Fatalf("Expected a SHA-256 checksum of add_tags, got %+v", before[1])
+	}
+
+	// Only the up file is checked
+	write("000001_add_tags.down.sql", "DROP TABLE IF EXISTS tags;")
+	if after, _ := fileChecksums(dir); after[1] != before[1] {
+		t.Errorf("Expected editing the down file to keep the checksum, got %+v", after[1])
+	}
+	write("000001_add_tags.up.sql", "CREATE TABLE tags (id BIGSERIAL);")
+	if after, _ := fileChecksums(dir); after[1].checksum == before[1].checksum {
+		t.Error("Expected editing the up file to change the checksum")
+	}
+}
+
+func TestChecksumMismatchNamesTheMigration(t *testing.T) {
+	edited := ChecksumMismatch{Version: 3, Name: "add_execution_logs", Recorded: "aaa", Current: "bbb"}
+	if text := edited.String(); !strings.Contains(text, "edited") || !strings.Contains(text, "add_execution_logs") {
+		t.Errorf("Unexpected description %q", text)
+	}
+	removed := ChecksumMismatch{Version: 3, Name: "add_execution_logs", Recorded: "aaa"}
+	if text := removed.String(); !strings.Contains(text, "missing") {
+		t.Errorf("Unexpected description %q", text)
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Numbering strategies for the versions of new migrations
+const (
+	// NumberingSequence numbers a new migration one after the newest, zero-padded to six digits
+	NumberingSequence = "sequence"
+	// NumberingTimestamp numbers a new migration with its UTC creation time, as YYYYMMDDHHMMSS
+	NumberingTimestamp = "timestamp"
+)
+
+// nonNameCharacters are the runs of characters replaced with underscores in migration names
+var nonNameCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up and down migration named name to the migrations directory,
+// numbered after the newest one there with the given strategy, and returns their paths.
+// The name is lower-cased with every run of other characters than letters and digits
+// replaced by an underscore. Existing files are never overwritten.
+func Create(migrationsPath, name, numbering string, now time.Time) ([]string, error) {
+	name = strings.Trim(nonNameCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	latest, err := LatestVersion(migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var version string
+	switch numbering {
+	case NumberingSequence:
+		version = fmt.Sprintf("%06d", latest+1)
+	case NumberingTimestamp:
+		version = now.UTC().Format("20060102150405")
+		if number, _ := strconv.ParseUint(version, 10, 64); uint(number) <= latest {
+			return nil, fmt.Errorf("timestamp %s is not after the newest migration %d", version, latest)
+		}
+	default:
+		return nil, fmt.Errorf("unknown numbering %q, use %s or %s", numbering, NumberingSequence, NumberingTimestamp)
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(migrationsPath, fmt.Sprintf("%s_%s.%s.sql", version, name, direction))
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, fmt.Errorf("could not create migration: %w", err)
+		}
+		file.Close()
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCreateNumbersMigrationsAfterTheNewest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	paths, err := Create(dir, "Add item tags!", NumberingSequence, now)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(paths) != 2 || filepath.Base(paths[0]) != "000001_add_item_tags.up.sql" || filepath.Base(paths[1]) != "000001_add_item_tags.down.sql" {
+		t.Fatalf("Expected the first numbered pair, got %v", paths)
+	}
+	if paths, err = Create(dir, "add_owners", NumberingSequence, now); err != nil || filepath.Base(paths[0]) != "000002_add_owners.up.sql" {
+		t.Fatalf("Expected the next number, got %v: %v", paths, err)
+	}
+
+	paths, err = Create(dir, "add_tenants", NumberingTimestamp, now)
+	if err != nil || filepath.Base(paths[1]) != "20300102030405_add_tenants.down.sql" {
+		t.Fatalf("Expected a timestamped pair, got %v: %v", paths, err)
+	}
+	if _, err := Create(dir, "add_again", NumberingTimestamp, now); err == nil {
+		t.Errorf("Expected a timestamp no later than the newest migration to be refused")
+	}
+	if _, err := Create(dir, "!!", NumberingSequence, now); err == nil {
+		t.Errorf("Expected a name without letters or digits to be refused")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 6 {
+		t.Errorf("Expected 6 migration files, got %d", len(entries))
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+)
+
+// Dialect is the SQL dialect of a database. Each dialect has its own migrations, in the
+// directory of its name under the migrations directory, and its own migrate driver.
+// PostgreSQL is the only dialect with stores, and so the only one there is.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+)
+
+// DialectFromEnv returns the dialect named by DB_DIALECT, postgres by default
+func DialectFromEnv() (Dialect, error) {
+	value := strings.ToLower(os.Getenv("DB_DIALECT"))
+	if value == "" {
+		return DialectPostgres, nil
+	}
+	switch dialect := Dialect(value); dialect {
+	case DialectPostgres:
+		return dialect, nil
+	default:
+		return "", fmt.Errorf("unknown DB_DIALECT %q, only %s is supported", value, DialectPostgres)
+	}
+}
+
+// Path returns the directory of the dialect's migrations under migrationsPath, or an
+// error when migrationsPath has no directory for the dialect
+func (d Dialect) Path(migrationsPath string) (string, error) {
+	path := filepath.Join(migrationsPath, string(d))
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("no %s migrations in %s: %w", d, migrationsPath, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("no %s migrations in %s: %s is not a directory", d, migrationsPath, path)
+	}
+	return path, nil
+}
+
+// databaseDriver returns the migrate driver of the dialect for db
+func (d Dialect) databaseDriver(db *sql.DB) (database.Driver, error) {
+	switch d {
+	case DialectPostgres:
+		driver, err := postgres.WithInstance(db, &postgres.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres driver: %w", err)
+		}
+		return driver, nil
+	default:
+		return nil, fmt.Errorf("migrating %s databases is not supported", d)
+	}
+}
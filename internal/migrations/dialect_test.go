@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDialectsHaveMigrationsOfTheirOwn(t *testing.T) {
+	t.Setenv("DB_DIALECT", "")
+	if dialect, err := DialectFromEnv(); err != nil || dialect != DialectPostgres {
+		t.Errorf("Expected postgres by default, got %q: %v", dialect, err)
+	}
+	t.Setenv("DB_DIALECT", "Postgres")
+	if dialect, err := DialectFromEnv(); err != nil || dialect != DialectPostgres {
+		t.Errorf("Expected postgres, got %q: %v", dialect, err)
+	}
+	// Dialects without migrations or a driver are refused rather than half supported
+	for _, value := range []string{"mysql", "sqlite", "oracle"} {
+		t.Setenv("DB_DIALECT", value)
+		if _, err := DialectFromEnv(); err == nil {
+			t.Errorf("Expected DB_DIALECT=%s to be refused", value)
+		}
+	}
+
+	if path, err := DialectPostgres.Path("../../migrations"); err != nil || path != filepath.Join("../../migrations", "postgres") {
+		t.Errorf("Expected the postgres directory, got %s: %v", path, err)
+	}
+	// A directory without the dialect's migrations is an error, not a place to look for them
+	flat := t.TempDir()
+	os.WriteFile(filepath.Join(flat, "000001_init.up.sql"), nil, 0o644)
+	if path, err := DialectPostgres.Path(flat); err == nil {
+		t.Errorf("Expected a directory without postgres migrations to be refused, got %s", path)
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// migrationLockKey is the key of the PostgreSQL advisory lock held while migrating. It
+// differs from the lock golang-migrate takes around each step, which gives up after 15
+// seconds and would fail instances waiting on a long migration.
+const migrationLockKey int64 = 0x70657269_6f646963
+
+// migrationLockTimeout is how long an instance waits for another to finish migrating
+const migrationLockTimeout = 10 * time.Minute
+
+// withMigrationLock runs migrate while holding the migration lock, so when several
+// instances start at once with AUTO_MIGRATE only one migrates and the others wait for it
+// and then find nothing left to do. Only PostgreSQL databases are locked.
+func withMigrationLock(db *sql.DB, dialect Dialect, migrate func() error) error {
+	if dialect != DialectPostgres {
+		return migrate()
+	}
+	if db.Stats().MaxOpenConnections == 1 {
+		// The lock's connection would leave none for migrating
+		logging.Warnf("Migrating without the migration lock: the connection pool allows one connection")
+		return migrate()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrationLockTimeout)
+	defer cancel()
+
+	// Advisory locks belong to a session, so take and release it on one connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("could not connect to take the migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, migrationLockKey).Scan(&locked); err != nil {
+		return fmt.Errorf("could not take the migration lock: %w", err)
+	}
+	if !locked {
+		logging.Infof("Waiting for another instance to finish migrating...")
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+			return fmt.Errorf("could not take the migration lock within %v: %w", migrationLockTimeout, err)
+		}
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			// Close the connection rather than return it to the pool still holding the lock
+			logging.Warnf("Failed to release the migration lock, closing its connection: %v", err)
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return migrate()
+}
@@ -1,16 +1,20 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+
+	"periodic-api/internal/logging"
 )
 
 // buildFileURL creates a proper file URL for the migrations path
@@ -36,74 +40,92 @@ func buildFileURL(migrationsPath string) string {
 	return "file://" + absPath
 }
 
-// MigrateUp runs all pending migrations
-func MigrateUp(db *sql.DB, migrationsPath string) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+// newMigrate returns a migrate instance applying the dialect's migrations in
+// migrationsPath to db
+func newMigrate(db *sql.DB, dialect Dialect, migrationsPath string) (*migrate.Migrate, error) {
+	driver, err := dialect.databaseDriver(db)
 	if err != nil {
-		return fmt.Errorf("could not create postgres driver: %w", err)
+		return nil, err
 	}
 
 	sourceURL := buildFileURL(migrationsPath)
-	log.Printf("Debug: Using migrations path: %s", migrationsPath)
-	log.Printf("Debug: Generated source URL: %s", sourceURL)
-
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
-	}
-	defer m.Close()
+	logging.Debugf("Using %s migrations path: %s", dialect, migrationsPath)
+	logging.Debugf("Generated source URL: %s", sourceURL)
 
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not run up migrations: %w", err)
-	}
-
-	return nil
-}
-
-// MigrateDown rolls back migrations
-func MigrateDown(db *sql.DB, migrationsPath string, steps int) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	m, err := migrate.NewWithDatabaseInstance(sourceURL, string(dialect), driver)
 	if err != nil {
-		return fmt.Errorf("could not create postgres driver: %w", err)
+		return nil, fmt.Errorf("could not create migrate instance: %w", err)
 	}
+	return m, nil
+}
 
-	sourceURL := buildFileURL(migrationsPath)
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
-	}
-	defer m.Close()
+// MigrateUp runs all pending migrations. Instances migrating at the same time take turns,
+// so the later ones find nothing left to run. A non-nil snapshotter backs up the database
+// first.
+func MigrateUp(db *sql.DB, dialect Dialect, migrationsPath string, snapshotter Snapshotter) error {
+	return withMigrationLock(db, dialect, func() error {
+		m, err := newMigrate(db, dialect, migrationsPath)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
 
-	if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not run down migrations: %w", err)
-	}
+		target, err := LatestVersion(migrationsPath)
+		if err != nil {
+			return err
+		}
+		err = withSnapshot(db, snapshotter, target, func() error {
+			if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+				return fmt.Errorf("could not run up migrations: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		recordChecksums(db, dialect, migrationsPath)
+		return nil
+	})
+}
 
-	return nil
+// MigrateDown rolls back migrations. A non-nil snapshotter backs up the database first.
+func MigrateDown(db *sql.DB, dialect Dialect, migrationsPath string, steps int, snapshotter Snapshotter) error {
+	return withMigrationLock(db, dialect, func() error {
+		m, err := newMigrate(db, dialect, migrationsPath)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		var target uint
+		if snapshotter != nil {
+			current, _, err := m.Version()
+			if err != nil && err != migrate.ErrNilVersion {
+				return fmt.Errorf("could not get migration version: %w", err)
+			}
+			if target, err = StepsBack(migrationsPath, current, steps); err != nil {
+				return err
+			}
+		}
+		err = withSnapshot(db, snapshotter, target, func() error {
+			if err := m.Steps(-steps); err != nil && err != migrate.ErrNoChange {
+				return fmt.Errorf("could not run down migrations: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		recordChecksums(db, dialect, migrationsPath)
+		return nil
+	})
 }
 
 // MigrateStatus returns the current migration version and status
-func MigrateStatus(db *sql.DB, migrationsPath string) (uint, bool, error) {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+func MigrateStatus(db *sql.DB, dialect Dialect, migrationsPath string) (uint, bool, error) {
+	m, err := newMigrate(db, dialect, migrationsPath)
 	if err != nil {
-		return 0, false, fmt.Errorf("could not create postgres driver: %w", err)
-	}
-
-	sourceURL := buildFileURL(migrationsPath)
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		"postgres",
-		driver,
-	)
-	if err != nil {
-		return 0, false, fmt.Errorf("could not create migrate instance: %w", err)
+		return 0, false, err
 	}
 	defer m.Close()
 
@@ -115,52 +137,80 @@ func MigrateStatus(db *sql.DB, migrationsPath string) (uint, bool, error) {
 	return version, dirty, nil
 }
 
-// MigrateTo migrates to a specific version
-func MigrateTo(db *sql.DB, migrationsPath string, version uint) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
-	if err != nil {
-		return fmt.Errorf("could not create postgres driver: %w", err)
+// MigrateTo migrates to a specific version. A non-nil snapshotter backs up the database
+// first.
+func MigrateTo(db *sql.DB, dialect Dialect, migrationsPath string, version uint, snapshotter Snapshotter) error {
+	return withMigrationLock(db, dialect, func() error {
+		m, err := newMigrate(db, dialect, migrationsPath)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		err = withSnapshot(db, snapshotter, version, func() error {
+			if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
+				return fmt.Errorf("could not migrate to version %d: %w", version, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		recordChecksums(db, dialect, migrationsPath)
+		return nil
+	})
+}
+
+// recordChecksums records the checksums of the migrations applied by a run, so later
+// edits to their files are caught. Only PostgreSQL databases record them.
+func recordChecksums(db *sql.DB, dialect Dialect, migrationsPath string) {
+	if dialect != DialectPostgres {
+		return
+	}
+	if err := RecordChecksums(context.Background(), db, migrationsPath, false); err != nil {
+		logging.Warnf("Failed to record migration checksums: %v", err)
 	}
+}
 
-	sourceURL := buildFileURL(migrationsPath)
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		"postgres",
-		driver,
-	)
+// ForceVersion sets the migration version without running migrations
+func ForceVersion(db *sql.DB, dialect Dialect, migrationsPath string, version int) error {
+	m, err := newMigrate(db, dialect, migrationsPath)
 	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
+		return err
 	}
 	defer m.Close()
 
-	if err := m.Migrate(version); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("could not migrate to version %d: %w", version, err)
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("could not force version %d: %w", version, err)
 	}
 
 	return nil
 }
 
-// ForceVersion sets the migration version without running migrations
-func ForceVersion(db *sql.DB, migrationsPath string, version int) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+// LatestVersion returns the version of the newest migration in the migrations directory,
+// or 0 if it has none
+func LatestVersion(migrationsPath string) (uint, error) {
+	src, err := source.Open(buildFileURL(migrationsPath))
 	if err != nil {
-		return fmt.Errorf("could not create postgres driver: %w", err)
+		return 0, fmt.Errorf("could not open migrations: %w", err)
 	}
+	defer src.Close()
 
-	sourceURL := buildFileURL(migrationsPath)
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		"postgres",
-		driver,
-	)
+	version, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
 	if err != nil {
-		return fmt.Errorf("could not create migrate instance: %w", err)
+		return 0, fmt.Errorf("could not read migrations: %w", err)
 	}
-	defer m.Close()
-
-	if err := m.Force(version); err != nil {
-		return fmt.Errorf("could not force version %d: %w", version, err)
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read migrations: %w", err)
+		}
+		version = next
 	}
-
-	return nil
 }
@@ -3,6 +3,7 @@ package migrations
 import (
 	"database/sql"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	_ "github.com/lib/pq"
@@ -46,13 +47,13 @@ func TestMigrateUp(t *testing.T) {
 	defer cleanup()
 
 	// Get test migrations path
-	migrationsPath, err := filepath.Abs("../../migrations")
+	migrationsPath, err := filepath.Abs("../../migrations/postgres")
 	if err != nil {
 		t.Fatalf("Failed to get migrations path: %v", err)
 	}
 
 	// Run migrations up
-	err = MigrateUp(db, migrationsPath)
+	err = MigrateUp(db, DialectPostgres, migrationsPath, nil)
 	if err != nil {
 		t.Fatalf("Failed to run migrations up: %v", err)
 	}
@@ -98,13 +99,13 @@ func TestMigrateStatus(t *testing.T) {
 	defer cleanup()
 
 	// Get test migrations path
-	migrationsPath, err := filepath.Abs("../../migrations")
+	migrationsPath, err := filepath.Abs("../../migrations/postgres")
 	if err != nil {
 		t.Fatalf("Failed to get migrations path: %v", err)
 	}
 
 	// Check status before any migrations
-	version, dirty, err := MigrateStatus(db, migrationsPath)
+	version, dirty, err := MigrateStatus(db, DialectPostgres, migrationsPath)
 	if err != nil {
 		t.Fatalf("Failed to get migration status: %v", err)
 	}
@@ -118,13 +119,13 @@ func TestMigrateStatus(t *testing.T) {
 	}
 
 	// Run migrations
-	err = MigrateUp(db, migrationsPath)
+	err = MigrateUp(db, DialectPostgres, migrationsPath, nil)
 	if err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
 	}
 
 	// Check status after migrations
-	version, dirty, err = MigrateStatus(db, migrationsPath)
+	version, dirty, err = MigrateStatus(db, DialectPostgres, migrationsPath)
 	if err != nil {
 		t.Fatalf("Failed to get migration status after migrations: %v", err)
 	}
@@ -143,13 +144,13 @@ func TestMigrateDown(t *testing.T) {
 	defer cleanup()
 
 	// Get test migrations path
-	migrationsPath, err := filepath.Abs("../../migrations")
+	migrationsPath, err := filepath.Abs("../../migrations/postgres")
 	if err != nil {
 		t.Fatalf("Failed to get migrations path: %v", err)
 	}
 
 	// Run migrations up first
-	err = MigrateUp(db, migrationsPath)
+	err = MigrateUp(db, DialectPostgres, migrationsPath, nil)
 	if err != nil {
 		t.Fatalf("Failed to run migrations up: %v", err)
 	}
@@ -171,13 +172,13 @@ func TestMigrateDown(t *testing.T) {
 	}
 
 	// Run migration down
-	err = MigrateDown(db, migrationsPath, 1)
+	err = MigrateDown(db, DialectPostgres, migrationsPath, 1, nil)
 	if err != nil {
 		t.Fatalf("Failed to run migration down: %v", err)
 	}
 
 	// Check status after rollback
-	version, dirty, err := MigrateStatus(db, migrationsPath)
+	version, dirty, err := MigrateStatus(db, DialectPostgres, migrationsPath)
 	if err != nil {
 		t.Fatalf("Failed to get migration status after rollback: %v", err)
 	}
@@ -190,4 +191,39 @@ func TestMigrateDown(t *testing.T) {
 	if version >= 2 {
 		t.Errorf("Expected lower version after rollback, got %d", version)
 	}
-}
\ No newline at end of file
+}
+func TestConcurrentMigrateUpLeavesACleanSchema(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	migrationsPath, err := filepath.Abs("../../migrations/postgres")
+	if err != nil {
+		t.Fatalf("Failed to get migrations path: %v", err)
+	}
+
+	// Instances starting together take turns instead of racing on the same migrations
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- MigrateUp(db, DialectPostgres, migrationsPath, nil)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent migration failed: %v", err)
+		}
+	}
+
+	version, dirty, err := MigrateStatus(db, DialectPostgres, migrationsPath)
+	if err != nil {
+		t.Fatalf("Failed to get migration status: %v", err)
+	}
+	if dirty || version != SchemaVersion {
+		t.Errorf("Expected a clean schema at version %d, got %d (dirty: %v)", SchemaVersion, version, dirty)
+	}
+}
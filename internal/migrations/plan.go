@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// PlannedMigration is a migration that would run, with the SQL it would execute
+type PlannedMigration struct {
+	Version uint
+	// Identifier is the migration's name, such as add_tenant_limits
+	Identifier string
+	// Up is true for a migration that would be applied, false for one rolled back
+	Up  bool
+	SQL string
+}
+
+// Plan returns the migrations that going from the current version to target would run,
+// in the order they would run in, without touching the database: the up migrations after
+// current up to and including target, or the down migrations from current back to just
+// after target.
+func Plan(migrationsPath string, current, target uint) ([]PlannedMigration, error) {
+	src, err := source.Open(buildFileURL(migrationsPath))
+	if err != nil {
+		return nil, fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return nil, err
+	}
+	if target != 0 && !slices.Contains(versions, target) {
+		return nil, fmt.Errorf("no migration has version %d", target)
+	}
+
+	var plan []PlannedMigration
+	if target >= current {
+		for _, version := range versions {
+			if version > current && version <= target {
+				migration, err := readMigration(src, version, true)
+				if err != nil {
+					return nil, err
+				}
+				plan = append(plan, migration)
+			}
+		}
+		return plan, nil
+	}
+
+	for _, version := range slices.Backward(versions) {
+		if version <= current && version > target {
+			migration, err := readMigration(src, version, false)
+			if err != nil {
+				return nil, err
+			}
+			plan = append(plan, migration)
+		}
+	}
+	return plan, nil
+}
+
+// StepsBack returns the version rolling back steps migrations from current would leave
+// the database at, 0 when that rolls back every migration
+func StepsBack(migrationsPath string, current uint, steps int) (uint, error) {
+	src, err := source.Open(buildFileURL(migrationsPath))
+	if err != nil {
+		return 0, fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return 0, err
+	}
+	index := slices.Index(versions, current)
+	if index < 0 {
+		return 0, fmt.Errorf("no migration has the current version %d", current)
+	}
+	if index-steps < 0 {
+		return 0, nil
+	}
+	return versions[index-steps], nil
+}
+
+// sourceVersions returns the versions of the migrations in src, oldest first
+func sourceVersions(src source.Driver) ([]uint, error) {
+	version, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	versions := []uint{version}
+	for {
+		version, err = src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read migrations: %w", err)
+		}
+		versions = append(versions, version)
+	}
+}
+
+// readMigration reads the up or down migration of a version from src
+func readMigration(src source.Driver, version uint, up bool) (PlannedMigration, error) {
+	read := src.ReadDown
+	if up {
+		read = src.ReadUp
+	}
+	body, identifier, err := read(version)
+	if err != nil {
+		return PlannedMigration{}, fmt.Errorf("could not read migration %d: %w", version, err)
+	}
+	defer body.Close()
+
+	sql, err := io.ReadAll(body)
+	if err != nil {
+		return PlannedMigration{}, fmt.Errorf("could not read migration %d: %w", version, err)
+	}
+	return PlannedMigration{Version: version, Identifier: identifier, Up: up, SQL: string(sql)}, nil
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanListsMigrationsInTheOrderTheyWouldRun(t *testing.T) {
+	const path = "../../migrations/postgres"
+
+	plan, err := Plan(path, 26, 28)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(plan) != 2 || plan[0].Version != 27 || plan[1].Version != 28 || !plan[0].Up {
+		t.Fatalf("Expected migrations 27 and 28 up, got %+v", plan)
+	}
+	if plan[1].Identifier != "add_user_deactivated_at" || !strings.Contains(plan[1].SQL, "ADD COLUMN") {
+		t.Errorf("Expected the up SQL of migration 28, got %+v", plan[1])
+	}
+
+	plan, err = Plan(path, 28, 26)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(plan) != 2 || plan[0].Version != 28 || plan[1].Version != 27 || plan[0].Up || !strings.Contains(plan[0].SQL, "DROP COLUMN") {
+		t.Fatalf("Expected migrations 28 and 27 down, got %+v", plan)
+	}
+
+	if plan, err := Plan(path, 28, 28); err != nil || len(plan) != 0 {
+		t.Errorf("Expected nothing to do at the target, got %+v: %v", plan, err)
+	}
+	if _, err := Plan(path, 0, 999999); err == nil {
+		t.Error("Expected an unknown target to be refused")
+	}
+
+	if target, err := StepsBack(path, 28, 2); err != nil || target != 26 {
+		t.Errorf("Expected rolling back 2 steps to leave version 26, got %d: %v", target, err)
+	}
+	if target, err := StepsBack(path, 2, 5); err != nil || target != 0 {
+		t.Errorf("Expected rolling back past the first migration to leave version 0, got %d: %v", target, err)
+	}
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// MigrationFile is a migration in the migrations directory
+type MigrationFile struct {
+	Version uint `json:"version" example:"28"`
+	// Name is the migration's name, such as add_user_deactivated_at
+	Name string `json:"name" example:"add_user_deactivated_at"`
+}
+
+// Report is the migration state of a database with the migrations that have been applied
+// to it and those still pending
+type Report struct {
+	SchemaStatus
+	Applied []MigrationFile `json:"applied"`
+	Pending []MigrationFile `json:"pending"`
+}
+
+// ReadReport reads the version of db and sorts the migrations in migrationsPath into those
+// applied up to it and those after it, oldest first
+func ReadReport(ctx context.Context, db *sql.DB, migrationsPath string) (Report, error) {
+	status, err := ReadSchemaStatus(ctx, db)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{SchemaStatus: status, Applied: []MigrationFile{}, Pending: []MigrationFile{}}
+
+	src, err := source.Open(buildFileURL(migrationsPath))
+	if err != nil {
+		return report, fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer src.Close()
+
+	versions, err := sourceVersions(src)
+	if err != nil {
+		return report, err
+	}
+	for _, version := range versions {
+		body, name, err := src.ReadUp(version)
+		if err != nil {
+			return report, fmt.Errorf("could not read migration %d: %w", version, err)
+		}
+		body.Close()
+
+		file := MigrationFile{Version: version, Name: name}
+		if version <= status.Version {
+			report.Applied = append(report.Applied, file)
+		} else {
+			report.Pending = append(report.Pending, file)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// SchemaVersion is the migration version this build's queries are written against. Bump
+// it with every new migration.
+const SchemaVersion uint = 34
+
+// SchemaStatus is the migration state of a database compared with SchemaVersion
+type SchemaStatus struct {
+	Version  uint `json:"version" example:"19"`
+	Expected uint `json:"expected" example:"19"`
+	Dirty    bool `json:"dirty" example:"false"`
+}
+
+// Ready reports whether this build can run against the schema. A database ahead of the
+// build is accepted, since migrations are additive and old instances keep serving while
+// a new release rolls out.
+func (s SchemaStatus) Ready() bool {
+	return !s.Dirty && s.Version >= s.Expected
+}
+
+// String describes the schema's state, and what to do about it when it isn't ready
+func (s SchemaStatus) String() string {
+	switch {
+	case s.Dirty:
+		return fmt.Sprintf("schema version %d is dirty; a migration failed partway and must be fixed and forced with cmd/migrate", s.Version)
+	case s.Version < s.Expected:
+		return fmt.Sprintf("schema is at version %d but this build expects %d; run the pending migrations", s.Version, s.Expected)
+	case s.Version > s.Expected:
+		return fmt.Sprintf("schema is at version %d, ahead of the %d this build expects", s.Version, s.Expected)
+	default:
+		return fmt.Sprintf("schema is up to date at version %d", s.Version)
+	}
+}
+
+// ReadSchemaStatus reads the version recorded by the migrations from the schema_migrations
+// table. A database that has never been migrated is at version 0.
+func ReadSchemaStatus(ctx context.Context, db *sql.DB) (SchemaStatus, error) {
+	status := SchemaStatus{Expected: SchemaVersion}
+
+	var table sql.NullString
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations')::text`).Scan(&table); err != nil {
+		return status, fmt.Errorf("could not look up schema_migrations: %w", err)
+	}
+	if !table.Valid {
+		return status, nil
+	}
+
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&status.Version, &status.Dirty)
+	if err != nil && err != sql.ErrNoRows {
+		return status, fmt.Errorf("could not read schema version: %w", err)
+	}
+	return status, nil
+}
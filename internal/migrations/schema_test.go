@@ -0,0 +1,13 @@
+package migrations
+
+import "testing"
+
+func TestSchemaVersionMatchesNewestMigration(t *testing.T) {
+	latest, err := LatestVersion("../../migrations/postgres")
+	if err != nil {
+		t.Fatalf("LatestVersion failed: %v", err)
+	}
+	if latest != SchemaVersion {
+		t.Errorf("The newest migration is %d but SchemaVersion is %d; bump SchemaVersion with each migration", latest, SchemaVersion)
+	}
+}
@@ -0,0 +1,288 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	"periodic-api/internal/awsapi"
+	"periodic-api/internal/logging"
+)
+
+// Ways of snapshotting the database before migrating
+const (
+	SnapshotRDSCluster  = "rds-cluster"
+	SnapshotRDSInstance = "rds-instance"
+	SnapshotPgDump      = "pg_dump"
+)
+
+// snapshotTimeout is how long migrations wait for a snapshot to be taken
+const snapshotTimeout = 30 * time.Minute
+
+// snapshotPollInterval is how often an RDS snapshot is checked while it is being created
+const snapshotPollInterval = 15 * time.Second
+
+// Snapshotter backs up the database before migrations are applied to it
+type Snapshotter interface {
+	// Snapshot takes a backup of the database at version from, before migrating it to
+	// version to, and returns what identifies the backup when restoring it
+	Snapshot(ctx context.Context, from, to uint) (string, error)
+	// Method names the kind of backup taken, such as rds-cluster
+	Method() string
+}
+
+// SnapshotConfig says how to back up the database before migrating it
+type SnapshotConfig struct {
+	// Method is SnapshotRDSCluster, SnapshotRDSInstance or SnapshotPgDump
+	Method string
+	// Target is the DB cluster or instance identifier of an RDS snapshot
+	Target string
+	// Dir is the directory pg_dump writes its dumps to
+	Dir string
+	// Endpoint replaces the public regional RDS endpoint, for testing
+	Endpoint string
+}
+
+// SnapshotConfigFromEnv returns how to back up the database before migrating it, from
+// MIGRATION_SNAPSHOT, MIGRATION_SNAPSHOT_TARGET, MIGRATION_SNAPSHOT_DIR and
+// MIGRATION_SNAPSHOT_ENDPOINT, and whether snapshots are enabled
+func SnapshotConfigFromEnv() (SnapshotConfig, bool, error) {
+	cfg := SnapshotConfig{
+		Method:   os.Getenv("MIGRATION_SNAPSHOT"),
+		Target:   os.Getenv("MIGRATION_SNAPSHOT_TARGET"),
+		Dir:      os.Getenv("MIGRATION_SNAPSHOT_DIR"),
+		Endpoint: os.Getenv("MIGRATION_SNAPSHOT_ENDPOINT"),
+	}
+	switch cfg.Method {
+	case "":
+		return cfg, false, nil
+	case SnapshotRDSCluster, SnapshotRDSInstance:
+		if cfg.Target == "" {
+			return cfg, false, fmt.Errorf("MIGRATION_SNAPSHOT_TARGET must name the DB cluster or instance to snapshot")
+		}
+	case SnapshotPgDump:
+		if cfg.Dir == "" {
+			cfg.Dir = "."
+		}
+	default:
+		return cfg, false, fmt.Errorf("unknown MIGRATION_SNAPSHOT %q, use %s, %s or %s", cfg.Method, SnapshotRDSCluster, SnapshotRDSInstance, SnapshotPgDump)
+	}
+	return cfg, true, nil
+}
+
+// NewSnapshotter creates the snapshotter configured by cfg. pg_dump connects to the
+// database with the libpq environment variables in dumpEnv.
+func NewSnapshotter(ctx context.Context, cfg SnapshotConfig, dumpEnv []string) (Snapshotter, error) {
+	if cfg.Method == SnapshotPgDump {
+		return &pgDumpSnapshotter{dir: cfg.Dir, env: dumpEnv}, nil
+	}
+
+	awsConfig, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+	}
+	if awsConfig.Region == "" {
+		return nil, fmt.Errorf("no AWS region configured for RDS")
+	}
+	return &rdsSnapshotter{
+		client:  awsapi.NewClient(awsConfig, "rds", awsConfig.Region, cfg.Endpoint),
+		cluster: cfg.Method == SnapshotRDSCluster,
+		target:  cfg.Target,
+	}, nil
+}
+
+// snapshotName names the backup of the database taken before migrating it from one
+// version to another
+func snapshotName(from, to uint, now time.Time) string {
+	return fmt.Sprintf("periodic-premigrate-v%d-to-v%d-%s", from, to, now.UTC().Format("20060102-150405"))
+}
+
+// rdsSnapshotter takes manual RDS snapshots of an Aurora cluster or a DB instance
+type rdsSnapshotter struct {
+	client  *awsapi.Client
+	cluster bool
+	target  string
+}
+
+// Method names the kind of snapshot taken
+func (s *rdsSnapshotter) Method() string {
+	if s.cluster {
+		return SnapshotRDSCluster
+	}
+	return SnapshotRDSInstance
+}
+
+// Snapshot creates a snapshot and waits until it is available
+func (s *rdsSnapshotter) Snapshot(ctx context.Context, from, to uint) (string, error) {
+	name := snapshotName(from, to, time.Now())
+	create := url.Values{"Version": {"2014-10-31"}}
+	if s.cluster {
+		create.Set("Action", "CreateDBClusterSnapshot")
+		create.Set("DBClusterIdentifier", s.target)
+		create.Set("DBClusterSnapshotIdentifier", name)
+	} else {
+		create.Set("Action", "CreateDBSnapshot")
+		create.Set("DBInstanceIdentifier", s.target)
+		create.Set("DBSnapshotIdentifier", name)
+	}
+	if _, err := s.post(ctx, create); err != nil {
+		return "", err
+	}
+
+	logging.Infof("Waiting for RDS snapshot %s of %s...", name, s.target)
+	for {
+		status, err := s.status(ctx, name)
+		if err != nil {
+			return name, err
+		}
+		switch status {
+		case "available":
+			return name, nil
+		case "creating", "":
+		default:
+			return name, fmt.Errorf("RDS snapshot %s is %s", name, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return name, fmt.Errorf("RDS snapshot %s not available in time: %w", name, ctx.Err())
+		case <-time.After(snapshotPollInterval):
+		}
+	}
+}
+
+// status returns the status of a snapshot, such as creating or available
+func (s *rdsSnapshotter) status(ctx context.Context, name string) (string, error) {
+	describe := url.Values{"Version": {"2014-10-31"}}
+	var response struct {
+		ClusterStatus  string `xml:"DescribeDBClusterSnapshotsResult>DBClusterSnapshots>DBClusterSnapshot>Status"`
+		InstanceStatus string `xml:"DescribeDBSnapshotsResult>DBSnapshots>DBSnapshot>Status"`
+	}
+	if s.cluster {
+		describe.Set("Action", "DescribeDBClusterSnapshots")
+		describe.Set("DBClusterSnapshotIdentifier", name)
+	} else {
+		describe.Set("Action", "DescribeDBSnapshots")
+		describe.Set("DBSnapshotIdentifier", name)
+	}
+
+	body, err := s.post(ctx, describe)
+	if err != nil {
+		return "", err
+	}
+	if err := xml.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("invalid RDS response: %w", err)
+	}
+	return response.ClusterStatus + response.InstanceStatus, nil
+}
+
+// post sends a query API request to RDS
+func (s *rdsSnapshotter) post(ctx context.Context, form url.Values) ([]byte, error) {
+	return s.client.Post(ctx, []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+	})
+}
+
+// pgDumpSnapshotter dumps self-hosted databases with pg_dump, in its custom format for
+// pg_restore
+type pgDumpSnapshotter struct {
+	dir string
+	env []string
+}
+
+// Method names the kind of snapshot taken
+func (s *pgDumpSnapshotter) Method() string {
+	return SnapshotPgDump
+}
+
+// Snapshot dumps the database to a new file in the dump directory and returns its path
+func (s *pgDumpSnapshotter) Snapshot(ctx context.Context, from, to uint) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", fmt.Errorf("could not create the dump directory: %w", err)
+	}
+	path, err := filepath.Abs(filepath.Join(s.dir, snapshotName(from, to, time.Now())+".dump"))
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.CommandContext(ctx, "pg_dump", "--format=custom", "--file="+path)
+	cmd.Env = append(os.Environ(), s.env...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return path, fmt.Errorf("pg_dump failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return path, nil
+}
+
+// withSnapshot runs migrate after backing up db with snapshotter, when migrating it to
+// target changes its version, and records the snapshot with the outcome of the run. The
+// database isn't migrated when it can't be backed up. A nil snapshotter backs up nothing.
+func withSnapshot(db *sql.DB, snapshotter Snapshotter, target uint, migrate func() error) error {
+	if snapshotter == nil {
+		return migrate()
+	}
+
+	snapshot, from, err := snapshotBeforeMigrating(db, snapshotter, target)
+	if err != nil {
+		return err
+	}
+	migrateErr := migrate()
+	if snapshot != "" {
+		recordSnapshot(db, snapshotter.Method(), snapshot, from, target, migrateErr)
+		if migrateErr != nil {
+			logging.Errorf("Migrating failed; %s snapshot %s holds the database as it was at version %d", snapshotter.Method(), snapshot, from)
+		}
+	}
+	return migrateErr
+}
+
+// snapshotBeforeMigrating backs up db with snapshotter when migrating it to target would
+// apply or roll back migrations, returning the backup's identifier and the version it was
+// taken at. When the database is already at target nothing is backed up and the
+// identifier is empty.
+func snapshotBeforeMigrating(db *sql.DB, snapshotter Snapshotter, target uint) (string, uint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	status, err := ReadSchemaStatus(ctx, db)
+	if err != nil {
+		return "", 0, err
+	}
+	if status.Version == target {
+		return "", status.Version, nil
+	}
+
+	logging.Infof("Taking a %s snapshot before migrating from version %d to %d...", snapshotter.Method(), status.Version, target)
+	snapshot, err := snapshotter.Snapshot(ctx, status.Version, target)
+	if err != nil {
+		return "", status.Version, fmt.Errorf("could not snapshot the database before migrating, nothing was migrated: %w", err)
+	}
+	logging.Infof("Took %s snapshot %s", snapshotter.Method(), snapshot)
+	return snapshot, status.Version, nil
+}
+
+// recordSnapshot saves the snapshot taken before a migration run in migration_snapshots,
+// with the run's outcome. The snapshot is logged instead when the table doesn't exist,
+// such as when the migration creating it failed.
+func recordSnapshot(db *sql.DB, method, snapshot string, from, to uint, migrateErr error) {
+	var failure sql.NullString
+	if migrateErr != nil {
+		failure = sql.NullString{String: migrateErr.Error(), Valid: true}
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO migration_snapshots (method, snapshot, from_version, to_version, error)
+		VALUES ($1, $2, $3, $4, $5)
+	`, method, snapshot, from, to, failure)
+	if err != nil {
+		logging.Warnf("Failed to record %s snapshot %s taken before migrating from version %d to %d: %v", method, snapshot, from, to, err)
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSnapshotConfigFromEnv(t *testing.T) {
+	t.Setenv("MIGRATION_SNAPSHOT", "")
+	if _, enabled, err := SnapshotConfigFromEnv(); err != nil || enabled {
+		t.Errorf("Expected snapshots to be off by default, got %v: %v", enabled, err)
+	}
+
+	t.Setenv("MIGRATION_SNAPSHOT", SnapshotRDSCluster)
+	t.Setenv("MIGRATION_SNAPSHOT_TARGET", "")
+	if _, _, err := SnapshotConfigFromEnv(); err == nil {
+		t.Error("Expected an RDS snapshot without a target to be refused")
+	}
+	t.Setenv("MIGRATION_SNAPSHOT_TARGET", "periodic-cluster")
+	if cfg, enabled, err := SnapshotConfigFromEnv(); err != nil || !enabled || cfg.Target != "periodic-cluster" {
+		t.Errorf("Expected a snapshot of periodic-cluster, got %+v (enabled: %v): %v", cfg, enabled, err)
+	}
+
+	t.Setenv("MIGRATION_SNAPSHOT", SnapshotPgDump)
+	t.Setenv("MIGRATION_SNAPSHOT_DIR", "")
+	if cfg, _, err := SnapshotConfigFromEnv(); err != nil || cfg.Dir != "." {
+		t.Errorf("Expected dumps in the working directory, got %q: %v", cfg.Dir, err)
+	}
+
+	t.Setenv("MIGRATION_SNAPSHOT", "tape")
+	if _, _, err := SnapshotConfigFromEnv(); err == nil {
+		t.Error("Expected an unknown snapshot method to be refused")
+	}
+}
+
+func TestSnapshotName(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if name := snapshotName(28, 29, now); name != "periodic-premigrate-v28-to-v29-20260304-050607" {
+		t.Errorf("Unexpected snapshot name %s", name)
+	}
+}
+
+func TestWithoutSnapshotterMigrationsRunAsIs(t *testing.T) {
+	ran := false
+	if err := withSnapshot(nil, nil, 29, func() error { ran = true; return nil }); err != nil || !ran {
+		t.Errorf("Expected the migration to run without a snapshot, ran %v: %v", ran, err)
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Audited entity types
+const (
+	AuditEntityScheduledItem = "scheduled_item"
+	AuditEntityTodoItem      = "todo_item"
+	AuditEntityUser          = "user"
+)
+
+// AuditLogEntry records a change made through the API: who made it, to which entity, and
+// the entity before and after the change
+type AuditLogEntry struct {
+	ID int64 `json:"id" example:"1"`
+	// Actor identifies the user making the change, from X-User-ID or the client address
+	Actor      string `json:"actor" example:"42"`
+	Action     string `json:"action" example:"update"`
+	EntityType string `json:"entityType" example:"scheduled_item"`
+	EntityID   int64  `json:"entityId" example:"7"`
+	// Before is the entity before the change; it is omitted for creations
+	Before json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	// After is the entity after the change; it is omitted for deletions
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// The platforms devices register push tokens with
+const (
+	DevicePlatformFCM  = "fcm"
+	DevicePlatformAPNs = "apns"
+)
+
+// DevicePlatforms lists the platforms devices can register push tokens with
+var DevicePlatforms = []string{DevicePlatformFCM, DevicePlatformAPNs}
+
+// DeviceToken is the push token of a user's mobile device, which is pushed a notification
+// when the scheduler creates a todo for the user
+type DeviceToken struct {
+	UserID int64 `json:"userId" example:"1"`
+	// Platform is fcm for Firebase Cloud Messaging or apns for the Apple Push Notification service
+	Platform  string    `json:"platform" validate:"required" example:"fcm"`
+	Token     string    `json:"token" validate:"required" example:"fMEP0vJqS0:APA91bHqX3"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
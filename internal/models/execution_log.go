@@ -12,4 +12,7 @@ type ExecutionLog struct {
 	Status          string     `json:"status"`
 	ErrorMessage    *string    `json:"errorMessage,omitempty"`
 	TodoItemID      *int64     `json:"todoItemId,omitempty"`
+	ExecutionKey    *string    `json:"executionKey,omitempty"`
+	RequestID       string     `json:"requestId,omitempty"`
+	TenantID        string     `json:"-"`
 }
\ No newline at end of file
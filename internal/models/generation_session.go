@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Roles of the messages in a generation session
+const (
+	GenerationRoleUser      = "user"
+	GenerationRoleAssistant = "assistant"
+)
+
+// GenerationSession is a conversation refining a generated scheduled item until it is
+// committed. Sessions expire after a period without messages.
+type GenerationSession struct {
+	ID int64 `json:"id" example:"1"`
+	// UserID identifies the user the session's LLM usage is accounted to
+	UserID   string `json:"userId" example:"42"`
+	Timezone string `json:"timezone" example:"America/New_York"`
+	// Messages alternate between the user's requests and the item JSON generated in reply
+	Messages []GenerationMessage `json:"messages"`
+	// Item is the latest generated item, which committing the session creates
+	Item      ScheduledItem `json:"item"`
+	CreatedAt time.Time     `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+	UpdatedAt time.Time     `json:"updatedAt" example:"2024-01-01T08:05:00Z"`
+	ExpiresAt time.Time     `json:"expiresAt" example:"2024-01-01T09:05:00Z"`
+}
+
+// GenerationMessage is a message in a generation session
+type GenerationMessage struct {
+	Role      string    `json:"role" example:"user"`
+	Content   string    `json:"content" example:"Actually make it biweekly"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T08:05:00Z"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Roles of the users a scheduled item is shared with
+const (
+	// ItemShareRoleViewer users can read the item
+	ItemShareRoleViewer = "viewer"
+	// ItemShareRoleEditor users can also change and run the item
+	ItemShareRoleEditor = "editor"
+)
+
+// ItemShareRoles lists the roles items can be shared with
+var ItemShareRoles = []string{ItemShareRoleViewer, ItemShareRoleEditor}
+
+// ItemShare grants a user other than its owner access to a scheduled item
+type ItemShare struct {
+	ScheduledItemID int64 `json:"scheduledItemId" example:"1"`
+	UserID          int64 `json:"userId" example:"2"`
+	// Role is viewer or editor
+	Role      string    `json:"role" validate:"required" example:"viewer"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// LLM operations recorded in the usage log
+const (
+	LLMOperationGenerate = "generate"
+	LLMOperationModify   = "modify"
+	LLMOperationRefine   = "refine"
+)
+
+// LLMUsage records the model calls made for one generation request
+type LLMUsage struct {
+	ID int64 `json:"id" example:"1"`
+	// UserID identifies the requesting user, from X-User-ID or the client address
+	UserID    string `json:"userId" example:"42"`
+	Operation string `json:"operation" example:"generate"`
+	Provider  string `json:"provider" example:"bedrock"`
+	Model     string `json:"model" example:"us.amazon.nova-lite-v1:0"`
+	// Calls counts the attempts to call the model, including retries and repairs
+	Calls        int       `json:"calls" example:"1"`
+	InputTokens  int       `json:"inputTokens" example:"850"`
+	OutputTokens int       `json:"outputTokens" example:"120"`
+	LatencyMs    int64     `json:"latencyMs" example:"1450"`
+	Succeeded    bool      `json:"succeeded" example:"true"`
+	TenantID     string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+}
+
+// LLMUsageTotals sums a user's LLM usage over a period
+type LLMUsageTotals struct {
+	UserID       string `json:"userId" example:"42"`
+	Requests     int    `json:"requests" example:"12"`
+	InputTokens  int    `json:"inputTokens" example:"10200"`
+	OutputTokens int    `json:"outputTokens" example:"1440"`
+}
+
+// Tokens returns the input and output tokens together
+func (t LLMUsageTotals) Tokens() int {
+	return t.InputTokens + t.OutputTokens
+}
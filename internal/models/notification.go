@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// The events users choose to be notified about
+const (
+	NotificationEventExecution = "execution"
+	NotificationEventFailure   = "failure"
+	NotificationEventReminder  = "reminder"
+	NotificationEventDigest    = "digest"
+)
+
+// The channels users choose to be notified through
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSlack = "slack"
+	NotificationChannelPush  = "push"
+)
+
+// NotificationEvents lists the events users can choose to be notified about
+var NotificationEvents = []string{NotificationEventExecution, NotificationEventFailure, NotificationEventReminder, NotificationEventDigest}
+
+// NotificationChannels lists the channels users can choose to be notified through
+var NotificationChannels = []string{NotificationChannelEmail, NotificationChannelSlack, NotificationChannelPush}
+
+// NotificationPreferences selects which executions of an item are notified and how the
+// messages read. Subject and Body are Go text/template templates given the item and its
+// execution log; defaults are used when they are empty.
+type NotificationPreferences struct {
+	OnSuccess bool   `json:"onSuccess" example:"false"`
+	OnFailure bool   `json:"onFailure" example:"true"`
+	Subject   string `json:"subject,omitempty" example:"{{.Item.Title}} failed"`
+	Body      string `json:"body,omitempty" example:"{{.Item.Title}} failed at {{.Execution.ExecutedAt}}: {{.Error}}"`
+}
+
+// NotificationSettings selects who is notified about the executions of a scheduled item.
+// The addresses in Email are sent the item's messages; the users in UserIDs are notified
+// following their NotificationPreference, or at their email address following the item's
+// settings when they have none. With Slack set, or a SlackChannel to override the
+// configured channel, the item's messages are also posted to Slack.
+type NotificationSettings struct {
+	NotificationPreferences
+	Email        []string `json:"email,omitempty" example:"ops@example.com"`
+	UserIDs      []int64  `json:"userIds,omitempty"`
+	Slack        bool     `json:"slack,omitempty" example:"true"`
+	SlackChannel string   `json:"slackChannel,omitempty" example:"#ops-alerts"`
+}
+
+// NotificationPreference is how a user wants to be notified: through which channels,
+// about which events and outside which quiet hours. Subject and Body override the
+// templates of the items that list the user.
+type NotificationPreference struct {
+	UserID   int64    `json:"userId" example:"1"`
+	Channels []string `json:"channels" example:"email,slack"`
+	Events   []string `json:"events" example:"failure,digest"`
+	// SlackUserID is the Slack member the slack channel sends direct messages to
+	SlackUserID string `json:"slackUserId,omitempty" example:"U0123456789"`
+	// QuietHoursStart and QuietHoursEnd are the local times (HH:MM) between which nothing is
+	// sent; the quiet hours may span midnight
+	QuietHoursStart string `json:"quietHoursStart,omitempty" example:"22:00"`
+	QuietHoursEnd   string `json:"quietHoursEnd,omitempty" example:"07:00"`
+	// Timezone is the IANA time zone of the quiet hours, UTC when empty
+	Timezone  string    `json:"timezone,omitempty" example:"Europe/London"`
+	Subject   string    `json:"subject,omitempty" example:"{{.Item.Title}} needs attention"`
+	Body      string    `json:"body,omitempty" example:"{{.Item.Title}}: {{.Error}}"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Roles of the members of an organization
+const (
+	// OrganizationRoleOwner members manage the organization and its membership
+	OrganizationRoleOwner = "owner"
+	// OrganizationRoleMember members share the organization's items and todos
+	OrganizationRoleMember = "member"
+)
+
+// OrganizationRoles lists the roles of organization members
+var OrganizationRoles = []string{OrganizationRoleOwner, OrganizationRoleMember}
+
+// Organization is a team, such as a household or an on-call rotation, whose members share
+// the scheduled items and todos that belong to it
+type Organization struct {
+	ID          int64     `json:"id" example:"1"`
+	Name        string    `json:"name" validate:"required" example:"On-call rotation"`
+	Description string    `json:"description,omitempty" example:"Platform team on-call"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// OrganizationMember is a user's membership of an organization
+type OrganizationMember struct {
+	OrganizationID int64 `json:"organizationId" example:"1"`
+	UserID         int64 `json:"userId" example:"2"`
+	// Role is owner or member
+	Role      string    `json:"role" validate:"required" example:"member"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OrganizationInvitation invites whoever owns an email address to join an organization
+// with a role. The invited user joins by accepting the invitation's token before it expires.
+type OrganizationInvitation struct {
+	ID             int64  `json:"id" example:"1"`
+	OrganizationID int64  `json:"organizationId" example:"1"`
+	Email          string `json:"email" validate:"required" example:"bob@example.com"`
+	// Role is owner or member
+	Role string `json:"role" validate:"required" example:"member"`
+	// Token is only returned when the invitation is created; only its hash is stored
+	Token      string     `json:"token,omitempty" example:"6f1c0b9e..."`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
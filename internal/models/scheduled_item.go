@@ -1,17 +1,31 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
-// ScheduledItem represents the data model for our CRUD operations
+// ScheduledItem represents the data model for our CRUD operations. Like todo items, users
+// and execution logs, it belongs to the tenant it was created in, which the API never exposes.
 type ScheduledItem struct {
-	ID              int64      `json:"id" example:"1"`
-	Title           string     `json:"title" example:"Daily standup meeting"`
-	Description     string     `json:"description" example:"Team daily standup meeting to discuss progress"`
-	StartsAt        time.Time  `json:"startsAt" example:"2024-01-01T09:00:00Z"`
-	Repeats         bool       `json:"repeats" example:"true"`
-	CronExpression  *string    `json:"cronExpression,omitempty" example:"0 9 * * 1-5"`
-	Expiration      *time.Time `json:"expiration,omitempty" example:"2024-12-31T23:59:59Z"`
-	NextExecutionAt time.Time  `json:"nextExecutionAt" example:"2024-01-02T09:00:00Z"`
+	ID              int64                 `json:"id" example:"1"`
+	Title           string                `json:"title" validate:"required" example:"Daily standup meeting"`
+	Description     string                `json:"description" example:"Team daily standup meeting to discuss progress"`
+	StartsAt        time.Time             `json:"startsAt" validate:"required" example:"2024-01-01T09:00:00Z"`
+	Repeats         bool                  `json:"repeats" example:"true"`
+	CronExpression  *string               `json:"cronExpression,omitempty" example:"0 9 * * 1-5"`
+	Expiration      *time.Time            `json:"expiration,omitempty" example:"2024-12-31T23:59:59Z"`
+	NextExecutionAt time.Time             `json:"nextExecutionAt" example:"2024-01-02T09:00:00Z"`
+	ActionType      string                `json:"actionType,omitempty" example:"todo"`
+	ActionConfig    json.RawMessage       `json:"actionConfig,omitempty" swaggertype:"object"`
+	JitterSeconds   int                   `json:"jitterSeconds,omitempty" example:"300"`
+	Paused          bool                  `json:"paused,omitempty" example:"false"`
+	Notifications   *NotificationSettings `json:"notifications,omitempty"`
+	OrganizationID  *int64                `json:"organizationId,omitempty" example:"1"`
+	OwnerID         *int64                `json:"ownerId,omitempty" example:"1"`
+	TenantID        string                `json:"-"`
+	Version         int64                 `json:"version" example:"1"`
+	RequestID       string                `json:"requestId,omitempty" example:"3f2b8c1e9a7d4f6012ab34cd56ef7890"`
+	CreatedAt       time.Time             `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+	UpdatedAt       time.Time             `json:"updatedAt" example:"2024-01-01T08:00:00Z"`
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SchedulerHeartbeat represents the latest reported state of a scheduler instance
+type SchedulerHeartbeat struct {
+	InstanceID string    `json:"instanceId"`
+	StartedAt  time.Time `json:"startedAt"`
+	LastTickAt time.Time `json:"lastTickAt"`
+	// LastSuccessAt is the last tick that claimed the due items without error
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"`
+	// IntervalMs is the instance's processing interval, which stalls are measured against
+	IntervalMs     int64 `json:"intervalMs"`
+	ItemsProcessed int64 `json:"itemsProcessed"`
+	ErrorCount     int64 `json:"errorCount"`
+}
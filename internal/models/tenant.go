@@ -0,0 +1,14 @@
+package models
+
+// TenantSummary counts what a tenant has, for administrators
+type TenantSummary struct {
+	Tenant         string `json:"tenant" example:"acme"`
+	Users          int    `json:"users" example:"12"`
+	ScheduledItems int    `json:"scheduledItems" example:"48"`
+	TodoItems      int    `json:"todoItems" example:"30"`
+	Webhooks       int    `json:"webhooks" example:"2"`
+	// GenerationsToday counts the generation requests the tenant's users made in the current UTC day
+	GenerationsToday int `json:"generationsToday" example:"7"`
+	// Limits are the limits the tenant is held to
+	Limits TenantLimits `json:"limits"`
+}
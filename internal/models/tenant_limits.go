@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// TenantLimits caps what a tenant may use; 0 means unlimited. Tenants without limits of
+// their own get the deployment's defaults.
+type TenantLimits struct {
+	Tenant string `json:"tenant" example:"acme"`
+	// MaxScheduledItems caps the scheduled items the tenant may have at once
+	MaxScheduledItems int `json:"maxScheduledItems" example:"100"`
+	// MaxDailyGenerations caps the generation requests the tenant's users may make per UTC day
+	MaxDailyGenerations int `json:"maxDailyGenerations" example:"50"`
+	// MaxWebhooks caps the webhooks the tenant may have at once
+	MaxWebhooks int `json:"maxWebhooks" example:"5"`
+	// UpdatedAt is when the limits were last adjusted; it is omitted for the defaults
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -1,8 +1,15 @@
 package models
 
-// TodoItem represents a to-do item with a text description and checked status
+import "time"
+
+// TodoItem represents a to-do item with a text description and checked status, optionally
+// belonging to an organization
 type TodoItem struct {
-	ID      int64  `json:"id"`
-	Text    string `json:"text"`
-	Checked bool   `json:"checked"`
+	ID             int64     `json:"id"`
+	Text           string    `json:"text"`
+	Checked        bool      `json:"checked"`
+	OrganizationID *int64    `json:"organizationId,omitempty" example:"1"`
+	TenantID       string    `json:"-"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
 }
\ No newline at end of file
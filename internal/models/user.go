@@ -1,8 +1,18 @@
 package models
 
+import "time"
+
 // User represents the data model for user objects
 type User struct {
 	ID           int64  `json:"id"`
-	Username     string `json:"username"`
-	PasswordHash []byte `json:"passwordHash"`
+	Username     string `json:"username" validate:"required"`
+	PasswordHash []byte `json:"passwordHash" swaggertype:"string" format:"byte"`
+	// Email is where the user is sent notifications about the items that list them
+	Email    string `json:"email,omitempty" example:"alice@example.com"`
+	TenantID string `json:"-"`
+	// DeactivatedAt is when an administrator deactivated the user, who can no longer make
+	// requests or be notified; it is omitted for active users and can't be set through /users
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
 }
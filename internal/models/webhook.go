@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Webhook is an endpoint that is sent the events it subscribes to. Webhooks registered by
+// a user are only sent the events about the scheduled items their owner may see.
+type Webhook struct {
+	ID         int64    `json:"id" example:"1"`
+	URL        string   `json:"url" validate:"required" example:"https://example.com/hooks/periodic"`
+	EventTypes []string `json:"eventTypes" validate:"required" example:"scheduled_item.executed,scheduled_item.failed,todo.created"`
+	// Secret signs deliveries; it is generated when not provided and only returned on creation
+	Secret    string    `json:"secret,omitempty" example:"3f1c9a..."`
+	Active    bool      `json:"active" example:"true"`
+	OwnerID   *int64    `json:"ownerId,omitempty" example:"1"`
+	TenantID  string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2024-01-01T08:00:00Z"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID           int64     `json:"id" example:"1"`
+	WebhookID    int64     `json:"webhookId" example:"1"`
+	EventID      int64     `json:"eventId" example:"42"`
+	EventType    string    `json:"eventType" example:"scheduled_item.executed"`
+	Attempt      int       `json:"attempt" example:"1"`
+	StatusCode   *int      `json:"statusCode,omitempty" example:"200"`
+	ErrorMessage *string   `json:"errorMessage,omitempty"`
+	Succeeded    bool      `json:"succeeded" example:"true"`
+	DeliveredAt  time.Time `json:"deliveredAt" example:"2024-01-01T09:00:00Z"`
+	DurationMs   int64     `json:"durationMs" example:"120"`
+}
@@ -0,0 +1,294 @@
+// Package mqtt implements the small part of MQTT 3.1.1 needed to publish messages to a broker
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPuback     = 4
+	packetPubrec     = 5
+	packetPubrel     = 6
+	packetPubcomp    = 7
+	packetDisconnect = 14
+)
+
+// keepAlive is the keep alive announced to the broker; connections only live for one publish
+const keepAlive = 60
+
+// Client publishes messages to an MQTT broker. Each publish uses its own short-lived
+// connection with a clean session, which suits the low rate at which scheduled items fire
+// and needs no reconnection handling.
+type Client struct {
+	config      Config
+	connections atomic.Int64
+}
+
+// NewClient creates a client for the broker in config
+func NewClient(config Config) *Client {
+	return &Client{
+		config: config,
+	}
+}
+
+// DefaultQoS returns the quality of service used when a message doesn't set its own
+func (c *Client) DefaultQoS() byte {
+	return c.config.QoS
+}
+
+// DefaultTopic returns the topic prefix used when a message doesn't set its own topic
+func (c *Client) DefaultTopic() string {
+	return c.config.Topic
+}
+
+// Publish connects to the broker, publishes the message with the given quality of service
+// and waits for the broker to acknowledge it as QoS 1 and 2 require
+func (c *Client) Publish(ctx context.Context, topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 2 {
+		return fmt.Errorf("invalid QoS %d", qos)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	reader := bufio.NewReader(conn)
+
+	if err := c.connect(conn, reader); err != nil {
+		return err
+	}
+
+	const packetID = 1
+	flags := qos << 1
+	if retain {
+		flags |= 1
+	}
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetID)
+	}
+	body = append(body, payload...)
+	if err := writePacket(conn, packetPublish<<4|flags, body); err != nil {
+		return fmt.Errorf("failed to send PUBLISH: %w", err)
+	}
+
+	switch qos {
+	case 1:
+		if err := expectAck(reader, packetPuback, packetID); err != nil {
+			return err
+		}
+	case 2:
+		if err := expectAck(reader, packetPubrec, packetID); err != nil {
+			return err
+		}
+		if err := writePacket(conn, packetPubrel<<4|0x02, binary.BigEndian.AppendUint16(nil, packetID)); err != nil {
+			return fmt.Errorf("failed to send PUBREL: %w", err)
+		}
+		if err := expectAck(reader, packetPubcomp, packetID); err != nil {
+			return err
+		}
+	}
+
+	// The message is delivered; a failed DISCONNECT doesn't change that
+	writePacket(conn, packetDisconnect<<4, nil)
+	return nil
+}
+
+// dial opens a TCP or TLS connection to the broker
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	broker, err := url.Parse(c.config.BrokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MQTT broker URL: %w", err)
+	}
+
+	useTLS := false
+	defaultPort := "1883"
+	switch strings.ToLower(broker.Scheme) {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+		defaultPort = "8883"
+	default:
+		return nil, fmt.Errorf("unsupported MQTT broker scheme %q", broker.Scheme)
+	}
+
+	address := broker.Host
+	if broker.Port() == "" {
+		address = net.JoinHostPort(broker.Hostname(), defaultPort)
+	}
+
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	if useTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: broker.Hostname()}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	return conn, nil
+}
+
+// connect sends CONNECT and waits for the broker to accept it
+func (c *Client) connect(conn net.Conn, reader *bufio.Reader) error {
+	// Suffix the client ID so concurrent publishes don't take over each other's sessions
+	clientID := fmt.Sprintf("%s-%d", c.config.ClientID, c.connections.Add(1))
+
+	var flags byte = 0x02 // clean session
+	if c.config.Username != "" {
+		flags |= 0x80
+		if c.config.Password != "" {
+			flags |= 0x40
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAlive)
+	body = appendString(body, clientID)
+	if c.config.Username != "" {
+		body = appendString(body, c.config.Username)
+		if c.config.Password != "" {
+			body = appendString(body, c.config.Password)
+		}
+	}
+	if err := writePacket(conn, packetConnect<<4, body); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	packetType, ack, err := readPacket(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read CONNACK: %w", err)
+	}
+	if packetType>>4 != packetConnack || len(ack) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %d", packetType>>4)
+	}
+	if ack[1] != 0 {
+		return fmt.Errorf("MQTT broker refused connection: %s", connackReason(ack[1]))
+	}
+	return nil
+}
+
+// expectAck reads the acknowledgement of the given type for a packet ID
+func expectAck(reader *bufio.Reader, expected byte, packetID uint16) error {
+	packetType, body, err := readPacket(reader)
+	if err != nil {
+		return fmt.Errorf("failed to read acknowledgement: %w", err)
+	}
+	if packetType>>4 != expected || len(body) != 2 || binary.BigEndian.Uint16(body) != packetID {
+		return fmt.Errorf("expected acknowledgement type %d for packet %d, got type %d", expected, packetID, packetType>>4)
+	}
+	return nil
+}
+
+// connackReason describes a CONNACK return code
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "client identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// writePacket writes a control packet with the given first byte and body
+func writePacket(w io.Writer, header byte, body []byte) error {
+	packet := []byte{header}
+	// The remaining length is a variable length integer of 7 bits per byte
+	length := len(body)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if length == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+	_, err := w.Write(packet)
+	return err
+}
+
+// readPacket reads a control packet and returns its first byte and body
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length := 0
+	for multiplier := 1; ; multiplier *= 128 {
+		if multiplier > 128*128*128 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+// ValidateTopic checks that a topic can be published to: it must be non-empty and can't
+// contain the + and # wildcards, which are only valid in subscriptions
+func ValidateTopic(topic string) error {
+	if topic == "" {
+		return errors.New("topic cannot be empty")
+	}
+	if strings.ContainsAny(topic, "+#") {
+		return errors.New("topic cannot contain wildcards")
+	}
+	if len(topic) > 65535 {
+		return errors.New("topic is too long")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// publishedMessage is what the fake broker received
+type publishedMessage struct {
+	connectFlags byte
+	header       byte
+	topic        string
+	payload      string
+}
+
+// startFakeBroker accepts one connection, acknowledges CONNECT and a QoS 1 PUBLISH, and
+// reports what it received
+func startFakeBroker(t *testing.T) (string, <-chan publishedMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan publishedMessage, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		reader := bufio.NewReader(conn)
+
+		var message publishedMessage
+		_, connect, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+		message.connectFlags = connect[7]
+		writePacket(conn, packetConnack<<4, []byte{0, 0})
+
+		header, publish, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+		topicLength := int(binary.BigEndian.Uint16(publish))
+		message.header = header
+		message.topic = string(publish[2 : 2+topicLength])
+		packetID := publish[2+topicLength : 4+topicLength]
+		message.payload = string(publish[4+topicLength:])
+		writePacket(conn, packetPuback<<4, packetID)
+
+		received <- message
+	}()
+
+	return "tcp://" + listener.Addr().String(), received
+}
+
+func TestPublishWaitsForPuback(t *testing.T) {
+	brokerURL, received := startFakeBroker(t)
+	client := NewClient(Config{
+		BrokerURL: brokerURL,
+		Username:  "periodic",
+		Password:  "secret",
+		ClientID:  "test",
+		Timeout:   time.Second,
+	})
+
+	if err := client.Publish(context.Background(), "home/chores/1", []byte("ON"), 1, true); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	message := <-received
+	if message.connectFlags != 0xC2 {
+		t.Errorf("Expected username, password and clean session flags, got %#x", message.connectFlags)
+	}
+	if message.header != packetPublish<<4|0x02|0x01 {
+		t.Errorf("Expected a retained QoS 1 PUBLISH, got header %#x", message.header)
+	}
+	if message.topic != "home/chores/1" || message.payload != "ON" {
+		t.Errorf("Unexpected message %+v", message)
+	}
+}
+
+func TestValidateTopicRejectsWildcards(t *testing.T) {
+	for _, topic := range []string{"", "home/+/chores", "home/#"} {
+		if err := ValidateTopic(topic); err == nil {
+			t.Errorf("Expected topic %q to be rejected", topic)
+		}
+	}
+	if err := ValidateTopic("home/chores"); err != nil {
+		t.Errorf("Expected topic to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package mqtt
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultClientID prefixes the client ID of each connection when MQTT_CLIENT_ID is not set
+	defaultClientID = "periodic-api"
+	// defaultQoS is the quality of service used when MQTT_QOS is not set
+	defaultQoS = 1
+	// defaultTopic is the topic prefix used when MQTT_TOPIC is not set
+	defaultTopic = "periodic/scheduled-items"
+	// defaultTimeout bounds connecting and publishing when MQTT_TIMEOUT is not set
+	defaultTimeout = 10 * time.Second
+)
+
+// Config configures the connection to an MQTT broker
+type Config struct {
+	// BrokerURL is the broker address, such as tcp://localhost:1883 or ssl://broker:8883
+	BrokerURL string
+	Username  string
+	Password  string
+	// ClientID prefixes the client ID of each connection
+	ClientID string
+	// QoS is the default quality of service of published messages (0, 1 or 2)
+	QoS byte
+	// Topic is the default topic prefix; messages go to <Topic>/<scheduled item ID>
+	Topic string
+	// Timeout bounds connecting to the broker and publishing a message
+	Timeout time.Duration
+}
+
+// ConfigFromEnv returns the broker configuration from the MQTT_BROKER_URL, MQTT_USERNAME,
+// MQTT_PASSWORD, MQTT_CLIENT_ID, MQTT_QOS, MQTT_TOPIC and MQTT_TIMEOUT environment
+// variables, and whether MQTT is enabled
+func ConfigFromEnv() (Config, bool) {
+	config := Config{
+		BrokerURL: os.Getenv("MQTT_BROKER_URL"),
+		Username:  os.Getenv("MQTT_USERNAME"),
+		Password:  os.Getenv("MQTT_PASSWORD"),
+		ClientID:  defaultClientID,
+		QoS:       defaultQoS,
+		Topic:     defaultTopic,
+		Timeout:   defaultTimeout,
+	}
+	if config.BrokerURL == "" {
+		return config, false
+	}
+
+	if clientID := os.Getenv("MQTT_CLIENT_ID"); clientID != "" {
+		config.ClientID = clientID
+	}
+	if topic := os.Getenv("MQTT_TOPIC"); topic != "" {
+		config.Topic = topic
+	}
+	if qosStr := os.Getenv("MQTT_QOS"); qosStr != "" {
+		if qos, err := strconv.Atoi(qosStr); err == nil && qos >= 0 && qos <= 2 {
+			config.QoS = byte(qos)
+		} else {
+			logging.Warnf("Invalid MQTT_QOS, using default: %d", config.QoS)
+		}
+	}
+	if timeoutStr := os.Getenv("MQTT_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout > 0 {
+			config.Timeout = timeout
+		} else {
+			logging.Warnf("Invalid MQTT_TIMEOUT format, using default: %v", config.Timeout)
+		}
+	}
+	return config, true
+}
@@ -0,0 +1,222 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+const (
+	// defaultAlertErrorRate is the share of failed items in a batch that raises an alert
+	defaultAlertErrorRate = 0.5
+	// defaultAlertMinBatch is the number of items a batch needs for its error rate to count
+	defaultAlertMinBatch = 5
+	// defaultAlertThrottle is how long an alert is not repeated after it is sent
+	defaultAlertThrottle = time.Hour
+)
+
+// AlertConfig selects where operator alerts about scheduler failures go and when they fire
+type AlertConfig struct {
+	// Email are the addresses alerts are emailed to
+	Email []string
+	// SlackChannel is the channel alerts are posted in; "default" posts to the configured channel
+	SlackChannel string
+	// ErrorRate is the share of failed items in a batch, from 0 to 1, that raises an alert
+	ErrorRate float64
+	// MinBatch is the number of executed items a batch needs before its error rate counts
+	MinBatch int
+	// Throttle is how long the same alert is held back after it is sent
+	Throttle time.Duration
+}
+
+// AlertConfigFromEnv returns the alert configuration from the ALERTS_EMAIL,
+// ALERTS_SLACK_CHANNEL, ALERTS_ERROR_RATE, ALERTS_MIN_BATCH and ALERTS_THROTTLE environment
+// variables, and whether alerts are enabled, logging invalid values replaced with defaults
+func AlertConfigFromEnv() (AlertConfig, bool) {
+	config, problems := parseAlertConfigEnv()
+	for _, problem := range problems {
+		logging.Warnf("Alert configuration: %v", problem)
+	}
+	return config, len(config.Email) > 0 || config.SlackChannel != ""
+}
+
+// CheckAlertConfigEnv reports the invalid alert settings that AlertConfigFromEnv would
+// replace with defaults
+func CheckAlertConfigEnv() error {
+	_, problems := parseAlertConfigEnv()
+	return errors.Join(problems...)
+}
+
+// parseAlertConfigEnv reads the alert configuration, returning the defaults used in place
+// of invalid values along with a description of each
+func parseAlertConfigEnv() (AlertConfig, []error) {
+	config := AlertConfig{
+		SlackChannel: strings.TrimSpace(os.Getenv("ALERTS_SLACK_CHANNEL")),
+		ErrorRate:    defaultAlertErrorRate,
+		MinBatch:     defaultAlertMinBatch,
+		Throttle:     defaultAlertThrottle,
+	}
+	var problems []error
+
+	for _, address := range strings.Split(os.Getenv("ALERTS_EMAIL"), ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if err := ValidateAddress(address); err != nil {
+			problems = append(problems, fmt.Errorf("ALERTS_EMAIL: %w", err))
+			continue
+		}
+		config.Email = append(config.Email, address)
+	}
+	if rateStr := os.Getenv("ALERTS_ERROR_RATE"); rateStr != "" {
+		if rate, err := strconv.ParseFloat(rateStr, 64); err == nil && rate > 0 && rate <= 1 {
+			config.ErrorRate = rate
+		} else {
+			problems = append(problems, fmt.Errorf("invalid ALERTS_ERROR_RATE %q, using default: %v", rateStr, config.ErrorRate))
+		}
+	}
+	if minStr := os.Getenv("ALERTS_MIN_BATCH"); minStr != "" {
+		if minBatch, err := strconv.Atoi(minStr); err == nil && minBatch > 0 {
+			config.MinBatch = minBatch
+		} else {
+			problems = append(problems, fmt.Errorf("invalid ALERTS_MIN_BATCH %q, using default: %d", minStr, config.MinBatch))
+		}
+	}
+	if throttleStr := os.Getenv("ALERTS_THROTTLE"); throttleStr != "" {
+		if throttle, err := time.ParseDuration(throttleStr); err == nil && throttle > 0 {
+			config.Throttle = throttle
+		} else {
+			problems = append(problems, fmt.Errorf("invalid ALERTS_THROTTLE %q, using default: %v", throttleStr, config.Throttle))
+		}
+	}
+	return config, problems
+}
+
+// Alerter tells operators when the scheduler fails: when too many items of a batch fail,
+// when due items can't be claimed, and when an item fails. Each alert is sent at most
+// once per throttle window, counting the ones held back in the next.
+type Alerter struct {
+	config AlertConfig
+	email  Notifier
+	slack  Notifier
+	now    func() time.Time
+
+	mu sync.Mutex
+	// sent is when each alert was last sent, and suppressed how many were held back since
+	sent       map[string]time.Time
+	suppressed map[string]int
+	// pending tracks the alerts being sent in the background
+	pending sync.WaitGroup
+}
+
+// NewAlerter creates an alerter sending through the email and Slack notifiers of dispatcher
+func NewAlerter(config AlertConfig, dispatcher *Dispatcher) *Alerter {
+	return &Alerter{
+		config:     config,
+		email:      dispatcher.email,
+		slack:      dispatcher.slack,
+		now:        time.Now,
+		sent:       map[string]time.Time{},
+		suppressed: map[string]int{},
+	}
+}
+
+// BatchProcessed alerts when the due items couldn't be claimed, or when the share of failed
+// items in a batch of at least the minimum size reaches the error rate
+func (a *Alerter) BatchProcessed(succeeded int, failed int, claimErr error) {
+	if claimErr != nil {
+		a.alert("claim", "Scheduler cannot claim due items",
+			fmt.Sprintf("The scheduler failed to claim the items due for execution: %v", claimErr))
+		return
+	}
+
+	executed := succeeded + failed
+	if executed < a.config.MinBatch || float64(failed) < a.config.ErrorRate*float64(executed) {
+		return
+	}
+	a.alert("batch", fmt.Sprintf("Scheduler batch failing: %d of %d items failed", failed, executed),
+		fmt.Sprintf("%d of the %d items executed in a scheduler batch failed, at or above the alert threshold of %.0f%%. See the execution logs for the errors.",
+			failed, executed, a.config.ErrorRate*100))
+}
+
+// ItemFailed alerts about a failed execution of an item. A failing item is retried once its
+// claim lapses, so its repeated failures are held back by the throttle.
+func (a *Alerter) ItemFailed(item models.ScheduledItem, execution models.ExecutionLog) {
+	message := "unknown error"
+	if execution.ErrorMessage != nil {
+		message = *execution.ErrorMessage
+	}
+	a.alert(fmt.Sprintf("item:%d", item.ID), fmt.Sprintf("Scheduled item %d failing: %s", item.ID, item.Title),
+		fmt.Sprintf("Scheduled item %q (ID %d) failed at %s and will be retried.\n\nError: %s",
+			item.Title, item.ID, execution.ExecutedAt.UTC().Format("2006-01-02 15:04:05 MST"), message))
+}
+
+// Wait blocks until the alerts being sent in the background are done
+func (a *Alerter) Wait() {
+	a.pending.Wait()
+}
+
+// alert sends an alert in the background unless the same alert was sent within the
+// throttle window, logging failures
+func (a *Alerter) alert(key string, subject string, body string) {
+	a.mu.Lock()
+	now := a.now()
+	if last, ok := a.sent[key]; ok && now.Sub(last) < a.config.Throttle {
+		a.suppressed[key]++
+		a.mu.Unlock()
+		logging.Debugf("Holding back alert %s: already sent at %v", key, last)
+		return
+	}
+	if count := a.suppressed[key]; count > 0 {
+		body += fmt.Sprintf("\n\n%d more alerts like this were held back since the last one.", count)
+	}
+	a.sent[key] = now
+	delete(a.suppressed, key)
+	a.mu.Unlock()
+
+	logging.Warnf("Alert: %s", subject)
+	a.pending.Add(1)
+	go func() {
+		defer a.pending.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+
+		if err := a.send(ctx, Message{Subject: "[periodic] " + subject, Body: body}); err != nil {
+			logging.Errorf("Failed to send alert %s: %v", key, err)
+		}
+	}()
+}
+
+// send delivers an alert to the configured addresses and Slack channel
+func (a *Alerter) send(ctx context.Context, message Message) error {
+	var errs []error
+	if len(a.config.Email) > 0 {
+		if a.email == nil {
+			errs = append(errs, fmt.Errorf("email is not configured"))
+		} else {
+			message.To = a.config.Email
+			errs = append(errs, deliver(ctx, a.email, "email", message))
+		}
+	}
+	if a.config.SlackChannel != "" {
+		if a.slack == nil {
+			errs = append(errs, fmt.Errorf("Slack is not configured"))
+		} else {
+			message.To = []string{a.config.SlackChannel}
+			if a.config.SlackChannel == "default" {
+				message.To = nil
+			}
+			errs = append(errs, deliver(ctx, a.slack, "Slack", message))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package notifications
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+
+	"github.com/robfig/cron/v3"
+)
+
+// maxDigestOccurrences bounds the occurrences of each item listed in a digest
+const maxDigestOccurrences = 10
+
+// ItemLister lists the scheduled items whose occurrences go into digests
+type ItemLister interface {
+	GetAllScheduledItems(ctx context.Context) []models.ScheduledItem
+}
+
+// TodoLister lists the todos whose open ones go into digests
+type TodoLister interface {
+	GetAllTodoItems(ctx context.Context) []models.TodoItem
+}
+
+// UserLister lists the users digests are sent to, and the tenants they belong to
+type UserLister interface {
+	GetAllUsers(ctx context.Context) []models.User
+	GetTenants(ctx context.Context) []string
+}
+
+// DigestOccurrence is an upcoming occurrence of an item, in the user's time zone
+type DigestOccurrence struct {
+	Item models.ScheduledItem
+	At   time.Time
+}
+
+// DigestData is what digest templates are executed with
+type DigestData struct {
+	User        models.User
+	From        time.Time
+	Until       time.Time
+	Occurrences []DigestOccurrence
+	Todos       []models.TodoItem
+}
+
+// Digester periodically sends users a summary of the occurrences of the items that list
+// them, until the next digest is due, and of the open todos
+type Digester struct {
+	schedule   cron.Schedule
+	dispatcher *Dispatcher
+	items      ItemLister
+	todos      TodoLister
+	users      UserLister
+}
+
+// NewDigester creates a digester sending digests through dispatcher at the times of the
+// cron expression schedule, such as "0 8 * * *" for daily or "0 8 * * 1" for weekly digests
+func NewDigester(schedule string, dispatcher *Dispatcher, items ItemLister, todos TodoLister, users UserLister) (*Digester, error) {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	parsed, err := parser.Parse(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest schedule '%s': %w", schedule, err)
+	}
+	return &Digester{
+		schedule:   parsed,
+		dispatcher: dispatcher,
+		items:      items,
+		todos:      todos,
+		users:      users,
+	}, nil
+}
+
+// Run sends digests whenever they are due until ctx is cancelled
+func (d *Digester) Run(ctx context.Context) {
+	for {
+		next := d.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := d.Send(sendCtx, next); err != nil {
+			logging.Errorf("Failed to send digests: %v", err)
+		}
+		cancel()
+	}
+}
+
+// Send sends the digests due at the given time, covering the occurrences until the next
+// digest. Only users whose preferences include the digest event are sent one, through
+// their channels, and nothing is sent during their quiet hours. Each tenant's users are
+// only sent the items and todos of their own tenant.
+func (d *Digester) Send(ctx context.Context, at time.Time) error {
+	var errs []error
+	for _, tenant := range d.users.GetTenants(ctx) {
+		errs = append(errs, d.sendTenant(store.WithTenant(ctx, tenant), at))
+	}
+	return errors.Join(errs...)
+}
+
+// sendTenant sends the digests due at the given time to the users of the context's tenant
+func (d *Digester) sendTenant(ctx context.Context, at time.Time) error {
+	until := d.schedule.Next(at)
+	items := d.items.GetAllScheduledItems(ctx)
+	var todos []models.TodoItem
+	for _, todo := range d.todos.GetAllTodoItems(ctx) {
+		if !todo.Checked {
+			todos = append(todos, todo)
+		}
+	}
+
+	var errs []error
+	for _, user := range d.users.GetAllUsers(ctx) {
+		if user.DeactivatedAt != nil {
+			continue
+		}
+		preference, ok := d.dispatcher.preferences.GetNotificationPreference(ctx, user.ID)
+		if !ok || !Allows(preference, models.NotificationEventDigest, at) {
+			continue
+		}
+
+		location := time.UTC
+		if preference.Timezone != "" {
+			if loaded, err := time.LoadLocation(preference.Timezone); err == nil {
+				location = loaded
+			}
+		}
+		data := DigestData{
+			User:        user,
+			From:        at.In(location),
+			Until:       until.In(location),
+			Occurrences: upcomingOccurrences(items, user.ID, at, until, location),
+			Todos:       todos,
+		}
+		if err := d.dispatcher.sendDigest(ctx, user, preference, data); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", user.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// upcomingOccurrences lists the occurrences in [from, until) of the items whose notification
+// settings list the user, in order and in the given location
+func upcomingOccurrences(items []models.ScheduledItem, userID int64, from time.Time, until time.Time, location *time.Location) []DigestOccurrence {
+	var occurrences []DigestOccurrence
+	for _, item := range items {
+		if item.Notifications == nil || !slices.Contains(item.Notifications.UserIDs, userID) {
+			continue
+		}
+		for _, at := range utils.Occurrences(item.StartsAt, item.Repeats, item.CronExpression, item.Expiration, from, until, maxDigestOccurrences) {
+			occurrences = append(occurrences, DigestOccurrence{Item: item, At: at.In(location)})
+		}
+	}
+	slices.SortFunc(occurrences, func(a, b DigestOccurrence) int {
+		return cmp.Or(a.At.Compare(b.At), cmp.Compare(a.Item.ID, b.Item.ID))
+	})
+	return occurrences
+}
+
+// sendDigest renders a user's digest and sends it through each of the channels in their
+// preferences
+func (d *Dispatcher) sendDigest(ctx context.Context, user models.User, preference models.NotificationPreference, data DigestData) error {
+	subject, body, err := d.templates.Render(TemplateDigest, "", "", data)
+	if err != nil {
+		return fmt.Errorf("digest: %w", err)
+	}
+
+	var errs []error
+	for _, channel := range preference.Channels {
+		switch channel {
+		case models.NotificationChannelEmail:
+			if user.Email == "" || d.email == nil {
+				logging.Debugf("Not emailing user %d their digest: no email address or email is not configured", user.ID)
+				continue
+			}
+			errs = append(errs, deliver(ctx, d.email, "email", Message{To: []string{user.Email}, Subject: subject, Body: body}))
+		case models.NotificationChannelSlack:
+			if preference.SlackUserID == "" || d.slack == nil {
+				logging.Debugf("Not messaging user %d their digest on Slack: no Slack user ID or Slack is not configured", user.ID)
+				continue
+			}
+			errs = append(errs, deliver(ctx, d.slack, "Slack", Message{To: []string{preference.SlackUserID}, Subject: subject, Body: body}))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,279 @@
+package notifications
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// notifyTimeout bounds the delivery of the notifications for a single execution
+const notifyTimeout = 30 * time.Second
+
+// UserLookup finds the users listed in an item's notification settings
+type UserLookup interface {
+	GetUser(ctx context.Context, id int64) (models.User, bool)
+}
+
+// PreferenceLookup finds the notification preferences of users
+type PreferenceLookup interface {
+	GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool)
+}
+
+// DeviceLookup finds the devices users are pushed notifications on
+type DeviceLookup interface {
+	GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken
+}
+
+// Dispatcher decides who is told about an execution and sends them the rendered messages
+type Dispatcher struct {
+	email       Notifier
+	slack       Notifier
+	users       UserLookup
+	preferences PreferenceLookup
+	devices     DeviceLookup
+	// push holds the push notifier of each device platform
+	push map[string]Notifier
+	// templates render the messages
+	templates *Templates
+	// pending tracks the notifications being sent in the background
+	pending sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher sending emails through email and posting to Slack
+// through slack, either of which may be nil when the channel isn't configured, and looking
+// up listed users and their notification preferences in users and preferences
+func NewDispatcher(email Notifier, slack Notifier, users UserLookup, preferences PreferenceLookup) *Dispatcher {
+	return &Dispatcher{
+		email:       email,
+		slack:       slack,
+		users:       users,
+		preferences: preferences,
+		templates:   DefaultTemplates(),
+	}
+}
+
+// UseTemplates renders messages with templates instead of the built-in ones
+func (d *Dispatcher) UseTemplates(templates *Templates) {
+	d.templates = templates
+}
+
+// EnablePush pushes notifications about the todos created for listed users to the devices
+// they registered in devices, through fcm and apns, either of which may be nil when the
+// platform isn't configured
+func (d *Dispatcher) EnablePush(devices DeviceLookup, fcm Notifier, apns Notifier) {
+	d.devices = devices
+	d.push = map[string]Notifier{}
+	if fcm != nil {
+		d.push[models.DevicePlatformFCM] = fcm
+	}
+	if apns != nil {
+		d.push[models.DevicePlatformAPNs] = apns
+	}
+}
+
+// NotifyExecution sends the notifications for an execution in the background, logging
+// any that fail, so executing items isn't held up by the mail service
+func (d *Dispatcher) NotifyExecution(item models.ScheduledItem, execution models.ExecutionLog) {
+	if item.Notifications == nil {
+		return
+	}
+
+	d.pending.Add(1)
+	go func() {
+		defer d.pending.Done()
+		// The users to notify belong to the item's tenant
+		ctx, cancel := context.WithTimeout(store.WithTenant(context.Background(), item.TenantID), notifyTimeout)
+		defer cancel()
+
+		if err := d.Notify(ctx, item, execution); err != nil {
+			logging.Errorf("Failed to send notifications for scheduled item ID=%d (RequestID=%s): %v", item.ID, item.RequestID, err)
+		}
+	}()
+}
+
+// Wait blocks until the notifications being sent in the background are done
+func (d *Dispatcher) Wait() {
+	d.pending.Wait()
+}
+
+// Notify sends the notifications for an execution of item. The item's addresses are sent
+// one message, and its Slack channel one post, when its settings ask for the outcome. Each
+// listed user is notified following their notification preferences, or emailed following
+// the item's settings and pushed the todo the execution created when they have none.
+// Channels that aren't configured are skipped.
+func (d *Dispatcher) Notify(ctx context.Context, item models.ScheduledItem, execution models.ExecutionLog) error {
+	settings := item.Notifications
+	if settings == nil || (execution.Status != "success" && execution.Status != "error") {
+		return nil
+	}
+	data := d.templates.Data(item, execution)
+
+	var errs []error
+	if wants(settings.NotificationPreferences, data.Succeeded) {
+		if len(settings.Email) > 0 {
+			errs = append(errs, d.send(ctx, d.email, "email", settings.Email, data, settings.NotificationPreferences))
+		}
+		if settings.Slack || settings.SlackChannel != "" {
+			errs = append(errs, d.send(ctx, d.slack, "Slack", []string{settings.SlackChannel}, data, settings.NotificationPreferences))
+		}
+	}
+
+	event := models.NotificationEventExecution
+	if !data.Succeeded {
+		event = models.NotificationEventFailure
+	}
+	for _, userID := range settings.UserIDs {
+		if d.users == nil {
+			errs = append(errs, fmt.Errorf("cannot notify user %d without a user store", userID))
+			continue
+		}
+		user, ok := d.users.GetUser(ctx, userID)
+		if !ok {
+			errs = append(errs, fmt.Errorf("user %d not found", userID))
+			continue
+		}
+		if user.DeactivatedAt != nil {
+			logging.Debugf("Not notifying user %d about scheduled item ID=%d: deactivated", userID, item.ID)
+			continue
+		}
+
+		if d.preferences != nil {
+			if preference, ok := d.preferences.GetNotificationPreference(ctx, userID); ok {
+				errs = append(errs, d.notifyUser(ctx, user, preference, event, data, settings.NotificationPreferences))
+				continue
+			}
+		}
+
+		// Users without preferences are pushed the todos created for them, and emailed
+		// following the item's settings
+		errs = append(errs, d.pushTodo(ctx, user, data))
+		if user.Email == "" {
+			logging.Debugf("Not notifying user %d about scheduled item ID=%d: no email address", userID, item.ID)
+			continue
+		}
+		if wants(settings.NotificationPreferences, data.Succeeded) {
+			errs = append(errs, d.send(ctx, d.email, "email", []string{user.Email}, data, settings.NotificationPreferences))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// notifyUser sends a user a notification about event through each of the channels in their
+// preferences, unless they don't want the event or it is within their quiet hours. Their
+// templates fall back to the item's.
+func (d *Dispatcher) notifyUser(ctx context.Context, user models.User, preference models.NotificationPreference, event string, data TemplateData, item models.NotificationPreferences) error {
+	if !Allows(preference, event, data.Execution.ExecutedAt) {
+		logging.Debugf("Not notifying user %d about scheduled item ID=%d: %s notifications are off or quiet hours apply", user.ID, data.Item.ID, event)
+		return nil
+	}
+
+	templates := models.NotificationPreferences{
+		Subject: cmp.Or(preference.Subject, item.Subject),
+		Body:    cmp.Or(preference.Body, item.Body),
+	}
+	var errs []error
+	for _, channel := range preference.Channels {
+		switch channel {
+		case models.NotificationChannelEmail:
+			if user.Email == "" {
+				logging.Debugf("Not emailing user %d about scheduled item ID=%d: no email address", user.ID, data.Item.ID)
+				continue
+			}
+			errs = append(errs, d.send(ctx, d.email, "email", []string{user.Email}, data, templates))
+		case models.NotificationChannelSlack:
+			if preference.SlackUserID == "" {
+				logging.Debugf("Not messaging user %d about scheduled item ID=%d on Slack: no Slack user ID", user.ID, data.Item.ID)
+				continue
+			}
+			errs = append(errs, d.send(ctx, d.slack, "Slack", []string{preference.SlackUserID}, data, templates))
+		case models.NotificationChannelPush:
+			errs = append(errs, d.pushTodo(ctx, user, data))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pushTodo pushes a notification about the todo an execution created to each of the
+// user's devices. Notifications about the same item share a collapse key, so a device
+// shows only the latest of an item that repeats while the user is away.
+func (d *Dispatcher) pushTodo(ctx context.Context, user models.User, data TemplateData) error {
+	if d.devices == nil || data.Execution.TodoItemID == nil {
+		return nil
+	}
+
+	tokens := map[string][]string{}
+	for _, device := range d.devices.GetDeviceTokens(ctx, user.ID) {
+		tokens[device.Platform] = append(tokens[device.Platform], device.Token)
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	subject, body, err := d.templates.Render(TemplatePush, "", "", data)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, platform := range models.DevicePlatforms {
+		if len(tokens[platform]) == 0 {
+			continue
+		}
+		notifier := d.push[platform]
+		if notifier == nil {
+			logging.Debugf("Not pushing user %d's todo for scheduled item ID=%d to %s devices: it is not configured", user.ID, data.Item.ID, platform)
+			continue
+		}
+		errs = append(errs, deliver(ctx, notifier, platform, Message{
+			To:          tokens[platform],
+			Subject:     subject,
+			Body:        body,
+			CollapseKey: fmt.Sprintf("scheduled-item-%d", data.Item.ID),
+			Data: map[string]string{
+				"scheduledItemId": strconv.FormatInt(data.Item.ID, 10),
+				"todoItemId":      strconv.FormatInt(*data.Execution.TodoItemID, 10),
+			},
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// send renders a message with the given preferences' templates and delivers it through
+// notifier, skipping the named channel when it isn't configured
+func (d *Dispatcher) send(ctx context.Context, notifier Notifier, channel string, to []string, data TemplateData, preferences models.NotificationPreferences) error {
+	if notifier == nil {
+		logging.Debugf("Not notifying about scheduled item ID=%d through %s: it is not configured", data.Item.ID, channel)
+		return nil
+	}
+
+	subject, body, err := d.templates.Render(TemplateExecution, preferences.Subject, preferences.Body, data)
+	if err != nil {
+		return err
+	}
+	return deliver(ctx, notifier, channel, Message{To: to, Subject: subject, Body: body})
+}
+
+// deliver sends a rendered message through notifier, naming the channel in the error
+func deliver(ctx context.Context, notifier Notifier, channel string, message Message) error {
+	if err := notifier.Notify(ctx, message); err != nil {
+		return fmt.Errorf("%s: %w", channel, err)
+	}
+	return nil
+}
+
+// wants reports whether preferences ask to be told about an execution with the given outcome
+func wants(preferences models.NotificationPreferences, succeeded bool) bool {
+	if succeeded {
+		return preferences.OnSuccess
+	}
+	return preferences.OnFailure
+}
@@ -0,0 +1,601 @@
+package notifications
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// recordingNotifier keeps the messages it is asked to send
+type recordingNotifier struct {
+	messages []Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message Message) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+// userMap looks up users from a map
+type userMap map[int64]models.User
+
+func (m userMap) GetUser(ctx context.Context, id int64) (models.User, bool) {
+	user, ok := m[id]
+	return user, ok
+}
+
+// preferenceMap looks up notification preferences from a map
+type preferenceMap map[int64]models.NotificationPreference
+
+func (m preferenceMap) GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool) {
+	preference, ok := m[userID]
+	return preference, ok
+}
+
+func TestDispatcherFollowsItemAndUserSettings(t *testing.T) {
+	notifier := &recordingNotifier{}
+	users := userMap{
+		1: {ID: 1, Email: "alice@example.com"},
+		2: {ID: 2, Email: "bob@example.com"},
+		3: {ID: 3},
+	}
+	preferences := preferenceMap{
+		2: {UserID: 2, Channels: []string{"email"}, Events: []string{"execution"}, Subject: "Bob: {{.Item.Title}}"},
+	}
+	dispatcher := NewDispatcher(notifier, nil, users, preferences)
+
+	item := models.ScheduledItem{
+		ID:    5,
+		Title: "Backup",
+		Notifications: &models.NotificationSettings{
+			NotificationPreferences: models.NotificationPreferences{OnFailure: true, Body: "{{.Item.Title}}: {{.Error}}"},
+			Email:                   []string{"ops@example.com"},
+			UserIDs:                 []int64{1, 2, 3},
+		},
+	}
+	errorMessage := "disk full"
+	failed := models.ExecutionLog{ScheduledItemID: 5, ExecutedAt: time.Now(), Status: "error", ErrorMessage: &errorMessage}
+
+	// A failure goes to the item's addresses and to users without preferences of their own
+	if err := dispatcher.Notify(context.Background(), item, failed); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if len(notifier.messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %+v", notifier.messages)
+	}
+	if got := notifier.messages[0]; got.To[0] != "ops@example.com" || got.Subject != "Failed: Backup" || got.Body != "Backup: disk full" {
+		t.Errorf("Unexpected item message %+v", got)
+	}
+	if got := notifier.messages[1]; got.To[0] != "alice@example.com" || got.Body != "Backup: disk full" {
+		t.Errorf("Unexpected message for alice %+v", got)
+	}
+
+	// A success only goes to the user who asked for it, with their subject and the item's body
+	notifier.messages = nil
+	succeeded := models.ExecutionLog{ScheduledItemID: 5, ExecutedAt: time.Now(), Status: "success"}
+	if err := dispatcher.Notify(context.Background(), item, succeeded); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if len(notifier.messages) != 1 || notifier.messages[0].To[0] != "bob@example.com" ||
+		notifier.messages[0].Subject != "Bob: Backup" || notifier.messages[0].Body != "Backup: " {
+		t.Errorf("Unexpected messages %+v", notifier.messages)
+	}
+
+	// Unknown users are reported
+	item.Notifications.UserIDs = []int64{9}
+	if err := dispatcher.Notify(context.Background(), item, failed); err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+}
+
+func TestDispatcherPostsToSlackChannel(t *testing.T) {
+	email := &recordingNotifier{}
+	slack := &recordingNotifier{}
+	dispatcher := NewDispatcher(email, slack, nil, nil)
+
+	item := models.ScheduledItem{
+		ID:    5,
+		Title: "Backup",
+		Notifications: &models.NotificationSettings{
+			NotificationPreferences: models.NotificationPreferences{OnFailure: true},
+			SlackChannel:            "#ops-alerts",
+		},
+	}
+	failed := models.ExecutionLog{ScheduledItemID: 5, ExecutedAt: time.Now(), Status: "error"}
+	if err := dispatcher.Notify(context.Background(), item, failed); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if len(email.messages) != 0 || len(slack.messages) != 1 || slack.messages[0].To[0] != "#ops-alerts" || slack.messages[0].Subject != "Failed: Backup" {
+		t.Errorf("Expected one Slack post to #ops-alerts, got email %+v and Slack %+v", email.messages, slack.messages)
+	}
+
+	// Without Slack configured the post is skipped
+	if err := NewDispatcher(email, nil, nil, nil).Notify(context.Background(), item, failed); err != nil {
+		t.Errorf("Expected an unconfigured channel to be skipped, got %v", err)
+	}
+}
+
+func TestDispatcherFollowsUserPreferences(t *testing.T) {
+	email := &recordingNotifier{}
+	slack := &recordingNotifier{}
+	users := userMap{1: {ID: 1, Email: "alice@example.com"}}
+	preferences := preferenceMap{1: {
+		UserID:          1,
+		Channels:        []string{"email", "slack"},
+		Events:          []string{"failure"},
+		SlackUserID:     "U0123456789",
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:00",
+		Timezone:        "America/New_York",
+	}}
+	dispatcher := NewDispatcher(email, slack, users, preferences)
+
+	item := models.ScheduledItem{
+		ID:    5,
+		Title: "Backup",
+		Notifications: &models.NotificationSettings{
+			NotificationPreferences: models.NotificationPreferences{OnSuccess: true, OnFailure: true},
+			UserIDs:                 []int64{1},
+		},
+	}
+	// 15:00 in New York
+	afternoon := time.Date(2024, 6, 3, 19, 0, 0, 0, time.UTC)
+
+	// Failures reach both of the user's channels
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{ExecutedAt: afternoon, Status: "error"}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if len(email.messages) != 1 || email.messages[0].To[0] != "alice@example.com" || len(slack.messages) != 1 || slack.messages[0].To[0] != "U0123456789" {
+		t.Fatalf("Expected an email and a Slack message, got email %+v and Slack %+v", email.messages, slack.messages)
+	}
+
+	// Executions are turned off, even though the item notifies about them
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{ExecutedAt: afternoon, Status: "success"}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+
+	// Nothing is sent during quiet hours: 23:30 in New York
+	night := time.Date(2024, 6, 4, 3, 30, 0, 0, time.UTC)
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{ExecutedAt: night, Status: "error"}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+
+	if len(email.messages) != 1 || len(slack.messages) != 1 {
+		t.Errorf("Expected no further messages, got email %+v and Slack %+v", email.messages, slack.messages)
+	}
+}
+
+func (m userMap) GetAllUsers(ctx context.Context) []models.User {
+	var users []models.User
+	for _, user := range m {
+		users = append(users, user)
+	}
+	return users
+}
+
+func (m userMap) GetTenants(ctx context.Context) []string {
+	return []string{store.DefaultTenant}
+}
+
+type itemList []models.ScheduledItem
+
+func (l itemList) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
+	return l
+}
+
+type todoList []models.TodoItem
+
+func (l todoList) GetAllTodoItems(ctx context.Context) []models.TodoItem {
+	return l
+}
+
+func TestDigesterSendsUpcomingOccurrencesAndOpenTodos(t *testing.T) {
+	email := &recordingNotifier{}
+	users := userMap{
+		1: {ID: 1, Email: "alice@example.com"},
+		2: {ID: 2, Email: "bob@example.com"},
+	}
+	preferences := preferenceMap{
+		1: {UserID: 1, Channels: []string{"email"}, Events: []string{"digest"}, Timezone: "Europe/Paris"},
+		2: {UserID: 2, Channels: []string{"email"}, Events: []string{"failure"}},
+	}
+	dispatcher := NewDispatcher(email, nil, users, preferences)
+
+	weekdays := "0 9 * * 1-5"
+	listed := &models.NotificationSettings{UserIDs: []int64{1}}
+	items := itemList{
+		{ID: 1, Title: "Standup", StartsAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Repeats: true, CronExpression: &weekdays, Notifications: listed},
+		{ID: 2, Title: "Unlisted", StartsAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Repeats: true, CronExpression: &weekdays},
+	}
+	todos := todoList{{ID: 1, Text: "Water plants"}, {ID: 2, Text: "Done already", Checked: true}}
+
+	digester, err := NewDigester("0 8 * * *", dispatcher, items, todos, users)
+	if err != nil {
+		t.Fatalf("Failed to create digester: %v", err)
+	}
+	// A Wednesday morning digest covers one standup until Thursday's digest
+	if err := digester.Send(context.Background(), time.Date(2024, 6, 5, 8, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Failed to send digests: %v", err)
+	}
+
+	if len(email.messages) != 1 || email.messages[0].To[0] != "alice@example.com" {
+		t.Fatalf("Expected only alice's digest, got %+v", email.messages)
+	}
+	body := email.messages[0].Body
+	if !strings.Contains(body, "Wed 5 Jun 11:00 Standup") || strings.Contains(body, "Unlisted") {
+		t.Errorf("Expected the listed item's occurrence in Paris time, got %q", body)
+	}
+	if !strings.Contains(body, "- Water plants") || strings.Contains(body, "Done already") {
+		t.Errorf("Expected only the open todos, got %q", body)
+	}
+
+	if _, err := NewDigester("every morning", dispatcher, items, todos, users); err == nil {
+		t.Error("Expected an invalid schedule to be rejected")
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	overnight := models.NotificationPreference{QuietHoursStart: "22:00", QuietHoursEnd: "07:00"}
+	daytime := models.NotificationPreference{QuietHoursStart: "09:00", QuietHoursEnd: "17:30", Timezone: "Asia/Tokyo"}
+	cases := []struct {
+		name       string
+		preference models.NotificationPreference
+		at         time.Time
+		quiet      bool
+	}{
+		{"before midnight", overnight, time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC), true},
+		{"after midnight", overnight, time.Date(2024, 1, 1, 6, 59, 0, 0, time.UTC), true},
+		{"at the end", overnight, time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), false},
+		{"in the day", overnight, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), false},
+		{"in the time zone", daytime, time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), true},
+		{"outside in the time zone", daytime, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), false},
+		{"no quiet hours", models.NotificationPreference{}, time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if quiet := InQuietHours(c.preference, c.at); quiet != c.quiet {
+				t.Errorf("Expected quiet=%v, got %v", c.quiet, quiet)
+			}
+		})
+	}
+}
+
+func TestSlackBotNotifierPostsMessage(t *testing.T) {
+	var payload map[string]string
+	ok := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-token" {
+			t.Errorf("Expected the bot token, got %q", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if ok {
+			w.Write([]byte(`{"ok":true}`))
+		} else {
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewSlackBotNotifier("xoxb-token", "#general")
+	notifier.url = server.URL
+
+	if err := notifier.Notify(context.Background(), Message{To: []string{""}, Subject: "Failed: Backup", Body: "disk full"}); err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	if payload["channel"] != "#general" || payload["text"] != "*Failed: Backup*\ndisk full" {
+		t.Errorf("Unexpected message %v", payload)
+	}
+
+	ok = false
+	if err := notifier.Notify(context.Background(), Message{To: []string{"#missing"}}); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected Slack's error to be reported, got %v", err)
+	}
+	if payload["channel"] != "#missing" {
+		t.Errorf("Expected the channel override to be used, got %v", payload)
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings models.NotificationSettings
+		valid    bool
+	}{
+		{"defaults", models.NotificationSettings{Email: []string{"ops@example.com"}}, true},
+		{"custom templates", models.NotificationSettings{NotificationPreferences: models.NotificationPreferences{Subject: "{{.Item.Title}}", Body: "{{if .Succeeded}}ok{{end}}"}}, true},
+		{"unparseable template", models.NotificationSettings{NotificationPreferences: models.NotificationPreferences{Subject: "{{.Item.Title"}}, false},
+		{"unknown field", models.NotificationSettings{NotificationPreferences: models.NotificationPreferences{Body: "{{.Item.Colour}}"}}, false},
+		{"bad address", models.NotificationSettings{Email: []string{"Ops <ops@example.com>"}}, false},
+		{"bad user", models.NotificationSettings{UserIDs: []int64{0}}, false},
+		{"slack channel", models.NotificationSettings{SlackChannel: "C0123456789"}, true},
+		{"bad slack channel", models.NotificationSettings{SlackChannel: "ops alerts"}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateSettings(c.settings); (err == nil) != c.valid {
+				t.Errorf("Expected valid=%v, got %v", c.valid, err)
+			}
+		})
+	}
+}
+
+func TestLoadTemplatesOverridesBuiltinsAndLinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "execution.subject.tmpl"), []byte("[periodic] {{.Item.Title}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	templates, err := LoadTemplates(dir, "https://periodic.example.com/")
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	if templates.Text(TemplateExecution, "subject") != "[periodic] {{.Item.Title}}" || templates.Text(TemplatePush, "subject") != "New todo" {
+		t.Errorf("Expected the execution subject to be overridden and the rest built in, got %q and %q",
+			templates.Text(TemplateExecution, "subject"), templates.Text(TemplatePush, "subject"))
+	}
+
+	email := &recordingNotifier{}
+	dispatcher := NewDispatcher(email, nil, nil, nil)
+	dispatcher.UseTemplates(templates)
+	item := models.ScheduledItem{
+		ID:            5,
+		Title:         "Backup",
+		Notifications: &models.NotificationSettings{NotificationPreferences: models.NotificationPreferences{OnSuccess: true}, Email: []string{"ops@example.com"}},
+	}
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{ScheduledItemID: 5, ExecutedAt: time.Now(), Status: "success"}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if len(email.messages) != 1 || email.messages[0].Subject != "[periodic] Backup" ||
+		!strings.Contains(email.messages[0].Body, "https://periodic.example.com/api/v1/scheduled-items/5") {
+		t.Errorf("Expected the overridden subject and a link to the item, got %+v", email.messages)
+	}
+
+	// Templates that don't render are rejected when loaded
+	if err := os.WriteFile(filepath.Join(dir, "digest.body.tmpl"), []byte("{{.Item.Title}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplates(dir, ""); err == nil {
+		t.Error("Expected a digest template using execution fields to be rejected")
+	}
+}
+
+func TestSESNotifierSendsSignedEmailRequest(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "/eu-west-1/ses/aws4_request") {
+			t.Errorf("Expected a SigV4 signature for ses in eu-west-1, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		form, _ = url.ParseQuery(string(body))
+		w.Write([]byte("<SendEmailResponse/>"))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{Region: "eu-west-1", Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")}
+	notifier, err := NewSESNotifier(cfg, "periodic@example.com", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	err = notifier.Notify(context.Background(), Message{To: []string{"a@example.com", "b@example.com"}, Subject: "Failed: Backup", Body: "disk full"})
+	if err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if form.Get("Action") != "SendEmail" || form.Get("Source") != "periodic@example.com" ||
+		form.Get("Destination.ToAddresses.member.1") != "a@example.com" || form.Get("Destination.ToAddresses.member.2") != "b@example.com" ||
+		form.Get("Message.Subject.Data") != "Failed: Backup" || form.Get("Message.Body.Text.Data") != "disk full" {
+		t.Errorf("Unexpected SendEmail request %v", form)
+	}
+}
+
+type deviceMap map[int64][]models.DeviceToken
+
+func (m deviceMap) GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken {
+	return m[userID]
+}
+
+func TestDispatcherPushesCreatedTodos(t *testing.T) {
+	fcm := &recordingNotifier{}
+	apns := &recordingNotifier{}
+	users := userMap{1: {ID: 1}, 2: {ID: 2}}
+	preferences := preferenceMap{2: {UserID: 2, Channels: []string{"email"}, Events: []string{"execution"}}}
+	dispatcher := NewDispatcher(nil, nil, users, preferences)
+	dispatcher.EnablePush(deviceMap{
+		1: {{UserID: 1, Platform: "fcm", Token: "android-1"}, {UserID: 1, Platform: "apns", Token: "iphone-1"}},
+		2: {{UserID: 2, Platform: "fcm", Token: "android-2"}},
+	}, fcm, apns)
+
+	item := models.ScheduledItem{ID: 7, Title: "Water plants", Notifications: &models.NotificationSettings{UserIDs: []int64{1, 2}}}
+	todoID := int64(42)
+
+	// Only executions that created a todo are pushed, whatever the item's outcome settings
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{Status: "success"}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+	if err := dispatcher.Notify(context.Background(), item, models.ExecutionLog{Status: "success", TodoItemID: &todoID}); err != nil {
+		t.Fatalf("Failed to notify: %v", err)
+	}
+
+	// User 2's preferences don't include push
+	if len(fcm.messages) != 1 || len(apns.messages) != 1 {
+		t.Fatalf("Expected one push per platform, got FCM %+v and APNs %+v", fcm.messages, apns.messages)
+	}
+	message := fcm.messages[0]
+	if message.To[0] != "android-1" || message.CollapseKey != "scheduled-item-7" || message.Data["todoItemId"] != "42" || message.Body != "Water plants" {
+		t.Errorf("Unexpected push %+v", message)
+	}
+}
+
+func TestFCMNotifierExchangesTokenAndSends(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent map[string]map[string]any
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			r.ParseForm()
+			if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(r.Form.Get("assertion"), ".") != 2 {
+				t.Errorf("Unexpected token request %v", r.Form)
+			}
+			w.Write([]byte(`{"access_token":"ya29.token","expires_in":3600}`))
+		case "/v1/projects/periodic-app/messages:send":
+			if auth := r.Header.Get("Authorization"); auth != "Bearer ya29.token" {
+				t.Errorf("Expected the access token, got %q", auth)
+			}
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"name":"projects/periodic-app/messages/1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(map[string]string{
+		"project_id":   "periodic-app",
+		"client_email": "push@periodic-app.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	notifier, err := NewFCMNotifier(credentials, server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	for range 2 {
+		if err := notifier.Notify(context.Background(), Message{To: []string{"device-1"}, Subject: "New todo", Body: "Backup", CollapseKey: "scheduled-item-1"}); err != nil {
+			t.Fatalf("Failed to push: %v", err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected the access token to be reused, got %d token requests", tokenRequests)
+	}
+	message := sent["message"]
+	if message["token"] != "device-1" || message["android"].(map[string]any)["collapse_key"] != "scheduled-item-1" {
+		t.Errorf("Unexpected message %v", message)
+	}
+}
+
+func TestAPNsNotifierSignsRequests(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/3/device/abc123" || r.Header.Get("apns-topic") != "com.example.periodic" || r.Header.Get("apns-collapse-id") != "scheduled-item-1" {
+			t.Errorf("Unexpected request %s %v", r.URL.Path, r.Header)
+		}
+
+		// The provider token is an ES256 JWT signed with the key
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "), ".")
+		if len(parts) != 3 {
+			t.Fatalf("Expected a JWT, got %q", r.Header.Get("Authorization"))
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		rs, ss := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(&key.PublicKey, digest[:], rs, ss) {
+			t.Error("Expected a valid ES256 signature")
+		}
+
+		var payload map[string]any
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["todoItemId"] != "5" || payload["aps"] == nil {
+			t.Errorf("Unexpected payload %v", payload)
+		}
+	}))
+	defer server.Close()
+
+	p8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	notifier, err := NewAPNsNotifier(p8, "KEY123", "TEAM123", "com.example.periodic", server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	message := Message{To: []string{"abc123"}, Subject: "New todo", Body: "Backup", CollapseKey: "scheduled-item-1", Data: map[string]string{"todoItemId": "5"}}
+	if err := notifier.Notify(context.Background(), message); err != nil {
+		t.Fatalf("Failed to push: %v", err)
+	}
+}
+
+func TestAlerterThresholdsAndThrottling(t *testing.T) {
+	email := &recordingNotifier{}
+	slack := &recordingNotifier{}
+	alerter := NewAlerter(AlertConfig{
+		Email:        []string{"oncall@example.com"},
+		SlackChannel: "#ops",
+		ErrorRate:    0.5,
+		MinBatch:     4,
+		Throttle:     time.Hour,
+	}, NewDispatcher(email, slack, nil, nil))
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	alerter.now = func() time.Time { return now }
+
+	// Small batches and low error rates don't alert
+	alerter.BatchProcessed(0, 3, nil)
+	alerter.BatchProcessed(3, 1, nil)
+	alerter.Wait()
+	if len(email.messages) != 0 {
+		t.Fatalf("Expected no alerts, got %+v", email.messages)
+	}
+
+	alerter.BatchProcessed(2, 2, nil)
+	alerter.Wait()
+	if len(email.messages) != 1 || email.messages[0].To[0] != "oncall@example.com" || len(slack.messages) != 1 || slack.messages[0].To[0] != "#ops" {
+		t.Fatalf("Expected an email and a Slack alert, got email %+v and Slack %+v", email.messages, slack.messages)
+	}
+
+	// A failing item alerts once per throttle window
+	errorMessage := "disk full"
+	item := models.ScheduledItem{ID: 9, Title: "Backup"}
+	for range 3 {
+		alerter.ItemFailed(item, models.ExecutionLog{ExecutedAt: now, ErrorMessage: &errorMessage})
+		now = now.Add(5 * time.Minute)
+	}
+	alerter.Wait()
+	if len(email.messages) != 2 || !strings.Contains(email.messages[1].Body, "disk full") {
+		t.Fatalf("Expected one alert for the item, got %+v", email.messages)
+	}
+
+	now = now.Add(time.Hour)
+	alerter.ItemFailed(item, models.ExecutionLog{ExecutedAt: now, ErrorMessage: &errorMessage})
+	alerter.Wait()
+	if len(email.messages) != 3 || !strings.Contains(email.messages[2].Body, "2 more alerts") {
+		t.Errorf("Expected the item to alert again with the held back count, got %+v", email.messages)
+	}
+}
@@ -0,0 +1,169 @@
+// Package notifications tells people about the executions of scheduled items, following
+// the notification settings of each item and of the users it lists.
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Message is a notification about a single execution
+type Message struct {
+	// To are the email addresses to send the message to, the Slack channel to post it in, or
+	// the device tokens to push it to
+	To      []string
+	Subject string
+	Body    string
+	// CollapseKey lets a pushed message replace an earlier one with the same key still
+	// shown on a device
+	CollapseKey string
+	// Data is passed along with pushed messages for the app to act on
+	Data map[string]string
+}
+
+// Notifier delivers notifications through a channel such as email or Slack
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// Config selects how notifications are delivered
+type Config struct {
+	// EmailFrom enables email through SES, sending from this address; it must be verified with SES
+	EmailFrom string
+	// Endpoint overrides the AWS service endpoint, e.g. to use LocalStack
+	Endpoint string
+	// SlackWebhookURL enables Slack, posting to this incoming webhook
+	SlackWebhookURL string
+	// SlackBotToken enables Slack, posting with this bot token; it takes precedence over the webhook
+	SlackBotToken string
+	// SlackChannel is the channel a bot token posts to when an item doesn't name one
+	SlackChannel string
+	// DigestSchedule is the cron expression digests are sent on; digests are off when empty
+	DigestSchedule string
+	// FCMCredentialsFile enables pushing to FCM devices, authenticating with this Firebase
+	// service account key file
+	FCMCredentialsFile string
+	// APNsKeyFile enables pushing to APNs devices, signing requests with this .p8 key of
+	// APNsKeyID, belonging to APNsTeamID, for the app with the APNsTopic bundle ID
+	APNsKeyFile string
+	APNsKeyID   string
+	APNsTeamID  string
+	APNsTopic   string
+	// APNsSandbox pushes through the development APNs server
+	APNsSandbox bool
+	// TemplatesDir holds templates overriding the built-in ones, see LoadTemplates
+	TemplatesDir string
+	// BaseURL is where the API is reachable, for links in messages; they have none when empty
+	BaseURL string
+}
+
+// ConfigFromEnv returns the notification configuration from the NOTIFICATIONS_EMAIL_FROM,
+// NOTIFICATIONS_AWS_ENDPOINT, NOTIFICATIONS_SLACK_WEBHOOK_URL, NOTIFICATIONS_SLACK_BOT_TOKEN,
+// NOTIFICATIONS_SLACK_CHANNEL, NOTIFICATIONS_DIGEST_SCHEDULE, NOTIFICATIONS_FCM_CREDENTIALS_FILE,
+// NOTIFICATIONS_APNS_KEY_FILE, NOTIFICATIONS_APNS_KEY_ID, NOTIFICATIONS_APNS_TEAM_ID,
+// NOTIFICATIONS_APNS_TOPIC, NOTIFICATIONS_APNS_SANDBOX, NOTIFICATIONS_TEMPLATES_DIR and
+// NOTIFICATIONS_BASE_URL environment variables, and whether notifications are enabled
+func ConfigFromEnv() (Config, bool) {
+	config := Config{
+		EmailFrom:          os.Getenv("NOTIFICATIONS_EMAIL_FROM"),
+		Endpoint:           os.Getenv("NOTIFICATIONS_AWS_ENDPOINT"),
+		SlackWebhookURL:    os.Getenv("NOTIFICATIONS_SLACK_WEBHOOK_URL"),
+		SlackBotToken:      os.Getenv("NOTIFICATIONS_SLACK_BOT_TOKEN"),
+		SlackChannel:       os.Getenv("NOTIFICATIONS_SLACK_CHANNEL"),
+		DigestSchedule:     os.Getenv("NOTIFICATIONS_DIGEST_SCHEDULE"),
+		FCMCredentialsFile: os.Getenv("NOTIFICATIONS_FCM_CREDENTIALS_FILE"),
+		APNsKeyFile:        os.Getenv("NOTIFICATIONS_APNS_KEY_FILE"),
+		APNsKeyID:          os.Getenv("NOTIFICATIONS_APNS_KEY_ID"),
+		APNsTeamID:         os.Getenv("NOTIFICATIONS_APNS_TEAM_ID"),
+		APNsTopic:          os.Getenv("NOTIFICATIONS_APNS_TOPIC"),
+		APNsSandbox:        strings.ToLower(os.Getenv("NOTIFICATIONS_APNS_SANDBOX")) == "true",
+		TemplatesDir:       os.Getenv("NOTIFICATIONS_TEMPLATES_DIR"),
+		BaseURL:            os.Getenv("NOTIFICATIONS_BASE_URL"),
+	}
+	return config, config.EmailFrom != "" || config.SlackWebhookURL != "" || config.SlackBotToken != "" ||
+		config.FCMCredentialsFile != "" || config.APNsKeyFile != ""
+}
+
+// NewDispatcherFromConfig creates a dispatcher for the channels enabled in config, using the
+// default AWS credential chain for email, pushing to the devices registered in devices and
+// rendering messages with the templates config selects
+func NewDispatcherFromConfig(ctx context.Context, cfg Config, users UserLookup, preferences PreferenceLookup, devices DeviceLookup) (*Dispatcher, error) {
+	templates, err := LoadTemplates(cfg.TemplatesDir, cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var email, slack Notifier
+	if cfg.EmailFrom != "" {
+		awsConfig, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("config.LoadDefaultConfig: %w", err)
+		}
+		email, err = NewSESNotifier(awsConfig, cfg.EmailFrom, cfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case cfg.SlackBotToken != "":
+		slack = NewSlackBotNotifier(cfg.SlackBotToken, cfg.SlackChannel)
+	case cfg.SlackWebhookURL != "":
+		slack = NewSlackWebhookNotifier(cfg.SlackWebhookURL)
+	}
+
+	dispatcher := NewDispatcher(email, slack, users, preferences)
+	dispatcher.UseTemplates(templates)
+	if cfg.FCMCredentialsFile != "" || cfg.APNsKeyFile != "" {
+		var fcm, apns Notifier
+		if cfg.FCMCredentialsFile != "" {
+			credentials, err := os.ReadFile(cfg.FCMCredentialsFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read FCM credentials: %w", err)
+			}
+			if fcm, err = NewFCMNotifier(credentials, ""); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.APNsKeyFile != "" {
+			key, err := os.ReadFile(cfg.APNsKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read APNs key: %w", err)
+			}
+			endpoint := apnsEndpoint
+			if cfg.APNsSandbox {
+				endpoint = apnsSandboxEndpoint
+			}
+			if apns, err = NewAPNsNotifier(key, cfg.APNsKeyID, cfg.APNsTeamID, cfg.APNsTopic, endpoint); err != nil {
+				return nil, err
+			}
+		}
+		dispatcher.EnablePush(devices, fcm, apns)
+	}
+	return dispatcher, nil
+}
+
+// Channels names the channels enabled in config, for logging
+func (c Config) Channels() string {
+	var channels []string
+	if c.EmailFrom != "" {
+		channels = append(channels, "email from "+c.EmailFrom)
+	}
+	switch {
+	case c.SlackBotToken != "":
+		channels = append(channels, "Slack bot")
+	case c.SlackWebhookURL != "":
+		channels = append(channels, "Slack webhook")
+	}
+	if c.FCMCredentialsFile != "" {
+		channels = append(channels, "FCM push")
+	}
+	if c.APNsKeyFile != "" {
+		channels = append(channels, "APNs push")
+	}
+	return strings.Join(channels, ", ")
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"periodic-api/internal/models"
+)
+
+// clockLayout is the format of quiet hours
+const clockLayout = "15:04"
+
+// Allows reports whether a user's preferences let them be notified about event at the given time
+func Allows(preference models.NotificationPreference, event string, at time.Time) bool {
+	return slices.Contains(preference.Events, event) && !InQuietHours(preference, at)
+}
+
+// InQuietHours reports whether at falls within a user's quiet hours, in their time zone
+func InQuietHours(preference models.NotificationPreference, at time.Time) bool {
+	if preference.QuietHoursStart == "" || preference.QuietHoursEnd == "" {
+		return false
+	}
+	start, err := parseClock(preference.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(preference.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	location := time.UTC
+	if preference.Timezone != "" {
+		if loaded, err := time.LoadLocation(preference.Timezone); err == nil {
+			location = loaded
+		}
+	}
+	local := at.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// Quiet hours spanning midnight
+	return minute >= start || minute < end
+}
+
+// ValidateQuietHours checks that quiet hours are both set or both empty, as HH:MM, and
+// that the time zone is known
+func ValidateQuietHours(start string, end string, timezone string) error {
+	if (start == "") != (end == "") {
+		return fmt.Errorf("quiet hours need both a start and an end")
+	}
+	for _, clock := range []string{start, end} {
+		if clock == "" {
+			continue
+		}
+		if _, err := parseClock(clock); err != nil {
+			return fmt.Errorf("invalid time '%s', expected HH:MM", clock)
+		}
+	}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return fmt.Errorf("unknown time zone '%s'", timezone)
+		}
+	}
+	return nil
+}
+
+// parseClock returns the minute of the day of an HH:MM time
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,365 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fcmEndpoint is the Firebase Cloud Messaging HTTP v1 API
+	fcmEndpoint = "https://fcm.googleapis.com"
+	// fcmScope is the OAuth scope of FCM access tokens
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// apnsEndpoint and apnsSandboxEndpoint are the production and development APNs servers
+	apnsEndpoint        = "https://api.push.apple.com"
+	apnsSandboxEndpoint = "https://api.sandbox.push.apple.com"
+	// apnsTokenLifetime is how long a provider token is reused; APNs rejects tokens older
+	// than an hour and refreshing them more than every 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// FCMNotifier pushes notifications to Android and iOS devices through Firebase Cloud
+// Messaging, authenticating as a service account. Each recipient is a device token.
+type FCMNotifier struct {
+	httpClient *http.Client
+	endpoint   string
+	projectID  string
+	email      string
+	key        *rsa.PrivateKey
+	tokenURI   string
+
+	mu          sync.Mutex
+	accessToken string
+	expires     time.Time
+}
+
+// fcmCredentials are the fields of a service account key file used to authenticate
+type fcmCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// NewFCMNotifier creates a notifier from the JSON key of a Firebase service account,
+// sending to endpoint when it is set instead of the FCM API
+func NewFCMNotifier(credentials []byte, endpoint string) (*FCMNotifier, error) {
+	var creds fcmCredentials
+	if err := json.Unmarshal(credentials, &creds); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	if creds.ProjectID == "" || creds.ClientEmail == "" || creds.TokenURI == "" {
+		return nil, fmt.Errorf("invalid FCM credentials: project_id, client_email and token_uri are required")
+	}
+	key, err := parsePrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid FCM credentials: private_key is not an RSA key")
+	}
+
+	if endpoint == "" {
+		endpoint = fcmEndpoint
+	}
+	return &FCMNotifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		projectID:  creds.ProjectID,
+		email:      creds.ClientEmail,
+		key:        rsaKey,
+		tokenURI:   creds.TokenURI,
+	}, nil
+}
+
+// Notify pushes the message to each device token, with the subject as the title
+func (n *FCMNotifier) Notify(ctx context.Context, message Message) error {
+	accessToken, err := n.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, device := range message.To {
+		payload := map[string]any{
+			"token": device,
+			"notification": map[string]string{
+				"title": message.Subject,
+				"body":  message.Body,
+			},
+		}
+		if len(message.Data) > 0 {
+			payload["data"] = message.Data
+		}
+		if message.CollapseKey != "" {
+			payload["android"] = map[string]string{"collapse_key": message.CollapseKey}
+			payload["apns"] = map[string]any{"headers": map[string]string{"apns-collapse-id": message.CollapseKey}}
+		}
+		body, err := json.Marshal(map[string]any{"message": payload})
+		if err != nil {
+			return fmt.Errorf("failed to encode FCM message: %w", err)
+		}
+
+		target := fmt.Sprintf("%s/v1/projects/%s/messages:send", n.endpoint, n.projectID)
+		errs = append(errs, postPush(ctx, n.httpClient, "FCM", target, body, map[string]string{
+			"Authorization": "Bearer " + accessToken,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// token returns an OAuth access token for the service account, exchanging a signed
+// assertion for a new one when the cached token is about to expire
+func (n *FCMNotifier) token(ctx context.Context) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if n.accessToken != "" && now.Before(n.expires) {
+		return n.accessToken, nil
+	}
+
+	assertion, err := signJWT(map[string]string{"alg": "RS256", "typ": "JWT"}, map[string]any{
+		"iss":   n.email,
+		"scope": fcmScope,
+		"aud":   n.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}, func(digest []byte) ([]byte, error) {
+		return rsa.SignPKCS1v15(rand.Reader, n.key, crypto.SHA256, digest)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create FCM token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("FCM token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read FCM token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("FCM token request returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to decode FCM token response: %w", err)
+	}
+
+	// Refresh a minute early so tokens don't expire in flight
+	n.accessToken = result.AccessToken
+	n.expires = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return n.accessToken, nil
+}
+
+// APNsNotifier pushes notifications to iOS devices through the Apple Push Notification
+// service, authenticating with a token signing key. Each recipient is a device token.
+type APNsNotifier struct {
+	httpClient *http.Client
+	endpoint   string
+	topic      string
+	keyID      string
+	teamID     string
+	key        *ecdsa.PrivateKey
+
+	mu     sync.Mutex
+	token  string
+	issued time.Time
+}
+
+// NewAPNsNotifier creates a notifier from a .p8 token signing key with the given key ID,
+// for the team and app bundle ID (topic) it belongs to. Endpoint selects the APNs server.
+func NewAPNsNotifier(key []byte, keyID string, teamID string, topic string, endpoint string) (*APNsNotifier, error) {
+	parsed, err := parsePrivateKey(string(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+	ecKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid APNs key: not an ECDSA key")
+	}
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, fmt.Errorf("APNs needs a key ID, team ID and topic")
+	}
+
+	return &APNsNotifier{
+		// APNs only speaks HTTP/2, which the default transport negotiates
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		topic:      topic,
+		keyID:      keyID,
+		teamID:     teamID,
+		key:        ecKey,
+	}, nil
+}
+
+// Notify pushes the message to each device token as an alert, with the subject as the title
+func (n *APNsNotifier) Notify(ctx context.Context, message Message) error {
+	token, err := n.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": message.Subject,
+				"body":  message.Body,
+			},
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode APNs message: %w", err)
+	}
+
+	headers := map[string]string{
+		"Authorization":    "bearer " + token,
+		"apns-topic":       n.topic,
+		"apns-push-type":   "alert",
+		"apns-collapse-id": message.CollapseKey,
+	}
+	if message.CollapseKey == "" {
+		delete(headers, "apns-collapse-id")
+	}
+
+	var errs []error
+	for _, device := range message.To {
+		errs = append(errs, postPush(ctx, n.httpClient, "APNs", n.endpoint+"/3/device/"+url.PathEscape(device), body, headers))
+	}
+	return errors.Join(errs...)
+}
+
+// providerToken returns the signed token APNs requests are authorized with, reusing it
+// for apnsTokenLifetime
+func (n *APNsNotifier) providerToken() (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if n.token != "" && now.Sub(n.issued) < apnsTokenLifetime {
+		return n.token, nil
+	}
+
+	token, err := signJWT(map[string]string{"alg": "ES256", "kid": n.keyID}, map[string]any{
+		"iss": n.teamID,
+		"iat": now.Unix(),
+	}, func(digest []byte) ([]byte, error) {
+		r, s, err := ecdsa.Sign(rand.Reader, n.key, digest)
+		if err != nil {
+			return nil, err
+		}
+		// JWS signatures are the fixed-size concatenation of r and s
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	n.token = token
+	n.issued = now
+	return n.token, nil
+}
+
+// postPush posts a push request, returning an error naming the service for any
+// unsuccessful response
+func postPush(ctx context.Context, client *http.Client, service string, target string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", service, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s returned status %d: %s", service, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// signJWT encodes and signs a JSON Web Token, with sign signing the SHA-256 digest of the
+// encoded header and claims
+func signJWT(header map[string]string, claims map[string]any, sign func(digest []byte) ([]byte, error)) (string, error) {
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token header: %w", err)
+	}
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := sign(digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM-encoded PKCS #8 private key
+func parsePrivateKey(pemKey string) (any, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,58 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"periodic-api/internal/awsapi"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// SESNotifier sends notifications as plain text emails through Amazon SES
+type SESNotifier struct {
+	client *awsapi.Client
+	from   string
+}
+
+// NewSESNotifier creates a notifier sending emails from the given address in the
+// configured region, using endpoint when set instead of the public regional endpoint
+func NewSESNotifier(cfg aws.Config, from string, endpoint string) (*SESNotifier, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("no AWS region configured for SES")
+	}
+	if endpoint == "" {
+		// SES is signed as "ses" but served from the "email" host
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", cfg.Region)
+	}
+	return &SESNotifier{
+		client: awsapi.NewClient(cfg, "ses", cfg.Region, endpoint),
+		from:   from,
+	}, nil
+}
+
+// Notify sends the message as a single email to all of its recipients
+func (n *SESNotifier) Notify(ctx context.Context, message Message) error {
+	if len(message.To) == 0 {
+		return nil
+	}
+
+	form := url.Values{
+		"Action":                    {"SendEmail"},
+		"Version":                   {"2010-12-01"},
+		"Source":                    {n.from},
+		"Message.Subject.Data":      {message.Subject},
+		"Message.Subject.Charset":   {"UTF-8"},
+		"Message.Body.Text.Data":    {message.Body},
+		"Message.Body.Text.Charset": {"UTF-8"},
+	}
+	for i, to := range message.To {
+		form.Set("Destination.ToAddresses.member."+strconv.Itoa(i+1), to)
+	}
+
+	_, err := n.client.Post(ctx, []byte(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded; charset=utf-8",
+	})
+	return err
+}
@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackPostMessageURL is the Web API method bot tokens post messages with
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// SlackNotifier posts notifications to Slack, through an incoming webhook or with a bot
+// token. The first recipient of a message is the channel it is posted to; messages without
+// one go to the default channel.
+type SlackNotifier struct {
+	httpClient *http.Client
+	// url is the incoming webhook, or the chat.postMessage endpoint for a bot token
+	url     string
+	token   string
+	channel string
+}
+
+// NewSlackWebhookNotifier creates a notifier posting to an incoming webhook. Webhooks post
+// to the channel they were created for; only legacy webhooks honor channel overrides.
+func NewSlackWebhookNotifier(webhookURL string) *SlackNotifier {
+	return &SlackNotifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		url:        webhookURL,
+	}
+}
+
+// NewSlackBotNotifier creates a notifier posting with a bot token, to defaultChannel when a
+// message names no channel. The bot must be a member of the channels it posts to.
+func NewSlackBotNotifier(token string, defaultChannel string) *SlackNotifier {
+	return &SlackNotifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		url:        slackPostMessageURL,
+		token:      token,
+		channel:    defaultChannel,
+	}
+}
+
+// slackResponse is the part of a Web API response reporting failures, which are sent with status 200
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// Notify posts the message's subject in bold followed by its body
+func (n *SlackNotifier) Notify(ctx context.Context, message Message) error {
+	channel := n.channel
+	if len(message.To) > 0 && message.To[0] != "" {
+		channel = message.To[0]
+	}
+	if n.token != "" && channel == "" {
+		return fmt.Errorf("no Slack channel to post to")
+	}
+
+	payload := map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", message.Subject, message.Body),
+	}
+	if channel != "" {
+		payload["channel"] = channel
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Slack response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Slack returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if n.token == "" {
+		// Webhooks answer "ok" in plain text
+		return nil
+	}
+
+	var result slackResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return fmt.Errorf("failed to decode Slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("Slack rejected message: %s", result.Error)
+	}
+	return nil
+}
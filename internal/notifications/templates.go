@@ -0,0 +1,246 @@
+package notifications
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+
+	"periodic-api/internal/models"
+)
+
+// Template kinds: each has a subject and a body
+const (
+	// TemplateExecution is the email and Slack message about an execution
+	TemplateExecution = "execution"
+	// TemplatePush is the push notification about a todo the scheduler created
+	TemplatePush = "push"
+	// TemplateDigest is the digest of upcoming items and open todos
+	TemplateDigest = "digest"
+)
+
+// TemplateKinds lists the kinds of notification templates
+var TemplateKinds = []string{TemplateExecution, TemplatePush, TemplateDigest}
+
+// builtinTemplates are the templates used unless a templates directory overrides them
+//
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Templates are the subject and body templates of each kind of notification. The built-in
+// templates can be overridden by files named <kind>.subject.tmpl and <kind>.body.tmpl in a
+// directory. Items and users can still set their own execution templates.
+type Templates struct {
+	// texts are the template texts by file name, such as execution.subject.tmpl
+	texts map[string]string
+	// baseURL is prefixed to the API paths in links
+	baseURL string
+}
+
+// apiPrefix is the path the API is served under, which links in messages point into
+const apiPrefix = "/api/v1"
+
+// DefaultTemplates returns the built-in templates, without links
+var DefaultTemplates = sync.OnceValue(func() *Templates {
+	templates, err := LoadTemplates("", "")
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in notification templates: %v", err))
+	}
+	return templates
+})
+
+// LoadTemplates reads the built-in templates, overridden by the ones in dir when it is set,
+// and checks that each renders. Links in messages point at the API under baseURL, such as
+// https://periodic.example.com; messages have no links when it is empty.
+func LoadTemplates(dir string, baseURL string) (*Templates, error) {
+	templates := &Templates{texts: map[string]string{}, baseURL: strings.TrimSuffix(baseURL, "/")}
+	for _, kind := range TemplateKinds {
+		for _, part := range []string{"subject", "body"} {
+			name := templateFile(kind, part)
+			text, err := fs.ReadFile(builtinTemplates, "templates/"+name)
+			if err != nil {
+				return nil, fmt.Errorf("missing built-in template %s: %w", name, err)
+			}
+			if dir != "" {
+				override, err := os.ReadFile(filepath.Join(dir, name))
+				switch {
+				case err == nil:
+					text = override
+				case !errors.Is(err, fs.ErrNotExist):
+					return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+				}
+			}
+			// Editors end files with a newline that messages shouldn't
+			templates.texts[name] = strings.TrimSuffix(string(text), "\n")
+		}
+	}
+
+	for _, kind := range TemplateKinds {
+		if _, _, err := templates.Render(kind, "", "", templates.SampleData(kind)); err != nil {
+			return nil, err
+		}
+	}
+	return templates, nil
+}
+
+// templateFile names the file of a part of a kind of template
+func templateFile(kind string, part string) string {
+	return kind + "." + part + ".tmpl"
+}
+
+// Text returns the template text of the subject or body of a kind of notification
+func (t *Templates) Text(kind string, part string) string {
+	return t.texts[templateFile(kind, part)]
+}
+
+// Render renders a kind of notification with data, using subject and body instead of the
+// loaded templates when they are set
+func (t *Templates) Render(kind string, subject string, body string, data any) (string, string, error) {
+	if !slices.Contains(TemplateKinds, kind) {
+		return "", "", fmt.Errorf("unknown template kind '%s'", kind)
+	}
+	renderedSubject, err := render("subject", data, subject, t.Text(kind, "subject"))
+	if err != nil {
+		return "", "", err
+	}
+	renderedBody, err := render("body", data, body, t.Text(kind, "body"))
+	if err != nil {
+		return "", "", err
+	}
+	// Email subjects and push titles are a single line
+	return strings.Join(strings.Fields(renderedSubject), " "), renderedBody, nil
+}
+
+// SampleData returns made-up data to preview and check a kind of template with
+func (t *Templates) SampleData(kind string) any {
+	cron := "0 9 * * 1-5"
+	todoID := int64(7)
+	executedAt := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	item := models.ScheduledItem{
+		ID:             1,
+		Title:          "Daily standup meeting",
+		Description:    "Team daily standup meeting to discuss progress",
+		StartsAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		Repeats:        true,
+		CronExpression: &cron,
+	}
+	if kind == TemplateDigest {
+		return DigestData{
+			User:  models.User{ID: 1, Username: "alice", Email: "alice@example.com"},
+			From:  executedAt.Add(-time.Hour),
+			Until: executedAt.Add(23 * time.Hour),
+			Occurrences: []DigestOccurrence{
+				{Item: item, At: executedAt},
+			},
+			Todos: []models.TodoItem{{ID: todoID, Text: "Daily standup meeting: Team daily standup meeting to discuss progress"}},
+		}
+	}
+	return t.Data(item, models.ExecutionLog{ID: 1, ScheduledItemID: item.ID, ExecutedAt: executedAt, Status: "success", TodoItemID: &todoID})
+}
+
+// TemplateLinks are links to the API for an item
+type TemplateLinks struct {
+	// Item is the scheduled item
+	Item string
+	// Executions is the item's execution history
+	Executions string
+}
+
+// TemplateData is what execution and push templates are executed with
+type TemplateData struct {
+	Item      models.ScheduledItem
+	Execution models.ExecutionLog
+	// Succeeded reports whether the execution succeeded
+	Succeeded bool
+	// Error is the error message of a failed execution
+	Error string
+	// Links are empty unless a base URL is configured
+	Links TemplateLinks
+}
+
+// Data describes an execution of an item for the templates
+func (t *Templates) Data(item models.ScheduledItem, execution models.ExecutionLog) TemplateData {
+	data := TemplateData{
+		Item:      item,
+		Execution: execution,
+		Succeeded: execution.Status == "success",
+	}
+	if execution.ErrorMessage != nil {
+		data.Error = *execution.ErrorMessage
+	}
+	if t.baseURL != "" {
+		data.Links = TemplateLinks{
+			Item:       fmt.Sprintf("%s%s/scheduled-items/%d", t.baseURL, apiPrefix, item.ID),
+			Executions: fmt.Sprintf("%s%s/execution-logs?scheduledItemId=%d", t.baseURL, apiPrefix, item.ID),
+		}
+	}
+	return data
+}
+
+// render executes the first non-empty template text with the given data
+func render(name string, data any, texts ...string) (string, error) {
+	text := ""
+	for _, candidate := range texts {
+		if candidate != "" {
+			text = candidate
+			break
+		}
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// ValidateTemplates checks that an item's or user's execution subject and body templates
+// parse and render
+func ValidateTemplates(subject string, body string) error {
+	templates := DefaultTemplates()
+	_, _, err := templates.Render(TemplateExecution, subject, body, templates.SampleData(TemplateExecution))
+	return err
+}
+
+// ValidateSettings checks an item's notification settings: its templates, addresses and Slack channel
+func ValidateSettings(settings models.NotificationSettings) error {
+	if err := ValidateTemplates(settings.Subject, settings.Body); err != nil {
+		return err
+	}
+	for _, address := range settings.Email {
+		if err := ValidateAddress(address); err != nil {
+			return err
+		}
+	}
+	for _, userID := range settings.UserIDs {
+		if userID <= 0 {
+			return fmt.Errorf("invalid user ID %d", userID)
+		}
+	}
+	if strings.ContainsFunc(settings.SlackChannel, unicode.IsSpace) {
+		return fmt.Errorf("invalid Slack channel '%s'", settings.SlackChannel)
+	}
+	return nil
+}
+
+// ValidateAddress checks that address is a single plain email address
+func ValidateAddress(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address {
+		return fmt.Errorf("invalid email address '%s'", address)
+	}
+	return nil
+}
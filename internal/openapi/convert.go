@@ -0,0 +1,182 @@
+// Package openapi converts the Swagger 2.0 document generated by swag into OpenAPI 3 and
+// validates requests against it, so the documented schemas are also the enforced ones
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Version is the OpenAPI version of converted documents
+const Version = "3.0.3"
+
+// defaultMediaType is used for operations that don't list what they consume or produce
+const defaultMediaType = "application/json"
+
+// Convert turns a Swagger 2.0 document into an OpenAPI 3 document. It covers the subset
+// of Swagger that swag generates: body, path, query and header parameters, schema
+// responses and definitions.
+func Convert(swagger []byte) ([]byte, error) {
+	var source map[string]any
+	if err := json.Unmarshal(swagger, &source); err != nil {
+		return nil, fmt.Errorf("invalid Swagger document: %w", err)
+	}
+	if version, _ := source["swagger"].(string); version != "2.0" {
+		return nil, fmt.Errorf("unsupported Swagger version %q", version)
+	}
+
+	document := map[string]any{
+		"openapi": Version,
+		"info":    source["info"],
+		"paths":   map[string]any{},
+		"components": map[string]any{
+			"schemas": convertRefs(objectOrEmpty(source["definitions"])),
+		},
+	}
+
+	host, _ := source["host"].(string)
+	basePath, _ := source["basePath"].(string)
+	if host != "" || basePath != "" {
+		server := basePath
+		if host != "" {
+			server = "http://" + host + basePath
+		}
+		document["servers"] = []any{map[string]any{"url": server}}
+	}
+
+	paths := document["paths"].(map[string]any)
+	for path, item := range objectOrEmpty(source["paths"]) {
+		converted := map[string]any{}
+		for method, operation := range objectOrEmpty(item) {
+			op, ok := operation.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid operation %s %s", method, path)
+			}
+			converted[method] = convertOperation(op)
+		}
+		paths[path] = converted
+	}
+
+	return json.MarshalIndent(document, "", "    ")
+}
+
+// convertOperation moves body parameters into a request body and response schemas into
+// content for each media type the operation produces
+func convertOperation(source map[string]any) map[string]any {
+	operation := map[string]any{}
+	for _, key := range []string{"summary", "description", "tags", "operationId", "deprecated"} {
+		if value, ok := source[key]; ok {
+			operation[key] = value
+		}
+	}
+
+	consumes := mediaTypes(source["consumes"])
+	produces := mediaTypes(source["produces"])
+
+	var parameters []any
+	for _, param := range listOrEmpty(source["parameters"]) {
+		p, ok := param.(map[string]any)
+		if !ok {
+			continue
+		}
+		if p["in"] == "body" {
+			content := map[string]any{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]any{"schema": convertRefs(p["schema"])}
+			}
+			requestBody := map[string]any{"content": content}
+			copyKeys(requestBody, p, "description", "required")
+			operation["requestBody"] = requestBody
+			continue
+		}
+
+		parameter := map[string]any{}
+		copyKeys(parameter, p, "name", "in", "description", "required")
+		schema := map[string]any{}
+		copyKeys(schema, p, "type", "format", "default", "enum", "items", "minimum", "maximum")
+		parameter["schema"] = convertRefs(schema)
+		parameters = append(parameters, parameter)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	responses := map[string]any{}
+	for code, response := range objectOrEmpty(source["responses"]) {
+		r, _ := response.(map[string]any)
+		converted := map[string]any{"description": r["description"]}
+		if schema, ok := r["schema"]; ok {
+			content := map[string]any{}
+			for _, mediaType := range produces {
+				content[mediaType] = map[string]any{"schema": convertRefs(schema)}
+			}
+			converted["content"] = content
+		}
+		responses[code] = converted
+	}
+	operation["responses"] = responses
+
+	return operation
+}
+
+// convertRefs returns a copy of value with definition references pointed at components
+func convertRefs(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				converted[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			converted[key] = convertRefs(item)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = convertRefs(item)
+		}
+		return converted
+	default:
+		return v
+	}
+}
+
+// mediaTypes returns the listed media types, or the default one when none are listed
+func mediaTypes(value any) []string {
+	var types []string
+	for _, item := range listOrEmpty(value) {
+		if mediaType, ok := item.(string); ok {
+			types = append(types, mediaType)
+		}
+	}
+	if len(types) == 0 {
+		types = []string{defaultMediaType}
+	}
+	return types
+}
+
+// copyKeys copies the given keys that are present from src to dst
+func copyKeys(dst, src map[string]any, keys ...string) {
+	for _, key := range keys {
+		if value, ok := src[key]; ok {
+			dst[key] = value
+		}
+	}
+}
+
+// objectOrEmpty returns value as a JSON object, or an empty object if it is not one
+func objectOrEmpty(value any) map[string]any {
+	if object, ok := value.(map[string]any); ok {
+		return object
+	}
+	return map[string]any{}
+}
+
+// listOrEmpty returns value as a JSON array, or nil if it is not one
+func listOrEmpty(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
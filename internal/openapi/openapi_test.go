@@ -0,0 +1,214 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"periodic-api/internal/problem"
+)
+
+func TestGeneratedDocumentIsUpToDate(t *testing.T) {
+	swagger, err := os.ReadFile("../../docs/swagger.json")
+	if err != nil {
+		t.Fatalf("Failed to read swagger.json: %v", err)
+	}
+	generated, err := os.ReadFile("../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read openapi.json: %v", err)
+	}
+
+	converted, err := Convert(swagger)
+	if err != nil {
+		t.Fatalf("Failed to convert swagger.json: %v", err)
+	}
+	if !bytes.Equal(converted, generated) {
+		t.Error("docs/openapi.json is out of date, run go generate ./docs")
+	}
+}
+
+func TestConvertMovesBodyParametersToRequestBody(t *testing.T) {
+	swagger := `{
+		"swagger": "2.0",
+		"info": {"title": "Test", "version": "1.0"},
+		"host": "localhost:8080",
+		"basePath": "/api/v1",
+		"paths": {"/things": {"post": {
+			"consumes": ["application/json"],
+			"produces": ["application/json"],
+			"parameters": [
+				{"name": "thing", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Thing"}},
+				{"name": "dryRun", "in": "query", "type": "boolean"}
+			],
+			"responses": {"201": {"description": "Created", "schema": {"$ref": "#/definitions/Thing"}}}
+		}}},
+		"definitions": {"Thing": {"type": "object", "properties": {"name": {"type": "string"}}}}
+	}`
+
+	converted, err := Convert([]byte(swagger))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	var document struct {
+		OpenAPI string `json:"openapi"`
+		Servers []struct {
+			URL string `json:"url"`
+		} `json:"servers"`
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string         `json:"name"`
+				Schema map[string]any `json:"schema"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Required bool `json:"required"`
+				Content  map[string]struct {
+					Schema map[string]any `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]struct {
+				Content map[string]struct {
+					Schema map[string]any `json:"schema"`
+				} `json:"content"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(converted, &document); err != nil {
+		t.Fatalf("Failed to decode converted document: %v", err)
+	}
+
+	if !strings.HasPrefix(document.OpenAPI, "3.") {
+		t.Errorf("Expected an OpenAPI 3 document, got version %q", document.OpenAPI)
+	}
+	if len(document.Servers) != 1 || document.Servers[0].URL != "http://localhost:8080/api/v1" {
+		t.Errorf("Unexpected servers %+v", document.Servers)
+	}
+
+	operation := document.Paths["/things"]["post"]
+	if len(operation.Parameters) != 1 || operation.Parameters[0].Name != "dryRun" || operation.Parameters[0].Schema["type"] != "boolean" {
+		t.Errorf("Expected only the query parameter with a schema, got %+v", operation.Parameters)
+	}
+	body := operation.RequestBody.Content["application/json"].Schema
+	if !operation.RequestBody.Required || body["$ref"] != "#/components/schemas/Thing" {
+		t.Errorf("Unexpected request body %+v", operation.RequestBody)
+	}
+	response := operation.Responses["201"].Content["application/json"].Schema
+	if response["$ref"] != "#/components/schemas/Thing" {
+		t.Errorf("Unexpected response schema %+v", response)
+	}
+}
+
+func TestValidateRequestsRejectsBodiesNotMatchingSchema(t *testing.T) {
+	document, err := os.ReadFile("../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read openapi.json: %v", err)
+	}
+	validator, err := NewValidator(document)
+	if err != nil {
+		t.Fatalf("NewValidator failed: %v", err)
+	}
+
+	var received string
+	handler := validator.ValidateRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	send := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		received = ""
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Missing required fields and mistyped values are reported per field
+	rec := send(http.MethodPost, "/scheduled-items", "application/json", `{"jitterSeconds":"soon","repeats":true}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", rec.Code)
+	}
+	var details problem.Details
+	if err := json.NewDecoder(rec.Body).Decode(&details); err != nil {
+		t.Fatalf("Failed to decode problem details: %v", err)
+	}
+	fields := map[string]bool{}
+	for _, fieldErr := range details.Errors {
+		fields[fieldErr.Field] = true
+	}
+	if details.Type != problem.TypeValidation || !fields["title"] || !fields["startsAt"] || !fields["jitterSeconds"] {
+		t.Errorf("Expected title, startsAt and jitterSeconds errors, got %+v", details)
+	}
+
+	// Errors in nested arrays name the element
+	rec = send(http.MethodPut, "/webhooks/1", "", `{"url":"https://example.com","eventTypes":[42]}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "eventTypes[0]") {
+		t.Errorf("Expected an eventTypes[0] error, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Valid bodies reach the handler intact
+	valid := `{"title":"Standup","startsAt":"2030-01-01T09:00:00Z","description":null}`
+	if rec := send(http.MethodPost, "/scheduled-items", "application/json", valid); rec.Code != http.StatusNoContent || received != valid {
+		t.Errorf("Expected the valid body to pass through, got %d with body %q", rec.Code, received)
+	}
+
+	// Merge patches only contain the fields being changed
+	if rec := send(http.MethodPatch, "/scheduled-items/1", "application/merge-patch+json", `{"title":"Renamed"}`); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the merge patch to pass through, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Malformed JSON is left for the handler to report
+	if rec := send(http.MethodPost, "/scheduled-items", "application/json", `{`); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected malformed JSON to pass through, got %d", rec.Code)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	document, err := os.ReadFile("../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read openapi.json: %v", err)
+	}
+	validator, err := NewValidator(document)
+	if err != nil {
+		t.Fatalf("NewValidator failed: %v", err)
+	}
+	const schema = "periodic-api_internal_models.ScheduledItem"
+
+	fields := func(errs []problem.FieldError) map[string]bool {
+		names := map[string]bool{}
+		for _, fieldErr := range errs {
+			names[fieldErr.Field] = true
+		}
+		return names
+	}
+
+	if errs := validator.ValidateJSON(schema, []byte(`{"title":"Standup","startsAt":"2030-01-01T09:00:00Z"}`), false); len(errs) != 0 {
+		t.Errorf("Expected a valid item, got %+v", errs)
+	}
+
+	// Unknown fields are rejected along with missing and mistyped ones
+	errs := validator.ValidateJSON(schema, []byte(`{"name":"Standup","repeats":"yes"}`), false)
+	if got := fields(errs); len(got) != 4 || !got["name"] || !got["repeats"] || !got["title"] || !got["startsAt"] {
+		t.Errorf("Expected name, repeats, title and startsAt errors, got %+v", errs)
+	}
+
+	// Partial documents may leave out required fields
+	if errs := validator.ValidateJSON(schema, []byte(`{"title":"Renamed"}`), true); len(errs) != 0 {
+		t.Errorf("Expected a valid partial item, got %+v", errs)
+	}
+
+	// Malformed and trailing JSON is reported against the body
+	for _, data := range []string{`{"title":`, `{"title":"A"} {"title":"B"}`} {
+		if got := fields(validator.ValidateJSON(schema, []byte(data), false)); !got["body"] {
+			t.Errorf("Expected a body error for %q", data)
+		}
+	}
+}
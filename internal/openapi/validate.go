@@ -0,0 +1,293 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"periodic-api/internal/problem"
+)
+
+// Validator rejects requests whose JSON bodies don't match the request body schema
+// documented for their operation
+type Validator struct {
+	routes  []route
+	schemas map[string]any
+}
+
+// route is an operation with a request body, matched by method and path template
+type route struct {
+	method   string
+	segments []string
+	// params is the number of templated segments; routes with fewer match first so
+	// /scheduled-items/next wins over /scheduled-items/{id}
+	params int
+	// content maps media types to request body schemas
+	content map[string]any
+}
+
+// NewValidator creates a validator for the operations of an OpenAPI 3 document
+func NewValidator(document []byte) (*Validator, error) {
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+
+	v := &Validator{schemas: doc.Components.Schemas}
+	for path, item := range doc.Paths {
+		for method, raw := range item {
+			var operation struct {
+				RequestBody *struct {
+					Content map[string]struct {
+						Schema any `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+			}
+			if err := json.Unmarshal(raw, &operation); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", method, path, err)
+			}
+			if operation.RequestBody == nil {
+				continue
+			}
+
+			r := route{
+				method:   strings.ToUpper(method),
+				segments: strings.Split(strings.Trim(path, "/"), "/"),
+				content:  map[string]any{},
+			}
+			for _, segment := range r.segments {
+				if isTemplate(segment) {
+					r.params++
+				}
+			}
+			for mediaType, media := range operation.RequestBody.Content {
+				r.content[mediaType] = media.Schema
+			}
+			v.routes = append(v.routes, r)
+		}
+	}
+
+	slices.SortStableFunc(v.routes, func(a, b route) int { return a.params - b.params })
+	return v, nil
+}
+
+// ValidateRequests is middleware checking request bodies before they reach next. Requests
+// for undocumented operations or media types pass through unchanged.
+func (v *Validator) ValidateRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schema, ok := v.requestSchema(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Let the handler report malformed JSON as it always has
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var errs []problem.FieldError
+		v.validate(schema, value, "", &errs)
+		if len(errs) > 0 {
+			problem.Validation("Request body does not match the API schema", errs...).Write(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ValidateJSON checks data against a schema in the document's components. It is stricter
+// than request validation: properties the schema doesn't define are rejected. With
+// partial, the schema's required properties may be left out, as in a merge patch.
+func (v *Validator) ValidateJSON(schemaName string, data []byte, partial bool) []problem.FieldError {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []problem.FieldError{{Field: "body", Message: "is not valid JSON: " + err.Error()}}
+	}
+	if decoder.More() {
+		return []problem.FieldError{{Field: "body", Message: "must be a single JSON value"}}
+	}
+
+	schema, ok := v.schemas[schemaName].(map[string]any)
+	if !ok {
+		return []problem.FieldError{{Field: "body", Message: fmt.Sprintf("has no schema %q to validate against", schemaName)}}
+	}
+
+	var errs []problem.FieldError
+	if object, ok := value.(map[string]any); ok {
+		properties := objectOrEmpty(schema["properties"])
+		for key := range object {
+			if _, ok := properties[key]; !ok {
+				errs = append(errs, problem.FieldError{Field: key, Message: "is not a known field"})
+			}
+		}
+		slices.SortFunc(errs, func(a, b problem.FieldError) int { return strings.Compare(a.Field, b.Field) })
+	}
+	if partial {
+		schema = maps.Clone(schema)
+		delete(schema, "required")
+	}
+	v.validate(schema, value, "", &errs)
+	return errs
+}
+
+// requestSchema finds the body schema documented for the request's operation and media type
+func (v *Validator) requestSchema(r *http.Request) (any, bool) {
+	mediaType := defaultMediaType
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsed, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, false
+		}
+		mediaType = parsed
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, route := range v.routes {
+		if route.method != r.Method || !matchSegments(route.segments, segments) {
+			continue
+		}
+		schema, ok := route.content[mediaType]
+		return schema, ok
+	}
+	return nil, false
+}
+
+// validate appends an error for every part of value that doesn't match schema. Only the
+// keywords swag generates are checked: $ref, type, required, properties, items and enum.
+func (v *Validator) validate(schema any, value any, field string, errs *[]problem.FieldError) {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		v.validate(v.schemas[strings.TrimPrefix(ref, "#/components/schemas/")], value, field, errs)
+		return
+	}
+
+	fail := func(message string) {
+		name := field
+		if name == "" {
+			name = "body"
+		}
+		*errs = append(*errs, problem.FieldError{Field: name, Message: message})
+	}
+
+	schemaType, _ := s["type"].(string)
+	switch schemaType {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range listOrEmpty(s["required"]) {
+			key, _ := name.(string)
+			if object[key] == nil {
+				*errs = append(*errs, problem.FieldError{Field: joinField(field, key), Message: "is required"})
+			}
+		}
+		properties := objectOrEmpty(s["properties"])
+		for key, propertyValue := range object {
+			// Optional fields may be null, which decodes to their zero value
+			if propertyValue == nil {
+				continue
+			}
+			if property, ok := properties[key]; ok {
+				v.validate(property, propertyValue, joinField(field, key), errs)
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range items {
+			v.validate(s["items"], item, fmt.Sprintf("%s[%d]", field, i), errs)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if enum := listOrEmpty(s["enum"]); len(enum) > 0 && !slices.Contains(enum, any(str)) {
+			fail(fmt.Sprintf("must be one of %v", enum))
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		if _, err := number.Int64(); err != nil {
+			fail("must be an integer")
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			fail("must be a number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+// matchSegments reports whether a path matches a path template split into segments
+func matchSegments(template, path []string) bool {
+	if len(template) != len(path) {
+		return false
+	}
+	for i, segment := range template {
+		if isTemplate(segment) {
+			if path[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplate reports whether a path segment is a parameter such as {id}
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// joinField appends a property name to a field path
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
@@ -0,0 +1,93 @@
+// Package problem writes API errors as RFC 7807 problem details (application/problem+json)
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the media type of problem detail responses
+const ContentType = "application/problem+json"
+
+// Problem types with semantics beyond the HTTP status code. Other problems use
+// "about:blank", meaning the status code and title say everything there is to say.
+const (
+	TypeValidation      = "/problems/validation-error"
+	TypeVersionConflict = "/problems/version-conflict"
+	// TypeInvalidGeneration is a 502 listing why the LLM's output was rejected, after it
+	// was asked to repair it once
+	TypeInvalidGeneration = "/problems/invalid-generation"
+	// TypeQuotaExceeded is a 429 for a user or tenant who has used up their daily LLM quota
+	TypeQuotaExceeded = "/problems/quota-exceeded"
+	// TypeLimitExceeded is a 403 for a tenant that already has as many scheduled items or
+	// webhooks as its limits allow
+	TypeLimitExceeded = "/problems/limit-exceeded"
+	// TypeRateLimited is a 429 for a tenant making requests faster than its rate limit allows
+	TypeRateLimited = "/problems/rate-limited"
+)
+
+// requestIDHeader is set on the response by the request ID middleware before handlers run
+const requestIDHeader = "X-Request-ID"
+
+// Details is an RFC 7807 problem details object
+type Details struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"Scheduled item not found"`
+	Instance string `json:"instance,omitempty" example:"/scheduled-items/42"`
+	// RequestID identifies the request in the server logs
+	RequestID string `json:"requestId,omitempty"`
+	// Errors lists the invalid fields of a validation problem
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field" example:"jitterSeconds"`
+	Message string `json:"message" example:"cannot be negative"`
+}
+
+// New creates a problem for the given status code with a human-readable explanation
+func New(status int, detail string) Details {
+	return Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Validation creates a 400 problem listing the invalid fields of a request
+func Validation(detail string, errs ...FieldError) Details {
+	d := New(http.StatusBadRequest, detail)
+	d.Type = TypeValidation
+	d.Title = "Validation Failed"
+	d.Errors = errs
+	return d
+}
+
+// Write sends the problem as the response to r
+func (d Details) Write(w http.ResponseWriter, r *http.Request) {
+	if d.Instance == "" {
+		// RequestURI keeps any prefix stripped by the router, such as the API version
+		d.Instance, _, _ = strings.Cut(r.RequestURI, "?")
+		if d.Instance == "" {
+			d.Instance = r.URL.Path
+		}
+	}
+	if d.RequestID == "" {
+		d.RequestID = w.Header().Get(requestIDHeader)
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(d.Status)
+	json.NewEncoder(w).Encode(d)
+}
+
+// Write sends a problem with the given status code and explanation as the response to r
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	New(status, detail).Write(w, r)
+}
@@ -0,0 +1,290 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/mqtt"
+	"periodic-api/internal/store"
+)
+
+// Built-in action types
+const (
+	ActionTypeTodo    = "todo"
+	ActionTypeWebhook = "webhook"
+	ActionTypeLog     = "log"
+	ActionTypeMQTT    = "mqtt"
+)
+
+// defaultWebhookTimeout bounds how long a webhook action may take
+const defaultWebhookTimeout = 10 * time.Second
+
+// ActionResult describes what an action produced when it was executed
+type ActionResult struct {
+	// TodoItemID is set when the action created a todo item
+	TodoItemID *int64
+}
+
+// Action is executed each time a scheduled item comes due
+type Action interface {
+	// Validate checks that the action config of a scheduled item is usable
+	Validate(config json.RawMessage) error
+	// Execute performs the action for the given scheduled item
+	Execute(ctx context.Context, item models.ScheduledItem) (ActionResult, error)
+}
+
+// BatchAction is an action that can also be executed for many due items at once, which
+// the service does for the items of one tenant that come due together
+type BatchAction interface {
+	Action
+	// ExecuteBatch performs the action for each of the given items, all of the context's
+	// tenant, returning their results in the same order. An error means none of them
+	// should be treated as executed.
+	ExecuteBatch(ctx context.Context, items []models.ScheduledItem) ([]ActionResult, error)
+}
+
+// TodoAction creates a todo item from the scheduled item
+type TodoAction struct {
+	todoStore store.TodoItemStore
+}
+
+// NewTodoAction creates a new todo action backed by the given store
+func NewTodoAction(todoStore store.TodoItemStore) *TodoAction {
+	return &TodoAction{
+		todoStore: todoStore,
+	}
+}
+
+// Validate accepts any config since the todo action has no options
+func (a *TodoAction) Validate(config json.RawMessage) error {
+	return nil
+}
+
+// Execute creates the todo item for the scheduled item, belonging to the item's organization
+func (a *TodoAction) Execute(ctx context.Context, item models.ScheduledItem) (ActionResult, error) {
+	// Create todo item from scheduled item, for the same organization
+	todoText := createTodoText(item)
+	todoItem := models.TodoItem{
+		Text:           todoText,
+		Checked:        false,
+		OrganizationID: item.OrganizationID,
+	}
+
+	createdTodo := a.todoStore.CreateTodoItem(ctx, todoItem)
+	if createdTodo.ID <= 0 {
+		return ActionResult{}, fmt.Errorf("failed to create todo item")
+	}
+
+	logging.Infof("Created todo item ID=%d: '%s' for scheduled item ID=%d",
+		createdTodo.ID, createdTodo.Text, item.ID)
+
+	return ActionResult{TodoItemID: &createdTodo.ID}, nil
+}
+
+// ExecuteBatch creates the todo items for the scheduled items with a single store call
+func (a *TodoAction) ExecuteBatch(ctx context.Context, items []models.ScheduledItem) ([]ActionResult, error) {
+	todoItems := make([]models.TodoItem, 0, len(items))
+	for _, item := range items {
+		todoItems = append(todoItems, models.TodoItem{
+			Text:           createTodoText(item),
+			Checked:        false,
+			OrganizationID: item.OrganizationID,
+		})
+	}
+
+	createdTodos, err := a.todoStore.CreateTodoItems(ctx, todoItems)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ActionResult, 0, len(createdTodos))
+	for i, createdTodo := range createdTodos {
+		logging.Infof("Created todo item ID=%d: '%s' for scheduled item ID=%d",
+			createdTodo.ID, createdTodo.Text, items[i].ID)
+		results = append(results, ActionResult{TodoItemID: &createdTodo.ID})
+	}
+	return results, nil
+}
+
+// WebhookConfig represents the action config of a webhook action
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// WebhookAction calls an HTTP endpoint with the scheduled item as the JSON body
+type WebhookAction struct {
+	client *http.Client
+}
+
+// NewWebhookAction creates a new webhook action using the given HTTP client. Clients from
+// webhooks.NewClient only connect to public addresses, so scheduled items can't call
+// services on the server's own network.
+func NewWebhookAction(client *http.Client) *WebhookAction {
+	return &WebhookAction{
+		client: client,
+	}
+}
+
+// Validate checks that the config contains a URL
+func (a *WebhookAction) Validate(config json.RawMessage) error {
+	_, err := parseWebhookConfig(config)
+	return err
+}
+
+// Execute sends the scheduled item to the configured webhook URL
+func (a *WebhookAction) Execute(ctx context.Context, item models.ScheduledItem) (ActionResult, error) {
+	config, err := parseWebhookConfig(item.ActionConfig)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	body, err := json.Marshal(item)
+	if err != nil {
+		return ActionResult{}, fmt.Errorf("failed to encode scheduled item: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, config.Method, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return ActionResult{}, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return ActionResult{}, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// Read the rest of the body so the connection can be reused
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ActionResult{}, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	logging.Infof("Called webhook %s %s for scheduled item ID=%d", config.Method, config.URL, item.ID)
+	return ActionResult{}, nil
+}
+
+// parseWebhookConfig decodes and validates a webhook action config
+func parseWebhookConfig(raw json.RawMessage) (WebhookConfig, error) {
+	var config WebhookConfig
+	if len(raw) == 0 {
+		return config, fmt.Errorf("webhook action requires a config with a url")
+	}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return config, fmt.Errorf("invalid webhook config: %w", err)
+	}
+	if config.URL == "" {
+		return config, fmt.Errorf("webhook action requires a url")
+	}
+	if config.Method == "" {
+		config.Method = http.MethodPost
+	}
+	return config, nil
+}
+
+// MQTTConfig represents the action config of an MQTT action; every field is optional
+type MQTTConfig struct {
+	// Topic defaults to the configured topic prefix followed by the scheduled item ID
+	Topic string `json:"topic,omitempty"`
+	// Payload defaults to the scheduled item as JSON
+	Payload *string `json:"payload,omitempty"`
+	// QoS defaults to the configured quality of service
+	QoS    *int `json:"qos,omitempty"`
+	Retain bool `json:"retain,omitempty"`
+}
+
+// MQTTAction publishes a message to an MQTT broker, e.g. for home automation systems
+type MQTTAction struct {
+	client *mqtt.Client
+}
+
+// NewMQTTAction creates a new MQTT action publishing through the given client
+func NewMQTTAction(client *mqtt.Client) *MQTTAction {
+	return &MQTTAction{
+		client: client,
+	}
+}
+
+// Validate checks the topic and QoS of the config, if given
+func (a *MQTTAction) Validate(config json.RawMessage) error {
+	_, err := parseMQTTConfig(config)
+	return err
+}
+
+// Execute publishes the message for the scheduled item
+func (a *MQTTAction) Execute(ctx context.Context, item models.ScheduledItem) (ActionResult, error) {
+	config, err := parseMQTTConfig(item.ActionConfig)
+	if err != nil {
+		return ActionResult{}, err
+	}
+
+	topic := config.Topic
+	if topic == "" {
+		topic = fmt.Sprintf("%s/%d", a.client.DefaultTopic(), item.ID)
+	}
+	qos := a.client.DefaultQoS()
+	if config.QoS != nil {
+		qos = byte(*config.QoS)
+	}
+
+	var payload []byte
+	if config.Payload != nil {
+		payload = []byte(*config.Payload)
+	} else if payload, err = json.Marshal(item); err != nil {
+		return ActionResult{}, fmt.Errorf("failed to encode scheduled item: %w", err)
+	}
+
+	if err := a.client.Publish(ctx, topic, payload, qos, config.Retain); err != nil {
+		return ActionResult{}, fmt.Errorf("MQTT publish failed: %w", err)
+	}
+
+	logging.Infof("Published to MQTT topic %s for scheduled item ID=%d", topic, item.ID)
+	return ActionResult{}, nil
+}
+
+// parseMQTTConfig decodes and validates an MQTT action config
+func parseMQTTConfig(raw json.RawMessage) (MQTTConfig, error) {
+	var config MQTTConfig
+	if len(raw) == 0 {
+		return config, nil
+	}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return config, fmt.Errorf("invalid mqtt config: %w", err)
+	}
+	if config.Topic != "" {
+		if err := mqtt.ValidateTopic(config.Topic); err != nil {
+			return config, fmt.Errorf("invalid mqtt topic: %w", err)
+		}
+	}
+	if config.QoS != nil && (*config.QoS < 0 || *config.QoS > 2) {
+		return config, fmt.Errorf("mqtt qos must be 0, 1 or 2")
+	}
+	return config, nil
+}
+
+// LogAction only writes the occurrence to the application log
+type LogAction struct{}
+
+// Validate accepts any config since the log action has no options
+func (a LogAction) Validate(config json.RawMessage) error {
+	return nil
+}
+
+// Execute logs the scheduled item occurrence
+func (a LogAction) Execute(ctx context.Context, item models.ScheduledItem) (ActionResult, error) {
+	logging.Infof("Scheduled item ID=%d '%s' is due at %v", item.ID, item.Title, item.NextExecutionAt)
+	return ActionResult{}, nil
+}
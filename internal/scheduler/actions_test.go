@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/mqtt"
+	"periodic-api/internal/store"
+	"periodic-api/internal/webhooks"
+)
+
+// Test that the log action succeeds without creating a todo item
+func TestExecuteLogAction(t *testing.T) {
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(store.NewMemoryScheduledItemStore(), todoStore, logStore)
+
+	executionLog, err := service.ExecuteScheduledItem(context.Background(), models.ScheduledItem{
+		ID:         1,
+		Title:      "Log only",
+		ActionType: ActionTypeLog,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if executionLog.Status != "success" {
+		t.Errorf("Expected status 'success', got '%s'", executionLog.Status)
+	}
+	if executionLog.TodoItemID != nil {
+		t.Errorf("Expected no todo item ID, got %d", *executionLog.TodoItemID)
+	}
+	if todos := len(todoStore.GetAllTodoItems(context.Background())); todos != 0 {
+		t.Errorf("Expected no todo items, got %d", todos)
+	}
+}
+
+// Test that the webhook action posts the scheduled item to the configured URL
+func TestExecuteWebhookAction(t *testing.T) {
+	received := make(chan models.ScheduledItem, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var item models.ScheduledItem
+		json.NewDecoder(r.Body).Decode(&item)
+		received <- item
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	// The test server listens on loopback
+	service.AllowPrivateNetworks()
+
+	config, _ := json.Marshal(WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Token": "secret"},
+	})
+	item := models.ScheduledItem{
+		ID:           7,
+		Title:        "Call webhook",
+		ActionType:   ActionTypeWebhook,
+		ActionConfig: config,
+	}
+
+	if _, err := service.ExecuteScheduledItem(context.Background(), item); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.ID != item.ID {
+			t.Errorf("Expected webhook body for item %d, got %d", item.ID, got.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Webhook was not called")
+	}
+}
+
+// Test that a failing webhook is recorded as an error
+func TestExecuteWebhookActionFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	// The test server listens on loopback
+	service.AllowPrivateNetworks()
+
+	executionLog, err := service.ExecuteScheduledItem(context.Background(), models.ScheduledItem{
+		ID:           8,
+		Title:        "Broken webhook",
+		ActionType:   ActionTypeWebhook,
+		ActionConfig: json.RawMessage(`{"url":"` + server.URL + `"}`),
+	})
+	if err == nil {
+		t.Fatal("Expected an error for a failing webhook")
+	}
+	if executionLog.Status != "error" {
+		t.Errorf("Expected status 'error', got '%s'", executionLog.Status)
+	}
+}
+
+// Test that webhook actions can't reach the server's own network
+func TestExecuteWebhookActionRefusesPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+
+	_, err := service.ExecuteScheduledItem(context.Background(), models.ScheduledItem{
+		ID:           9,
+		Title:        "Probe the metadata service",
+		ActionType:   ActionTypeWebhook,
+		ActionConfig: json.RawMessage(`{"url":"` + server.URL + `"}`),
+	})
+	if !errors.Is(err, webhooks.ErrPrivateAddress) {
+		t.Errorf("Expected the loopback webhook to be refused, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", requests.Load())
+	}
+}
+
+// Test validation of action types and configs
+func TestValidateAction(t *testing.T) {
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	// The test server listens on loopback
+	service.AllowPrivateNetworks()
+
+	tests := []struct {
+		name    string
+		item    models.ScheduledItem
+		wantErr bool
+	}{
+		{name: "Default action", item: models.ScheduledItem{}, wantErr: false},
+		{name: "Todo action", item: models.ScheduledItem{ActionType: ActionTypeTodo}, wantErr: false},
+		{name: "Log action", item: models.ScheduledItem{ActionType: ActionTypeLog}, wantErr: false},
+		{name: "Unknown action", item: models.ScheduledItem{ActionType: "email"}, wantErr: true},
+		{name: "Webhook without config", item: models.ScheduledItem{ActionType: ActionTypeWebhook}, wantErr: true},
+		{name: "Webhook without url", item: models.ScheduledItem{ActionType: ActionTypeWebhook, ActionConfig: json.RawMessage(`{}`)}, wantErr: true},
+		{name: "Webhook with url", item: models.ScheduledItem{ActionType: ActionTypeWebhook, ActionConfig: json.RawMessage(`{"url":"http://example.com"}`)}, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateAction(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// Test that MQTT action configs are validated when the action is enabled
+func TestValidateMQTTAction(t *testing.T) {
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	// The test server listens on loopback
+	service.AllowPrivateNetworks()
+	item := models.ScheduledItem{ActionType: ActionTypeMQTT}
+
+	if err := service.ValidateAction(item); err == nil {
+		t.Error("Expected the mqtt action to be unknown until it is registered")
+	}
+
+	service.RegisterAction(ActionTypeMQTT, NewMQTTAction(mqtt.NewClient(mqtt.Config{})))
+	if err := service.ValidateAction(item); err != nil {
+		t.Errorf("Expected an empty config to be valid, got %v", err)
+	}
+
+	for _, config := range []string{`{"topic":"home/#"}`, `{"qos":3}`} {
+		item.ActionConfig = json.RawMessage(config)
+		if err := service.ValidateAction(item); err == nil {
+			t.Errorf("Expected config %s to be rejected", config)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+// defaultInterval is how often due items are checked when SCHEDULER_INTERVAL is not set
+const defaultInterval = 30 * time.Second
+
+// IntervalFromEnv returns the processing interval from the SCHEDULER_INTERVAL environment variable
+func IntervalFromEnv() time.Duration {
+	interval := defaultInterval
+	if intervalStr := os.Getenv("SCHEDULER_INTERVAL"); intervalStr != "" {
+		if parsedInterval, err := time.ParseDuration(intervalStr); err == nil {
+			interval = parsedInterval
+		} else {
+			logging.Warnf("Invalid SCHEDULER_INTERVAL format, using default: %v", interval)
+		}
+	}
+	return interval
+}
+
+// InstanceID identifies this scheduler process in heartbeats
+func InstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"periodic-api/internal/models"
+)
+
+// stalledTicks is the number of intervals without a successful tick after which a
+// scheduler is reported stalled
+const stalledTicks = 3
+
+// Stalled reports whether a scheduler instance has gone stalledTicks intervals without a
+// successful tick, e.g. because it crashed or can't reach the database. Instances that
+// don't report an interval, such as one-shot runs, are measured against the default.
+func Stalled(heartbeat models.SchedulerHeartbeat, now time.Time) bool {
+	interval := time.Duration(heartbeat.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return heartbeat.LastSuccessAt == nil || now.Sub(*heartbeat.LastSuccessAt) > stalledTicks*interval
+}
+
+// Status represents the health of the scheduler as reported by the status endpoint
+type Status struct {
+	models.SchedulerHeartbeat
+	Interval string `json:"interval"`
+	Healthy  bool   `json:"healthy"`
+}
+
+// Status returns the current heartbeat of the service and whether it is keeping up
+func (s *Service) Status() Status {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	healthy := s.interval > 0 && !Stalled(s.status, time.Now())
+
+	return Status{
+		SchedulerHeartbeat: s.status,
+		Interval:           s.interval.String(),
+		Healthy:            healthy,
+	}
+}
+
+// HealthHandler returns an HTTP handler serving the /healthz, /status and /metrics endpoints
+func (s *Service) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+// handleHealthz reports whether the scheduler has ticked successfully recently
+func (s *Service) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.Status().Healthy {
+		http.Error(w, "Scheduler stalled", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Write([]byte("ok"))
+}
+
+// handleStatus returns the scheduler heartbeat as JSON
+func (s *Service) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Status())
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"periodic-api/internal/logging"
+)
+
+// NotificationChannel is the PostgreSQL channel on which scheduled item changes are announced
+const NotificationChannel = "scheduled_items"
+
+// ListenForChanges forwards PostgreSQL notifications about scheduled item changes to the
+// service until the context is cancelled. Each notification carries the item's next
+// execution time as Unix epoch seconds.
+func (s *Service) ListenForChanges(ctx context.Context, listener *pq.Listener) {
+	for {
+		select {
+		case notification := <-listener.Notify:
+			// A nil notification means the connection was re-established and
+			// notifications may have been missed, so check for due items now
+			if notification == nil {
+				s.NotifyNextExecution(time.Now())
+				continue
+			}
+
+			seconds, err := strconv.ParseFloat(notification.Extra, 64)
+			if err != nil {
+				logging.Warnf("Invalid scheduled item notification payload '%s': %v", notification.Extra, err)
+				continue
+			}
+
+			s.NotifyNextExecution(time.Unix(0, int64(seconds*float64(time.Second))))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"periodic-api/internal/store"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Item outcomes counted by scheduler_items_processed_total
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeFailed    = "failed"
+	outcomeSkipped   = "skipped"
+)
+
+var (
+	// batchDurationBuckets are the upper bounds, in seconds, of the batch duration histogram
+	batchDurationBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+	// executionLagBuckets are the upper bounds, in seconds, of the execution lag histogram,
+	// from on time to an hour behind
+	executionLagBuckets = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}
+)
+
+// histogram counts observations in cumulative buckets, the way Prometheus exposes them
+type histogram struct {
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) histogram {
+	return histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+// observe adds a value to every bucket it falls within
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// metrics holds the scheduler's counters and histograms since the process started
+type metrics struct {
+	mu            sync.Mutex
+	batches       uint64
+	claimErrors   uint64
+	itemsDue      uint64
+	processed     map[string]uint64
+	batchDuration histogram
+	executionLag  histogram
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		processed: map[string]uint64{
+			outcomeSucceeded: 0,
+			outcomeFailed:    0,
+			outcomeSkipped:   0,
+		},
+		batchDuration: newHistogram(batchDurationBuckets),
+		executionLag:  newHistogram(executionLagBuckets),
+	}
+}
+
+// recordBatch counts a processing pass, the items it found due and how long it took
+func (m *metrics) recordBatch(result ProcessResult, due int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.batches++
+	if result.ClaimErr != nil {
+		m.claimErrors++
+	}
+	m.itemsDue += uint64(due)
+	m.batchDuration.observe(duration.Seconds())
+}
+
+// recordItem counts the outcome of processing an item and how late it was picked up
+func (m *metrics) recordItem(outcome string, lag time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.processed[outcome]++
+	m.executionLag.observe(max(lag, 0).Seconds())
+}
+
+// writeTo writes the metrics in the Prometheus text exposition format
+func (m *metrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounter(w, "scheduler_batches_total", "Processing passes over the items that were due.", m.batches)
+	writeCounter(w, "scheduler_claim_errors_total", "Processing passes that failed to claim the items that were due.", m.claimErrors)
+	writeCounter(w, "scheduler_items_due_total", "Items found due and claimed for processing.", m.itemsDue)
+
+	fmt.Fprintf(w, "# HELP scheduler_items_processed_total Items processed, by outcome.\n")
+	fmt.Fprintf(w, "# TYPE scheduler_items_processed_total counter\n")
+	for _, outcome := range []string{outcomeSucceeded, outcomeFailed, outcomeSkipped} {
+		fmt.Fprintf(w, "scheduler_items_processed_total{outcome=%q} %d\n", outcome, m.processed[outcome])
+	}
+
+	writeHistogram(w, "scheduler_batch_duration_seconds", "Time taken by each processing pass.", &m.batchDuration)
+	writeHistogram(w, "scheduler_execution_lag_seconds", "Time between when an item was due and when it was processed.", &m.executionLag)
+}
+
+func writeCounter(w io.Writer, name string, help string, value uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func writeHistogram(w io.Writer, name string, help string, h *histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, bound := range h.bounds {
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+}
+
+// handleMetrics returns the scheduler metrics, along with the slow query counts of the
+// PostgreSQL stores, for Prometheus to scrape
+func (s *Service) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.writeTo(w)
+	store.WriteSlowQueryMetrics(w)
+}
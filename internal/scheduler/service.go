@@ -0,0 +1,750 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+	"periodic-api/internal/webhooks"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records a span for each processing batch and each item processed
+var tracer = otel.Tracer("periodic-api/internal/scheduler")
+
+// claimLease is how long an item stays claimed by this scheduler before another
+// instance may pick it up again, e.g. after a crash mid-processing
+const claimLease = 5 * time.Minute
+
+// defaultClaimRetryBackoff is the wait before checking again after due items could not be
+// claimed; it doubles for every further failure, up to the processing interval
+const defaultClaimRetryBackoff = time.Second
+
+// ExecutionNotifier tells people about the executions of scheduled items
+type ExecutionNotifier interface {
+	NotifyExecution(item models.ScheduledItem, execution models.ExecutionLog)
+}
+
+// FailureAlerter tells operators about failing batches and items
+type FailureAlerter interface {
+	BatchProcessed(succeeded int, failed int, claimErr error)
+	ItemFailed(item models.ScheduledItem, execution models.ExecutionLog)
+}
+
+// Service executes the actions of scheduled items and records execution logs.
+// It is shared by the scheduler daemon and the API so both use the same code path.
+type Service struct {
+	itemStore      store.ScheduledItemStore
+	logStore       store.ExecutionLogStore
+	heartbeatStore store.SchedulerHeartbeatStore
+	transactor     store.Transactor
+	notifier       ExecutionNotifier
+	alerter        FailureAlerter
+	actions        map[string]Action
+	wakeups        chan time.Time
+	// intervalChanged tells a running service to pick up an interval set by SetInterval
+	intervalChanged chan struct{}
+	// metrics counts batches and processed items for the /metrics endpoint
+	metrics *metrics
+	// claimRetryBackoff is the wait before a running service checks again after failing
+	// to claim due items, so the batch isn't left until the next tick
+	claimRetryBackoff time.Duration
+
+	// statusMu guards the running totals reported through heartbeats
+	statusMu sync.Mutex
+	status   models.SchedulerHeartbeat
+	interval time.Duration
+}
+
+// NewService creates a new scheduler service with the given stores and the built-in actions
+func NewService(itemStore store.ScheduledItemStore, todoStore store.TodoItemStore, logStore store.ExecutionLogStore) *Service {
+	return &Service{
+		itemStore:  itemStore,
+		logStore:   logStore,
+		transactor: store.NoopTransactor{},
+		actions: map[string]Action{
+			ActionTypeTodo:    NewTodoAction(todoStore),
+			ActionTypeWebhook: NewWebhookAction(webhooks.NewClient(defaultWebhookTimeout, false)),
+			ActionTypeLog:     LogAction{},
+		},
+		wakeups:           make(chan time.Time, 16),
+		intervalChanged:   make(chan struct{}, 1),
+		metrics:           newMetrics(),
+		claimRetryBackoff: defaultClaimRetryBackoff,
+		status: models.SchedulerHeartbeat{
+			StartedAt: time.Now(),
+		},
+	}
+}
+
+// EnableHeartbeat makes the service save its status under the given instance ID after every tick
+func (s *Service) EnableHeartbeat(heartbeatStore store.SchedulerHeartbeatStore, instanceID string) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+
+	s.heartbeatStore = heartbeatStore
+	s.status.InstanceID = instanceID
+}
+
+// EnableTransactions makes the service record each execution, including the todo item it
+// creates, the execution log and the next execution time, within a single transaction
+func (s *Service) EnableTransactions(transactor store.Transactor) {
+	s.transactor = transactor
+}
+
+// EnableNotifications makes the service hand every execution to notifier once it has been
+// recorded, so the item's notification settings are still known after the item is deleted
+func (s *Service) EnableNotifications(notifier ExecutionNotifier) {
+	s.notifier = notifier
+}
+
+// EnableAlerts makes the service tell alerter about each processed batch and every item
+// that fails while processing due items; items run on demand are not alerted about
+func (s *Service) EnableAlerts(alerter FailureAlerter) {
+	s.alerter = alerter
+}
+
+// AllowPrivateNetworks lets webhook actions call loopback, private and link-local
+// addresses, such as receivers on localhost during development
+func (s *Service) AllowPrivateNetworks() {
+	s.RegisterAction(ActionTypeWebhook, NewWebhookAction(webhooks.NewClient(defaultWebhookTimeout, true)))
+}
+
+// RegisterAction adds or replaces the action executed for items with the given action type
+func (s *Service) RegisterAction(actionType string, action Action) {
+	s.actions[actionType] = action
+}
+
+// ValidateAction checks that a scheduled item's action type is known and its config is valid
+func (s *Service) ValidateAction(item models.ScheduledItem) error {
+	action, err := s.actionFor(item)
+	if err != nil {
+		return err
+	}
+	return action.Validate(item.ActionConfig)
+}
+
+// actionFor returns the action for a scheduled item, defaulting to todo creation
+func (s *Service) actionFor(item models.ScheduledItem) (Action, error) {
+	actionType := item.ActionType
+	if actionType == "" {
+		actionType = ActionTypeTodo
+	}
+
+	action, exists := s.actions[actionType]
+	if !exists {
+		return nil, fmt.Errorf("unknown action type '%s'", actionType)
+	}
+	return action, nil
+}
+
+// Run processes due items every interval until the context is cancelled. Between ticks
+// it also wakes up for executions announced through NotifyNextExecution, so items that
+// become due before the next tick are processed on time. When the due items can't be
+// claimed, e.g. while the database resumes from a pause, it checks again after a backoff
+// instead of leaving the batch until the next tick.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	s.statusMu.Lock()
+	s.interval = interval
+	s.statusMu.Unlock()
+
+	// Create ticker for periodic execution as a safety net for missed notifications
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Timer for the earliest announced execution before the next tick
+	wakeTimer := time.NewTimer(0)
+	if !wakeTimer.Stop() {
+		<-wakeTimer.C
+	}
+	defer wakeTimer.Stop()
+	var wakeAt time.Time
+
+	// process runs a batch, scheduling an early retry when claiming the due items failed
+	claimFailures := 0
+	process := func() {
+		result := s.ProcessScheduledItems(ctx)
+		if result.ClaimErr == nil || ctx.Err() != nil {
+			claimFailures = 0
+			return
+		}
+		backoff := s.claimRetryBackoff
+		for i := 0; i < claimFailures && backoff < interval; i++ {
+			backoff *= 2
+		}
+		claimFailures++
+		logging.Warnf("Retrying to claim due items in %v", backoff)
+		s.NotifyNextExecution(time.Now().Add(backoff))
+	}
+
+	// Run initial check
+	process()
+
+	// Main service loop
+	for {
+		select {
+		case <-ticker.C:
+			process()
+		case <-s.intervalChanged:
+			s.statusMu.Lock()
+			interval = s.interval
+			s.statusMu.Unlock()
+			ticker.Reset(interval)
+		case at := <-s.wakeups:
+			delay := time.Until(at)
+			if delay >= interval {
+				// The ticker will pick this execution up in time
+				continue
+			}
+			if !wakeAt.IsZero() && !at.Before(wakeAt) {
+				// An earlier wake-up is already pending
+				continue
+			}
+			if delay < 0 {
+				delay = 0
+			}
+			if !wakeAt.IsZero() && !wakeTimer.Stop() {
+				<-wakeTimer.C
+			}
+			wakeAt = at
+			wakeTimer.Reset(delay)
+		case <-wakeTimer.C:
+			wakeAt = time.Time{}
+			process()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SetInterval changes the processing interval of a running service, e.g. when the
+// configuration is reloaded. The next tick is one new interval from now.
+func (s *Service) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	s.statusMu.Lock()
+	s.interval = interval
+	s.statusMu.Unlock()
+
+	select {
+	case s.intervalChanged <- struct{}{}:
+	default:
+		// A change is already pending and will read the latest interval
+	}
+}
+
+// NotifyNextExecution tells a running scheduler that an item is due at the given time so it
+// can wake up early instead of waiting for the next tick. It never blocks.
+func (s *Service) NotifyNextExecution(at time.Time) {
+	select {
+	case s.wakeups <- at:
+	default:
+		// A wake-up is already queued; the ticker remains the fallback
+	}
+}
+
+// ProcessResult summarizes a single pass over the items that were due
+type ProcessResult struct {
+	Succeeded int
+	Failed    int
+	Skipped   int
+	// ClaimErr is set when the due items could not be claimed at all
+	ClaimErr error
+}
+
+// ProcessScheduledItems executes all items that are due and schedules their next execution
+func (s *Service) ProcessScheduledItems(ctx context.Context) ProcessResult {
+	logging.Debugf("Processing scheduled items...")
+
+	// Never look for due items on a read replica, which may not have the latest executions
+	ctx = store.WithPrimary(ctx)
+	ctx, span := tracer.Start(ctx, "scheduler process batch")
+	startedAt := time.Now()
+	var result ProcessResult
+	var due int
+	defer func() {
+		s.metrics.recordBatch(result, due, time.Since(startedAt))
+
+		errorCount := result.Failed
+		if result.ClaimErr != nil {
+			errorCount++
+			span.RecordError(result.ClaimErr)
+			span.SetStatus(codes.Error, result.ClaimErr.Error())
+		}
+		s.recordTick(ctx, result.Succeeded+result.Skipped, errorCount, result.ClaimErr == nil)
+		if s.alerter != nil {
+			s.alerter.BatchProcessed(result.Succeeded, result.Failed, result.ClaimErr)
+		}
+
+		span.SetAttributes(
+			attribute.Int("scheduler.items.succeeded", result.Succeeded),
+			attribute.Int("scheduler.items.failed", result.Failed),
+			attribute.Int("scheduler.items.skipped", result.Skipped),
+		)
+		span.End()
+	}()
+
+	// Claim items that are due for execution so other scheduler instances skip them
+	// Use a reasonable limit for batch processing
+	itemsDue, err := s.itemStore.ClaimDueItems(ctx, 100, claimLease)
+	if err != nil {
+		logging.Errorf("Error getting scheduled items due for execution: %v", err)
+		result.ClaimErr = err
+		return result
+	}
+
+	// Early return if no items to process
+	if len(itemsDue) == 0 {
+		logging.Debugf("No items due for execution")
+		return result
+	}
+
+	due = len(itemsDue)
+	logging.Debugf("Found %d items due for execution", len(itemsDue))
+	span.SetAttributes(attribute.Int("scheduler.items.due", len(itemsDue)))
+
+	// Items whose action runs in batches are executed together with the other items of
+	// their tenant, the rest one by one
+	batches, singles := s.planBatches(itemsDue)
+	for _, batch := range batches {
+		s.processBatch(ctx, batch, &result)
+	}
+	for _, item := range singles {
+		s.processItem(ctx, item, &result)
+	}
+
+	logging.Infof("Processed %d items: %d successful, %d errors, %d already executed",
+		len(itemsDue), result.Succeeded, result.Failed, result.Skipped)
+
+	logging.Debugf("Finished processing scheduled items")
+	return result
+}
+
+// processItem executes a claimed item that is due and schedules its next execution,
+// counting the outcome in result
+func (s *Service) processItem(ctx context.Context, item models.ScheduledItem, result *ProcessResult) {
+	ctx, span := tracer.Start(ctx, "scheduler process item", trace.WithAttributes(
+		attribute.Int64("scheduled_item.id", item.ID),
+		attribute.String("scheduled_item.action", item.ActionType),
+	))
+	defer span.End()
+
+	// Items are claimed across tenants, but each is processed within its own
+	ctx = store.WithTenant(ctx, item.TenantID)
+
+	// How late the item is picked up shows whether the scheduler is falling behind
+	lag := time.Since(item.NextExecutionAt)
+
+	logging.Debugf("Processing item: ID=%d, Title='%s', NextExecutionAt=%v, RequestID=%s",
+		item.ID, item.Title, item.NextExecutionAt, item.RequestID)
+
+	// Skip todo creation if this occurrence already ran, e.g. when a previous run
+	// crashed before updating the next execution time
+	executionKey := createExecutionKey(item)
+	if existingLog, exists := s.logStore.GetExecutionLogByKey(ctx, executionKey); exists {
+		logging.Warnf("Item ID=%d (RequestID=%s) already executed for %v (log ID=%d), skipping todo creation",
+			item.ID, item.RequestID, item.NextExecutionAt, existingLog.ID)
+		span.SetAttributes(attribute.Bool("scheduler.already_executed", true))
+		result.Skipped++
+		s.metrics.recordItem(outcomeSkipped, lag)
+		if err := s.updateProcessedScheduledItem(ctx, item); err != nil {
+			logging.Errorf("Failed to schedule next execution of item ID=%d: %v", item.ID, err)
+			span.RecordError(err)
+		}
+		return
+	}
+
+	// Update next execution time together with the execution itself
+	complete := func(ctx context.Context) error {
+		return s.updateProcessedScheduledItem(ctx, item)
+	}
+	if executionLog, err := s.executeScheduledItem(ctx, item, &executionKey, complete); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		result.Failed++
+		s.metrics.recordItem(outcomeFailed, lag)
+		if s.alerter != nil {
+			s.alerter.ItemFailed(item, executionLog)
+		}
+		return
+	}
+
+	result.Succeeded++
+	s.metrics.recordItem(outcomeSucceeded, lag)
+}
+
+// itemBatch is a group of due items of one tenant whose action is executed for all of them at once
+type itemBatch struct {
+	tenantID string
+	action   BatchAction
+	items    []models.ScheduledItem
+}
+
+// planBatches groups the due items by tenant and action type into batches for the items
+// whose action is a BatchAction, and returns the other items to process one by one
+func (s *Service) planBatches(items []models.ScheduledItem) ([]*itemBatch, []models.ScheduledItem) {
+	type batchKey struct {
+		tenantID   string
+		actionType string
+	}
+
+	grouped := make(map[batchKey]*itemBatch)
+	var batches []*itemBatch
+	var singles []models.ScheduledItem
+	for _, item := range items {
+		action, err := s.actionFor(item)
+		batchAction, ok := action.(BatchAction)
+		if err != nil || !ok {
+			singles = append(singles, item)
+			continue
+		}
+
+		key := batchKey{tenantID: item.TenantID, actionType: item.ActionType}
+		if key.actionType == "" {
+			key.actionType = ActionTypeTodo
+		}
+		batch, exists := grouped[key]
+		if !exists {
+			batch = &itemBatch{tenantID: item.TenantID, action: batchAction}
+			grouped[key] = batch
+			batches = append(batches, batch)
+		}
+		batch.items = append(batch.items, item)
+	}
+
+	// A batch of one item gains nothing over processing it on its own
+	batches = slices.DeleteFunc(batches, func(batch *itemBatch) bool {
+		if len(batch.items) == 1 {
+			singles = append(singles, batch.items[0])
+			return true
+		}
+		return false
+	})
+	return batches, singles
+}
+
+// processBatch executes the action of a batch of due items at once and records their
+// execution logs and next execution times, with one batch write each, within one transaction, counting the outcomes
+// in result. When the batch fails, its items are processed one by one instead, so an item
+// that can't be executed doesn't hold up the others.
+func (s *Service) processBatch(ctx context.Context, batch *itemBatch, result *ProcessResult) {
+	ctx, span := tracer.Start(ctx, "scheduler process item batch", trace.WithAttributes(
+		attribute.Int("scheduler.items.batched", len(batch.items)),
+	))
+	defer span.End()
+
+	ctx = store.WithTenant(ctx, batch.tenantID)
+	startedAt := time.Now()
+
+	// Occurrences that already ran are skipped the same way as when processing one by one
+	var items []models.ScheduledItem
+	for _, item := range batch.items {
+		if _, exists := s.logStore.GetExecutionLogByKey(ctx, createExecutionKey(item)); exists {
+			s.processItem(ctx, item, result)
+			continue
+		}
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	var executionLogs []models.ExecutionLog
+	executed := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		actionResults, err := batch.action.ExecuteBatch(ctx, items)
+		if err != nil {
+			return err
+		}
+		executed = true
+		if len(actionResults) != len(items) {
+			return fmt.Errorf("action returned %d results for %d items", len(actionResults), len(items))
+		}
+
+		logs := make([]models.ExecutionLog, 0, len(items))
+		for i, item := range items {
+			executionKey := createExecutionKey(item)
+			logs = append(logs, newExecutionLog(item, "success", nil, actionResults[i].TodoItemID, &executionKey))
+		}
+		executionLogs, err = s.logStore.CreateExecutionLogs(ctx, logs)
+		if err != nil {
+			return fmt.Errorf("failed to record execution logs: %w", err)
+		}
+
+		return s.updateProcessedScheduledItems(ctx, items)
+	})
+	if err != nil {
+		span.RecordError(err)
+		if !executed || s.rollsBack() {
+			logging.Warnf("Failed to process a batch of %d items of tenant %s, processing them one by one: %v",
+				len(items), batch.tenantID, err)
+			for _, item := range items {
+				s.processItem(ctx, item, result)
+			}
+			return
+		}
+
+		// Without a transaction the action's writes remain, so running the items again
+		// would repeat them. Those recorded before the failure are skipped as already
+		// executed; the others are recorded as failed but not run again.
+		logging.Errorf("Failed to record a batch of %d items of tenant %s after executing it, not executing them again: %v",
+			len(items), batch.tenantID, err)
+		for _, item := range items {
+			if _, exists := s.logStore.GetExecutionLogByKey(ctx, createExecutionKey(item)); exists {
+				s.processItem(ctx, item, result)
+				continue
+			}
+			s.recordUnrecordedItem(ctx, item, err, result)
+		}
+		return
+	}
+
+	for i, item := range items {
+		result.Succeeded++
+		s.metrics.recordItem(outcomeSucceeded, startedAt.Sub(item.NextExecutionAt))
+		s.notify(item, executionLogs[i])
+	}
+}
+
+// recordUnrecordedItem records an item whose action was executed as part of a batch that
+// failed afterwards without being rolled back: the execution is logged as failed and the
+// occurrence is moved past, so it isn't executed a second time
+func (s *Service) recordUnrecordedItem(ctx context.Context, item models.ScheduledItem, err error, result *ProcessResult) {
+	errorMsg := fmt.Sprintf("executed, but recording the execution failed: %v", err)
+	executionLog := s.logExecution(ctx, item, "error", &errorMsg, nil, nil)
+	result.Failed++
+	s.metrics.recordItem(outcomeFailed, time.Since(item.NextExecutionAt))
+	if s.alerter != nil {
+		s.alerter.ItemFailed(item, executionLog)
+	}
+	if err := s.updateProcessedScheduledItem(ctx, item); err != nil {
+		logging.Errorf("Failed to schedule next execution of item ID=%d: %v", item.ID, err)
+	}
+}
+
+// rollsBack reports whether the service's transactor undoes the writes of a failed
+// transaction, which NoopTransactor doesn't
+func (s *Service) rollsBack() bool {
+	_, noop := s.transactor.(store.NoopTransactor)
+	return !noop
+}
+
+// recordTick adds the outcome of a processing pass to the running totals and saves a
+// heartbeat. A pass is successful when the due items could be claimed.
+func (s *Service) recordTick(ctx context.Context, processed int, errors int, successful bool) {
+	s.statusMu.Lock()
+	now := time.Now()
+	s.status.LastTickAt = now
+	if successful {
+		s.status.LastSuccessAt = &now
+	}
+	s.status.IntervalMs = s.interval.Milliseconds()
+	s.status.ItemsProcessed += int64(processed)
+	s.status.ErrorCount += int64(errors)
+	heartbeat := s.status
+	heartbeatStore := s.heartbeatStore
+	s.statusMu.Unlock()
+
+	if heartbeatStore != nil && !heartbeatStore.SaveHeartbeat(ctx, heartbeat) {
+		logging.Errorf("Failed to save heartbeat for scheduler instance %s", heartbeat.InstanceID)
+	}
+}
+
+// ExecuteScheduledItem runs a scheduled item's action once and records the outcome in the
+// execution log. It does not change when the item executes next.
+func (s *Service) ExecuteScheduledItem(ctx context.Context, item models.ScheduledItem) (models.ExecutionLog, error) {
+	return s.executeScheduledItem(ctx, item, nil, nil)
+}
+
+// executeScheduledItem runs the action of a scheduled item and logs the outcome.
+// A successful execution is recorded under the execution key when one is given. The
+// action, the success log and the writes made by complete share one transaction, so a
+// failure in any of them rolls back the others before the failure is logged.
+func (s *Service) executeScheduledItem(ctx context.Context, item models.ScheduledItem, executionKey *string, complete func(ctx context.Context) error) (models.ExecutionLog, error) {
+	var executionLog models.ExecutionLog
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		action, err := s.actionFor(item)
+		if err != nil {
+			return err
+		}
+
+		result, err := action.Execute(ctx, item)
+		if err != nil {
+			return err
+		}
+
+		// Log successful execution
+		executionLog = s.logExecution(ctx, item, "success", nil, result.TodoItemID, executionKey)
+		if executionLog.ID <= 0 {
+			return fmt.Errorf("failed to record execution log")
+		}
+
+		if complete != nil {
+			return complete(ctx)
+		}
+		return nil
+	})
+	if err == nil {
+		s.notify(item, executionLog)
+		return executionLog, nil
+	}
+
+	errorMsg := err.Error()
+	logging.Errorf("Failed to execute scheduled item ID=%d (RequestID=%s): %s", item.ID, item.RequestID, errorMsg)
+
+	// Log failed execution without the key so the occurrence can be retried
+	executionLog = s.logExecution(ctx, item, "error", &errorMsg, nil, nil)
+	s.notify(item, executionLog)
+	return executionLog, fmt.Errorf("failed to execute scheduled item ID=%d: %w", item.ID, err)
+}
+
+// notify hands a recorded execution to the notifier, when notifications are enabled
+func (s *Service) notify(item models.ScheduledItem, executionLog models.ExecutionLog) {
+	if s.notifier == nil || executionLog.ID <= 0 {
+		return
+	}
+	s.notifier.NotifyExecution(item, executionLog)
+}
+
+// createExecutionKey identifies a single scheduled occurrence of an item
+func createExecutionKey(item models.ScheduledItem) string {
+	return fmt.Sprintf("%d:%s", item.ID, item.NextExecutionAt.UTC().Format(time.RFC3339Nano))
+}
+
+// createTodoText generates a descriptive todo item text from a scheduled item
+func createTodoText(item models.ScheduledItem) string {
+	// Create a meaningful todo text based on the scheduled item
+	if item.Description != "" {
+		// If there's a description, use both title and description
+		return fmt.Sprintf("%s - %s", item.Title, item.Description)
+	}
+
+	// If no description, just use the title
+	return item.Title
+}
+
+// updateProcessedScheduledItem calculates and updates the next execution time for a scheduled item
+func (s *Service) updateProcessedScheduledItem(ctx context.Context, item models.ScheduledItem) error {
+	nextExec := nextExecutionAfterProcessing(item)
+	if nextExec == nil {
+		return s.deleteProcessedScheduledItem(ctx, item)
+	}
+
+	if !s.itemStore.UpdateNextExecutionAt(ctx, item.ID, *nextExec) {
+		return fmt.Errorf("failed to update next execution for item ID=%d", item.ID)
+	}
+	logging.Infof("Updated next execution for repeating item ID=%d to %v", item.ID, *nextExec)
+	return nil
+}
+
+// updateProcessedScheduledItems updates the next execution times of processed scheduled
+// items with one batch update, and deletes those that won't execute again
+func (s *Service) updateProcessedScheduledItems(ctx context.Context, items []models.ScheduledItem) error {
+	nextExecutions := make(map[int64]time.Time, len(items))
+	for _, item := range items {
+		nextExec := nextExecutionAfterProcessing(item)
+		if nextExec == nil {
+			if err := s.deleteProcessedScheduledItem(ctx, item); err != nil {
+				return err
+			}
+			continue
+		}
+		nextExecutions[item.ID] = *nextExec
+	}
+	if len(nextExecutions) == 0 {
+		return nil
+	}
+
+	if err := s.itemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions); err != nil {
+		return fmt.Errorf("failed to update next executions: %w", err)
+	}
+	logging.Infof("Updated next execution for %d repeating items", len(nextExecutions))
+	return nil
+}
+
+// nextExecutionAfterProcessing returns when a processed item executes next, based on its
+// cron expression, or nil when it doesn't repeat or has expired
+func nextExecutionAfterProcessing(item models.ScheduledItem) *time.Time {
+	if !item.Repeats {
+		return nil
+	}
+	return utils.CalculateNextExecution(item.StartsAt, item.Repeats, item.CronExpression, item.Expiration, item.JitterSeconds)
+}
+
+// deleteProcessedScheduledItem deletes a processed item that won't execute again
+func (s *Service) deleteProcessedScheduledItem(ctx context.Context, item models.ScheduledItem) error {
+	if !item.Repeats {
+		if !s.itemStore.DeleteScheduledItem(ctx, item.ID) {
+			return fmt.Errorf("failed to delete completed item ID=%d", item.ID)
+		}
+		logging.Infof("Deleted completed non-repeating item ID=%d", item.ID)
+		return nil
+	}
+
+	// Repeating item has expired or no valid next execution
+	if !s.itemStore.DeleteScheduledItem(ctx, item.ID) {
+		return fmt.Errorf("failed to delete expired item ID=%d", item.ID)
+	}
+	logging.Infof("Deleted expired repeating item ID=%d", item.ID)
+	return nil
+}
+
+// logExecution creates an execution log entry for a scheduled item processing attempt,
+// carrying the ID of the request that created or last modified the item
+func (s *Service) logExecution(ctx context.Context, item models.ScheduledItem, status string, errorMessage *string, todoItemID *int64, executionKey *string) models.ExecutionLog {
+	scheduledItemID := item.ID
+
+	// Validate input parameters
+	if scheduledItemID <= 0 {
+		logging.Errorf("Invalid scheduled item ID for execution log: %d", scheduledItemID)
+		return models.ExecutionLog{}
+	}
+
+	if status != "success" && status != "error" && status != "skipped" {
+		logging.Errorf("Invalid status for execution log: %s", status)
+		return models.ExecutionLog{}
+	}
+
+	createdLog := s.logStore.CreateExecutionLog(ctx, newExecutionLog(item, status, errorMessage, todoItemID, executionKey))
+	if createdLog.ID > 0 {
+		if status == "success" && todoItemID != nil {
+			logging.Debugf("Logged successful execution: log ID=%d, scheduled item ID=%d, todo item ID=%d, RequestID=%s",
+				createdLog.ID, scheduledItemID, *todoItemID, item.RequestID)
+		} else if status == "error" && errorMessage != nil {
+			logging.Debugf("Logged failed execution: log ID=%d, scheduled item ID=%d, error: %s",
+				createdLog.ID, scheduledItemID, *errorMessage)
+		} else {
+			logging.Debugf("Logged execution: log ID=%d, scheduled item ID=%d, status: %s",
+				createdLog.ID, scheduledItemID, status)
+		}
+	} else {
+		logging.Errorf("Failed to create execution log for scheduled item ID=%d", scheduledItemID)
+	}
+
+	return createdLog
+}
+
+// newExecutionLog returns the execution log of a scheduled item executed now
+func newExecutionLog(item models.ScheduledItem, status string, errorMessage *string, todoItemID *int64, executionKey *string) models.ExecutionLog {
+	return models.ExecutionLog{
+		ScheduledItemID: item.ID,
+		ExecutedAt:      time.Now(),
+		Status:          status,
+		ErrorMessage:    errorMessage,
+		TodoItemID:      todoItemID,
+		ExecutionKey:    executionKey,
+		RequestID:       item.RequestID,
+	}
+}
@@ -0,0 +1,413 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// TestCompleteSchedulerWorkflow tests the entire scheduler workflow using in-memory stores
+func TestCompleteSchedulerWorkflow(t *testing.T) {
+	// Create in-memory stores
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+
+	t.Run("Complete workflow with multiple item types", func(t *testing.T) {
+		// Set up test data
+		now := time.Now()
+		pastTime := now.Add(-time.Hour)
+		futureTime := now.Add(time.Hour)
+		cronExpr := "0 */2 * * *" // Every 2 hours
+		expiration := now.Add(24 * time.Hour)
+
+		// Create different types of scheduled items
+		items := []models.ScheduledItem{
+			{
+				Title:           "One-time urgent task",
+				Description:     "Needs immediate attention",
+				StartsAt:        pastTime,
+				Repeats:         false,
+				NextExecutionAt: pastTime, // Due now
+			},
+			{
+				Title:           "Regular cleanup",
+				Description:     "Clean temporary files",
+				StartsAt:        pastTime,
+				Repeats:         true,
+				CronExpression:  &cronExpr,
+				Expiration:      &expiration,
+				NextExecutionAt: pastTime, // Due now
+			},
+			{
+				Title:           "Future task",
+				Description:     "Not yet due",
+				StartsAt:        futureTime,
+				Repeats:         false,
+				NextExecutionAt: futureTime, // Not due yet
+			},
+		}
+
+		// Create all items
+		var createdItems []models.ScheduledItem
+		for _, item := range items {
+			created := itemStore.CreateScheduledItem(context.Background(), item)
+			if created.ID == 0 {
+				t.Fatalf("Failed to create scheduled item: %s", item.Title)
+			}
+			createdItems = append(createdItems, created)
+		}
+
+		// Record initial state
+		initialTodos := len(todoStore.GetAllTodoItems(context.Background()))
+		initialLogs := len(logStore.GetAllExecutionLogs(context.Background()))
+		initialItems := len(itemStore.GetAllScheduledItems(context.Background()))
+
+		// Execute the main scheduler processing function
+		NewService(itemStore, todoStore, logStore).ProcessScheduledItems(context.Background())
+
+		// Verify results
+		finalTodos := todoStore.GetAllTodoItems(context.Background())
+		finalLogs := logStore.GetAllExecutionLogs(context.Background())
+		finalItems := itemStore.GetAllScheduledItems(context.Background())
+
+		// Should have created 2 todo items (one-time + repeating item that were due)
+		expectedTodos := initialTodos + 2
+		if len(finalTodos) != expectedTodos {
+			t.Errorf("Expected %d todos, got %d", expectedTodos, len(finalTodos))
+		}
+
+		// Should have 2 execution logs
+		expectedLogs := initialLogs + 2
+		if len(finalLogs) != expectedLogs {
+			t.Errorf("Expected %d logs, got %d", expectedLogs, len(finalLogs))
+		}
+
+		// Should have one less item (one-time item deleted, repeating item updated, future item unchanged)
+		expectedItems := initialItems - 1
+		if len(finalItems) != expectedItems {
+			t.Errorf("Expected %d scheduled items, got %d", expectedItems, len(finalItems))
+		}
+
+		// Verify todo content
+		todoTexts := make(map[string]bool)
+		for _, todo := range finalTodos {
+			todoTexts[todo.Text] = true
+		}
+
+		expectedTodoTexts := []string{
+			"One-time urgent task - Needs immediate attention",
+			"Regular cleanup - Clean temporary files",
+		}
+
+		for _, expectedText := range expectedTodoTexts {
+			if !todoTexts[expectedText] {
+				t.Errorf("Expected todo text not found: %s", expectedText)
+			}
+		}
+
+		// Verify execution logs
+		var successLogs, errorLogs int
+		for _, log := range finalLogs {
+			switch log.Status {
+			case "success":
+				successLogs++
+			case "error":
+				errorLogs++
+			}
+		}
+
+		if successLogs < 2 {
+			t.Errorf("Expected at least 2 success logs, got %d", successLogs)
+		}
+
+		if errorLogs > 0 {
+			t.Errorf("Expected 0 error logs, got %d", errorLogs)
+		}
+
+		// Verify the one-time item was deleted
+		_, exists := itemStore.GetScheduledItem(context.Background(), createdItems[0].ID)
+		if exists {
+			t.Error("One-time item should have been deleted")
+		}
+
+		// Verify the repeating item still exists and was updated
+		repeatingItem, exists := itemStore.GetScheduledItem(context.Background(), createdItems[1].ID)
+		if !exists {
+			t.Error("Repeating item should still exist")
+		} else if !repeatingItem.NextExecutionAt.After(now) {
+			t.Error("Repeating item should have future next execution time")
+		}
+
+		// Verify the future item was not processed
+		futureItem, exists := itemStore.GetScheduledItem(context.Background(), createdItems[2].ID)
+		if !exists {
+			t.Error("Future item should still exist")
+		} else if !futureItem.NextExecutionAt.Equal(futureTime) {
+			t.Error("Future item's next execution time should be unchanged")
+		}
+	})
+
+	t.Run("Handle empty queue gracefully", func(t *testing.T) {
+		// Clear all scheduled items
+		allItems := itemStore.GetAllScheduledItems(context.Background())
+		for _, item := range allItems {
+			itemStore.DeleteScheduledItem(context.Background(), item.ID)
+		}
+
+		// Record initial state
+		initialTodos := len(todoStore.GetAllTodoItems(context.Background()))
+		initialLogs := len(logStore.GetAllExecutionLogs(context.Background()))
+
+		// Process with empty queue
+		NewService(itemStore, todoStore, logStore).ProcessScheduledItems(context.Background())
+
+		// Verify no changes
+		finalTodos := len(todoStore.GetAllTodoItems(context.Background()))
+		finalLogs := len(logStore.GetAllExecutionLogs(context.Background()))
+
+		if finalTodos != initialTodos {
+			t.Errorf("Expected %d todos (no change), got %d", initialTodos, finalTodos)
+		}
+
+		if finalLogs != initialLogs {
+			t.Errorf("Expected %d logs (no change), got %d", initialLogs, finalLogs)
+		}
+	})
+
+	t.Run("Handle processing errors gracefully", func(t *testing.T) {
+		// This test would require mocking the todo store to simulate failures
+		// For now, we've tested the error logging functionality in unit tests
+		t.Skip("Error simulation requires mocking - covered by unit tests")
+	})
+}
+
+// TestConcurrentSchedulers verifies that schedulers sharing a store never process the same item twice
+func TestConcurrentSchedulers(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+
+	pastTime := time.Now().Add(-time.Hour)
+	const dueItems = 20
+	for i := 0; i < dueItems; i++ {
+		itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+			Title:           "Concurrent task",
+			StartsAt:        pastTime,
+			Repeats:         false,
+			NextExecutionAt: pastTime,
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NewService(itemStore, todoStore, logStore).ProcessScheduledItems(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if todos := len(todoStore.GetAllTodoItems(context.Background())); todos != dueItems {
+		t.Errorf("Expected %d todos, got %d", dueItems, todos)
+	}
+	if logs := len(logStore.GetAllExecutionLogs(context.Background())); logs != dueItems {
+		t.Errorf("Expected %d execution logs, got %d", dueItems, logs)
+	}
+}
+
+// TestProcessSkipsAlreadyExecutedOccurrence verifies that an occurrence recorded in the
+// execution log is not executed again after a crash before rescheduling
+func TestProcessSkipsAlreadyExecutedOccurrence(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+
+	pastTime := time.Now().Add(-time.Hour)
+	item := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Already done",
+		StartsAt:        pastTime,
+		Repeats:         false,
+		NextExecutionAt: pastTime,
+	})
+
+	// Simulate a previous run that created the todo but crashed before rescheduling
+	executionKey := createExecutionKey(item)
+	logStore.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: item.ID,
+		Status:          "success",
+		ExecutionKey:    &executionKey,
+	})
+
+	result := NewService(itemStore, todoStore, logStore).ProcessScheduledItems(context.Background())
+
+	if result.Skipped != 1 || result.Succeeded != 0 || result.Failed != 0 {
+		t.Errorf("Expected 1 skipped item, got %+v", result)
+	}
+	if todos := len(todoStore.GetAllTodoItems(context.Background())); todos != 0 {
+		t.Errorf("Expected no todos for an already executed occurrence, got %d", todos)
+	}
+	if logs := len(logStore.GetAllExecutionLogs(context.Background())); logs != 1 {
+		t.Errorf("Expected 1 execution log, got %d", logs)
+	}
+	if _, exists := itemStore.GetScheduledItem(context.Background(), item.ID); exists {
+		t.Error("Completed non-repeating item should still be deleted")
+	}
+}
+
+// TestRunWakesUpForAnnouncedExecution verifies that the run loop processes an announced
+// execution without waiting for the next tick
+func TestRunWakesUpForAnnouncedExecution(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(itemStore, todoStore, logStore)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx, time.Hour)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	dueAt := time.Now().Add(100 * time.Millisecond)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Imminent task",
+		StartsAt:        dueAt,
+		Repeats:         false,
+		NextExecutionAt: dueAt,
+	})
+	service.NotifyNextExecution(dueAt)
+
+	deadline := time.After(5 * time.Second)
+	for len(todoStore.GetAllTodoItems(context.Background())) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Announced execution was not processed before the next tick")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// TestHeartbeat verifies that processing passes are reported through the heartbeat store and health endpoints
+func TestHeartbeat(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	heartbeatStore := store.NewMemorySchedulerHeartbeatStore()
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	service.EnableHeartbeat(heartbeatStore, "test-instance")
+
+	handler := service.HealthHandler()
+
+	// Not healthy before the run loop has ticked
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d before first tick, got %d", http.StatusServiceUnavailable, recorder.Code)
+	}
+
+	pastTime := time.Now().Add(-time.Hour)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Heartbeat task",
+		StartsAt:        pastTime,
+		Repeats:         false,
+		NextExecutionAt: pastTime,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		if heartbeat, exists := heartbeatStore.GetHeartbeat(context.Background(), "test-instance"); exists && heartbeat.ItemsProcessed == 1 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("Heartbeat was not saved after the initial tick")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected status %d after a tick, got %d", http.StatusOK, recorder.Code)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status Status
+	if err := json.NewDecoder(recorder.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status.InstanceID != "test-instance" || status.ItemsProcessed != 1 || !status.Healthy {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.LastSuccessAt == nil || status.IntervalMs != time.Hour.Milliseconds() {
+		t.Errorf("Expected the successful tick and interval in the status, got %+v", status)
+	}
+}
+
+// TestMetrics verifies that processed items and how late they ran are exposed for scraping
+func TestMetrics(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+
+	// Due two minutes ago, so it lands in the lag buckets above 60 seconds
+	dueAt := time.Now().Add(-2 * time.Minute)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Late task",
+		StartsAt:        dueAt,
+		NextExecutionAt: dueAt,
+	})
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Broken task",
+		StartsAt:        dueAt,
+		NextExecutionAt: dueAt,
+		ActionType:      "unknown",
+	})
+	service.ProcessScheduledItems(context.Background())
+
+	recorder := httptest.NewRecorder()
+	service.HealthHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	body := recorder.Body.String()
+	for _, line := range []string{
+		"scheduler_batches_total 1",
+		"scheduler_items_due_total 2",
+		`scheduler_items_processed_total{outcome="succeeded"} 1`,
+		`scheduler_items_processed_total{outcome="failed"} 1`,
+		`scheduler_items_processed_total{outcome="skipped"} 0`,
+		`scheduler_execution_lag_seconds_bucket{le="60"} 0`,
+		`scheduler_execution_lag_seconds_bucket{le="300"} 2`,
+		`scheduler_execution_lag_seconds_bucket{le="+Inf"} 2`,
+		"scheduler_execution_lag_seconds_count 2",
+		"scheduler_batch_duration_seconds_count 1",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+}
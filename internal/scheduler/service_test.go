@@ -0,0 +1,676 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// Test the createTodoText function
+func TestCreateTodoText(t *testing.T) {
+	tests := []struct {
+		name         string
+		item         models.ScheduledItem
+		expectedText string
+	}{
+		{
+			name: "Item with title and description",
+			item: models.ScheduledItem{
+				Title:       "Daily Standup",
+				Description: "Team daily standup meeting",
+			},
+			expectedText: "Daily Standup - Team daily standup meeting",
+		},
+		{
+			name: "Item with title only",
+			item: models.ScheduledItem{
+				Title:       "Weekly Report",
+				Description: "",
+			},
+			expectedText: "Weekly Report",
+		},
+		{
+			name: "Item with empty description",
+			item: models.ScheduledItem{
+				Title:       "Code Review",
+				Description: "",
+			},
+			expectedText: "Code Review",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := createTodoText(tt.item)
+			if result != tt.expectedText {
+				t.Errorf("Expected '%s', got '%s'", tt.expectedText, result)
+			}
+		})
+	}
+}
+
+// Test the updateProcessedScheduledItem method with in-memory stores
+func TestUpdateProcessedScheduledItem(t *testing.T) {
+	// Create in-memory store for testing
+	store := store.NewMemoryScheduledItemStore()
+	service := NewService(store, nil, nil)
+
+	t.Run("Delete non-repeating item", func(t *testing.T) {
+		// Create a non-repeating item
+		item := models.ScheduledItem{
+			Title:           "One-time task",
+			Description:     "Test task",
+			StartsAt:        time.Now().Add(-time.Hour),
+			Repeats:         false,
+			NextExecutionAt: time.Now().Add(-time.Hour),
+		}
+
+		createdItem := store.CreateScheduledItem(context.Background(), item)
+		if createdItem.ID == 0 {
+			t.Fatal("Failed to create scheduled item")
+		}
+
+		// Verify item exists
+		_, exists := store.GetScheduledItem(context.Background(), createdItem.ID)
+		if !exists {
+			t.Fatal("Item should exist before processing")
+		}
+
+		// Process the item
+		service.updateProcessedScheduledItem(context.Background(), createdItem)
+
+		// Verify item was deleted
+		_, exists = store.GetScheduledItem(context.Background(), createdItem.ID)
+		if exists {
+			t.Error("Non-repeating item should be deleted after processing")
+		}
+	})
+
+	t.Run("Update repeating item with valid next execution", func(t *testing.T) {
+		// Create a repeating item with future executions
+		cronExpr := "0 */6 * * *" // Every 6 hours
+		futureExpiration := time.Now().Add(24 * time.Hour)
+
+		item := models.ScheduledItem{
+			Title:           "Repeating task",
+			Description:     "Test repeating task",
+			StartsAt:        time.Now().Add(-time.Hour),
+			Repeats:         true,
+			CronExpression:  &cronExpr,
+			Expiration:      &futureExpiration,
+			NextExecutionAt: time.Now().Add(-time.Hour),
+		}
+
+		createdItem := store.CreateScheduledItem(context.Background(), item)
+		if createdItem.ID == 0 {
+			t.Fatal("Failed to create scheduled item")
+		}
+
+		originalNextExecution := createdItem.NextExecutionAt
+
+		// Process the item
+		service.updateProcessedScheduledItem(context.Background(), createdItem)
+
+		// Verify item still exists
+		updatedItem, exists := store.GetScheduledItem(context.Background(), createdItem.ID)
+		if !exists {
+			t.Fatal("Repeating item should still exist after processing")
+		}
+
+		// Verify next execution time was updated
+		if !updatedItem.NextExecutionAt.After(originalNextExecution) {
+			t.Error("Next execution time should have been updated to a future time")
+		}
+
+		// Clean up
+		store.DeleteScheduledItem(context.Background(), createdItem.ID)
+	})
+
+	t.Run("Delete expired repeating item", func(t *testing.T) {
+		// Create an expired repeating item
+		cronExpr := "0 */6 * * *"                    // Every 6 hours
+		pastExpiration := time.Now().Add(-time.Hour) // Expired 1 hour ago
+
+		item := models.ScheduledItem{
+			Title:           "Expired repeating task",
+			Description:     "Test expired task",
+			StartsAt:        time.Now().Add(-2 * time.Hour),
+			Repeats:         true,
+			CronExpression:  &cronExpr,
+			Expiration:      &pastExpiration,
+			NextExecutionAt: time.Now().Add(-30 * time.Minute),
+		}
+
+		createdItem := store.CreateScheduledItem(context.Background(), item)
+		if createdItem.ID == 0 {
+			t.Fatal("Failed to create scheduled item")
+		}
+
+		// Verify item exists
+		_, exists := store.GetScheduledItem(context.Background(), createdItem.ID)
+		if !exists {
+			t.Fatal("Item should exist before processing")
+		}
+
+		// Process the item
+		service.updateProcessedScheduledItem(context.Background(), createdItem)
+
+		// Verify item was deleted due to expiration
+		_, exists = store.GetScheduledItem(context.Background(), createdItem.ID)
+		if exists {
+			t.Error("Expired repeating item should be deleted after processing")
+		}
+	})
+}
+
+// Test the logExecution method
+func TestLogExecution(t *testing.T) {
+	// Create in-memory execution log store for testing
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(nil, nil, logStore)
+
+	t.Run("Log successful execution", func(t *testing.T) {
+		scheduledItemID := int64(123)
+		todoItemID := int64(456)
+
+		initialLogCount := len(logStore.GetAllExecutionLogs(context.Background()))
+
+		// Log successful execution
+		service.logExecution(context.Background(), models.ScheduledItem{ID: scheduledItemID}, "success", nil, &todoItemID, nil)
+
+		// Verify log was created
+		finalLogs := logStore.GetAllExecutionLogs(context.Background())
+		if len(finalLogs) != initialLogCount+1 {
+			t.Fatalf("Expected %d logs, got %d", initialLogCount+1, len(finalLogs))
+		}
+
+		// Find our log entry
+		var ourLog *models.ExecutionLog
+		for _, log := range finalLogs {
+			if log.ScheduledItemID == scheduledItemID {
+				ourLog = &log
+				break
+			}
+		}
+
+		if ourLog == nil {
+			t.Fatal("Log entry not found")
+		}
+
+		// Verify log details
+		if ourLog.Status != "success" {
+			t.Errorf("Expected status 'success', got '%s'", ourLog.Status)
+		}
+
+		if ourLog.TodoItemID == nil || *ourLog.TodoItemID != todoItemID {
+			t.Errorf("Expected todo item ID %d, got %v", todoItemID, ourLog.TodoItemID)
+		}
+
+		if ourLog.ErrorMessage != nil {
+			t.Errorf("Expected no error message, got '%s'", *ourLog.ErrorMessage)
+		}
+	})
+
+	t.Run("Log failed execution", func(t *testing.T) {
+		scheduledItemID := int64(789)
+		errorMsg := "Test error message"
+
+		initialLogCount := len(logStore.GetAllExecutionLogs(context.Background()))
+
+		// Log failed execution
+		service.logExecution(context.Background(), models.ScheduledItem{ID: scheduledItemID}, "error", &errorMsg, nil, nil)
+
+		// Verify log was created
+		finalLogs := logStore.GetAllExecutionLogs(context.Background())
+		if len(finalLogs) != initialLogCount+1 {
+			t.Fatalf("Expected %d logs, got %d", initialLogCount+1, len(finalLogs))
+		}
+
+		// Find our log entry
+		var ourLog *models.ExecutionLog
+		for _, log := range finalLogs {
+			if log.ScheduledItemID == scheduledItemID {
+				ourLog = &log
+				break
+			}
+		}
+
+		if ourLog == nil {
+			t.Fatal("Log entry not found")
+		}
+
+		// Verify log details
+		if ourLog.Status != "error" {
+			t.Errorf("Expected status 'error', got '%s'", ourLog.Status)
+		}
+
+		if ourLog.ErrorMessage == nil || *ourLog.ErrorMessage != errorMsg {
+			t.Errorf("Expected error message '%s', got %v", errorMsg, ourLog.ErrorMessage)
+		}
+
+		if ourLog.TodoItemID != nil {
+			t.Errorf("Expected no todo item ID, got %v", *ourLog.TodoItemID)
+		}
+	})
+
+	t.Run("Reject invalid parameters", func(t *testing.T) {
+		initialLogCount := len(logStore.GetAllExecutionLogs(context.Background()))
+
+		// Test invalid scheduled item ID
+		service.logExecution(context.Background(), models.ScheduledItem{}, "success", nil, nil, nil)
+
+		// Test invalid status
+		service.logExecution(context.Background(), models.ScheduledItem{ID: 123}, "invalid_status", nil, nil, nil)
+
+		// Verify no logs were created
+		finalLogs := logStore.GetAllExecutionLogs(context.Background())
+		if len(finalLogs) != initialLogCount {
+			t.Errorf("Expected %d logs (no new logs), got %d", initialLogCount, len(finalLogs))
+		}
+	})
+}
+
+// Test the ExecuteScheduledItem method used for manual runs
+func TestExecuteScheduledItem(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(itemStore, todoStore, logStore)
+
+	nextExecution := time.Now().Add(time.Hour)
+	createdItem := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Manual task",
+		Description:     "Run on demand",
+		StartsAt:        nextExecution,
+		Repeats:         false,
+		NextExecutionAt: nextExecution,
+		RequestID:       "create-request",
+	})
+
+	executionLog, err := service.ExecuteScheduledItem(context.Background(), createdItem)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if executionLog.Status != "success" {
+		t.Errorf("Expected status 'success', got '%s'", executionLog.Status)
+	}
+
+	if executionLog.TodoItemID == nil {
+		t.Fatal("Expected execution log to reference the created todo item")
+	}
+	if executionLog.RequestID != "create-request" {
+		t.Errorf("Expected execution log to carry the item's request ID, got '%s'", executionLog.RequestID)
+	}
+
+	todo, exists := todoStore.GetTodoItem(context.Background(), *executionLog.TodoItemID)
+	if !exists {
+		t.Fatal("Todo item should have been created")
+	}
+	if todo.Text != "Manual task - Run on demand" {
+		t.Errorf("Unexpected todo text: '%s'", todo.Text)
+	}
+
+	// A manual run must not reschedule or delete the item
+	item, exists := itemStore.GetScheduledItem(context.Background(), createdItem.ID)
+	if !exists {
+		t.Fatal("Scheduled item should still exist after a manual run")
+	}
+	if !item.NextExecutionAt.Equal(nextExecution) {
+		t.Errorf("Next execution should be unchanged, got %v", item.NextExecutionAt)
+	}
+}
+
+// recordingNotifier records the executions it is told about
+type recordingNotifier struct {
+	executions []models.ExecutionLog
+}
+
+func (n *recordingNotifier) NotifyExecution(item models.ScheduledItem, execution models.ExecutionLog) {
+	n.executions = append(n.executions, execution)
+}
+
+func TestExecutionsAreNotified(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	notifier := &recordingNotifier{}
+	service.EnableNotifications(notifier)
+
+	item := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Notified", StartsAt: time.Now(), NextExecutionAt: time.Now()})
+	if _, err := service.ExecuteScheduledItem(context.Background(), item); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	item.ActionType = "unknown"
+	if _, err := service.ExecuteScheduledItem(context.Background(), item); err == nil {
+		t.Fatal("Expected an unknown action to fail")
+	}
+
+	if len(notifier.executions) != 2 || notifier.executions[0].Status != "success" || notifier.executions[1].Status != "error" {
+		t.Errorf("Expected a successful and a failed execution to be notified, got %+v", notifier.executions)
+	}
+}
+
+// recordingAlerter records the batches and failed items it is told about
+type recordingAlerter struct {
+	batches [][2]int
+	failed  []int64
+}
+
+func (a *recordingAlerter) BatchProcessed(succeeded int, failed int, claimErr error) {
+	a.batches = append(a.batches, [2]int{succeeded, failed})
+}
+
+func (a *recordingAlerter) ItemFailed(item models.ScheduledItem, execution models.ExecutionLog) {
+	a.failed = append(a.failed, item.ID)
+}
+
+func TestProcessingFailuresAreAlerted(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	alerter := &recordingAlerter{}
+	service.EnableAlerts(alerter)
+
+	due := time.Now().Add(-time.Minute)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Works", StartsAt: due, NextExecutionAt: due})
+	broken := itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Broken", StartsAt: due, NextExecutionAt: due, ActionType: "unknown"})
+
+	service.ProcessScheduledItems(context.Background())
+
+	if len(alerter.batches) != 1 || alerter.batches[0] != [2]int{1, 1} {
+		t.Errorf("Expected a batch with one success and one failure, got %v", alerter.batches)
+	}
+	if len(alerter.failed) != 1 || alerter.failed[0] != broken.ID {
+		t.Errorf("Expected the broken item to be alerted about, got %v", alerter.failed)
+	}
+}
+
+// failingRescheduleStore is a scheduled item store whose next execution updates always fail
+type failingRescheduleStore struct {
+	*store.MemoryScheduledItemStore
+}
+
+func (s failingRescheduleStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	return false
+}
+
+// recordingTransactor records the outcome of each transaction it runs
+type recordingTransactor struct {
+	errs []error
+}
+
+func (t *recordingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	t.errs = append(t.errs, err)
+	return err
+}
+
+func TestProcessRollsBackWhenRescheduleFails(t *testing.T) {
+	itemStore := failingRescheduleStore{store.NewMemoryScheduledItemStore()}
+	logStore := store.NewMemoryExecutionLogStore()
+	transactor := &recordingTransactor{}
+
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), logStore)
+	service.EnableTransactions(transactor)
+
+	cronExpr := "0 * * * *"
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Hourly task",
+		StartsAt:        time.Now().Add(-time.Hour),
+		Repeats:         true,
+		CronExpression:  &cronExpr,
+		NextExecutionAt: time.Now().Add(-time.Minute),
+	})
+
+	result := service.ProcessScheduledItems(context.Background())
+	if result.Failed != 1 || result.Succeeded != 0 {
+		t.Errorf("Expected the execution to fail, got %+v", result)
+	}
+
+	if len(transactor.errs) != 1 || transactor.errs[0] == nil {
+		t.Fatalf("Expected the transaction to be rolled back, got %v", transactor.errs)
+	}
+
+	// Memory stores can't undo writes, so the success log remains next to the failure log
+	logs := logStore.GetAllExecutionLogs(context.Background())
+	if len(logs) != 2 {
+		t.Fatalf("Expected the success log written in the transaction and the failure log, got %d", len(logs))
+	}
+	for _, logEntry := range logs {
+		if logEntry.Status == "error" && logEntry.ExecutionKey != nil {
+			t.Error("Failure log must not reserve the execution key")
+		}
+	}
+}
+
+// batchCountingTodoStore is a todo item store that counts its batch inserts, which fail
+// when fail is set
+type batchCountingTodoStore struct {
+	*store.MemoryTodoItemStore
+	batches int
+	fail    bool
+}
+
+func (s *batchCountingTodoStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	s.batches++
+	if s.fail {
+		return nil, errors.New("batch insert failed")
+	}
+	return s.MemoryTodoItemStore.CreateTodoItems(ctx, items)
+}
+
+func TestProcessBatchesTodoItems(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := &batchCountingTodoStore{MemoryTodoItemStore: store.NewMemoryTodoItemStore()}
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(itemStore, todoStore, logStore)
+	notifier := &recordingNotifier{}
+	service.EnableNotifications(notifier)
+
+	due := time.Now().Add(-time.Minute)
+	for _, title := range []string{"First", "Second", "Third"} {
+		itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: title, StartsAt: due, NextExecutionAt: due})
+	}
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Logged", StartsAt: due, NextExecutionAt: due, ActionType: ActionTypeLog})
+
+	result := service.ProcessScheduledItems(context.Background())
+	if result.Succeeded != 4 || result.Failed != 0 {
+		t.Fatalf("Expected 4 successful executions, got %+v", result)
+	}
+	if todoStore.batches != 1 {
+		t.Errorf("Expected the todo items to be created in one batch, got %d", todoStore.batches)
+	}
+	if todos := todoStore.GetAllTodoItems(context.Background()); len(todos) != 3 {
+		t.Errorf("Expected 3 todo items, got %d", len(todos))
+	}
+
+	logs := logStore.GetAllExecutionLogs(context.Background())
+	if len(logs) != 4 {
+		t.Fatalf("Expected 4 execution logs, got %d", len(logs))
+	}
+	for _, logEntry := range logs {
+		if logEntry.Status != "success" || logEntry.ExecutionKey == nil {
+			t.Errorf("Expected a keyed success log, got %+v", logEntry)
+		}
+	}
+	if len(notifier.executions) != 4 {
+		t.Errorf("Expected 4 executions to be notified, got %d", len(notifier.executions))
+	}
+	if remaining := itemStore.GetAllScheduledItems(context.Background()); len(remaining) != 0 {
+		t.Errorf("Expected the one-time items to be deleted, got %d left", len(remaining))
+	}
+}
+
+func TestFailedBatchIsProcessedOneByOne(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := &batchCountingTodoStore{MemoryTodoItemStore: store.NewMemoryTodoItemStore(), fail: true}
+	service := NewService(itemStore, todoStore, store.NewMemoryExecutionLogStore())
+
+	due := time.Now().Add(-time.Minute)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "First", StartsAt: due, NextExecutionAt: due})
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Second", StartsAt: due, NextExecutionAt: due})
+
+	result := service.ProcessScheduledItems(context.Background())
+	if result.Succeeded != 2 || result.Failed != 0 {
+		t.Fatalf("Expected both items to succeed one by one, got %+v", result)
+	}
+	if todos := todoStore.GetAllTodoItems(context.Background()); len(todos) != 2 {
+		t.Errorf("Expected 2 todo items, got %d", len(todos))
+	}
+}
+
+// failingBatchLogStore is an execution log store whose batch inserts write the first log
+// and then fail, as a batch can when its writes aren't in a transaction
+type failingBatchLogStore struct {
+	*store.MemoryExecutionLogStore
+}
+
+func (s failingBatchLogStore) CreateExecutionLogs(ctx context.Context, logs []models.ExecutionLog) ([]models.ExecutionLog, error) {
+	s.MemoryExecutionLogStore.CreateExecutionLogs(ctx, logs[:1])
+	return nil, errors.New("batch insert failed")
+}
+
+func TestFailedBatchWithoutTransactionIsNotExecutedAgain(t *testing.T) {
+	itemStore := store.NewMemoryScheduledItemStore()
+	todoStore := store.NewMemoryTodoItemStore()
+	logStore := failingBatchLogStore{store.NewMemoryExecutionLogStore()}
+	service := NewService(itemStore, todoStore, logStore)
+
+	due := time.Now().Add(-time.Minute)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "First", StartsAt: due, NextExecutionAt: due})
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Second", StartsAt: due, NextExecutionAt: due})
+
+	// The first item's execution was recorded and the second's wasn't, but neither todo
+	// item may be created twice
+	result := service.ProcessScheduledItems(context.Background())
+	if result.Skipped != 1 || result.Failed != 1 || result.Succeeded != 0 {
+		t.Errorf("Expected the recorded item to be skipped and the other to fail, got %+v", result)
+	}
+	if todos := todoStore.GetAllTodoItems(context.Background()); len(todos) != 2 {
+		t.Errorf("Expected 2 todo items, got %d", len(todos))
+	}
+	if remaining := itemStore.GetAllScheduledItems(context.Background()); len(remaining) != 0 {
+		t.Errorf("Expected both occurrences to be moved past, got %d items left", len(remaining))
+	}
+
+	// With a transaction that rolls back, the items are tried again one by one
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Third", StartsAt: due, NextExecutionAt: due})
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Fourth", StartsAt: due, NextExecutionAt: due})
+	service.EnableTransactions(&recordingTransactor{})
+	if result := service.ProcessScheduledItems(context.Background()); result.Succeeded+result.Skipped != 2 {
+		t.Errorf("Expected the items to be processed one by one, got %+v", result)
+	}
+}
+
+// batchCountingItemStore is a scheduled item store that counts its batch updates of next
+// execution times
+type batchCountingItemStore struct {
+	*store.MemoryScheduledItemStore
+	batches int
+}
+
+func (s *batchCountingItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	s.batches++
+	return s.MemoryScheduledItemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions)
+}
+
+func TestProcessBatchesNextExecutionUpdates(t *testing.T) {
+	itemStore := &batchCountingItemStore{MemoryScheduledItemStore: store.NewMemoryScheduledItemStore()}
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+
+	cronExpr := "0 * * * *"
+	due := time.Now().Add(-time.Minute)
+	for _, title := range []string{"First", "Second"} {
+		itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{
+			Title: title, StartsAt: due.Add(-time.Hour), Repeats: true, CronExpression: &cronExpr, NextExecutionAt: due,
+		})
+	}
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Once", StartsAt: due, NextExecutionAt: due})
+
+	result := service.ProcessScheduledItems(context.Background())
+	if result.Succeeded != 3 {
+		t.Fatalf("Expected 3 successful executions, got %+v", result)
+	}
+	if itemStore.batches != 1 {
+		t.Errorf("Expected one batch update, got %d", itemStore.batches)
+	}
+
+	items := itemStore.GetAllScheduledItems(context.Background())
+	if len(items) != 2 {
+		t.Fatalf("Expected the repeating items to remain, got %d items", len(items))
+	}
+	for _, item := range items {
+		if !item.NextExecutionAt.After(time.Now()) {
+			t.Errorf("Expected item ID=%d to be rescheduled, got %v", item.ID, item.NextExecutionAt)
+		}
+	}
+}
+
+// Test that a running service picks up a new interval
+func TestSetIntervalResetsTicker(t *testing.T) {
+	service := NewService(store.NewMemoryScheduledItemStore(), store.NewMemoryTodoItemStore(), store.NewMemoryExecutionLogStore())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go service.Run(ctx, time.Hour)
+	deadline := time.Now().Add(time.Second)
+	for service.Status().LastTickAt.IsZero() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	firstTick := service.Status().LastTickAt
+
+	service.SetInterval(10 * time.Millisecond)
+	for !service.Status().LastTickAt.After(firstTick) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	status := service.Status()
+	if !status.LastTickAt.After(firstTick) || status.Interval != "10ms" {
+		t.Errorf("Expected ticks every 10ms after the change, got %+v", status)
+	}
+}
+
+// resumingItemStore is a scheduled item store whose first claims fail, like a database
+// resuming from a pause
+type resumingItemStore struct {
+	*store.MemoryScheduledItemStore
+	failures int32
+	claims   atomic.Int32
+}
+
+func (s *resumingItemStore) ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error) {
+	if s.claims.Add(1) <= s.failures {
+		return nil, errors.New("database is resuming")
+	}
+	return s.MemoryScheduledItemStore.ClaimDueItems(ctx, limit, lease)
+}
+
+// Test that a running service retries a failed claim before the next tick
+func TestRunRetriesFailedClaims(t *testing.T) {
+	itemStore := &resumingItemStore{MemoryScheduledItemStore: store.NewMemoryScheduledItemStore(), failures: 2}
+	logStore := store.NewMemoryExecutionLogStore()
+	service := NewService(itemStore, store.NewMemoryTodoItemStore(), logStore)
+	service.claimRetryBackoff = 5 * time.Millisecond
+
+	due := time.Now().Add(-time.Minute)
+	itemStore.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Due", StartsAt: due, NextExecutionAt: due})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx, time.Hour)
+
+	deadline := time.Now().Add(time.Second)
+	for len(logStore.GetAllExecutionLogs(context.Background())) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if logs := logStore.GetAllExecutionLogs(context.Background()); len(logs) != 1 {
+		t.Errorf("Expected the item to be executed once the claim succeeded, got %d logs", len(logs))
+	}
+	if claims := itemStore.claims.Load(); claims != 3 {
+		t.Errorf("Expected 3 claims, got %d", claims)
+	}
+}
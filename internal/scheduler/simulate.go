@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/utils"
+)
+
+// Simulation is what processing a due item at some time would do
+type Simulation struct {
+	Item models.ScheduledItem `json:"item"`
+	// Action describes what the item's action would do, such as the todo it would create
+	Action string `json:"action"`
+	// TodoText is the text of the todo item a todo action would create
+	TodoText string `json:"todoText,omitempty"`
+	// NextExecutionAt is when the item would be executed next, before any jitter, or nil
+	// when it would be deleted
+	NextExecutionAt *time.Time `json:"nextExecutionAt"`
+}
+
+// Simulate works out which of items would be due at the given time and what processing
+// each of them would do, in the order they came due, without executing or changing
+// anything. Like the scheduler, it skips paused and expired items.
+func Simulate(items []models.ScheduledItem, at time.Time) []Simulation {
+	var simulations []Simulation
+	for _, item := range items {
+		if item.Paused || item.NextExecutionAt.After(at) || (item.Expiration != nil && !item.Expiration.After(at)) {
+			continue
+		}
+
+		simulation := Simulation{Item: item}
+		switch item.ActionType {
+		case "", ActionTypeTodo:
+			simulation.TodoText = createTodoText(item)
+			simulation.Action = fmt.Sprintf("create todo %q", simulation.TodoText)
+		case ActionTypeWebhook:
+			simulation.Action = "call webhook"
+			if config, err := parseWebhookConfig(item.ActionConfig); err == nil {
+				simulation.Action += " " + config.Method + " " + config.URL
+			}
+		case ActionTypeMQTT:
+			simulation.Action = "publish to MQTT"
+			if config, err := parseMQTTConfig(item.ActionConfig); err == nil && config.Topic != "" {
+				simulation.Action += " topic " + config.Topic
+			}
+		default:
+			simulation.Action = item.ActionType
+		}
+		if item.Repeats {
+			simulation.NextExecutionAt = utils.CalculateNextExecutionAt(at, item.StartsAt, item.Repeats, item.CronExpression, item.Expiration, 0)
+		}
+		simulations = append(simulations, simulation)
+	}
+
+	sort.SliceStable(simulations, func(i, j int) bool {
+		return simulations[i].Item.NextExecutionAt.Before(simulations[j].Item.NextExecutionAt)
+	})
+	return simulations
+}
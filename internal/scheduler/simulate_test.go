@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+)
+
+func TestSimulate(t *testing.T) {
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	daily := "0 9 * * *"
+	hourly := "30 * * * *"
+	expiration := at.Add(-time.Minute)
+	laterExpiration := at.Add(12 * time.Hour)
+
+	items := []models.ScheduledItem{
+		{ID: 1, Title: "Standup", Description: "Daily", Repeats: true, CronExpression: &daily,
+			StartsAt: at.AddDate(0, -1, 0), NextExecutionAt: at},
+		{ID: 2, Title: "Once", StartsAt: at.Add(-time.Hour), NextExecutionAt: at.Add(-time.Hour)},
+		{ID: 3, Title: "Not yet", Repeats: true, CronExpression: &daily, NextExecutionAt: at.Add(time.Minute)},
+		{ID: 4, Title: "Paused", Repeats: true, CronExpression: &daily, NextExecutionAt: at, Paused: true},
+		{ID: 5, Title: "Expired", Repeats: true, CronExpression: &daily, NextExecutionAt: at, Expiration: &expiration},
+		{ID: 6, Title: "Ping", Repeats: true, CronExpression: &hourly, StartsAt: at.AddDate(0, -1, 0),
+			NextExecutionAt: at.Add(-30 * time.Minute), Expiration: &laterExpiration,
+			ActionType: ActionTypeWebhook, ActionConfig: json.RawMessage(`{"url":"https://example.com/hook"}`)},
+	}
+
+	simulations := Simulate(items, at)
+	if len(simulations) != 3 {
+		t.Fatalf("Expected 3 due items, got %d: %+v", len(simulations), simulations)
+	}
+
+	// Ordered by when they came due
+	if simulations[0].Item.ID != 2 || simulations[1].Item.ID != 6 || simulations[2].Item.ID != 1 {
+		t.Errorf("Expected items 2, 6 and 1, got %d, %d and %d", simulations[0].Item.ID, simulations[1].Item.ID, simulations[2].Item.ID)
+	}
+
+	once := simulations[0]
+	if once.TodoText != "Once" || once.NextExecutionAt != nil {
+		t.Errorf("Expected the one-time item to create a todo and be deleted, got %+v", once)
+	}
+
+	ping := simulations[1]
+	if ping.TodoText != "" || ping.Action != "call webhook POST https://example.com/hook" {
+		t.Errorf("Expected the webhook to be described, got %q", ping.Action)
+	}
+	if ping.NextExecutionAt == nil || !ping.NextExecutionAt.Equal(at.Add(30*time.Minute)) {
+		t.Errorf("Expected the webhook next at %v, got %v", at.Add(30*time.Minute), ping.NextExecutionAt)
+	}
+
+	standup := simulations[2]
+	if standup.TodoText != "Standup - Daily" {
+		t.Errorf("Expected the todo text \"Standup - Daily\", got %q", standup.TodoText)
+	}
+	if standup.NextExecutionAt == nil || !standup.NextExecutionAt.Equal(at.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the standup next at %v, got %v", at.AddDate(0, 0, 1), standup.NextExecutionAt)
+	}
+}
@@ -0,0 +1,149 @@
+// Package seed loads development and demo data into the stores from fixture files
+package seed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"periodic-api/internal/utils"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Production is the environment that is never seeded
+const Production = "production"
+
+// environmentName matches the environment names fixtures can be loaded for
+var environmentName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Fixtures is the data seeded into an environment, read from <environment>.yaml
+type Fixtures struct {
+	// Tenant is the tenant the data is seeded into; DefaultTenant when empty
+	Tenant         string          `yaml:"tenant"`
+	Users          []User          `yaml:"users"`
+	TodoItems      []TodoItem      `yaml:"todoItems"`
+	ScheduledItems []ScheduledItem `yaml:"scheduledItems"`
+}
+
+// User is a user to seed
+type User struct {
+	Username     string `yaml:"username"`
+	PasswordHash string `yaml:"passwordHash"`
+}
+
+// TodoItem is a todo item to seed
+type TodoItem struct {
+	Text    string `yaml:"text"`
+	Checked bool   `yaml:"checked"`
+}
+
+// ScheduledItem is a scheduled item to seed. Repeating items without a start begin when
+// they are seeded; items that don't repeat need a start in the future.
+type ScheduledItem struct {
+	Title          string     `yaml:"title"`
+	Description    string     `yaml:"description"`
+	StartsAt       *time.Time `yaml:"startsAt"`
+	Repeats        bool       `yaml:"repeats"`
+	CronExpression *string    `yaml:"cronExpression"`
+}
+
+// Stores are the stores fixtures are seeded into
+type Stores struct {
+	Users          store.UserStore
+	TodoItems      store.TodoItemStore
+	ScheduledItems store.ScheduledItemStore
+}
+
+// Load reads the fixtures of an environment from dir. The production environment is
+// refused, so demo data can't end up in front of customers.
+func Load(dir, environment string) (Fixtures, error) {
+	if environment == Production {
+		return Fixtures{}, fmt.Errorf("refusing to seed %s", Production)
+	}
+	if !environmentName.MatchString(environment) {
+		return Fixtures{}, fmt.Errorf("invalid environment %q", environment)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, environment+".yaml"))
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("could not read fixtures: %w", err)
+	}
+
+	var fixtures Fixtures
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fixtures); err != nil {
+		return Fixtures{}, fmt.Errorf("invalid fixtures in %s.yaml: %w", environment, err)
+	}
+	for i, item := range fixtures.ScheduledItems {
+		if item.Title == "" {
+			return Fixtures{}, fmt.Errorf("scheduled item %d of %s.yaml has no title", i+1, environment)
+		}
+	}
+	return fixtures, nil
+}
+
+// Apply seeds fixtures into stores in their tenant. Each kind of data is only seeded while
+// the tenant has none yet, so seeding again changes nothing. Scheduled items whose next
+// execution can't be determined are skipped with a warning.
+func Apply(ctx context.Context, fixtures Fixtures, stores Stores) {
+	if fixtures.Tenant != "" {
+		ctx = store.WithTenant(ctx, fixtures.Tenant)
+	}
+	// Seed everything whoever the context's user is
+	ctx = store.WithoutUser(ctx)
+
+	if stores.Users != nil && len(fixtures.Users) > 0 && len(stores.Users.GetAllUsers(ctx)) == 0 {
+		for _, user := range fixtures.Users {
+			stores.Users.CreateUser(ctx, models.User{
+				Username:     user.Username,
+				PasswordHash: []byte(user.PasswordHash),
+			})
+		}
+		logging.Infof("Seeded %d users", len(fixtures.Users))
+	}
+
+	if stores.TodoItems != nil && len(fixtures.TodoItems) > 0 && len(stores.TodoItems.GetAllTodoItems(ctx)) == 0 {
+		for _, todo := range fixtures.TodoItems {
+			stores.TodoItems.CreateTodoItem(ctx, models.TodoItem{
+				Text:    todo.Text,
+				Checked: todo.Checked,
+			})
+		}
+		logging.Infof("Seeded %d todo items", len(fixtures.TodoItems))
+	}
+
+	if stores.ScheduledItems != nil && len(fixtures.ScheduledItems) > 0 && len(stores.ScheduledItems.GetAllScheduledItems(ctx)) == 0 {
+		seeded := 0
+		now := time.Now()
+		for _, fixture := range fixtures.ScheduledItems {
+			item := models.ScheduledItem{
+				Title:          fixture.Title,
+				Description:    fixture.Description,
+				StartsAt:       now,
+				Repeats:        fixture.Repeats,
+				CronExpression: fixture.CronExpression,
+			}
+			if fixture.StartsAt != nil {
+				item.StartsAt = *fixture.StartsAt
+			}
+
+			next := utils.CalculateNextExecution(item.StartsAt, item.Repeats, item.CronExpression, nil, 0)
+			if next == nil {
+				logging.Warnf("Not seeding scheduled item %q: cannot determine its next execution time", item.Title)
+				continue
+			}
+			item.NextExecutionAt = *next
+			stores.ScheduledItems.CreateScheduledItem(ctx, item)
+			seeded++
+		}
+		logging.Infof("Seeded %d scheduled items", seeded)
+	}
+}
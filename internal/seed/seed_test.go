@@ -0,0 +1,44 @@
+package seed
+
+import (
+	"context"
+	"periodic-api/internal/store"
+	"testing"
+)
+
+func TestApplySeedsEachEnvironmentOnce(t *testing.T) {
+	for _, environment := range []string{"development", "demo"} {
+		fixtures, err := Load("../../seeds", environment)
+		if err != nil {
+			t.Fatalf("Load(%s) failed: %v", environment, err)
+		}
+
+		stores := Stores{
+			Users:          store.NewMemoryUserStore(),
+			TodoItems:      store.NewMemoryTodoItemStore(),
+			ScheduledItems: store.NewMemoryScheduledItemStore(),
+		}
+		Apply(context.Background(), fixtures, stores)
+		Apply(context.Background(), fixtures, stores)
+
+		ctx := store.WithTenant(context.Background(), fixtures.Tenant)
+		if users := stores.Users.GetAllUsers(ctx); len(users) != len(fixtures.Users) {
+			t.Errorf("Expected %d %s users once, got %d", len(fixtures.Users), environment, len(users))
+		}
+		if todos := stores.TodoItems.GetAllTodoItems(ctx); len(todos) != len(fixtures.TodoItems) {
+			t.Errorf("Expected %d %s todo items once, got %d", len(fixtures.TodoItems), environment, len(todos))
+		}
+		if items := stores.ScheduledItems.GetAllScheduledItems(ctx); len(items) != len(fixtures.ScheduledItems) {
+			t.Errorf("Expected all %d %s scheduled items to be schedulable, got %d", len(fixtures.ScheduledItems), environment, len(items))
+		}
+	}
+}
+
+func TestLoadRefusesProduction(t *testing.T) {
+	if _, err := Load("../../seeds", Production); err == nil {
+		t.Error("Expected production not to be seeded")
+	}
+	if _, err := Load("../../seeds", "../migrations/000001_initial_schema"); err == nil {
+		t.Error("Expected environment names to stay inside the seeds directory")
+	}
+}
@@ -0,0 +1,201 @@
+// Package storage selects the storage backend the commands work on and opens its stores
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+
+	"periodic-api/internal/db"
+	"periodic-api/internal/store"
+)
+
+// Kind is a storage backend
+type Kind string
+
+const (
+	Postgres Kind = "postgres"
+	DynamoDB Kind = "dynamodb"
+	Memory   Kind = "memory"
+)
+
+// FlagUsage describes the --store flag every command selecting a backend takes
+const FlagUsage = "Storage backend: postgres, dynamodb or memory (overrides USE_POSTGRES_DB and USE_DYNAMODB)"
+
+// Flag registers the --store flag, which selects the backend instead of the environment
+func Flag() *string {
+	return FlagSet(flag.CommandLine)
+}
+
+// FlagSet registers the --store flag on the flags of a subcommand
+func FlagSet(flags *flag.FlagSet) *string {
+	return flags.String("store", "", FlagUsage)
+}
+
+// Parse returns the backend of the given name
+func Parse(name string) (Kind, error) {
+	switch kind := Kind(strings.ToLower(name)); kind {
+	case Postgres, DynamoDB, Memory:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("unknown store %q: must be postgres, dynamodb or memory", name)
+	}
+}
+
+// FromEnv returns the backend selected by USE_POSTGRES_DB or, when it isn't set to true,
+// USE_DYNAMODB; without either the stores are kept in memory
+func FromEnv() Kind {
+	switch {
+	case strings.ToLower(os.Getenv("USE_POSTGRES_DB")) == "true":
+		return Postgres
+	case strings.ToLower(os.Getenv("USE_DYNAMODB")) == "true":
+		return DynamoDB
+	default:
+		return Memory
+	}
+}
+
+// Select returns the backend named by a --store value, or the one selected by the
+// environment when the flag wasn't given
+func Select(name string) (Kind, error) {
+	if name == "" {
+		return FromEnv(), nil
+	}
+	return Parse(name)
+}
+
+// Env returns the environment variables selecting the backend, for commands that pass the
+// selection on to code reading the environment
+func (k Kind) Env() map[string]string {
+	return map[string]string{
+		"USE_POSTGRES_DB": fmt.Sprint(k == Postgres),
+		"USE_DYNAMODB":    fmt.Sprint(k == DynamoDB),
+	}
+}
+
+// Stores are the stores of a backend. Wrappers such as the auditing, publishing and
+// caching stores are left to the commands that want them.
+type Stores struct {
+	Kind Kind
+
+	ScheduledItems          store.ScheduledItemStore
+	TodoItems               store.TodoItemStore
+	Users                   store.UserStore
+	ExecutionLogs           store.ExecutionLogStore
+	Heartbeats              store.SchedulerHeartbeatStore
+	Webhooks                store.WebhookStore
+	NotificationPreferences store.NotificationPreferenceStore
+	DeviceTokens            store.DeviceTokenStore
+	Organizations           store.OrganizationStore
+	ItemShares              store.ItemShareStore
+	TenantLimits            store.TenantLimitStore
+	LLMUsage                store.LLMUsageStore
+	GenerationSessions      store.GenerationSessionStore
+	AuditLog                store.AuditLogStore
+
+	// Transactor runs store operations in a transaction with PostgreSQL, and as they come
+	// otherwise
+	Transactor store.Transactor
+	// Restorer loads records with their IDs; only PostgreSQL has one
+	Restorer store.Restorer
+
+	// DB is the PostgreSQL database, for PostgreSQL
+	DB *sql.DB
+	// Dynamo and Table are the DynamoDB client and table, for DynamoDB
+	Dynamo *dynamodb.Client
+	Table  string
+}
+
+// Open connects to the backend and creates its stores. PostgreSQL databases are pinged
+// first; the caller closes them with Close.
+func Open(ctx context.Context, kind Kind) (*Stores, error) {
+	stores := &Stores{Kind: kind, Transactor: store.NoopTransactor{}}
+	switch kind {
+	case Postgres:
+		database, err := db.InitDB()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize database: %w", err)
+		}
+		stores.DB = database
+		stores.ScheduledItems = store.NewPostgresScheduledItemStore(database)
+		stores.TodoItems = store.NewPostgresTodoItemStore(database)
+		stores.Users = store.NewPostgresUserStore(database)
+		stores.ExecutionLogs = store.NewPostgresExecutionLogStore(database)
+		stores.Heartbeats = store.NewPostgresSchedulerHeartbeatStore(database)
+		stores.Webhooks = store.NewPostgresWebhookStore(database)
+		stores.NotificationPreferences = store.NewPostgresNotificationPreferenceStore(database)
+		stores.DeviceTokens = store.NewPostgresDeviceTokenStore(database)
+		stores.Organizations = store.NewPostgresOrganizationStore(database)
+		stores.ItemShares = store.NewPostgresItemShareStore(database)
+		stores.TenantLimits = store.NewPostgresTenantLimitStore(database)
+		stores.LLMUsage = store.NewPostgresLLMUsageStore(database)
+		stores.GenerationSessions = store.NewPostgresGenerationSessionStore(database)
+		stores.AuditLog = store.NewPostgresAuditLogStore(database)
+		stores.Transactor = store.NewPostgresTransactor(database)
+		stores.Restorer = store.NewPostgresRestorer(database)
+	case DynamoDB:
+		client, err := db.NewDynamoClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize DynamoDB client: %w", err)
+		}
+		table := db.DynamoTableName()
+		stores.Dynamo, stores.Table = client, table
+		stores.ScheduledItems = store.NewDynamoScheduledItemStore(client, table)
+		stores.TodoItems = store.NewDynamoTodoItemStore(client, table)
+		stores.Users = store.NewDynamoUserStore(client, table)
+		stores.ExecutionLogs = store.NewDynamoExecutionLogStore(client, table)
+		stores.Heartbeats = store.NewDynamoSchedulerHeartbeatStore(client, table)
+		stores.Webhooks = store.NewDynamoWebhookStore(client, table)
+		stores.NotificationPreferences = store.NewDynamoNotificationPreferenceStore(client, table)
+		stores.DeviceTokens = store.NewDynamoDeviceTokenStore(client, table)
+		stores.Organizations = store.NewDynamoOrganizationStore(client, table)
+		stores.ItemShares = store.NewDynamoItemShareStore(client, table)
+		stores.TenantLimits = store.NewDynamoTenantLimitStore(client, table)
+		stores.LLMUsage = store.NewDynamoLLMUsageStore(client, table)
+		stores.GenerationSessions = store.NewDynamoGenerationSessionStore(client, table)
+		stores.AuditLog = store.NewDynamoAuditLogStore(client, table)
+	case Memory:
+		stores.ScheduledItems = store.NewMemoryScheduledItemStore()
+		stores.TodoItems = store.NewMemoryTodoItemStore()
+		stores.Users = store.NewMemoryUserStore()
+		stores.ExecutionLogs = store.NewMemoryExecutionLogStore()
+		stores.Heartbeats = store.NewMemorySchedulerHeartbeatStore()
+		stores.Webhooks = store.NewMemoryWebhookStore()
+		stores.NotificationPreferences = store.NewMemoryNotificationPreferenceStore()
+		stores.DeviceTokens = store.NewMemoryDeviceTokenStore()
+		stores.Organizations = store.NewMemoryOrganizationStore()
+		stores.ItemShares = store.NewMemoryItemShareStore()
+		stores.TenantLimits = store.NewMemoryTenantLimitStore()
+		stores.LLMUsage = store.NewMemoryLLMUsageStore()
+		stores.GenerationSessions = store.NewMemoryGenerationSessionStore()
+		stores.AuditLog = store.NewMemoryAuditLogStore()
+	default:
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+	return stores, nil
+}
+
+// Close closes the PostgreSQL database; the other backends hold nothing to close
+func (s *Stores) Close() error {
+	if s.DB == nil {
+		return nil
+	}
+	return s.DB.Close()
+}
+
+// Description names the backend for log messages, such as "DynamoDB table periodic"
+func (s *Stores) Description() string {
+	switch s.Kind {
+	case Postgres:
+		return "PostgreSQL database"
+	case DynamoDB:
+		return "DynamoDB table " + s.Table
+	default:
+		return "in-memory stores"
+	}
+}
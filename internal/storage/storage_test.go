@@ -0,0 +1,61 @@
+package storage
+
+import "testing"
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name        string
+		flag        string
+		usePostgres string
+		useDynamo   string
+		want        Kind
+	}{
+		{name: "environment postgres", usePostgres: "true", useDynamo: "true", want: Postgres},
+		{name: "environment dynamodb", usePostgres: "false", useDynamo: "TRUE", want: DynamoDB},
+		{name: "environment memory", want: Memory},
+		{name: "flag overrides environment", flag: "memory", usePostgres: "true", want: Memory},
+		{name: "flag is case insensitive", flag: "DynamoDB", want: DynamoDB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("USE_POSTGRES_DB", tt.usePostgres)
+			t.Setenv("USE_DYNAMODB", tt.useDynamo)
+			got, err := Select(tt.flag)
+			if err != nil {
+				t.Fatalf("Select(%q) failed: %v", tt.flag, err)
+			}
+			if got != tt.want {
+				t.Errorf("Select(%q) = %q, want %q", tt.flag, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Select("mysql"); err == nil {
+		t.Error("Expected an unknown store to be rejected")
+	}
+}
+
+func TestKindEnvSelectsKind(t *testing.T) {
+	for _, kind := range []Kind{Postgres, DynamoDB, Memory} {
+		for name, value := range kind.Env() {
+			t.Setenv(name, value)
+		}
+		if got := FromEnv(); got != kind {
+			t.Errorf("FromEnv() after %q.Env() = %q", kind, got)
+		}
+	}
+}
+
+func TestOpenMemory(t *testing.T) {
+	stores, err := Open(t.Context(), Memory)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer stores.Close()
+	if stores.ScheduledItems == nil || stores.AuditLog == nil || stores.Transactor == nil {
+		t.Errorf("Expected the in-memory stores to be created, got %+v", stores)
+	}
+	if stores.DB != nil || stores.Dynamo != nil || stores.Restorer != nil {
+		t.Errorf("Expected no database for the in-memory stores, got %+v", stores)
+	}
+}
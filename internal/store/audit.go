@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+type actorContextKey struct{}
+
+// WithActor returns a context whose changes are recorded in the audit log as made by actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or "" when there is none
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// recordAudit adds an entry for a change made by the context's actor to the audit log.
+// Changes without an actor, such as those made by the scheduler, are not audited. A nil
+// before or after is left out of the entry.
+func recordAudit(ctx context.Context, auditStore AuditLogStore, action string, entityType string, entityID int64, before any, after any) {
+	actor := ActorFromContext(ctx)
+	if actor == "" {
+		return
+	}
+
+	entry := models.AuditLogEntry{
+		Actor:      actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		logging.Errorf("Error marshalling %s %d for the audit log: %v", entityType, entityID, err)
+		return
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		logging.Errorf("Error marshalling %s %d for the audit log: %v", entityType, entityID, err)
+		return
+	}
+
+	if created := auditStore.CreateAuditLogEntry(ctx, entry); created.ID == 0 {
+		logging.Errorf("Failed to audit %s of %s %d by %s", action, entityType, entityID, actor)
+	}
+}
+
+// auditSnapshot returns the JSON recorded for an entity, or nil when there is none
+func auditSnapshot(entity any) (json.RawMessage, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	return json.Marshal(entity)
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// PostgresAuditLogStore provides PostgreSQL storage operations for the audit log
+type PostgresAuditLogStore struct {
+	db *sql.DB
+}
+
+// NewPostgresAuditLogStore creates a new PostgreSQL audit log store with the given database connection
+func NewPostgresAuditLogStore(db *sql.DB) *PostgresAuditLogStore {
+	return &PostgresAuditLogStore{
+		db: db,
+	}
+}
+
+// CreateAuditLogEntry records a change in the database. Inside a transaction the entry is
+// written with the change, so it is only kept if the change commits.
+func (s *PostgresAuditLogStore) CreateAuditLogEntry(ctx context.Context, entry models.AuditLogEntry) models.AuditLogEntry {
+	query := `
+		INSERT INTO audit_log
+		(actor, action, entity_type, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		entry.Actor,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+	).Scan(&entry.ID, &entry.CreatedAt)
+
+	if err != nil {
+		logging.Errorf("Error creating audit log entry: %v", err)
+		return models.AuditLogEntry{} // Return empty entry on error
+	}
+
+	return entry
+}
+
+// GetAuditLog returns the entries matching the filter from the database, newest first
+func (s *PostgresAuditLogStore) GetAuditLog(ctx context.Context, filter AuditLogFilter) []models.AuditLogEntry {
+	// Zero bounds are replaced so the period conditions always apply
+	since, until := filter.Since, filter.Until
+	if until.IsZero() {
+		until = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	query := `
+		SELECT id, actor, action, entity_type, entity_id, before, after, created_at
+		FROM audit_log
+		WHERE ($1 = '' OR actor = $1)
+		  AND ($2 = '' OR entity_type = $2)
+		  AND ($3 = 0 OR entity_id = $3)
+		  AND created_at >= $4 AND created_at < $5
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6
+	`
+
+	rows, err := querier(ctx, s.db).QueryContext(ctx, query, filter.Actor, filter.EntityType, filter.EntityID, since, until, filter.Limit)
+	if err != nil {
+		logging.Errorf("Error querying audit log: %v", err)
+		return []models.AuditLogEntry{}
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLogEntry{}
+	for rows.Next() {
+		var entry models.AuditLogEntry
+		var before, after []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Actor,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		// A NULL column scans as nil, which is omitted from the entry
+		entry.Before = before
+		entry.After = after
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+
+	return entries
+}
+
+// nullableJSON returns a JSON document as a query parameter, or NULL when there is none
+func nullableJSON(document json.RawMessage) any {
+	if document == nil {
+		return nil
+	}
+	return string(document)
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoAuditLogEntry is the DynamoDB representation of an audit log entry. Entries are
+// sorted by creation time, like LLM usage, so the newest are read first.
+type dynamoAuditLogEntry struct {
+	PK         string    `dynamodbav:"pk"`
+	SK         string    `dynamodbav:"sk"`
+	ID         int64     `dynamodbav:"id"`
+	Actor      string    `dynamodbav:"actor"`
+	Action     string    `dynamodbav:"action"`
+	EntityType string    `dynamodbav:"entity_type"`
+	EntityID   int64     `dynamodbav:"entity_id"`
+	Before     string    `dynamodbav:"before,omitempty"`
+	After      string    `dynamodbav:"after,omitempty"`
+	CreatedAt  time.Time `dynamodbav:"created_at"`
+}
+
+// toModel converts the DynamoDB representation back to an audit log entry
+func (r dynamoAuditLogEntry) toModel() models.AuditLogEntry {
+	entry := models.AuditLogEntry{
+		ID:         r.ID,
+		Actor:      r.Actor,
+		Action:     r.Action,
+		EntityType: r.EntityType,
+		EntityID:   r.EntityID,
+		CreatedAt:  r.CreatedAt,
+	}
+	if r.Before != "" {
+		entry.Before = json.RawMessage(r.Before)
+	}
+	if r.After != "" {
+		entry.After = json.RawMessage(r.After)
+	}
+	return entry
+}
+
+// DynamoAuditLogStore provides DynamoDB storage operations for the audit log
+type DynamoAuditLogStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoAuditLogStore creates a new DynamoDB audit log store using the given client and table
+func NewDynamoAuditLogStore(client *dynamodb.Client, table string) *DynamoAuditLogStore {
+	return &DynamoAuditLogStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateAuditLogEntry records a change in the table
+func (s *DynamoAuditLogStore) CreateAuditLogEntry(ctx context.Context, entry models.AuditLogEntry) models.AuditLogEntry {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityAuditLog)
+	if err != nil {
+		logging.Errorf("Error allocating audit log entry ID: %v", err)
+		return models.AuditLogEntry{}
+	}
+	entry.ID = id
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	record, err := attributevalue.MarshalMap(dynamoAuditLogEntry{
+		PK:         dynamoEntityAuditLog,
+		SK:         dynamoTimeSortKey(entry.CreatedAt) + "#" + dynamoSortKeyForID(entry.ID),
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		Action:     entry.Action,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Before:     string(entry.Before),
+		After:      string(entry.After),
+		CreatedAt:  entry.CreatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling audit log entry: %v", err)
+		return models.AuditLogEntry{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error creating audit log entry: %v", err)
+		return models.AuditLogEntry{} // Return empty entry on error
+	}
+
+	return entry
+}
+
+// GetAuditLog returns the entries matching the filter from the table, newest first
+func (s *DynamoAuditLogStore) GetAuditLog(ctx context.Context, filter AuditLogFilter) []models.AuditLogEntry {
+	// "~" sorts after every timestamp, so an open period reaches the newest entry
+	until := "~"
+	if !filter.Until.IsZero() {
+		until = dynamoTimeSortKey(filter.Until)
+	}
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :since AND :until"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: dynamoEntityAuditLog},
+			":since": &types.AttributeValueMemberS{Value: dynamoTimeSortKey(filter.Since)},
+			":until": &types.AttributeValueMemberS{Value: until},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	var conditions []string
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = :actor")
+		input.ExpressionAttributeValues[":actor"] = &types.AttributeValueMemberS{Value: filter.Actor}
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "entity_type = :entity_type")
+		input.ExpressionAttributeValues[":entity_type"] = &types.AttributeValueMemberS{Value: filter.EntityType}
+	}
+	if filter.EntityID != 0 {
+		conditions = append(conditions, "entity_id = :entity_id")
+		input.ExpressionAttributeValues[":entity_id"] = dynamoNumber(filter.EntityID)
+	}
+	if len(conditions) > 0 {
+		input.FilterExpression = aws.String(strings.Join(conditions, " AND "))
+	} else {
+		input.Limit = aws.Int32(int32(filter.Limit))
+	}
+
+	// Filters apply after each page is read, so keep paging until there are enough entries
+	entries := []models.AuditLogEntry{}
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() && len(entries) < filter.Limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying audit log: %v", err)
+			return []models.AuditLogEntry{}
+		}
+
+		var records []dynamoAuditLogEntry
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling audit log: %v", err)
+			return []models.AuditLogEntry{}
+		}
+		for _, record := range records {
+			if len(entries) == filter.Limit {
+				break
+			}
+			entries = append(entries, record.toModel())
+		}
+	}
+	return entries
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"sync"
+	"time"
+)
+
+// MemoryAuditLogStore provides in-memory storage operations for the audit log
+type MemoryAuditLogStore struct {
+	sync.RWMutex
+	entries []models.AuditLogEntry
+	nextID  int64
+}
+
+// NewMemoryAuditLogStore creates a new in-memory audit log store
+func NewMemoryAuditLogStore() *MemoryAuditLogStore {
+	return &MemoryAuditLogStore{
+		entries: []models.AuditLogEntry{},
+		nextID:  1,
+	}
+}
+
+// CreateAuditLogEntry records a change in the in-memory store
+func (s *MemoryAuditLogStore) CreateAuditLogEntry(ctx context.Context, entry models.AuditLogEntry) models.AuditLogEntry {
+	s.Lock()
+	defer s.Unlock()
+
+	entry.ID = s.nextID
+	s.nextID++
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	s.entries = append(s.entries, entry)
+	return entry
+}
+
+// GetAuditLog returns the entries matching the filter from the in-memory store, newest first
+func (s *MemoryAuditLogStore) GetAuditLog(ctx context.Context, filter AuditLogFilter) []models.AuditLogEntry {
+	s.RLock()
+	defer s.RUnlock()
+
+	entries := []models.AuditLogEntry{}
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		if filter.Matches(s.entries[i]) {
+			entries = append(entries, s.entries[i])
+		}
+	}
+	return entries
+}
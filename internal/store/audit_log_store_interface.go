@@ -0,0 +1,34 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// AuditLogFilter selects audit log entries; zero fields match every entry
+type AuditLogFilter struct {
+	Actor      string
+	EntityType string
+	EntityID   int64
+	// Since and Until bound when the change was made, inclusive and exclusive
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Matches reports whether an entry is selected by the filter, ignoring the limit
+func (f AuditLogFilter) Matches(entry models.AuditLogEntry) bool {
+	return (f.Actor == "" || entry.Actor == f.Actor) &&
+		(f.EntityType == "" || entry.EntityType == f.EntityType) &&
+		(f.EntityID == 0 || entry.EntityID == f.EntityID) &&
+		(f.Since.IsZero() || !entry.CreatedAt.Before(f.Since)) &&
+		(f.Until.IsZero() || entry.CreatedAt.Before(f.Until))
+}
+
+// AuditLogStore defines the interface for recording and querying the audit log
+type AuditLogStore interface {
+	CreateAuditLogEntry(ctx context.Context, entry models.AuditLogEntry) models.AuditLogEntry
+	// GetAuditLog returns up to filter.Limit entries matching the filter, newest first
+	GetAuditLog(ctx context.Context, filter AuditLogFilter) []models.AuditLogEntry
+}
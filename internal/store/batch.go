@@ -0,0 +1,30 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxBatchRows is the most rows written by a single multi-row statement, keeping its
+// parameters well under PostgreSQL's limit of 65535
+const maxBatchRows = 1000
+
+// valuesList returns the placeholders of a multi-row VALUES list, such as
+// ($1, $2), ($3, $4) for two rows of two columns
+func valuesList(rows int, columns int) string {
+	var b strings.Builder
+	for row := range rows {
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for column := range columns {
+			if column > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", row*columns+column+1)
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
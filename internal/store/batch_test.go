@@ -0,0 +1,12 @@
+package store
+
+import "testing"
+
+func TestValuesList(t *testing.T) {
+	if got := valuesList(2, 3); got != "($1, $2, $3), ($4, $5, $6)" {
+		t.Errorf("Unexpected VALUES list %q", got)
+	}
+	if got := valuesList(1, 1); got != "($1)" {
+		t.Errorf("Unexpected VALUES list %q", got)
+	}
+}
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"container/list"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultCacheTTL is how long cached reads are served when CACHE_TTL is not set
+	defaultCacheTTL = 30 * time.Second
+	// defaultCacheSize is the number of entries kept per cache when CACHE_SIZE is not set
+	defaultCacheSize = 1000
+	// defaultCachePollTTL is how long polled reads are served when CACHE_POLL_TTL is not set
+	defaultCachePollTTL = 5 * time.Second
+)
+
+// CacheConfig configures the read-through caches placed in front of the stores
+type CacheConfig struct {
+	TTL  time.Duration
+	Size int
+	// PollTTL is how long the reads dashboards poll are served, which other processes such
+	// as the scheduler keep changing: the next scheduled items and the todo listing. Zero
+	// leaves the next items uncached and serves the todo listing for TTL.
+	PollTTL time.Duration
+}
+
+// CacheConfigFromEnv returns the cache configuration from the USE_CACHE, CACHE_TTL,
+// CACHE_SIZE and CACHE_POLL_TTL environment variables, and whether caching is enabled
+func CacheConfigFromEnv() (CacheConfig, bool) {
+	config := CacheConfig{TTL: defaultCacheTTL, Size: defaultCacheSize, PollTTL: defaultCachePollTTL}
+	if strings.ToLower(os.Getenv("USE_CACHE")) != "true" {
+		return config, false
+	}
+
+	if ttlStr := os.Getenv("CACHE_TTL"); ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err == nil && ttl > 0 {
+			config.TTL = ttl
+		} else {
+			logging.Warnf("Invalid CACHE_TTL format, using default: %v", config.TTL)
+		}
+	}
+	if sizeStr := os.Getenv("CACHE_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			config.Size = size
+		} else {
+			logging.Warnf("Invalid CACHE_SIZE, using default: %d", config.Size)
+		}
+	}
+	if pollTTLStr := os.Getenv("CACHE_POLL_TTL"); pollTTLStr != "" {
+		if pollTTL, err := time.ParseDuration(pollTTLStr); err == nil && pollTTL >= 0 {
+			config.PollTTL = pollTTL
+		} else {
+			logging.Warnf("Invalid CACHE_POLL_TTL format, using default: %v", config.PollTTL)
+		}
+	}
+	return config, true
+}
+
+// CacheStats reports how often a cache answered reads without hitting the underlying store
+type CacheStats struct {
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Entries int   `json:"entries"`
+	// HitRate is the share of reads answered from the cache, from 0 to 1
+	HitRate float64 `json:"hitRate"`
+}
+
+// withHitRate returns the statistics with the hit rate worked out from the counts
+func (s CacheStats) withHitRate() CacheStats {
+	s.HitRate = 0
+	if reads := s.Hits + s.Misses; reads > 0 {
+		s.HitRate = float64(s.Hits) / float64(reads)
+	}
+	return s
+}
+
+// lruEntry is a cached value along with its key and expiry
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// lruCache is a size-bounded, least-recently-used cache whose entries expire after a TTL
+type lruCache[K comparable, V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[K]*list.Element
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// newLRUCache creates a cache holding at most size entries for up to ttl each
+func newLRUCache[K comparable, V any](config CacheConfig) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		ttl:     config.TTL,
+		size:    config.Size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// pollConfig returns the configuration of the caches in front of polled reads, falling
+// back to the usual TTL when PollTTL is zero
+func (c CacheConfig) pollConfig() CacheConfig {
+	if c.PollTTL > 0 {
+		c.TTL = c.PollTTL
+	}
+	return c
+}
+
+// get returns the cached value for key if present and not expired
+func (c *lruCache[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		if time.Now().Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.hits.Add(1)
+			return entry.value, true
+		}
+		c.removeElement(element)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// set caches value for key, evicting the least recently used entry when full
+func (c *lruCache[K, V]) set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// delete removes key from the cache
+func (c *lruCache[K, V]) delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// deleteFunc removes every key for which match returns true
+func (c *lruCache[K, V]) deleteFunc(match func(key K) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.entries {
+		if match(key) {
+			c.removeElement(element)
+		}
+	}
+}
+
+// streamThrough streams the list cached under key or, on a miss, the list streamed by load
+// as it is read. The streamed list is cached only once it has been read to the end, so a
+// stream that fails or that the caller stops early leaves the cache as it was.
+func streamThrough[K comparable, T any](c *lruCache[K, []T], key K, load func() iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if items, ok := c.get(key); ok {
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		var items []T
+		for item, err := range load() {
+			if err != nil {
+				yield(item, err)
+				return
+			}
+			items = append(items, item)
+			if !yield(item, nil) {
+				return
+			}
+		}
+		c.set(key, items)
+	}
+}
+
+// removeElement unlinks an entry; the caller must hold the lock
+func (c *lruCache[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry[K, V]).key)
+}
+
+// stats returns the hit and miss counters along with the current number of entries
+func (c *lruCache[K, V]) stats() CacheStats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}.withHitRate()
+}
+
+// addCacheStats sums the statistics of several caches
+func addCacheStats(stats ...CacheStats) CacheStats {
+	var total CacheStats
+	for _, s := range stats {
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+		total.Entries += s.Entries
+	}
+	return total.withHitRate()
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// PostgresDeviceTokenStore provides PostgreSQL storage operations for the push tokens of users' devices
+type PostgresDeviceTokenStore struct {
+	db *sql.DB
+}
+
+// NewPostgresDeviceTokenStore creates a new PostgreSQL device token store with the given database connection
+func NewPostgresDeviceTokenStore(db *sql.DB) *PostgresDeviceTokenStore {
+	return &PostgresDeviceTokenStore{
+		db: db,
+	}
+}
+
+// RegisterDeviceToken adds a device's token for a user, or refreshes it when it is already registered
+func (s *PostgresDeviceTokenStore) RegisterDeviceToken(ctx context.Context, device models.DeviceToken) (models.DeviceToken, bool) {
+	query := `
+		INSERT INTO device_tokens (user_id, platform, token) 
+		VALUES ($1, $2, $3) 
+		ON CONFLICT (user_id, token) DO UPDATE SET
+			platform = EXCLUDED.platform,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, device.UserID, device.Platform, device.Token).Scan(&device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		logging.Errorf("Error registering device token: %v", err)
+		return models.DeviceToken{}, false
+	}
+
+	return device, true
+}
+
+// GetDeviceTokens returns the device tokens of a user in registration order
+func (s *PostgresDeviceTokenStore) GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken {
+	query := `
+		SELECT user_id, platform, token, created_at, updated_at 
+		FROM device_tokens 
+		WHERE user_id = $1 
+		ORDER BY created_at, token
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		logging.Errorf("Error querying device tokens: %v", err)
+		return []models.DeviceToken{}
+	}
+	defer rows.Close()
+
+	devices := []models.DeviceToken{}
+	for rows.Next() {
+		var device models.DeviceToken
+		if err := rows.Scan(&device.UserID, &device.Platform, &device.Token, &device.CreatedAt, &device.UpdatedAt); err != nil {
+			logging.Errorf("Error scanning device token row: %v", err)
+			continue
+		}
+		devices = append(devices, device)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating device token rows: %v", err)
+	}
+
+	return devices
+}
+
+// DeleteDeviceToken removes a device token of a user from the database
+func (s *PostgresDeviceTokenStore) DeleteDeviceToken(ctx context.Context, userID int64, token string) bool {
+	query := `DELETE FROM device_tokens WHERE user_id = $1 AND token = $2`
+	result, err := timed(s.db).ExecContext(ctx, query, userID, token)
+	if err != nil {
+		logging.Errorf("Error deleting device token: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoDeviceToken is the DynamoDB representation of a device token. Device tokens are
+// partitioned by user and keyed by the token, so a user's devices are a single Query.
+type dynamoDeviceToken struct {
+	PK        string    `dynamodbav:"pk"`
+	SK        string    `dynamodbav:"sk"`
+	UserID    int64     `dynamodbav:"user_id"`
+	Platform  string    `dynamodbav:"platform"`
+	Token     string    `dynamodbav:"token"`
+	CreatedAt time.Time `dynamodbav:"created_at"`
+	UpdatedAt time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to a device token
+func (r dynamoDeviceToken) toModel() models.DeviceToken {
+	return models.DeviceToken{
+		UserID:    r.UserID,
+		Platform:  r.Platform,
+		Token:     r.Token,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// dynamoDeviceTokenPartition returns the partition key holding the device tokens of a user
+func dynamoDeviceTokenPartition(userID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityDeviceToken, userID)
+}
+
+// DynamoDeviceTokenStore provides DynamoDB storage operations for the push tokens of users' devices
+type DynamoDeviceTokenStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoDeviceTokenStore creates a new DynamoDB device token store using the given client and table
+func NewDynamoDeviceTokenStore(client *dynamodb.Client, table string) *DynamoDeviceTokenStore {
+	return &DynamoDeviceTokenStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// RegisterDeviceToken adds a device's token for a user, or refreshes it when it is already registered
+func (s *DynamoDeviceTokenStore) RegisterDeviceToken(ctx context.Context, device models.DeviceToken) (models.DeviceToken, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":user_id":  device.UserID,
+		":platform": device.Platform,
+		":token":    device.Token,
+		":now":      time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling device token: %v", err)
+		return models.DeviceToken{}, false
+	}
+
+	// Update in place so the registration time of existing tokens is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoDeviceTokenPartition(device.UserID), device.Token),
+		UpdateExpression: aws.String("SET user_id = :user_id, platform = :platform, #token = :token, " +
+			"created_at = if_not_exists(created_at, :now), updated_at = :now"),
+		ExpressionAttributeNames:  map[string]string{"#token": "token"},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		logging.Errorf("Error registering device token: %v", err)
+		return models.DeviceToken{}, false
+	}
+
+	var record dynamoDeviceToken
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling device token: %v", err)
+		return models.DeviceToken{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetDeviceTokens returns the device tokens of a user in registration order
+func (s *DynamoDeviceTokenStore) GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoDeviceTokenPartition(userID)},
+		},
+	})
+
+	devices := []models.DeviceToken{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying device tokens: %v", err)
+			return []models.DeviceToken{}
+		}
+
+		var records []dynamoDeviceToken
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling device tokens: %v", err)
+			return []models.DeviceToken{}
+		}
+		for _, record := range records {
+			devices = append(devices, record.toModel())
+		}
+	}
+
+	// Tokens sort by value in the table
+	slices.SortStableFunc(devices, func(a, b models.DeviceToken) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return devices
+}
+
+// DeleteDeviceToken removes a device token of a user from the table
+func (s *DynamoDeviceTokenStore) DeleteDeviceToken(ctx context.Context, userID int64, token string) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoDeviceTokenPartition(userID), token),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting device token: %v", err)
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryDeviceTokenStore provides in-memory storage operations for the push tokens of users' devices
+type MemoryDeviceTokenStore struct {
+	sync.RWMutex
+	devices map[int64][]models.DeviceToken
+}
+
+// NewMemoryDeviceTokenStore creates a new in-memory device token store
+func NewMemoryDeviceTokenStore() *MemoryDeviceTokenStore {
+	return &MemoryDeviceTokenStore{
+		devices: make(map[int64][]models.DeviceToken),
+	}
+}
+
+// RegisterDeviceToken adds a device's token for a user, or refreshes it when it is already registered
+func (s *MemoryDeviceTokenStore) RegisterDeviceToken(ctx context.Context, device models.DeviceToken) (models.DeviceToken, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	device.UpdatedAt = time.Now()
+	device.CreatedAt = device.UpdatedAt
+	devices := s.devices[device.UserID]
+	for i, existing := range devices {
+		if existing.Token == device.Token {
+			device.CreatedAt = existing.CreatedAt
+			devices[i] = device
+			return device, true
+		}
+	}
+	s.devices[device.UserID] = append(devices, device)
+	return device, true
+}
+
+// GetDeviceTokens returns the device tokens of a user in registration order
+func (s *MemoryDeviceTokenStore) GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken {
+	s.RLock()
+	defer s.RUnlock()
+
+	return append([]models.DeviceToken{}, s.devices[userID]...)
+}
+
+// DeleteDeviceToken removes a device token of a user from the in-memory store
+func (s *MemoryDeviceTokenStore) DeleteDeviceToken(ctx context.Context, userID int64, token string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	devices := s.devices[userID]
+	i := slices.IndexFunc(devices, func(device models.DeviceToken) bool { return device.Token == token })
+	if i < 0 {
+		return false
+	}
+	s.devices[userID] = slices.Delete(devices, i, i+1)
+	return true
+}
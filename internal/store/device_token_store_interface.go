@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// DeviceTokenStore defines the interface for storage operations on the push tokens of users' devices
+type DeviceTokenStore interface {
+	// RegisterDeviceToken adds a device's token for a user, or refreshes it when it is already registered
+	RegisterDeviceToken(ctx context.Context, device models.DeviceToken) (models.DeviceToken, bool)
+	GetDeviceTokens(ctx context.Context, userID int64) []models.DeviceToken
+	DeleteDeviceToken(ctx context.Context, userID int64, token string) bool
+}
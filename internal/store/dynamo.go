@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// The DynamoDB stores share a single table. Every entity is keyed by its type in the
+// partition key and its zero-padded ID in the sort key, so listing an entity type is a
+// single Query in ID order; webhook deliveries are partitioned per webhook, device tokens
+// per user, organization members per organization and item shares both per item and per
+// user instead, and LLM usage and the audit log are sorted by creation time. Two sparse
+// global secondary indexes cover the remaining access patterns: due scheduled items
+// ordered by next execution time, and the execution history of a scheduled item ordered
+// by execution time.
+const (
+	dynamoPartitionKey = "pk"
+	dynamoSortKey      = "sk"
+
+	// dynamoNextExecutionIndex is keyed by pk and next_execution_at, which only scheduled items carry
+	dynamoNextExecutionIndex = "next_execution_at-index"
+	// dynamoHistoryIndex is keyed by scheduled_item_id and executed_at_id, which only execution logs carry
+	dynamoHistoryIndex = "scheduled_item_id-index"
+
+	dynamoEntityScheduledItem          = "SCHEDULED_ITEM"
+	dynamoEntityTodoItem               = "TODO_ITEM"
+	dynamoEntityUser                   = "USER"
+	dynamoEntityExecutionLog           = "EXECUTION_LOG"
+	dynamoEntityExecutionKey           = "EXECUTION_KEY"
+	dynamoEntitySchedulerHeartbeat     = "SCHEDULER_HEARTBEAT"
+	dynamoEntityWebhook                = "WEBHOOK"
+	dynamoEntityWebhookDelivery        = "WEBHOOK_DELIVERY"
+	dynamoEntityLLMUsage               = "LLM_USAGE"
+	dynamoEntityGenerationSession      = "GENERATION_SESSION"
+	dynamoEntityAuditLog               = "AUDIT_LOG"
+	dynamoEntityNotificationPreference = "NOTIFICATION_PREFERENCE"
+	dynamoEntityDeviceToken            = "DEVICE_TOKEN"
+	dynamoEntityOrganization           = "ORGANIZATION"
+	dynamoEntityOrganizationMember     = "ORGANIZATION_MEMBER"
+	dynamoEntityInvitation             = "ORGANIZATION_INVITATION"
+	dynamoEntityInvitationToken        = "INVITATION_TOKEN"
+	dynamoEntityItemShare              = "ITEM_SHARE"
+	dynamoEntityUserItemShare          = "USER_ITEM_SHARE"
+	dynamoEntityTenantLimits           = "TENANT_LIMITS"
+	dynamoEntityCounter                = "COUNTER"
+)
+
+// dynamoTableCreateTimeout is how long EnsureDynamoTable waits for a new table to become active
+const dynamoTableCreateTimeout = 2 * time.Minute
+
+// dynamoSortKeyForID formats an ID so that sort keys order numerically
+func dynamoSortKeyForID(id int64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+// dynamoTimeSortKeyFormat formats creation times so that sort keys order chronologically
+const dynamoTimeSortKeyFormat = "2006-01-02T15:04:05.000000000Z"
+
+// dynamoTimeSortKey returns the sort key prefix of records created at t, for the entities
+// sorted by creation time
+func dynamoTimeSortKey(t time.Time) string {
+	return t.UTC().Format(dynamoTimeSortKeyFormat)
+}
+
+// dynamoKey returns the primary key of an entity
+func dynamoKey(entity string, sortKey string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		dynamoPartitionKey: &types.AttributeValueMemberS{Value: entity},
+		dynamoSortKey:      &types.AttributeValueMemberS{Value: sortKey},
+	}
+}
+
+// dynamoNumber returns a number attribute value
+func dynamoNumber(value int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(value, 10)}
+}
+
+// nextDynamoID atomically increments and returns the ID counter of an entity type
+func nextDynamoID(ctx context.Context, client *dynamodb.Client, table string, entity string) (int64, error) {
+	output, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(table),
+		Key:              dynamoKey(dynamoEntityCounter, entity),
+		UpdateExpression: aws.String("ADD next_id :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	nextID, ok := output.Attributes["next_id"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("counter for %s returned no ID", entity)
+	}
+	return strconv.ParseInt(nextID.Value, 10, 64)
+}
+
+// isConditionalCheckFailed reports whether a write was rejected by its condition expression
+func isConditionalCheckFailed(err error) bool {
+	var conditionErr *types.ConditionalCheckFailedException
+	return errors.As(err, &conditionErr)
+}
+
+// EnsureDynamoTable creates the table used by the DynamoDB stores, along with its
+// indexes, if it does not exist yet
+func EnsureDynamoTable(ctx context.Context, client *dynamodb.Client, table string) error {
+	_, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err == nil {
+		return nil
+	}
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("describe table: %w", err)
+	}
+
+	_, err = client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName:   aws.String(table),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(dynamoPartitionKey), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(dynamoSortKey), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("next_execution_at"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("scheduled_item_id"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("executed_at_id"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(dynamoPartitionKey), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(dynamoSortKey), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(dynamoNextExecutionIndex),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String(dynamoPartitionKey), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("next_execution_at"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+			{
+				IndexName: aws.String(dynamoHistoryIndex),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("scheduled_item_id"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("executed_at_id"), KeyType: types.KeyTypeRange},
+				},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)}, dynamoTableCreateTimeout); err != nil {
+		return fmt.Errorf("wait for table: %w", err)
+	}
+	return nil
+}
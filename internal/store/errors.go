@@ -0,0 +1,12 @@
+package store
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the entity to update does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrVersionConflict is returned when an update was based on an outdated version of an entity
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrInvalidLimit is returned when a page is asked for with a limit below 1
+	ErrInvalidLimit = errors.New("limit must be at least 1")
+)
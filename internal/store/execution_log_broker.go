@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"sync"
+)
+
+// executionLogSubscriberBuffer is the number of entries buffered per subscriber
+// before new entries are dropped for that subscriber
+const executionLogSubscriberBuffer = 64
+
+// executionLogBroker fans newly created execution logs out to subscribers
+type executionLogBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan models.ExecutionLog]struct{}
+
+	// stopTail cancels the tail started for the current subscribers, and tailDone is
+	// closed once it has returned
+	stopTail context.CancelFunc
+	tailDone chan struct{}
+}
+
+// Subscribe registers a new subscriber and returns its channel along with a
+// function that must be called to unsubscribe and release the channel
+func (b *executionLogBroker) Subscribe() (<-chan models.ExecutionLog, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.subscribeLocked(nil)
+}
+
+// subscribeLocked registers a new subscriber with b.mu held. Unsubscribing calls
+// onLeave, if any, with b.mu held once the subscriber has been removed.
+func (b *executionLogBroker) subscribeLocked(onLeave func()) (<-chan models.ExecutionLog, func()) {
+	ch := make(chan models.ExecutionLog, executionLogSubscriberBuffer)
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan models.ExecutionLog]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			if onLeave != nil {
+				onLeave()
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// subscribeTailing registers a subscriber like Subscribe, running tail while there are
+// subscribers: it is started in its own goroutine for the first subscriber, and its
+// context is cancelled once the last one unsubscribes. A tail started again waits for the
+// previous one to return, so two never publish at the same time.
+func (b *executionLogBroker) subscribeTailing(tail func(ctx context.Context)) (<-chan models.ExecutionLog, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopTail == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		previous, done := b.tailDone, make(chan struct{})
+		b.stopTail, b.tailDone = cancel, done
+		go func() {
+			defer close(done)
+			if previous != nil {
+				<-previous
+			}
+			tail(ctx)
+		}()
+	}
+
+	return b.subscribeLocked(func() {
+		if len(b.subscribers) == 0 && b.stopTail != nil {
+			b.stopTail()
+			b.stopTail = nil
+		}
+	})
+}
+
+// publish delivers an execution log to every subscriber without blocking
+func (b *executionLogBroker) publish(logEntry models.ExecutionLog) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- logEntry:
+		default:
+			logging.Warnf("Execution log subscriber is falling behind, dropping log ID=%d", logEntry.ID)
+		}
+	}
+}
+
+// subscriberCount returns the number of active subscribers
+func (b *executionLogBroker) subscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
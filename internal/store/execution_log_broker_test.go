@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"periodic-api/internal/models"
+)
+
+func TestBrokerTailsOnlyWhileSubscribed(t *testing.T) {
+	var broker executionLogBroker
+	var starts atomic.Int64
+	var running atomic.Int32
+	stopped := make(chan struct{}, 2)
+	tail := func(ctx context.Context) {
+		starts.Add(1)
+		if running.Add(1) != 1 {
+			t.Error("Expected a single tail at a time")
+		}
+		broker.publish(models.ExecutionLog{ID: starts.Load()})
+		<-ctx.Done()
+		running.Add(-1)
+		stopped <- struct{}{}
+	}
+
+	first, unsubscribeFirst := broker.subscribeTailing(tail)
+	_, unsubscribeSecond := broker.subscribeTailing(tail)
+	select {
+	case logEntry := <-first:
+		if logEntry.ID != 1 {
+			t.Errorf("Expected the log published by the tail, got %+v", logEntry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the tail to publish")
+	}
+
+	// The tail keeps running until the last subscriber leaves
+	unsubscribeFirst()
+	select {
+	case <-stopped:
+		t.Fatal("Expected the tail to keep running while a subscriber is left")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unsubscribeSecond()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the tail to stop once nobody is subscribed")
+	}
+
+	// A new subscriber starts it again
+	again, unsubscribeAgain := broker.subscribeTailing(tail)
+	defer unsubscribeAgain()
+	select {
+	case logEntry := <-again:
+		if logEntry.ID != 2 || starts.Load() != 2 {
+			t.Errorf("Expected a second tail, got %+v after %d starts", logEntry, starts.Load())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the restarted tail to publish")
+	}
+	if count := broker.subscriberCount(); count != 1 {
+		t.Errorf("Expected 1 subscriber, got %d", count)
+	}
+}
@@ -1,86 +1,208 @@
 package store
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
+	"iter"
+	"periodic-api/internal/logging"
 	"periodic-api/internal/models"
-	"sync"
+	"slices"
 	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// executionLogPollInterval is how often the PostgreSQL store checks for new
+	// execution logs to deliver to subscribers
+	executionLogPollInterval = time.Second
+	// executionLogGapTimeout is how long the PostgreSQL store keeps looking for an
+	// execution log whose ID was skipped over, as the rows of transactions still open
+	// commit after rows with higher IDs; one still missing by then was rolled back
+	executionLogGapTimeout = time.Minute
+	// maxExecutionLogGaps caps the number of skipped IDs looked for at once
+	maxExecutionLogGaps = 1000
 )
 
 // PostgresExecutionLogStore provides PostgreSQL storage operations for execution logs
+// Its lookups and listings read from the read replica when one is set, see SetReadReplica.
 type PostgresExecutionLogStore struct {
-	sync.RWMutex
 	db *sql.DB
+	// statements holds the prepared statements of the scheduler's hot paths
+	statements *statementCache
+	broker     executionLogBroker
 }
 
 // NewPostgresExecutionLogStore creates a new PostgreSQL execution log store with the given database connection
 func NewPostgresExecutionLogStore(db *sql.DB) *PostgresExecutionLogStore {
 	return &PostgresExecutionLogStore{
-		db: db,
+		db:         db,
+		statements: newStatementCache(db),
 	}
 }
 
 // CreateExecutionLog adds a new execution log to the database
-func (s *PostgresExecutionLogStore) CreateExecutionLog(logEntry models.ExecutionLog) models.ExecutionLog {
-	s.Lock()
-	defer s.Unlock()
-
+func (s *PostgresExecutionLogStore) CreateExecutionLog(ctx context.Context, logEntry models.ExecutionLog) models.ExecutionLog {
 	// Set executed time if not provided
 	if logEntry.ExecutedAt.IsZero() {
 		logEntry.ExecutedAt = time.Now()
 	}
+	logEntry.TenantID = TenantFromContext(ctx)
 
 	query := `
 		INSERT INTO execution_logs 
-		(scheduled_item_id, executed_at, status, error_message, todo_item_id) 
-		VALUES ($1, $2, $3, $4, $5) 
+		(scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
 		RETURNING id
 	`
 
-	err := s.db.QueryRow(
+	err := s.statements.querier(ctx).QueryRowContext(
+		ctx,
 		query,
 		logEntry.ScheduledItemID,
 		logEntry.ExecutedAt,
 		logEntry.Status,
 		logEntry.ErrorMessage,
 		logEntry.TodoItemID,
+		logEntry.ExecutionKey,
+		logEntry.RequestID,
+		logEntry.TenantID,
 	).Scan(&logEntry.ID)
 
 	if err != nil {
-		log.Printf("Error creating execution log: %v", err)
+		logging.Errorf("Error creating execution log: %v", err)
 		return models.ExecutionLog{} // Return empty log on error
 	}
 
 	return logEntry
 }
 
+// CreateExecutionLogs adds the execution logs to the database with multi-row inserts and
+// returns them with their IDs, in the same order. A duplicate execution key fails the
+// whole insert. Outside a transaction, each insert of up to maxBatchRows logs commits on
+// its own.
+func (s *PostgresExecutionLogStore) CreateExecutionLogs(ctx context.Context, logEntries []models.ExecutionLog) ([]models.ExecutionLog, error) {
+	tenantID := TenantFromContext(ctx)
+	createdLogs := make([]models.ExecutionLog, 0, len(logEntries))
+	for batch := range slices.Chunk(logEntries, maxBatchRows) {
+		query := `
+			INSERT INTO execution_logs
+			(scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id)
+			VALUES ` + valuesList(len(batch), 8) + `
+			RETURNING id
+		`
+
+		args := make([]any, 0, len(batch)*8)
+		pending := make([]models.ExecutionLog, 0, len(batch))
+		for _, logEntry := range batch {
+			if logEntry.ExecutedAt.IsZero() {
+				logEntry.ExecutedAt = time.Now()
+			}
+			logEntry.TenantID = tenantID
+			args = append(args,
+				logEntry.ScheduledItemID,
+				logEntry.ExecutedAt,
+				logEntry.Status,
+				logEntry.ErrorMessage,
+				logEntry.TodoItemID,
+				logEntry.ExecutionKey,
+				logEntry.RequestID,
+				logEntry.TenantID,
+			)
+			pending = append(pending, logEntry)
+		}
+
+		rows, err := querier(ctx, s.db).QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create execution logs: %w", err)
+		}
+		ids := make([]int64, 0, len(batch))
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to create execution logs: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to create execution logs: %w", err)
+		}
+		if len(ids) != len(batch) {
+			return nil, fmt.Errorf("created %d of %d execution logs", len(ids), len(batch))
+		}
+
+		// IDs are drawn from the sequence in the order the rows are inserted
+		slices.SortFunc(ids, cmp.Compare[int64])
+		for i, logEntry := range pending {
+			logEntry.ID = ids[i]
+			createdLogs = append(createdLogs, logEntry)
+		}
+	}
+	return createdLogs, nil
+}
+
 // GetExecutionLog retrieves an execution log by ID from the database
-func (s *PostgresExecutionLogStore) GetExecutionLog(id int64) (models.ExecutionLog, bool) {
-	s.RLock()
-	defer s.RUnlock()
+func (s *PostgresExecutionLogStore) GetExecutionLog(ctx context.Context, id int64) (models.ExecutionLog, bool) {
+	var logEntry models.ExecutionLog
+	query := `
+		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+		FROM execution_logs 
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	err := readQuerier(ctx, s.db).QueryRowContext(ctx, query, id, TenantFromContext(ctx)).Scan(
+		&logEntry.ID,
+		&logEntry.ScheduledItemID,
+		&logEntry.ExecutedAt,
+		&logEntry.Status,
+		&logEntry.ErrorMessage,
+		&logEntry.TodoItemID,
+		&logEntry.ExecutionKey,
+		&logEntry.RequestID,
+		&logEntry.TenantID,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ExecutionLog{}, false
+		}
+		logging.Errorf("Error getting execution log: %v", err)
+		return models.ExecutionLog{}, false
+	}
 
+	return logEntry, true
+}
+
+// GetExecutionLogByKey retrieves the execution log recorded for an execution key
+func (s *PostgresExecutionLogStore) GetExecutionLogByKey(ctx context.Context, executionKey string) (models.ExecutionLog, bool) {
 	var logEntry models.ExecutionLog
 	query := `
-		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id 
+		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
 		FROM execution_logs 
-		WHERE id = $1
+		WHERE execution_key = $1 AND tenant_id = $2
 	`
 
-	err := s.db.QueryRow(query, id).Scan(
+	err := s.statements.querier(ctx).QueryRowContext(ctx, query, executionKey, TenantFromContext(ctx)).Scan(
 		&logEntry.ID,
 		&logEntry.ScheduledItemID,
 		&logEntry.ExecutedAt,
 		&logEntry.Status,
 		&logEntry.ErrorMessage,
 		&logEntry.TodoItemID,
+		&logEntry.ExecutionKey,
+		&logEntry.RequestID,
+		&logEntry.TenantID,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.ExecutionLog{}, false
 		}
-		log.Printf("Error getting execution log: %v", err)
+		logging.Errorf("Error getting execution log by key: %v", err)
 		return models.ExecutionLog{}, false
 	}
 
@@ -88,19 +210,17 @@ func (s *PostgresExecutionLogStore) GetExecutionLog(id int64) (models.ExecutionL
 }
 
 // GetAllExecutionLogs returns all execution logs from the database
-func (s *PostgresExecutionLogStore) GetAllExecutionLogs() []models.ExecutionLog {
-	s.RLock()
-	defer s.RUnlock()
-
+func (s *PostgresExecutionLogStore) GetAllExecutionLogs(ctx context.Context) []models.ExecutionLog {
 	query := `
-		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id 
+		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
 		FROM execution_logs
+		WHERE tenant_id = $1
 		ORDER BY executed_at DESC
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, TenantFromContext(ctx))
 	if err != nil {
-		log.Printf("Error querying execution logs: %v", err)
+		logging.Errorf("Error querying execution logs: %v", err)
 		return []models.ExecutionLog{}
 	}
 	defer rows.Close()
@@ -116,10 +236,13 @@ func (s *PostgresExecutionLogStore) GetAllExecutionLogs() []models.ExecutionLog
 			&logEntry.Status,
 			&logEntry.ErrorMessage,
 			&logEntry.TodoItemID,
+			&logEntry.ExecutionKey,
+			&logEntry.RequestID,
+			&logEntry.TenantID,
 		)
 
 		if err != nil {
-			log.Printf("Error scanning row: %v", err)
+			logging.Errorf("Error scanning row: %v", err)
 			continue
 		}
 
@@ -127,28 +250,97 @@ func (s *PostgresExecutionLogStore) GetAllExecutionLogs() []models.ExecutionLog
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
+		logging.Errorf("Error iterating rows: %v", err)
 	}
 
 	return logs
 }
 
-// GetExecutionLogsByScheduledItemID returns all execution logs for a specific scheduled item
-func (s *PostgresExecutionLogStore) GetExecutionLogsByScheduledItemID(scheduledItemID int64) []models.ExecutionLog {
-	s.RLock()
-	defer s.RUnlock()
+// StreamExecutionLogs streams the execution logs of the database, newest first, a row at
+// a time as they are read, so the history is never held in memory whole. The stream ends
+// after the first error.
+func (s *PostgresExecutionLogStore) StreamExecutionLogs(ctx context.Context) iter.Seq2[models.ExecutionLog, error] {
+	return func(yield func(models.ExecutionLog, error) bool) {
+		query := `
+			SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+			FROM execution_logs
+			WHERE tenant_id = $1
+			ORDER BY executed_at DESC
+		`
+
+		rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, TenantFromContext(ctx))
+		if err != nil {
+			yield(models.ExecutionLog{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var logEntry models.ExecutionLog
+
+			err := rows.Scan(
+				&logEntry.ID,
+				&logEntry.ScheduledItemID,
+				&logEntry.ExecutedAt,
+				&logEntry.Status,
+				&logEntry.ErrorMessage,
+				&logEntry.TodoItemID,
+				&logEntry.ExecutionKey,
+				&logEntry.RequestID,
+				&logEntry.TenantID,
+			)
+
+			if err != nil {
+				yield(models.ExecutionLog{}, err)
+				return
+			}
+
+			if !yield(logEntry, nil) {
+				return
+			}
+		}
 
-	query := `
-		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id 
-		FROM execution_logs
-		WHERE scheduled_item_id = $1
-		ORDER BY executed_at DESC
-	`
+		if err := rows.Err(); err != nil {
+			yield(models.ExecutionLog{}, err)
+		}
+	}
+}
+
+// GetExecutionLogsPage returns a page of up to limit execution logs, newest first, starting
+// after cursor, or from the most recent entry when it is nil. The
+// idx_execution_logs_tenant_keyset index lets each page seek to its cursor however deep
+// into the history it is. The returned cursor is nil when there are no further pages.
+func (s *PostgresExecutionLogStore) GetExecutionLogsPage(ctx context.Context, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	if limit < 1 {
+		return []models.ExecutionLog{}, nil, ErrInvalidLimit
+	}
 
-	rows, err := s.db.Query(query, scheduledItemID)
+	var rows *sql.Rows
+	var err error
+
+	// Fetch one extra row to find out whether another page follows
+	if cursor == nil {
+		query := `
+			SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+			FROM execution_logs
+			WHERE tenant_id = $2
+			ORDER BY executed_at DESC, id DESC
+			LIMIT $1
+		`
+		rows, err = readQuerier(ctx, s.db).QueryContext(ctx, query, limit+1, TenantFromContext(ctx))
+	} else {
+		query := `
+			SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+			FROM execution_logs
+			WHERE tenant_id = $4
+			  AND (executed_at, id) < ($1, $2)
+			ORDER BY executed_at DESC, id DESC
+			LIMIT $3
+		`
+		rows, err = readQuerier(ctx, s.db).QueryContext(ctx, query, cursor.ExecutedAt, cursor.ID, limit+1, TenantFromContext(ctx))
+	}
 	if err != nil {
-		log.Printf("Error querying execution logs by scheduled item ID: %v", err)
-		return []models.ExecutionLog{}
+		return []models.ExecutionLog{}, nil, err
 	}
 	defer rows.Close()
 
@@ -163,19 +355,203 @@ func (s *PostgresExecutionLogStore) GetExecutionLogsByScheduledItemID(scheduledI
 			&logEntry.Status,
 			&logEntry.ErrorMessage,
 			&logEntry.TodoItemID,
+			&logEntry.ExecutionKey,
+			&logEntry.RequestID,
+			&logEntry.TenantID,
 		)
 
 		if err != nil {
-			log.Printf("Error scanning row: %v", err)
-			continue
+			return []models.ExecutionLog{}, nil, err
 		}
 
 		logs = append(logs, logEntry)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
+		return []models.ExecutionLog{}, nil, err
 	}
 
-	return logs
-}
\ No newline at end of file
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+
+	logs = logs[:limit]
+	last := logs[len(logs)-1]
+	return logs, &ExecutionLogCursor{ExecutedAt: last.ExecutedAt, ID: last.ID}, nil
+}
+
+// GetExecutionLogsByScheduledItemID returns a page of execution logs for a specific scheduled item,
+// newest first. A nil cursor starts from the most recent entry. The returned cursor is nil when
+// there are no further pages. A limit below 1 is rejected with ErrInvalidLimit.
+func (s *PostgresExecutionLogStore) GetExecutionLogsByScheduledItemID(ctx context.Context, scheduledItemID int64, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	if limit < 1 {
+		return []models.ExecutionLog{}, nil, ErrInvalidLimit
+	}
+
+	var rows *sql.Rows
+	var err error
+
+	// Fetch one extra row to find out whether another page follows
+	if cursor == nil {
+		query := `
+			SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+			FROM execution_logs
+			WHERE scheduled_item_id = $1 AND tenant_id = $3
+			ORDER BY executed_at DESC, id DESC
+			LIMIT $2
+		`
+		rows, err = readQuerier(ctx, s.db).QueryContext(ctx, query, scheduledItemID, limit+1, TenantFromContext(ctx))
+	} else {
+		query := `
+			SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+			FROM execution_logs
+			WHERE scheduled_item_id = $1 AND tenant_id = $5
+			  AND (executed_at, id) < ($2, $3)
+			ORDER BY executed_at DESC, id DESC
+			LIMIT $4
+		`
+		rows, err = readQuerier(ctx, s.db).QueryContext(ctx, query, scheduledItemID, cursor.ExecutedAt, cursor.ID, limit+1, TenantFromContext(ctx))
+	}
+	if err != nil {
+		return []models.ExecutionLog{}, nil, err
+	}
+	defer rows.Close()
+
+	var logs []models.ExecutionLog
+	for rows.Next() {
+		var logEntry models.ExecutionLog
+
+		err := rows.Scan(
+			&logEntry.ID,
+			&logEntry.ScheduledItemID,
+			&logEntry.ExecutedAt,
+			&logEntry.Status,
+			&logEntry.ErrorMessage,
+			&logEntry.TodoItemID,
+			&logEntry.ExecutionKey,
+			&logEntry.RequestID,
+			&logEntry.TenantID,
+		)
+
+		if err != nil {
+			return []models.ExecutionLog{}, nil, err
+		}
+
+		logs = append(logs, logEntry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.ExecutionLog{}, nil, err
+	}
+
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+
+	logs = logs[:limit]
+	last := logs[len(logs)-1]
+	return logs, &ExecutionLogCursor{ExecutedAt: last.ExecutedAt, ID: last.ID}, nil
+}
+
+// Subscribe returns a channel that receives execution logs as they are created.
+// Logs are picked up by polling the table while there are subscribers, so entries written
+// by other processes (such as the scheduler service) are delivered as well. Logs of every
+// tenant are delivered, each with its TenantID.
+func (s *PostgresExecutionLogStore) Subscribe(_ context.Context) (<-chan models.ExecutionLog, func()) {
+	return s.broker.subscribeTailing(s.tailExecutionLogs)
+}
+
+// tailExecutionLogs polls for execution logs created after it started and publishes them
+// to subscribers until the context is cancelled. IDs are handed out when rows are
+// inserted, not when they commit, so an ID skipped over by a poll is looked for again by
+// the following polls until its row shows up or executionLogGapTimeout has passed.
+func (s *PostgresExecutionLogStore) tailExecutionLogs(ctx context.Context) {
+	var lastID int64
+	if err := timed(s.db).QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM execution_logs`).Scan(&lastID); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		logging.Errorf("Error getting latest execution log ID: %v", err)
+	}
+	gaps := make(map[int64]time.Time)
+
+	ticker := time.NewTicker(executionLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		lastID = s.publishNewExecutionLogs(ctx, lastID, gaps)
+	}
+}
+
+// publishNewExecutionLogs publishes the execution logs with IDs after lastID and those
+// whose IDs are in gaps, and returns the highest ID seen. gaps is updated with the IDs
+// skipped over, mapped to when they were first missed, and loses those found or given up on.
+func (s *PostgresExecutionLogStore) publishNewExecutionLogs(ctx context.Context, lastID int64, gaps map[int64]time.Time) int64 {
+	now := time.Now()
+	missing := make([]int64, 0, len(gaps))
+	for id, missedAt := range gaps {
+		if now.Sub(missedAt) > executionLogGapTimeout {
+			// Rolled back, or still uncommitted long after anyone would wait for it
+			delete(gaps, id)
+			continue
+		}
+		missing = append(missing, id)
+	}
+
+	query := `
+		SELECT id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id 
+		FROM execution_logs
+		WHERE id > $1 OR id = ANY($2)
+		ORDER BY id
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query, lastID, pq.Array(missing))
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.Errorf("Error querying new execution logs: %v", err)
+		}
+		return lastID
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var logEntry models.ExecutionLog
+
+		err := rows.Scan(
+			&logEntry.ID,
+			&logEntry.ScheduledItemID,
+			&logEntry.ExecutedAt,
+			&logEntry.Status,
+			&logEntry.ErrorMessage,
+			&logEntry.TodoItemID,
+			&logEntry.ExecutionKey,
+			&logEntry.RequestID,
+			&logEntry.TenantID,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		if logEntry.ID > lastID {
+			for id := lastID + 1; id < logEntry.ID && len(gaps) < maxExecutionLogGaps; id++ {
+				gaps[id] = now
+			}
+			lastID = logEntry.ID
+		} else {
+			delete(gaps, logEntry.ID)
+		}
+		s.broker.publish(logEntry)
+	}
+
+	if err = rows.Err(); err != nil && ctx.Err() == nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+	return lastID
+}
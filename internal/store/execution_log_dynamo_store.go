@@ -0,0 +1,419 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoExecutionLog is the DynamoDB representation of an execution log. ExecutedAtID
+// combines the execution time and ID so the history index sorts newest first with the
+// ID breaking ties, like the PostgreSQL history index.
+type dynamoExecutionLog struct {
+	PK              string  `dynamodbav:"pk"`
+	SK              string  `dynamodbav:"sk"`
+	ID              int64   `dynamodbav:"id"`
+	ScheduledItemID int64   `dynamodbav:"scheduled_item_id"`
+	ExecutedAt      int64   `dynamodbav:"executed_at"`
+	ExecutedAtID    string  `dynamodbav:"executed_at_id"`
+	Status          string  `dynamodbav:"status"`
+	ErrorMessage    *string `dynamodbav:"error_message,omitempty"`
+	TodoItemID      *int64  `dynamodbav:"todo_item_id,omitempty"`
+	ExecutionKey    *string `dynamodbav:"execution_key,omitempty"`
+	RequestID       string  `dynamodbav:"request_id,omitempty"`
+	TenantID        string  `dynamodbav:"tenant_id,omitempty"`
+}
+
+// dynamoExecutionKey reserves an execution key for the log that recorded it
+type dynamoExecutionKey struct {
+	PK    string `dynamodbav:"pk"`
+	SK    string `dynamodbav:"sk"`
+	LogID int64  `dynamodbav:"log_id"`
+}
+
+// dynamoHistorySortKey returns the history index sort key for a position in the history
+func dynamoHistorySortKey(executedAt time.Time, id int64) string {
+	return fmt.Sprintf("%020d#%020d", executedAt.UnixNano(), id)
+}
+
+// toModel converts the DynamoDB representation back to an execution log
+func (r dynamoExecutionLog) toModel() models.ExecutionLog {
+	log := models.ExecutionLog{
+		ID:              r.ID,
+		ScheduledItemID: r.ScheduledItemID,
+		ExecutedAt:      time.Unix(0, r.ExecutedAt),
+		Status:          r.Status,
+		ErrorMessage:    r.ErrorMessage,
+		TodoItemID:      r.TodoItemID,
+		ExecutionKey:    r.ExecutionKey,
+		RequestID:       r.RequestID,
+		TenantID:        r.TenantID,
+	}
+	if log.TenantID == "" {
+		log.TenantID = DefaultTenant
+	}
+	return log
+}
+
+// DynamoExecutionLogStore provides DynamoDB storage operations for execution logs
+type DynamoExecutionLogStore struct {
+	client *dynamodb.Client
+	table  string
+	broker executionLogBroker
+}
+
+// NewDynamoExecutionLogStore creates a new DynamoDB execution log store using the given client and table
+func NewDynamoExecutionLogStore(client *dynamodb.Client, table string) *DynamoExecutionLogStore {
+	return &DynamoExecutionLogStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateExecutionLog adds a new execution log to the table. A log with an execution key
+// is written together with a reservation of that key, so a key is only ever recorded once.
+func (s *DynamoExecutionLogStore) CreateExecutionLog(ctx context.Context, logEntry models.ExecutionLog) models.ExecutionLog {
+	// Set executed time if not provided
+	if logEntry.ExecutedAt.IsZero() {
+		logEntry.ExecutedAt = time.Now()
+	}
+	logEntry.TenantID = TenantFromContext(ctx)
+
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityExecutionLog)
+	if err != nil {
+		logging.Errorf("Error allocating execution log ID: %v", err)
+		return models.ExecutionLog{}
+	}
+	logEntry.ID = id
+
+	record, err := attributevalue.MarshalMap(dynamoExecutionLog{
+		PK:              dynamoEntityExecutionLog,
+		SK:              dynamoSortKeyForID(logEntry.ID),
+		ID:              logEntry.ID,
+		ScheduledItemID: logEntry.ScheduledItemID,
+		ExecutedAt:      logEntry.ExecutedAt.UnixNano(),
+		ExecutedAtID:    dynamoHistorySortKey(logEntry.ExecutedAt, logEntry.ID),
+		Status:          logEntry.Status,
+		ErrorMessage:    logEntry.ErrorMessage,
+		TodoItemID:      logEntry.TodoItemID,
+		ExecutionKey:    logEntry.ExecutionKey,
+		RequestID:       logEntry.RequestID,
+		TenantID:        logEntry.TenantID,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling execution log: %v", err)
+		return models.ExecutionLog{}
+	}
+
+	writes := []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:           aws.String(s.table),
+			Item:                record,
+			ConditionExpression: aws.String("attribute_not_exists(pk)"),
+		}},
+	}
+
+	if logEntry.ExecutionKey != nil {
+		keyRecord, err := attributevalue.MarshalMap(dynamoExecutionKey{
+			PK:    dynamoEntityExecutionKey,
+			SK:    *logEntry.ExecutionKey,
+			LogID: logEntry.ID,
+		})
+		if err != nil {
+			logging.Errorf("Error marshalling execution key: %v", err)
+			return models.ExecutionLog{}
+		}
+
+		writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+			TableName:           aws.String(s.table),
+			Item:                keyRecord,
+			ConditionExpression: aws.String("attribute_not_exists(pk)"),
+		}})
+	}
+
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: writes,
+	})
+	if err != nil {
+		logging.Errorf("Error creating execution log: %v", err)
+		return models.ExecutionLog{} // Return empty log on error
+	}
+
+	return logEntry
+}
+
+// CreateExecutionLogs adds the execution logs to the table one by one, each together with
+// its execution key, and returns them in the same order. Logs written before a failure
+// stay in the table.
+func (s *DynamoExecutionLogStore) CreateExecutionLogs(ctx context.Context, logEntries []models.ExecutionLog) ([]models.ExecutionLog, error) {
+	createdLogs := make([]models.ExecutionLog, 0, len(logEntries))
+	for _, logEntry := range logEntries {
+		createdLog := s.CreateExecutionLog(ctx, logEntry)
+		if createdLog.ID == 0 {
+			return nil, fmt.Errorf("failed to create execution log %d of %d", len(createdLogs)+1, len(logEntries))
+		}
+		createdLogs = append(createdLogs, createdLog)
+	}
+	return createdLogs, nil
+}
+
+// GetExecutionLog retrieves an execution log by ID from the table
+func (s *DynamoExecutionLogStore) GetExecutionLog(ctx context.Context, id int64) (models.ExecutionLog, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityExecutionLog, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting execution log: %v", err)
+		return models.ExecutionLog{}, false
+	}
+	if output.Item == nil {
+		return models.ExecutionLog{}, false
+	}
+
+	var record dynamoExecutionLog
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling execution log: %v", err)
+		return models.ExecutionLog{}, false
+	}
+	if !ownTenant(ctx, record.TenantID) {
+		return models.ExecutionLog{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetExecutionLogByKey retrieves the execution log recorded for an execution key
+func (s *DynamoExecutionLogStore) GetExecutionLogByKey(ctx context.Context, executionKey string) (models.ExecutionLog, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityExecutionKey, executionKey),
+	})
+	if err != nil {
+		logging.Errorf("Error getting execution key: %v", err)
+		return models.ExecutionLog{}, false
+	}
+	if output.Item == nil {
+		return models.ExecutionLog{}, false
+	}
+
+	var keyRecord dynamoExecutionKey
+	if err := attributevalue.UnmarshalMap(output.Item, &keyRecord); err != nil {
+		logging.Errorf("Error unmarshalling execution key: %v", err)
+		return models.ExecutionLog{}, false
+	}
+
+	return s.GetExecutionLog(ctx, keyRecord.LogID)
+}
+
+// GetAllExecutionLogs returns all execution logs of the context's tenant from the table,
+// newest first
+func (s *DynamoExecutionLogStore) GetAllExecutionLogs(ctx context.Context) []models.ExecutionLog {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityExecutionLog},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+	tenantDynamoQuery(ctx, input)
+	logs, err := s.queryExecutionLogs(ctx, input, 0)
+	if err != nil {
+		logging.Errorf("Error querying execution logs: %v", err)
+		return []models.ExecutionLog{}
+	}
+
+	return logs
+}
+
+// StreamExecutionLogs streams the execution logs of the table, newest first by ID, a query
+// page at a time, so the history is never held in memory whole. The stream ends after the
+// first error.
+func (s *DynamoExecutionLogStore) StreamExecutionLogs(ctx context.Context) iter.Seq2[models.ExecutionLog, error] {
+	return func(yield func(models.ExecutionLog, error) bool) {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: dynamoEntityExecutionLog},
+			},
+			ScanIndexForward: aws.Bool(false),
+		}
+		tenantDynamoQuery(ctx, input)
+		paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(models.ExecutionLog{}, err)
+				return
+			}
+
+			var records []dynamoExecutionLog
+			if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+				yield(models.ExecutionLog{}, err)
+				return
+			}
+			for _, record := range records {
+				if !yield(record.toModel(), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetExecutionLogsPage returns a page of up to limit execution logs, newest first, starting
+// after cursor, or from the most recent entry when it is nil. Only the history index is
+// sorted by execution time, and it is partitioned per item, so the page is taken from the
+// full listing. The returned cursor is nil when there are no further pages.
+func (s *DynamoExecutionLogStore) GetExecutionLogsPage(ctx context.Context, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	return keysetPage(s.GetAllExecutionLogs(ctx), limit, cursor, executionLogCursor, compareExecutionLogCursors)
+}
+
+// GetExecutionLogsByScheduledItemID returns a page of execution logs for a specific scheduled item,
+// newest first. A nil cursor starts from the most recent entry. The returned cursor is nil when
+// there are no further pages. A limit below 1 is rejected with ErrInvalidLimit.
+func (s *DynamoExecutionLogStore) GetExecutionLogsByScheduledItemID(ctx context.Context, scheduledItemID int64, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	if limit < 1 {
+		return []models.ExecutionLog{}, nil, ErrInvalidLimit
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		IndexName:              aws.String(dynamoHistoryIndex),
+		KeyConditionExpression: aws.String("scheduled_item_id = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id": dynamoNumber(scheduledItemID),
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	// Continue after the cursor position
+	if cursor != nil {
+		input.KeyConditionExpression = aws.String("scheduled_item_id = :id AND executed_at_id < :cursor")
+		input.ExpressionAttributeValues[":cursor"] = &types.AttributeValueMemberS{
+			Value: dynamoHistorySortKey(cursor.ExecutedAt, cursor.ID),
+		}
+	}
+	tenantDynamoQuery(ctx, input)
+
+	// Fetch one extra entry to find out whether there is a next page
+	logs, err := s.queryExecutionLogs(ctx, input, limit+1)
+	if err != nil {
+		return []models.ExecutionLog{}, nil, err
+	}
+
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+
+	logs = logs[:limit]
+	last := logs[len(logs)-1]
+	return logs, &ExecutionLogCursor{ExecutedAt: last.ExecutedAt, ID: last.ID}, nil
+}
+
+// queryExecutionLogs runs a query for execution logs, following pages until limit entries
+// were read. A limit of zero reads every page.
+func (s *DynamoExecutionLogStore) queryExecutionLogs(ctx context.Context, input *dynamodb.QueryInput, limit int) ([]models.ExecutionLog, error) {
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	var logs []models.ExecutionLog
+	for paginator.HasMorePages() && (limit == 0 || len(logs) < limit) {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var records []dynamoExecutionLog
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			logs = append(logs, record.toModel())
+		}
+	}
+
+	if limit > 0 && len(logs) > limit {
+		logs = logs[:limit]
+	}
+	return logs, nil
+}
+
+// Subscribe returns a channel that receives execution logs as they are created.
+// Logs are picked up by polling the table while there are subscribers, so entries written
+// by other processes (such as the scheduler service) are delivered as well. Logs of every
+// tenant are delivered, each with its TenantID.
+func (s *DynamoExecutionLogStore) Subscribe(_ context.Context) (<-chan models.ExecutionLog, func()) {
+	return s.broker.subscribeTailing(s.tailExecutionLogs)
+}
+
+// tailExecutionLogs polls for execution logs created after it started and publishes them
+// to subscribers until the context is cancelled
+func (s *DynamoExecutionLogStore) tailExecutionLogs(ctx context.Context) {
+	lastID, err := s.latestExecutionLogID(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		logging.Errorf("Error getting latest execution log ID: %v", err)
+	}
+
+	ticker := time.NewTicker(executionLogPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		logs, err := s.queryExecutionLogs(ctx, &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("pk = :pk AND sk > :last"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk":   &types.AttributeValueMemberS{Value: dynamoEntityExecutionLog},
+				":last": &types.AttributeValueMemberS{Value: dynamoSortKeyForID(lastID)},
+			},
+			ConsistentRead: aws.Bool(true),
+		}, 0)
+		if err != nil {
+			if ctx.Err() == nil {
+				logging.Errorf("Error querying new execution logs: %v", err)
+			}
+			continue
+		}
+
+		for _, logEntry := range logs {
+			lastID = logEntry.ID
+			s.broker.publish(logEntry)
+		}
+	}
+}
+
+// latestExecutionLogID returns the ID of the most recently created execution log, or zero if there are none
+func (s *DynamoExecutionLogStore) latestExecutionLogID(ctx context.Context) (int64, error) {
+	logs, err := s.queryExecutionLogs(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityExecutionLog},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(1),
+	}, 1)
+	if err != nil || len(logs) == 0 {
+		return 0, err
+	}
+	return logs[0].ID, nil
+}
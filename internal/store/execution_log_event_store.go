@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+)
+
+// executionStatusEvents maps execution log statuses to the events published for them
+var executionStatusEvents = map[string]string{
+	"success": events.ScheduledItemExecuted,
+	"error":   events.ScheduledItemFailed,
+	"skipped": events.ScheduledItemSkipped,
+}
+
+// PublishingExecutionLogStore publishes an event for every execution logged through another
+// execution log store. Events for logs written inside a transaction are published once it
+// commits.
+type PublishingExecutionLogStore struct {
+	ExecutionLogStore
+	bus *events.Bus
+}
+
+// NewPublishingExecutionLogStore wraps the given store so executions are published on bus
+func NewPublishingExecutionLogStore(store ExecutionLogStore, bus *events.Bus) *PublishingExecutionLogStore {
+	return &PublishingExecutionLogStore{
+		ExecutionLogStore: store,
+		bus:               bus,
+	}
+}
+
+// CreateExecutionLog stores the log and publishes a scheduled_item.executed, failed or
+// skipped event depending on its status
+func (s *PublishingExecutionLogStore) CreateExecutionLog(ctx context.Context, log models.ExecutionLog) models.ExecutionLog {
+	createdLog := s.ExecutionLogStore.CreateExecutionLog(ctx, log)
+	if eventType, ok := executionStatusEvents[createdLog.Status]; ok && createdLog.ID != 0 {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), eventType, createdLog) })
+	}
+	return createdLog
+}
+
+// CreateExecutionLogs stores the logs and publishes an event for each, like CreateExecutionLog
+func (s *PublishingExecutionLogStore) CreateExecutionLogs(ctx context.Context, logs []models.ExecutionLog) ([]models.ExecutionLog, error) {
+	createdLogs, err := s.ExecutionLogStore.CreateExecutionLogs(ctx, logs)
+	for _, createdLog := range createdLogs {
+		if eventType, ok := executionStatusEvents[createdLog.Status]; ok {
+			AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), eventType, createdLog) })
+		}
+	}
+	return createdLogs, err
+}
@@ -1,7 +1,11 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"iter"
 	"periodic-api/internal/models"
+	"sort"
 	"sync"
 	"time"
 )
@@ -11,6 +15,7 @@ type MemoryExecutionLogStore struct {
 	sync.RWMutex
 	logs   map[int64]models.ExecutionLog
 	nextID int64
+	broker executionLogBroker
 }
 
 // NewMemoryExecutionLogStore creates a new in-memory execution log store
@@ -22,10 +27,17 @@ func NewMemoryExecutionLogStore() *MemoryExecutionLogStore {
 }
 
 // CreateExecutionLog adds a new execution log to the in-memory store
-func (s *MemoryExecutionLogStore) CreateExecutionLog(log models.ExecutionLog) models.ExecutionLog {
+func (s *MemoryExecutionLogStore) CreateExecutionLog(ctx context.Context, log models.ExecutionLog) models.ExecutionLog {
 	s.Lock()
 	defer s.Unlock()
 
+	// Enforce unique execution keys like the database constraint does
+	if log.ExecutionKey != nil {
+		if _, exists := s.findByKey(*log.ExecutionKey); exists {
+			return models.ExecutionLog{}
+		}
+	}
+
 	// Assign a new ID and set executed time if not provided
 	log.ID = s.nextID
 	s.nextID++
@@ -33,43 +45,157 @@ func (s *MemoryExecutionLogStore) CreateExecutionLog(log models.ExecutionLog) mo
 	if log.ExecutedAt.IsZero() {
 		log.ExecutedAt = time.Now()
 	}
+	log.TenantID = TenantFromContext(ctx)
 
-	// Store the log
+	// Store the log and notify subscribers
 	s.logs[log.ID] = log
+	s.broker.publish(log)
 	return log
 }
 
+// CreateExecutionLogs adds the execution logs to the in-memory store and returns them
+// with their IDs, in the same order. Like the database, it adds none of them when an
+// execution key is already recorded or repeated within the logs.
+func (s *MemoryExecutionLogStore) CreateExecutionLogs(ctx context.Context, logs []models.ExecutionLog) ([]models.ExecutionLog, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	keys := make(map[string]bool, len(logs))
+	for _, log := range logs {
+		if log.ExecutionKey == nil {
+			continue
+		}
+		if _, exists := s.findByKey(*log.ExecutionKey); exists || keys[*log.ExecutionKey] {
+			return nil, fmt.Errorf("execution key %s is already recorded", *log.ExecutionKey)
+		}
+		keys[*log.ExecutionKey] = true
+	}
+
+	createdLogs := make([]models.ExecutionLog, 0, len(logs))
+	for _, log := range logs {
+		log.ID = s.nextID
+		s.nextID++
+		if log.ExecutedAt.IsZero() {
+			log.ExecutedAt = time.Now()
+		}
+		log.TenantID = TenantFromContext(ctx)
+
+		s.logs[log.ID] = log
+		s.broker.publish(log)
+		createdLogs = append(createdLogs, log)
+	}
+	return createdLogs, nil
+}
+
 // GetExecutionLog retrieves an execution log by ID from the in-memory store
-func (s *MemoryExecutionLogStore) GetExecutionLog(id int64) (models.ExecutionLog, bool) {
+func (s *MemoryExecutionLogStore) GetExecutionLog(ctx context.Context, id int64) (models.ExecutionLog, bool) {
 	s.RLock()
 	defer s.RUnlock()
 
 	log, exists := s.logs[id]
-	return log, exists
+	if !exists || !ownTenant(ctx, log.TenantID) {
+		return models.ExecutionLog{}, false
+	}
+	return log, true
+}
+
+// GetExecutionLogByKey retrieves the execution log recorded for an execution key
+func (s *MemoryExecutionLogStore) GetExecutionLogByKey(ctx context.Context, executionKey string) (models.ExecutionLog, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	log, exists := s.findByKey(executionKey)
+	if !exists || !ownTenant(ctx, log.TenantID) {
+		return models.ExecutionLog{}, false
+	}
+	return log, true
+}
+
+// findByKey looks up a log by execution key; callers must hold the lock
+func (s *MemoryExecutionLogStore) findByKey(executionKey string) (models.ExecutionLog, bool) {
+	for _, log := range s.logs {
+		if log.ExecutionKey != nil && *log.ExecutionKey == executionKey {
+			return log, true
+		}
+	}
+	return models.ExecutionLog{}, false
 }
 
 // GetAllExecutionLogs returns all execution logs from the in-memory store
-func (s *MemoryExecutionLogStore) GetAllExecutionLogs() []models.ExecutionLog {
+func (s *MemoryExecutionLogStore) GetAllExecutionLogs(ctx context.Context) []models.ExecutionLog {
 	s.RLock()
 	defer s.RUnlock()
 
 	logs := make([]models.ExecutionLog, 0, len(s.logs))
 	for _, log := range s.logs {
-		logs = append(logs, log)
+		if ownTenant(ctx, log.TenantID) {
+			logs = append(logs, log)
+		}
 	}
 	return logs
 }
 
-// GetExecutionLogsByScheduledItemID returns all execution logs for a specific scheduled item
-func (s *MemoryExecutionLogStore) GetExecutionLogsByScheduledItemID(scheduledItemID int64) []models.ExecutionLog {
+// StreamExecutionLogs streams a snapshot of the in-memory execution logs
+func (s *MemoryExecutionLogStore) StreamExecutionLogs(ctx context.Context) iter.Seq2[models.ExecutionLog, error] {
+	return sliceStream(s.GetAllExecutionLogs(ctx))
+}
+
+// GetExecutionLogsPage returns a page of up to limit execution logs, newest first, starting
+// after cursor, or from the most recent entry when it is nil. The returned cursor is nil
+// when there are no further pages.
+func (s *MemoryExecutionLogStore) GetExecutionLogsPage(ctx context.Context, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	return keysetPage(s.GetAllExecutionLogs(ctx), limit, cursor, executionLogCursor, compareExecutionLogCursors)
+}
+
+// GetExecutionLogsByScheduledItemID returns a page of execution logs for a specific scheduled item,
+// newest first. A nil cursor starts from the most recent entry. The returned cursor is nil when
+// there are no further pages. A limit below 1 is rejected with ErrInvalidLimit.
+func (s *MemoryExecutionLogStore) GetExecutionLogsByScheduledItemID(ctx context.Context, scheduledItemID int64, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	if limit < 1 {
+		return []models.ExecutionLog{}, nil, ErrInvalidLimit
+	}
+
 	s.RLock()
 	defer s.RUnlock()
 
 	var logs []models.ExecutionLog
 	for _, log := range s.logs {
-		if log.ScheduledItemID == scheduledItemID {
-			logs = append(logs, log)
+		if log.ScheduledItemID != scheduledItemID || !ownTenant(ctx, log.TenantID) {
+			continue
+		}
+
+		// Skip entries at or before the cursor position
+		if cursor != nil && !executionLogOlderThan(log, cursor.ExecutedAt, cursor.ID) {
+			continue
 		}
+
+		logs = append(logs, log)
 	}
-	return logs
-}
\ No newline at end of file
+
+	// Sort newest first, using the ID to break ties
+	sort.Slice(logs, func(i, j int) bool {
+		return executionLogOlderThan(logs[j], logs[i].ExecutedAt, logs[i].ID)
+	})
+
+	if len(logs) <= limit {
+		return logs, nil, nil
+	}
+
+	logs = logs[:limit]
+	last := logs[len(logs)-1]
+	return logs, &ExecutionLogCursor{ExecutedAt: last.ExecutedAt, ID: last.ID}, nil
+}
+
+// executionLogOlderThan reports whether a log comes after the given position in newest-first order
+func executionLogOlderThan(log models.ExecutionLog, executedAt time.Time, id int64) bool {
+	if log.ExecutedAt.Equal(executedAt) {
+		return log.ID < id
+	}
+	return log.ExecutedAt.Before(executedAt)
+}
+
+// Subscribe returns a channel that receives the execution logs of every tenant as they
+// are created
+func (s *MemoryExecutionLogStore) Subscribe(_ context.Context) (<-chan models.ExecutionLog, func()) {
+	return s.broker.Subscribe()
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"periodic-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestMemoryExecutionLogStoreSubscribe(t *testing.T) {
+	store := NewMemoryExecutionLogStore()
+
+	logs, unsubscribe := store.Subscribe(t.Context())
+
+	created := store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: 1,
+		Status:          "success",
+	})
+
+	select {
+	case received := <-logs:
+		if received.ID != created.ID {
+			t.Errorf("Expected log ID %d, got %d", created.ID, received.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for execution log")
+	}
+
+	unsubscribe()
+
+	// Channel should be closed after unsubscribing
+	if _, ok := <-logs; ok {
+		t.Error("Expected channel to be closed after unsubscribe")
+	}
+
+	// Creating logs without subscribers should not block
+	store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: 1,
+		Status:          "success",
+	})
+
+	// Calling unsubscribe twice should be safe
+	unsubscribe()
+}
+
+func TestMemoryExecutionLogStoreSlowSubscriber(t *testing.T) {
+	store := NewMemoryExecutionLogStore()
+
+	_, unsubscribe := store.Subscribe(t.Context())
+	defer unsubscribe()
+
+	// A subscriber that never reads must not block log creation
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < executionLogSubscriberBuffer*2; i++ {
+			store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+				ScheduledItemID: 1,
+				Status:          "success",
+			})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Creating execution logs blocked on a slow subscriber")
+	}
+}
+
+func TestMemoryExecutionLogStoreGetByScheduledItemIDPagination(t *testing.T) {
+	store := NewMemoryExecutionLogStore()
+	base := time.Now().Add(-time.Hour)
+
+	// Five logs for item 1 (two sharing a timestamp) and one for item 2
+	for i := 0; i < 4; i++ {
+		store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+			ScheduledItemID: 1,
+			ExecutedAt:      base.Add(time.Duration(i) * time.Minute),
+			Status:          "success",
+		})
+	}
+	store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: 1,
+		ExecutedAt:      base.Add(3 * time.Minute),
+		Status:          "success",
+	})
+	store.CreateExecutionLog(context.Background(), models.ExecutionLog{
+		ScheduledItemID: 2,
+		ExecutedAt:      base,
+		Status:          "success",
+	})
+
+	var seen []int64
+	var cursor *ExecutionLogCursor
+	for page := 0; page < 5; page++ {
+		logs, next, err := store.GetExecutionLogsByScheduledItemID(context.Background(), 1, 2, cursor)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, log := range logs {
+			seen = append(seen, log.ID)
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	// Newest first, with the higher ID first for equal timestamps
+	expected := []int64{5, 4, 3, 2, 1}
+	if len(seen) != len(expected) {
+		t.Fatalf("Expected %d logs, got %d: %v", len(expected), len(seen), seen)
+	}
+	for i := range expected {
+		if seen[i] != expected[i] {
+			t.Errorf("Expected log ID %d at position %d, got %d", expected[i], i, seen[i])
+		}
+	}
+}
+
+func TestMemoryExecutionLogStoreGetByScheduledItemIDRejectsInvalidLimits(t *testing.T) {
+	store := NewMemoryExecutionLogStore()
+	store.CreateExecutionLog(context.Background(), models.ExecutionLog{ScheduledItemID: 1, Status: "success"})
+
+	for _, limit := range []int{0, -1} {
+		logs, next, err := store.GetExecutionLogsByScheduledItemID(context.Background(), 1, limit, nil)
+		if !errors.Is(err, ErrInvalidLimit) || len(logs) != 0 || next != nil {
+			t.Errorf("Expected limit %d to be rejected, got %d logs, cursor %v and error %v", limit, len(logs), next, err)
+		}
+	}
+}
+
+func TestMemoryExecutionLogStoreCreateExecutionLogs(t *testing.T) {
+	store := NewMemoryExecutionLogStore()
+	first, second := "1:a", "2:a"
+
+	created, err := store.CreateExecutionLogs(context.Background(), []models.ExecutionLog{
+		{ScheduledItemID: 1, Status: "success", ExecutionKey: &first},
+		{ScheduledItemID: 2, Status: "success", ExecutionKey: &second},
+	})
+	if err != nil {
+		t.Fatalf("CreateExecutionLogs failed: %v", err)
+	}
+	if len(created) != 2 || created[0].ScheduledItemID != 1 || created[1].ScheduledItemID != 2 || created[0].ID == created[1].ID {
+		t.Fatalf("Expected both logs in order with their own IDs, got %+v", created)
+	}
+
+	// A recorded key fails the whole batch
+	third := "3:a"
+	if _, err := store.CreateExecutionLogs(context.Background(), []models.ExecutionLog{
+		{ScheduledItemID: 3, Status: "success", ExecutionKey: &third},
+		{ScheduledItemID: 1, Status: "success", ExecutionKey: &first},
+	}); err == nil {
+		t.Fatal("Expected a recorded execution key to be refused")
+	}
+	if _, exists := store.GetExecutionLogByKey(context.Background(), third); exists {
+		t.Error("Expected no log of a refused batch to be added")
+	}
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+)
+
+// ScopedExecutionLogStore confines the execution logs read through another store to
+// those of the items the context's user may see through items. The logs of deleted
+// items, whose owner is no longer known, stay visible.
+type ScopedExecutionLogStore struct {
+	ExecutionLogStore
+	items *ScopedScheduledItemStore
+}
+
+// NewScopedExecutionLogStore wraps the given store so it only sees the logs of the items
+// visible through items
+func NewScopedExecutionLogStore(store ExecutionLogStore, items *ScopedScheduledItemStore) *ScopedExecutionLogStore {
+	return &ScopedExecutionLogStore{
+		ExecutionLogStore: store,
+		items:             items,
+	}
+}
+
+// GetExecutionLog returns the log when the context's user may see its item
+func (s *ScopedExecutionLogStore) GetExecutionLog(ctx context.Context, id int64) (models.ExecutionLog, bool) {
+	log, exists := s.ExecutionLogStore.GetExecutionLog(ctx, id)
+	if !exists || s.items.hides(ctx, log.ScheduledItemID) {
+		return models.ExecutionLog{}, false
+	}
+	return log, true
+}
+
+// GetAllExecutionLogs returns the logs of the items the context's user may see
+func (s *ScopedExecutionLogStore) GetAllExecutionLogs(ctx context.Context) []models.ExecutionLog {
+	logs := s.ExecutionLogStore.GetAllExecutionLogs(ctx)
+	hides := s.items.hider(ctx)
+	return slices.DeleteFunc(logs, func(log models.ExecutionLog) bool {
+		return hides(log.ScheduledItemID)
+	})
+}
+
+// StreamExecutionLogs streams the logs of the items the context's user may see
+func (s *ScopedExecutionLogStore) StreamExecutionLogs(ctx context.Context) iter.Seq2[models.ExecutionLog, error] {
+	return func(yield func(models.ExecutionLog, error) bool) {
+		hides := s.items.hider(ctx)
+		visible := func(log models.ExecutionLog) bool { return !hides(log.ScheduledItemID) }
+		for log, err := range filterStream(s.ExecutionLogStore.StreamExecutionLogs(ctx), visible) {
+			if !yield(log, err) {
+				return
+			}
+		}
+	}
+}
+
+// GetExecutionLogsPage returns the logs of a page whose items the context's user may see,
+// so pages can come back shorter than limit. The cursor still marks the end of the page
+// as read, so no log is skipped or repeated.
+func (s *ScopedExecutionLogStore) GetExecutionLogsPage(ctx context.Context, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	logs, next, err := s.ExecutionLogStore.GetExecutionLogsPage(ctx, limit, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	hides := s.items.hider(ctx)
+	return slices.DeleteFunc(logs, func(log models.ExecutionLog) bool {
+		return hides(log.ScheduledItemID)
+	}), next, nil
+}
+
+// GetExecutionLogsByScheduledItemID returns the logs of an item when the context's user may see it
+func (s *ScopedExecutionLogStore) GetExecutionLogsByScheduledItemID(ctx context.Context, scheduledItemID int64, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error) {
+	if s.items.hides(ctx, scheduledItemID) {
+		return []models.ExecutionLog{}, nil, nil
+	}
+	return s.ExecutionLogStore.GetExecutionLogsByScheduledItemID(ctx, scheduledItemID, limit, cursor)
+}
+
+// Subscribe returns a channel that receives the new logs of the context's tenant whose
+// items its user may see, checking each item as its log arrives. Contexts without a user
+// receive the logs of every tenant, like the wrapped store's subscribers.
+func (s *ScopedExecutionLogStore) Subscribe(ctx context.Context) (<-chan models.ExecutionLog, func()) {
+	logs, unsubscribe := s.ExecutionLogStore.Subscribe(ctx)
+	if _, scoped := UserFromContext(ctx); !scoped {
+		return logs, unsubscribe
+	}
+
+	visible := make(chan models.ExecutionLog, executionLogSubscriberBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(visible)
+		for log := range logs {
+			if !ownTenant(ctx, log.TenantID) || s.items.hides(ctx, log.ScheduledItemID) {
+				continue
+			}
+			select {
+			case visible <- log:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return visible, func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+		})
+	}
+}
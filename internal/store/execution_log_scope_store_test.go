@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"testing"
+)
+
+// countingItemStore counts the item lookups made through it
+type countingItemStore struct {
+	ScheduledItemStore
+	lookups  int
+	listings int
+}
+
+func (s *countingItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
+	s.lookups++
+	return s.ScheduledItemStore.GetScheduledItem(ctx, id)
+}
+
+func (s *countingItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
+	s.listings++
+	return s.ScheduledItemStore.GetAllScheduledItems(ctx)
+}
+
+func TestScopedExecutionLogPagesOnlyLookUpTheirItems(t *testing.T) {
+	ctx := context.Background()
+	alice, bob := int64(1), int64(2)
+	items := &countingItemStore{ScheduledItemStore: NewMemoryScheduledItemStore()}
+	logs := NewMemoryExecutionLogStore()
+	var ids []int64
+	for _, owner := range []*int64{&alice, &bob, &alice, nil} {
+		item := items.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Item", OwnerID: owner})
+		ids = append(ids, item.ID)
+		for range 3 {
+			logs.CreateExecutionLog(ctx, models.ExecutionLog{ScheduledItemID: item.ID, Status: "success"})
+		}
+	}
+	scoped := NewScopedExecutionLogStore(logs, NewScopedScheduledItemStore(items, NewMemoryItemShareStore()))
+
+	// The newest page holds the logs of the last two items, of which bob may see one
+	page, next, err := scoped.GetExecutionLogsPage(WithUser(ctx, bob), 6, nil)
+	if err != nil {
+		t.Fatalf("GetExecutionLogsPage failed: %v", err)
+	}
+	if len(page) != 3 || page[0].ScheduledItemID != ids[3] || next == nil {
+		t.Errorf("Expected the page to hold the 3 logs of the item without an owner, got %+v", page)
+	}
+	if items.listings != 0 || items.lookups != 2 {
+		t.Errorf("Expected the page to look up its 2 items once each, got %d lookups and %d listings", items.lookups, items.listings)
+	}
+
+	items.lookups = 0
+	if all := scoped.GetAllExecutionLogs(WithUser(ctx, bob)); len(all) != 6 {
+		t.Errorf("Expected bob to see the 6 logs of his item and the one without an owner, got %d", len(all))
+	}
+	if items.listings != 0 || items.lookups != len(ids) {
+		t.Errorf("Expected each item to be looked up once, got %d lookups and %d listings", items.lookups, items.listings)
+	}
+}
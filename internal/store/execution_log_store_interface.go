@@ -1,13 +1,28 @@
 package store
 
 import (
+	"context"
+	"iter"
 	"periodic-api/internal/models"
+	"time"
 )
 
+// ExecutionLogCursor marks the last execution log of a page; the next page
+// starts with the entries that come after it in newest-first order
+type ExecutionLogCursor struct {
+	ExecutedAt time.Time
+	ID         int64
+}
+
 // ExecutionLogStore defines the interface for execution log storage operations
 type ExecutionLogStore interface {
-	CreateExecutionLog(log models.ExecutionLog) models.ExecutionLog
-	GetExecutionLog(id int64) (models.ExecutionLog, bool)
-	GetAllExecutionLogs() []models.ExecutionLog
-	GetExecutionLogsByScheduledItemID(scheduledItemID int64) []models.ExecutionLog
+	CreateExecutionLog(ctx context.Context, log models.ExecutionLog) models.ExecutionLog
+	CreateExecutionLogs(ctx context.Context, logs []models.ExecutionLog) ([]models.ExecutionLog, error)
+	GetExecutionLog(ctx context.Context, id int64) (models.ExecutionLog, bool)
+	GetExecutionLogByKey(ctx context.Context, executionKey string) (models.ExecutionLog, bool)
+	GetAllExecutionLogs(ctx context.Context) []models.ExecutionLog
+	StreamExecutionLogs(ctx context.Context) iter.Seq2[models.ExecutionLog, error]
+	GetExecutionLogsPage(ctx context.Context, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error)
+	GetExecutionLogsByScheduledItemID(ctx context.Context, scheduledItemID int64, limit int, cursor *ExecutionLogCursor) ([]models.ExecutionLog, *ExecutionLogCursor, error)
+	Subscribe(ctx context.Context) (<-chan models.ExecutionLog, func())
 }
\ No newline at end of file
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// PostgresGenerationSessionStore provides PostgreSQL storage operations for generation sessions
+type PostgresGenerationSessionStore struct {
+	db *sql.DB
+}
+
+// NewPostgresGenerationSessionStore creates a new PostgreSQL generation session store with the given database connection
+func NewPostgresGenerationSessionStore(db *sql.DB) *PostgresGenerationSessionStore {
+	return &PostgresGenerationSessionStore{
+		db: db,
+	}
+}
+
+// CreateGenerationSession adds a new session to the database, removing expired ones
+func (s *PostgresGenerationSessionStore) CreateGenerationSession(ctx context.Context, session models.GenerationSession) models.GenerationSession {
+	if _, err := querier(ctx, s.db).ExecContext(ctx, `DELETE FROM generation_sessions WHERE expires_at <= NOW()`); err != nil {
+		logging.Errorf("Error removing expired generation sessions: %v", err)
+	}
+
+	messages, item, err := marshalGenerationSession(session)
+	if err != nil {
+		logging.Errorf("Error marshalling generation session: %v", err)
+		return models.GenerationSession{}
+	}
+
+	query := `
+		INSERT INTO generation_sessions
+		(user_id, timezone, messages, item, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		session.UserID,
+		session.Timezone,
+		messages,
+		item,
+		session.ExpiresAt,
+	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+
+	if err != nil {
+		logging.Errorf("Error creating generation session: %v", err)
+		return models.GenerationSession{} // Return empty session on error
+	}
+
+	return session
+}
+
+// GetGenerationSession retrieves a session by ID from the database
+func (s *PostgresGenerationSessionStore) GetGenerationSession(ctx context.Context, id int64) (models.GenerationSession, bool) {
+	var session models.GenerationSession
+	var messages, item []byte
+	query := `
+		SELECT id, user_id, timezone, messages, item, created_at, updated_at, expires_at
+		FROM generation_sessions
+		WHERE id = $1
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Timezone,
+		&messages,
+		&item,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+		&session.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.GenerationSession{}, false
+		}
+		logging.Errorf("Error getting generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+
+	if err := json.Unmarshal(messages, &session.Messages); err != nil {
+		logging.Errorf("Error unmarshalling generation session messages: %v", err)
+		return models.GenerationSession{}, false
+	}
+	if err := json.Unmarshal(item, &session.Item); err != nil {
+		logging.Errorf("Error unmarshalling generation session item: %v", err)
+		return models.GenerationSession{}, false
+	}
+
+	return session, true
+}
+
+// UpdateGenerationSession updates an existing session in the database
+func (s *PostgresGenerationSessionStore) UpdateGenerationSession(ctx context.Context, id int64, updatedSession models.GenerationSession) (models.GenerationSession, bool) {
+	messages, item, err := marshalGenerationSession(updatedSession)
+	if err != nil {
+		logging.Errorf("Error marshalling generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+
+	query := `
+		UPDATE generation_sessions
+		SET messages = $1, item = $2, expires_at = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING user_id, timezone, created_at, updated_at
+	`
+
+	err = querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		messages,
+		item,
+		updatedSession.ExpiresAt,
+		id,
+	).Scan(&updatedSession.UserID, &updatedSession.Timezone, &updatedSession.CreatedAt, &updatedSession.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.GenerationSession{}, false
+		}
+		logging.Errorf("Error updating generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+
+	updatedSession.ID = id
+	return updatedSession, true
+}
+
+// DeleteGenerationSession removes a session from the database
+func (s *PostgresGenerationSessionStore) DeleteGenerationSession(ctx context.Context, id int64) bool {
+	query := `DELETE FROM generation_sessions WHERE id = $1`
+	result, err := querier(ctx, s.db).ExecContext(ctx, query, id)
+	if err != nil {
+		logging.Errorf("Error deleting generation session: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
+
+// marshalGenerationSession encodes the messages and item of a session for their JSONB columns
+func marshalGenerationSession(session models.GenerationSession) ([]byte, []byte, error) {
+	messages, err := json.Marshal(session.Messages)
+	if err != nil {
+		return nil, nil, err
+	}
+	item, err := json.Marshal(session.Item)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messages, item, nil
+}
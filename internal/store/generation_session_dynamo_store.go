@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoGenerationSession is the DynamoDB representation of a generation session. The
+// messages and item are stored as the same JSON documents as in PostgreSQL.
+type dynamoGenerationSession struct {
+	PK        string    `dynamodbav:"pk"`
+	SK        string    `dynamodbav:"sk"`
+	ID        int64     `dynamodbav:"id"`
+	UserID    string    `dynamodbav:"user_id"`
+	Timezone  string    `dynamodbav:"timezone"`
+	Messages  string    `dynamodbav:"messages"`
+	Item      string    `dynamodbav:"item"`
+	CreatedAt time.Time `dynamodbav:"created_at"`
+	UpdatedAt time.Time `dynamodbav:"updated_at"`
+	ExpiresAt time.Time `dynamodbav:"expires_at"`
+}
+
+// toModel converts the DynamoDB representation back to a generation session
+func (r dynamoGenerationSession) toModel() (models.GenerationSession, error) {
+	session := models.GenerationSession{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		Timezone:  r.Timezone,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		ExpiresAt: r.ExpiresAt,
+	}
+	if err := json.Unmarshal([]byte(r.Messages), &session.Messages); err != nil {
+		return models.GenerationSession{}, err
+	}
+	if err := json.Unmarshal([]byte(r.Item), &session.Item); err != nil {
+		return models.GenerationSession{}, err
+	}
+	return session, nil
+}
+
+// DynamoGenerationSessionStore provides DynamoDB storage operations for generation sessions
+type DynamoGenerationSessionStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoGenerationSessionStore creates a new DynamoDB generation session store using the given client and table
+func NewDynamoGenerationSessionStore(client *dynamodb.Client, table string) *DynamoGenerationSessionStore {
+	return &DynamoGenerationSessionStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateGenerationSession adds a new session to the table, removing expired ones
+func (s *DynamoGenerationSessionStore) CreateGenerationSession(ctx context.Context, session models.GenerationSession) models.GenerationSession {
+	s.deleteExpiredSessions(ctx)
+
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityGenerationSession)
+	if err != nil {
+		logging.Errorf("Error allocating generation session ID: %v", err)
+		return models.GenerationSession{}
+	}
+	session.ID = id
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+
+	record, err := marshalDynamoGenerationSession(session)
+	if err != nil {
+		logging.Errorf("Error marshalling generation session: %v", err)
+		return models.GenerationSession{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		logging.Errorf("Error creating generation session: %v", err)
+		return models.GenerationSession{} // Return empty session on error
+	}
+
+	return session
+}
+
+// GetGenerationSession retrieves a session by ID from the table
+func (s *DynamoGenerationSessionStore) GetGenerationSession(ctx context.Context, id int64) (models.GenerationSession, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityGenerationSession, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+	if output.Item == nil {
+		return models.GenerationSession{}, false
+	}
+
+	return unmarshalDynamoGenerationSession(output.Item)
+}
+
+// UpdateGenerationSession updates an existing session in the table
+func (s *DynamoGenerationSessionStore) UpdateGenerationSession(ctx context.Context, id int64, updatedSession models.GenerationSession) (models.GenerationSession, bool) {
+	messages, item, err := marshalGenerationSession(updatedSession)
+	if err != nil {
+		logging.Errorf("Error marshalling generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":messages":   string(messages),
+		":item":       string(item),
+		":expires_at": updatedSession.ExpiresAt,
+		":updated_at": time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+
+	// Update in place so the owner and creation time are kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityGenerationSession, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET messages = :messages, #item = :item, expires_at = :expires_at, updated_at = :updated_at"),
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+		ExpressionAttributeNames:  map[string]string{"#item": "item"},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating generation session: %v", err)
+		}
+		return models.GenerationSession{}, false
+	}
+
+	return unmarshalDynamoGenerationSession(output.Attributes)
+}
+
+// DeleteGenerationSession removes a session from the table
+func (s *DynamoGenerationSessionStore) DeleteGenerationSession(ctx context.Context, id int64) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoEntityGenerationSession, dynamoSortKeyForID(id)),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting generation session: %v", err)
+		return false
+	}
+	return len(output.Attributes) > 0
+}
+
+// deleteExpiredSessions removes sessions past their expiry. It is best effort; sessions
+// left behind are still reported as expired when read.
+func (s *DynamoGenerationSessionStore) deleteExpiredSessions(ctx context.Context) {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityGenerationSession},
+		},
+		ProjectionExpression: aws.String("sk, expires_at"),
+	})
+
+	now := time.Now()
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying generation sessions: %v", err)
+			return
+		}
+
+		var records []dynamoGenerationSession
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling generation sessions: %v", err)
+			return
+		}
+		for _, record := range records {
+			if record.ExpiresAt.After(now) {
+				continue
+			}
+			_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: aws.String(s.table),
+				Key:       dynamoKey(dynamoEntityGenerationSession, record.SK),
+			})
+			if err != nil {
+				logging.Errorf("Error deleting expired generation session: %v", err)
+			}
+		}
+	}
+}
+
+// marshalDynamoGenerationSession converts a session to its DynamoDB representation
+func marshalDynamoGenerationSession(session models.GenerationSession) (map[string]types.AttributeValue, error) {
+	messages, item, err := marshalGenerationSession(session)
+	if err != nil {
+		return nil, err
+	}
+	return attributevalue.MarshalMap(dynamoGenerationSession{
+		PK:        dynamoEntityGenerationSession,
+		SK:        dynamoSortKeyForID(session.ID),
+		ID:        session.ID,
+		UserID:    session.UserID,
+		Timezone:  session.Timezone,
+		Messages:  string(messages),
+		Item:      string(item),
+		CreatedAt: session.CreatedAt,
+		UpdatedAt: session.UpdatedAt,
+		ExpiresAt: session.ExpiresAt,
+	})
+}
+
+// unmarshalDynamoGenerationSession converts a DynamoDB item back to a session
+func unmarshalDynamoGenerationSession(item map[string]types.AttributeValue) (models.GenerationSession, bool) {
+	var record dynamoGenerationSession
+	if err := attributevalue.UnmarshalMap(item, &record); err != nil {
+		logging.Errorf("Error unmarshalling generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+	session, err := record.toModel()
+	if err != nil {
+		logging.Errorf("Error unmarshalling generation session: %v", err)
+		return models.GenerationSession{}, false
+	}
+	return session, true
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryGenerationSessionStore provides in-memory storage operations for generation sessions
+type MemoryGenerationSessionStore struct {
+	sync.RWMutex
+	sessions map[int64]models.GenerationSession
+	nextID   int64
+}
+
+// NewMemoryGenerationSessionStore creates a new in-memory generation session store
+func NewMemoryGenerationSessionStore() *MemoryGenerationSessionStore {
+	return &MemoryGenerationSessionStore{
+		sessions: make(map[int64]models.GenerationSession),
+		nextID:   1,
+	}
+}
+
+// CreateGenerationSession adds a new session to the in-memory store, removing expired ones
+func (s *MemoryGenerationSessionStore) CreateGenerationSession(ctx context.Context, session models.GenerationSession) models.GenerationSession {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	for id, existing := range s.sessions {
+		if !existing.ExpiresAt.After(now) {
+			delete(s.sessions, id)
+		}
+	}
+
+	session.ID = s.nextID
+	s.nextID++
+	session.Messages = slices.Clone(session.Messages)
+	session.CreatedAt = now
+	session.UpdatedAt = now
+
+	s.sessions[session.ID] = session
+	return session
+}
+
+// GetGenerationSession retrieves a session by ID from the in-memory store
+func (s *MemoryGenerationSessionStore) GetGenerationSession(ctx context.Context, id int64) (models.GenerationSession, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	session, exists := s.sessions[id]
+	session.Messages = slices.Clone(session.Messages)
+	return session, exists
+}
+
+// UpdateGenerationSession updates an existing session in the in-memory store
+func (s *MemoryGenerationSessionStore) UpdateGenerationSession(ctx context.Context, id int64, updatedSession models.GenerationSession) (models.GenerationSession, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, exists := s.sessions[id]
+	if !exists {
+		return models.GenerationSession{}, false
+	}
+
+	updatedSession.ID = id
+	updatedSession.Messages = slices.Clone(updatedSession.Messages)
+	updatedSession.CreatedAt = existing.CreatedAt
+	updatedSession.UpdatedAt = time.Now()
+	s.sessions[id] = updatedSession
+	return updatedSession, true
+}
+
+// DeleteGenerationSession removes a session from the in-memory store
+func (s *MemoryGenerationSessionStore) DeleteGenerationSession(ctx context.Context, id int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.sessions[id]; !exists {
+		return false
+	}
+
+	delete(s.sessions, id)
+	return true
+}
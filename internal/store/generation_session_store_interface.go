@@ -0,0 +1,16 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// GenerationSessionStore defines the interface for generation session storage operations.
+// Stores return sessions whether or not they have expired; expired sessions are removed
+// when new sessions are created.
+type GenerationSessionStore interface {
+	CreateGenerationSession(ctx context.Context, session models.GenerationSession) models.GenerationSession
+	GetGenerationSession(ctx context.Context, id int64) (models.GenerationSession, bool)
+	UpdateGenerationSession(ctx context.Context, id int64, updatedSession models.GenerationSession) (models.GenerationSession, bool)
+	DeleteGenerationSession(ctx context.Context, id int64) bool
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// PostgresItemShareStore provides PostgreSQL storage operations for the shares of scheduled items
+type PostgresItemShareStore struct {
+	db *sql.DB
+}
+
+// NewPostgresItemShareStore creates a new PostgreSQL item share store with the given database connection
+func NewPostgresItemShareStore(db *sql.DB) *PostgresItemShareStore {
+	return &PostgresItemShareStore{
+		db: db,
+	}
+}
+
+// SetShare shares an item with a user, or changes their role when it already is
+func (s *PostgresItemShareStore) SetShare(ctx context.Context, share models.ItemShare) (models.ItemShare, bool) {
+	query := `
+		INSERT INTO item_shares (scheduled_item_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (scheduled_item_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, share.ScheduledItemID, share.UserID, share.Role).Scan(&share.CreatedAt, &share.UpdatedAt)
+	if err != nil {
+		logging.Errorf("Error setting item share: %v", err)
+		return models.ItemShare{}, false
+	}
+
+	return share, true
+}
+
+// GetShare retrieves the share of an item with a user from the database
+func (s *PostgresItemShareStore) GetShare(ctx context.Context, scheduledItemID int64, userID int64) (models.ItemShare, bool) {
+	var share models.ItemShare
+	query := `
+		SELECT scheduled_item_id, user_id, role, created_at, updated_at
+		FROM item_shares
+		WHERE scheduled_item_id = $1 AND user_id = $2
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, scheduledItemID, userID).
+		Scan(&share.ScheduledItemID, &share.UserID, &share.Role, &share.CreatedAt, &share.UpdatedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Errorf("Error getting item share: %v", err)
+		}
+		return models.ItemShare{}, false
+	}
+
+	return share, true
+}
+
+// GetShares returns the shares of an item in the order they were granted
+func (s *PostgresItemShareStore) GetShares(ctx context.Context, scheduledItemID int64) []models.ItemShare {
+	return s.query(ctx, `
+		SELECT scheduled_item_id, user_id, role, created_at, updated_at
+		FROM item_shares
+		WHERE scheduled_item_id = $1
+		ORDER BY created_at, user_id
+	`, scheduledItemID)
+}
+
+// GetSharesForUser returns the shares of the items shared with a user in the order they were granted
+func (s *PostgresItemShareStore) GetSharesForUser(ctx context.Context, userID int64) []models.ItemShare {
+	return s.query(ctx, `
+		SELECT scheduled_item_id, user_id, role, created_at, updated_at
+		FROM item_shares
+		WHERE user_id = $1
+		ORDER BY created_at, scheduled_item_id
+	`, userID)
+}
+
+// query returns the shares selected by query
+func (s *PostgresItemShareStore) query(ctx context.Context, query string, args ...any) []models.ItemShare {
+	rows, err := timed(s.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.Errorf("Error querying item shares: %v", err)
+		return []models.ItemShare{}
+	}
+	defer rows.Close()
+
+	shares := []models.ItemShare{}
+	for rows.Next() {
+		var share models.ItemShare
+		if err := rows.Scan(&share.ScheduledItemID, &share.UserID, &share.Role, &share.CreatedAt, &share.UpdatedAt); err != nil {
+			logging.Errorf("Error scanning item share row: %v", err)
+			continue
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating item share rows: %v", err)
+	}
+
+	return shares
+}
+
+// RemoveShare stops sharing an item with a user in the database
+func (s *PostgresItemShareStore) RemoveShare(ctx context.Context, scheduledItemID int64, userID int64) bool {
+	query := `DELETE FROM item_shares WHERE scheduled_item_id = $1 AND user_id = $2`
+	result, err := timed(s.db).ExecContext(ctx, query, scheduledItemID, userID)
+	if err != nil {
+		logging.Errorf("Error removing item share: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoItemShare is the DynamoDB representation of an item share. Each share is written
+// twice, partitioned by item and keyed by user, and partitioned by user and keyed by item,
+// so both the shares of an item and the items shared with a user are a single Query.
+type dynamoItemShare struct {
+	PK              string    `dynamodbav:"pk"`
+	SK              string    `dynamodbav:"sk"`
+	ScheduledItemID int64     `dynamodbav:"scheduled_item_id"`
+	UserID          int64     `dynamodbav:"user_id"`
+	Role            string    `dynamodbav:"role"`
+	CreatedAt       time.Time `dynamodbav:"created_at"`
+	UpdatedAt       time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to an item share
+func (r dynamoItemShare) toModel() models.ItemShare {
+	return models.ItemShare{
+		ScheduledItemID: r.ScheduledItemID,
+		UserID:          r.UserID,
+		Role:            r.Role,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}
+}
+
+// dynamoItemSharePartition returns the partition key holding the shares of an item
+func dynamoItemSharePartition(scheduledItemID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityItemShare, scheduledItemID)
+}
+
+// dynamoUserItemSharePartition returns the partition key holding the shares with a user
+func dynamoUserItemSharePartition(userID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityUserItemShare, userID)
+}
+
+// DynamoItemShareStore provides DynamoDB storage operations for the shares of scheduled items
+type DynamoItemShareStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoItemShareStore creates a new DynamoDB item share store using the given client and table
+func NewDynamoItemShareStore(client *dynamodb.Client, table string) *DynamoItemShareStore {
+	return &DynamoItemShareStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// SetShare shares an item with a user, or changes their role when it already is
+func (s *DynamoItemShareStore) SetShare(ctx context.Context, share models.ItemShare) (models.ItemShare, bool) {
+	share.UpdatedAt = time.Now()
+	share.CreatedAt = share.UpdatedAt
+	if existing, exists := s.GetShare(ctx, share.ScheduledItemID, share.UserID); exists {
+		share.CreatedAt = existing.CreatedAt
+	}
+
+	var writes []types.TransactWriteItem
+	for _, key := range [][2]string{
+		{dynamoItemSharePartition(share.ScheduledItemID), dynamoSortKeyForID(share.UserID)},
+		{dynamoUserItemSharePartition(share.UserID), dynamoSortKeyForID(share.ScheduledItemID)},
+	} {
+		record, err := attributevalue.MarshalMap(dynamoItemShare{
+			PK:              key[0],
+			SK:              key[1],
+			ScheduledItemID: share.ScheduledItemID,
+			UserID:          share.UserID,
+			Role:            share.Role,
+			CreatedAt:       share.CreatedAt,
+			UpdatedAt:       share.UpdatedAt,
+		})
+		if err != nil {
+			logging.Errorf("Error marshalling item share: %v", err)
+			return models.ItemShare{}, false
+		}
+		writes = append(writes, types.TransactWriteItem{Put: &types.Put{
+			TableName: aws.String(s.table),
+			Item:      record,
+		}})
+	}
+
+	_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: writes,
+	})
+	if err != nil {
+		logging.Errorf("Error setting item share: %v", err)
+		return models.ItemShare{}, false
+	}
+
+	return share, true
+}
+
+// GetShare retrieves the share of an item with a user from the table
+func (s *DynamoItemShareStore) GetShare(ctx context.Context, scheduledItemID int64, userID int64) (models.ItemShare, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoItemSharePartition(scheduledItemID), dynamoSortKeyForID(userID)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting item share: %v", err)
+		return models.ItemShare{}, false
+	}
+	if output.Item == nil {
+		return models.ItemShare{}, false
+	}
+
+	var record dynamoItemShare
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling item share: %v", err)
+		return models.ItemShare{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetShares returns the shares of an item in the order they were granted
+func (s *DynamoItemShareStore) GetShares(ctx context.Context, scheduledItemID int64) []models.ItemShare {
+	return s.query(ctx, dynamoItemSharePartition(scheduledItemID))
+}
+
+// GetSharesForUser returns the shares of the items shared with a user in the order they were granted
+func (s *DynamoItemShareStore) GetSharesForUser(ctx context.Context, userID int64) []models.ItemShare {
+	return s.query(ctx, dynamoUserItemSharePartition(userID))
+}
+
+// query returns the shares in a partition, ordered by when they were granted
+func (s *DynamoItemShareStore) query(ctx context.Context, partition string) []models.ItemShare {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: partition},
+		},
+	})
+
+	shares := []models.ItemShare{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying item shares: %v", err)
+			return []models.ItemShare{}
+		}
+
+		var records []dynamoItemShare
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling item shares: %v", err)
+			return []models.ItemShare{}
+		}
+		for _, record := range records {
+			shares = append(shares, record.toModel())
+		}
+	}
+
+	// Shares sort by user or item ID in the table
+	slices.SortStableFunc(shares, func(a, b models.ItemShare) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return shares
+}
+
+// RemoveShare stops sharing an item with a user in the table
+func (s *DynamoItemShareStore) RemoveShare(ctx context.Context, scheduledItemID int64, userID int64) bool {
+	if _, exists := s.GetShare(ctx, scheduledItemID, userID); !exists {
+		return false
+	}
+
+	_, err := s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Delete: &types.Delete{TableName: aws.String(s.table), Key: dynamoKey(dynamoItemSharePartition(scheduledItemID), dynamoSortKeyForID(userID))}},
+			{Delete: &types.Delete{TableName: aws.String(s.table), Key: dynamoKey(dynamoUserItemSharePartition(userID), dynamoSortKeyForID(scheduledItemID))}},
+		},
+	})
+	if err != nil {
+		logging.Errorf("Error removing item share: %v", err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryItemShareStore provides in-memory storage operations for the shares of scheduled items
+type MemoryItemShareStore struct {
+	sync.RWMutex
+	shares []models.ItemShare
+}
+
+// NewMemoryItemShareStore creates a new in-memory item share store
+func NewMemoryItemShareStore() *MemoryItemShareStore {
+	return &MemoryItemShareStore{}
+}
+
+// SetShare shares an item with a user, or changes their role when it already is
+func (s *MemoryItemShareStore) SetShare(ctx context.Context, share models.ItemShare) (models.ItemShare, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	share.UpdatedAt = time.Now()
+	share.CreatedAt = share.UpdatedAt
+	for i, existing := range s.shares {
+		if existing.ScheduledItemID == share.ScheduledItemID && existing.UserID == share.UserID {
+			share.CreatedAt = existing.CreatedAt
+			s.shares[i] = share
+			return share, true
+		}
+	}
+	s.shares = append(s.shares, share)
+	return share, true
+}
+
+// GetShare retrieves the share of an item with a user from the in-memory store
+func (s *MemoryItemShareStore) GetShare(ctx context.Context, scheduledItemID int64, userID int64) (models.ItemShare, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, share := range s.shares {
+		if share.ScheduledItemID == scheduledItemID && share.UserID == userID {
+			return share, true
+		}
+	}
+	return models.ItemShare{}, false
+}
+
+// GetShares returns the shares of an item in the order they were granted
+func (s *MemoryItemShareStore) GetShares(ctx context.Context, scheduledItemID int64) []models.ItemShare {
+	return s.filter(func(share models.ItemShare) bool { return share.ScheduledItemID == scheduledItemID })
+}
+
+// GetSharesForUser returns the shares of the items shared with a user in the order they were granted
+func (s *MemoryItemShareStore) GetSharesForUser(ctx context.Context, userID int64) []models.ItemShare {
+	return s.filter(func(share models.ItemShare) bool { return share.UserID == userID })
+}
+
+// filter returns the shares matching keep
+func (s *MemoryItemShareStore) filter(keep func(share models.ItemShare) bool) []models.ItemShare {
+	s.RLock()
+	defer s.RUnlock()
+
+	shares := []models.ItemShare{}
+	for _, share := range s.shares {
+		if keep(share) {
+			shares = append(shares, share)
+		}
+	}
+	return shares
+}
+
+// RemoveShare stops sharing an item with a user in the in-memory store
+func (s *MemoryItemShareStore) RemoveShare(ctx context.Context, scheduledItemID int64, userID int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	i := slices.IndexFunc(s.shares, func(share models.ItemShare) bool {
+		return share.ScheduledItemID == scheduledItemID && share.UserID == userID
+	})
+	if i < 0 {
+		return false
+	}
+	s.shares = slices.Delete(s.shares, i, i+1)
+	return true
+}
@@ -0,0 +1,17 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// ItemShareStore defines the interface for storage operations on the shares of scheduled items
+type ItemShareStore interface {
+	// SetShare shares an item with a user, or changes their role when it already is
+	SetShare(ctx context.Context, share models.ItemShare) (models.ItemShare, bool)
+	GetShare(ctx context.Context, scheduledItemID int64, userID int64) (models.ItemShare, bool)
+	GetShares(ctx context.Context, scheduledItemID int64) []models.ItemShare
+	// GetSharesForUser returns the shares of the items shared with a user
+	GetSharesForUser(ctx context.Context, userID int64) []models.ItemShare
+	RemoveShare(ctx context.Context, scheduledItemID int64, userID int64) bool
+}
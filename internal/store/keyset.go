@@ -0,0 +1,69 @@
+package store
+
+import (
+	"cmp"
+	"periodic-api/internal/models"
+	"slices"
+)
+
+// keysetPage returns a page of up to limit items taken from a full listing, in the order
+// compare gives their cursors: those after cursor, or from the start when cursor is nil.
+// The returned cursor marks the page's last item, and is nil when no further items
+// follow. A limit below 1 is rejected with ErrInvalidLimit. It serves the stores that
+// can't seek to a cursor in their queries.
+func keysetPage[T, C any](items []T, limit int, cursor *C, cursorOf func(T) C, compare func(a, b C) int) ([]T, *C, error) {
+	if limit < 1 {
+		return []T{}, nil, ErrInvalidLimit
+	}
+	if cursor != nil {
+		items = slices.DeleteFunc(items, func(item T) bool {
+			return compare(cursorOf(item), *cursor) <= 0
+		})
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		return compare(cursorOf(a), cursorOf(b))
+	})
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+	next := cursorOf(items[len(items)-1])
+	return items, &next, nil
+}
+
+// compareScheduledItemCursors orders scheduled items by next execution time, then ID
+func compareScheduledItemCursors(a, b ScheduledItemCursor) int {
+	if c := a.NextExecutionAt.Compare(b.NextExecutionAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareTodoItemCursors orders todo items by ID
+func compareTodoItemCursors(a, b TodoItemCursor) int {
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareExecutionLogCursors orders execution logs newest first, then by descending ID
+func compareExecutionLogCursors(a, b ExecutionLogCursor) int {
+	if c := b.ExecutedAt.Compare(a.ExecutedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.ID, a.ID)
+}
+
+// scheduledItemCursor returns the cursor marking a scheduled item
+func scheduledItemCursor(item models.ScheduledItem) ScheduledItemCursor {
+	return ScheduledItemCursor{NextExecutionAt: item.NextExecutionAt, ID: item.ID}
+}
+
+// todoItemCursor returns the cursor marking a todo item
+func todoItemCursor(item models.TodoItem) TodoItemCursor {
+	return TodoItemCursor{ID: item.ID}
+}
+
+// executionLogCursor returns the cursor marking an execution log
+func executionLogCursor(log models.ExecutionLog) ExecutionLogCursor {
+	return ExecutionLogCursor{ExecutedAt: log.ExecutedAt, ID: log.ID}
+}
@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// PostgresLLMUsageStore provides PostgreSQL storage operations for LLM usage
+type PostgresLLMUsageStore struct {
+	db *sql.DB
+}
+
+// NewPostgresLLMUsageStore creates a new PostgreSQL LLM usage store with the given database connection
+func NewPostgresLLMUsageStore(db *sql.DB) *PostgresLLMUsageStore {
+	return &PostgresLLMUsageStore{
+		db: db,
+	}
+}
+
+// CreateLLMUsage records LLM usage in the database
+func (s *PostgresLLMUsageStore) CreateLLMUsage(ctx context.Context, usage models.LLMUsage) models.LLMUsage {
+	query := `
+		INSERT INTO llm_usage
+		(user_id, operation, provider, model, calls, input_tokens, output_tokens, latency_ms, succeeded, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	usage.TenantID = TenantFromContext(ctx)
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		usage.UserID,
+		usage.Operation,
+		usage.Provider,
+		usage.Model,
+		usage.Calls,
+		usage.InputTokens,
+		usage.OutputTokens,
+		usage.LatencyMs,
+		usage.Succeeded,
+		usage.TenantID,
+	).Scan(&usage.ID, &usage.CreatedAt)
+
+	if err != nil {
+		logging.Errorf("Error creating LLM usage: %v", err)
+		return models.LLMUsage{} // Return empty usage on error
+	}
+
+	return usage
+}
+
+// GetLLMUsage returns the usage recorded in a period from the database, oldest first
+func (s *PostgresLLMUsageStore) GetLLMUsage(ctx context.Context, userID string, since time.Time, until time.Time) []models.LLMUsage {
+	query := `
+		SELECT id, user_id, operation, provider, model, calls, input_tokens, output_tokens, latency_ms, succeeded, tenant_id, created_at
+		FROM llm_usage
+		WHERE ($1 = '' OR user_id = $1) AND created_at >= $2 AND created_at < $3 AND tenant_id = $4
+		ORDER BY created_at, id
+	`
+
+	rows, err := querier(ctx, s.db).QueryContext(ctx, query, userID, since, until, TenantFromContext(ctx))
+	if err != nil {
+		logging.Errorf("Error querying LLM usage: %v", err)
+		return []models.LLMUsage{}
+	}
+	defer rows.Close()
+
+	usage := []models.LLMUsage{}
+	for rows.Next() {
+		var record models.LLMUsage
+
+		err := rows.Scan(
+			&record.ID,
+			&record.UserID,
+			&record.Operation,
+			&record.Provider,
+			&record.Model,
+			&record.Calls,
+			&record.InputTokens,
+			&record.OutputTokens,
+			&record.LatencyMs,
+			&record.Succeeded,
+			&record.TenantID,
+			&record.CreatedAt,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		usage = append(usage, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+
+	return usage
+}
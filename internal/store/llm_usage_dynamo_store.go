@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoLLMUsage is the DynamoDB representation of an LLM usage record. Records are
+// sorted by creation time so a period is a single Query.
+type dynamoLLMUsage struct {
+	PK           string    `dynamodbav:"pk"`
+	SK           string    `dynamodbav:"sk"`
+	ID           int64     `dynamodbav:"id"`
+	UserID       string    `dynamodbav:"user_id"`
+	Operation    string    `dynamodbav:"operation"`
+	Provider     string    `dynamodbav:"provider"`
+	Model        string    `dynamodbav:"model"`
+	Calls        int       `dynamodbav:"calls"`
+	InputTokens  int       `dynamodbav:"input_tokens"`
+	OutputTokens int       `dynamodbav:"output_tokens"`
+	LatencyMs    int64     `dynamodbav:"latency_ms"`
+	Succeeded    bool      `dynamodbav:"succeeded"`
+	TenantID     string    `dynamodbav:"tenant_id,omitempty"`
+	CreatedAt    time.Time `dynamodbav:"created_at"`
+}
+
+// toModel converts the DynamoDB representation back to an LLM usage record
+func (r dynamoLLMUsage) toModel() models.LLMUsage {
+	return models.LLMUsage{
+		ID:           r.ID,
+		UserID:       r.UserID,
+		Operation:    r.Operation,
+		Provider:     r.Provider,
+		Model:        r.Model,
+		Calls:        r.Calls,
+		InputTokens:  r.InputTokens,
+		OutputTokens: r.OutputTokens,
+		LatencyMs:    r.LatencyMs,
+		Succeeded:    r.Succeeded,
+		TenantID:     r.TenantID,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+// DynamoLLMUsageStore provides DynamoDB storage operations for LLM usage
+type DynamoLLMUsageStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoLLMUsageStore creates a new DynamoDB LLM usage store using the given client and table
+func NewDynamoLLMUsageStore(client *dynamodb.Client, table string) *DynamoLLMUsageStore {
+	return &DynamoLLMUsageStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateLLMUsage records LLM usage in the table
+func (s *DynamoLLMUsageStore) CreateLLMUsage(ctx context.Context, usage models.LLMUsage) models.LLMUsage {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityLLMUsage)
+	if err != nil {
+		logging.Errorf("Error allocating LLM usage ID: %v", err)
+		return models.LLMUsage{}
+	}
+	usage.ID = id
+	usage.TenantID = TenantFromContext(ctx)
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+
+	record, err := attributevalue.MarshalMap(dynamoLLMUsage{
+		PK:           dynamoEntityLLMUsage,
+		SK:           dynamoTimeSortKey(usage.CreatedAt) + "#" + dynamoSortKeyForID(usage.ID),
+		ID:           usage.ID,
+		UserID:       usage.UserID,
+		Operation:    usage.Operation,
+		Provider:     usage.Provider,
+		Model:        usage.Model,
+		Calls:        usage.Calls,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		LatencyMs:    usage.LatencyMs,
+		Succeeded:    usage.Succeeded,
+		TenantID:     usage.TenantID,
+		CreatedAt:    usage.CreatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling LLM usage: %v", err)
+		return models.LLMUsage{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error creating LLM usage: %v", err)
+		return models.LLMUsage{} // Return empty usage on error
+	}
+
+	return usage
+}
+
+// GetLLMUsage returns the usage recorded in a period from the table, oldest first
+func (s *DynamoLLMUsageStore) GetLLMUsage(ctx context.Context, userID string, since time.Time, until time.Time) []models.LLMUsage {
+	// Sort keys of records created at until follow the bare timestamp, so BETWEEN excludes them
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk AND sk BETWEEN :since AND :until"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk":    &types.AttributeValueMemberS{Value: dynamoEntityLLMUsage},
+			":since": &types.AttributeValueMemberS{Value: dynamoTimeSortKey(since)},
+			":until": &types.AttributeValueMemberS{Value: dynamoTimeSortKey(until)},
+		},
+	}
+	if userID != "" {
+		input.FilterExpression = aws.String("user_id = :user_id")
+		input.ExpressionAttributeValues[":user_id"] = &types.AttributeValueMemberS{Value: userID}
+	}
+	tenantDynamoQuery(ctx, input)
+
+	usage := []models.LLMUsage{}
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying LLM usage: %v", err)
+			return []models.LLMUsage{}
+		}
+
+		var records []dynamoLLMUsage
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling LLM usage: %v", err)
+			return []models.LLMUsage{}
+		}
+		for _, record := range records {
+			usage = append(usage, record.toModel())
+		}
+	}
+	return usage
+}
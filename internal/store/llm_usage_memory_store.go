@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"sync"
+	"time"
+)
+
+// MemoryLLMUsageStore provides in-memory storage operations for LLM usage
+type MemoryLLMUsageStore struct {
+	sync.RWMutex
+	usage  []models.LLMUsage
+	nextID int64
+}
+
+// NewMemoryLLMUsageStore creates a new in-memory LLM usage store
+func NewMemoryLLMUsageStore() *MemoryLLMUsageStore {
+	return &MemoryLLMUsageStore{
+		usage:  []models.LLMUsage{},
+		nextID: 1,
+	}
+}
+
+// CreateLLMUsage records LLM usage in the in-memory store
+func (s *MemoryLLMUsageStore) CreateLLMUsage(ctx context.Context, usage models.LLMUsage) models.LLMUsage {
+	s.Lock()
+	defer s.Unlock()
+
+	usage.ID = s.nextID
+	s.nextID++
+	usage.TenantID = TenantFromContext(ctx)
+	if usage.CreatedAt.IsZero() {
+		usage.CreatedAt = time.Now()
+	}
+
+	s.usage = append(s.usage, usage)
+	return usage
+}
+
+// GetLLMUsage returns the usage recorded in a period from the in-memory store, oldest first
+func (s *MemoryLLMUsageStore) GetLLMUsage(ctx context.Context, userID string, since time.Time, until time.Time) []models.LLMUsage {
+	s.RLock()
+	defer s.RUnlock()
+
+	usage := []models.LLMUsage{}
+	for _, record := range s.usage {
+		if !ownTenant(ctx, record.TenantID) || (userID != "" && record.UserID != userID) {
+			continue
+		}
+		if record.CreatedAt.Before(since) || !record.CreatedAt.Before(until) {
+			continue
+		}
+		usage = append(usage, record)
+	}
+	return usage
+}
@@ -0,0 +1,33 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// LLMUsageStore defines the interface for recording and querying LLM usage
+type LLMUsageStore interface {
+	CreateLLMUsage(ctx context.Context, usage models.LLMUsage) models.LLMUsage
+	// GetLLMUsage returns the usage recorded at or after since and before until, oldest
+	// first. An empty userID returns the usage of every user.
+	GetLLMUsage(ctx context.Context, userID string, since time.Time, until time.Time) []models.LLMUsage
+}
+
+// SumLLMUsage totals usage records per user, in the order each user first appears
+func SumLLMUsage(usage []models.LLMUsage) []models.LLMUsageTotals {
+	totals := []models.LLMUsageTotals{}
+	index := map[string]int{}
+	for _, record := range usage {
+		i, ok := index[record.UserID]
+		if !ok {
+			i = len(totals)
+			index[record.UserID] = i
+			totals = append(totals, models.LLMUsageTotals{UserID: record.UserID})
+		}
+		totals[i].Requests++
+		totals[i].InputTokens += record.InputTokens
+		totals[i].OutputTokens += record.OutputTokens
+	}
+	return totals
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// PostgresNotificationPreferenceStore provides PostgreSQL storage operations for users' notification preferences
+type PostgresNotificationPreferenceStore struct {
+	db *sql.DB
+}
+
+// NewPostgresNotificationPreferenceStore creates a new PostgreSQL notification preference store with the given database connection
+func NewPostgresNotificationPreferenceStore(db *sql.DB) *PostgresNotificationPreferenceStore {
+	return &PostgresNotificationPreferenceStore{
+		db: db,
+	}
+}
+
+// SaveNotificationPreference creates or replaces the preferences of a user
+func (s *PostgresNotificationPreferenceStore) SaveNotificationPreference(ctx context.Context, preference models.NotificationPreference) (models.NotificationPreference, bool) {
+	query := `
+		INSERT INTO notification_preferences 
+		(user_id, channels, events, slack_user_id, quiet_hours_start, quiet_hours_end, timezone, subject, body) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+		ON CONFLICT (user_id) DO UPDATE SET
+			channels = EXCLUDED.channels,
+			events = EXCLUDED.events,
+			slack_user_id = EXCLUDED.slack_user_id,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone,
+			subject = EXCLUDED.subject,
+			body = EXCLUDED.body,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(
+		ctx,
+		query,
+		preference.UserID,
+		pq.Array(preference.Channels),
+		pq.Array(preference.Events),
+		preference.SlackUserID,
+		preference.QuietHoursStart,
+		preference.QuietHoursEnd,
+		preference.Timezone,
+		preference.Subject,
+		preference.Body,
+	).Scan(&preference.CreatedAt, &preference.UpdatedAt)
+
+	if err != nil {
+		logging.Errorf("Error saving notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	return preference, true
+}
+
+// GetNotificationPreference retrieves the preferences of a user from the database
+func (s *PostgresNotificationPreferenceStore) GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool) {
+	var preference models.NotificationPreference
+	query := `
+		SELECT user_id, channels, events, slack_user_id, quiet_hours_start, quiet_hours_end, timezone, subject, body, created_at, updated_at 
+		FROM notification_preferences 
+		WHERE user_id = $1
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, userID).Scan(
+		&preference.UserID,
+		pq.Array(&preference.Channels),
+		pq.Array(&preference.Events),
+		&preference.SlackUserID,
+		&preference.QuietHoursStart,
+		&preference.QuietHoursEnd,
+		&preference.Timezone,
+		&preference.Subject,
+		&preference.Body,
+		&preference.CreatedAt,
+		&preference.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.NotificationPreference{}, false
+		}
+		logging.Errorf("Error getting notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	return preference, true
+}
+
+// DeleteNotificationPreference removes the preferences of a user from the database
+func (s *PostgresNotificationPreferenceStore) DeleteNotificationPreference(ctx context.Context, userID int64) bool {
+	query := `DELETE FROM notification_preferences WHERE user_id = $1`
+	result, err := timed(s.db).ExecContext(ctx, query, userID)
+	if err != nil {
+		logging.Errorf("Error deleting notification preferences: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoNotificationPreference is the DynamoDB representation of a user's notification preferences
+type dynamoNotificationPreference struct {
+	PK              string    `dynamodbav:"pk"`
+	SK              string    `dynamodbav:"sk"`
+	UserID          int64     `dynamodbav:"user_id"`
+	Channels        []string  `dynamodbav:"channels"`
+	Events          []string  `dynamodbav:"events"`
+	SlackUserID     string    `dynamodbav:"slack_user_id"`
+	QuietHoursStart string    `dynamodbav:"quiet_hours_start"`
+	QuietHoursEnd   string    `dynamodbav:"quiet_hours_end"`
+	Timezone        string    `dynamodbav:"timezone"`
+	Subject         string    `dynamodbav:"subject"`
+	Body            string    `dynamodbav:"body"`
+	CreatedAt       time.Time `dynamodbav:"created_at"`
+	UpdatedAt       time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to notification preferences
+func (r dynamoNotificationPreference) toModel() models.NotificationPreference {
+	return models.NotificationPreference{
+		UserID:          r.UserID,
+		Channels:        r.Channels,
+		Events:          r.Events,
+		SlackUserID:     r.SlackUserID,
+		QuietHoursStart: r.QuietHoursStart,
+		QuietHoursEnd:   r.QuietHoursEnd,
+		Timezone:        r.Timezone,
+		Subject:         r.Subject,
+		Body:            r.Body,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}
+}
+
+// DynamoNotificationPreferenceStore provides DynamoDB storage operations for users' notification preferences
+type DynamoNotificationPreferenceStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoNotificationPreferenceStore creates a new DynamoDB notification preference store using the given client and table
+func NewDynamoNotificationPreferenceStore(client *dynamodb.Client, table string) *DynamoNotificationPreferenceStore {
+	return &DynamoNotificationPreferenceStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// SaveNotificationPreference creates or replaces the preferences of a user
+func (s *DynamoNotificationPreferenceStore) SaveNotificationPreference(ctx context.Context, preference models.NotificationPreference) (models.NotificationPreference, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":user_id":           preference.UserID,
+		":channels":          preference.Channels,
+		":events":            preference.Events,
+		":slack_user_id":     preference.SlackUserID,
+		":quiet_hours_start": preference.QuietHoursStart,
+		":quiet_hours_end":   preference.QuietHoursEnd,
+		":timezone":          preference.Timezone,
+		":subject":           preference.Subject,
+		":body":              preference.Body,
+		":now":               time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	// Update in place so the creation time of existing preferences is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityNotificationPreference, dynamoSortKeyForID(preference.UserID)),
+		UpdateExpression: aws.String("SET user_id = :user_id, channels = :channels, events = :events, " +
+			"slack_user_id = :slack_user_id, quiet_hours_start = :quiet_hours_start, quiet_hours_end = :quiet_hours_end, " +
+			"timezone = :timezone, subject = :subject, body = :body, " +
+			"created_at = if_not_exists(created_at, :now), updated_at = :now"),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		logging.Errorf("Error saving notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	var record dynamoNotificationPreference
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetNotificationPreference retrieves the preferences of a user from the table
+func (s *DynamoNotificationPreferenceStore) GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityNotificationPreference, dynamoSortKeyForID(userID)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+	if output.Item == nil {
+		return models.NotificationPreference{}, false
+	}
+
+	var record dynamoNotificationPreference
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling notification preferences: %v", err)
+		return models.NotificationPreference{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// DeleteNotificationPreference removes the preferences of a user from the table
+func (s *DynamoNotificationPreferenceStore) DeleteNotificationPreference(ctx context.Context, userID int64) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoEntityNotificationPreference, dynamoSortKeyForID(userID)),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting notification preferences: %v", err)
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
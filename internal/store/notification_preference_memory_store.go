@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"sync"
+	"time"
+)
+
+// MemoryNotificationPreferenceStore provides in-memory storage operations for users' notification preferences
+type MemoryNotificationPreferenceStore struct {
+	sync.RWMutex
+	preferences map[int64]models.NotificationPreference
+}
+
+// NewMemoryNotificationPreferenceStore creates a new in-memory notification preference store
+func NewMemoryNotificationPreferenceStore() *MemoryNotificationPreferenceStore {
+	return &MemoryNotificationPreferenceStore{
+		preferences: make(map[int64]models.NotificationPreference),
+	}
+}
+
+// SaveNotificationPreference creates or replaces the preferences of a user
+func (s *MemoryNotificationPreferenceStore) SaveNotificationPreference(ctx context.Context, preference models.NotificationPreference) (models.NotificationPreference, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	preference.UpdatedAt = time.Now()
+	preference.CreatedAt = preference.UpdatedAt
+	if existing, exists := s.preferences[preference.UserID]; exists {
+		preference.CreatedAt = existing.CreatedAt
+	}
+	s.preferences[preference.UserID] = preference
+	return preference, true
+}
+
+// GetNotificationPreference retrieves the preferences of a user from the in-memory store
+func (s *MemoryNotificationPreferenceStore) GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	preference, exists := s.preferences[userID]
+	return preference, exists
+}
+
+// DeleteNotificationPreference removes the preferences of a user from the in-memory store
+func (s *MemoryNotificationPreferenceStore) DeleteNotificationPreference(ctx context.Context, userID int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.preferences[userID]; !exists {
+		return false
+	}
+	delete(s.preferences, userID)
+	return true
+}
@@ -0,0 +1,14 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// NotificationPreferenceStore defines the interface for users' notification preference storage operations
+type NotificationPreferenceStore interface {
+	// SaveNotificationPreference creates or replaces the preferences of a user
+	SaveNotificationPreference(ctx context.Context, preference models.NotificationPreference) (models.NotificationPreference, bool)
+	GetNotificationPreference(ctx context.Context, userID int64) (models.NotificationPreference, bool)
+	DeleteNotificationPreference(ctx context.Context, userID int64) bool
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"encoding/json"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// encodeNotifications encodes notification settings as JSON for storage, returning nil for
+// missing settings so they are stored as NULL
+func encodeNotifications(settings *models.NotificationSettings) *string {
+	if settings == nil {
+		return nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		logging.Errorf("Error encoding notification settings: %v", err)
+		return nil
+	}
+	encoded := string(data)
+	return &encoded
+}
+
+// decodeNotifications decodes notification settings read from a JSON column, returning nil
+// for NULL or unreadable settings
+func decodeNotifications(data []byte) *models.NotificationSettings {
+	if len(data) == 0 {
+		return nil
+	}
+	var settings models.NotificationSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		logging.Errorf("Error decoding notification settings: %v", err)
+		return nil
+	}
+	return &settings
+}
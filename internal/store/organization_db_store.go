@@ -0,0 +1,327 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// PostgresOrganizationStore provides PostgreSQL storage operations for organizations and their members
+type PostgresOrganizationStore struct {
+	db *sql.DB
+}
+
+// NewPostgresOrganizationStore creates a new PostgreSQL organization store with the given database connection
+func NewPostgresOrganizationStore(db *sql.DB) *PostgresOrganizationStore {
+	return &PostgresOrganizationStore{
+		db: db,
+	}
+}
+
+// CreateOrganization adds a new organization to the database
+func (s *PostgresOrganizationStore) CreateOrganization(ctx context.Context, organization models.Organization) models.Organization {
+	query := `
+		INSERT INTO organizations (name, description)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, organization.Name, organization.Description).
+		Scan(&organization.ID, &organization.CreatedAt, &organization.UpdatedAt)
+	if err != nil {
+		logging.Errorf("Error creating organization: %v", err)
+		return models.Organization{}
+	}
+
+	return organization
+}
+
+// GetOrganization retrieves an organization by ID from the database
+func (s *PostgresOrganizationStore) GetOrganization(ctx context.Context, id int64) (models.Organization, bool) {
+	var organization models.Organization
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM organizations
+		WHERE id = $1
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, id).Scan(
+		&organization.ID,
+		&organization.Name,
+		&organization.Description,
+		&organization.CreatedAt,
+		&organization.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Errorf("Error getting organization: %v", err)
+		}
+		return models.Organization{}, false
+	}
+
+	return organization, true
+}
+
+// GetAllOrganizations returns all organizations from the database in ID order
+func (s *PostgresOrganizationStore) GetAllOrganizations(ctx context.Context) []models.Organization {
+	query := `
+		SELECT id, name, description, created_at, updated_at
+		FROM organizations
+		ORDER BY id
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query)
+	if err != nil {
+		logging.Errorf("Error querying organizations: %v", err)
+		return []models.Organization{}
+	}
+	defer rows.Close()
+
+	organizations := []models.Organization{}
+	for rows.Next() {
+		var organization models.Organization
+		if err := rows.Scan(&organization.ID, &organization.Name, &organization.Description, &organization.CreatedAt, &organization.UpdatedAt); err != nil {
+			logging.Errorf("Error scanning organization row: %v", err)
+			continue
+		}
+		organizations = append(organizations, organization)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating organization rows: %v", err)
+	}
+
+	return organizations
+}
+
+// UpdateOrganization updates an existing organization in the database
+func (s *PostgresOrganizationStore) UpdateOrganization(ctx context.Context, id int64, organization models.Organization) (models.Organization, bool) {
+	query := `
+		UPDATE organizations
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, organization.Name, organization.Description, id).
+		Scan(&organization.CreatedAt, &organization.UpdatedAt)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Errorf("Error updating organization: %v", err)
+		}
+		return models.Organization{}, false
+	}
+
+	organization.ID = id
+	return organization, true
+}
+
+// DeleteOrganization removes an organization from the database; its memberships and
+// invitations are removed by the foreign keys
+func (s *PostgresOrganizationStore) DeleteOrganization(ctx context.Context, id int64) bool {
+	query := `DELETE FROM organizations WHERE id = $1`
+	result, err := timed(s.db).ExecContext(ctx, query, id)
+	if err != nil {
+		logging.Errorf("Error deleting organization: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
+
+// SetMember adds a user to an organization, or changes their role when they are already a member
+func (s *PostgresOrganizationStore) SetMember(ctx context.Context, member models.OrganizationMember) (models.OrganizationMember, bool) {
+	query := `
+		INSERT INTO organization_members (organization_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, member.OrganizationID, member.UserID, member.Role).Scan(&member.CreatedAt, &member.UpdatedAt)
+	if err != nil {
+		logging.Errorf("Error setting organization member: %v", err)
+		return models.OrganizationMember{}, false
+	}
+
+	return member, true
+}
+
+// GetMembers returns the members of an organization in the order they joined
+func (s *PostgresOrganizationStore) GetMembers(ctx context.Context, organizationID int64) []models.OrganizationMember {
+	query := `
+		SELECT organization_id, user_id, role, created_at, updated_at
+		FROM organization_members
+		WHERE organization_id = $1
+		ORDER BY created_at, user_id
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logging.Errorf("Error querying organization members: %v", err)
+		return []models.OrganizationMember{}
+	}
+	defer rows.Close()
+
+	members := []models.OrganizationMember{}
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Role, &member.CreatedAt, &member.UpdatedAt); err != nil {
+			logging.Errorf("Error scanning organization member row: %v", err)
+			continue
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating organization member rows: %v", err)
+	}
+
+	return members
+}
+
+// RemoveMember removes a user from an organization in the database
+func (s *PostgresOrganizationStore) RemoveMember(ctx context.Context, organizationID int64, userID int64) bool {
+	query := `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+	result, err := timed(s.db).ExecContext(ctx, query, organizationID, userID)
+	if err != nil {
+		logging.Errorf("Error removing organization member: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
+
+// CreateInvitation adds an invitation to an organization to the database
+func (s *PostgresOrganizationStore) CreateInvitation(ctx context.Context, invitation models.OrganizationInvitation) (models.OrganizationInvitation, bool) {
+	query := `
+		INSERT INTO organization_invitations (organization_id, email, role, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, invitation.OrganizationID, invitation.Email, invitation.Role,
+		hashInvitationToken(invitation.Token), invitation.ExpiresAt).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		logging.Errorf("Error creating organization invitation: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+
+	invitation.AcceptedAt = nil
+	return invitation, true
+}
+
+// GetInvitations returns the invitations to an organization in the order they were created
+func (s *PostgresOrganizationStore) GetInvitations(ctx context.Context, organizationID int64) []models.OrganizationInvitation {
+	query := `
+		SELECT id, organization_id, email, role, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE organization_id = $1
+		ORDER BY id
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query, organizationID)
+	if err != nil {
+		logging.Errorf("Error querying organization invitations: %v", err)
+		return []models.OrganizationInvitation{}
+	}
+	defer rows.Close()
+
+	invitations := []models.OrganizationInvitation{}
+	for rows.Next() {
+		var invitation models.OrganizationInvitation
+		if err := rows.Scan(&invitation.ID, &invitation.OrganizationID, &invitation.Email, &invitation.Role,
+			&invitation.ExpiresAt, &invitation.AcceptedAt, &invitation.CreatedAt); err != nil {
+			logging.Errorf("Error scanning organization invitation row: %v", err)
+			continue
+		}
+		invitations = append(invitations, invitation)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating organization invitation rows: %v", err)
+	}
+
+	return invitations
+}
+
+// GetInvitationByToken finds the invitation with the given token in the database
+func (s *PostgresOrganizationStore) GetInvitationByToken(ctx context.Context, token string) (models.OrganizationInvitation, bool) {
+	var invitation models.OrganizationInvitation
+	query := `
+		SELECT id, organization_id, email, role, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE token_hash = $1
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, hashInvitationToken(token)).Scan(
+		&invitation.ID,
+		&invitation.OrganizationID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Errorf("Error getting organization invitation: %v", err)
+		}
+		return models.OrganizationInvitation{}, false
+	}
+
+	return invitation, true
+}
+
+// AcceptInvitation marks an invitation accepted, unless it already was
+func (s *PostgresOrganizationStore) AcceptInvitation(ctx context.Context, organizationID int64, id int64, acceptedAt time.Time) bool {
+	query := `
+		UPDATE organization_invitations
+		SET accepted_at = $3
+		WHERE organization_id = $1 AND id = $2 AND accepted_at IS NULL
+	`
+	result, err := timed(s.db).ExecContext(ctx, query, organizationID, id, acceptedAt)
+	if err != nil {
+		logging.Errorf("Error accepting organization invitation: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
+
+// DeleteInvitation removes an invitation from the database, so its token can no longer be accepted
+func (s *PostgresOrganizationStore) DeleteInvitation(ctx context.Context, organizationID int64, id int64) bool {
+	query := `DELETE FROM organization_invitations WHERE organization_id = $1 AND id = $2`
+	result, err := timed(s.db).ExecContext(ctx, query, organizationID, id)
+	if err != nil {
+		logging.Errorf("Error deleting organization invitation: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
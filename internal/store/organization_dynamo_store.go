@@ -0,0 +1,554 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoOrganization is the DynamoDB representation of an organization
+type dynamoOrganization struct {
+	PK          string    `dynamodbav:"pk"`
+	SK          string    `dynamodbav:"sk"`
+	ID          int64     `dynamodbav:"id"`
+	Name        string    `dynamodbav:"name"`
+	Description string    `dynamodbav:"description,omitempty"`
+	CreatedAt   time.Time `dynamodbav:"created_at"`
+	UpdatedAt   time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to an organization
+func (r dynamoOrganization) toModel() models.Organization {
+	return models.Organization{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
+
+// dynamoOrganizationMember is the DynamoDB representation of an organization membership.
+// Memberships are partitioned by organization and keyed by user ID, so the members of an
+// organization are a single Query.
+type dynamoOrganizationMember struct {
+	PK             string    `dynamodbav:"pk"`
+	SK             string    `dynamodbav:"sk"`
+	OrganizationID int64     `dynamodbav:"organization_id"`
+	UserID         int64     `dynamodbav:"user_id"`
+	Role           string    `dynamodbav:"role"`
+	CreatedAt      time.Time `dynamodbav:"created_at"`
+	UpdatedAt      time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to an organization membership
+func (r dynamoOrganizationMember) toModel() models.OrganizationMember {
+	return models.OrganizationMember{
+		OrganizationID: r.OrganizationID,
+		UserID:         r.UserID,
+		Role:           r.Role,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
+
+// dynamoOrganizationMemberPartition returns the partition key holding the members of an organization
+func dynamoOrganizationMemberPartition(organizationID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityOrganizationMember, organizationID)
+}
+
+// dynamoInvitation is the DynamoDB representation of an organization invitation.
+// Invitations are partitioned by organization and keyed by ID, so the invitations to an
+// organization are a single Query. A second record, keyed by the token hash, points at
+// each invitation so it can be found by its token.
+type dynamoInvitation struct {
+	PK             string     `dynamodbav:"pk"`
+	SK             string     `dynamodbav:"sk"`
+	ID             int64      `dynamodbav:"id"`
+	OrganizationID int64      `dynamodbav:"organization_id"`
+	Email          string     `dynamodbav:"email"`
+	Role           string     `dynamodbav:"role"`
+	TokenHash      string     `dynamodbav:"token_hash"`
+	ExpiresAt      time.Time  `dynamodbav:"expires_at"`
+	AcceptedAt     *time.Time `dynamodbav:"accepted_at,omitempty"`
+	CreatedAt      time.Time  `dynamodbav:"created_at"`
+}
+
+// toModel converts the DynamoDB representation back to an organization invitation
+func (r dynamoInvitation) toModel() models.OrganizationInvitation {
+	return models.OrganizationInvitation{
+		ID:             r.ID,
+		OrganizationID: r.OrganizationID,
+		Email:          r.Email,
+		Role:           r.Role,
+		ExpiresAt:      r.ExpiresAt,
+		AcceptedAt:     r.AcceptedAt,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+// dynamoInvitationToken is the record that finds an invitation by the hash of its token
+type dynamoInvitationToken struct {
+	PK             string `dynamodbav:"pk"`
+	SK             string `dynamodbav:"sk"`
+	OrganizationID int64  `dynamodbav:"organization_id"`
+	InvitationID   int64  `dynamodbav:"invitation_id"`
+}
+
+// dynamoInvitationPartition returns the partition key holding the invitations to an organization
+func dynamoInvitationPartition(organizationID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityInvitation, organizationID)
+}
+
+// DynamoOrganizationStore provides DynamoDB storage operations for organizations and their members
+type DynamoOrganizationStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoOrganizationStore creates a new DynamoDB organization store using the given client and table
+func NewDynamoOrganizationStore(client *dynamodb.Client, table string) *DynamoOrganizationStore {
+	return &DynamoOrganizationStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateOrganization adds a new organization to the table
+func (s *DynamoOrganizationStore) CreateOrganization(ctx context.Context, organization models.Organization) models.Organization {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityOrganization)
+	if err != nil {
+		logging.Errorf("Error allocating organization ID: %v", err)
+		return models.Organization{}
+	}
+	organization.ID = id
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = organization.CreatedAt
+
+	record, err := attributevalue.MarshalMap(dynamoOrganization{
+		PK:          dynamoEntityOrganization,
+		SK:          dynamoSortKeyForID(organization.ID),
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		CreatedAt:   organization.CreatedAt,
+		UpdatedAt:   organization.UpdatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization: %v", err)
+		return models.Organization{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		logging.Errorf("Error creating organization: %v", err)
+		return models.Organization{}
+	}
+
+	return organization
+}
+
+// GetOrganization retrieves an organization by ID from the table
+func (s *DynamoOrganizationStore) GetOrganization(ctx context.Context, id int64) (models.Organization, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityOrganization, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting organization: %v", err)
+		return models.Organization{}, false
+	}
+	if output.Item == nil {
+		return models.Organization{}, false
+	}
+
+	var record dynamoOrganization
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling organization: %v", err)
+		return models.Organization{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllOrganizations returns all organizations from the table in ID order
+func (s *DynamoOrganizationStore) GetAllOrganizations(ctx context.Context) []models.Organization {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityOrganization},
+		},
+	})
+
+	organizations := []models.Organization{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying organizations: %v", err)
+			return []models.Organization{}
+		}
+
+		var records []dynamoOrganization
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling organizations: %v", err)
+			return []models.Organization{}
+		}
+		for _, record := range records {
+			organizations = append(organizations, record.toModel())
+		}
+	}
+
+	return organizations
+}
+
+// UpdateOrganization updates an existing organization in the table
+func (s *DynamoOrganizationStore) UpdateOrganization(ctx context.Context, id int64, organization models.Organization) (models.Organization, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":name":        organization.Name,
+		":description": organization.Description,
+		":updated_at":  time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization: %v", err)
+		return models.Organization{}, false
+	}
+
+	// Update in place so the creation time is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityOrganization, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET #name = :name, description = :description, updated_at = :updated_at"),
+		ConditionExpression:       aws.String("attribute_exists(pk)"),
+		ExpressionAttributeNames:  map[string]string{"#name": "name"},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating organization: %v", err)
+		}
+		return models.Organization{}, false
+	}
+
+	var record dynamoOrganization
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling organization: %v", err)
+		return models.Organization{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// DeleteOrganization removes an organization along with its memberships and invitations from the table
+func (s *DynamoOrganizationStore) DeleteOrganization(ctx context.Context, id int64) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoEntityOrganization, dynamoSortKeyForID(id)),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting organization: %v", err)
+		return false
+	}
+	if len(output.Attributes) == 0 {
+		return false
+	}
+
+	for _, member := range s.GetMembers(ctx, id) {
+		s.RemoveMember(ctx, id, member.UserID)
+	}
+	for _, invitation := range s.GetInvitations(ctx, id) {
+		s.DeleteInvitation(ctx, id, invitation.ID)
+	}
+	return true
+}
+
+// SetMember adds a user to an organization, or changes their role when they are already a member
+func (s *DynamoOrganizationStore) SetMember(ctx context.Context, member models.OrganizationMember) (models.OrganizationMember, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":organization_id": member.OrganizationID,
+		":user_id":         member.UserID,
+		":role":            member.Role,
+		":now":             time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization member: %v", err)
+		return models.OrganizationMember{}, false
+	}
+
+	// Update in place so the time existing members joined is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoOrganizationMemberPartition(member.OrganizationID), dynamoSortKeyForID(member.UserID)),
+		UpdateExpression: aws.String("SET organization_id = :organization_id, user_id = :user_id, #role = :role, " +
+			"created_at = if_not_exists(created_at, :now), updated_at = :now"),
+		ExpressionAttributeNames:  map[string]string{"#role": "role"},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		logging.Errorf("Error setting organization member: %v", err)
+		return models.OrganizationMember{}, false
+	}
+
+	var record dynamoOrganizationMember
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling organization member: %v", err)
+		return models.OrganizationMember{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetMembers returns the members of an organization in the order they joined
+func (s *DynamoOrganizationStore) GetMembers(ctx context.Context, organizationID int64) []models.OrganizationMember {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoOrganizationMemberPartition(organizationID)},
+		},
+	})
+
+	members := []models.OrganizationMember{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying organization members: %v", err)
+			return []models.OrganizationMember{}
+		}
+
+		var records []dynamoOrganizationMember
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling organization members: %v", err)
+			return []models.OrganizationMember{}
+		}
+		for _, record := range records {
+			members = append(members, record.toModel())
+		}
+	}
+
+	// Members sort by user ID in the table
+	slices.SortStableFunc(members, func(a, b models.OrganizationMember) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return members
+}
+
+// RemoveMember removes a user from an organization in the table
+func (s *DynamoOrganizationStore) RemoveMember(ctx context.Context, organizationID int64, userID int64) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoOrganizationMemberPartition(organizationID), dynamoSortKeyForID(userID)),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error removing organization member: %v", err)
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
+
+// CreateInvitation adds an invitation to an organization to the table, along with the
+// record that finds it by its token
+func (s *DynamoOrganizationStore) CreateInvitation(ctx context.Context, invitation models.OrganizationInvitation) (models.OrganizationInvitation, bool) {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityInvitation)
+	if err != nil {
+		logging.Errorf("Error allocating organization invitation ID: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+	invitation.ID = id
+	invitation.CreatedAt = time.Now()
+	invitation.AcceptedAt = nil
+	tokenHash := hashInvitationToken(invitation.Token)
+
+	record, err := attributevalue.MarshalMap(dynamoInvitation{
+		PK:             dynamoInvitationPartition(invitation.OrganizationID),
+		SK:             dynamoSortKeyForID(invitation.ID),
+		ID:             invitation.ID,
+		OrganizationID: invitation.OrganizationID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		TokenHash:      tokenHash,
+		ExpiresAt:      invitation.ExpiresAt,
+		CreatedAt:      invitation.CreatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization invitation: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+	token, err := attributevalue.MarshalMap(dynamoInvitationToken{
+		PK:             dynamoEntityInvitationToken,
+		SK:             tokenHash,
+		OrganizationID: invitation.OrganizationID,
+		InvitationID:   invitation.ID,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization invitation token: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+
+	// The organization must still exist when the invitation is written
+	_, err = s.client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{ConditionCheck: &types.ConditionCheck{
+				TableName:           aws.String(s.table),
+				Key:                 dynamoKey(dynamoEntityOrganization, dynamoSortKeyForID(invitation.OrganizationID)),
+				ConditionExpression: aws.String("attribute_exists(pk)"),
+			}},
+			{Put: &types.Put{TableName: aws.String(s.table), Item: record}},
+			{Put: &types.Put{TableName: aws.String(s.table), Item: token, ConditionExpression: aws.String("attribute_not_exists(pk)")}},
+		},
+	})
+	if err != nil {
+		logging.Errorf("Error creating organization invitation: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+
+	return invitation, true
+}
+
+// GetInvitations returns the invitations to an organization in the order they were created
+func (s *DynamoOrganizationStore) GetInvitations(ctx context.Context, organizationID int64) []models.OrganizationInvitation {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoInvitationPartition(organizationID)},
+		},
+	})
+
+	invitations := []models.OrganizationInvitation{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying organization invitations: %v", err)
+			return []models.OrganizationInvitation{}
+		}
+
+		var records []dynamoInvitation
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling organization invitations: %v", err)
+			return []models.OrganizationInvitation{}
+		}
+		for _, record := range records {
+			invitations = append(invitations, record.toModel())
+		}
+	}
+
+	return invitations
+}
+
+// GetInvitationByToken finds the invitation with the given token in the table
+func (s *DynamoOrganizationStore) GetInvitationByToken(ctx context.Context, token string) (models.OrganizationInvitation, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityInvitationToken, hashInvitationToken(token)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting organization invitation token: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+	if output.Item == nil {
+		return models.OrganizationInvitation{}, false
+	}
+
+	var pointer dynamoInvitationToken
+	if err := attributevalue.UnmarshalMap(output.Item, &pointer); err != nil {
+		logging.Errorf("Error unmarshalling organization invitation token: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+
+	output, err = s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoInvitationPartition(pointer.OrganizationID), dynamoSortKeyForID(pointer.InvitationID)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting organization invitation: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+	if output.Item == nil {
+		return models.OrganizationInvitation{}, false
+	}
+
+	var record dynamoInvitation
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling organization invitation: %v", err)
+		return models.OrganizationInvitation{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// AcceptInvitation marks an invitation accepted, unless it already was
+func (s *DynamoOrganizationStore) AcceptInvitation(ctx context.Context, organizationID int64, id int64, acceptedAt time.Time) bool {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":accepted_at": acceptedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling organization invitation: %v", err)
+		return false
+	}
+
+	_, err = s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoInvitationPartition(organizationID), dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET accepted_at = :accepted_at"),
+		ConditionExpression:       aws.String("attribute_exists(pk) AND attribute_not_exists(accepted_at)"),
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error accepting organization invitation: %v", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// DeleteInvitation removes an invitation and the record that finds it by its token from
+// the table, so its token can no longer be accepted
+func (s *DynamoOrganizationStore) DeleteInvitation(ctx context.Context, organizationID int64, id int64) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoInvitationPartition(organizationID), dynamoSortKeyForID(id)),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting organization invitation: %v", err)
+		return false
+	}
+	if len(output.Attributes) == 0 {
+		return false
+	}
+
+	var record dynamoInvitation
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling organization invitation: %v", err)
+		return true
+	}
+	_, err = s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityInvitationToken, record.TokenHash),
+	})
+	if err != nil {
+		logging.Errorf("Error deleting organization invitation token: %v", err)
+	}
+
+	return true
+}
@@ -0,0 +1,231 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryOrganizationStore provides in-memory storage operations for organizations and their members
+type MemoryOrganizationStore struct {
+	sync.RWMutex
+	organizations map[int64]models.Organization
+	members       map[int64][]models.OrganizationMember
+	nextID        int64
+	// invitations are kept with the hashes of their tokens, which are never returned
+	invitations      map[int64][]models.OrganizationInvitation
+	invitationTokens map[string]models.OrganizationInvitation
+	nextInvitationID int64
+}
+
+// NewMemoryOrganizationStore creates a new in-memory organization store
+func NewMemoryOrganizationStore() *MemoryOrganizationStore {
+	return &MemoryOrganizationStore{
+		organizations: make(map[int64]models.Organization),
+		members:       make(map[int64][]models.OrganizationMember),
+		nextID:        1,
+
+		invitations:      make(map[int64][]models.OrganizationInvitation),
+		invitationTokens: make(map[string]models.OrganizationInvitation),
+		nextInvitationID: 1,
+	}
+}
+
+// CreateOrganization adds a new organization to the in-memory store
+func (s *MemoryOrganizationStore) CreateOrganization(ctx context.Context, organization models.Organization) models.Organization {
+	s.Lock()
+	defer s.Unlock()
+
+	organization.ID = s.nextID
+	s.nextID++
+	organization.CreatedAt = time.Now()
+	organization.UpdatedAt = organization.CreatedAt
+
+	s.organizations[organization.ID] = organization
+	return organization
+}
+
+// GetOrganization retrieves an organization by ID from the in-memory store
+func (s *MemoryOrganizationStore) GetOrganization(ctx context.Context, id int64) (models.Organization, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	organization, exists := s.organizations[id]
+	return organization, exists
+}
+
+// GetAllOrganizations returns all organizations from the in-memory store in ID order
+func (s *MemoryOrganizationStore) GetAllOrganizations(ctx context.Context) []models.Organization {
+	s.RLock()
+	defer s.RUnlock()
+
+	organizations := make([]models.Organization, 0, len(s.organizations))
+	for _, organization := range s.organizations {
+		organizations = append(organizations, organization)
+	}
+	slices.SortFunc(organizations, func(a, b models.Organization) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return organizations
+}
+
+// UpdateOrganization updates an existing organization in the in-memory store
+func (s *MemoryOrganizationStore) UpdateOrganization(ctx context.Context, id int64, organization models.Organization) (models.Organization, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, exists := s.organizations[id]
+	if !exists {
+		return models.Organization{}, false
+	}
+
+	organization.ID = id
+	organization.CreatedAt = existing.CreatedAt
+	organization.UpdatedAt = time.Now()
+	s.organizations[id] = organization
+	return organization, true
+}
+
+// DeleteOrganization removes an organization along with its memberships and invitations from the in-memory store
+func (s *MemoryOrganizationStore) DeleteOrganization(ctx context.Context, id int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.organizations[id]; !exists {
+		return false
+	}
+
+	delete(s.organizations, id)
+	delete(s.members, id)
+	delete(s.invitations, id)
+	for hash, invitation := range s.invitationTokens {
+		if invitation.OrganizationID == id {
+			delete(s.invitationTokens, hash)
+		}
+	}
+	return true
+}
+
+// SetMember adds a user to an organization, or changes their role when they are already a member
+func (s *MemoryOrganizationStore) SetMember(ctx context.Context, member models.OrganizationMember) (models.OrganizationMember, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.organizations[member.OrganizationID]; !exists {
+		return models.OrganizationMember{}, false
+	}
+
+	member.UpdatedAt = time.Now()
+	member.CreatedAt = member.UpdatedAt
+	members := s.members[member.OrganizationID]
+	for i, existing := range members {
+		if existing.UserID == member.UserID {
+			member.CreatedAt = existing.CreatedAt
+			members[i] = member
+			return member, true
+		}
+	}
+	s.members[member.OrganizationID] = append(members, member)
+	return member, true
+}
+
+// GetMembers returns the members of an organization in the order they joined
+func (s *MemoryOrganizationStore) GetMembers(ctx context.Context, organizationID int64) []models.OrganizationMember {
+	s.RLock()
+	defer s.RUnlock()
+
+	return append([]models.OrganizationMember{}, s.members[organizationID]...)
+}
+
+// RemoveMember removes a user from an organization in the in-memory store
+func (s *MemoryOrganizationStore) RemoveMember(ctx context.Context, organizationID int64, userID int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	members := s.members[organizationID]
+	i := slices.IndexFunc(members, func(member models.OrganizationMember) bool { return member.UserID == userID })
+	if i < 0 {
+		return false
+	}
+	s.members[organizationID] = slices.Delete(members, i, i+1)
+	return true
+}
+
+// CreateInvitation adds an invitation to an organization in the in-memory store
+func (s *MemoryOrganizationStore) CreateInvitation(ctx context.Context, invitation models.OrganizationInvitation) (models.OrganizationInvitation, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.organizations[invitation.OrganizationID]; !exists {
+		return models.OrganizationInvitation{}, false
+	}
+
+	invitation.ID = s.nextInvitationID
+	s.nextInvitationID++
+	invitation.CreatedAt = time.Now()
+	invitation.AcceptedAt = nil
+
+	stored := invitation
+	stored.Token = ""
+	s.invitations[invitation.OrganizationID] = append(s.invitations[invitation.OrganizationID], stored)
+	s.invitationTokens[hashInvitationToken(invitation.Token)] = stored
+	return invitation, true
+}
+
+// GetInvitations returns the invitations to an organization in the order they were created
+func (s *MemoryOrganizationStore) GetInvitations(ctx context.Context, organizationID int64) []models.OrganizationInvitation {
+	s.RLock()
+	defer s.RUnlock()
+
+	return append([]models.OrganizationInvitation{}, s.invitations[organizationID]...)
+}
+
+// GetInvitationByToken finds the invitation with the given token in the in-memory store
+func (s *MemoryOrganizationStore) GetInvitationByToken(ctx context.Context, token string) (models.OrganizationInvitation, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	invitation, exists := s.invitationTokens[hashInvitationToken(token)]
+	if !exists {
+		return models.OrganizationInvitation{}, false
+	}
+	// The token index isn't updated on acceptance, so read the invitation itself
+	i := slices.IndexFunc(s.invitations[invitation.OrganizationID], func(other models.OrganizationInvitation) bool { return other.ID == invitation.ID })
+	return s.invitations[invitation.OrganizationID][i], true
+}
+
+// AcceptInvitation marks an invitation accepted, unless it already was
+func (s *MemoryOrganizationStore) AcceptInvitation(ctx context.Context, organizationID int64, id int64, acceptedAt time.Time) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	invitations := s.invitations[organizationID]
+	i := slices.IndexFunc(invitations, func(invitation models.OrganizationInvitation) bool { return invitation.ID == id })
+	if i < 0 || invitations[i].AcceptedAt != nil {
+		return false
+	}
+	invitations[i].AcceptedAt = &acceptedAt
+	return true
+}
+
+// DeleteInvitation removes an invitation from the in-memory store, so its token can no longer be accepted
+func (s *MemoryOrganizationStore) DeleteInvitation(ctx context.Context, organizationID int64, id int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	invitations := s.invitations[organizationID]
+	i := slices.IndexFunc(invitations, func(invitation models.OrganizationInvitation) bool { return invitation.ID == id })
+	if i < 0 {
+		return false
+	}
+	s.invitations[organizationID] = slices.Delete(invitations, i, i+1)
+	for hash, invitation := range s.invitationTokens {
+		if invitation.OrganizationID == organizationID && invitation.ID == id {
+			delete(s.invitationTokens, hash)
+		}
+	}
+	return true
+}
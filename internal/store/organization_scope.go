@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type organizationContextKey struct{}
+
+// WithOrganization returns a context whose scheduled item and todo listings only include
+// those belonging to the organization
+func WithOrganization(ctx context.Context, organizationID int64) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, organizationID)
+}
+
+// OrganizationFromContext returns the organization set by WithOrganization, and whether
+// there is one
+func OrganizationFromContext(ctx context.Context) (int64, bool) {
+	organizationID, ok := ctx.Value(organizationContextKey{}).(int64)
+	return organizationID, ok
+}
+
+// inScope reports whether an item or todo belonging to organizationID is listed in ctx
+func inScope(ctx context.Context, organizationID *int64) bool {
+	scope, scoped := OrganizationFromContext(ctx)
+	return !scoped || (organizationID != nil && *organizationID == scope)
+}
+
+// scopeDynamoQuery filters a listing query to the context's organization, if any
+func scopeDynamoQuery(ctx context.Context, input *dynamodb.QueryInput) {
+	if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+		input.FilterExpression, input.ExpressionAttributeValues = andDynamoCondition(input.FilterExpression, input.ExpressionAttributeValues,
+			"organization_id = :organization_id", map[string]types.AttributeValue{":organization_id": dynamoNumber(organizationID)})
+	}
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// OrganizationStore defines the interface for storage operations on organizations and their members
+type OrganizationStore interface {
+	CreateOrganization(ctx context.Context, organization models.Organization) models.Organization
+	GetOrganization(ctx context.Context, id int64) (models.Organization, bool)
+	GetAllOrganizations(ctx context.Context) []models.Organization
+	UpdateOrganization(ctx context.Context, id int64, organization models.Organization) (models.Organization, bool)
+	// DeleteOrganization removes an organization along with its memberships
+	DeleteOrganization(ctx context.Context, id int64) bool
+	// SetMember adds a user to an organization, or changes their role when they are already a member
+	SetMember(ctx context.Context, member models.OrganizationMember) (models.OrganizationMember, bool)
+	GetMembers(ctx context.Context, organizationID int64) []models.OrganizationMember
+	RemoveMember(ctx context.Context, organizationID int64, userID int64) bool
+	// CreateInvitation stores an invitation along with the hash of its token
+	CreateInvitation(ctx context.Context, invitation models.OrganizationInvitation) (models.OrganizationInvitation, bool)
+	// GetInvitations returns the invitations to an organization in the order they were created
+	GetInvitations(ctx context.Context, organizationID int64) []models.OrganizationInvitation
+	// GetInvitationByToken finds the invitation with the given token
+	GetInvitationByToken(ctx context.Context, token string) (models.OrganizationInvitation, bool)
+	// AcceptInvitation marks an invitation accepted, unless it already was
+	AcceptInvitation(ctx context.Context, organizationID int64, id int64, acceptedAt time.Time) bool
+	DeleteInvitation(ctx context.Context, organizationID int64, id int64) bool
+}
+
+// hashInvitationToken returns the hash invitation tokens are stored and looked up by, so
+// the tokens themselves never reach the database
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
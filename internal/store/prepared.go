@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"periodic-api/internal/logging"
+)
+
+// preparedStatementsEnabled tells the PostgreSQL stores whether to prepare their frequent
+// statements
+var preparedStatementsEnabled atomic.Bool
+
+func init() {
+	preparedStatementsEnabled.Store(true)
+}
+
+// PreparedStatementsFromEnv returns whether the PostgreSQL stores prepare their frequent
+// statements, from the DB_PREPARED_STATEMENTS environment variable (default: true)
+func PreparedStatementsFromEnv() bool {
+	valueStr := os.Getenv("DB_PREPARED_STATEMENTS")
+	if valueStr == "" {
+		return true
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		logging.Warnf("Invalid DB_PREPARED_STATEMENTS format, using default: true")
+		return true
+	}
+	return value
+}
+
+// SetPreparedStatements sets whether the PostgreSQL stores prepare their frequent
+// statements. Turn it off behind poolers that don't support prepared statements, such as
+// PgBouncer in transaction mode, or that pin connections using them, such as RDS Proxy.
+func SetPreparedStatements(enabled bool) {
+	preparedStatementsEnabled.Store(enabled)
+}
+
+// statementCache prepares the statements of a store on first use and keeps them for the
+// store's lifetime, so frequent statements aren't parsed and planned again on every call.
+// database/sql prepares each statement again on every connection it runs on.
+type statementCache struct {
+	db    *sql.DB
+	stmts sync.Map // query -> *sql.Stmt
+}
+
+// newStatementCache creates an empty statement cache for the given database
+func newStatementCache(db *sql.DB) *statementCache {
+	return &statementCache{db: db}
+}
+
+// querier returns a querier running statements as prepared statements, within the
+// transaction carried by ctx if there is one, timing them like querier does. Without
+// prepared statements it is the same as querier.
+func (c *statementCache) querier(ctx context.Context) dbQuerier {
+	if !preparedStatementsEnabled.Load() {
+		return querier(ctx, c.db)
+	}
+	tx, _ := ctx.Value(txContextKey{}).(*sql.Tx)
+	return timed(&preparedQuerier{cache: c, tx: tx})
+}
+
+// prepare returns the prepared statement for query, preparing it the first time
+func (c *statementCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if stmt, ok := c.stmts.Load(query); ok {
+		return stmt.(*sql.Stmt), nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the statement prepared first when concurrent calls both prepared it
+	if existing, loaded := c.stmts.LoadOrStore(query, stmt); loaded {
+		stmt.Close()
+		return existing.(*sql.Stmt), nil
+	}
+	return stmt, nil
+}
+
+// preparedQuerier runs statements through a statementCache. A statement that can't be
+// prepared is run directly instead, which reports the same error if the SQL is at fault.
+type preparedQuerier struct {
+	cache *statementCache
+	tx    *sql.Tx
+}
+
+// stmt returns the prepared statement for query, bound to the transaction if there is one
+func (q *preparedQuerier) stmt(ctx context.Context, query string) (*sql.Stmt, bool) {
+	stmt, err := q.cache.prepare(ctx, query)
+	if err != nil {
+		logging.Warnf("Failed to prepare statement, running it unprepared: %v", err)
+		return nil, false
+	}
+	if q.tx != nil {
+		return q.tx.StmtContext(ctx, stmt), true
+	}
+	return stmt, true
+}
+
+// direct returns the transaction or database to run statements on unprepared
+func (q *preparedQuerier) direct() dbQuerier {
+	if q.tx != nil {
+		return q.tx
+	}
+	return q.cache.db
+}
+
+func (q *preparedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if stmt, ok := q.stmt(ctx, query); ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return q.direct().ExecContext(ctx, query, args...)
+}
+
+func (q *preparedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if stmt, ok := q.stmt(ctx, query); ok {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return q.direct().QueryContext(ctx, query, args...)
+}
+
+func (q *preparedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if stmt, ok := q.stmt(ctx, query); ok {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return q.direct().QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// preparingConnector is a database driver that counts the statements it prepares.
+// database/sql prepares every statement it runs on it, since its connections can't run
+// statements directly.
+type preparingConnector struct {
+	prepares atomic.Int64
+}
+
+func (c *preparingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return preparingConn{c}, nil
+}
+
+func (c *preparingConnector) Driver() driver.Driver {
+	return preparingDriver{c}
+}
+
+type preparingDriver struct {
+	connector *preparingConnector
+}
+
+func (d preparingDriver) Open(name string) (driver.Conn, error) {
+	return preparingConn(d), nil
+}
+
+type preparingConn struct {
+	connector *preparingConnector
+}
+
+func (c preparingConn) Prepare(query string) (driver.Stmt, error) {
+	c.connector.prepares.Add(1)
+	return preparedStmt{}, nil
+}
+
+func (c preparingConn) Close() error { return nil }
+
+func (c preparingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type preparedStmt struct{}
+
+func (preparedStmt) Close() error  { return nil }
+func (preparedStmt) NumInput() int { return -1 }
+
+func (preparedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (preparedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+func TestStatementCacheReusesStatements(t *testing.T) {
+	defer SetPreparedStatements(true)
+
+	connector := &preparingConnector{}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	statements := newStatementCache(db)
+	query := `UPDATE things SET note = $1 WHERE id = $2`
+	for range 3 {
+		if _, err := statements.querier(context.Background()).ExecContext(context.Background(), query, "note", 1); err != nil {
+			t.Fatalf("ExecContext failed: %v", err)
+		}
+	}
+	if prepares := connector.prepares.Load(); prepares != 1 {
+		t.Errorf("Expected the statement to be prepared once, got %d", prepares)
+	}
+
+	// Without prepared statements every run prepares it again
+	SetPreparedStatements(false)
+	for range 2 {
+		if _, err := statements.querier(context.Background()).ExecContext(context.Background(), query, "note", 1); err != nil {
+			t.Fatalf("ExecContext failed: %v", err)
+		}
+	}
+	if prepares := connector.prepares.Load(); prepares != 3 {
+		t.Errorf("Expected 2 more prepares without the cache, got %d in all", prepares)
+	}
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultReplicaMaxLag is how far a read replica may fall behind the primary before
+	// reads go back to the primary when DB_READ_MAX_LAG is not set
+	defaultReplicaMaxLag = 5 * time.Second
+	// replicaLagInterval is how often the replica's lag is measured
+	replicaLagInterval = time.Second
+)
+
+// replicaLagQuery measures how far the replica's replay is behind the primary. A replica
+// that has replayed everything it received is caught up however long ago the last write
+// was, and a server that isn't in recovery is the primary itself.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END
+`
+
+// readReplica is the replica the PostgreSQL stores send their listing and lookup queries
+// to; nil sends everything to the primary
+var readReplica atomic.Pointer[ReadReplica]
+
+// ReadReplica is a read-only PostgreSQL replica of the primary database. It is only read
+// from while its lag, measured by Monitor, is within the maximum; until the first
+// measurement, when a measurement fails and while it lags further behind, reads go to the
+// primary.
+type ReadReplica struct {
+	db     *sql.DB
+	maxLag time.Duration
+
+	// lag holds the last measured lag in nanoseconds, or -1 when it is unknown
+	lag atomic.Int64
+	// reads and fallbacks count the queries sent to the replica and those sent to the
+	// primary because the replica was unusable
+	reads     atomic.Uint64
+	fallbacks atomic.Uint64
+}
+
+// NewReadReplica creates a replica reading from db while it lags at most maxLag behind
+func NewReadReplica(db *sql.DB, maxLag time.Duration) *ReadReplica {
+	replica := &ReadReplica{db: db, maxLag: maxLag}
+	replica.lag.Store(-1)
+	return replica
+}
+
+// ReplicaMaxLagFromEnv returns how far a read replica may fall behind the primary before
+// reads go back to the primary, from the DB_READ_MAX_LAG environment variable
+func ReplicaMaxLagFromEnv() time.Duration {
+	valueStr := os.Getenv("DB_READ_MAX_LAG")
+	if valueStr == "" {
+		return defaultReplicaMaxLag
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value < 0 {
+		logging.Warnf("Invalid DB_READ_MAX_LAG format, using default: %v", defaultReplicaMaxLag)
+		return defaultReplicaMaxLag
+	}
+	return value
+}
+
+// SetReadReplica makes the PostgreSQL stores read from replica, or only from the primary
+// when it is nil
+func SetReadReplica(replica *ReadReplica) {
+	readReplica.Store(replica)
+}
+
+// Monitor measures the replica's lag every second until the context is cancelled
+func (r *ReadReplica) Monitor(ctx context.Context) {
+	ticker := time.NewTicker(replicaLagInterval)
+	defer ticker.Stop()
+
+	for {
+		r.measure(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// measure records the replica's current lag, or that it is unknown when the replica
+// can't be queried, logging when the replica becomes unusable or usable again
+func (r *ReadReplica) measure(ctx context.Context) {
+	wasUsable := r.usable()
+
+	queryCtx, cancel := context.WithTimeout(ctx, replicaLagInterval)
+	defer cancel()
+	var seconds float64
+	if err := r.db.QueryRowContext(queryCtx, replicaLagQuery).Scan(&seconds); err != nil {
+		if ctx.Err() != nil {
+			// Shutting down
+			return
+		}
+		r.lag.Store(-1)
+		if wasUsable {
+			logging.Warnf("Failed to measure the read replica's lag, reading from the primary: %v", err)
+		}
+		return
+	}
+	r.lag.Store(int64(seconds * float64(time.Second)))
+
+	if usable := r.usable(); usable != wasUsable {
+		if usable {
+			logging.Infof("Read replica is %v behind the primary, reading from it", r.Lag())
+		} else {
+			logging.Warnf("Read replica is %v behind the primary, reading from the primary until it catches up", r.Lag())
+		}
+	}
+}
+
+// Lag returns the last measured lag of the replica, or -1 when it is unknown
+func (r *ReadReplica) Lag() time.Duration {
+	return time.Duration(r.lag.Load())
+}
+
+// usable reports whether the replica's last measured lag is within the maximum
+func (r *ReadReplica) usable() bool {
+	lag := r.lag.Load()
+	return lag >= 0 && time.Duration(lag) <= r.maxLag
+}
+
+// WriteReplicaMetrics writes the read replica's lag and the queries sent to it, in the
+// Prometheus text exposition format. It writes nothing without a replica.
+func WriteReplicaMetrics(w io.Writer) {
+	replica := readReplica.Load()
+	if replica == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "# HELP db_replica_lag_seconds Last measured lag of the read replica behind the primary; -1 when unknown.\n")
+	fmt.Fprintf(w, "# TYPE db_replica_lag_seconds gauge\n")
+	lag := replica.Lag().Seconds()
+	if lag < 0 {
+		lag = -1
+	}
+	fmt.Fprintf(w, "db_replica_lag_seconds %s\n", strconv.FormatFloat(lag, 'g', -1, 64))
+
+	fmt.Fprintf(w, "# HELP db_replica_reads_total Queries sent to the read replica.\n")
+	fmt.Fprintf(w, "# TYPE db_replica_reads_total counter\n")
+	fmt.Fprintf(w, "db_replica_reads_total %d\n", replica.reads.Load())
+
+	fmt.Fprintf(w, "# HELP db_replica_fallbacks_total Queries sent to the primary because the read replica was unavailable or lagging.\n")
+	fmt.Fprintf(w, "# TYPE db_replica_fallbacks_total counter\n")
+	fmt.Fprintf(w, "db_replica_fallbacks_total %d\n", replica.fallbacks.Load())
+}
+
+// primaryContextKey is the context key marking operations that must read from the primary
+type primaryContextKey struct{}
+
+// WithPrimary returns a context whose store operations read from the primary even when
+// there is a read replica, for callers that can't tolerate replication lag, such as the
+// scheduler looking for due items
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// readQuerier returns the querier for a listing or lookup: the transaction carried by
+// ctx if there is one, otherwise the read replica while it is caught up, and db when there
+// is no replica, it lags behind or ctx asks for the primary. Statements are timed like
+// querier's.
+func readQuerier(ctx context.Context, db *sql.DB) dbQuerier {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return timed(tx)
+	}
+	replica := readReplica.Load()
+	if replica == nil {
+		return timed(db)
+	}
+	if primary, _ := ctx.Value(primaryContextKey{}).(bool); primary {
+		return timed(db)
+	}
+	if !replica.usable() {
+		replica.fallbacks.Add(1)
+		return timed(db)
+	}
+	replica.reads.Add(1)
+	return timed(replica.db)
+}
@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// lagConnector is a database driver answering every query with a single value, the
+// replica's lag in seconds, or failing while err is set
+type lagConnector struct {
+	seconds atomic.Value // float64
+	err     atomic.Value // error
+}
+
+func (c *lagConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return lagConn{c}, nil
+}
+
+func (c *lagConnector) Driver() driver.Driver {
+	return nil
+}
+
+type lagConn struct {
+	connector *lagConnector
+}
+
+func (c lagConn) Prepare(query string) (driver.Stmt, error) {
+	return lagStmt(c), nil
+}
+
+func (c lagConn) Close() error { return nil }
+
+func (c lagConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type lagStmt struct {
+	connector *lagConnector
+}
+
+func (s lagStmt) Close() error  { return nil }
+func (s lagStmt) NumInput() int { return -1 }
+
+func (s lagStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("statements are not supported")
+}
+
+func (s lagStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err, _ := s.connector.err.Load().(error); err != nil {
+		return nil, err
+	}
+	return &lagRows{value: s.connector.seconds.Load().(float64)}, nil
+}
+
+type lagRows struct {
+	value float64
+	done  bool
+}
+
+func (r *lagRows) Columns() []string { return []string{"lag"} }
+func (r *lagRows) Close() error      { return nil }
+
+func (r *lagRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+// queriedDB returns the database a querier returned by readQuerier runs its statements on
+func queriedDB(q dbQuerier) dbQuerier {
+	return q.(*timedQuerier).dbQuerier
+}
+
+func TestReadQuerierRoutesToCaughtUpReplica(t *testing.T) {
+	primary := sql.OpenDB(&lagConnector{})
+	defer primary.Close()
+	connector := &lagConnector{}
+	connector.seconds.Store(0.5)
+	replicaDB := sql.OpenDB(connector)
+	defer replicaDB.Close()
+
+	ctx := context.Background()
+	if queriedDB(readQuerier(ctx, primary)) != primary {
+		t.Error("Expected reads from the primary without a replica")
+	}
+
+	replica := NewReadReplica(replicaDB, 5*time.Second)
+	SetReadReplica(replica)
+	defer SetReadReplica(nil)
+
+	if queriedDB(readQuerier(ctx, primary)) != primary {
+		t.Error("Expected reads from the primary until the replica's lag is measured")
+	}
+
+	replica.measure(ctx)
+	if queriedDB(readQuerier(ctx, primary)) != replicaDB {
+		t.Errorf("Expected reads from the replica %v behind", replica.Lag())
+	}
+	if queriedDB(readQuerier(WithPrimary(ctx), primary)) != primary {
+		t.Error("Expected reads from the primary when the context asks for it")
+	}
+	if queriedDB(querier(ctx, primary)) != primary {
+		t.Error("Expected writes to go to the primary")
+	}
+
+	connector.seconds.Store(10.0)
+	replica.measure(ctx)
+	if queriedDB(readQuerier(ctx, primary)) != primary {
+		t.Errorf("Expected reads from the primary with the replica %v behind", replica.Lag())
+	}
+
+	connector.seconds.Store(0.0)
+	replica.measure(ctx)
+	connector.err.Store(errors.New("connection refused"))
+	replica.measure(ctx)
+	if queriedDB(readQuerier(ctx, primary)) != primary || replica.Lag() != -1 {
+		t.Errorf("Expected reads from the primary when the lag can't be measured, got lag %v", replica.Lag())
+	}
+
+	var metrics strings.Builder
+	WriteReplicaMetrics(&metrics)
+	for _, expected := range []string{"db_replica_lag_seconds -1\n", "db_replica_reads_total 1\n", "db_replica_fallbacks_total 3\n"} {
+		if !strings.Contains(metrics.String(), expected) {
+			t.Errorf("Expected %q in metrics:\n%s", expected, metrics.String())
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"periodic-api/internal/models"
+)
+
+// Restorer inserts records with the IDs and timestamps they were exported with, where the
+// stores would assign new ones, for restoring backups. Records join the transaction
+// carried by the context.
+type Restorer interface {
+	RestoreUser(ctx context.Context, user models.User) error
+	RestoreTodoItem(ctx context.Context, item models.TodoItem) error
+	RestoreScheduledItem(ctx context.Context, item models.ScheduledItem) error
+	RestoreExecutionLog(ctx context.Context, log models.ExecutionLog) error
+	// Finish makes the IDs assigned from then on follow those restored
+	Finish(ctx context.Context) error
+}
+
+// PostgresRestorer restores records into the PostgreSQL tables
+type PostgresRestorer struct {
+	db *sql.DB
+}
+
+// NewPostgresRestorer creates a new restorer for the given database connection
+func NewPostgresRestorer(db *sql.DB) *PostgresRestorer {
+	return &PostgresRestorer{
+		db: db,
+	}
+}
+
+// RestoreUser inserts a user in the context's tenant with its ID
+func (r *PostgresRestorer) RestoreUser(ctx context.Context, user models.User) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO users
+		(id, username, password_hash, email, deactivated_at, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, user.ID, user.Username, user.PasswordHash, user.Email, user.DeactivatedAt, TenantFromContext(ctx), user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("could not restore user %d: %w", user.ID, err)
+	}
+	return nil
+}
+
+// RestoreTodoItem inserts a todo item in the context's tenant with its ID
+func (r *PostgresRestorer) RestoreTodoItem(ctx context.Context, item models.TodoItem) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO todo_items
+		(id, text, checked, organization_id, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, item.ID, item.Text, item.Checked, item.OrganizationID, TenantFromContext(ctx), item.CreatedAt, item.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("could not restore todo item %d: %w", item.ID, err)
+	}
+	return nil
+}
+
+// RestoreScheduledItem inserts a scheduled item in the context's tenant with its ID and
+// version
+func (r *PostgresRestorer) RestoreScheduledItem(ctx context.Context, item models.ScheduledItem) error {
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+	var actionConfig interface{}
+	if len(item.ActionConfig) > 0 {
+		actionConfig = string(item.ActionConfig)
+	}
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO scheduled_items
+		(id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at, paused)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+	`,
+		item.ID,
+		item.Title,
+		item.Description,
+		item.StartsAt,
+		item.Repeats,
+		item.CronExpression,
+		item.Expiration,
+		item.NextExecutionAt,
+		item.ActionType,
+		actionConfig,
+		item.JitterSeconds,
+		encodeNotifications(item.Notifications),
+		item.RequestID,
+		item.OrganizationID,
+		item.OwnerID,
+		TenantFromContext(ctx),
+		item.Version,
+		item.CreatedAt,
+		item.UpdatedAt,
+		item.Paused,
+	)
+	if err != nil {
+		return fmt.Errorf("could not restore scheduled item %d: %w", item.ID, err)
+	}
+	return nil
+}
+
+// RestoreExecutionLog inserts an execution log in the context's tenant with its ID
+func (r *PostgresRestorer) RestoreExecutionLog(ctx context.Context, log models.ExecutionLog) error {
+	_, err := querier(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO execution_logs
+		(id, scheduled_item_id, executed_at, status, error_message, todo_item_id, execution_key, request_id, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, log.ID, log.ScheduledItemID, log.ExecutedAt, log.Status, log.ErrorMessage, log.TodoItemID, log.ExecutionKey, log.RequestID, TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("could not restore execution log %d: %w", log.ID, err)
+	}
+	return nil
+}
+
+// restoredTables are the tables whose ID sequences Finish moves past the restored IDs
+var restoredTables = []string{"users", "todo_items", "scheduled_items", "execution_logs"}
+
+// Finish moves the ID sequences past the highest restored IDs, so records created later
+// don't collide with them
+func (r *PostgresRestorer) Finish(ctx context.Context) error {
+	for _, table := range restoredTables {
+		_, err := querier(ctx, r.db).ExecContext(ctx, fmt.Sprintf(
+			`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), GREATEST(MAX(id), 1), MAX(id) IS NOT NULL) FROM %[1]s`, table))
+		if err != nil {
+			return fmt.Errorf("could not move the %s ID sequence: %w", table, err)
+		}
+	}
+	return nil
+}
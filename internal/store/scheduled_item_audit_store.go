@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// AuditingScheduledItemStore records the changes made through another scheduled item store
+// in the audit log, with the item before and after each change. Changes made without an
+// actor, such as the scheduler's, pass straight through.
+type AuditingScheduledItemStore struct {
+	ScheduledItemStore
+	audit AuditLogStore
+}
+
+// NewAuditingScheduledItemStore wraps the given store so its changes are recorded in auditStore
+func NewAuditingScheduledItemStore(store ScheduledItemStore, auditStore AuditLogStore) *AuditingScheduledItemStore {
+	return &AuditingScheduledItemStore{
+		ScheduledItemStore: store,
+		audit:              auditStore,
+	}
+}
+
+// CreateScheduledItem creates the item and audits its creation
+func (s *AuditingScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
+	createdItem := s.ScheduledItemStore.CreateScheduledItem(ctx, item)
+	if createdItem.ID != 0 {
+		recordAudit(ctx, s.audit, models.AuditActionCreate, models.AuditEntityScheduledItem, createdItem.ID, nil, createdItem)
+	}
+	return createdItem
+}
+
+// UpdateScheduledItem updates the item and audits the change
+func (s *AuditingScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	if ActorFromContext(ctx) == "" {
+		return s.ScheduledItemStore.UpdateScheduledItem(ctx, id, item)
+	}
+
+	before, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	updatedItem, err := s.ScheduledItemStore.UpdateScheduledItem(ctx, id, item)
+	if err == nil {
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityScheduledItem, id, before, updatedItem)
+	}
+	return updatedItem, err
+}
+
+// UpdateNextExecutionAt moves the next execution and audits the change
+func (s *AuditingScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	if ActorFromContext(ctx) == "" {
+		return s.ScheduledItemStore.UpdateNextExecutionAt(ctx, id, nextExecutionAt)
+	}
+
+	before, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	updated := s.ScheduledItemStore.UpdateNextExecutionAt(ctx, id, nextExecutionAt)
+	if updated {
+		after, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityScheduledItem, id, before, after)
+	}
+	return updated
+}
+
+// UpdateNextExecutionAtBatch updates the items' next execution times and audits the change
+// of each
+func (s *AuditingScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	if ActorFromContext(ctx) == "" {
+		return s.ScheduledItemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions)
+	}
+
+	before := make(map[int64]models.ScheduledItem, len(nextExecutions))
+	for id := range nextExecutions {
+		before[id], _ = s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	}
+	if err := s.ScheduledItemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions); err != nil {
+		return err
+	}
+	for id := range nextExecutions {
+		after, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityScheduledItem, id, before[id], after)
+	}
+	return nil
+}
+
+// DeleteScheduledItem deletes the item and audits its deletion
+func (s *AuditingScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	if ActorFromContext(ctx) == "" {
+		return s.ScheduledItemStore.DeleteScheduledItem(ctx, id)
+	}
+
+	before, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	deleted := s.ScheduledItemStore.DeleteScheduledItem(ctx, id)
+	if deleted {
+		recordAudit(ctx, s.audit, models.AuditActionDelete, models.AuditEntityScheduledItem, id, before, nil)
+	}
+	return deleted
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+)
+
+// CachedScheduledItemStore is a read-through cache in front of another scheduled item store.
+// Single items and the full listing are cached; writes made through this store invalidate
+// the affected entries once their transaction commits, while writes made by other
+// processes become visible once the TTL expires. Reads within a transaction bypass the
+// cache, so they see the transaction's own writes and don't cache uncommitted ones. The next items, which dashboards poll, are cached for the shorter PollTTL
+// since items fall due and the scheduler reschedules them all the time; claims always go
+// to the underlying store.
+type CachedScheduledItemStore struct {
+	store ScheduledItemStore
+	items *lruCache[int64, models.ScheduledItem]
+	all   *lruCache[string, []models.ScheduledItem]
+	// next is nil when PollTTL is zero
+	next *lruCache[nextItemsKey, []models.ScheduledItem]
+}
+
+// nextItemsKey identifies a cached request for the next scheduled items
+type nextItemsKey struct {
+	tenant string
+	limit  int
+	offset int64
+}
+
+// NewCachedScheduledItemStore wraps the given store with a read-through cache
+func NewCachedScheduledItemStore(store ScheduledItemStore, config CacheConfig) *CachedScheduledItemStore {
+	s := &CachedScheduledItemStore{
+		store: store,
+		items: newLRUCache[int64, models.ScheduledItem](config),
+		all:   newLRUCache[string, []models.ScheduledItem](config),
+	}
+	if config.PollTTL > 0 {
+		s.next = newLRUCache[nextItemsKey, []models.ScheduledItem](config.pollConfig())
+	}
+	return s
+}
+
+// CreateScheduledItem creates the item in the underlying store and invalidates the listings
+func (s *CachedScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
+	createdItem := s.store.CreateScheduledItem(ctx, item)
+	s.invalidateListings(ctx)
+	return createdItem
+}
+
+// GetScheduledItem returns the cached item, loading it from the underlying store on a miss
+func (s *CachedScheduledItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
+	if inTransaction(ctx) {
+		return s.store.GetScheduledItem(ctx, id)
+	}
+	if item, ok := s.items.get(id); ok && ownTenant(ctx, item.TenantID) {
+		return item, true
+	}
+
+	// Load from the primary, so a replica lagging behind the write that evicted the entry
+	// isn't cached until it expires
+	item, exists := s.store.GetScheduledItem(WithPrimary(ctx), id)
+	if exists {
+		s.items.set(id, item)
+	}
+	return item, exists
+}
+
+// GetAllScheduledItems returns the cached listing, loading it from the underlying store on a
+// miss. Listings are cached per tenant, and listings scoped to an organization aren't cached.
+func (s *CachedScheduledItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
+	if _, scoped := OrganizationFromContext(ctx); scoped || inTransaction(ctx) {
+		return s.store.GetAllScheduledItems(ctx)
+	}
+	if items, ok := s.all.get(TenantFromContext(ctx)); ok {
+		return slices.Clone(items)
+	}
+
+	items := s.store.GetAllScheduledItems(WithPrimary(ctx))
+	s.all.set(TenantFromContext(ctx), slices.Clone(items))
+	return items
+}
+
+// StreamScheduledItems streams the cached listing, or on a miss the underlying store's listing
+// as it is read, caching it once the stream has been read to the end. Listings scoped to
+// an organization or read within a transaction aren't cached and stream from the
+// underlying store.
+func (s *CachedScheduledItemStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	if _, scoped := OrganizationFromContext(ctx); scoped || inTransaction(ctx) {
+		return s.store.StreamScheduledItems(ctx)
+	}
+	return streamThrough(s.all, TenantFromContext(ctx), func() iter.Seq2[models.ScheduledItem, error] {
+		return s.store.StreamScheduledItems(WithPrimary(ctx))
+	})
+}
+
+// GetScheduledItemsPage is not cached; pages are read from the underlying store, which
+// seeks to the cursor itself
+func (s *CachedScheduledItemStore) GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error) {
+	return s.store.GetScheduledItemsPage(ctx, limit, cursor)
+}
+
+// GetNextScheduledItems returns the cached next items, loading them from the underlying
+// store on a miss. They are cached per tenant, limit and offset for PollTTL, so items that
+// fall due in the meantime show up once it expires.
+func (s *CachedScheduledItemStore) GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error) {
+	if s.next == nil || inTransaction(ctx) {
+		return s.store.GetNextScheduledItems(ctx, limit, offset)
+	}
+	key := nextItemsKey{tenant: TenantFromContext(ctx), limit: limit, offset: offset}
+	if items, ok := s.next.get(key); ok {
+		return slices.Clone(items), nil
+	}
+
+	items, err := s.store.GetNextScheduledItems(ctx, limit, offset)
+	if err != nil {
+		return items, err
+	}
+	s.next.set(key, slices.Clone(items))
+	return items, nil
+}
+
+// ClaimDueItems is not cached since claims must be made against the underlying store
+func (s *CachedScheduledItemStore) ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error) {
+	return s.store.ClaimDueItems(ctx, limit, lease)
+}
+
+// UpdateScheduledItem updates the item in the underlying store and invalidates it
+func (s *CachedScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	updatedItem, err := s.store.UpdateScheduledItem(ctx, id, item)
+	s.invalidate(ctx, id)
+	return updatedItem, err
+}
+
+// UpdateNextExecutionAt updates the item in the underlying store and invalidates it
+func (s *CachedScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	updated := s.store.UpdateNextExecutionAt(ctx, id, nextExecutionAt)
+	s.invalidate(ctx, id)
+	return updated
+}
+
+// UpdateNextExecutionAtBatch updates the items in the underlying store and invalidates them
+func (s *CachedScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	err := s.store.UpdateNextExecutionAtBatch(ctx, nextExecutions)
+	AfterCommit(ctx, func() {
+		for id := range nextExecutions {
+			s.items.delete(id)
+		}
+		s.dropListings(TenantFromContext(ctx))
+	})
+	return err
+}
+
+// DeleteScheduledItem deletes the item from the underlying store and invalidates it
+func (s *CachedScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	deleted := s.store.DeleteScheduledItem(ctx, id)
+	s.invalidate(ctx, id)
+	return deleted
+}
+
+// Stats returns the combined hit and miss counts of the item and listing caches
+func (s *CachedScheduledItemStore) Stats() CacheStats {
+	return addCacheStats(s.items.stats(), s.all.stats())
+}
+
+// NextStats returns the hit and miss counts of the next items cache
+func (s *CachedScheduledItemStore) NextStats() CacheStats {
+	if s.next == nil {
+		return CacheStats{}
+	}
+	return s.next.stats()
+}
+
+// invalidate drops a cached item along with the tenant's listings that contain it once
+// the transaction carried by ctx commits. Dropping them any earlier would let a concurrent
+// read cache the item as it was before the write until the TTL expires.
+func (s *CachedScheduledItemStore) invalidate(ctx context.Context, id int64) {
+	AfterCommit(ctx, func() {
+		s.items.delete(id)
+		s.dropListings(TenantFromContext(ctx))
+	})
+}
+
+// invalidateListings drops the tenant's cached listing and next items once the transaction
+// carried by ctx commits
+func (s *CachedScheduledItemStore) invalidateListings(ctx context.Context) {
+	AfterCommit(ctx, func() { s.dropListings(TenantFromContext(ctx)) })
+}
+
+// dropListings drops the tenant's cached listing and next items
+func (s *CachedScheduledItemStore) dropListings(tenant string) {
+	s.all.delete(tenant)
+	if s.next != nil {
+		s.next.deleteFunc(func(key nextItemsKey) bool { return key.tenant == tenant })
+	}
+}
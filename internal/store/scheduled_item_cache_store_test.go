@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"periodic-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestCachedScheduledItemStore(t *testing.T) {
+	ctx := context.Background()
+	underlying := NewMemoryScheduledItemStore()
+	store := NewCachedScheduledItemStore(underlying, CacheConfig{TTL: time.Minute, Size: 10})
+
+	created := store.CreateScheduledItem(ctx, models.ScheduledItem{
+		Title:    "Cached item",
+		StartsAt: time.Now().Add(time.Hour),
+	})
+
+	// First read misses, second read is served from the cache
+	store.GetScheduledItem(ctx, created.ID)
+	store.GetScheduledItem(ctx, created.ID)
+	if stats := store.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %+v", stats)
+	}
+
+	// Writes through the cache must invalidate the cached item and listing
+	if items := store.GetAllScheduledItems(ctx); len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	next := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	store.UpdateNextExecutionAt(ctx, created.ID, next)
+	if item, _ := store.GetScheduledItem(ctx, created.ID); !item.NextExecutionAt.Equal(next) {
+		t.Errorf("Expected next execution %v after invalidation, got %v", next, item.NextExecutionAt)
+	}
+
+	store.DeleteScheduledItem(ctx, created.ID)
+	if _, exists := store.GetScheduledItem(ctx, created.ID); exists {
+		t.Error("Expected deleted item to be evicted from the cache")
+	}
+	if items := store.GetAllScheduledItems(ctx); len(items) != 0 {
+		t.Errorf("Expected empty listing after delete, got %d items", len(items))
+	}
+}
+
+func TestCachedScheduledItemStoreInvalidatesOnCommit(t *testing.T) {
+	ctx := context.Background()
+	store := NewCachedScheduledItemStore(NewMemoryScheduledItemStore(), CacheConfig{TTL: time.Minute, Size: 10})
+	created := store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Cached item", StartsAt: time.Now().Add(time.Hour)})
+	store.GetScheduledItem(ctx, created.ID)
+
+	// A transaction as PostgresTransactor runs it, committed by running its hooks
+	hooks := &afterCommitHooks{}
+	txCtx := context.WithValue(ctx, afterCommitContextKey{}, hooks)
+	next := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	store.UpdateNextExecutionAt(txCtx, created.ID, next)
+
+	// The transaction reads its own write, while the rest keep the cached item until it commits
+	if item, _ := store.GetScheduledItem(txCtx, created.ID); !item.NextExecutionAt.Equal(next) {
+		t.Errorf("Expected the transaction to read its own write, got %v", item.NextExecutionAt)
+	}
+	if item, _ := store.GetScheduledItem(ctx, created.ID); item.NextExecutionAt.Equal(next) {
+		t.Error("Expected the cached item to stay until the transaction commits")
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	if item, _ := store.GetScheduledItem(ctx, created.ID); !item.NextExecutionAt.Equal(next) {
+		t.Errorf("Expected next execution %v once the transaction commits, got %v", next, item.NextExecutionAt)
+	}
+}
+
+func TestCachedScheduledItemStoreCachesDrainedStreams(t *testing.T) {
+	ctx := context.Background()
+	underlying := &countingStreamStore{ScheduledItemStore: NewMemoryScheduledItemStore()}
+	store := NewCachedScheduledItemStore(underlying, CacheConfig{TTL: time.Minute, Size: 10})
+	for i := 0; i < 3; i++ {
+		store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Streamed item", StartsAt: time.Now().Add(time.Hour)})
+	}
+
+	// Streams that fail or are stopped early aren't cached
+	underlying.fail = true
+	for range store.StreamScheduledItems(ctx) {
+	}
+	underlying.fail = false
+	for range store.StreamScheduledItems(ctx) {
+		break
+	}
+	if underlying.streams != 2 {
+		t.Fatalf("Expected 2 streams from the underlying store, got %d", underlying.streams)
+	}
+
+	// A stream read to the end is cached, and the next one is served from the cache
+	for _, count := range []int{0, 0} {
+		for _, err := range store.StreamScheduledItems(ctx) {
+			if err != nil {
+				t.Fatalf("Unexpected stream error: %v", err)
+			}
+			count++
+		}
+		if count != 3 {
+			t.Errorf("Expected 3 streamed items, got %d", count)
+		}
+	}
+	if underlying.streams != 3 {
+		t.Errorf("Expected the drained stream to be cached, got %d streams from the underlying store", underlying.streams)
+	}
+}
+
+// countingStreamStore counts the streams read from a scheduled item store, failing them
+// after the first item while fail is set
+type countingStreamStore struct {
+	ScheduledItemStore
+	streams int
+	fail    bool
+}
+
+func (s *countingStreamStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	s.streams++
+	fail := s.fail
+	return func(yield func(models.ScheduledItem, error) bool) {
+		for item, err := range s.ScheduledItemStore.StreamScheduledItems(ctx) {
+			if !yield(item, err) {
+				return
+			}
+			if fail {
+				yield(models.ScheduledItem{}, errors.New("connection reset"))
+				return
+			}
+		}
+	}
+}
+
+func TestCachedScheduledItemStoreCachesNextItems(t *testing.T) {
+	ctx := context.Background()
+	underlying := NewMemoryScheduledItemStore()
+	store := NewCachedScheduledItemStore(underlying, CacheConfig{TTL: time.Minute, Size: 10, PollTTL: 50 * time.Millisecond})
+
+	due := time.Now().Add(-time.Minute)
+	created := store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Due", StartsAt: due, NextExecutionAt: due})
+
+	store.GetNextScheduledItems(ctx, 10, 0)
+	if items, _ := store.GetNextScheduledItems(ctx, 10, 0); len(items) != 1 {
+		t.Fatalf("Expected 1 next item, got %d", len(items))
+	}
+	if stats := store.NextStats(); stats.Hits != 1 || stats.Misses != 1 || stats.HitRate != 0.5 {
+		t.Errorf("Expected 1 hit and 1 miss, got %+v", stats)
+	}
+
+	// Writes made by other processes show up once the poll TTL expires
+	underlying.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Also due", StartsAt: due, NextExecutionAt: due})
+	if items, _ := store.GetNextScheduledItems(ctx, 10, 0); len(items) != 1 {
+		t.Errorf("Expected the cached next item, got %d items", len(items))
+	}
+	time.Sleep(60 * time.Millisecond)
+	if items, _ := store.GetNextScheduledItems(ctx, 10, 0); len(items) != 2 {
+		t.Errorf("Expected 2 next items after the poll TTL, got %d", len(items))
+	}
+
+	// Writes through the cache invalidate the next items straight away
+	store.UpdateNextExecutionAt(ctx, created.ID, time.Now().Add(time.Hour))
+	if items, _ := store.GetNextScheduledItems(ctx, 10, 0); len(items) != 1 {
+		t.Errorf("Expected 1 next item after rescheduling, got %d", len(items))
+	}
+}
+
+func TestLRUCacheEvictionAndExpiry(t *testing.T) {
+	cache := newLRUCache[int, string](CacheConfig{TTL: 50 * time.Millisecond, Size: 2})
+
+	cache.set(1, "one")
+	cache.set(2, "two")
+	cache.get(1) // 1 is now the most recently used
+	cache.set(3, "three")
+
+	if _, ok := cache.get(2); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.get(1); !ok {
+		t.Error("Expected recently used entry to be kept")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := cache.get(3); ok {
+		t.Error("Expected entry to expire after the TTL")
+	}
+}
@@ -1,39 +1,59 @@
 package store
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"fmt"
+	"iter"
+	"maps"
+	"periodic-api/internal/logging"
 	"periodic-api/internal/models"
-	"sync"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // PostgresScheduledItemStore provides PostgreSQL storage operations for scheduled items
+// Its lookups and listings read from the read replica when one is set, see SetReadReplica.
 type PostgresScheduledItemStore struct {
-	sync.RWMutex
 	db *sql.DB
+	// statements holds the prepared statements of the scheduler's hot paths
+	statements *statementCache
 }
 
 // NewPostgresScheduledItemStore creates a new PostgreSQL store with the given database connection
 func NewPostgresScheduledItemStore(db *sql.DB) *PostgresScheduledItemStore {
 	return &PostgresScheduledItemStore{
-		db: db,
+		db:         db,
+		statements: newStatementCache(db),
 	}
 }
 
 // CreateScheduledItem adds a new scheduled item to the database
-func (s *PostgresScheduledItemStore) CreateScheduledItem(item models.ScheduledItem) models.ScheduledItem {
-	s.Lock()
-	defer s.Unlock()
-
+func (s *PostgresScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
 	query := `
 		INSERT INTO scheduled_items 
-		(title, description, starts_at, repeats, cron_expression, expiration, next_execution_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7) 
-		RETURNING id
+		(title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, notifications, request_id, organization_id, owner_id, tenant_id, paused) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) 
+		RETURNING id, version, created_at, updated_at
 	`
 
-	err := s.db.QueryRow(
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+	item.TenantID = TenantFromContext(ctx)
+
+	// Store a missing action config as NULL
+	var actionConfig interface{}
+	if len(item.ActionConfig) > 0 {
+		actionConfig = string(item.ActionConfig)
+	}
+
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
 		query,
 		item.Title,
 		item.Description,
@@ -42,10 +62,19 @@ func (s *PostgresScheduledItemStore) CreateScheduledItem(item models.ScheduledIt
 		item.CronExpression,
 		item.Expiration,
 		item.NextExecutionAt,
-	).Scan(&item.ID)
+		item.ActionType,
+		actionConfig,
+		item.JitterSeconds,
+		encodeNotifications(item.Notifications),
+		item.RequestID,
+		item.OrganizationID,
+		item.OwnerID,
+		item.TenantID,
+		item.Paused,
+	).Scan(&item.ID, &item.Version, &item.CreatedAt, &item.UpdatedAt)
 
 	if err != nil {
-		log.Printf("Error creating scheduled item: %v", err)
+		logging.Errorf("Error creating scheduled item: %v", err)
 		return models.ScheduledItem{} // Return empty item on error
 	}
 
@@ -53,21 +82,20 @@ func (s *PostgresScheduledItemStore) CreateScheduledItem(item models.ScheduledIt
 }
 
 // GetScheduledItem retrieves a scheduled item by ID from the database
-func (s *PostgresScheduledItemStore) GetScheduledItem(id int64) (models.ScheduledItem, bool) {
-	s.RLock()
-	defer s.RUnlock()
-
+func (s *PostgresScheduledItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
 	var item models.ScheduledItem
 	query := `
-		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at 
+		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at 
 		FROM scheduled_items 
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
 	var cronExpression sql.NullString
 	var expiration sql.NullTime
+	var actionConfig []byte
+	var notifications []byte
 
-	err := s.db.QueryRow(query, id).Scan(
+	err := readQuerier(ctx, s.db).QueryRowContext(ctx, query, id, TenantFromContext(ctx)).Scan(
 		&item.ID,
 		&item.Title,
 		&item.Description,
@@ -76,13 +104,25 @@ func (s *PostgresScheduledItemStore) GetScheduledItem(id int64) (models.Schedule
 		&cronExpression,
 		&expiration,
 		&item.NextExecutionAt,
+		&item.ActionType,
+		&actionConfig,
+		&item.JitterSeconds,
+		&item.Paused,
+		&notifications,
+		&item.RequestID,
+		&item.OrganizationID,
+		&item.OwnerID,
+		&item.TenantID,
+		&item.Version,
+		&item.CreatedAt,
+		&item.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.ScheduledItem{}, false
 		}
-		log.Printf("Error getting scheduled item: %v", err)
+		logging.Errorf("Error getting scheduled item: %v", err)
 		return models.ScheduledItem{}, false
 	}
 
@@ -93,23 +133,31 @@ func (s *PostgresScheduledItemStore) GetScheduledItem(id int64) (models.Schedule
 	if expiration.Valid {
 		item.Expiration = &expiration.Time
 	}
+	if actionConfig != nil {
+		item.ActionConfig = actionConfig
+	}
+	item.Notifications = decodeNotifications(notifications)
 
 	return item, true
 }
 
-// GetAllScheduledItems returns all scheduled items from the database
-func (s *PostgresScheduledItemStore) GetAllScheduledItems() []models.ScheduledItem {
-	s.RLock()
-	defer s.RUnlock()
-
+// GetAllScheduledItems returns all scheduled items from the database, or those of the
+// context's organization
+func (s *PostgresScheduledItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
 	query := `
-		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at 
+		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at 
 		FROM scheduled_items
+		WHERE tenant_id = $1
 	`
+	args := []any{TenantFromContext(ctx)}
+	if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+		query += `AND organization_id = $2`
+		args = append(args, organizationID)
+	}
 
-	rows, err := s.db.Query(query)
+	rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error querying scheduled items: %v", err)
+		logging.Errorf("Error querying scheduled items: %v", err)
 		return []models.ScheduledItem{}
 	}
 	defer rows.Close()
@@ -119,6 +167,8 @@ func (s *PostgresScheduledItemStore) GetAllScheduledItems() []models.ScheduledIt
 		var item models.ScheduledItem
 		var cronExpression sql.NullString
 		var expiration sql.NullTime
+		var actionConfig []byte
+		var notifications []byte
 
 		err := rows.Scan(
 			&item.ID,
@@ -129,10 +179,22 @@ func (s *PostgresScheduledItemStore) GetAllScheduledItems() []models.ScheduledIt
 			&cronExpression,
 			&expiration,
 			&item.NextExecutionAt,
+			&item.ActionType,
+			&actionConfig,
+			&item.JitterSeconds,
+			&item.Paused,
+			&notifications,
+			&item.RequestID,
+			&item.OrganizationID,
+			&item.OwnerID,
+			&item.TenantID,
+			&item.Version,
+			&item.CreatedAt,
+			&item.UpdatedAt,
 		)
 
 		if err != nil {
-			log.Printf("Error scanning row: %v", err)
+			logging.Errorf("Error scanning row: %v", err)
 			continue
 		}
 
@@ -143,77 +205,482 @@ func (s *PostgresScheduledItemStore) GetAllScheduledItems() []models.ScheduledIt
 		if expiration.Valid {
 			item.Expiration = &expiration.Time
 		}
+		if actionConfig != nil {
+			item.ActionConfig = actionConfig
+		}
+		item.Notifications = decodeNotifications(notifications)
 
 		items = append(items, item)
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
+		logging.Errorf("Error iterating rows: %v", err)
 	}
 
 	return items
 }
 
-// UpdateNextExecutionAt updates the next execution time for a scheduled item
-func (s *PostgresScheduledItemStore) UpdateNextExecutionAt(id int64, nextExecutionAt time.Time) bool {
-	s.Lock()
-	defer s.Unlock()
+// StreamScheduledItems streams the scheduled items of the database, or those of the
+// context's organization, a row at a time as they are read, so the listing is never held
+// in memory whole. The stream ends after the first error.
+func (s *PostgresScheduledItemStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	return func(yield func(models.ScheduledItem, error) bool) {
+		query := `
+			SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at 
+			FROM scheduled_items
+			WHERE tenant_id = $1
+		`
+		args := []any{TenantFromContext(ctx)}
+		if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+			query += `AND organization_id = $2`
+			args = append(args, organizationID)
+		}
+
+		rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(models.ScheduledItem{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var item models.ScheduledItem
+			var cronExpression sql.NullString
+			var expiration sql.NullTime
+			var actionConfig []byte
+			var notifications []byte
+
+			err := rows.Scan(
+				&item.ID,
+				&item.Title,
+				&item.Description,
+				&item.StartsAt,
+				&item.Repeats,
+				&cronExpression,
+				&expiration,
+				&item.NextExecutionAt,
+				&item.ActionType,
+				&actionConfig,
+				&item.JitterSeconds,
+				&item.Paused,
+				&notifications,
+				&item.RequestID,
+				&item.OrganizationID,
+				&item.OwnerID,
+				&item.TenantID,
+				&item.Version,
+				&item.CreatedAt,
+				&item.UpdatedAt,
+			)
+
+			if err != nil {
+				yield(models.ScheduledItem{}, err)
+				return
+			}
+
+			// Handle nullable fields
+			if cronExpression.Valid {
+				item.CronExpression = &cronExpression.String
+			}
+			if expiration.Valid {
+				item.Expiration = &expiration.Time
+			}
+			if actionConfig != nil {
+				item.ActionConfig = actionConfig
+			}
+			item.Notifications = decodeNotifications(notifications)
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(models.ScheduledItem{}, err)
+		}
+	}
+}
+
+// GetScheduledItemsPage returns a page of up to limit scheduled items in next execution
+// time order, ties broken by ID, starting after cursor, or from the start when it is nil.
+// The idx_scheduled_items_tenant_keyset index lets each page seek to its cursor however
+// deep into the listing it is. The returned cursor is nil when there are no further pages.
+func (s *PostgresScheduledItemStore) GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error) {
+	if limit < 1 {
+		return []models.ScheduledItem{}, nil, ErrInvalidLimit
+	}
 
-	query := `UPDATE scheduled_items SET next_execution_at = $1 WHERE id = $2`
+	query := `
+		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at 
+		FROM scheduled_items
+		WHERE tenant_id = $1
+	`
+	args := []any{TenantFromContext(ctx)}
+	if cursor != nil {
+		args = append(args, cursor.NextExecutionAt, cursor.ID)
+		query += fmt.Sprintf(`AND (next_execution_at, id) > ($%d, $%d) `, len(args)-1, len(args))
+	}
+	if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+		args = append(args, organizationID)
+		query += fmt.Sprintf(`AND organization_id = $%d `, len(args))
+	}
+	// Fetch one extra row to find out whether another page follows
+	args = append(args, limit+1)
+	query += fmt.Sprintf(`ORDER BY next_execution_at, id LIMIT $%d`, len(args))
 
-	result, err := s.db.Exec(query, nextExecutionAt, id)
+	rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error updating next execution time: %v", err)
+		return []models.ScheduledItem{}, nil, err
+	}
+	defer rows.Close()
+
+	var items []models.ScheduledItem
+	for rows.Next() {
+		var item models.ScheduledItem
+		var cronExpression sql.NullString
+		var expiration sql.NullTime
+		var actionConfig []byte
+		var notifications []byte
+
+		err := rows.Scan(
+			&item.ID,
+			&item.Title,
+			&item.Description,
+			&item.StartsAt,
+			&item.Repeats,
+			&cronExpression,
+			&expiration,
+			&item.NextExecutionAt,
+			&item.ActionType,
+			&actionConfig,
+			&item.JitterSeconds,
+			&item.Paused,
+			&notifications,
+			&item.RequestID,
+			&item.OrganizationID,
+			&item.OwnerID,
+			&item.TenantID,
+			&item.Version,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+
+		if err != nil {
+			return []models.ScheduledItem{}, nil, err
+		}
+
+		// Handle nullable fields
+		if cronExpression.Valid {
+			item.CronExpression = &cronExpression.String
+		}
+		if expiration.Valid {
+			item.Expiration = &expiration.Time
+		}
+		if actionConfig != nil {
+			item.ActionConfig = actionConfig
+		}
+		item.Notifications = decodeNotifications(notifications)
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.ScheduledItem{}, nil, err
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+
+	items = items[:limit]
+	next := scheduledItemCursor(items[len(items)-1])
+	return items, &next, nil
+}
+
+// UpdateScheduledItem replaces a scheduled item if its version matches item.Version,
+// returning ErrNotFound or ErrVersionConflict otherwise
+func (s *PostgresScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	query := `
+		UPDATE scheduled_items 
+		SET title = $1, description = $2, starts_at = $3, repeats = $4, cron_expression = $5, expiration = $6, 
+		    next_execution_at = $7, action_type = $8, action_config = $9, jitter_seconds = $10, 
+		    notifications = $11, request_id = $12, organization_id = $13, paused = $17, version = version + 1, updated_at = NOW() 
+		WHERE id = $14 AND version = $15 AND tenant_id = $16
+		RETURNING owner_id, tenant_id, version, created_at, updated_at
+	`
+
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+
+	// Store a missing action config as NULL
+	var actionConfig interface{}
+	if len(item.ActionConfig) > 0 {
+		actionConfig = string(item.ActionConfig)
+	}
+
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		item.Title,
+		item.Description,
+		item.StartsAt,
+		item.Repeats,
+		item.CronExpression,
+		item.Expiration,
+		item.NextExecutionAt,
+		item.ActionType,
+		actionConfig,
+		item.JitterSeconds,
+		encodeNotifications(item.Notifications),
+		item.RequestID,
+		item.OrganizationID,
+		id,
+		item.Version,
+		TenantFromContext(ctx),
+		item.Paused,
+	).Scan(&item.OwnerID, &item.TenantID, &item.Version, &item.CreatedAt, &item.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		// Either the item is gone or another update got there first
+		var exists bool
+		if err := querier(ctx, s.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM scheduled_items WHERE id = $1 AND tenant_id = $2)`, id, TenantFromContext(ctx)).Scan(&exists); err != nil {
+			return models.ScheduledItem{}, err
+		}
+		if !exists {
+			return models.ScheduledItem{}, ErrNotFound
+		}
+		return models.ScheduledItem{}, ErrVersionConflict
+	}
+	if err != nil {
+		return models.ScheduledItem{}, err
+	}
+
+	item.ID = id
+	return item, nil
+}
+
+// UpdateNextExecutionAt updates the next execution time for a scheduled item
+func (s *PostgresScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	// Moving the next execution time also releases any claim held on the item
+	query := `UPDATE scheduled_items SET next_execution_at = $1, claimed_until = NULL WHERE id = $2 AND tenant_id = $3`
+
+	result, err := s.statements.querier(ctx).ExecContext(ctx, query, nextExecutionAt, id, TenantFromContext(ctx))
+	if err != nil {
+		logging.Errorf("Error updating next execution time: %v", err)
 		return false
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
+		logging.Errorf("Error getting rows affected: %v", err)
 		return false
 	}
 
 	return rowsAffected > 0
 }
 
-// DeleteScheduledItem removes a scheduled item from the database
-func (s *PostgresScheduledItemStore) DeleteScheduledItem(id int64) bool {
-	s.Lock()
-	defer s.Unlock()
+// UpdateNextExecutionAtBatch updates the next execution times of many scheduled items,
+// keyed by ID, with one UPDATE per maxBatchRows items, releasing their claims. It returns
+// ErrNotFound when any of the items doesn't exist; within a transaction, rolling it back
+// undoes the others.
+func (s *PostgresScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	// Rows are locked in ID order, so concurrent batches can't deadlock
+	ids := slices.Sorted(maps.Keys(nextExecutions))
+	tenantID := TenantFromContext(ctx)
+	for batch := range slices.Chunk(ids, maxBatchRows) {
+		query := `
+			UPDATE scheduled_items AS item
+			SET next_execution_at = next.next_execution_at::timestamp, claimed_until = NULL
+			FROM (VALUES ` + valuesList(len(batch), 2) + `) AS next (id, next_execution_at)
+			WHERE item.id = next.id::bigint AND item.tenant_id = $` + strconv.Itoa(len(batch)*2+1)
+
+		args := make([]any, 0, len(batch)*2+1)
+		for _, id := range batch {
+			args = append(args, id, nextExecutions[id])
+		}
+		args = append(args, tenantID)
+
+		result, err := querier(ctx, s.db).ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to update next execution times: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to update next execution times: %w", err)
+		}
+		if rowsAffected != int64(len(batch)) {
+			return fmt.Errorf("%w: updated the next execution times of %d of %d items", ErrNotFound, rowsAffected, len(batch))
+		}
+	}
+	return nil
+}
 
-	query := `DELETE FROM scheduled_items WHERE id = $1`
-	result, err := s.db.Exec(query, id)
+// DeleteScheduledItem removes a scheduled item from the database
+func (s *PostgresScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	query := `DELETE FROM scheduled_items WHERE id = $1 AND tenant_id = $2`
+	result, err := querier(ctx, s.db).ExecContext(ctx, query, id, TenantFromContext(ctx))
 	if err != nil {
-		log.Printf("Error deleting scheduled item: %v", err)
+		logging.Errorf("Error deleting scheduled item: %v", err)
 		return false
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
+		logging.Errorf("Error getting rows affected: %v", err)
 		return false
 	}
 
 	return rowsAffected > 0
 }
 
-// GetNextScheduledItems returns scheduled items ordered by next execution time
-func (s *PostgresScheduledItemStore) GetNextScheduledItems(limit int, offset int64) ([]models.ScheduledItem, error) {
-	s.RLock()
-	defer s.RUnlock()
-
+// GetNextScheduledItems returns scheduled items ordered by next execution time. NOT paused
+// lets the query use the idx_scheduled_items_tenant_due partial index.
+func (s *PostgresScheduledItemStore) GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error) {
 	now := time.Now()
 
 	query := `
-		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at 
+		SELECT id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at 
 		FROM scheduled_items 
 		WHERE next_execution_at <= $1 
 		  AND (expiration IS NULL OR expiration > $1)
+		  AND NOT paused
+		  AND tenant_id = $4
 		ORDER BY next_execution_at 
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := s.db.Query(query, now, limit, offset)
+	rows, err := s.statements.querier(ctx).QueryContext(ctx, query, now, limit, offset, TenantFromContext(ctx))
+	if err != nil {
+		return []models.ScheduledItem{}, err
+	}
+	defer rows.Close()
+
+	var items []models.ScheduledItem
+	for rows.Next() {
+		var item models.ScheduledItem
+		var cronExpression sql.NullString
+		var expiration sql.NullTime
+		var actionConfig []byte
+		var notifications []byte
+
+		err := rows.Scan(
+			&item.ID,
+			&item.Title,
+			&item.Description,
+			&item.StartsAt,
+			&item.Repeats,
+			&cronExpression,
+			&expiration,
+			&item.NextExecutionAt,
+			&item.ActionType,
+			&actionConfig,
+			&item.JitterSeconds,
+			&item.Paused,
+			&notifications,
+			&item.RequestID,
+			&item.OrganizationID,
+			&item.OwnerID,
+			&item.TenantID,
+			&item.Version,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+
+		if err != nil {
+			return []models.ScheduledItem{}, err
+		}
+
+		// Handle nullable fields
+		if cronExpression.Valid {
+			item.CronExpression = &cronExpression.String
+		}
+		if expiration.Valid {
+			item.Expiration = &expiration.Time
+		}
+		if actionConfig != nil {
+			item.ActionConfig = actionConfig
+		}
+		item.Notifications = decodeNotifications(notifications)
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.ScheduledItem{}, err
+	}
+
+	return items, nil
+}
+
+
+// claimDueItemsQuery claims up to $3 items due at $1 until $2, taking turns between the
+// tenants. Each tenant's due items are read through the idx_scheduled_items_tenant_due
+// partial index, at most $3 of them, so the window ranks at most $3 rows per tenant
+// however large the backlog. The tenants are found by skipping through the same index
+// from one tenant_id to the next (a loose index scan), one index probe per tenant, rather
+// than by reading their items. Both scans keep NOT paused so the partial index applies.
+// The plan to expect is the recursive scan feeding a nested loop of Limit nodes over
+// index scans of idx_scheduled_items_tenant_due, without a sort or scan over the whole due
+// range; cmd/loadgen -tenants N -explain prints the EXPLAIN ANALYZE of a claim to check.
+const claimDueItemsQuery = `
+	WITH RECURSIVE tenants AS (
+		(
+			SELECT tenant_id FROM scheduled_items
+			WHERE NOT paused
+			ORDER BY tenant_id
+			LIMIT 1
+		)
+		UNION ALL
+		SELECT (
+			SELECT scheduled_items.tenant_id FROM scheduled_items
+			WHERE NOT paused AND scheduled_items.tenant_id > tenants.tenant_id
+			ORDER BY scheduled_items.tenant_id
+			LIMIT 1
+		)
+		FROM tenants
+		WHERE tenants.tenant_id IS NOT NULL
+	)
+	UPDATE scheduled_items
+	SET claimed_until = $2
+	WHERE id IN (
+		SELECT item.id
+		FROM scheduled_items item
+		JOIN (
+			SELECT due.id, ROW_NUMBER() OVER (PARTITION BY tenants.tenant_id ORDER BY due.next_execution_at) AS tenant_rank
+			FROM tenants
+			CROSS JOIN LATERAL (
+				SELECT id, next_execution_at
+				FROM scheduled_items
+				WHERE tenant_id = tenants.tenant_id
+				  AND next_execution_at <= $1
+				  AND (expiration IS NULL OR expiration > $1)
+				  AND NOT paused
+				  AND (claimed_until IS NULL OR claimed_until <= $1)
+				ORDER BY next_execution_at
+				LIMIT $3
+			) due
+		) due ON due.id = item.id
+		WHERE (item.claimed_until IS NULL OR item.claimed_until <= $1)
+		ORDER BY due.tenant_rank, item.next_execution_at
+		LIMIT $3
+		FOR UPDATE OF item SKIP LOCKED
+	)
+	RETURNING id, title, description, starts_at, repeats, cron_expression, expiration, next_execution_at, action_type, action_config, jitter_seconds, paused, notifications, request_id, organization_id, owner_id, tenant_id, version, created_at, updated_at
+`
+
+// ClaimDueItems atomically claims up to limit items that are due for execution and not
+// already claimed. A claim expires after the lease duration so items held by a crashed
+// scheduler are picked up again. Rows locked by a concurrent claim are skipped rather
+// than waited on, so multiple schedulers can run side by side. Items are claimed across
+// all tenants, taking turns between them by each item's rank among its tenant's due items,
+// so one tenant with many due items can't hold up the others; each carries its TenantID
+// for processing. See claimDueItemsQuery for how the due items are found.
+func (s *PostgresScheduledItemStore) ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error) {
+	now := time.Now()
+
+	rows, err := s.statements.querier(ctx).QueryContext(ctx, claimDueItemsQuery, now, now.Add(lease), limit)
 	if err != nil {
 		return []models.ScheduledItem{}, err
 	}
@@ -224,6 +691,8 @@ func (s *PostgresScheduledItemStore) GetNextScheduledItems(limit int, offset int
 		var item models.ScheduledItem
 		var cronExpression sql.NullString
 		var expiration sql.NullTime
+		var actionConfig []byte
+		var notifications []byte
 
 		err := rows.Scan(
 			&item.ID,
@@ -234,6 +703,18 @@ func (s *PostgresScheduledItemStore) GetNextScheduledItems(limit int, offset int
 			&cronExpression,
 			&expiration,
 			&item.NextExecutionAt,
+			&item.ActionType,
+			&actionConfig,
+			&item.JitterSeconds,
+			&item.Paused,
+			&notifications,
+			&item.RequestID,
+			&item.OrganizationID,
+			&item.OwnerID,
+			&item.TenantID,
+			&item.Version,
+			&item.CreatedAt,
+			&item.UpdatedAt,
 		)
 
 		if err != nil {
@@ -247,6 +728,10 @@ func (s *PostgresScheduledItemStore) GetNextScheduledItems(limit int, offset int
 		if expiration.Valid {
 			item.Expiration = &expiration.Time
 		}
+		if actionConfig != nil {
+			item.ActionConfig = actionConfig
+		}
+		item.Notifications = decodeNotifications(notifications)
 
 		items = append(items, item)
 	}
@@ -255,6 +740,39 @@ func (s *PostgresScheduledItemStore) GetNextScheduledItems(limit int, offset int
 		return []models.ScheduledItem{}, err
 	}
 
+	// RETURNING does not preserve the subquery order
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].NextExecutionAt.Before(items[j].NextExecutionAt)
+	})
+
 	return items, nil
 }
 
+// ExplainClaimDueItems returns the plan of ClaimDueItems with the time and buffers each
+// step took, from EXPLAIN ANALYZE. The claim runs in a transaction that is rolled back, so
+// no item stays claimed.
+func (s *PostgresScheduledItemStore) ExplainClaimDueItems(ctx context.Context, limit int, lease time.Duration) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	rows, err := tx.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+claimDueItemsQuery, now, now.Add(lease), limit)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		plan.WriteString(line)
+		plan.WriteString("\n")
+	}
+	return plan.String(), rows.Err()
+}
@@ -0,0 +1,511 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoClaimCandidates is how many times the batch size of due items ClaimDueItems reads
+// to share the batch between tenants. A tenant whose earliest due item is behind that
+// many items of others waits for a later batch.
+const dynamoClaimCandidates = 10
+
+// dynamoScheduledItem is the DynamoDB representation of a scheduled item. Times used in
+// key conditions and filters are stored as Unix nanoseconds so they compare numerically.
+type dynamoScheduledItem struct {
+	PK              string    `dynamodbav:"pk"`
+	SK              string    `dynamodbav:"sk"`
+	ID              int64     `dynamodbav:"id"`
+	Title           string    `dynamodbav:"title"`
+	Description     string    `dynamodbav:"description"`
+	StartsAt        time.Time `dynamodbav:"starts_at"`
+	Repeats         bool      `dynamodbav:"repeats"`
+	CronExpression  *string   `dynamodbav:"cron_expression,omitempty"`
+	Expiration      *int64    `dynamodbav:"expiration,omitempty"`
+	NextExecutionAt int64     `dynamodbav:"next_execution_at"`
+	ActionType      string    `dynamodbav:"action_type"`
+	ActionConfig    *string   `dynamodbav:"action_config,omitempty"`
+	JitterSeconds   int       `dynamodbav:"jitter_seconds"`
+	Paused          bool      `dynamodbav:"paused,omitempty"`
+	Notifications   *string   `dynamodbav:"notifications,omitempty"`
+	RequestID       string    `dynamodbav:"request_id,omitempty"`
+	OrganizationID  *int64    `dynamodbav:"organization_id,omitempty"`
+	OwnerID         *int64    `dynamodbav:"owner_id,omitempty"`
+	TenantID        string    `dynamodbav:"tenant_id,omitempty"`
+	ClaimedUntil    *int64    `dynamodbav:"claimed_until,omitempty"`
+	Version         int64     `dynamodbav:"version"`
+	CreatedAt       time.Time `dynamodbav:"created_at"`
+	UpdatedAt       time.Time `dynamodbav:"updated_at"`
+}
+
+// newDynamoScheduledItem converts a scheduled item to its DynamoDB representation
+func newDynamoScheduledItem(item models.ScheduledItem) dynamoScheduledItem {
+	record := dynamoScheduledItem{
+		PK:              dynamoEntityScheduledItem,
+		SK:              dynamoSortKeyForID(item.ID),
+		ID:              item.ID,
+		Title:           item.Title,
+		Description:     item.Description,
+		StartsAt:        item.StartsAt,
+		Repeats:         item.Repeats,
+		CronExpression:  item.CronExpression,
+		NextExecutionAt: item.NextExecutionAt.UnixNano(),
+		ActionType:      item.ActionType,
+		JitterSeconds:   item.JitterSeconds,
+		Paused:          item.Paused,
+		Notifications:   encodeNotifications(item.Notifications),
+		RequestID:       item.RequestID,
+		OrganizationID:  item.OrganizationID,
+		OwnerID:         item.OwnerID,
+		TenantID:        item.TenantID,
+		Version:         item.Version,
+		CreatedAt:       item.CreatedAt,
+		UpdatedAt:       item.UpdatedAt,
+	}
+	if item.Expiration != nil {
+		expiration := item.Expiration.UnixNano()
+		record.Expiration = &expiration
+	}
+	if len(item.ActionConfig) > 0 {
+		actionConfig := string(item.ActionConfig)
+		record.ActionConfig = &actionConfig
+	}
+	return record
+}
+
+// toModel converts the DynamoDB representation back to a scheduled item
+func (r dynamoScheduledItem) toModel() models.ScheduledItem {
+	item := models.ScheduledItem{
+		ID:              r.ID,
+		Title:           r.Title,
+		Description:     r.Description,
+		StartsAt:        r.StartsAt,
+		Repeats:         r.Repeats,
+		CronExpression:  r.CronExpression,
+		NextExecutionAt: time.Unix(0, r.NextExecutionAt),
+		ActionType:      r.ActionType,
+		JitterSeconds:   r.JitterSeconds,
+		Paused:          r.Paused,
+		RequestID:       r.RequestID,
+		OrganizationID:  r.OrganizationID,
+		OwnerID:         r.OwnerID,
+		TenantID:        r.TenantID,
+		Version:         r.Version,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}
+	if item.TenantID == "" {
+		item.TenantID = DefaultTenant
+	}
+	if r.Expiration != nil {
+		expiration := time.Unix(0, *r.Expiration)
+		item.Expiration = &expiration
+	}
+	if r.ActionConfig != nil {
+		item.ActionConfig = []byte(*r.ActionConfig)
+	}
+	if r.Notifications != nil {
+		item.Notifications = decodeNotifications([]byte(*r.Notifications))
+	}
+	return item
+}
+
+// DynamoScheduledItemStore provides DynamoDB storage operations for scheduled items
+type DynamoScheduledItemStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoScheduledItemStore creates a new DynamoDB store using the given client and table
+func NewDynamoScheduledItemStore(client *dynamodb.Client, table string) *DynamoScheduledItemStore {
+	return &DynamoScheduledItemStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateScheduledItem adds a new scheduled item to the table
+func (s *DynamoScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityScheduledItem)
+	if err != nil {
+		logging.Errorf("Error allocating scheduled item ID: %v", err)
+		return models.ScheduledItem{}
+	}
+	item.ID = id
+	item.Version = 1
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+	item.TenantID = TenantFromContext(ctx)
+
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+
+	record, err := attributevalue.MarshalMap(newDynamoScheduledItem(item))
+	if err != nil {
+		logging.Errorf("Error marshalling scheduled item: %v", err)
+		return models.ScheduledItem{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error creating scheduled item: %v", err)
+		return models.ScheduledItem{} // Return empty item on error
+	}
+
+	return item
+}
+
+// GetScheduledItem retrieves a scheduled item by ID from the table
+func (s *DynamoScheduledItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityScheduledItem, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting scheduled item: %v", err)
+		return models.ScheduledItem{}, false
+	}
+	if output.Item == nil {
+		return models.ScheduledItem{}, false
+	}
+
+	var record dynamoScheduledItem
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling scheduled item: %v", err)
+		return models.ScheduledItem{}, false
+	}
+	if !ownTenant(ctx, record.TenantID) {
+		return models.ScheduledItem{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllScheduledItems returns all scheduled items from the table, or those of the
+// context's organization
+func (s *DynamoScheduledItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityScheduledItem},
+		},
+	}
+	scopeDynamoQuery(ctx, input)
+	tenantDynamoQuery(ctx, input)
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	var items []models.ScheduledItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying scheduled items: %v", err)
+			return []models.ScheduledItem{}
+		}
+
+		var records []dynamoScheduledItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling scheduled items: %v", err)
+			return []models.ScheduledItem{}
+		}
+		for _, record := range records {
+			items = append(items, record.toModel())
+		}
+	}
+
+	return items
+}
+
+// StreamScheduledItems streams the scheduled items of the table, or those of the context's
+// organization, a query page at a time, so the listing is never held in memory whole.
+// The stream ends after the first error.
+func (s *DynamoScheduledItemStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	return func(yield func(models.ScheduledItem, error) bool) {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: dynamoEntityScheduledItem},
+			},
+		}
+		scopeDynamoQuery(ctx, input)
+		tenantDynamoQuery(ctx, input)
+		paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(models.ScheduledItem{}, err)
+				return
+			}
+
+			var records []dynamoScheduledItem
+			if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+				yield(models.ScheduledItem{}, err)
+				return
+			}
+			for _, record := range records {
+				if !yield(record.toModel(), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetScheduledItemsPage returns a page of up to limit scheduled items in next execution
+// time order, ties broken by ID, starting after cursor, or from the start when it is nil.
+// The next execution index only holds the items that aren't paused, so the page is taken
+// from the full listing. The returned cursor is nil when there are no further pages.
+func (s *DynamoScheduledItemStore) GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error) {
+	return keysetPage(s.GetAllScheduledItems(ctx), limit, cursor, scheduledItemCursor, compareScheduledItemCursors)
+}
+
+// UpdateScheduledItem replaces a scheduled item if its version matches item.Version,
+// returning ErrNotFound or ErrVersionConflict otherwise
+func (s *DynamoScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            dynamoKey(dynamoEntityScheduledItem, dynamoSortKeyForID(id)),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return models.ScheduledItem{}, err
+	}
+	if output.Item == nil {
+		return models.ScheduledItem{}, ErrNotFound
+	}
+
+	var existing dynamoScheduledItem
+	if err := attributevalue.UnmarshalMap(output.Item, &existing); err != nil {
+		return models.ScheduledItem{}, err
+	}
+	if !ownTenant(ctx, existing.TenantID) {
+		return models.ScheduledItem{}, ErrNotFound
+	}
+	if existing.Version != item.Version {
+		return models.ScheduledItem{}, ErrVersionConflict
+	}
+
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+
+	item.ID = id
+	item.OwnerID = existing.OwnerID
+	item.TenantID = existing.TenantID
+	item.Version = existing.Version + 1
+	item.CreatedAt = existing.CreatedAt
+	item.UpdatedAt = time.Now()
+
+	// Keep any claim held by a scheduler that is processing the item
+	updated := newDynamoScheduledItem(item)
+	updated.ClaimedUntil = existing.ClaimedUntil
+
+	record, err := attributevalue.MarshalMap(updated)
+	if err != nil {
+		return models.ScheduledItem{}, err
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("version = :expected"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":expected": dynamoNumber(existing.Version),
+		},
+	})
+	if err != nil {
+		if isConditionalCheckFailed(err) {
+			return models.ScheduledItem{}, ErrVersionConflict
+		}
+		return models.ScheduledItem{}, err
+	}
+
+	return item, nil
+}
+
+// UpdateNextExecutionAt updates the next execution time for a scheduled item
+func (s *DynamoScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	condition, values := andDynamoCondition(aws.String("attribute_exists(pk)"), map[string]types.AttributeValue{
+		":next": dynamoNumber(nextExecutionAt.UnixNano()),
+	}, tenant, tenantValues)
+
+	// Moving the next execution time also releases any claim held on the item
+	_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityScheduledItem, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET next_execution_at = :next REMOVE claimed_until"),
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating next execution time: %v", err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// UpdateNextExecutionAtBatch updates the next execution times of many scheduled items,
+// keyed by ID, one by one, since an update of each item's condition can't be batched. It
+// returns ErrNotFound once an item doesn't exist; the items updated before stay updated.
+func (s *DynamoScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	for _, id := range slices.Sorted(maps.Keys(nextExecutions)) {
+		if !s.UpdateNextExecutionAt(ctx, id, nextExecutions[id]) {
+			return fmt.Errorf("%w: scheduled item %d", ErrNotFound, id)
+		}
+	}
+	return nil
+}
+
+// DeleteScheduledItem removes a scheduled item from the table
+func (s *DynamoScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	condition, values := dynamoTenantCondition(ctx)
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityScheduledItem, dynamoSortKeyForID(id)),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error deleting scheduled item: %v", err)
+		}
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
+
+// GetNextScheduledItems returns due scheduled items ordered by next execution time
+func (s *DynamoScheduledItemStore) GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error) {
+	now := time.Now().UnixNano()
+
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	items, err := s.queryDueItems(ctx, now, tenant, tenantValues, int(offset)+limit)
+	if err != nil {
+		return []models.ScheduledItem{}, err
+	}
+
+	if int(offset) >= len(items) {
+		return []models.ScheduledItem{}, nil
+	}
+	return items[offset:], nil
+}
+
+// ClaimDueItems claims up to limit items that are due for execution and not already
+// claimed. Each claim is a conditional write, so items claimed concurrently by another
+// scheduler are skipped. A claim expires after the lease duration. Items are claimed
+// across all tenants, taking turns between the tenants of the earliest due items so one
+// with many due items can't hold up the others.
+func (s *DynamoScheduledItemStore) ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error) {
+	now := time.Now()
+
+	// The index orders items by execution time alone, so look past the first limit items
+	// for those of other tenants
+	unclaimed := "attribute_not_exists(claimed_until) OR claimed_until <= :now"
+	candidates, err := s.queryDueItems(ctx, now.UnixNano(), unclaimed, nil, limit*dynamoClaimCandidates)
+	if err != nil {
+		return []models.ScheduledItem{}, err
+	}
+	candidates = interleaveTenants(candidates)
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	var items []models.ScheduledItem
+	for _, item := range candidates {
+		_, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String(s.table),
+			Key:              dynamoKey(dynamoEntityScheduledItem, dynamoSortKeyForID(item.ID)),
+			UpdateExpression: aws.String("SET claimed_until = :until"),
+			// Only claim the item if nobody else did since it was read
+			ConditionExpression: aws.String("attribute_exists(pk) AND next_execution_at = :next AND (" + unclaimed + ")"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":until": dynamoNumber(now.Add(lease).UnixNano()),
+				":next":  dynamoNumber(item.NextExecutionAt.UnixNano()),
+				":now":   dynamoNumber(now.UnixNano()),
+			},
+		})
+		if err != nil {
+			if isConditionalCheckFailed(err) {
+				continue
+			}
+			return []models.ScheduledItem{}, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// queryDueItems returns up to limit unexpired items with a next execution at or before
+// now, in execution order. An optional filter, with the values it refers to, further
+// restricts the items returned.
+func (s *DynamoScheduledItemStore) queryDueItems(ctx context.Context, now int64, filter string, filterValues map[string]types.AttributeValue, limit int) ([]models.ScheduledItem, error) {
+	// Paused items are stored without the paused attribute once resumed
+	filterExpression := "(attribute_not_exists(expiration) OR expiration > :now) AND attribute_not_exists(paused)"
+	if filter != "" {
+		filterExpression = "(" + filterExpression + ") AND (" + filter + ")"
+	}
+
+	values := map[string]types.AttributeValue{
+		":pk":  &types.AttributeValueMemberS{Value: dynamoEntityScheduledItem},
+		":now": dynamoNumber(now),
+	}
+	for name, value := range filterValues {
+		values[name] = value
+	}
+
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:                 aws.String(s.table),
+		IndexName:                 aws.String(dynamoNextExecutionIndex),
+		KeyConditionExpression:    aws.String("pk = :pk AND next_execution_at <= :now"),
+		FilterExpression:          aws.String(filterExpression),
+		ExpressionAttributeValues: values,
+	})
+
+	var items []models.ScheduledItem
+	for paginator.HasMorePages() && len(items) < limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var records []dynamoScheduledItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			items = append(items, record.toModel())
+		}
+	}
+
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
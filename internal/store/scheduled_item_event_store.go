@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// PublishingScheduledItemStore publishes an event for every change made through another
+// scheduled item store. Events for changes made inside a transaction are published once it
+// commits.
+type PublishingScheduledItemStore struct {
+	ScheduledItemStore
+	bus *events.Bus
+}
+
+// NewPublishingScheduledItemStore wraps the given store so its changes are published on bus
+func NewPublishingScheduledItemStore(store ScheduledItemStore, bus *events.Bus) *PublishingScheduledItemStore {
+	return &PublishingScheduledItemStore{
+		ScheduledItemStore: store,
+		bus:                bus,
+	}
+}
+
+// CreateScheduledItem creates the item and publishes a scheduled_item.created event
+func (s *PublishingScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
+	createdItem := s.ScheduledItemStore.CreateScheduledItem(ctx, item)
+	if createdItem.ID != 0 {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.ScheduledItemCreated, createdItem) })
+	}
+	return createdItem
+}
+
+// UpdateScheduledItem updates the item and publishes a scheduled_item.updated event
+func (s *PublishingScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	updatedItem, err := s.ScheduledItemStore.UpdateScheduledItem(ctx, id, item)
+	if err == nil {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.ScheduledItemUpdated, updatedItem) })
+	}
+	return updatedItem, err
+}
+
+// UpdateNextExecutionAt moves the next execution and publishes a scheduled_item.rescheduled event
+func (s *PublishingScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	updated := s.ScheduledItemStore.UpdateNextExecutionAt(ctx, id, nextExecutionAt)
+	if updated {
+		AfterCommit(ctx, func() {
+			s.bus.PublishTenant(TenantFromContext(ctx), events.ScheduledItemRescheduled, rescheduledEventData{ID: id, NextExecutionAt: nextExecutionAt})
+		})
+	}
+	return updated
+}
+
+// UpdateNextExecutionAtBatch updates the items' next execution times and publishes a
+// scheduled_item.rescheduled event for each
+func (s *PublishingScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	if err := s.ScheduledItemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions); err != nil {
+		return err
+	}
+	for id, nextExecutionAt := range nextExecutions {
+		AfterCommit(ctx, func() {
+			s.bus.PublishTenant(TenantFromContext(ctx), events.ScheduledItemRescheduled, rescheduledEventData{ID: id, NextExecutionAt: nextExecutionAt})
+		})
+	}
+	return nil
+}
+
+// DeleteScheduledItem deletes the item and publishes a scheduled_item.deleted event
+func (s *PublishingScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	// Look the owner up first, as only they may see the deletion once the item is gone
+	item, _ := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	deleted := s.ScheduledItemStore.DeleteScheduledItem(ctx, id)
+	if deleted {
+		AfterCommit(ctx, func() {
+			s.bus.PublishTenant(TenantFromContext(ctx), events.ScheduledItemDeleted, deletedEventData{ID: id, ownerID: item.OwnerID})
+		})
+	}
+	return deleted
+}
+
+// rescheduledEventData describes a scheduled item's new next execution time
+type rescheduledEventData struct {
+	ID              int64     `json:"id"`
+	NextExecutionAt time.Time `json:"nextExecutionAt"`
+}
@@ -1,6 +1,9 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"iter"
 	"periodic-api/internal/models"
 	"sort"
 	"sync"
@@ -11,6 +14,7 @@ import (
 type MemoryScheduledItemStore struct {
 	sync.RWMutex
 	items  map[int64]models.ScheduledItem
+	claims map[int64]time.Time
 	nextID int64
 }
 
@@ -18,18 +22,28 @@ type MemoryScheduledItemStore struct {
 func NewMemoryScheduledItemStore() *MemoryScheduledItemStore {
 	return &MemoryScheduledItemStore{
 		items:  make(map[int64]models.ScheduledItem),
+		claims: make(map[int64]time.Time),
 		nextID: 1,
 	}
 }
 
 // CreateScheduledItem adds a new scheduled item to the in-memory store
-func (s *MemoryScheduledItemStore) CreateScheduledItem(item models.ScheduledItem) models.ScheduledItem {
+func (s *MemoryScheduledItemStore) CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem {
 	s.Lock()
 	defer s.Unlock()
 
 	// Assign a new ID
 	item.ID = s.nextID
 	s.nextID++
+	item.Version = 1
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+	item.TenantID = TenantFromContext(ctx)
+
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
 
 	// Store the item
 	s.items[item.ID] = item
@@ -37,56 +51,128 @@ func (s *MemoryScheduledItemStore) CreateScheduledItem(item models.ScheduledItem
 }
 
 // GetScheduledItem retrieves a scheduled item by ID from the in-memory store
-func (s *MemoryScheduledItemStore) GetScheduledItem(id int64) (models.ScheduledItem, bool) {
+func (s *MemoryScheduledItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
 	s.RLock()
 	defer s.RUnlock()
 
 	item, exists := s.items[id]
-	return item, exists
+	if !exists || !ownTenant(ctx, item.TenantID) {
+		return models.ScheduledItem{}, false
+	}
+	return item, true
 }
 
-// GetAllScheduledItems returns all scheduled items from the in-memory store
-func (s *MemoryScheduledItemStore) GetAllScheduledItems() []models.ScheduledItem {
+// GetAllScheduledItems returns all scheduled items from the in-memory store, or those of
+// the context's organization
+func (s *MemoryScheduledItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
 	s.RLock()
 	defer s.RUnlock()
 
 	items := make([]models.ScheduledItem, 0, len(s.items))
 	for _, item := range s.items {
-		items = append(items, item)
+		if ownTenant(ctx, item.TenantID) && inScope(ctx, item.OrganizationID) {
+			items = append(items, item)
+		}
 	}
 	return items
 }
 
+// StreamScheduledItems streams a snapshot of the in-memory scheduled items
+func (s *MemoryScheduledItemStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	return sliceStream(s.GetAllScheduledItems(ctx))
+}
+
+// GetScheduledItemsPage returns a page of up to limit scheduled items in next execution
+// time order, ties broken by ID, starting after cursor, or from the start when it is nil.
+// The returned cursor is nil when there are no further pages.
+func (s *MemoryScheduledItemStore) GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error) {
+	return keysetPage(s.GetAllScheduledItems(ctx), limit, cursor, scheduledItemCursor, compareScheduledItemCursors)
+}
+
+// UpdateScheduledItem replaces a scheduled item if its version matches item.Version,
+// returning ErrNotFound or ErrVersionConflict otherwise
+func (s *MemoryScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, exists := s.items[id]
+	if !exists || !ownTenant(ctx, existing.TenantID) {
+		return models.ScheduledItem{}, ErrNotFound
+	}
+	if existing.Version != item.Version {
+		return models.ScheduledItem{}, ErrVersionConflict
+	}
+
+	// Items without an explicit action create a todo
+	if item.ActionType == "" {
+		item.ActionType = "todo"
+	}
+
+	item.ID = id
+	item.OwnerID = existing.OwnerID
+	item.TenantID = existing.TenantID
+	item.Version = existing.Version + 1
+	item.CreatedAt = existing.CreatedAt
+	item.UpdatedAt = time.Now()
+	s.items[id] = item
+	return item, nil
+}
+
 // UpdateNextExecutionAt updates the next execution time for a scheduled item
-func (s *MemoryScheduledItemStore) UpdateNextExecutionAt(id int64, nextExecutionAt time.Time) bool {
+func (s *MemoryScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
 	s.Lock()
 	defer s.Unlock()
 
 	item, exists := s.items[id]
-	if !exists {
+	if !exists || !ownTenant(ctx, item.TenantID) {
 		return false
 	}
 
+	// Moving the next execution time also releases any claim held on the item
 	item.NextExecutionAt = nextExecutionAt
 	s.items[id] = item
+	delete(s.claims, id)
 	return true
 }
 
+// UpdateNextExecutionAtBatch updates the next execution times of many scheduled items,
+// keyed by ID, releasing their claims. It updates none of them and returns ErrNotFound
+// when any of the items doesn't exist.
+func (s *MemoryScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for id := range nextExecutions {
+		if item, exists := s.items[id]; !exists || !ownTenant(ctx, item.TenantID) {
+			return fmt.Errorf("%w: scheduled item %d", ErrNotFound, id)
+		}
+	}
+
+	for id, nextExecutionAt := range nextExecutions {
+		item := s.items[id]
+		item.NextExecutionAt = nextExecutionAt
+		s.items[id] = item
+		delete(s.claims, id)
+	}
+	return nil
+}
+
 // DeleteScheduledItem removes a scheduled item from the in-memory store
-func (s *MemoryScheduledItemStore) DeleteScheduledItem(id int64) bool {
+func (s *MemoryScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, exists := s.items[id]; !exists {
+	if item, exists := s.items[id]; !exists || !ownTenant(ctx, item.TenantID) {
 		return false
 	}
 
 	delete(s.items, id)
+	delete(s.claims, id)
 	return true
 }
 
 // GetNextScheduledItems returns scheduled items ordered by next execution time with pagination
-func (s *MemoryScheduledItemStore) GetNextScheduledItems(limit int, offset int64) ([]models.ScheduledItem, error) {
+func (s *MemoryScheduledItemStore) GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error) {
 	s.RLock()
 	defer s.RUnlock()
 
@@ -95,6 +181,10 @@ func (s *MemoryScheduledItemStore) GetNextScheduledItems(limit int, offset int64
 	// Filter items that are due for execution and not expired
 	var itemsDue []models.ScheduledItem
 	for _, item := range s.items {
+		if !ownTenant(ctx, item.TenantID) {
+			continue
+		}
+
 		// Skip items that are not yet due
 		if item.NextExecutionAt.After(now) {
 			continue
@@ -104,6 +194,11 @@ func (s *MemoryScheduledItemStore) GetNextScheduledItems(limit int, offset int64
 		if item.Expiration != nil && now.After(*item.Expiration) {
 			continue
 		}
+
+		// Skip paused items
+		if item.Paused {
+			continue
+		}
 		
 		itemsDue = append(itemsDue, item)
 	}
@@ -124,3 +219,50 @@ func (s *MemoryScheduledItemStore) GetNextScheduledItems(limit int, offset int64
 	return itemsDue[startIndex:endIndex], nil
 }
 
+// ClaimDueItems claims up to limit items that are due for execution and not already claimed.
+// A claim expires after the lease duration. Items are claimed across all tenants, taking
+// turns between them so one with many due items can't hold up the others.
+func (s *MemoryScheduledItemStore) ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+
+	var itemsDue []models.ScheduledItem
+	for _, item := range s.items {
+		if item.NextExecutionAt.After(now) {
+			continue
+		}
+
+		if item.Expiration != nil && now.After(*item.Expiration) {
+			continue
+		}
+
+		// Paused items wait until they are resumed
+		if item.Paused {
+			continue
+		}
+
+		// Skip items with a claim that hasn't expired yet
+		if claimedUntil, claimed := s.claims[item.ID]; claimed && claimedUntil.After(now) {
+			continue
+		}
+
+		itemsDue = append(itemsDue, item)
+	}
+
+	sort.Slice(itemsDue, func(i, j int) bool {
+		return itemsDue[i].NextExecutionAt.Before(itemsDue[j].NextExecutionAt)
+	})
+	itemsDue = interleaveTenants(itemsDue)
+
+	if len(itemsDue) > limit {
+		itemsDue = itemsDue[:limit]
+	}
+
+	for _, item := range itemsDue {
+		s.claims[item.ID] = now.Add(lease)
+	}
+
+	return itemsDue, nil
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"periodic-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreClaimDueItems(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+
+	due := store.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Due Item",
+		StartsAt:        now.Add(-time.Hour),
+		NextExecutionAt: now.Add(-time.Minute),
+	})
+	store.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Future Item",
+		StartsAt:        now.Add(time.Hour),
+		NextExecutionAt: now.Add(time.Hour),
+	})
+
+	claimed, err := store.ClaimDueItems(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != due.ID {
+		t.Fatalf("Expected to claim item %d, got %v", due.ID, claimed)
+	}
+
+	// A second claim must not return the item while the lease is held
+	claimed, err = store.ClaimDueItems(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Errorf("Expected no items while claimed, got %d", len(claimed))
+	}
+
+	// Updating the next execution time releases the claim
+	store.UpdateNextExecutionAt(context.Background(), due.ID, now.Add(-time.Second))
+	claimed, err = store.ClaimDueItems(context.Background(), 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(claimed) != 1 {
+		t.Errorf("Expected item to be claimable after release, got %d items", len(claimed))
+	}
+}
+
+func TestMemoryStoreClaimDueItemsLeaseExpires(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+
+	store.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Due Item",
+		StartsAt:        now.Add(-time.Hour),
+		NextExecutionAt: now.Add(-time.Minute),
+	})
+
+	// A zero lease expires immediately, as if the claiming scheduler crashed
+	if claimed, _ := store.ClaimDueItems(context.Background(), 10, 0); len(claimed) != 1 {
+		t.Fatalf("Expected to claim 1 item, got %d", len(claimed))
+	}
+	if claimed, _ := store.ClaimDueItems(context.Background(), 10, time.Minute); len(claimed) != 1 {
+		t.Errorf("Expected expired claim to be reclaimed, got %d items", len(claimed))
+	}
+}
+
+func TestMemoryStoreClaimDueItemsSkipsPausedItems(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+
+	paused := store.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:           "Paused Item",
+		StartsAt:        now.Add(-time.Hour),
+		NextExecutionAt: now.Add(-time.Minute),
+		Paused:          true,
+	})
+	if claimed, _ := store.ClaimDueItems(context.Background(), 10, time.Minute); len(claimed) != 0 {
+		t.Fatalf("Expected paused items not to be claimed, got %d", len(claimed))
+	}
+
+	// Resuming the item makes it claimable again
+	paused.Paused = false
+	if _, err := store.UpdateScheduledItem(context.Background(), paused.ID, paused); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if claimed, _ := store.ClaimDueItems(context.Background(), 10, time.Minute); len(claimed) != 1 {
+		t.Errorf("Expected the resumed item to be claimed, got %d items", len(claimed))
+	}
+}
+
+func TestMemoryStoreClaimDueItemsTakesTurnsBetweenTenants(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+
+	// acme's backlog is due before globex's only item
+	busy := WithTenant(context.Background(), "acme")
+	for i := 0; i < 5; i++ {
+		store.CreateScheduledItem(busy, models.ScheduledItem{
+			Title:           "Backlog",
+			StartsAt:        now.Add(-time.Hour),
+			NextExecutionAt: now.Add(-time.Hour + time.Duration(i)*time.Minute),
+		})
+	}
+	quiet := store.CreateScheduledItem(WithTenant(context.Background(), "globex"), models.ScheduledItem{
+		Title:           "Quiet",
+		StartsAt:        now.Add(-time.Hour),
+		NextExecutionAt: now.Add(-time.Minute),
+	})
+
+	claimed, err := store.ClaimDueItems(context.Background(), 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0].TenantID != "acme" || claimed[1].ID != quiet.ID {
+		t.Fatalf("Expected acme's earliest item and globex's item, got %+v", claimed)
+	}
+}
+
+func TestMemoryStoreUpdateScheduledItemVersionConflict(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+
+	created := store.CreateScheduledItem(context.Background(), models.ScheduledItem{
+		Title:    "Original",
+		StartsAt: now.Add(time.Hour),
+	})
+	if created.Version != 1 {
+		t.Fatalf("Expected new item to have version 1, got %d", created.Version)
+	}
+
+	first := created
+	first.Title = "First Edit"
+	updated, err := store.UpdateScheduledItem(context.Background(), created.ID, first)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("Expected version 2 after update, got %d", updated.Version)
+	}
+
+	// A second writer still holding the original version must be rejected
+	second := created
+	second.Title = "Second Edit"
+	if _, err := store.UpdateScheduledItem(context.Background(), created.ID, second); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+
+	if _, err := store.UpdateScheduledItem(context.Background(), created.ID+100, updated); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if item, _ := store.GetScheduledItem(context.Background(), created.ID); item.Title != "First Edit" {
+		t.Errorf("Expected first edit to be kept, got %q", item.Title)
+	}
+}
+
+func TestMemoryStoreUpdateNextExecutionAtBatch(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	first := store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "First", StartsAt: now, NextExecutionAt: now})
+	second := store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Second", StartsAt: now, NextExecutionAt: now})
+
+	next := now.Add(time.Hour)
+	if err := store.UpdateNextExecutionAtBatch(ctx, map[int64]time.Time{first.ID: next, second.ID: next.Add(time.Hour)}); err != nil {
+		t.Fatalf("UpdateNextExecutionAtBatch failed: %v", err)
+	}
+	if item, _ := store.GetScheduledItem(ctx, second.ID); !item.NextExecutionAt.Equal(next.Add(time.Hour)) {
+		t.Errorf("Expected the second item next at %v, got %v", next.Add(time.Hour), item.NextExecutionAt)
+	}
+
+	// An unknown item fails the whole batch
+	err := store.UpdateNextExecutionAtBatch(ctx, map[int64]time.Time{first.ID: now, 999: now})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if item, _ := store.GetScheduledItem(ctx, first.ID); !item.NextExecutionAt.Equal(next) {
+		t.Errorf("Expected the first item to be left unchanged, got %v", item.NextExecutionAt)
+	}
+}
+
+func TestMemoryStoreGetScheduledItemsPage(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	ctx := context.Background()
+	base := time.Now().Add(time.Hour)
+
+	// Items 2 and 3 share a next execution time, so their IDs decide their order
+	for _, offset := range []time.Duration{3, 1, 1, 0, 2} {
+		store.CreateScheduledItem(ctx, models.ScheduledItem{
+			Title:           "Item",
+			StartsAt:        base,
+			NextExecutionAt: base.Add(offset * time.Minute),
+		})
+	}
+
+	var seen []int64
+	var cursor *ScheduledItemCursor
+	for page := 0; page < 5; page++ {
+		items, next, err := store.GetScheduledItemsPage(ctx, 2, cursor)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, item := range items {
+			seen = append(seen, item.ID)
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+
+		// An item created ahead of the cursor doesn't shift the pages that follow
+		if page == 0 {
+			store.CreateScheduledItem(ctx, models.ScheduledItem{Title: "Earlier", StartsAt: base, NextExecutionAt: base.Add(-time.Minute)})
+		}
+	}
+
+	expected := []int64{4, 2, 3, 5, 1}
+	if len(seen) != len(expected) {
+		t.Fatalf("Expected items %v, got %v", expected, seen)
+	}
+	for i := range expected {
+		if seen[i] != expected[i] {
+			t.Errorf("Expected item %d at position %d, got %d", expected[i], i, seen[i])
+		}
+	}
+}
+
+func TestMemoryStoreGetScheduledItemsPageRejectsInvalidLimits(t *testing.T) {
+	store := NewMemoryScheduledItemStore()
+	now := time.Now()
+	store.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Item", StartsAt: now, NextExecutionAt: now})
+
+	for _, limit := range []int{0, -1} {
+		items, next, err := store.GetScheduledItemsPage(context.Background(), limit, nil)
+		if !errors.Is(err, ErrInvalidLimit) || len(items) != 0 || next != nil {
+			t.Errorf("Expected limit %d to be rejected, got %d items, cursor %v and error %v", limit, len(items), next, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"periodic-api/internal/models"
 	"testing"
 	"time"
@@ -12,50 +13,50 @@ func TestMemoryStoreGetNextScheduledItems(t *testing.T) {
 
 	// Create test items with different next execution times
 	item1 := models.ScheduledItem{
-		Title:           "Future Item 1",
+		Title:           "Due Item 1",
 		Description:     "Description 1",
-		StartsAt:        now.Add(1 * time.Hour),
+		StartsAt:        now.Add(-1 * time.Hour),
 		Repeats:         false,
-		NextExecutionAt: timePtr(now.Add(1 * time.Hour)),
+		NextExecutionAt: now.Add(-1 * time.Hour),
 	}
 
 	item2 := models.ScheduledItem{
-		Title:           "Future Item 2",
+		Title:           "Due Item 2",
 		Description:     "Description 2",
-		StartsAt:        now.Add(2 * time.Hour),
+		StartsAt:        now.Add(-30 * time.Minute),
 		Repeats:         false,
-		NextExecutionAt: timePtr(now.Add(2 * time.Hour)),
+		NextExecutionAt: now.Add(-30 * time.Minute),
 	}
 
 	item3 := models.ScheduledItem{
-		Title:           "Past Item",
+		Title:           "Future Item",
 		Description:     "Description 3",
-		StartsAt:        now.Add(-1 * time.Hour),
+		StartsAt:        now.Add(1 * time.Hour),
 		Repeats:         false,
-		NextExecutionAt: nil, // No next execution
+		NextExecutionAt: now.Add(1 * time.Hour), // Not due yet
 	}
 
 	item4 := models.ScheduledItem{
 		Title:           "Earliest Item",
 		Description:     "Description 4",
-		StartsAt:        now.Add(30 * time.Minute),
+		StartsAt:        now.Add(-2 * time.Hour),
 		Repeats:         false,
-		NextExecutionAt: timePtr(now.Add(30 * time.Minute)),
+		NextExecutionAt: now.Add(-2 * time.Hour),
 	}
 
 	// Add items to store
-	store.CreateScheduledItem(item1)
-	store.CreateScheduledItem(item2)
-	store.CreateScheduledItem(item3)
-	store.CreateScheduledItem(item4)
+	store.CreateScheduledItem(context.Background(), item1)
+	store.CreateScheduledItem(context.Background(), item2)
+	store.CreateScheduledItem(context.Background(), item3)
+	store.CreateScheduledItem(context.Background(), item4)
 
 	// Test GetNextScheduledItems
-	nextItems, err := store.GetNextScheduledItems(5, 0)
+	nextItems, err := store.GetNextScheduledItems(context.Background(), 5, 0)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
 
-	// Should return 3 items (excluding the one with nil NextExecutionAt)
+	// Should return 3 items (excluding the one that isn't due yet)
 	if len(nextItems) != 3 {
 		t.Errorf("Expected 3 items but got %d", len(nextItems))
 	}
@@ -66,7 +67,7 @@ func TestMemoryStoreGetNextScheduledItems(t *testing.T) {
 	}
 
 	// Test with limit
-	limitedItems, err := store.GetNextScheduledItems(2, 0)
+	limitedItems, err := store.GetNextScheduledItems(context.Background(), 2, 0)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -76,7 +77,7 @@ func TestMemoryStoreGetNextScheduledItems(t *testing.T) {
 	}
 
 	// Test with limit larger than available items
-	allItems, err := store.GetNextScheduledItems(10, 0)
+	allItems, err := store.GetNextScheduledItems(context.Background(), 10, 0)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -86,57 +87,28 @@ func TestMemoryStoreGetNextScheduledItems(t *testing.T) {
 	}
 }
 
-func TestMemoryStoreNextExecutionCalculation(t *testing.T) {
+func TestMemoryStoreKeepsNextExecution(t *testing.T) {
 	store := NewMemoryScheduledItemStore()
 	now := time.Now()
 
-	// Test non-repeating future item
-	futureItem := models.ScheduledItem{
-		Title:       "Future Item",
-		Description: "Future description",
-		StartsAt:    now.Add(1 * time.Hour),
-		Repeats:     false,
-	}
-
-	created := store.CreateScheduledItem(futureItem)
-	if created.NextExecutionAt == nil {
-		t.Error("Expected NextExecutionAt to be set for future non-repeating item")
-	} else if created.NextExecutionAt.Unix() != futureItem.StartsAt.Unix() {
-		t.Errorf("Expected NextExecutionAt to equal StartsAt for non-repeating item")
-	}
-
-	// Test non-repeating past item
-	pastItem := models.ScheduledItem{
-		Title:       "Past Item",
-		Description: "Past description",
-		StartsAt:    now.Add(-1 * time.Hour),
-		Repeats:     false,
+	// The store keeps the next execution time worked out by its callers
+	item := models.ScheduledItem{
+		Title:           "Future Item",
+		Description:     "Future description",
+		StartsAt:        now.Add(1 * time.Hour),
+		Repeats:         false,
+		NextExecutionAt: now.Add(1 * time.Hour),
 	}
 
-	createdPast := store.CreateScheduledItem(pastItem)
-	if createdPast.NextExecutionAt != nil {
-		t.Error("Expected NextExecutionAt to be nil for past non-repeating item")
+	created := store.CreateScheduledItem(context.Background(), item)
+	if !created.NextExecutionAt.Equal(item.NextExecutionAt) {
+		t.Errorf("Expected NextExecutionAt %v, got %v", item.NextExecutionAt, created.NextExecutionAt)
 	}
-
-	// Test repeating item with cron expression
-	cronExpr := "0 9 * * MON-FRI" // 9 AM weekdays
-	repeatingItem := models.ScheduledItem{
-		Title:          "Repeating Item",
-		Description:    "Repeating description",
-		StartsAt:       now.Add(-1 * time.Hour), // Started in the past
-		Repeats:        true,
-		CronExpression: &cronExpr,
+	retrieved, found := store.GetScheduledItem(context.Background(), created.ID)
+	if !found {
+		t.Fatalf("Expected to find item %d", created.ID)
 	}
-
-	createdRepeating := store.CreateScheduledItem(repeatingItem)
-	if createdRepeating.NextExecutionAt == nil {
-		t.Error("Expected NextExecutionAt to be set for repeating item")
-	} else if !createdRepeating.NextExecutionAt.After(now) {
-		t.Error("Expected NextExecutionAt to be in the future for repeating item")
+	if !retrieved.NextExecutionAt.Equal(item.NextExecutionAt) {
+		t.Errorf("Expected stored NextExecutionAt %v, got %v", item.NextExecutionAt, retrieved.NextExecutionAt)
 	}
 }
-
-// Helper function to create time pointers
-func timePtr(t time.Time) *time.Time {
-	return &t
-}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+)
+
+// ScopedScheduledItemStore confines the scheduled items read and changed through another
+// store to those the context's user may see (WithUser): the items they own, those shared
+// with them and those without an owner. Viewers can't change the items shared with them
+// and only owners can delete items; items a user can't see are reported as not found.
+// Contexts without a user, like the scheduler's, see every item of their tenant.
+type ScopedScheduledItemStore struct {
+	ScheduledItemStore
+	shares ItemShareStore
+}
+
+// NewScopedScheduledItemStore wraps the given store so it only sees the items shares
+// give the context's user access to
+func NewScopedScheduledItemStore(store ScheduledItemStore, shares ItemShareStore) *ScopedScheduledItemStore {
+	return &ScopedScheduledItemStore{
+		ScheduledItemStore: store,
+		shares:             shares,
+	}
+}
+
+// GetScheduledItem returns the item when the context's user may see it
+func (s *ScopedScheduledItemStore) GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool) {
+	item, exists := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	if !exists || ScheduledItemAccess(ctx, s.shares, item) == ItemAccessNone {
+		return models.ScheduledItem{}, false
+	}
+	return item, true
+}
+
+// GetAllScheduledItems returns the items the context's user may see
+func (s *ScopedScheduledItemStore) GetAllScheduledItems(ctx context.Context) []models.ScheduledItem {
+	return s.visible(ctx, s.ScheduledItemStore.GetAllScheduledItems(ctx))
+}
+
+// StreamScheduledItems streams the items the context's user may see
+func (s *ScopedScheduledItemStore) StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error] {
+	return func(yield func(models.ScheduledItem, error) bool) {
+		for item, err := range filterStream(s.ScheduledItemStore.StreamScheduledItems(ctx), s.sees(ctx)) {
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
+
+// GetScheduledItemsPage returns the items of a page the context's user may see, so pages
+// can come back shorter than limit. The cursor still marks the end of the page as read,
+// so no item is skipped or repeated.
+func (s *ScopedScheduledItemStore) GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error) {
+	items, next, err := s.ScheduledItemStore.GetScheduledItemsPage(ctx, limit, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.visible(ctx, items), next, nil
+}
+
+// GetNextScheduledItems returns the next items the context's user may see
+func (s *ScopedScheduledItemStore) GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error) {
+	items, err := s.ScheduledItemStore.GetNextScheduledItems(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.visible(ctx, items), nil
+}
+
+// UpdateScheduledItem updates the item when the context's user may edit it
+func (s *ScopedScheduledItemStore) UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error) {
+	if !s.allowed(ctx, id, ItemAccessEdit) {
+		return models.ScheduledItem{}, ErrNotFound
+	}
+	return s.ScheduledItemStore.UpdateScheduledItem(ctx, id, item)
+}
+
+// UpdateNextExecutionAt reschedules the item when the context's user may edit it
+func (s *ScopedScheduledItemStore) UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool {
+	if !s.allowed(ctx, id, ItemAccessEdit) {
+		return false
+	}
+	return s.ScheduledItemStore.UpdateNextExecutionAt(ctx, id, nextExecutionAt)
+}
+
+// UpdateNextExecutionAtBatch updates the items' next execution times when the context's
+// user may edit all of them
+func (s *ScopedScheduledItemStore) UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error {
+	for id := range nextExecutions {
+		if !s.allowed(ctx, id, ItemAccessEdit) {
+			return fmt.Errorf("%w: scheduled item %d", ErrNotFound, id)
+		}
+	}
+	return s.ScheduledItemStore.UpdateNextExecutionAtBatch(ctx, nextExecutions)
+}
+
+// DeleteScheduledItem deletes the item when the context's user owns it
+func (s *ScopedScheduledItemStore) DeleteScheduledItem(ctx context.Context, id int64) bool {
+	if !s.allowed(ctx, id, ItemAccessOwner) {
+		return false
+	}
+	return s.ScheduledItemStore.DeleteScheduledItem(ctx, id)
+}
+
+// SeesEvent reports whether the context's user may see the scheduled item an event
+// published on the bus is about, as of when it is delivered. Once an item is deleted only
+// its owner sees its deletion, as its shares are deleted with it. Events about anything
+// else, like todo items, are seen by every user of their tenant.
+func (s *ScopedScheduledItemStore) SeesEvent(ctx context.Context, event events.Event) bool {
+	switch data := event.Data.(type) {
+	case models.ScheduledItem:
+		return ScheduledItemAccess(ctx, s.shares, data) != ItemAccessNone
+	case models.ExecutionLog:
+		return !s.hides(ctx, data.ScheduledItemID)
+	case rescheduledEventData:
+		return !s.hides(ctx, data.ID)
+	case deletedEventData:
+		deleted := models.ScheduledItem{ID: data.ID, OwnerID: data.ownerID}
+		return ScheduledItemAccess(ctx, s.shares, deleted) != ItemAccessNone
+	default:
+		return true
+	}
+}
+
+// allowed reports whether the context's user has at least the access needed to an item.
+// Items that don't exist are left for the wrapped store to report.
+func (s *ScopedScheduledItemStore) allowed(ctx context.Context, id int64, needed ItemAccess) bool {
+	if _, scoped := UserFromContext(ctx); !scoped {
+		return true
+	}
+	item, exists := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	return !exists || ScheduledItemAccess(ctx, s.shares, item) >= needed
+}
+
+// visible filters items down to those the context's user may see
+func (s *ScopedScheduledItemStore) visible(ctx context.Context, items []models.ScheduledItem) []models.ScheduledItem {
+	sees := s.sees(ctx)
+	return slices.DeleteFunc(items, func(item models.ScheduledItem) bool { return !sees(item) })
+}
+
+// sees returns whether the context's user may see an item, looking up the items shared
+// with them once
+func (s *ScopedScheduledItemStore) sees(ctx context.Context) func(item models.ScheduledItem) bool {
+	userID, scoped := UserFromContext(ctx)
+	if !scoped || s.shares == nil {
+		return func(models.ScheduledItem) bool { return true }
+	}
+
+	shared := make(map[int64]bool)
+	for _, share := range s.shares.GetSharesForUser(ctx, userID) {
+		shared[share.ScheduledItemID] = true
+	}
+	return func(item models.ScheduledItem) bool {
+		return item.OwnerID == nil || *item.OwnerID == userID || shared[item.ID]
+	}
+}
+
+// hides reports whether an item exists that the context's user may not see
+func (s *ScopedScheduledItemStore) hides(ctx context.Context, id int64) bool {
+	if _, scoped := UserFromContext(ctx); !scoped {
+		return false
+	}
+	item, exists := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+	return exists && ScheduledItemAccess(ctx, s.shares, item) == ItemAccessNone
+}
+
+// hider returns whether the context's user may not see an existing item, for filtering
+// the rows that refer to items without loading every item of the tenant. The items shared
+// with the user are looked up once and each item at most once, so it suits a single
+// listing or page, not a live stream whose items may be shared or unshared meanwhile.
+func (s *ScopedScheduledItemStore) hider(ctx context.Context) func(id int64) bool {
+	if _, scoped := UserFromContext(ctx); !scoped {
+		return func(int64) bool { return false }
+	}
+
+	sees := s.sees(ctx)
+	hidden := make(map[int64]bool)
+	return func(id int64) bool {
+		hide, known := hidden[id]
+		if !known {
+			item, exists := s.ScheduledItemStore.GetScheduledItem(ctx, id)
+			hide = exists && !sees(item)
+			hidden[id] = hide
+		}
+		return hide
+	}
+}
@@ -1,16 +1,30 @@
 package store
 
 import (
+	"context"
+	"iter"
 	"time"
 	"periodic-api/internal/models"
 )
 
+// ScheduledItemCursor marks the last scheduled item of a page; the next page starts with
+// the items that come after it in next_execution_at order, ties broken by ID
+type ScheduledItemCursor struct {
+	NextExecutionAt time.Time
+	ID              int64
+}
+
 // ScheduledItemStore defines the interface for scheduled item storage operations
 type ScheduledItemStore interface {
-	CreateScheduledItem(item models.ScheduledItem) models.ScheduledItem
-	GetScheduledItem(id int64) (models.ScheduledItem, bool)
-	GetAllScheduledItems() []models.ScheduledItem
-	GetNextScheduledItems(limit int, offset int64) ([]models.ScheduledItem, error)
-	UpdateNextExecutionAt(id int64, nextExecutionAt time.Time) bool
-	DeleteScheduledItem(id int64) bool
+	CreateScheduledItem(ctx context.Context, item models.ScheduledItem) models.ScheduledItem
+	GetScheduledItem(ctx context.Context, id int64) (models.ScheduledItem, bool)
+	GetAllScheduledItems(ctx context.Context) []models.ScheduledItem
+	StreamScheduledItems(ctx context.Context) iter.Seq2[models.ScheduledItem, error]
+	GetScheduledItemsPage(ctx context.Context, limit int, cursor *ScheduledItemCursor) ([]models.ScheduledItem, *ScheduledItemCursor, error)
+	GetNextScheduledItems(ctx context.Context, limit int, offset int64) ([]models.ScheduledItem, error)
+	ClaimDueItems(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledItem, error)
+	UpdateScheduledItem(ctx context.Context, id int64, item models.ScheduledItem) (models.ScheduledItem, error)
+	UpdateNextExecutionAt(ctx context.Context, id int64, nextExecutionAt time.Time) bool
+	UpdateNextExecutionAtBatch(ctx context.Context, nextExecutions map[int64]time.Time) error
+	DeleteScheduledItem(ctx context.Context, id int64) bool
 }
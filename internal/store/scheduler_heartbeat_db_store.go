@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// PostgresSchedulerHeartbeatStore provides PostgreSQL storage operations for scheduler heartbeats
+type PostgresSchedulerHeartbeatStore struct {
+	db *sql.DB
+}
+
+// NewPostgresSchedulerHeartbeatStore creates a new PostgreSQL scheduler heartbeat store with the given database connection
+func NewPostgresSchedulerHeartbeatStore(db *sql.DB) *PostgresSchedulerHeartbeatStore {
+	return &PostgresSchedulerHeartbeatStore{
+		db: db,
+	}
+}
+
+// SaveHeartbeat creates or replaces the heartbeat of a scheduler instance
+func (s *PostgresSchedulerHeartbeatStore) SaveHeartbeat(ctx context.Context, heartbeat models.SchedulerHeartbeat) bool {
+	query := `
+		INSERT INTO scheduler_heartbeats 
+		(instance_id, started_at, last_tick_at, last_success_at, interval_ms, items_processed, error_count) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		ON CONFLICT (instance_id) DO UPDATE SET
+			started_at = EXCLUDED.started_at,
+			last_tick_at = EXCLUDED.last_tick_at,
+			last_success_at = EXCLUDED.last_success_at,
+			interval_ms = EXCLUDED.interval_ms,
+			items_processed = EXCLUDED.items_processed,
+			error_count = EXCLUDED.error_count
+	`
+
+	_, err := timed(s.db).ExecContext(
+		ctx,
+		query,
+		heartbeat.InstanceID,
+		heartbeat.StartedAt,
+		heartbeat.LastTickAt,
+		heartbeat.LastSuccessAt,
+		heartbeat.IntervalMs,
+		heartbeat.ItemsProcessed,
+		heartbeat.ErrorCount,
+	)
+
+	if err != nil {
+		logging.Errorf("Error saving scheduler heartbeat: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// GetHeartbeat retrieves the heartbeat of a scheduler instance from the database
+func (s *PostgresSchedulerHeartbeatStore) GetHeartbeat(ctx context.Context, instanceID string) (models.SchedulerHeartbeat, bool) {
+	var heartbeat models.SchedulerHeartbeat
+	query := `
+		SELECT instance_id, started_at, last_tick_at, last_success_at, interval_ms, items_processed, error_count 
+		FROM scheduler_heartbeats 
+		WHERE instance_id = $1
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, instanceID).Scan(
+		&heartbeat.InstanceID,
+		&heartbeat.StartedAt,
+		&heartbeat.LastTickAt,
+		&heartbeat.LastSuccessAt,
+		&heartbeat.IntervalMs,
+		&heartbeat.ItemsProcessed,
+		&heartbeat.ErrorCount,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.SchedulerHeartbeat{}, false
+		}
+		logging.Errorf("Error getting scheduler heartbeat: %v", err)
+		return models.SchedulerHeartbeat{}, false
+	}
+
+	return heartbeat, true
+}
+
+// GetAllHeartbeats returns the heartbeats of all scheduler instances from the database
+func (s *PostgresSchedulerHeartbeatStore) GetAllHeartbeats(ctx context.Context) []models.SchedulerHeartbeat {
+	query := `
+		SELECT instance_id, started_at, last_tick_at, last_success_at, interval_ms, items_processed, error_count 
+		FROM scheduler_heartbeats
+		ORDER BY last_tick_at DESC
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query)
+	if err != nil {
+		logging.Errorf("Error querying scheduler heartbeats: %v", err)
+		return []models.SchedulerHeartbeat{}
+	}
+	defer rows.Close()
+
+	var heartbeats []models.SchedulerHeartbeat
+	for rows.Next() {
+		var heartbeat models.SchedulerHeartbeat
+
+		err := rows.Scan(
+			&heartbeat.InstanceID,
+			&heartbeat.StartedAt,
+			&heartbeat.LastTickAt,
+			&heartbeat.LastSuccessAt,
+			&heartbeat.IntervalMs,
+			&heartbeat.ItemsProcessed,
+			&heartbeat.ErrorCount,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		heartbeats = append(heartbeats, heartbeat)
+	}
+
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+
+	return heartbeats
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoSchedulerHeartbeat is the DynamoDB representation of a scheduler heartbeat
+type dynamoSchedulerHeartbeat struct {
+	PK             string     `dynamodbav:"pk"`
+	SK             string     `dynamodbav:"sk"`
+	InstanceID     string     `dynamodbav:"instance_id"`
+	StartedAt      time.Time  `dynamodbav:"started_at"`
+	LastTickAt     time.Time  `dynamodbav:"last_tick_at"`
+	LastSuccessAt  *time.Time `dynamodbav:"last_success_at,omitempty"`
+	IntervalMs     int64      `dynamodbav:"interval_ms"`
+	ItemsProcessed int64      `dynamodbav:"items_processed"`
+	ErrorCount     int64      `dynamodbav:"error_count"`
+}
+
+// toModel converts the DynamoDB representation back to a scheduler heartbeat
+func (r dynamoSchedulerHeartbeat) toModel() models.SchedulerHeartbeat {
+	return models.SchedulerHeartbeat{
+		InstanceID:     r.InstanceID,
+		StartedAt:      r.StartedAt,
+		LastTickAt:     r.LastTickAt,
+		LastSuccessAt:  r.LastSuccessAt,
+		IntervalMs:     r.IntervalMs,
+		ItemsProcessed: r.ItemsProcessed,
+		ErrorCount:     r.ErrorCount,
+	}
+}
+
+// DynamoSchedulerHeartbeatStore provides DynamoDB storage operations for scheduler heartbeats
+type DynamoSchedulerHeartbeatStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoSchedulerHeartbeatStore creates a new DynamoDB scheduler heartbeat store using the given client and table
+func NewDynamoSchedulerHeartbeatStore(client *dynamodb.Client, table string) *DynamoSchedulerHeartbeatStore {
+	return &DynamoSchedulerHeartbeatStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// SaveHeartbeat creates or replaces the heartbeat of a scheduler instance
+func (s *DynamoSchedulerHeartbeatStore) SaveHeartbeat(ctx context.Context, heartbeat models.SchedulerHeartbeat) bool {
+	record, err := attributevalue.MarshalMap(dynamoSchedulerHeartbeat{
+		PK:             dynamoEntitySchedulerHeartbeat,
+		SK:             heartbeat.InstanceID,
+		InstanceID:     heartbeat.InstanceID,
+		StartedAt:      heartbeat.StartedAt,
+		LastTickAt:     heartbeat.LastTickAt,
+		LastSuccessAt:  heartbeat.LastSuccessAt,
+		IntervalMs:     heartbeat.IntervalMs,
+		ItemsProcessed: heartbeat.ItemsProcessed,
+		ErrorCount:     heartbeat.ErrorCount,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling scheduler heartbeat: %v", err)
+		return false
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error saving scheduler heartbeat: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// GetHeartbeat retrieves the heartbeat of a scheduler instance from the table
+func (s *DynamoSchedulerHeartbeatStore) GetHeartbeat(ctx context.Context, instanceID string) (models.SchedulerHeartbeat, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntitySchedulerHeartbeat, instanceID),
+	})
+	if err != nil {
+		logging.Errorf("Error getting scheduler heartbeat: %v", err)
+		return models.SchedulerHeartbeat{}, false
+	}
+	if output.Item == nil {
+		return models.SchedulerHeartbeat{}, false
+	}
+
+	var record dynamoSchedulerHeartbeat
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling scheduler heartbeat: %v", err)
+		return models.SchedulerHeartbeat{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllHeartbeats returns the heartbeats of all scheduler instances, most recent tick first
+func (s *DynamoSchedulerHeartbeatStore) GetAllHeartbeats(ctx context.Context) []models.SchedulerHeartbeat {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntitySchedulerHeartbeat},
+		},
+	})
+
+	var heartbeats []models.SchedulerHeartbeat
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying scheduler heartbeats: %v", err)
+			return []models.SchedulerHeartbeat{}
+		}
+
+		var records []dynamoSchedulerHeartbeat
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling scheduler heartbeats: %v", err)
+			return []models.SchedulerHeartbeat{}
+		}
+		for _, record := range records {
+			heartbeats = append(heartbeats, record.toModel())
+		}
+	}
+
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].LastTickAt.After(heartbeats[j].LastTickAt)
+	})
+
+	return heartbeats
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"sync"
+)
+
+// MemorySchedulerHeartbeatStore provides in-memory storage operations for scheduler heartbeats
+type MemorySchedulerHeartbeatStore struct {
+	sync.RWMutex
+	heartbeats map[string]models.SchedulerHeartbeat
+}
+
+// NewMemorySchedulerHeartbeatStore creates a new in-memory scheduler heartbeat store
+func NewMemorySchedulerHeartbeatStore() *MemorySchedulerHeartbeatStore {
+	return &MemorySchedulerHeartbeatStore{
+		heartbeats: make(map[string]models.SchedulerHeartbeat),
+	}
+}
+
+// SaveHeartbeat creates or replaces the heartbeat of a scheduler instance
+func (s *MemorySchedulerHeartbeatStore) SaveHeartbeat(ctx context.Context, heartbeat models.SchedulerHeartbeat) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	s.heartbeats[heartbeat.InstanceID] = heartbeat
+	return true
+}
+
+// GetHeartbeat retrieves the heartbeat of a scheduler instance from the in-memory store
+func (s *MemorySchedulerHeartbeatStore) GetHeartbeat(ctx context.Context, instanceID string) (models.SchedulerHeartbeat, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	heartbeat, exists := s.heartbeats[instanceID]
+	return heartbeat, exists
+}
+
+// GetAllHeartbeats returns the heartbeats of all scheduler instances from the in-memory store
+func (s *MemorySchedulerHeartbeatStore) GetAllHeartbeats(ctx context.Context) []models.SchedulerHeartbeat {
+	s.RLock()
+	defer s.RUnlock()
+
+	heartbeats := make([]models.SchedulerHeartbeat, 0, len(s.heartbeats))
+	for _, heartbeat := range s.heartbeats {
+		heartbeats = append(heartbeats, heartbeat)
+	}
+	return heartbeats
+}
@@ -0,0 +1,13 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// SchedulerHeartbeatStore defines the interface for scheduler heartbeat storage operations
+type SchedulerHeartbeatStore interface {
+	SaveHeartbeat(ctx context.Context, heartbeat models.SchedulerHeartbeat) bool
+	GetHeartbeat(ctx context.Context, instanceID string) (models.SchedulerHeartbeat, bool)
+	GetAllHeartbeats(ctx context.Context) []models.SchedulerHeartbeat
+}
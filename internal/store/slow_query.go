@@ -0,0 +1,230 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultSlowQueryThreshold is how long a statement may take before it is logged as
+	// slow when DB_SLOW_QUERY_THRESHOLD is not set
+	defaultSlowQueryThreshold = 200 * time.Millisecond
+	// maxLoggedArgLength truncates long parameters, such as JSON documents, in slow query logs
+	maxLoggedArgLength = 100
+)
+
+// slowQueryThreshold holds the current threshold in nanoseconds; 0 disables slow query logging
+var slowQueryThreshold atomic.Int64
+
+func init() {
+	slowQueryThreshold.Store(int64(defaultSlowQueryThreshold))
+}
+
+// SlowQueryThresholdFromEnv returns the slow query threshold from the DB_SLOW_QUERY_THRESHOLD
+// environment variable, where 0 disables slow query logging
+func SlowQueryThresholdFromEnv() time.Duration {
+	valueStr := os.Getenv("DB_SLOW_QUERY_THRESHOLD")
+	if valueStr == "" {
+		return defaultSlowQueryThreshold
+	}
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value < 0 {
+		logging.Warnf("Invalid DB_SLOW_QUERY_THRESHOLD format, using default: %v", defaultSlowQueryThreshold)
+		return defaultSlowQueryThreshold
+	}
+	return value
+}
+
+// SetSlowQueryThreshold sets how long a PostgreSQL store statement may take before it is
+// logged and counted as slow; 0 disables slow query logging
+func SetSlowQueryThreshold(threshold time.Duration) {
+	slowQueryThreshold.Store(int64(threshold))
+}
+
+// slowQueryCounts counts slow statements by the store operation that ran them
+type slowQueryCounts struct {
+	mu      sync.Mutex
+	counts  map[string]uint64
+	seconds map[string]float64
+}
+
+var slowQueries = &slowQueryCounts{
+	counts:  make(map[string]uint64),
+	seconds: make(map[string]float64),
+}
+
+// record counts a slow statement run by the named operation
+func (c *slowQueryCounts) record(name string, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[name]++
+	c.seconds[name] += duration.Seconds()
+}
+
+// WriteSlowQueryMetrics writes the slow statement counts of the PostgreSQL stores, by store
+// operation, in the Prometheus text exposition format
+func WriteSlowQueryMetrics(w io.Writer) {
+	slowQueries.mu.Lock()
+	defer slowQueries.mu.Unlock()
+
+	names := make([]string, 0, len(slowQueries.counts))
+	for name := range slowQueries.counts {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	threshold := time.Duration(slowQueryThreshold.Load())
+	fmt.Fprintf(w, "# HELP db_slow_query_threshold_seconds Duration above which a statement is counted as slow; 0 when disabled.\n")
+	fmt.Fprintf(w, "# TYPE db_slow_query_threshold_seconds gauge\n")
+	fmt.Fprintf(w, "db_slow_query_threshold_seconds %s\n", strconv.FormatFloat(threshold.Seconds(), 'g', -1, 64))
+
+	fmt.Fprintf(w, "# HELP db_slow_queries_total Statements that took longer than the slow query threshold, by store operation.\n")
+	fmt.Fprintf(w, "# TYPE db_slow_queries_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "db_slow_queries_total{query=%q} %d\n", name, slowQueries.counts[name])
+	}
+
+	fmt.Fprintf(w, "# HELP db_slow_query_seconds_total Time spent in slow statements, by store operation.\n")
+	fmt.Fprintf(w, "# TYPE db_slow_query_seconds_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "db_slow_query_seconds_total{query=%q} %s\n", name, strconv.FormatFloat(slowQueries.seconds[name], 'g', -1, 64))
+	}
+}
+
+// timedQuerier times the statements it runs, logging and counting those slower than the
+// slow query threshold. Queries are timed until their first rows arrive.
+type timedQuerier struct {
+	dbQuerier
+}
+
+// timed wraps a database or transaction so its statements are timed
+func timed(q dbQuerier) dbQuerier {
+	return &timedQuerier{q}
+}
+
+func (q *timedQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := q.dbQuerier.ExecContext(ctx, query, args...)
+	observeQuery(query, args, time.Since(start))
+	return result, err
+}
+
+func (q *timedQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := q.dbQuerier.QueryContext(ctx, query, args...)
+	observeQuery(query, args, time.Since(start))
+	return rows, err
+}
+
+func (q *timedQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := q.dbQuerier.QueryRowContext(ctx, query, args...)
+	observeQuery(query, args, time.Since(start))
+	return row
+}
+
+// observeQuery logs and counts a statement if it was slow. It must be called directly by
+// a timedQuerier method, so that the store operation running the statement can be named.
+func observeQuery(query string, args []any, duration time.Duration) {
+	threshold := time.Duration(slowQueryThreshold.Load())
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	name := callerName(3)
+	slowQueries.record(name, duration)
+	logging.Warnf("Slow query %s took %v (threshold %v): %s; args: %s",
+		name, duration, threshold, strings.Join(strings.Fields(query), " "), formatQueryArgs(args))
+}
+
+// callerName returns the name of the function skip frames up the stack, without its
+// package path, such as PostgresScheduledItemStore.CreateScheduledItem
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimPrefix(name, "store.")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// formatQueryArgs describes the parameters of a statement for the slow query log,
+// truncating long values and hiding sensitive ones
+func formatQueryArgs(args []any) string {
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case sensitiveArg:
+			formatted[i] = "[REDACTED]"
+		case []byte:
+			formatted[i] = fmt.Sprintf("[%d bytes]", len(value))
+		default:
+			text := fmt.Sprintf("%v", derefArg(arg))
+			if len(text) > maxLoggedArgLength {
+				text = text[:maxLoggedArgLength] + "..."
+			}
+			formatted[i] = strconv.Quote(text)
+		}
+	}
+	return "[" + strings.Join(formatted, ", ") + "]"
+}
+
+// derefArg returns the value a pointer parameter points to, or nil for a nil pointer
+func derefArg(arg any) any {
+	switch value := arg.(type) {
+	case *string:
+		if value == nil {
+			return nil
+		}
+		return *value
+	case *int64:
+		if value == nil {
+			return nil
+		}
+		return *value
+	case *time.Time:
+		if value == nil {
+			return nil
+		}
+		return *value
+	}
+	return arg
+}
+
+// sensitiveArg is a statement parameter, such as a password hash, that is passed to the
+// database as is but never logged
+type sensitiveArg struct {
+	value any
+}
+
+// sensitive marks a statement parameter to be left out of the slow query log
+func sensitive(value any) sensitiveArg {
+	return sensitiveArg{value: value}
+}
+
+// Value passes the wrapped parameter to the driver
+func (a sensitiveArg) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(a.value)
+}
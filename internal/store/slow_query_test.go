@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sleepingQuerier is a database whose statements take a fixed time
+type sleepingQuerier struct {
+	delay time.Duration
+}
+
+func (q sleepingQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	time.Sleep(q.delay)
+	return nil, nil
+}
+
+func (q sleepingQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	time.Sleep(q.delay)
+	return nil, nil
+}
+
+func (q sleepingQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	time.Sleep(q.delay)
+	return nil
+}
+
+// slowQueryStore runs statements the way the PostgreSQL stores do
+type slowQueryStore struct {
+	db dbQuerier
+}
+
+func (s *slowQueryStore) UpdateThing(ctx context.Context, secret string) {
+	timed(s.db).ExecContext(ctx, "UPDATE things\n\t\tSET secret = $1, note = $2", sensitive(secret), strings.Repeat("x", 200))
+}
+
+func TestSlowQueryMetrics(t *testing.T) {
+	defer SetSlowQueryThreshold(defaultSlowQueryThreshold)
+	SetSlowQueryThreshold(5 * time.Millisecond)
+
+	// Statements under the threshold aren't counted
+	fast := &slowQueryStore{db: sleepingQuerier{}}
+	fast.UpdateThing(context.Background(), "hunter2")
+
+	slow := &slowQueryStore{db: sleepingQuerier{delay: 10 * time.Millisecond}}
+	slow.UpdateThing(context.Background(), "hunter2")
+	slow.UpdateThing(context.Background(), "hunter2")
+
+	var metrics strings.Builder
+	WriteSlowQueryMetrics(&metrics)
+	for _, line := range []string{
+		"db_slow_query_threshold_seconds 0.005\n",
+		`db_slow_queries_total{query="slowQueryStore.UpdateThing"} 2` + "\n",
+	} {
+		if !strings.Contains(metrics.String(), line) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, metrics.String())
+		}
+	}
+
+	// A threshold of 0 disables slow query logging
+	SetSlowQueryThreshold(0)
+	slow.UpdateThing(context.Background(), "hunter2")
+	metrics.Reset()
+	WriteSlowQueryMetrics(&metrics)
+	if !strings.Contains(metrics.String(), `db_slow_queries_total{query="slowQueryStore.UpdateThing"} 2`+"\n") {
+		t.Errorf("Expected no more slow queries to be counted, got:\n%s", metrics.String())
+	}
+}
+
+func TestFormatQueryArgs(t *testing.T) {
+	note := "pending"
+	var missing *string
+	got := formatQueryArgs([]any{sensitive("hunter2"), []byte(`{"a":1}`), &note, missing, int64(42), strings.Repeat("x", 120)})
+	want := `[[REDACTED], [7 bytes], "pending", "<nil>", "42", "` + strings.Repeat("x", 100) + `..."]`
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
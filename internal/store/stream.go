@@ -0,0 +1,29 @@
+package store
+
+import "iter"
+
+// sliceStream streams the items of an already loaded listing, for the stores that can't
+// read their listings a row at a time
+func sliceStream[T any](items []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// filterStream streams the items of stream for which keep returns true, along with any error
+func filterStream[T any](stream iter.Seq2[T, error], keep func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item, err := range stream {
+			if err == nil && !keep(item) {
+				continue
+			}
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DefaultTenant owns the data of single-tenant deployments, along with everything stored
+// before tenants were introduced
+const DefaultTenant = "default"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context whose store operations only see and change the scheduled
+// items, todo items, users and execution logs of the tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set by WithTenant, or DefaultTenant when there is none.
+// Every store operation is confined to this tenant, so data can't leak between tenants
+// even when a caller forgets to check.
+func TenantFromContext(ctx context.Context) string {
+	if tenant, ok := ctx.Value(tenantContextKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return DefaultTenant
+}
+
+// ownTenant reports whether data belonging to tenant may be seen in ctx. Data stored
+// without a tenant belongs to DefaultTenant.
+func ownTenant(ctx context.Context, tenant string) bool {
+	if tenant == "" {
+		tenant = DefaultTenant
+	}
+	return tenant == TenantFromContext(ctx)
+}
+
+// dynamoTenantCondition returns the condition matching records of the context's tenant,
+// with the value it refers to. Records written before tenants have no tenant_id and
+// belong to DefaultTenant.
+func dynamoTenantCondition(ctx context.Context) (string, map[string]types.AttributeValue) {
+	tenant := TenantFromContext(ctx)
+	values := map[string]types.AttributeValue{":tenant_id": &types.AttributeValueMemberS{Value: tenant}}
+	if tenant == DefaultTenant {
+		return "(tenant_id = :tenant_id OR attribute_not_exists(tenant_id))", values
+	}
+	return "tenant_id = :tenant_id", values
+}
+
+// andDynamoCondition combines a condition with an existing one, merging the values they refer to
+func andDynamoCondition(existing *string, values map[string]types.AttributeValue, condition string, conditionValues map[string]types.AttributeValue) (*string, map[string]types.AttributeValue) {
+	if values == nil {
+		values = make(map[string]types.AttributeValue)
+	}
+	for name, value := range conditionValues {
+		values[name] = value
+	}
+	if existing == nil || *existing == "" {
+		return aws.String(condition), values
+	}
+	return aws.String(strings.Join([]string{"(" + *existing + ")", condition}, " AND ")), values
+}
+
+// tenantDynamoQuery filters a query to the records of the context's tenant
+func tenantDynamoQuery(ctx context.Context, input *dynamodb.QueryInput) {
+	condition, values := dynamoTenantCondition(ctx)
+	input.FilterExpression, input.ExpressionAttributeValues = andDynamoCondition(input.FilterExpression, input.ExpressionAttributeValues, condition, values)
+}
+
+// interleaveTenants orders items, sorted by next execution, so that every tenant's first
+// item comes before any tenant's second one and so on. Taking a batch from the front then
+// shares it fairly between tenants, however many items one of them has due.
+func interleaveTenants(items []models.ScheduledItem) []models.ScheduledItem {
+	ranks := make(map[string]int)
+	rank := make([]int, len(items))
+	for i, item := range items {
+		tenant := item.TenantID
+		if tenant == "" {
+			tenant = DefaultTenant
+		}
+		rank[i] = ranks[tenant]
+		ranks[tenant]++
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	// The sort is stable, so items of the same rank stay in execution order
+	sort.SliceStable(order, func(i, j int) bool {
+		return rank[order[i]] < rank[order[j]]
+	})
+
+	interleaved := make([]models.ScheduledItem, len(items))
+	for i, index := range order {
+		interleaved[i] = items[index]
+	}
+	return interleaved
+}
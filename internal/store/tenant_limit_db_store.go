@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+)
+
+// PostgresTenantLimitStore provides PostgreSQL storage operations for the limits of tenants
+type PostgresTenantLimitStore struct {
+	db *sql.DB
+}
+
+// NewPostgresTenantLimitStore creates a new PostgreSQL tenant limit store with the given database connection
+func NewPostgresTenantLimitStore(db *sql.DB) *PostgresTenantLimitStore {
+	return &PostgresTenantLimitStore{
+		db: db,
+	}
+}
+
+// SaveTenantLimits creates or replaces the limits of a tenant in the database
+func (s *PostgresTenantLimitStore) SaveTenantLimits(ctx context.Context, limits models.TenantLimits) (models.TenantLimits, bool) {
+	query := `
+		INSERT INTO tenant_limits (tenant_id, max_scheduled_items, max_daily_generations, max_webhooks)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			max_scheduled_items = EXCLUDED.max_scheduled_items,
+			max_daily_generations = EXCLUDED.max_daily_generations,
+			max_webhooks = EXCLUDED.max_webhooks,
+			updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, limits.Tenant, limits.MaxScheduledItems, limits.MaxDailyGenerations, limits.MaxWebhooks).
+		Scan(&limits.UpdatedAt)
+	if err != nil {
+		logging.Errorf("Error saving tenant limits: %v", err)
+		return models.TenantLimits{}, false
+	}
+
+	return limits, true
+}
+
+// GetTenantLimits retrieves the limits of a tenant from the database
+func (s *PostgresTenantLimitStore) GetTenantLimits(ctx context.Context, tenant string) (models.TenantLimits, bool) {
+	var limits models.TenantLimits
+	query := `
+		SELECT tenant_id, max_scheduled_items, max_daily_generations, max_webhooks, updated_at
+		FROM tenant_limits
+		WHERE tenant_id = $1
+	`
+
+	err := timed(s.db).QueryRowContext(ctx, query, tenant).Scan(
+		&limits.Tenant,
+		&limits.MaxScheduledItems,
+		&limits.MaxDailyGenerations,
+		&limits.MaxWebhooks,
+		&limits.UpdatedAt,
+	)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			logging.Errorf("Error getting tenant limits: %v", err)
+		}
+		return models.TenantLimits{}, false
+	}
+
+	return limits, true
+}
+
+// GetAllTenantLimits returns the limits of every tenant from the database, ordered by tenant
+func (s *PostgresTenantLimitStore) GetAllTenantLimits(ctx context.Context) []models.TenantLimits {
+	query := `
+		SELECT tenant_id, max_scheduled_items, max_daily_generations, max_webhooks, updated_at
+		FROM tenant_limits
+		ORDER BY tenant_id
+	`
+
+	rows, err := timed(s.db).QueryContext(ctx, query)
+	if err != nil {
+		logging.Errorf("Error querying tenant limits: %v", err)
+		return []models.TenantLimits{}
+	}
+	defer rows.Close()
+
+	all := []models.TenantLimits{}
+	for rows.Next() {
+		var limits models.TenantLimits
+		if err := rows.Scan(&limits.Tenant, &limits.MaxScheduledItems, &limits.MaxDailyGenerations, &limits.MaxWebhooks, &limits.UpdatedAt); err != nil {
+			logging.Errorf("Error scanning tenant limits row: %v", err)
+			continue
+		}
+		all = append(all, limits)
+	}
+	if err := rows.Err(); err != nil {
+		logging.Errorf("Error iterating tenant limits rows: %v", err)
+	}
+
+	return all
+}
+
+// DeleteTenantLimits removes the limits of a tenant from the database
+func (s *PostgresTenantLimitStore) DeleteTenantLimits(ctx context.Context, tenant string) bool {
+	query := `DELETE FROM tenant_limits WHERE tenant_id = $1`
+	result, err := timed(s.db).ExecContext(ctx, query, tenant)
+	if err != nil {
+		logging.Errorf("Error deleting tenant limits: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoTenantLimits is the DynamoDB representation of the limits of a tenant. They are
+// keyed by tenant, so listing them is a single Query ordered by tenant.
+type dynamoTenantLimits struct {
+	PK                  string    `dynamodbav:"pk"`
+	SK                  string    `dynamodbav:"sk"`
+	Tenant              string    `dynamodbav:"tenant_id"`
+	MaxScheduledItems   int       `dynamodbav:"max_scheduled_items"`
+	MaxDailyGenerations int       `dynamodbav:"max_daily_generations"`
+	MaxWebhooks         int       `dynamodbav:"max_webhooks"`
+	UpdatedAt           time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to the limits of a tenant
+func (r dynamoTenantLimits) toModel() models.TenantLimits {
+	updatedAt := r.UpdatedAt
+	return models.TenantLimits{
+		Tenant:              r.Tenant,
+		MaxScheduledItems:   r.MaxScheduledItems,
+		MaxDailyGenerations: r.MaxDailyGenerations,
+		MaxWebhooks:         r.MaxWebhooks,
+		UpdatedAt:           &updatedAt,
+	}
+}
+
+// DynamoTenantLimitStore provides DynamoDB storage operations for the limits of tenants
+type DynamoTenantLimitStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoTenantLimitStore creates a new DynamoDB tenant limit store using the given client and table
+func NewDynamoTenantLimitStore(client *dynamodb.Client, table string) *DynamoTenantLimitStore {
+	return &DynamoTenantLimitStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// SaveTenantLimits creates or replaces the limits of a tenant in the table
+func (s *DynamoTenantLimitStore) SaveTenantLimits(ctx context.Context, limits models.TenantLimits) (models.TenantLimits, bool) {
+	now := time.Now()
+	limits.UpdatedAt = &now
+
+	record, err := attributevalue.MarshalMap(dynamoTenantLimits{
+		PK:                  dynamoEntityTenantLimits,
+		SK:                  limits.Tenant,
+		Tenant:              limits.Tenant,
+		MaxScheduledItems:   limits.MaxScheduledItems,
+		MaxDailyGenerations: limits.MaxDailyGenerations,
+		MaxWebhooks:         limits.MaxWebhooks,
+		UpdatedAt:           now,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling tenant limits: %v", err)
+		return models.TenantLimits{}, false
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error saving tenant limits: %v", err)
+		return models.TenantLimits{}, false
+	}
+
+	return limits, true
+}
+
+// GetTenantLimits retrieves the limits of a tenant from the table
+func (s *DynamoTenantLimitStore) GetTenantLimits(ctx context.Context, tenant string) (models.TenantLimits, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityTenantLimits, tenant),
+	})
+	if err != nil {
+		logging.Errorf("Error getting tenant limits: %v", err)
+		return models.TenantLimits{}, false
+	}
+	if output.Item == nil {
+		return models.TenantLimits{}, false
+	}
+
+	var record dynamoTenantLimits
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling tenant limits: %v", err)
+		return models.TenantLimits{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllTenantLimits returns the limits of every tenant from the table, ordered by tenant
+func (s *DynamoTenantLimitStore) GetAllTenantLimits(ctx context.Context) []models.TenantLimits {
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityTenantLimits},
+		},
+	})
+
+	all := []models.TenantLimits{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying tenant limits: %v", err)
+			return []models.TenantLimits{}
+		}
+
+		var records []dynamoTenantLimits
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling tenant limits: %v", err)
+			return []models.TenantLimits{}
+		}
+		for _, record := range records {
+			all = append(all, record.toModel())
+		}
+	}
+
+	return all
+}
+
+// DeleteTenantLimits removes the limits of a tenant from the table
+func (s *DynamoTenantLimitStore) DeleteTenantLimits(ctx context.Context, tenant string) bool {
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.table),
+		Key:          dynamoKey(dynamoEntityTenantLimits, tenant),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		logging.Errorf("Error deleting tenant limits: %v", err)
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
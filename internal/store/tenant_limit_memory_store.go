@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryTenantLimitStore provides in-memory storage operations for the limits of tenants
+type MemoryTenantLimitStore struct {
+	sync.RWMutex
+	limits map[string]models.TenantLimits
+}
+
+// NewMemoryTenantLimitStore creates a new in-memory tenant limit store
+func NewMemoryTenantLimitStore() *MemoryTenantLimitStore {
+	return &MemoryTenantLimitStore{
+		limits: make(map[string]models.TenantLimits),
+	}
+}
+
+// SaveTenantLimits creates or replaces the limits of a tenant in the in-memory store
+func (s *MemoryTenantLimitStore) SaveTenantLimits(ctx context.Context, limits models.TenantLimits) (models.TenantLimits, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	limits.UpdatedAt = &now
+	s.limits[limits.Tenant] = limits
+	return limits, true
+}
+
+// GetTenantLimits retrieves the limits of a tenant from the in-memory store
+func (s *MemoryTenantLimitStore) GetTenantLimits(ctx context.Context, tenant string) (models.TenantLimits, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	limits, exists := s.limits[tenant]
+	return limits, exists
+}
+
+// GetAllTenantLimits returns the limits of every tenant from the in-memory store, ordered by tenant
+func (s *MemoryTenantLimitStore) GetAllTenantLimits(ctx context.Context) []models.TenantLimits {
+	s.RLock()
+	defer s.RUnlock()
+
+	all := make([]models.TenantLimits, 0, len(s.limits))
+	for _, limits := range s.limits {
+		all = append(all, limits)
+	}
+	slices.SortFunc(all, func(a, b models.TenantLimits) int { return strings.Compare(a.Tenant, b.Tenant) })
+	return all
+}
+
+// DeleteTenantLimits removes the limits of a tenant from the in-memory store
+func (s *MemoryTenantLimitStore) DeleteTenantLimits(ctx context.Context, tenant string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, exists := s.limits[tenant]; !exists {
+		return false
+	}
+	delete(s.limits, tenant)
+	return true
+}
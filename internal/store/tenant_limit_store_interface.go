@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// TenantLimitStore defines the interface for storage operations on the limits of tenants.
+// Unlike the other stores it isn't confined to the context's tenant, since the limits of
+// every tenant are managed by administrators.
+type TenantLimitStore interface {
+	// SaveTenantLimits creates or replaces the limits of a tenant
+	SaveTenantLimits(ctx context.Context, limits models.TenantLimits) (models.TenantLimits, bool)
+	GetTenantLimits(ctx context.Context, tenant string) (models.TenantLimits, bool)
+	// GetAllTenantLimits returns the limits of every tenant that has its own, ordered by tenant
+	GetAllTenantLimits(ctx context.Context) []models.TenantLimits
+	DeleteTenantLimits(ctx context.Context, tenant string) bool
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// AuditingTodoItemStore records the changes made through another todo item store in the
+// audit log, with the item before and after each change. Changes made without an actor,
+// such as todo items created by the scheduler, pass straight through.
+type AuditingTodoItemStore struct {
+	TodoItemStore
+	audit AuditLogStore
+}
+
+// NewAuditingTodoItemStore wraps the given store so its changes are recorded in auditStore
+func NewAuditingTodoItemStore(store TodoItemStore, auditStore AuditLogStore) *AuditingTodoItemStore {
+	return &AuditingTodoItemStore{
+		TodoItemStore: store,
+		audit:         auditStore,
+	}
+}
+
+// CreateTodoItem creates the item and audits its creation
+func (s *AuditingTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
+	createdItem := s.TodoItemStore.CreateTodoItem(ctx, item)
+	if createdItem.ID != 0 {
+		recordAudit(ctx, s.audit, models.AuditActionCreate, models.AuditEntityTodoItem, createdItem.ID, nil, createdItem)
+	}
+	return createdItem
+}
+
+// CreateTodoItems creates the items and audits the creation of each
+func (s *AuditingTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	createdItems, err := s.TodoItemStore.CreateTodoItems(ctx, items)
+	for _, createdItem := range createdItems {
+		recordAudit(ctx, s.audit, models.AuditActionCreate, models.AuditEntityTodoItem, createdItem.ID, nil, createdItem)
+	}
+	return createdItems, err
+}
+
+// UpdateTodoItem updates the item and audits the change
+func (s *AuditingTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
+	if ActorFromContext(ctx) == "" {
+		return s.TodoItemStore.UpdateTodoItem(ctx, id, updatedItem)
+	}
+
+	before, _ := s.TodoItemStore.GetTodoItem(ctx, id)
+	item, updated := s.TodoItemStore.UpdateTodoItem(ctx, id, updatedItem)
+	if updated {
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityTodoItem, id, before, item)
+	}
+	return item, updated
+}
+
+// DeleteTodoItem deletes the item and audits its deletion
+func (s *AuditingTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
+	if ActorFromContext(ctx) == "" {
+		return s.TodoItemStore.DeleteTodoItem(ctx, id)
+	}
+
+	before, _ := s.TodoItemStore.GetTodoItem(ctx, id)
+	deleted := s.TodoItemStore.DeleteTodoItem(ctx, id)
+	if deleted {
+		recordAudit(ctx, s.audit, models.AuditActionDelete, models.AuditEntityTodoItem, id, before, nil)
+	}
+	return deleted
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"iter"
+	"periodic-api/internal/models"
+	"slices"
+)
+
+// CachedTodoItemStore is a read-through cache in front of another todo item store.
+// Single items and the full listing are cached; writes made through this store invalidate
+// the affected entries once their transaction commits, and reads within a transaction
+// bypass the cache, while writes made by other processes (such as todos created by
+// the scheduler service) become visible once the TTL expires. The listing, which
+// dashboards poll for open todos, is cached for the shorter PollTTL when it is set.
+type CachedTodoItemStore struct {
+	store TodoItemStore
+	items *lruCache[int64, models.TodoItem]
+	all   *lruCache[string, []models.TodoItem]
+}
+
+// NewCachedTodoItemStore wraps the given store with a read-through cache
+func NewCachedTodoItemStore(store TodoItemStore, config CacheConfig) *CachedTodoItemStore {
+	return &CachedTodoItemStore{
+		store: store,
+		items: newLRUCache[int64, models.TodoItem](config),
+		all:   newLRUCache[string, []models.TodoItem](config.pollConfig()),
+	}
+}
+
+// CreateTodoItem creates the item in the underlying store and invalidates the listing
+func (s *CachedTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
+	createdItem := s.store.CreateTodoItem(ctx, item)
+	s.invalidateListing(ctx)
+	return createdItem
+}
+
+// CreateTodoItems creates the items in the underlying store and invalidates the listing
+func (s *CachedTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	createdItems, err := s.store.CreateTodoItems(ctx, items)
+	s.invalidateListing(ctx)
+	return createdItems, err
+}
+
+// GetTodoItem returns the cached item, loading it from the underlying store on a miss
+func (s *CachedTodoItemStore) GetTodoItem(ctx context.Context, id int64) (models.TodoItem, bool) {
+	if inTransaction(ctx) {
+		return s.store.GetTodoItem(ctx, id)
+	}
+	if item, ok := s.items.get(id); ok && ownTenant(ctx, item.TenantID) {
+		return item, true
+	}
+
+	// Load from the primary, so a replica lagging behind the write that evicted the entry
+	// isn't cached until it expires
+	item, exists := s.store.GetTodoItem(WithPrimary(ctx), id)
+	if exists {
+		s.items.set(id, item)
+	}
+	return item, exists
+}
+
+// GetAllTodoItems returns the cached listing, loading it from the underlying store on a
+// miss. Listings are cached per tenant, and listings scoped to an organization aren't cached.
+func (s *CachedTodoItemStore) GetAllTodoItems(ctx context.Context) []models.TodoItem {
+	if _, scoped := OrganizationFromContext(ctx); scoped || inTransaction(ctx) {
+		return s.store.GetAllTodoItems(ctx)
+	}
+	if items, ok := s.all.get(TenantFromContext(ctx)); ok {
+		return slices.Clone(items)
+	}
+
+	items := s.store.GetAllTodoItems(WithPrimary(ctx))
+	s.all.set(TenantFromContext(ctx), slices.Clone(items))
+	return items
+}
+
+// StreamTodoItems streams the cached listing, or on a miss the underlying store's listing
+// as it is read, caching it once the stream has been read to the end. Listings scoped to
+// an organization or read within a transaction aren't cached and stream from the
+// underlying store.
+func (s *CachedTodoItemStore) StreamTodoItems(ctx context.Context) iter.Seq2[models.TodoItem, error] {
+	if _, scoped := OrganizationFromContext(ctx); scoped || inTransaction(ctx) {
+		return s.store.StreamTodoItems(ctx)
+	}
+	return streamThrough(s.all, TenantFromContext(ctx), func() iter.Seq2[models.TodoItem, error] {
+		return s.store.StreamTodoItems(WithPrimary(ctx))
+	})
+}
+
+// GetTodoItemsPage is not cached; pages are read from the underlying store, which seeks
+// to the cursor itself
+func (s *CachedTodoItemStore) GetTodoItemsPage(ctx context.Context, limit int, cursor *TodoItemCursor) ([]models.TodoItem, *TodoItemCursor, error) {
+	return s.store.GetTodoItemsPage(ctx, limit, cursor)
+}
+
+// UpdateTodoItem updates the item in the underlying store and invalidates it
+func (s *CachedTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
+	item, updated := s.store.UpdateTodoItem(ctx, id, updatedItem)
+	s.invalidate(ctx, id)
+	return item, updated
+}
+
+// DeleteTodoItem deletes the item from the underlying store and invalidates it
+func (s *CachedTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
+	deleted := s.store.DeleteTodoItem(ctx, id)
+	s.invalidate(ctx, id)
+	return deleted
+}
+
+// Stats returns the combined hit and miss counts of the item and listing caches
+func (s *CachedTodoItemStore) Stats() CacheStats {
+	return addCacheStats(s.items.stats(), s.all.stats())
+}
+
+// ListingStats returns the hit and miss counts of the listing cache
+func (s *CachedTodoItemStore) ListingStats() CacheStats {
+	return s.all.stats()
+}
+
+// invalidate drops a cached item along with the tenant's listing that contains it once the
+// transaction carried by ctx commits, so a concurrent read can't cache the item as it was
+// before the write
+func (s *CachedTodoItemStore) invalidate(ctx context.Context, id int64) {
+	AfterCommit(ctx, func() {
+		s.items.delete(id)
+		s.all.delete(TenantFromContext(ctx))
+	})
+}
+
+// invalidateListing drops the tenant's cached listing once the transaction carried by ctx
+// commits
+func (s *CachedTodoItemStore) invalidateListing(ctx context.Context) {
+	AfterCommit(ctx, func() { s.all.delete(TenantFromContext(ctx)) })
+}
@@ -1,93 +1,158 @@
 package store
 
 import (
+	"cmp"
+	"context"
+	"periodic-api/internal/logging"
 	"periodic-api/internal/models"
 	"database/sql"
-	"log"
-	"sync"
+	"fmt"
+	"iter"
+	"slices"
 )
 
 // PostgresTodoItemStore provides PostgreSQL storage operations for todo items
+// Its lookups and listings read from the read replica when one is set, see SetReadReplica.
 type PostgresTodoItemStore struct {
-	sync.RWMutex
 	db *sql.DB
+	// statements holds the prepared statements of the scheduler's hot paths
+	statements *statementCache
 }
 
 // NewPostgresTodoItemStore creates a new PostgreSQL store with the given database connection
 func NewPostgresTodoItemStore(db *sql.DB) *PostgresTodoItemStore {
 	return &PostgresTodoItemStore{
-		db: db,
+		db:         db,
+		statements: newStatementCache(db),
 	}
 }
 
 // CreateTodoItem adds a new todo item to the database
-func (s *PostgresTodoItemStore) CreateTodoItem(item models.TodoItem) models.TodoItem {
-	s.Lock()
-	defer s.Unlock()
-
+func (s *PostgresTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
 	query := `
 		INSERT INTO todo_items 
-		(text, checked) 
-		VALUES ($1, $2) 
-		RETURNING id
+		(text, checked, organization_id, tenant_id) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING id, created_at, updated_at
 	`
 
-	err := s.db.QueryRow(
+	item.TenantID = TenantFromContext(ctx)
+	err := s.statements.querier(ctx).QueryRowContext(
+		ctx,
 		query,
 		item.Text,
 		item.Checked,
-	).Scan(&item.ID)
+		item.OrganizationID,
+		item.TenantID,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
 
 	if err != nil {
-		log.Printf("Error creating todo item: %v", err)
+		logging.Errorf("Error creating todo item: %v", err)
 		return models.TodoItem{} // Return empty item on error
 	}
 
 	return item
 }
 
-// GetTodoItem retrieves a todo item by ID from the database
-func (s *PostgresTodoItemStore) GetTodoItem(id int64) (models.TodoItem, bool) {
-	s.RLock()
-	defer s.RUnlock()
+// CreateTodoItems adds the todo items to the database with multi-row inserts and returns
+// them with their IDs, in the same order. Outside a transaction, each insert of up to
+// maxBatchRows items commits on its own.
+func (s *PostgresTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	tenantID := TenantFromContext(ctx)
+	createdItems := make([]models.TodoItem, 0, len(items))
+	for batch := range slices.Chunk(items, maxBatchRows) {
+		query := `
+			INSERT INTO todo_items
+			(text, checked, organization_id, tenant_id)
+			VALUES ` + valuesList(len(batch), 4) + `
+			RETURNING id, created_at, updated_at
+		`
+
+		args := make([]any, 0, len(batch)*4)
+		for _, item := range batch {
+			args = append(args, item.Text, item.Checked, item.OrganizationID, tenantID)
+		}
 
+		rows, err := querier(ctx, s.db).QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create todo items: %w", err)
+		}
+		created := make([]models.TodoItem, 0, len(batch))
+		for rows.Next() {
+			var item models.TodoItem
+			if err := rows.Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to create todo items: %w", err)
+			}
+			created = append(created, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to create todo items: %w", err)
+		}
+		if len(created) != len(batch) {
+			return nil, fmt.Errorf("created %d of %d todo items", len(created), len(batch))
+		}
+
+		// IDs are drawn from the sequence in the order the rows are inserted
+		slices.SortFunc(created, func(a, b models.TodoItem) int { return cmp.Compare(a.ID, b.ID) })
+		for i, item := range batch {
+			item.ID = created[i].ID
+			item.CreatedAt = created[i].CreatedAt
+			item.UpdatedAt = created[i].UpdatedAt
+			item.TenantID = tenantID
+			createdItems = append(createdItems, item)
+		}
+	}
+	return createdItems, nil
+}
+
+// GetTodoItem retrieves a todo item by ID from the database
+func (s *PostgresTodoItemStore) GetTodoItem(ctx context.Context, id int64) (models.TodoItem, bool) {
 	var item models.TodoItem
 	query := `
-		SELECT id, text, checked 
+		SELECT id, text, checked, organization_id, tenant_id, created_at, updated_at 
 		FROM todo_items 
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
-	err := s.db.QueryRow(query, id).Scan(
+	err := readQuerier(ctx, s.db).QueryRowContext(ctx, query, id, TenantFromContext(ctx)).Scan(
 		&item.ID,
 		&item.Text,
 		&item.Checked,
+		&item.OrganizationID,
+		&item.TenantID,
+		&item.CreatedAt,
+		&item.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.TodoItem{}, false
 		}
-		log.Printf("Error getting todo item: %v", err)
+		logging.Errorf("Error getting todo item: %v", err)
 		return models.TodoItem{}, false
 	}
 
 	return item, true
 }
 
-// GetAllTodoItems returns all todo items from the database
-func (s *PostgresTodoItemStore) GetAllTodoItems() []models.TodoItem {
-	s.RLock()
-	defer s.RUnlock()
-
+// GetAllTodoItems returns all todo items from the database, or those of the context's organization
+func (s *PostgresTodoItemStore) GetAllTodoItems(ctx context.Context) []models.TodoItem {
 	query := `
-		SELECT id, text, checked 
+		SELECT id, text, checked, organization_id, tenant_id, created_at, updated_at 
 		FROM todo_items
+		WHERE tenant_id = $1
 	`
+	args := []any{TenantFromContext(ctx)}
+	if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+		query += `AND organization_id = $2`
+		args = append(args, organizationID)
+	}
 
-	rows, err := s.db.Query(query)
+	rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		log.Printf("Error querying todo items: %v", err)
+		logging.Errorf("Error querying todo items: %v", err)
 		return []models.TodoItem{}
 	}
 	defer rows.Close()
@@ -100,10 +165,14 @@ func (s *PostgresTodoItemStore) GetAllTodoItems() []models.TodoItem {
 			&item.ID,
 			&item.Text,
 			&item.Checked,
+			&item.OrganizationID,
+			&item.TenantID,
+			&item.CreatedAt,
+			&item.UpdatedAt,
 		)
 
 		if err != nil {
-			log.Printf("Error scanning row: %v", err)
+			logging.Errorf("Error scanning row: %v", err)
 			continue
 		}
 
@@ -111,42 +180,154 @@ func (s *PostgresTodoItemStore) GetAllTodoItems() []models.TodoItem {
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
+		logging.Errorf("Error iterating rows: %v", err)
 	}
 
 	return items
 }
 
-// UpdateTodoItem updates an existing todo item in the database
-func (s *PostgresTodoItemStore) UpdateTodoItem(id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
-	s.Lock()
-	defer s.Unlock()
+// StreamTodoItems streams the todo items of the database, or those of the context's
+// organization, a row at a time as they are read, so the listing is never held in memory
+// whole. The stream ends after the first error.
+func (s *PostgresTodoItemStore) StreamTodoItems(ctx context.Context) iter.Seq2[models.TodoItem, error] {
+	return func(yield func(models.TodoItem, error) bool) {
+		query := `
+			SELECT id, text, checked, organization_id, tenant_id, created_at, updated_at 
+			FROM todo_items
+			WHERE tenant_id = $1
+		`
+		args := []any{TenantFromContext(ctx)}
+		if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+			query += `AND organization_id = $2`
+			args = append(args, organizationID)
+		}
+
+		rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(models.TodoItem{}, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var item models.TodoItem
+
+			err := rows.Scan(
+				&item.ID,
+				&item.Text,
+				&item.Checked,
+				&item.OrganizationID,
+				&item.TenantID,
+				&item.CreatedAt,
+				&item.UpdatedAt,
+			)
+
+			if err != nil {
+				yield(models.TodoItem{}, err)
+				return
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(models.TodoItem{}, err)
+		}
+	}
+}
+
+// GetTodoItemsPage returns a page of up to limit todo items in ID order, starting after
+// cursor, or from the start when it is nil. The idx_todo_items_tenant_keyset index lets
+// each page seek to its cursor however deep into the listing it is. The returned cursor
+// is nil when there are no further pages.
+func (s *PostgresTodoItemStore) GetTodoItemsPage(ctx context.Context, limit int, cursor *TodoItemCursor) ([]models.TodoItem, *TodoItemCursor, error) {
+	if limit < 1 {
+		return []models.TodoItem{}, nil, ErrInvalidLimit
+	}
+
+	query := `
+		SELECT id, text, checked, organization_id, tenant_id, created_at, updated_at 
+		FROM todo_items
+		WHERE tenant_id = $1
+	`
+	args := []any{TenantFromContext(ctx)}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(`AND id > $%d `, len(args))
+	}
+	if organizationID, scoped := OrganizationFromContext(ctx); scoped {
+		args = append(args, organizationID)
+		query += fmt.Sprintf(`AND organization_id = $%d `, len(args))
+	}
+	// Fetch one extra row to find out whether another page follows
+	args = append(args, limit+1)
+	query += fmt.Sprintf(`ORDER BY id LIMIT $%d`, len(args))
+
+	rows, err := readQuerier(ctx, s.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return []models.TodoItem{}, nil, err
+	}
+	defer rows.Close()
+
+	var items []models.TodoItem
+	for rows.Next() {
+		var item models.TodoItem
 
+		err := rows.Scan(
+			&item.ID,
+			&item.Text,
+			&item.Checked,
+			&item.OrganizationID,
+			&item.TenantID,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		)
+
+		if err != nil {
+			return []models.TodoItem{}, nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return []models.TodoItem{}, nil, err
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+
+	items = items[:limit]
+	return items, &TodoItemCursor{ID: items[len(items)-1].ID}, nil
+}
+
+// UpdateTodoItem updates an existing todo item in the database
+func (s *PostgresTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
 	query := `
 		UPDATE todo_items 
-		SET text = $1, checked = $2 
-		WHERE id = $3
+		SET text = $1, checked = $2, organization_id = $3, updated_at = NOW() 
+		WHERE id = $4 AND tenant_id = $5
+		RETURNING tenant_id, created_at, updated_at
 	`
 
-	result, err := s.db.Exec(
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
 		query,
 		updatedItem.Text,
 		updatedItem.Checked,
+		updatedItem.OrganizationID,
 		id,
-	)
+		TenantFromContext(ctx),
+	).Scan(&updatedItem.TenantID, &updatedItem.CreatedAt, &updatedItem.UpdatedAt)
 
 	if err != nil {
-		log.Printf("Error updating todo item: %v", err)
-		return models.TodoItem{}, false
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		return models.TodoItem{}, false
-	}
-
-	if rowsAffected == 0 {
+		if err == sql.ErrNoRows {
+			return models.TodoItem{}, false
+		}
+		logging.Errorf("Error updating todo item: %v", err)
 		return models.TodoItem{}, false
 	}
 
@@ -155,53 +336,19 @@ func (s *PostgresTodoItemStore) UpdateTodoItem(id int64, updatedItem models.Todo
 }
 
 // DeleteTodoItem removes a todo item from the database
-func (s *PostgresTodoItemStore) DeleteTodoItem(id int64) bool {
-	s.Lock()
-	defer s.Unlock()
-
-	query := `DELETE FROM todo_items WHERE id = $1`
-	result, err := s.db.Exec(query, id)
+func (s *PostgresTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
+	query := `DELETE FROM todo_items WHERE id = $1 AND tenant_id = $2`
+	result, err := querier(ctx, s.db).ExecContext(ctx, query, id, TenantFromContext(ctx))
 	if err != nil {
-		log.Printf("Error deleting todo item: %v", err)
+		logging.Errorf("Error deleting todo item: %v", err)
 		return false
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
+		logging.Errorf("Error getting rows affected: %v", err)
 		return false
 	}
 
 	return rowsAffected > 0
 }
-
-// AddSampleData adds sample data to the database if it's empty
-func (s *PostgresTodoItemStore) AddSampleData() {
-	count := 0
-	err := s.db.QueryRow("SELECT COUNT(*) FROM todo_items").Scan(&count)
-	if err != nil {
-		log.Printf("Error checking for existing data: %v", err)
-		return
-	}
-
-	// Add sample data if the table is empty
-	if count == 0 {
-		log.Println("Adding sample todo items...")
-
-		// Add some sample data
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Buy groceries",
-			Checked: false,
-		})
-
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Clean the house",
-			Checked: true,
-		})
-
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Finish project",
-			Checked: false,
-		})
-	}
-}
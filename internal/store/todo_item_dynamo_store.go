@@ -0,0 +1,331 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoTodoItem is the DynamoDB representation of a todo item
+type dynamoTodoItem struct {
+	PK             string    `dynamodbav:"pk"`
+	SK             string    `dynamodbav:"sk"`
+	ID             int64     `dynamodbav:"id"`
+	Text           string    `dynamodbav:"text"`
+	Checked        bool      `dynamodbav:"checked"`
+	OrganizationID *int64    `dynamodbav:"organization_id,omitempty"`
+	TenantID       string    `dynamodbav:"tenant_id,omitempty"`
+	CreatedAt      time.Time `dynamodbav:"created_at"`
+	UpdatedAt      time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to a todo item
+func (r dynamoTodoItem) toModel() models.TodoItem {
+	item := models.TodoItem{
+		ID:             r.ID,
+		Text:           r.Text,
+		Checked:        r.Checked,
+		OrganizationID: r.OrganizationID,
+		TenantID:       r.TenantID,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+	if item.TenantID == "" {
+		item.TenantID = DefaultTenant
+	}
+	return item
+}
+
+// DynamoTodoItemStore provides DynamoDB storage operations for todo items
+type DynamoTodoItemStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoTodoItemStore creates a new DynamoDB todo item store using the given client and table
+func NewDynamoTodoItemStore(client *dynamodb.Client, table string) *DynamoTodoItemStore {
+	return &DynamoTodoItemStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateTodoItem adds a new todo item to the table
+func (s *DynamoTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityTodoItem)
+	if err != nil {
+		logging.Errorf("Error allocating todo item ID: %v", err)
+		return models.TodoItem{}
+	}
+	item.ID = id
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+	item.TenantID = TenantFromContext(ctx)
+
+	record, err := attributevalue.MarshalMap(dynamoTodoItem{
+		PK:             dynamoEntityTodoItem,
+		SK:             dynamoSortKeyForID(item.ID),
+		ID:             item.ID,
+		Text:           item.Text,
+		Checked:        item.Checked,
+		OrganizationID: item.OrganizationID,
+		TenantID:       item.TenantID,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling todo item: %v", err)
+		return models.TodoItem{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		logging.Errorf("Error creating todo item: %v", err)
+		return models.TodoItem{} // Return empty item on error
+	}
+
+	return item
+}
+
+// CreateTodoItems adds the todo items to the table one by one, since each needs an ID
+// allocated from the counter, and returns them in the same order. Items written before a
+// failure stay in the table.
+func (s *DynamoTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	createdItems := make([]models.TodoItem, 0, len(items))
+	for _, item := range items {
+		createdItem := s.CreateTodoItem(ctx, item)
+		if createdItem.ID == 0 {
+			return nil, fmt.Errorf("failed to create todo item %d of %d", len(createdItems)+1, len(items))
+		}
+		createdItems = append(createdItems, createdItem)
+	}
+	return createdItems, nil
+}
+
+// GetTodoItem retrieves a todo item by ID from the table
+func (s *DynamoTodoItemStore) GetTodoItem(ctx context.Context, id int64) (models.TodoItem, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityTodoItem, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting todo item: %v", err)
+		return models.TodoItem{}, false
+	}
+	if output.Item == nil {
+		return models.TodoItem{}, false
+	}
+
+	var record dynamoTodoItem
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling todo item: %v", err)
+		return models.TodoItem{}, false
+	}
+	if !ownTenant(ctx, record.TenantID) {
+		return models.TodoItem{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllTodoItems returns all todo items from the table, or those of the context's organization
+func (s *DynamoTodoItemStore) GetAllTodoItems(ctx context.Context) []models.TodoItem {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityTodoItem},
+		},
+	}
+	scopeDynamoQuery(ctx, input)
+	tenantDynamoQuery(ctx, input)
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	var items []models.TodoItem
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying todo items: %v", err)
+			return []models.TodoItem{}
+		}
+
+		var records []dynamoTodoItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling todo items: %v", err)
+			return []models.TodoItem{}
+		}
+		for _, record := range records {
+			items = append(items, record.toModel())
+		}
+	}
+
+	return items
+}
+
+// StreamTodoItems streams the todo items of the table, or those of the context's
+// organization, a query page at a time, so the listing is never held in memory whole.
+// The stream ends after the first error.
+func (s *DynamoTodoItemStore) StreamTodoItems(ctx context.Context) iter.Seq2[models.TodoItem, error] {
+	return func(yield func(models.TodoItem, error) bool) {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.table),
+			KeyConditionExpression: aws.String("pk = :pk"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":pk": &types.AttributeValueMemberS{Value: dynamoEntityTodoItem},
+			},
+		}
+		scopeDynamoQuery(ctx, input)
+		tenantDynamoQuery(ctx, input)
+		paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				yield(models.TodoItem{}, err)
+				return
+			}
+
+			var records []dynamoTodoItem
+			if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+				yield(models.TodoItem{}, err)
+				return
+			}
+			for _, record := range records {
+				if !yield(record.toModel(), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GetTodoItemsPage returns a page of up to limit todo items in ID order, starting after
+// cursor, or from the start when it is nil. The sort key orders the items by ID, so the
+// query starts right after the cursor. The returned cursor is nil when there are no
+// further pages.
+func (s *DynamoTodoItemStore) GetTodoItemsPage(ctx context.Context, limit int, cursor *TodoItemCursor) ([]models.TodoItem, *TodoItemCursor, error) {
+	if limit < 1 {
+		return []models.TodoItem{}, nil, ErrInvalidLimit
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityTodoItem},
+		},
+	}
+	if cursor != nil {
+		input.KeyConditionExpression = aws.String("pk = :pk AND sk > :cursor")
+		input.ExpressionAttributeValues[":cursor"] = &types.AttributeValueMemberS{Value: dynamoSortKeyForID(cursor.ID)}
+	}
+	scopeDynamoQuery(ctx, input)
+	tenantDynamoQuery(ctx, input)
+
+	// Filters apply after each page is read, so keep paging until there are enough items,
+	// plus one to find out whether another page follows
+	var items []models.TodoItem
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() && len(items) <= limit {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return []models.TodoItem{}, nil, err
+		}
+
+		var records []dynamoTodoItem
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			return []models.TodoItem{}, nil, err
+		}
+		for _, record := range records {
+			items = append(items, record.toModel())
+		}
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+	items = items[:limit]
+	return items, &TodoItemCursor{ID: items[len(items)-1].ID}, nil
+}
+
+// UpdateTodoItem updates an existing todo item in the table
+func (s *DynamoTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
+	fields := map[string]any{
+		":text":       updatedItem.Text,
+		":checked":    updatedItem.Checked,
+		":updated_at": time.Now(),
+	}
+	update := "SET #text = :text, checked = :checked, updated_at = :updated_at"
+	if updatedItem.OrganizationID != nil {
+		fields[":organization_id"] = *updatedItem.OrganizationID
+		update += ", organization_id = :organization_id"
+	} else {
+		update += " REMOVE organization_id"
+	}
+	values, err := attributevalue.MarshalMap(fields)
+	if err != nil {
+		logging.Errorf("Error marshalling todo item: %v", err)
+		return models.TodoItem{}, false
+	}
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	condition, values := andDynamoCondition(aws.String("attribute_exists(pk)"), values, tenant, tenantValues)
+
+	// Update in place so the creation time is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 dynamoKey(dynamoEntityTodoItem, dynamoSortKeyForID(id)),
+		UpdateExpression:    aws.String(update),
+		ConditionExpression: condition,
+		ExpressionAttributeNames: map[string]string{
+			"#text": "text",
+		},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating todo item: %v", err)
+		}
+		return models.TodoItem{}, false
+	}
+
+	var record dynamoTodoItem
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling todo item: %v", err)
+		return models.TodoItem{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// DeleteTodoItem removes a todo item from the table
+func (s *DynamoTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
+	condition, values := dynamoTenantCondition(ctx)
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityTodoItem, dynamoSortKeyForID(id)),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error deleting todo item: %v", err)
+		}
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
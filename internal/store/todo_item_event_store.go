@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+)
+
+// PublishingTodoItemStore publishes an event for every change made through another todo
+// item store. Events for changes made inside a transaction are published once it commits.
+type PublishingTodoItemStore struct {
+	TodoItemStore
+	bus *events.Bus
+}
+
+// NewPublishingTodoItemStore wraps the given store so its changes are published on bus
+func NewPublishingTodoItemStore(store TodoItemStore, bus *events.Bus) *PublishingTodoItemStore {
+	return &PublishingTodoItemStore{
+		TodoItemStore: store,
+		bus:           bus,
+	}
+}
+
+// CreateTodoItem creates the item and publishes a todo.created event
+func (s *PublishingTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
+	createdItem := s.TodoItemStore.CreateTodoItem(ctx, item)
+	if createdItem.ID != 0 {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.TodoCreated, createdItem) })
+	}
+	return createdItem
+}
+
+// CreateTodoItems creates the items and publishes a todo.created event for each
+func (s *PublishingTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	createdItems, err := s.TodoItemStore.CreateTodoItems(ctx, items)
+	for _, createdItem := range createdItems {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.TodoCreated, createdItem) })
+	}
+	return createdItems, err
+}
+
+// UpdateTodoItem updates the item and publishes a todo.updated event
+func (s *PublishingTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
+	item, updated := s.TodoItemStore.UpdateTodoItem(ctx, id, updatedItem)
+	if updated {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.TodoUpdated, item) })
+	}
+	return item, updated
+}
+
+// DeleteTodoItem deletes the item and publishes a todo.deleted event
+func (s *PublishingTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
+	deleted := s.TodoItemStore.DeleteTodoItem(ctx, id)
+	if deleted {
+		AfterCommit(ctx, func() { s.bus.PublishTenant(TenantFromContext(ctx), events.TodoDeleted, deletedEventData{ID: id}) })
+	}
+	return deleted
+}
+
+// deletedEventData identifies the deleted entity in deletion events
+type deletedEventData struct {
+	ID int64 `json:"id"`
+
+	// ownerID is the owner of a deleted scheduled item, kept so subscribers can still
+	// tell who may see its deletion
+	ownerID *int64
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"context"
+	"iter"
 	"periodic-api/internal/models"
 	"sync"
+	"time"
 )
 
 // MemoryTodoItemStore provides in-memory storage operations for todo items
@@ -21,84 +24,109 @@ func NewMemoryTodoItemStore() *MemoryTodoItemStore {
 }
 
 // CreateTodoItem adds a new todo item to the in-memory store
-func (s *MemoryTodoItemStore) CreateTodoItem(item models.TodoItem) models.TodoItem {
+func (s *MemoryTodoItemStore) CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem {
 	s.Lock()
 	defer s.Unlock()
 
 	// Assign a new ID
 	item.ID = s.nextID
 	s.nextID++
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+	item.TenantID = TenantFromContext(ctx)
 
 	// Store the item
 	s.items[item.ID] = item
 	return item
 }
 
+// CreateTodoItems adds the todo items to the in-memory store and returns them with their
+// IDs, in the same order
+func (s *MemoryTodoItemStore) CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	createdItems := make([]models.TodoItem, 0, len(items))
+	for _, item := range items {
+		item.ID = s.nextID
+		s.nextID++
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		item.TenantID = TenantFromContext(ctx)
+
+		s.items[item.ID] = item
+		createdItems = append(createdItems, item)
+	}
+	return createdItems, nil
+}
+
 // GetTodoItem retrieves a todo item by ID from the in-memory store
-func (s *MemoryTodoItemStore) GetTodoItem(id int64) (models.TodoItem, bool) {
+func (s *MemoryTodoItemStore) GetTodoItem(ctx context.Context, id int64) (models.TodoItem, bool) {
 	s.RLock()
 	defer s.RUnlock()
 
 	item, exists := s.items[id]
-	return item, exists
+	if !exists || !ownTenant(ctx, item.TenantID) {
+		return models.TodoItem{}, false
+	}
+	return item, true
 }
 
-// GetAllTodoItems returns all todo items from the in-memory store
-func (s *MemoryTodoItemStore) GetAllTodoItems() []models.TodoItem {
+// GetAllTodoItems returns all todo items from the in-memory store, or those of the
+// context's organization
+func (s *MemoryTodoItemStore) GetAllTodoItems(ctx context.Context) []models.TodoItem {
 	s.RLock()
 	defer s.RUnlock()
 
 	items := make([]models.TodoItem, 0, len(s.items))
 	for _, item := range s.items {
-		items = append(items, item)
+		if ownTenant(ctx, item.TenantID) && inScope(ctx, item.OrganizationID) {
+			items = append(items, item)
+		}
 	}
 	return items
 }
 
+// StreamTodoItems streams a snapshot of the in-memory todo items
+func (s *MemoryTodoItemStore) StreamTodoItems(ctx context.Context) iter.Seq2[models.TodoItem, error] {
+	return sliceStream(s.GetAllTodoItems(ctx))
+}
+
+// GetTodoItemsPage returns a page of up to limit todo items in ID order, starting after
+// cursor, or from the start when it is nil. The returned cursor is nil when there are no
+// further pages.
+func (s *MemoryTodoItemStore) GetTodoItemsPage(ctx context.Context, limit int, cursor *TodoItemCursor) ([]models.TodoItem, *TodoItemCursor, error) {
+	return keysetPage(s.GetAllTodoItems(ctx), limit, cursor, todoItemCursor, compareTodoItemCursors)
+}
+
 // UpdateTodoItem updates an existing todo item in the in-memory store
-func (s *MemoryTodoItemStore) UpdateTodoItem(id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
+func (s *MemoryTodoItemStore) UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool) {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, exists := s.items[id]; !exists {
+	existing, exists := s.items[id]
+	if !exists || !ownTenant(ctx, existing.TenantID) {
 		return models.TodoItem{}, false
 	}
 
 	updatedItem.ID = id
+	updatedItem.TenantID = existing.TenantID
+	updatedItem.CreatedAt = existing.CreatedAt
+	updatedItem.UpdatedAt = time.Now()
 	s.items[id] = updatedItem
 	return updatedItem, true
 }
 
 // DeleteTodoItem removes a todo item from the in-memory store
-func (s *MemoryTodoItemStore) DeleteTodoItem(id int64) bool {
+func (s *MemoryTodoItemStore) DeleteTodoItem(ctx context.Context, id int64) bool {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, exists := s.items[id]; !exists {
+	if item, exists := s.items[id]; !exists || !ownTenant(ctx, item.TenantID) {
 		return false
 	}
 
 	delete(s.items, id)
 	return true
 }
-
-// AddSampleData adds sample data to the in-memory store
-func (s *MemoryTodoItemStore) AddSampleData() {
-	// Only add sample data if the store is empty
-	if len(s.GetAllTodoItems()) == 0 {
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Buy groceries",
-			Checked: false,
-		})
-
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Clean the house",
-			Checked: true,
-		})
-
-		s.CreateTodoItem(models.TodoItem{
-			Text:    "Finish project",
-			Checked: false,
-		})
-	}
-}
@@ -1,15 +1,25 @@
 package store
 
 import (
+	"context"
+	"iter"
 	"periodic-api/internal/models"
 )
 
+// TodoItemCursor marks the last todo item of a page; the next page starts with the items
+// that come after it in ID order
+type TodoItemCursor struct {
+	ID int64
+}
+
 // TodoItemStore defines the interface for todo item storage operations
 type TodoItemStore interface {
-	CreateTodoItem(item models.TodoItem) models.TodoItem
-	GetTodoItem(id int64) (models.TodoItem, bool)
-	GetAllTodoItems() []models.TodoItem
-	UpdateTodoItem(id int64, updatedItem models.TodoItem) (models.TodoItem, bool)
-	DeleteTodoItem(id int64) bool
-	AddSampleData()
+	CreateTodoItem(ctx context.Context, item models.TodoItem) models.TodoItem
+	CreateTodoItems(ctx context.Context, items []models.TodoItem) ([]models.TodoItem, error)
+	GetTodoItem(ctx context.Context, id int64) (models.TodoItem, bool)
+	GetAllTodoItems(ctx context.Context) []models.TodoItem
+	StreamTodoItems(ctx context.Context) iter.Seq2[models.TodoItem, error]
+	GetTodoItemsPage(ctx context.Context, limit int, cursor *TodoItemCursor) ([]models.TodoItem, *TodoItemCursor, error)
+	UpdateTodoItem(ctx context.Context, id int64, updatedItem models.TodoItem) (models.TodoItem, bool)
+	DeleteTodoItem(ctx context.Context, id int64) bool
 }
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Transactor runs a group of store operations so that they either all take effect or none do
+type Transactor interface {
+	// WithinTransaction calls fn with a context carrying the transaction. Store operations
+	// given that context join the transaction, which is committed when fn returns nil and
+	// rolled back otherwise.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NoopTransactor runs operations without a transaction, for stores that don't support them
+type NoopTransactor struct{}
+
+// WithinTransaction calls fn directly; writes made before an error are not undone
+func (NoopTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// PostgresTransactor runs operations of the PostgreSQL stores in a single database transaction
+type PostgresTransactor struct {
+	db *sql.DB
+}
+
+// NewPostgresTransactor creates a new transactor for stores sharing the given database connection
+func NewPostgresTransactor(db *sql.DB) *PostgresTransactor {
+	return &PostgresTransactor{
+		db: db,
+	}
+}
+
+// txContextKey is the context key under which the current transaction is stored
+type txContextKey struct{}
+
+// WithinTransaction runs fn in a new transaction, or in the current one if ctx already carries one
+func (t *PostgresTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	hooks := &afterCommitHooks{}
+	ctx = context.WithValue(context.WithValue(ctx, txContextKey{}, tx), afterCommitContextKey{}, hooks)
+	if err := fn(ctx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+// afterCommitContextKey is the context key under which the current transaction's hooks are stored
+type afterCommitContextKey struct{}
+
+// afterCommitHooks collects the functions to run once a transaction commits
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// AfterCommit runs fn once the transaction carried by ctx commits, or right away when ctx
+// carries none. Use it for side effects, such as publishing events, that must not happen
+// if the transaction is rolled back.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitContextKey{}).(*afterCommitHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// inTransaction reports whether ctx carries a transaction whose writes aren't committed yet
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(afterCommitContextKey{}).(*afterCommitHooks)
+	return ok
+}
+
+// dbQuerier is the subset of *sql.DB and *sql.Tx used by the PostgreSQL stores
+type dbQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// querier returns the transaction carried by ctx, or db when there is none, timing the
+// statements run on it
+func querier(ctx context.Context, db *sql.DB) dbQuerier {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return timed(tx)
+	}
+	return timed(db)
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+	"time"
+)
+
+// AuditingUserStore records the changes made through another user store in the audit log,
+// with the user before and after each change. Password hashes are left out of the log.
+type AuditingUserStore struct {
+	UserStore
+	audit AuditLogStore
+}
+
+// NewAuditingUserStore wraps the given store so its changes are recorded in auditStore
+func NewAuditingUserStore(store UserStore, auditStore AuditLogStore) *AuditingUserStore {
+	return &AuditingUserStore{
+		UserStore: store,
+		audit:     auditStore,
+	}
+}
+
+// CreateUser creates the user and audits its creation
+func (s *AuditingUserStore) CreateUser(ctx context.Context, user models.User) models.User {
+	createdUser := s.UserStore.CreateUser(ctx, user)
+	if createdUser.ID != 0 {
+		recordAudit(ctx, s.audit, models.AuditActionCreate, models.AuditEntityUser, createdUser.ID, nil, auditedUser(createdUser))
+	}
+	return createdUser
+}
+
+// UpdateUser updates the user and audits the change
+func (s *AuditingUserStore) UpdateUser(ctx context.Context, id int64, updatedUser models.User) (models.User, bool) {
+	if ActorFromContext(ctx) == "" {
+		return s.UserStore.UpdateUser(ctx, id, updatedUser)
+	}
+
+	before, _ := s.UserStore.GetUser(ctx, id)
+	user, updated := s.UserStore.UpdateUser(ctx, id, updatedUser)
+	if updated {
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityUser, id, auditedUser(before), auditedUser(user))
+	}
+	return user, updated
+}
+
+// SetUserDeactivated deactivates or reactivates the user and audits the change
+func (s *AuditingUserStore) SetUserDeactivated(ctx context.Context, id int64, deactivatedAt *time.Time) (models.User, bool) {
+	if ActorFromContext(ctx) == "" {
+		return s.UserStore.SetUserDeactivated(ctx, id, deactivatedAt)
+	}
+
+	before, _ := s.UserStore.GetUser(ctx, id)
+	user, updated := s.UserStore.SetUserDeactivated(ctx, id, deactivatedAt)
+	if updated {
+		recordAudit(ctx, s.audit, models.AuditActionUpdate, models.AuditEntityUser, id, auditedUser(before), auditedUser(user))
+	}
+	return user, updated
+}
+
+// DeleteUser deletes the user and audits its deletion
+func (s *AuditingUserStore) DeleteUser(ctx context.Context, id int64) bool {
+	if ActorFromContext(ctx) == "" {
+		return s.UserStore.DeleteUser(ctx, id)
+	}
+
+	before, _ := s.UserStore.GetUser(ctx, id)
+	deleted := s.UserStore.DeleteUser(ctx, id)
+	if deleted {
+		recordAudit(ctx, s.audit, models.AuditActionDelete, models.AuditEntityUser, id, auditedUser(before), nil)
+	}
+	return deleted
+}
+
+// auditedUser returns the user as recorded in the audit log, without the password hash
+func auditedUser(user models.User) models.User {
+	user.PasswordHash = nil
+	return user
+}
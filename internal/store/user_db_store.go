@@ -1,15 +1,15 @@
 package store
 
 import (
+	"context"
+	"periodic-api/internal/logging"
 	"periodic-api/internal/models"
 	"database/sql"
-	"log"
-	"sync"
+	"time"
 )
 
 // PostgresUserStore provides PostgreSQL storage operations for users
 type PostgresUserStore struct {
-	sync.RWMutex
 	db *sql.DB
 }
 
@@ -21,25 +21,27 @@ func NewPostgresUserStore(db *sql.DB) *PostgresUserStore {
 }
 
 // CreateUser adds a new user to the database
-func (s *PostgresUserStore) CreateUser(user models.User) models.User {
-	s.Lock()
-	defer s.Unlock()
-
+func (s *PostgresUserStore) CreateUser(ctx context.Context, user models.User) models.User {
 	query := `
 		INSERT INTO users 
-		(username, password_hash) 
-		VALUES ($1, $2) 
-		RETURNING id
+		(username, password_hash, email, tenant_id) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING id, created_at, updated_at
 	`
 
-	err := s.db.QueryRow(
+	user.TenantID = TenantFromContext(ctx)
+	user.DeactivatedAt = nil
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
 		query,
 		user.Username,
 		user.PasswordHash,
-	).Scan(&user.ID)
+		user.Email,
+		user.TenantID,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
-		log.Printf("Error creating user: %v", err)
+		logging.Errorf("Error creating user: %v", err)
 		return models.User{} // Return empty user on error
 	}
 
@@ -47,28 +49,30 @@ func (s *PostgresUserStore) CreateUser(user models.User) models.User {
 }
 
 // GetUser retrieves a user by ID from the database
-func (s *PostgresUserStore) GetUser(id int64) (models.User, bool) {
-	s.RLock()
-	defer s.RUnlock()
-
+func (s *PostgresUserStore) GetUser(ctx context.Context, id int64) (models.User, bool) {
 	var user models.User
 	query := `
-		SELECT id, username, password_hash 
+		SELECT id, username, password_hash, email, tenant_id, deactivated_at, created_at, updated_at 
 		FROM users 
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
-	err := s.db.QueryRow(query, id).Scan(
+	err := querier(ctx, s.db).QueryRowContext(ctx, query, id, TenantFromContext(ctx)).Scan(
 		&user.ID,
 		&user.Username,
 		&user.PasswordHash,
+		&user.Email,
+		&user.TenantID,
+		&user.DeactivatedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.User{}, false
 		}
-		log.Printf("Error getting user: %v", err)
+		logging.Errorf("Error getting user: %v", err)
 		return models.User{}, false
 	}
 
@@ -76,18 +80,16 @@ func (s *PostgresUserStore) GetUser(id int64) (models.User, bool) {
 }
 
 // GetAllUsers returns all users from the database
-func (s *PostgresUserStore) GetAllUsers() []models.User {
-	s.RLock()
-	defer s.RUnlock()
-
+func (s *PostgresUserStore) GetAllUsers(ctx context.Context) []models.User {
 	query := `
-		SELECT id, username, password_hash 
+		SELECT id, username, password_hash, email, tenant_id, deactivated_at, created_at, updated_at 
 		FROM users
+		WHERE tenant_id = $1
 	`
 
-	rows, err := s.db.Query(query)
+	rows, err := querier(ctx, s.db).QueryContext(ctx, query, TenantFromContext(ctx))
 	if err != nil {
-		log.Printf("Error querying users: %v", err)
+		logging.Errorf("Error querying users: %v", err)
 		return []models.User{}
 	}
 	defer rows.Close()
@@ -100,10 +102,15 @@ func (s *PostgresUserStore) GetAllUsers() []models.User {
 			&user.ID,
 			&user.Username,
 			&user.PasswordHash,
+			&user.Email,
+			&user.TenantID,
+			&user.DeactivatedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
 		)
 
 		if err != nil {
-			log.Printf("Error scanning row: %v", err)
+			logging.Errorf("Error scanning row: %v", err)
 			continue
 		}
 
@@ -111,42 +118,36 @@ func (s *PostgresUserStore) GetAllUsers() []models.User {
 	}
 
 	if err = rows.Err(); err != nil {
-		log.Printf("Error iterating rows: %v", err)
+		logging.Errorf("Error iterating rows: %v", err)
 	}
 
 	return users
 }
 
 // UpdateUser updates an existing user in the database
-func (s *PostgresUserStore) UpdateUser(id int64, updatedUser models.User) (models.User, bool) {
-	s.Lock()
-	defer s.Unlock()
-
+func (s *PostgresUserStore) UpdateUser(ctx context.Context, id int64, updatedUser models.User) (models.User, bool) {
 	query := `
 		UPDATE users 
-		SET username = $1, password_hash = $2 
-		WHERE id = $3
+		SET username = $1, password_hash = $2, email = $3, updated_at = NOW() 
+		WHERE id = $4 AND tenant_id = $5
+		RETURNING tenant_id, deactivated_at, created_at, updated_at
 	`
 
-	result, err := s.db.Exec(
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
 		query,
 		updatedUser.Username,
 		updatedUser.PasswordHash,
+		updatedUser.Email,
 		id,
-	)
+		TenantFromContext(ctx),
+	).Scan(&updatedUser.TenantID, &updatedUser.DeactivatedAt, &updatedUser.CreatedAt, &updatedUser.UpdatedAt)
 
 	if err != nil {
-		log.Printf("Error updating user: %v", err)
-		return models.User{}, false
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		return models.User{}, false
-	}
-
-	if rowsAffected == 0 {
+		if err == sql.ErrNoRows {
+			return models.User{}, false
+		}
+		logging.Errorf("Error updating user: %v", err)
 		return models.User{}, false
 	}
 
@@ -155,48 +156,77 @@ func (s *PostgresUserStore) UpdateUser(id int64, updatedUser models.User) (model
 }
 
 // DeleteUser removes a user from the database
-func (s *PostgresUserStore) DeleteUser(id int64) bool {
-	s.Lock()
-	defer s.Unlock()
-
-	query := `DELETE FROM users WHERE id = $1`
-	result, err := s.db.Exec(query, id)
+func (s *PostgresUserStore) DeleteUser(ctx context.Context, id int64) bool {
+	query := `DELETE FROM users WHERE id = $1 AND tenant_id = $2`
+	result, err := querier(ctx, s.db).ExecContext(ctx, query, id, TenantFromContext(ctx))
 	if err != nil {
-		log.Printf("Error deleting user: %v", err)
+		logging.Errorf("Error deleting user: %v", err)
 		return false
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
+		logging.Errorf("Error getting rows affected: %v", err)
 		return false
 	}
 
 	return rowsAffected > 0
 }
 
-// AddSampleData adds sample data to the database if it's empty
-func (s *PostgresUserStore) AddSampleData() {
-	count := 0
-	err := s.db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
+// SetUserDeactivated deactivates or reactivates a user in the database
+func (s *PostgresUserStore) SetUserDeactivated(ctx context.Context, id int64, deactivatedAt *time.Time) (models.User, bool) {
+	var user models.User
+	query := `
+		UPDATE users
+		SET deactivated_at = $1, updated_at = NOW()
+		WHERE id = $2 AND tenant_id = $3
+		RETURNING id, username, password_hash, email, tenant_id, deactivated_at, created_at, updated_at
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(ctx, query, deactivatedAt, id, TenantFromContext(ctx)).Scan(
+		&user.ID,
+		&user.Username,
+		&user.PasswordHash,
+		&user.Email,
+		&user.TenantID,
+		&user.DeactivatedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
 	if err != nil {
-		log.Printf("Error checking for existing data: %v", err)
-		return
+		if err == sql.ErrNoRows {
+			return models.User{}, false
+		}
+		logging.Errorf("Error deactivating user: %v", err)
+		return models.User{}, false
 	}
 
-	// Add sample data if the table is empty
-	if count == 0 {
-		log.Println("Adding sample user data...")
+	return user, true
+}
 
-		// Add some sample data
-		s.CreateUser(models.User{
-			Username: "admin",
-			PasswordHash: []byte("admin123"),
-		})
+// GetTenants returns the tenants that have users, across all tenants
+func (s *PostgresUserStore) GetTenants(ctx context.Context) []string {
+	rows, err := querier(ctx, s.db).QueryContext(ctx, `SELECT DISTINCT tenant_id FROM users ORDER BY tenant_id`)
+	if err != nil {
+		logging.Errorf("Error querying tenants: %v", err)
+		return []string{}
+	}
+	defer rows.Close()
+
+	tenants := []string{}
+	for rows.Next() {
+		var tenant string
+		if err := rows.Scan(&tenant); err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+		tenants = append(tenants, tenant)
+	}
 
-		s.CreateUser(models.User{
-			Username: "user1",
-			PasswordHash: []byte("password123"),
-		})
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
 	}
+
+	return tenants
 }
@@ -0,0 +1,284 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoUser is the DynamoDB representation of a user
+type dynamoUser struct {
+	PK            string     `dynamodbav:"pk"`
+	SK            string     `dynamodbav:"sk"`
+	ID            int64      `dynamodbav:"id"`
+	Username      string     `dynamodbav:"username"`
+	PasswordHash  []byte     `dynamodbav:"password_hash"`
+	Email         string     `dynamodbav:"email,omitempty"`
+	TenantID      string     `dynamodbav:"tenant_id,omitempty"`
+	DeactivatedAt *time.Time `dynamodbav:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `dynamodbav:"created_at"`
+	UpdatedAt     time.Time  `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to a user
+func (r dynamoUser) toModel() models.User {
+	user := models.User{
+		ID:            r.ID,
+		Username:      r.Username,
+		PasswordHash:  r.PasswordHash,
+		Email:         r.Email,
+		TenantID:      r.TenantID,
+		DeactivatedAt: r.DeactivatedAt,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+	if user.TenantID == "" {
+		user.TenantID = DefaultTenant
+	}
+	return user
+}
+
+// DynamoUserStore provides DynamoDB storage operations for users
+type DynamoUserStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoUserStore creates a new DynamoDB user store using the given client and table
+func NewDynamoUserStore(client *dynamodb.Client, table string) *DynamoUserStore {
+	return &DynamoUserStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateUser adds a new user to the table
+func (s *DynamoUserStore) CreateUser(ctx context.Context, user models.User) models.User {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityUser)
+	if err != nil {
+		logging.Errorf("Error allocating user ID: %v", err)
+		return models.User{}
+	}
+	user.ID = id
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	user.TenantID = TenantFromContext(ctx)
+	user.DeactivatedAt = nil
+
+	record, err := attributevalue.MarshalMap(dynamoUser{
+		PK:           dynamoEntityUser,
+		SK:           dynamoSortKeyForID(user.ID),
+		ID:           user.ID,
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+		Email:        user.Email,
+		TenantID:     user.TenantID,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling user: %v", err)
+		return models.User{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		logging.Errorf("Error creating user: %v", err)
+		return models.User{} // Return empty user on error
+	}
+
+	return user
+}
+
+// GetUser retrieves a user by ID from the table
+func (s *DynamoUserStore) GetUser(ctx context.Context, id int64) (models.User, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityUser, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting user: %v", err)
+		return models.User{}, false
+	}
+	if output.Item == nil {
+		return models.User{}, false
+	}
+
+	var record dynamoUser
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling user: %v", err)
+		return models.User{}, false
+	}
+	if !ownTenant(ctx, record.TenantID) {
+		return models.User{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllUsers returns all users of the context's tenant from the table
+func (s *DynamoUserStore) GetAllUsers(ctx context.Context) []models.User {
+	input := s.usersQuery()
+	tenantDynamoQuery(ctx, input)
+	return s.queryUsers(ctx, input)
+}
+
+// GetTenants returns the tenants that have users, across all tenants
+func (s *DynamoUserStore) GetTenants(ctx context.Context) []string {
+	tenants := []string{}
+	for _, user := range s.queryUsers(ctx, s.usersQuery()) {
+		if !slices.Contains(tenants, user.TenantID) {
+			tenants = append(tenants, user.TenantID)
+		}
+	}
+	slices.Sort(tenants)
+	return tenants
+}
+
+// usersQuery returns the query for the users of all tenants
+func (s *DynamoUserStore) usersQuery() *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityUser},
+		},
+	}
+}
+
+// queryUsers returns the users matched by a query
+func (s *DynamoUserStore) queryUsers(ctx context.Context, input *dynamodb.QueryInput) []models.User {
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	var users []models.User
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying users: %v", err)
+			return []models.User{}
+		}
+
+		var records []dynamoUser
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling users: %v", err)
+			return []models.User{}
+		}
+		for _, record := range records {
+			users = append(users, record.toModel())
+		}
+	}
+
+	return users
+}
+
+// UpdateUser updates an existing user in the table
+func (s *DynamoUserStore) UpdateUser(ctx context.Context, id int64, updatedUser models.User) (models.User, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":username":      updatedUser.Username,
+		":password_hash": updatedUser.PasswordHash,
+		":email":         updatedUser.Email,
+		":updated_at":    time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling user: %v", err)
+		return models.User{}, false
+	}
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	condition, values := andDynamoCondition(aws.String("attribute_exists(pk)"), values, tenant, tenantValues)
+
+	// Update in place so the creation time is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityUser, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET username = :username, password_hash = :password_hash, email = :email, updated_at = :updated_at"),
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating user: %v", err)
+		}
+		return models.User{}, false
+	}
+
+	var record dynamoUser
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling user: %v", err)
+		return models.User{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// SetUserDeactivated deactivates or reactivates a user in the table
+func (s *DynamoUserStore) SetUserDeactivated(ctx context.Context, id int64, deactivatedAt *time.Time) (models.User, bool) {
+	update := "SET deactivated_at = :deactivated_at, updated_at = :updated_at"
+	attributes := map[string]any{":updated_at": time.Now()}
+	if deactivatedAt != nil {
+		attributes[":deactivated_at"] = *deactivatedAt
+	} else {
+		update = "SET updated_at = :updated_at REMOVE deactivated_at"
+	}
+	values, err := attributevalue.MarshalMap(attributes)
+	if err != nil {
+		logging.Errorf("Error marshalling user: %v", err)
+		return models.User{}, false
+	}
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	condition, values := andDynamoCondition(aws.String("attribute_exists(pk)"), values, tenant, tenantValues)
+
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityUser, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       condition,
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error deactivating user: %v", err)
+		}
+		return models.User{}, false
+	}
+
+	var record dynamoUser
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling user: %v", err)
+		return models.User{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// DeleteUser removes a user from the table
+func (s *DynamoUserStore) DeleteUser(ctx context.Context, id int64) bool {
+	condition, values := dynamoTenantCondition(ctx)
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityUser, dynamoSortKeyForID(id)),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error deleting user: %v", err)
+		}
+		return false
+	}
+
+	return len(output.Attributes) > 0
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"context"
 	"periodic-api/internal/models"
+	"slices"
 	"sync"
+	"time"
 )
 
 // MemoryUserStore provides in-memory storage operations for users
@@ -21,13 +24,17 @@ func NewMemoryUserStore() *MemoryUserStore {
 }
 
 // CreateUser adds a new user to the in-memory store
-func (s *MemoryUserStore) CreateUser(user models.User) models.User {
+func (s *MemoryUserStore) CreateUser(ctx context.Context, user models.User) models.User {
 	s.Lock()
 	defer s.Unlock()
 
 	// Assign a new ID
 	user.ID = s.nextID
 	s.nextID++
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	user.TenantID = TenantFromContext(ctx)
+	user.DeactivatedAt = nil
 
 	// Store the user
 	s.users[user.ID] = user
@@ -35,46 +42,56 @@ func (s *MemoryUserStore) CreateUser(user models.User) models.User {
 }
 
 // GetUser retrieves a user by ID from the in-memory store
-func (s *MemoryUserStore) GetUser(id int64) (models.User, bool) {
+func (s *MemoryUserStore) GetUser(ctx context.Context, id int64) (models.User, bool) {
 	s.RLock()
 	defer s.RUnlock()
 
 	user, exists := s.users[id]
-	return user, exists
+	if !exists || !ownTenant(ctx, user.TenantID) {
+		return models.User{}, false
+	}
+	return user, true
 }
 
 // GetAllUsers returns all users from the in-memory store
-func (s *MemoryUserStore) GetAllUsers() []models.User {
+func (s *MemoryUserStore) GetAllUsers(ctx context.Context) []models.User {
 	s.RLock()
 	defer s.RUnlock()
 
 	users := make([]models.User, 0, len(s.users))
 	for _, user := range s.users {
-		users = append(users, user)
+		if ownTenant(ctx, user.TenantID) {
+			users = append(users, user)
+		}
 	}
 	return users
 }
 
 // UpdateUser updates an existing user in the in-memory store
-func (s *MemoryUserStore) UpdateUser(id int64, updatedUser models.User) (models.User, bool) {
+func (s *MemoryUserStore) UpdateUser(ctx context.Context, id int64, updatedUser models.User) (models.User, bool) {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, exists := s.users[id]; !exists {
+	existing, exists := s.users[id]
+	if !exists || !ownTenant(ctx, existing.TenantID) {
 		return models.User{}, false
 	}
 
 	updatedUser.ID = id
+	updatedUser.TenantID = existing.TenantID
+	updatedUser.DeactivatedAt = existing.DeactivatedAt
+	updatedUser.CreatedAt = existing.CreatedAt
+	updatedUser.UpdatedAt = time.Now()
 	s.users[id] = updatedUser
 	return updatedUser, true
 }
 
 // DeleteUser removes a user from the in-memory store
-func (s *MemoryUserStore) DeleteUser(id int64) bool {
+func (s *MemoryUserStore) DeleteUser(ctx context.Context, id int64) bool {
 	s.Lock()
 	defer s.Unlock()
 
-	if _, exists := s.users[id]; !exists {
+	if user, exists := s.users[id]; !exists || !ownTenant(ctx, user.TenantID) {
 		return false
 	}
 
@@ -82,18 +99,33 @@ func (s *MemoryUserStore) DeleteUser(id int64) bool {
 	return true
 }
 
-// AddSampleData adds sample data to the in-memory store
-func (s *MemoryUserStore) AddSampleData() {
-	// Only add sample data if the store is empty
-	if len(s.GetAllUsers()) == 0 {
-		s.CreateUser(models.User{
-			Username: "admin",
-			PasswordHash: []byte("admin123"),
-		})
-
-		s.CreateUser(models.User{
-			Username: "user1",
-			PasswordHash: []byte("password123"),
-		})
+// SetUserDeactivated deactivates or reactivates a user in the in-memory store
+func (s *MemoryUserStore) SetUserDeactivated(ctx context.Context, id int64, deactivatedAt *time.Time) (models.User, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	user, exists := s.users[id]
+	if !exists || !ownTenant(ctx, user.TenantID) {
+		return models.User{}, false
+	}
+
+	user.DeactivatedAt = deactivatedAt
+	user.UpdatedAt = time.Now()
+	s.users[id] = user
+	return user, true
+}
+
+// GetTenants returns the tenants that have users, across all tenants
+func (s *MemoryUserStore) GetTenants(ctx context.Context) []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	tenants := []string{}
+	for _, user := range s.users {
+		if !slices.Contains(tenants, user.TenantID) {
+			tenants = append(tenants, user.TenantID)
+		}
 	}
+	slices.Sort(tenants)
+	return tenants
 }
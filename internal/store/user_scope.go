@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+type userContextKey struct{}
+
+// WithUser returns a context whose reads and changes through the scoped stores are
+// confined to what the user may see; see ScopedScheduledItemStore
+func WithUser(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+// WithoutUser returns a context that sees every item of its tenant through the scoped
+// stores, for checks that must count everything whoever asks, such as tenant limits
+func WithoutUser(ctx context.Context) context.Context {
+	return context.WithValue(ctx, userContextKey{}, int64(0))
+}
+
+// UserFromContext returns the user set by WithUser, and whether there is one
+func UserFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userContextKey{}).(int64)
+	return userID, ok && userID > 0
+}
+
+// ItemAccess is what a user may do with a scheduled item
+type ItemAccess int
+
+const (
+	ItemAccessNone ItemAccess = iota
+	ItemAccessView
+	ItemAccessEdit
+	ItemAccessOwner
+)
+
+// ScheduledItemAccess returns what the context's user may do with an item: everything
+// with the items they own, and what their share allows with items shared with them.
+// Contexts without a user, such as the scheduler's, those of administrators and those of
+// unauthenticated deployments, may do anything, as may everyone with items that have no
+// owner or when nothing can be shared.
+func ScheduledItemAccess(ctx context.Context, shares ItemShareStore, item models.ScheduledItem) ItemAccess {
+	if shares == nil || item.OwnerID == nil {
+		return ItemAccessOwner
+	}
+	userID, ok := UserFromContext(ctx)
+	if !ok || userID == *item.OwnerID {
+		return ItemAccessOwner
+	}
+
+	share, exists := shares.GetShare(ctx, item.ID, userID)
+	switch {
+	case !exists:
+		return ItemAccessNone
+	case share.Role == models.ItemShareRoleEditor:
+		return ItemAccessEdit
+	default:
+		return ItemAccessView
+	}
+}
@@ -1,15 +1,19 @@
 package store
 
 import (
+	"context"
 	"periodic-api/internal/models"
+	"time"
 )
 
 // UserStore defines the interface for user storage operations
 type UserStore interface {
-	CreateUser(user models.User) models.User
-	GetUser(id int64) (models.User, bool)
-	GetAllUsers() []models.User
-	UpdateUser(id int64, updatedUser models.User) (models.User, bool)
-	DeleteUser(id int64) bool
-	AddSampleData()
+	CreateUser(ctx context.Context, user models.User) models.User
+	GetUser(ctx context.Context, id int64) (models.User, bool)
+	GetAllUsers(ctx context.Context) []models.User
+	UpdateUser(ctx context.Context, id int64, updatedUser models.User) (models.User, bool)
+	DeleteUser(ctx context.Context, id int64) bool
+	// SetUserDeactivated deactivates a user as of deactivatedAt, or reactivates them when it is nil
+	SetUserDeactivated(ctx context.Context, id int64, deactivatedAt *time.Time) (models.User, bool)
+	GetTenants(ctx context.Context) []string
 }
@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// PostgresWebhookStore provides PostgreSQL storage operations for webhooks and their deliveries
+type PostgresWebhookStore struct {
+	db *sql.DB
+}
+
+// NewPostgresWebhookStore creates a new PostgreSQL webhook store with the given database connection
+func NewPostgresWebhookStore(db *sql.DB) *PostgresWebhookStore {
+	return &PostgresWebhookStore{
+		db: db,
+	}
+}
+
+// CreateWebhook adds a new webhook to the database
+func (s *PostgresWebhookStore) CreateWebhook(ctx context.Context, webhook models.Webhook) models.Webhook {
+	query := `
+		INSERT INTO webhooks 
+		(url, event_types, secret, active, owner_id, tenant_id) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
+		RETURNING id, created_at, updated_at
+	`
+
+	webhook.TenantID = TenantFromContext(ctx)
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		webhook.URL,
+		pq.Array(webhook.EventTypes),
+		sensitive(webhook.Secret),
+		webhook.Active,
+		webhook.OwnerID,
+		webhook.TenantID,
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+
+	if err != nil {
+		logging.Errorf("Error creating webhook: %v", err)
+		return models.Webhook{} // Return empty webhook on error
+	}
+
+	return webhook
+}
+
+// GetWebhook retrieves a webhook by ID from the database
+func (s *PostgresWebhookStore) GetWebhook(ctx context.Context, id int64) (models.Webhook, bool) {
+	var webhook models.Webhook
+	query := `
+		SELECT id, url, event_types, secret, active, owner_id, tenant_id, created_at, updated_at 
+		FROM webhooks 
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(ctx, query, id, TenantFromContext(ctx)).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		pq.Array(&webhook.EventTypes),
+		&webhook.Secret,
+		&webhook.Active,
+		&webhook.OwnerID,
+		&webhook.TenantID,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Webhook{}, false
+		}
+		logging.Errorf("Error getting webhook: %v", err)
+		return models.Webhook{}, false
+	}
+
+	return webhook, true
+}
+
+// GetAllWebhooks returns all webhooks from the database in ID order
+func (s *PostgresWebhookStore) GetAllWebhooks(ctx context.Context) []models.Webhook {
+	query := `
+		SELECT id, url, event_types, secret, active, owner_id, tenant_id, created_at, updated_at 
+		FROM webhooks
+		WHERE tenant_id = $1
+		ORDER BY id
+	`
+
+	rows, err := querier(ctx, s.db).QueryContext(ctx, query, TenantFromContext(ctx))
+	if err != nil {
+		logging.Errorf("Error querying webhooks: %v", err)
+		return []models.Webhook{}
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var webhook models.Webhook
+
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			pq.Array(&webhook.EventTypes),
+			&webhook.Secret,
+			&webhook.Active,
+			&webhook.OwnerID,
+			&webhook.TenantID,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+
+	return webhooks
+}
+
+// UpdateWebhook updates an existing webhook in the database
+func (s *PostgresWebhookStore) UpdateWebhook(ctx context.Context, id int64, updatedWebhook models.Webhook) (models.Webhook, bool) {
+	query := `
+		UPDATE webhooks 
+		SET url = $1, event_types = $2, secret = $3, active = $4, updated_at = NOW() 
+		WHERE id = $5 AND tenant_id = $6
+		RETURNING owner_id, tenant_id, created_at, updated_at
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		updatedWebhook.URL,
+		pq.Array(updatedWebhook.EventTypes),
+		sensitive(updatedWebhook.Secret),
+		updatedWebhook.Active,
+		id,
+		TenantFromContext(ctx),
+	).Scan(&updatedWebhook.OwnerID, &updatedWebhook.TenantID, &updatedWebhook.CreatedAt, &updatedWebhook.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Webhook{}, false
+		}
+		logging.Errorf("Error updating webhook: %v", err)
+		return models.Webhook{}, false
+	}
+
+	updatedWebhook.ID = id
+	return updatedWebhook, true
+}
+
+// DeleteWebhook removes a webhook from the database; its deliveries are removed by the foreign key
+func (s *PostgresWebhookStore) DeleteWebhook(ctx context.Context, id int64) bool {
+	query := `DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`
+	result, err := querier(ctx, s.db).ExecContext(ctx, query, id, TenantFromContext(ctx))
+	if err != nil {
+		logging.Errorf("Error deleting webhook: %v", err)
+		return false
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logging.Errorf("Error getting rows affected: %v", err)
+		return false
+	}
+
+	return rowsAffected > 0
+}
+
+// CreateWebhookDelivery records a delivery attempt in the database
+func (s *PostgresWebhookStore) CreateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) models.WebhookDelivery {
+	query := `
+		INSERT INTO webhook_deliveries 
+		(webhook_id, event_id, event_type, attempt, status_code, error_message, succeeded, delivered_at, duration_ms) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+		RETURNING id
+	`
+
+	err := querier(ctx, s.db).QueryRowContext(
+		ctx,
+		query,
+		delivery.WebhookID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.ErrorMessage,
+		delivery.Succeeded,
+		delivery.DeliveredAt,
+		delivery.DurationMs,
+	).Scan(&delivery.ID)
+
+	if err != nil {
+		logging.Errorf("Error creating webhook delivery: %v", err)
+		return models.WebhookDelivery{} // Return empty delivery on error
+	}
+
+	return delivery
+}
+
+// GetWebhookDeliveries returns up to limit deliveries of a webhook from the database, newest first
+func (s *PostgresWebhookStore) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) []models.WebhookDelivery {
+	query := `
+		SELECT id, webhook_id, event_id, event_type, attempt, status_code, error_message, succeeded, delivered_at, duration_ms 
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	rows, err := querier(ctx, s.db).QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		logging.Errorf("Error querying webhook deliveries: %v", err)
+		return []models.WebhookDelivery{}
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&delivery.Attempt,
+			&delivery.StatusCode,
+			&delivery.ErrorMessage,
+			&delivery.Succeeded,
+			&delivery.DeliveredAt,
+			&delivery.DurationMs,
+		)
+
+		if err != nil {
+			logging.Errorf("Error scanning row: %v", err)
+			continue
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		logging.Errorf("Error iterating rows: %v", err)
+	}
+
+	return deliveries
+}
@@ -0,0 +1,363 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// dynamoWebhook is the DynamoDB representation of a webhook
+type dynamoWebhook struct {
+	PK         string    `dynamodbav:"pk"`
+	SK         string    `dynamodbav:"sk"`
+	ID         int64     `dynamodbav:"id"`
+	URL        string    `dynamodbav:"url"`
+	EventTypes []string  `dynamodbav:"event_types"`
+	Secret     string    `dynamodbav:"secret"`
+	Active     bool      `dynamodbav:"active"`
+	OwnerID    *int64    `dynamodbav:"owner_id,omitempty"`
+	TenantID   string    `dynamodbav:"tenant_id,omitempty"`
+	CreatedAt  time.Time `dynamodbav:"created_at"`
+	UpdatedAt  time.Time `dynamodbav:"updated_at"`
+}
+
+// toModel converts the DynamoDB representation back to a webhook
+func (r dynamoWebhook) toModel() models.Webhook {
+	return models.Webhook{
+		ID:         r.ID,
+		URL:        r.URL,
+		EventTypes: r.EventTypes,
+		Secret:     r.Secret,
+		Active:     r.Active,
+		OwnerID:    r.OwnerID,
+		TenantID:   r.TenantID,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// dynamoWebhookDelivery is the DynamoDB representation of a webhook delivery. Deliveries
+// are partitioned by webhook so a webhook's log is a single Query.
+type dynamoWebhookDelivery struct {
+	PK           string    `dynamodbav:"pk"`
+	SK           string    `dynamodbav:"sk"`
+	ID           int64     `dynamodbav:"id"`
+	WebhookID    int64     `dynamodbav:"webhook_id"`
+	EventID      int64     `dynamodbav:"event_id"`
+	EventType    string    `dynamodbav:"event_type"`
+	Attempt      int       `dynamodbav:"attempt"`
+	StatusCode   *int      `dynamodbav:"status_code,omitempty"`
+	ErrorMessage *string   `dynamodbav:"error_message,omitempty"`
+	Succeeded    bool      `dynamodbav:"succeeded"`
+	DeliveredAt  time.Time `dynamodbav:"delivered_at"`
+	DurationMs   int64     `dynamodbav:"duration_ms"`
+}
+
+// toModel converts the DynamoDB representation back to a webhook delivery
+func (r dynamoWebhookDelivery) toModel() models.WebhookDelivery {
+	return models.WebhookDelivery{
+		ID:           r.ID,
+		WebhookID:    r.WebhookID,
+		EventID:      r.EventID,
+		EventType:    r.EventType,
+		Attempt:      r.Attempt,
+		StatusCode:   r.StatusCode,
+		ErrorMessage: r.ErrorMessage,
+		Succeeded:    r.Succeeded,
+		DeliveredAt:  r.DeliveredAt,
+		DurationMs:   r.DurationMs,
+	}
+}
+
+// dynamoWebhookDeliveryPartition returns the partition key holding the deliveries of a webhook
+func dynamoWebhookDeliveryPartition(webhookID int64) string {
+	return fmt.Sprintf("%s#%d", dynamoEntityWebhookDelivery, webhookID)
+}
+
+// DynamoWebhookStore provides DynamoDB storage operations for webhooks and their deliveries
+type DynamoWebhookStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+// NewDynamoWebhookStore creates a new DynamoDB webhook store using the given client and table
+func NewDynamoWebhookStore(client *dynamodb.Client, table string) *DynamoWebhookStore {
+	return &DynamoWebhookStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// CreateWebhook adds a new webhook to the table
+func (s *DynamoWebhookStore) CreateWebhook(ctx context.Context, webhook models.Webhook) models.Webhook {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityWebhook)
+	if err != nil {
+		logging.Errorf("Error allocating webhook ID: %v", err)
+		return models.Webhook{}
+	}
+	webhook.ID = id
+	webhook.TenantID = TenantFromContext(ctx)
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	record, err := attributevalue.MarshalMap(dynamoWebhook{
+		PK:         dynamoEntityWebhook,
+		SK:         dynamoSortKeyForID(webhook.ID),
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Secret:     webhook.Secret,
+		Active:     webhook.Active,
+		OwnerID:    webhook.OwnerID,
+		TenantID:   webhook.TenantID,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling webhook: %v", err)
+		return models.Webhook{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(pk)"),
+	})
+	if err != nil {
+		logging.Errorf("Error creating webhook: %v", err)
+		return models.Webhook{} // Return empty webhook on error
+	}
+
+	return webhook
+}
+
+// GetWebhook retrieves a webhook by ID from the table
+func (s *DynamoWebhookStore) GetWebhook(ctx context.Context, id int64) (models.Webhook, bool) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoKey(dynamoEntityWebhook, dynamoSortKeyForID(id)),
+	})
+	if err != nil {
+		logging.Errorf("Error getting webhook: %v", err)
+		return models.Webhook{}, false
+	}
+	if output.Item == nil {
+		return models.Webhook{}, false
+	}
+
+	var record dynamoWebhook
+	if err := attributevalue.UnmarshalMap(output.Item, &record); err != nil {
+		logging.Errorf("Error unmarshalling webhook: %v", err)
+		return models.Webhook{}, false
+	}
+	if !ownTenant(ctx, record.TenantID) {
+		return models.Webhook{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// GetAllWebhooks returns all webhooks from the table in ID order
+func (s *DynamoWebhookStore) GetAllWebhooks(ctx context.Context) []models.Webhook {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoEntityWebhook},
+		},
+	}
+	tenantDynamoQuery(ctx, input)
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+
+	webhooks := []models.Webhook{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying webhooks: %v", err)
+			return []models.Webhook{}
+		}
+
+		var records []dynamoWebhook
+		if err := attributevalue.UnmarshalListOfMaps(page.Items, &records); err != nil {
+			logging.Errorf("Error unmarshalling webhooks: %v", err)
+			return []models.Webhook{}
+		}
+		for _, record := range records {
+			webhooks = append(webhooks, record.toModel())
+		}
+	}
+
+	return webhooks
+}
+
+// UpdateWebhook updates an existing webhook in the table
+func (s *DynamoWebhookStore) UpdateWebhook(ctx context.Context, id int64, updatedWebhook models.Webhook) (models.Webhook, bool) {
+	values, err := attributevalue.MarshalMap(map[string]any{
+		":url":         updatedWebhook.URL,
+		":event_types": updatedWebhook.EventTypes,
+		":secret":      updatedWebhook.Secret,
+		":active":      updatedWebhook.Active,
+		":updated_at":  time.Now(),
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling webhook: %v", err)
+		return models.Webhook{}, false
+	}
+	tenant, tenantValues := dynamoTenantCondition(ctx)
+	condition, values := andDynamoCondition(aws.String("attribute_exists(pk)"), values, tenant, tenantValues)
+
+	// Update in place so the creation time is kept
+	output, err := s.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityWebhook, dynamoSortKeyForID(id)),
+		UpdateExpression:          aws.String("SET #url = :url, event_types = :event_types, secret = :secret, active = :active, updated_at = :updated_at"),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  map[string]string{"#url": "url"},
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error updating webhook: %v", err)
+		}
+		return models.Webhook{}, false
+	}
+
+	var record dynamoWebhook
+	if err := attributevalue.UnmarshalMap(output.Attributes, &record); err != nil {
+		logging.Errorf("Error unmarshalling webhook: %v", err)
+		return models.Webhook{}, false
+	}
+
+	return record.toModel(), true
+}
+
+// DeleteWebhook removes a webhook and its delivery log from the table
+func (s *DynamoWebhookStore) DeleteWebhook(ctx context.Context, id int64) bool {
+	condition, values := dynamoTenantCondition(ctx)
+	output, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(s.table),
+		Key:                       dynamoKey(dynamoEntityWebhook, dynamoSortKeyForID(id)),
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeValues: values,
+		ReturnValues:              types.ReturnValueAllOld,
+	})
+	if err != nil {
+		if !isConditionalCheckFailed(err) {
+			logging.Errorf("Error deleting webhook: %v", err)
+		}
+		return false
+	}
+	if len(output.Attributes) == 0 {
+		return false
+	}
+
+	// Deleting the log is best effort; leftover deliveries are unreachable without the webhook
+	partition := dynamoWebhookDeliveryPartition(id)
+	paginator := dynamodb.NewQueryPaginator(s.client, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: partition},
+		},
+		ProjectionExpression: aws.String("sk"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			logging.Errorf("Error querying deliveries of deleted webhook ID=%d: %v", id, err)
+			break
+		}
+		for _, item := range page.Items {
+			sortKey, ok := item[dynamoSortKey].(*types.AttributeValueMemberS)
+			if !ok {
+				continue
+			}
+			_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: aws.String(s.table),
+				Key:       dynamoKey(partition, sortKey.Value),
+			})
+			if err != nil {
+				logging.Errorf("Error deleting delivery of deleted webhook ID=%d: %v", id, err)
+			}
+		}
+	}
+
+	return true
+}
+
+// CreateWebhookDelivery records a delivery attempt in the table
+func (s *DynamoWebhookStore) CreateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) models.WebhookDelivery {
+	id, err := nextDynamoID(ctx, s.client, s.table, dynamoEntityWebhookDelivery)
+	if err != nil {
+		logging.Errorf("Error allocating webhook delivery ID: %v", err)
+		return models.WebhookDelivery{}
+	}
+	delivery.ID = id
+
+	record, err := attributevalue.MarshalMap(dynamoWebhookDelivery{
+		PK:           dynamoWebhookDeliveryPartition(delivery.WebhookID),
+		SK:           dynamoSortKeyForID(delivery.ID),
+		ID:           delivery.ID,
+		WebhookID:    delivery.WebhookID,
+		EventID:      delivery.EventID,
+		EventType:    delivery.EventType,
+		Attempt:      delivery.Attempt,
+		StatusCode:   delivery.StatusCode,
+		ErrorMessage: delivery.ErrorMessage,
+		Succeeded:    delivery.Succeeded,
+		DeliveredAt:  delivery.DeliveredAt,
+		DurationMs:   delivery.DurationMs,
+	})
+	if err != nil {
+		logging.Errorf("Error marshalling webhook delivery: %v", err)
+		return models.WebhookDelivery{}
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      record,
+	})
+	if err != nil {
+		logging.Errorf("Error creating webhook delivery: %v", err)
+		return models.WebhookDelivery{} // Return empty delivery on error
+	}
+
+	return delivery
+}
+
+// GetWebhookDeliveries returns up to limit deliveries of a webhook from the table, newest first
+func (s *DynamoWebhookStore) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) []models.WebhookDelivery {
+	output, err := s.client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.table),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberS{Value: dynamoWebhookDeliveryPartition(webhookID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(int32(limit)),
+	})
+	if err != nil {
+		logging.Errorf("Error querying webhook deliveries: %v", err)
+		return []models.WebhookDelivery{}
+	}
+
+	var records []dynamoWebhookDelivery
+	if err := attributevalue.UnmarshalListOfMaps(output.Items, &records); err != nil {
+		logging.Errorf("Error unmarshalling webhook deliveries: %v", err)
+		return []models.WebhookDelivery{}
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(records))
+	for _, record := range records {
+		deliveries = append(deliveries, record.toModel())
+	}
+	return deliveries
+}
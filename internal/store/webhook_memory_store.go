@@ -0,0 +1,135 @@
+package store
+
+import (
+	"cmp"
+	"context"
+	"periodic-api/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryWebhookStore provides in-memory storage operations for webhooks and their deliveries
+type MemoryWebhookStore struct {
+	sync.RWMutex
+	webhooks       map[int64]models.Webhook
+	deliveries     map[int64][]models.WebhookDelivery
+	nextID         int64
+	nextDeliveryID int64
+}
+
+// NewMemoryWebhookStore creates a new in-memory webhook store
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		webhooks:       make(map[int64]models.Webhook),
+		deliveries:     make(map[int64][]models.WebhookDelivery),
+		nextID:         1,
+		nextDeliveryID: 1,
+	}
+}
+
+// CreateWebhook adds a new webhook to the in-memory store
+func (s *MemoryWebhookStore) CreateWebhook(ctx context.Context, webhook models.Webhook) models.Webhook {
+	s.Lock()
+	defer s.Unlock()
+
+	webhook.ID = s.nextID
+	s.nextID++
+	webhook.EventTypes = slices.Clone(webhook.EventTypes)
+	webhook.TenantID = TenantFromContext(ctx)
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	s.webhooks[webhook.ID] = webhook
+	return webhook
+}
+
+// GetWebhook retrieves a webhook by ID from the in-memory store
+func (s *MemoryWebhookStore) GetWebhook(ctx context.Context, id int64) (models.Webhook, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	webhook, exists := s.webhooks[id]
+	if !exists || !ownTenant(ctx, webhook.TenantID) {
+		return models.Webhook{}, false
+	}
+	return webhook, true
+}
+
+// GetAllWebhooks returns all webhooks from the in-memory store in ID order
+func (s *MemoryWebhookStore) GetAllWebhooks(ctx context.Context) []models.Webhook {
+	s.RLock()
+	defer s.RUnlock()
+
+	webhooks := make([]models.Webhook, 0, len(s.webhooks))
+	for _, webhook := range s.webhooks {
+		if ownTenant(ctx, webhook.TenantID) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b models.Webhook) int { return cmp.Compare(a.ID, b.ID) })
+	return webhooks
+}
+
+// UpdateWebhook updates an existing webhook in the in-memory store
+func (s *MemoryWebhookStore) UpdateWebhook(ctx context.Context, id int64, updatedWebhook models.Webhook) (models.Webhook, bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	existing, exists := s.webhooks[id]
+	if !exists || !ownTenant(ctx, existing.TenantID) {
+		return models.Webhook{}, false
+	}
+
+	updatedWebhook.ID = id
+	updatedWebhook.EventTypes = slices.Clone(updatedWebhook.EventTypes)
+	updatedWebhook.OwnerID = existing.OwnerID
+	updatedWebhook.TenantID = existing.TenantID
+	updatedWebhook.CreatedAt = existing.CreatedAt
+	updatedWebhook.UpdatedAt = time.Now()
+	s.webhooks[id] = updatedWebhook
+	return updatedWebhook, true
+}
+
+// DeleteWebhook removes a webhook and its delivery log from the in-memory store
+func (s *MemoryWebhookStore) DeleteWebhook(ctx context.Context, id int64) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if webhook, exists := s.webhooks[id]; !exists || !ownTenant(ctx, webhook.TenantID) {
+		return false
+	}
+
+	delete(s.webhooks, id)
+	delete(s.deliveries, id)
+	return true
+}
+
+// CreateWebhookDelivery records a delivery attempt in the in-memory store
+func (s *MemoryWebhookStore) CreateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) models.WebhookDelivery {
+	s.Lock()
+	defer s.Unlock()
+
+	// Deliveries still in flight when their webhook is deleted are dropped
+	if _, exists := s.webhooks[delivery.WebhookID]; !exists {
+		return models.WebhookDelivery{}
+	}
+
+	delivery.ID = s.nextDeliveryID
+	s.nextDeliveryID++
+	s.deliveries[delivery.WebhookID] = append(s.deliveries[delivery.WebhookID], delivery)
+	return delivery
+}
+
+// GetWebhookDeliveries returns up to limit deliveries of a webhook from the in-memory store, newest first
+func (s *MemoryWebhookStore) GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) []models.WebhookDelivery {
+	s.RLock()
+	defer s.RUnlock()
+
+	stored := s.deliveries[webhookID]
+	deliveries := make([]models.WebhookDelivery, 0, min(limit, len(stored)))
+	for i := len(stored) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		deliveries = append(deliveries, stored[i])
+	}
+	return deliveries
+}
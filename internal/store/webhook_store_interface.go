@@ -0,0 +1,18 @@
+package store
+
+import (
+	"context"
+	"periodic-api/internal/models"
+)
+
+// WebhookStore defines the interface for webhook and webhook delivery storage operations
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook models.Webhook) models.Webhook
+	GetWebhook(ctx context.Context, id int64) (models.Webhook, bool)
+	GetAllWebhooks(ctx context.Context) []models.Webhook
+	UpdateWebhook(ctx context.Context, id int64, updatedWebhook models.Webhook) (models.Webhook, bool)
+	DeleteWebhook(ctx context.Context, id int64) bool
+	CreateWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) models.WebhookDelivery
+	// GetWebhookDeliveries returns up to limit deliveries of a webhook, newest first
+	GetWebhookDeliveries(ctx context.Context, webhookID int64, limit int) []models.WebhookDelivery
+}
@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	smithytracing "github.com/aws/smithy-go/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AWS returns a tracer provider for the TracerProvider option of AWS SDK clients, which
+// records SDK operations such as Bedrock and DynamoDB calls as spans of the current trace
+func AWS() smithytracing.TracerProvider {
+	return awsTracerProvider{}
+}
+
+// awsSpanKinds maps the SDK's span kinds to OpenTelemetry's
+var awsSpanKinds = map[smithytracing.SpanKind]trace.SpanKind{
+	smithytracing.SpanKindInternal: trace.SpanKindInternal,
+	smithytracing.SpanKindClient:   trace.SpanKindClient,
+	smithytracing.SpanKindServer:   trace.SpanKindServer,
+	smithytracing.SpanKindProducer: trace.SpanKindProducer,
+	smithytracing.SpanKindConsumer: trace.SpanKindConsumer,
+}
+
+// awsTracerProvider creates tracers from the installed OpenTelemetry provider
+type awsTracerProvider struct{}
+
+func (awsTracerProvider) Tracer(scope string, options ...smithytracing.TracerOption) smithytracing.Tracer {
+	return awsTracer{tracer: otel.Tracer(scope)}
+}
+
+// awsTracer starts OpenTelemetry spans for the SDK
+type awsTracer struct {
+	tracer trace.Tracer
+}
+
+func (t awsTracer) StartSpan(ctx context.Context, name string, options ...smithytracing.SpanOption) (context.Context, smithytracing.Span) {
+	var spanOptions smithytracing.SpanOptions
+	for _, option := range options {
+		option(&spanOptions)
+	}
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(awsSpanKinds[spanOptions.Kind]),
+		trace.WithAttributes(awsAttributes(spanOptions.Properties.Values())...))
+	return ctx, awsSpan{name: name, span: span}
+}
+
+// awsSpan adapts an OpenTelemetry span to the SDK's span interface
+type awsSpan struct {
+	name string
+	span trace.Span
+}
+
+func (s awsSpan) Name() string {
+	return s.name
+}
+
+func (s awsSpan) Context() smithytracing.SpanContext {
+	spanContext := s.span.SpanContext()
+	return smithytracing.SpanContext{
+		TraceID:  spanContext.TraceID().String(),
+		SpanID:   spanContext.SpanID().String(),
+		IsRemote: spanContext.IsRemote(),
+	}
+}
+
+func (s awsSpan) AddEvent(name string, options ...smithytracing.EventOption) {
+	var eventOptions smithytracing.EventOptions
+	for _, option := range options {
+		option(&eventOptions)
+	}
+	s.span.AddEvent(name, trace.WithAttributes(awsAttributes(eventOptions.Properties.Values())...))
+}
+
+func (s awsSpan) SetStatus(status smithytracing.SpanStatus) {
+	switch status {
+	case smithytracing.SpanStatusOK:
+		s.span.SetStatus(codes.Ok, "")
+	case smithytracing.SpanStatusError:
+		s.span.SetStatus(codes.Error, "")
+	}
+}
+
+func (s awsSpan) SetProperty(key, value any) {
+	s.span.SetAttributes(awsAttribute(key, value))
+}
+
+func (s awsSpan) End() {
+	s.span.End()
+}
+
+// awsAttributes converts SDK span properties to attributes
+func awsAttributes(properties map[any]any) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(properties))
+	for key, value := range properties {
+		attributes = append(attributes, awsAttribute(key, value))
+	}
+	return attributes
+}
+
+// awsAttribute converts an SDK span property to an attribute
+func awsAttribute(key, value any) attribute.KeyValue {
+	name := fmt.Sprint(key)
+	switch v := value.(type) {
+	case string:
+		return attribute.String(name, v)
+	case bool:
+		return attribute.Bool(name, v)
+	case int:
+		return attribute.Int(name, v)
+	case int64:
+		return attribute.Int64(name, v)
+	case float64:
+		return attribute.Float64(name, v)
+	default:
+		return attribute.String(name, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"periodic-api/internal/logging"
+)
+
+// Config configures where spans are exported
+type Config struct {
+	// Endpoint is the URL spans are POSTed to, such as http://localhost:4318/v1/traces
+	Endpoint string
+	// Headers are sent with every export, such as an API key for a hosted collector
+	Headers map[string]string
+	// ServiceName identifies the process in the exported spans
+	ServiceName string
+}
+
+// ConfigFromEnv returns the export configuration from the standard OpenTelemetry
+// variables, and whether tracing is enabled. OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is the
+// full URL to send spans to, or OTEL_EXPORTER_OTLP_ENDPOINT the collector's base URL, to
+// which /v1/traces is added. OTEL_EXPORTER_OTLP_HEADERS holds comma-separated name=value
+// pairs, and OTEL_SERVICE_NAME overrides the given service name.
+func ConfigFromEnv(serviceName string) (Config, bool) {
+	config := Config{
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		Headers:     map[string]string{},
+		ServiceName: serviceName,
+	}
+	if config.Endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			config.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if config.Endpoint == "" {
+		return config, false
+	}
+	if parsed, err := url.Parse(config.Endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		logging.Warnf("Invalid OTLP endpoint %q, tracing disabled", config.Endpoint)
+		return config, false
+	}
+
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		config.ServiceName = name
+	}
+	if headers := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); headers != "" {
+		for _, pair := range strings.Split(headers, ",") {
+			name, value, found := strings.Cut(pair, "=")
+			name = strings.TrimSpace(name)
+			if !found || name == "" {
+				logging.Warnf("Ignoring invalid OTEL_EXPORTER_OTLP_HEADERS entry %q", pair)
+				continue
+			}
+			// Values may be URL encoded, as the specification allows
+			if decoded, err := url.QueryUnescape(strings.TrimSpace(value)); err == nil {
+				value = decoded
+			}
+			config.Headers[name] = value
+		}
+	}
+	return config, true
+}
@@ -0,0 +1,293 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/version"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// exportInterval is how often queued spans are sent
+	exportInterval = 5 * time.Second
+	// exportBatchSize is the most spans sent in one request; a full batch is sent at once
+	exportBatchSize = 512
+	// exportQueueSize is the most spans waiting to be sent; further spans are dropped
+	exportQueueSize = 4096
+	// exportTimeout bounds each request to the collector
+	exportTimeout = 10 * time.Second
+)
+
+// exporter batches ended spans and sends them to the collector
+type exporter struct {
+	config Config
+	client *http.Client
+	spans  chan spanData
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newExporter creates an exporter for the configured collector
+func newExporter(config Config) *exporter {
+	return &exporter{
+		config: config,
+		client: &http.Client{Timeout: exportTimeout},
+		spans:  make(chan spanData, exportQueueSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// add queues an ended span, dropping it if the queue is full
+func (e *exporter) add(data spanData) {
+	select {
+	case e.spans <- data:
+	default:
+		logging.Debugf("Span queue is full, dropping span %s", data.name)
+	}
+}
+
+// close stops run after it sends the queued spans
+func (e *exporter) close() {
+	e.closeOnce.Do(func() { close(e.closed) })
+}
+
+// run sends the queued spans every exportInterval, or as soon as a batch is full, until
+// close is called
+func (e *exporter) run() {
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	batch := make([]spanData, 0, exportBatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = batch[:0]
+		}
+	}
+	for {
+		select {
+		case data := <-e.spans:
+			batch = append(batch, data)
+			if len(batch) == exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.closed:
+			for {
+				select {
+				case data := <-e.spans:
+					batch = append(batch, data)
+					if len(batch) == exportBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send exports a batch of spans, logging failures; spans that can't be sent are dropped
+func (e *exporter) send(batch []spanData) {
+	body, err := json.Marshal(e.request(batch))
+	if err != nil {
+		logging.Errorf("Error encoding %d spans: %v", len(batch), err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		logging.Errorf("Error creating span export request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		logging.Warnf("Failed to export %d spans: %v", len(batch), err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		logging.Warnf("Failed to export %d spans: collector returned status %d: %s", len(batch), resp.StatusCode, bytes.TrimSpace(message))
+	}
+}
+
+// OTLP JSON encoding of an export request. IDs are hex and 64-bit integers are strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		TraceState        string         `json:"traceState,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string     `json:"stringValue,omitempty"`
+		BoolValue   *bool       `json:"boolValue,omitempty"`
+		IntValue    *string     `json:"intValue,omitempty"`
+		DoubleValue *float64    `json:"doubleValue,omitempty"`
+		ArrayValue  *otlpValues `json:"arrayValue,omitempty"`
+	}
+	otlpValues struct {
+		Values []otlpValue `json:"values"`
+	}
+)
+
+// request builds the export request for a batch, grouping the spans by scope
+func (e *exporter) request(batch []spanData) otlpRequest {
+	resource := otlpResource{Attributes: otlpAttributes([]attribute.KeyValue{
+		attribute.String("service.name", e.config.ServiceName),
+		attribute.String("service.version", version.Version),
+	})}
+
+	var scopes []otlpScopeSpans
+	index := map[string]int{}
+	for _, data := range batch {
+		i, ok := index[data.scope]
+		if !ok {
+			i = len(scopes)
+			index[data.scope] = i
+			scopes = append(scopes, otlpScopeSpans{Scope: otlpScope{Name: data.scope}})
+		}
+		scopes[i].Spans = append(scopes[i].Spans, otlpSpanFrom(data))
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{Resource: resource, ScopeSpans: scopes}}}
+}
+
+// otlpSpanFrom encodes a span. The API's span kinds have the same numbers as OTLP's, but
+// its status codes don't.
+func otlpSpanFrom(data spanData) otlpSpan {
+	span := otlpSpan{
+		TraceID:           data.spanContext.TraceID().String(),
+		SpanID:            data.spanContext.SpanID().String(),
+		TraceState:        data.spanContext.TraceState().String(),
+		Name:              data.name,
+		Kind:              int(data.kind),
+		StartTimeUnixNano: unixNano(data.start),
+		EndTimeUnixNano:   unixNano(data.end),
+		Attributes:        otlpAttributes(data.attributes),
+	}
+	if data.parentSpanID.IsValid() {
+		span.ParentSpanID = data.parentSpanID.String()
+	}
+	for _, e := range data.events {
+		span.Events = append(span.Events, otlpEvent{TimeUnixNano: unixNano(e.time), Name: e.name, Attributes: otlpAttributes(e.attributes)})
+	}
+	switch data.statusCode {
+	case codes.Ok:
+		span.Status.Code = 1
+	case codes.Error:
+		span.Status = otlpStatus{Code: 2, Message: data.statusText}
+	}
+	return span
+}
+
+// otlpAttributes encodes attributes
+func otlpAttributes(attributes []attribute.KeyValue) []otlpKeyValue {
+	encoded := make([]otlpKeyValue, 0, len(attributes))
+	for _, attr := range attributes {
+		encoded = append(encoded, otlpKeyValue{Key: string(attr.Key), Value: otlpValueFrom(attr.Value)})
+	}
+	return encoded
+}
+
+// otlpValueFrom encodes an attribute value
+func otlpValueFrom(value attribute.Value) otlpValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		v := value.AsBool()
+		return otlpValue{BoolValue: &v}
+	case attribute.INT64:
+		v := strconv.FormatInt(value.AsInt64(), 10)
+		return otlpValue{IntValue: &v}
+	case attribute.FLOAT64:
+		v := value.AsFloat64()
+		return otlpValue{DoubleValue: &v}
+	case attribute.STRING:
+		v := value.AsString()
+		return otlpValue{StringValue: &v}
+	case attribute.BOOLSLICE:
+		return otlpArray(value.AsBoolSlice(), attribute.BoolValue)
+	case attribute.INT64SLICE:
+		return otlpArray(value.AsInt64Slice(), attribute.Int64Value)
+	case attribute.FLOAT64SLICE:
+		return otlpArray(value.AsFloat64Slice(), attribute.Float64Value)
+	case attribute.STRINGSLICE:
+		return otlpArray(value.AsStringSlice(), attribute.StringValue)
+	default:
+		v := fmt.Sprint(value.AsInterface())
+		return otlpValue{StringValue: &v}
+	}
+}
+
+// otlpArray encodes a slice attribute as an array of values
+func otlpArray[T any](values []T, toValue func(T) attribute.Value) otlpValue {
+	array := &otlpValues{Values: make([]otlpValue, 0, len(values))}
+	for _, v := range values {
+		array.Values = append(array.Values, otlpValueFrom(toValue(v)))
+	}
+	return otlpValue{ArrayValue: array}
+}
+
+// unixNano formats a time as OTLP JSON does, as a string of nanoseconds since the epoch
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,256 @@
+// Package tracing records OpenTelemetry spans and exports them to a collector over
+// OTLP/HTTP with JSON encoding. Code creates spans with the OpenTelemetry API
+// (otel.Tracer), which does nothing until Setup installs the provider here.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+// Setup installs a tracer provider exporting to the configured collector, and W3C trace
+// context propagation. The returned function sends the remaining spans, waiting at most
+// exportTimeout, and stops exporting.
+func Setup(config Config) (shutdown func()) {
+	exporter := newExporter(config)
+	provider := &provider{exporter: exporter}
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	done := make(chan struct{})
+	go func() {
+		exporter.run()
+		close(done)
+	}()
+	return func() {
+		exporter.close()
+		select {
+		case <-done:
+		case <-time.After(exportTimeout):
+		}
+	}
+}
+
+// provider creates tracers whose spans are sent to an exporter when they end
+type provider struct {
+	embedded.TracerProvider
+	exporter *exporter
+}
+
+// Tracer returns a tracer for the named instrumentation scope
+func (p *provider) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
+	return &tracer{provider: p, scope: name}
+}
+
+// tracer starts spans in an instrumentation scope
+type tracer struct {
+	embedded.Tracer
+	provider *provider
+	scope    string
+}
+
+// Start starts a span, as a child of the span in ctx unless it asks for a new root. Spans
+// in a trace a caller chose not to sample aren't recorded.
+func (t *tracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+
+	parent := trace.SpanContextFromContext(ctx)
+	if config.NewRoot() {
+		parent = trace.SpanContext{}
+	}
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		rand.Read(traceID[:])
+	}
+	var spanID trace.SpanID
+	rand.Read(spanID[:])
+
+	flags := trace.FlagsSampled
+	if parent.IsValid() && !parent.IsSampled() {
+		flags = 0
+	}
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+		TraceState: parent.TraceState(),
+	})
+	if !spanContext.IsSampled() {
+		// A span carrying the context without recording anything
+		ctx = trace.ContextWithSpanContext(ctx, spanContext)
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	start := config.Timestamp()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	s := &span{
+		tracer: t,
+		data: spanData{
+			scope:       t.scope,
+			name:        name,
+			kind:        config.SpanKind(),
+			spanContext: spanContext,
+			start:       start,
+			attributes:  slices.Clone(config.Attributes()),
+		},
+	}
+	if parent.IsValid() {
+		s.data.parentSpanID = parent.SpanID()
+	}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// spanData is what is exported for a span
+type spanData struct {
+	scope        string
+	name         string
+	kind         trace.SpanKind
+	spanContext  trace.SpanContext
+	parentSpanID trace.SpanID
+	start        time.Time
+	end          time.Time
+	attributes   []attribute.KeyValue
+	events       []event
+	statusCode   codes.Code
+	statusText   string
+}
+
+// event is something that happened during a span, such as an error
+type event struct {
+	name       string
+	time       time.Time
+	attributes []attribute.KeyValue
+}
+
+// span records an operation until it ends
+type span struct {
+	embedded.Span
+	tracer *tracer
+
+	mu    sync.Mutex
+	data  spanData
+	ended bool
+}
+
+// End finishes the span and queues it for export. Calls after the first are ignored.
+func (s *span) End(options ...trace.SpanEndOption) {
+	config := trace.NewSpanEndConfig(options...)
+	end := config.Timestamp()
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.end = end
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.provider.exporter.add(data)
+}
+
+// AddEvent records an event at the current time, or the time given in the options
+func (s *span) AddEvent(name string, options ...trace.EventOption) {
+	config := trace.NewEventConfig(options...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.events = append(s.data.events, event{name: name, time: config.Timestamp(), attributes: config.Attributes()})
+	}
+}
+
+// AddLink is a no-op, since links aren't exported
+func (s *span) AddLink(link trace.Link) {}
+
+// IsRecording reports whether the span is still recording
+func (s *span) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+// RecordError records the error as an exception event. It doesn't change the status.
+func (s *span) RecordError(err error, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	options = append(options, trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", err)),
+		attribute.String("exception.message", err.Error()),
+	))
+	s.AddEvent("exception", options...)
+}
+
+// SpanContext returns the span's identifiers
+func (s *span) SpanContext() trace.SpanContext {
+	return s.data.spanContext
+}
+
+// SetStatus sets the outcome of the operation. Unset is ignored and an OK status is
+// final, as the specification requires; the description is only kept for errors.
+func (s *span) SetStatus(code codes.Code, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended || code == codes.Unset || s.data.statusCode == codes.Ok {
+		return
+	}
+	s.data.statusCode = code
+	s.data.statusText = ""
+	if code == codes.Error {
+		s.data.statusText = description
+	}
+}
+
+// SetName renames the span
+func (s *span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.name = name
+	}
+}
+
+// SetAttributes adds attributes to the span, replacing any with the same keys
+func (s *span) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for _, attr := range kv {
+		replaced := false
+		for i, existing := range s.data.attributes {
+			if existing.Key == attr.Key {
+				s.data.attributes[i] = attr
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.data.attributes = append(s.data.attributes, attr)
+		}
+	}
+}
+
+// TracerProvider returns the provider that created the span
+func (s *span) TracerProvider() trace.TracerProvider {
+	return s.tracer.provider
+}
@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	if _, enabled := ConfigFromEnv("periodic-api"); enabled {
+		t.Error("Expected tracing to be disabled without an endpoint")
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-api-key=abc%3D, x-team = ops")
+	config, enabled := ConfigFromEnv("periodic-api")
+	if !enabled || config.Endpoint != "http://collector:4318/v1/traces" || config.ServiceName != "periodic-api" {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if config.Headers["x-api-key"] != "abc=" || config.Headers["x-team"] != "ops" {
+		t.Errorf("Unexpected headers %v", config.Headers)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "https://traces.example.com/ingest")
+	t.Setenv("OTEL_SERVICE_NAME", "periodic-staging")
+	if config, _ := ConfigFromEnv("periodic-api"); config.Endpoint != "https://traces.example.com/ingest" || config.ServiceName != "periodic-staging" {
+		t.Errorf("Expected the traces endpoint and service name overrides, got %+v", config)
+	}
+}
+
+func TestSetupExportsSpans(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var requests []otlpRequest
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("x-api-key")
+		var request otlpRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+	}))
+	defer server.Close()
+
+	shutdown := Setup(Config{Endpoint: server.URL, Headers: map[string]string{"x-api-key": "abc"}, ServiceName: "periodic-test"})
+
+	// Continue a trace started by a caller, as the HTTP middleware does
+	header := http.Header{"Traceparent": {"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	tracer := otel.Tracer("periodic-api/test")
+	ctx, parent := tracer.Start(ctx, "GET", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "SELECT", trace.WithAttributes(attribute.Int("rows", 3), attribute.StringSlice("tables", []string{"users"})))
+	child.RecordError(errors.New("timeout"))
+	child.SetStatus(codes.Error, "timeout")
+	child.End()
+	parent.SetStatus(codes.Ok, "")
+	parent.End()
+
+	// Spans in a trace the caller didn't sample aren't recorded
+	header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-00")
+	_, unsampled := tracer.Start(otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header)), "GET")
+	if unsampled.IsRecording() {
+		t.Error("Expected a span in an unsampled trace not to record")
+	}
+	unsampled.End()
+
+	shutdown()
+
+	if len(requests) != 1 || apiKey != "abc" {
+		t.Fatalf("Expected one export with the configured headers, got %d (key %q)", len(requests), apiKey)
+	}
+	resource := requests[0].ResourceSpans[0]
+	if name := *resource.Resource.Attributes[0].Value.StringValue; name != "periodic-test" {
+		t.Errorf("Expected service name periodic-test, got %s", name)
+	}
+	spans := resource.ScopeSpans[0].Spans
+	if resource.ScopeSpans[0].Scope.Name != "periodic-api/test" || len(spans) != 2 {
+		t.Fatalf("Expected two spans in the test scope, got %+v", resource.ScopeSpans)
+	}
+
+	exportedChild, exportedParent := spans[0], spans[1]
+	if exportedParent.TraceID != "0af7651916cd43dd8448eb211c80319c" || exportedParent.ParentSpanID != "b7ad6b7169203331" || exportedParent.Kind != 2 || exportedParent.Status.Code != 1 {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", exportedParent)
+	}
+	if exportedChild.TraceID != exportedParent.TraceID || exportedChild.ParentSpanID != exportedParent.SpanID {
+		t.Errorf("Expected the child span under the server span, got %+v", exportedChild)
+	}
+	if exportedChild.Status.Code != 2 || exportedChild.Status.Message != "timeout" || len(exportedChild.Events) != 1 || exportedChild.Events[0].Name != "exception" {
+		t.Errorf("Expected the child span to record the error, got %+v", exportedChild)
+	}
+	if *exportedChild.Attributes[0].Value.IntValue != "3" || *exportedChild.Attributes[1].Value.ArrayValue.Values[0].StringValue != "users" {
+		t.Errorf("Unexpected attributes %+v", exportedChild.Attributes)
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Messages API version requested
+const anthropicVersion = "2023-06-01"
+
+// AnthropicLLMClient generates scheduled items with the Anthropic Messages API
+type AnthropicLLMClient struct {
+	client  *http.Client
+	config  LLMConfig
+	prompts systemPrompts
+}
+
+// NewAnthropicLLMClient creates a client for the Messages API at config.BaseURL
+func NewAnthropicLLMClient(config LLMConfig) (*AnthropicLLMClient, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("LLM_API_KEY is required for the %s provider", LLMProviderAnthropic)
+	}
+
+	prompts, err := loadSystemPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnthropicLLMClient{
+		client:  &http.Client{Timeout: llmRequestTimeout},
+		config:  config,
+		prompts: prompts,
+	}, nil
+}
+
+// GenerateScheduledItemJSON sends a generation prompt to the Messages API and returns JSON response
+func (c *AnthropicLLMClient) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	fullPrompt, err := buildGenerationPrompt(c.prompts.generate, userPrompt, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// ModifyScheduledItemJSON sends a modification prompt to the Messages API and returns the merge patch
+func (c *AnthropicLLMClient) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	fullPrompt, err := buildModificationPrompt(c.prompts.modify, itemJSON, instruction, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RefineScheduledItemJSON sends a generation conversation and the user's new message to the Messages API and returns the revised JSON
+func (c *AnthropicLLMClient) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	fullPrompt, err := buildRefinementPrompt(c.prompts.generate, conversation, message, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RepairJSON sends previously generated JSON and its problems to the Messages API and returns the corrected JSON
+func (c *AnthropicLLMClient) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return c.complete(ctx, buildRepairPrompt(output, problems))
+}
+
+// complete sends a prompt to the Messages API and returns the generated text
+func (c *AnthropicLLMClient) complete(ctx context.Context, fullPrompt string) (string, error) {
+	request := struct {
+		Model       string       `json:"model"`
+		Messages    []llmMessage `json:"messages"`
+		MaxTokens   int          `json:"max_tokens"`
+		Temperature float64      `json:"temperature"`
+		TopP        *float64     `json:"top_p,omitempty"`
+	}{
+		Model:       c.config.ModelID,
+		Messages:    []llmMessage{{Role: "user", Content: fullPrompt}},
+		MaxTokens:   c.config.MaxTokens,
+		Temperature: c.config.Temperature,
+		TopP:        c.config.TopP,
+	}
+
+	headers := map[string]string{
+		"x-api-key":         c.config.APIKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	var response struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/v1/messages"
+	if err := postLLMJSON(ctx, c.client, url, headers, request, &response); err != nil {
+		return "", err
+	}
+	recordLLMTokens(ctx, response.Usage.InputTokens, response.Usage.OutputTokens)
+
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("no text in response")
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+
+	"periodic-api/internal/logging"
+	"periodic-api/internal/tracing"
 )
 
 // AWSConfig represents the AWS configuration loaded from file
@@ -24,19 +26,20 @@ type AWSConfig struct {
 
 // AWSLLMClient handles interactions with AWS Bedrock LLM
 type AWSLLMClient struct {
-	client       *bedrockruntime.Client
-	systemPrompt string
+	client  *bedrockruntime.Client
+	config  LLMConfig
+	prompts systemPrompts
 }
 
-// NewAWSLLMClient creates a new AWS LLM client
-func NewAWSLLMClient(ctx context.Context) (*AWSLLMClient, error) {
+// NewAWSLLMClient creates a new AWS LLM client using the given model configuration
+func NewAWSLLMClient(ctx context.Context, llmConfig LLMConfig) (*AWSLLMClient, error) {
 	// Load AWS configuration from file
 	awsConfig, err := loadAWSConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	log.Printf("AWS Config loaded - Region: %s, AccessKeyID: %s", awsConfig.Region, awsConfig.AccessKeyID[:8]+"...")
+	logging.Debugf("AWS Config loaded - Region: %s, AccessKeyID: %s", awsConfig.Region, awsConfig.AccessKeyID[:8]+"...")
 
 	// Create AWS config with custom credentials
 	cfg, err := config.LoadDefaultConfig(ctx,
@@ -46,21 +49,26 @@ func NewAWSLLMClient(ctx context.Context) (*AWSLLMClient, error) {
 			awsConfig.SecretAccessKey,
 			awsConfig.SessionToken,
 		)),
+		// Throttling is retried by ResilientLLMProvider, within its per-call timeout
+		config.WithRetryMaxAttempts(1),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Load system prompt from file
-	systemPrompt, err := loadSystemPrompt()
+	prompts, err := loadSystemPrompts()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load system prompt: %w", err)
+		return nil, err
 	}
 
-	client := bedrockruntime.NewFromConfig(cfg)
+	client := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+		o.TracerProvider = tracing.AWS()
+	})
 	return &AWSLLMClient{
-		client:       client,
-		systemPrompt: systemPrompt,
+		client:  client,
+		config:  llmConfig,
+		prompts: prompts,
 	}, nil
 }
 
@@ -90,111 +98,81 @@ func loadAWSConfig() (*AWSConfig, error) {
 	return &awsConfig, nil
 }
 
-// loadSystemPrompt reads the system prompt from the external file
-func loadSystemPrompt() (string, error) {
-	content, err := os.ReadFile("new_scheduled_item_system_prompt.txt")
+// GenerateScheduledItemJSON sends a generation prompt to Bedrock and returns JSON response
+func (c *AWSLLMClient) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	fullPrompt, err := buildGenerationPrompt(c.prompts.generate, userPrompt, userTimezone)
 	if err != nil {
-		return "", fmt.Errorf("failed to read system prompt file: %w", err)
+		return "", err
 	}
-	return string(content), nil
+	return c.complete(ctx, fullPrompt)
 }
 
-// GenerateScheduledItemJSON sends a prompt to AWS LLM and returns JSON response
-func (c *AWSLLMClient) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
-	// Validate and load the user's timezone
-	location, err := time.LoadLocation(userTimezone)
+// ModifyScheduledItemJSON sends a modification prompt to Bedrock and returns the merge patch
+func (c *AWSLLMClient) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	fullPrompt, err := buildModificationPrompt(c.prompts.modify, itemJSON, instruction, userTimezone)
 	if err != nil {
-		return "", fmt.Errorf("invalid timezone '%s': %w", userTimezone, err)
-	}
-
-	// Get current time in user's timezone
-	currentTime := time.Now()
-	currentTimeInUserTZ := currentTime.In(location)
-	formatter := "2006-01-02 15:04:05"
-	currentDateTime := currentTimeInUserTZ.Format(formatter)
-
-	// Build additional context
-	additionalContext := fmt.Sprintf("Additional context:\n User's timezone: %s,\n User's current date and time: %s", userTimezone, currentDateTime)
-
-	// Use the loaded system prompt with additional context
-	fullPrompt := fmt.Sprintf("%s\n\n%s\n\nUser request: %s", c.systemPrompt, additionalContext, userPrompt)
-
-	// Prepare the request body for Nova model
-	requestBody := map[string]interface{}{
-		"messages": []map[string]interface{}{
-			{
-				"role": "user",
-				"content": []map[string]interface{}{
-					{
-						"text": fullPrompt,
-					},
-				},
-			},
-		},
-		"inferenceConfig": map[string]interface{}{
-			"temperature": 0.0,
-			"top_p":       0.0,
-			"top_k":       20,
-		},
+		return "", err
 	}
+	return c.complete(ctx, fullPrompt)
+}
 
-	log.Printf("Request body: %+v", requestBody)
-
-	bodyBytes, err := json.Marshal(requestBody)
+// RefineScheduledItemJSON sends a generation conversation and the user's new message to Bedrock and returns the revised JSON
+func (c *AWSLLMClient) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	fullPrompt, err := buildRefinementPrompt(c.prompts.generate, conversation, message, userTimezone)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request body: %w", err)
+		return "", err
 	}
+	return c.complete(ctx, fullPrompt)
+}
 
-	// Call Bedrock with Nova model using inference profile
-	// The inference profile should match the region from config
-	modelId := fmt.Sprintf("us.amazon.nova-lite-v1:0")
-	log.Printf("Using model ID: %s", modelId)
-
-	input := &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(modelId),
-		ContentType: aws.String("application/json"),
-		Accept:      aws.String("application/json"),
-		Body:        bodyBytes,
-	}
+// RepairJSON sends previously generated JSON and its problems to Bedrock and returns the corrected JSON
+func (c *AWSLLMClient) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return c.complete(ctx, buildRepairPrompt(output, problems))
+}
 
-	result, err := c.client.InvokeModel(ctx, input)
-	if err != nil {
-		return "", fmt.Errorf("failed to invoke model: %w", err)
+// complete sends a prompt to Bedrock and returns the generated text
+func (c *AWSLLMClient) complete(ctx context.Context, fullPrompt string) (string, error) {
+	// Use the Converse API, which takes the same request for every model family, so
+	// switching between Nova and Claude only needs a different model ID
+	inferenceConfig := &types.InferenceConfiguration{
+		MaxTokens:   aws.Int32(int32(c.config.MaxTokens)),
+		Temperature: aws.Float32(float32(c.config.Temperature)),
 	}
-
-	// Parse the response
-	var response map[string]interface{}
-	if err := json.Unmarshal(result.Body, &response); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	if c.config.TopP != nil {
+		inferenceConfig.TopP = aws.Float32(float32(*c.config.TopP))
 	}
 
-	log.Printf("Response: %+v", response)
+	logging.Debugf("Using model ID: %s", c.config.ModelID)
 
-	// Extract the generated text for Nova model response format
-	output, ok := response["output"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("unexpected response format - no output field")
+	input := &bedrockruntime.ConverseInput{
+		ModelId: aws.String(c.config.ModelID),
+		Messages: []types.Message{
+			{
+				Role:    types.ConversationRoleUser,
+				Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: fullPrompt}},
+			},
+		},
+		InferenceConfig: inferenceConfig,
 	}
 
-	message, ok := output["message"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("unexpected response format - no message field")
+	result, err := c.client.Converse(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to invoke model: %w", err)
 	}
-
-	content, ok := message["content"].([]interface{})
-	if !ok || len(content) == 0 {
-		return "", fmt.Errorf("unexpected response format - no content array")
+	if result.Usage != nil {
+		recordLLMTokens(ctx, int(aws.ToInt32(result.Usage.InputTokens)), int(aws.ToInt32(result.Usage.OutputTokens)))
 	}
 
-	textContent, ok := content[0].(map[string]interface{})
+	// Extract the generated text from the response message
+	output, ok := result.Output.(*types.ConverseOutputMemberMessage)
 	if !ok {
-		return "", fmt.Errorf("unexpected content format")
+		return "", fmt.Errorf("unexpected response format - no message output")
 	}
 
-	generatedText, ok := textContent["text"].(string)
-	if !ok {
-		return "", fmt.Errorf("no text in response")
+	for _, block := range output.Value.Content {
+		if text, ok := block.(*types.ContentBlockMemberText); ok {
+			return text.Value, nil
+		}
 	}
-
-	return generatedText, nil
+	return "", fmt.Errorf("no text in response")
 }
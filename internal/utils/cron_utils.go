@@ -1,21 +1,31 @@
 package utils
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
-// CalculateNextExecution calculates the next execution time for a scheduled item
+// CalculateNextExecution calculates the next execution time for a scheduled item, delayed by a
+// random amount of up to jitterSeconds so items sharing a schedule do not all fire at once.
 // Returns nil if the item should not execute again (expired or one-time item in the past)
-func CalculateNextExecution(startsAt time.Time, repeats bool, cronExpression *string, expiration *time.Time) *time.Time {
-	now := time.Now()
+func CalculateNextExecution(startsAt time.Time, repeats bool, cronExpression *string, expiration *time.Time, jitterSeconds int) *time.Time {
+	return CalculateNextExecutionAt(time.Now(), startsAt, repeats, cronExpression, expiration, jitterSeconds)
+}
 
+// CalculateNextExecutionAt calculates the next execution time after now like
+// CalculateNextExecution, for working out what happens at another time than the present
+func CalculateNextExecutionAt(now time.Time, startsAt time.Time, repeats bool, cronExpression *string, expiration *time.Time, jitterSeconds int) *time.Time {
 	// For non-repeating items
 	if !repeats {
 		// If starts in the future, return startsAt
 		if startsAt.After(now) {
-			return &startsAt
+			nextTime := applyJitter(startsAt, jitterSeconds, expiration)
+			return &nextTime
 		}
 		// If starts in the past, no next execution
 		return nil
@@ -51,12 +61,190 @@ func CalculateNextExecution(startsAt time.Time, repeats bool, cronExpression *st
 		}
 	}
 
+	nextTime = applyJitter(nextTime, jitterSeconds, expiration)
 	return &nextTime
 }
 
+// Occurrences lists up to limit times a scheduled item is due in [from, until), ignoring
+// jitter, for previewing its schedule
+func Occurrences(startsAt time.Time, repeats bool, cronExpression *string, expiration *time.Time, from time.Time, until time.Time, limit int) []time.Time {
+	due := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(until) && (expiration == nil || !t.After(*expiration))
+	}
+
+	if !repeats {
+		if due(startsAt) && limit > 0 {
+			return []time.Time{startsAt}
+		}
+		return nil
+	}
+
+	if cronExpression == nil || *cronExpression == "" {
+		return nil
+	}
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(*cronExpression)
+	if err != nil {
+		return nil
+	}
+
+	// Occurrences never come before the start time
+	after := from.Add(-time.Second)
+	if startsAt.After(from) {
+		after = startsAt.Add(-time.Second)
+	}
+	var occurrences []time.Time
+	for next := schedule.Next(after); len(occurrences) < limit && due(next); next = schedule.Next(next) {
+		occurrences = append(occurrences, next)
+	}
+	return occurrences
+}
+
+// applyJitter delays t by a random duration within the jitter window. The delay is dropped
+// if it would push the execution past the expiration.
+func applyJitter(t time.Time, jitterSeconds int, expiration *time.Time) time.Time {
+	if jitterSeconds <= 0 {
+		return t
+	}
+
+	jittered := t.Add(rand.N(time.Duration(jitterSeconds) * time.Second))
+	if expiration != nil && !jittered.Before(*expiration) {
+		return t
+	}
+	return jittered
+}
+
 // ValidateCronExpression validates if a cron expression is valid
 func ValidateCronExpression(cronExpression string) error {
 	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
 	_, err := parser.Parse(cronExpression)
 	return err
 }
+
+// Names of the months and days of the week, as cron fields number them
+var (
+	cronMonthNames   = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	cronWeekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// DescribeCronExpression describes a valid cron expression in English, such as "At 09:00
+// on every day-of-week from Monday through Friday." for "0 9 * * 1-5"
+func DescribeCronExpression(cronExpression string) (string, error) {
+	if err := ValidateCronExpression(cronExpression); err != nil {
+		return "", err
+	}
+	fields := strings.Fields(cronExpression)
+	minute, hour, dayOfMonth, month, dayOfWeek := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	var description strings.Builder
+	m, minuteErr := strconv.Atoi(minute)
+	h, hourErr := strconv.Atoi(hour)
+	if minuteErr == nil && hourErr == nil {
+		fmt.Fprintf(&description, "At %02d:%02d", h, m)
+	} else {
+		description.WriteString("At " + describeCronField(minute, "minute", nil, 0))
+		if hour != "*" {
+			description.WriteString(" past " + describeCronField(hour, "hour", nil, 0))
+		}
+	}
+
+	restrictsDayOfMonth := dayOfMonth != "*" && dayOfMonth != "?"
+	if restrictsDayOfMonth {
+		description.WriteString(" on " + describeCronField(dayOfMonth, "day-of-month", nil, 1))
+	}
+	if dayOfWeek != "*" && dayOfWeek != "?" {
+		// Items are due on the days matching either field when both are restricted
+		if restrictsDayOfMonth {
+			description.WriteString(" or")
+		}
+		description.WriteString(" on " + describeCronField(dayOfWeek, "day-of-week", cronWeekdayNames, 0))
+	}
+	if month != "*" {
+		description.WriteString(" in " + describeCronField(month, "month", cronMonthNames, 1))
+	}
+	description.WriteString(".")
+	return description.String(), nil
+}
+
+// describeCronField describes a field of a valid cron expression counting unit, whose
+// values are named by names, starting at first, when names isn't nil
+func describeCronField(field string, unit string, names []string, first int) string {
+	value := func(v string) string {
+		if names == nil {
+			return v
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			return names[n-first]
+		}
+		for _, name := range names {
+			if strings.EqualFold(name[:3], v) {
+				return name
+			}
+		}
+		return v
+	}
+
+	parts := strings.Split(field, ",")
+	phrases := make([]string, len(parts))
+	singles := true
+	for i, part := range parts {
+		rng, step, stepped := strings.Cut(part, "/")
+		every := "every " + unit
+		if stepped {
+			n, _ := strconv.Atoi(step)
+			every = "every " + ordinal(n) + " " + unit
+		}
+		switch from, to, isRange := strings.Cut(rng, "-"); {
+		case rng == "*" || rng == "?":
+			phrases[i] = every
+		case isRange:
+			phrases[i] = fmt.Sprintf("%s from %s through %s", every, value(from), value(to))
+		case stepped:
+			phrases[i] = fmt.Sprintf("%s from %s", every, value(rng))
+		default:
+			phrases[i] = value(rng)
+			continue
+		}
+		singles = false
+	}
+
+	listed := joinWithAnd(phrases)
+	if singles && names == nil {
+		return unit + " " + listed
+	}
+	if !singles && names == nil {
+		// Single values among ranges still need their unit
+		for i, part := range parts {
+			if !strings.ContainsAny(part, "*?-/") {
+				phrases[i] = unit + " " + phrases[i]
+			}
+		}
+		listed = joinWithAnd(phrases)
+	}
+	return listed
+}
+
+// joinWithAnd joins phrases as "a, b and c"
+func joinWithAnd(phrases []string) string {
+	if len(phrases) == 1 {
+		return phrases[0]
+	}
+	return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+}
+
+// ordinal formats n as 1st, 2nd, 3rd, 4th and so on
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if n%100 >= 11 && n%100 <= 13 {
+		suffix = "th"
+	}
+	return strconv.Itoa(n) + suffix
+}
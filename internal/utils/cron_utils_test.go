@@ -70,7 +70,7 @@ func TestCalculateNextExecution(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CalculateNextExecution(tt.startsAt, tt.repeats, tt.cronExpression, tt.expiration)
+			result := CalculateNextExecution(tt.startsAt, tt.repeats, tt.cronExpression, tt.expiration, 0)
 
 			if tt.expectNil {
 				if result != nil {
@@ -94,6 +94,30 @@ func TestCalculateNextExecution(t *testing.T) {
 	}
 }
 
+func TestCalculateNextExecutionJitter(t *testing.T) {
+	startsAt := time.Now().Add(time.Hour)
+	jitterSeconds := 300
+
+	for i := 0; i < 20; i++ {
+		result := CalculateNextExecution(startsAt, false, nil, nil, jitterSeconds)
+		if result == nil {
+			t.Fatal("Expected non-nil result for future item with jitter")
+		}
+		if result.Before(startsAt) || !result.Before(startsAt.Add(time.Duration(jitterSeconds)*time.Second)) {
+			t.Errorf("Expected %v to be within the jitter window after %v", *result, startsAt)
+		}
+	}
+
+	// Jitter must never push an execution past the expiration
+	expiration := startsAt.Add(time.Second)
+	for i := 0; i < 20; i++ {
+		result := CalculateNextExecution(startsAt, false, nil, &expiration, jitterSeconds)
+		if result == nil || !result.Before(expiration) {
+			t.Errorf("Expected execution before expiration %v, got %v", expiration, result)
+		}
+	}
+}
+
 func TestValidateCronExpression(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -130,3 +154,79 @@ func TestValidateCronExpression(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestOccurrences(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(7 * 24 * time.Hour)
+	weekdays := "0 9 * * 1-5"
+	expiration := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		startsAt       time.Time
+		repeats        bool
+		cronExpression *string
+		expiration     *time.Time
+		limit          int
+		expected       int
+	}{
+		{name: "Weekday mornings", startsAt: from.Add(-time.Hour), repeats: true, cronExpression: &weekdays, limit: 10, expected: 5},
+		{name: "Starting mid-week", startsAt: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), repeats: true, cronExpression: &weekdays, limit: 10, expected: 2},
+		{name: "Until expiration", startsAt: from, repeats: true, cronExpression: &weekdays, expiration: &expiration, limit: 10, expected: 3},
+		{name: "Limited", startsAt: from, repeats: true, cronExpression: &weekdays, limit: 2, expected: 2},
+		{name: "One-time within the window", startsAt: from.Add(time.Hour), limit: 10, expected: 1},
+		{name: "One-time after the window", startsAt: until, limit: 10, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences := Occurrences(tt.startsAt, tt.repeats, tt.cronExpression, tt.expiration, from, until, tt.limit)
+			if len(occurrences) != tt.expected {
+				t.Fatalf("Expected %d occurrences, got %v", tt.expected, occurrences)
+			}
+			for i, occurrence := range occurrences {
+				if occurrence.Before(from) || !occurrence.Before(until) || occurrence.Before(tt.startsAt) {
+					t.Errorf("Occurrence %v is outside the window", occurrence)
+				}
+				if i > 0 && !occurrence.After(occurrences[i-1]) {
+					t.Errorf("Occurrences are not in order: %v", occurrences)
+				}
+			}
+		})
+	}
+}
+
+func TestDescribeCronExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"0 9 * * 1-5", "At 09:00 on every day-of-week from Monday through Friday."},
+		{"* * * * *", "At every minute."},
+		{"*/15 * * * *", "At every 15th minute."},
+		{"30 * * * *", "At minute 30."},
+		{"0 0 1,15 * *", "At 00:00 on day-of-month 1 and 15."},
+		{"5 4 * * SUN", "At 04:05 on Sunday."},
+		{"23 0-20/2 * * *", "At minute 23 past every 2nd hour from 0 through 20."},
+		{"0 0,12 1 */2 *", "At minute 0 past hour 0 and 12 on day-of-month 1 in every 2nd month."},
+		{"0 8 1 * MON", "At 08:00 on day-of-month 1 or on Monday."},
+		{"0 9 * JAN-MAR *", "At 09:00 in every month from January through March."},
+		{"0 9,17 * * 1,3-5", "At minute 0 past hour 9 and 17 on Monday and every day-of-week from Wednesday through Friday."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			description, err := DescribeCronExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("DescribeCronExpression failed: %v", err)
+			}
+			if description != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, description)
+			}
+		})
+	}
+
+	if _, err := DescribeCronExpression("invalid"); err == nil {
+		t.Error("Expected an invalid expression to be rejected")
+	}
+}
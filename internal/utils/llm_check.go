@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// CheckLLMCredentials makes a request that needs no tokens to confirm the provider is
+// reachable and accepts the configured credentials: listing the models of the HTTP
+// providers, or asking STS who the AWS credentials used for Bedrock belong to
+func CheckLLMCredentials(ctx context.Context, llmConfig LLMConfig) error {
+	baseURL := strings.TrimSuffix(llmConfig.BaseURL, "/")
+	client := &http.Client{Timeout: llmConfig.Timeout}
+	switch llmConfig.Provider {
+	case LLMProviderOpenAI:
+		headers := map[string]string{}
+		if llmConfig.APIKey != "" {
+			headers["Authorization"] = "Bearer " + llmConfig.APIKey
+		}
+		return getLLM(ctx, client, baseURL+"/models", headers)
+	case LLMProviderOllama:
+		return getLLM(ctx, client, baseURL+"/api/tags", nil)
+	case LLMProviderAnthropic:
+		return getLLM(ctx, client, baseURL+"/v1/models", map[string]string{
+			"x-api-key":         llmConfig.APIKey,
+			"anthropic-version": anthropicVersion,
+		})
+	default:
+		awsConfig, err := loadAWSConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		cfg, err := config.LoadDefaultConfig(ctx,
+			config.WithRegion(awsConfig.Region),
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+				awsConfig.AccessKeyID,
+				awsConfig.SecretAccessKey,
+				awsConfig.SessionToken,
+			)),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		if _, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+			return fmt.Errorf("AWS credentials were rejected: %w", err)
+		}
+		return nil
+	}
+}
+
+// getLLM sends a GET request to an HTTP-based provider, returning an LLMStatusError for
+// an error status
+func getLLM(ctx context.Context, client *http.Client, url string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach model provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &LLMStatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckLLMCredentials(t *testing.T) {
+	tests := []struct {
+		provider string
+		path     string
+		header   string
+		value    string
+	}{
+		{LLMProviderOpenAI, "/models", "Authorization", "Bearer key"},
+		{LLMProviderAnthropic, "/v1/models", "X-Api-Key", "key"},
+		{LLMProviderOllama, "/api/tags", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var requests []map[string]any
+			var headers http.Header
+			server := newFakeLLMServer(t, tt.path, `{}`, &requests, &headers)
+			config := LLMConfig{Provider: tt.provider, BaseURL: server.URL, APIKey: "key", Timeout: time.Second}
+
+			if err := CheckLLMCredentials(context.Background(), config); err != nil {
+				t.Fatalf("CheckLLMCredentials failed: %v", err)
+			}
+			if tt.header != "" && headers.Get(tt.header) != tt.value {
+				t.Errorf("Expected %s %q, got %q", tt.header, tt.value, headers.Get(tt.header))
+			}
+
+			// The fake server answers 404 for any other path, standing in for a rejected key
+			config.BaseURL = server.URL + "/elsewhere"
+			var statusErr *LLMStatusError
+			if err := CheckLLMCredentials(context.Background(), config); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected a status error, got %v", err)
+			}
+		})
+	}
+}
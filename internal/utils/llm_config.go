@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLLMModelID is the Bedrock model or inference profile used when LLM_MODEL_ID is not set
+	DefaultLLMModelID = "us.amazon.nova-lite-v1:0"
+	// defaultLLMProvider is used when LLM_PROVIDER is not set
+	defaultLLMProvider = LLMProviderBedrock
+	// defaultLLMMaxTokens bounds the generated response when LLM_MAX_TOKENS is not set
+	defaultLLMMaxTokens = 1024
+	// maxLLMMaxTokens is the largest LLM_MAX_TOKENS accepted
+	maxLLMMaxTokens = 8192
+	// defaultLLMTimeout bounds each call to the model when LLM_TIMEOUT is not set
+	defaultLLMTimeout = 30 * time.Second
+	// defaultLLMMaxRetries is how often throttled calls are retried when LLM_MAX_RETRIES is not set
+	defaultLLMMaxRetries = 2
+	// defaultLLMBreakerThreshold is the number of consecutive failures that open the
+	// circuit breaker when LLM_BREAKER_THRESHOLD is not set
+	defaultLLMBreakerThreshold = 5
+	// defaultLLMBreakerCooldown is how long the breaker stays open when LLM_BREAKER_COOLDOWN is not set
+	defaultLLMBreakerCooldown = 30 * time.Second
+)
+
+// llmProviderDefaults are the base URL and model used by each provider when LLM_BASE_URL
+// and LLM_MODEL_ID are not set
+var llmProviderDefaults = map[string]struct{ baseURL, modelID string }{
+	LLMProviderBedrock:   {"", DefaultLLMModelID},
+	LLMProviderOpenAI:    {"https://api.openai.com/v1", "gpt-4o-mini"},
+	LLMProviderOllama:    {"http://localhost:11434", "llama3.1"},
+	LLMProviderAnthropic: {"https://api.anthropic.com", "claude-3-5-haiku-latest"},
+}
+
+// LLMConfig selects the model used to generate scheduled items and its inference parameters
+type LLMConfig struct {
+	// Provider is bedrock, openai, ollama or anthropic
+	Provider string
+	// BaseURL is the API of the openai, ollama and anthropic providers; Bedrock uses the
+	// region in aws_config.json instead
+	BaseURL string
+	// APIKey authenticates with the openai and anthropic providers
+	APIKey string
+	// ModelID is the provider's model name; for Bedrock a model ID or inference profile,
+	// e.g. us.amazon.nova-pro-v1:0 or us.anthropic.claude-3-5-haiku-20241022-v1:0
+	ModelID string
+	// MaxTokens bounds the length of the generated response
+	MaxTokens int
+	// Temperature controls sampling; it defaults to 0 for repeatable output
+	Temperature float64
+	// TopP is only sent when set, since some models reject it alongside a temperature
+	TopP *float64
+	// Timeout bounds each call to the model, including each retry
+	Timeout time.Duration
+	// MaxRetries is how often a throttled or temporarily unavailable call is retried
+	MaxRetries int
+	// BreakerThreshold is the number of consecutive failures after which calls fail fast
+	// for BreakerCooldown, giving an unhealthy provider time to recover
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// DailyRequestQuota and DailyTokenQuota limit the generation requests and the tokens
+	// each user may use per UTC day; 0 means unlimited
+	DailyRequestQuota int
+	DailyTokenQuota   int
+}
+
+// LLMConfigFromEnv returns the model configuration from the LLM_PROVIDER, LLM_BASE_URL,
+// LLM_API_KEY, LLM_MODEL_ID, LLM_MAX_TOKENS, LLM_TEMPERATURE, LLM_TOP_P, LLM_TIMEOUT,
+// LLM_MAX_RETRIES, LLM_BREAKER_THRESHOLD, LLM_BREAKER_COOLDOWN, LLM_DAILY_REQUEST_QUOTA and
+// LLM_DAILY_TOKEN_QUOTA environment variables. Unlike most settings, invalid values are an
+// error rather than falling back to the default, so a typo fails at startup instead of
+// silently changing the generated items.
+func LLMConfigFromEnv() (LLMConfig, error) {
+	config := LLMConfig{
+		Provider:         defaultLLMProvider,
+		APIKey:           os.Getenv("LLM_API_KEY"),
+		MaxTokens:        defaultLLMMaxTokens,
+		Timeout:          defaultLLMTimeout,
+		MaxRetries:       defaultLLMMaxRetries,
+		BreakerThreshold: defaultLLMBreakerThreshold,
+		BreakerCooldown:  defaultLLMBreakerCooldown,
+	}
+	var errs []error
+
+	if provider := strings.ToLower(os.Getenv("LLM_PROVIDER")); provider != "" {
+		if _, ok := llmProviderDefaults[provider]; ok {
+			config.Provider = provider
+		} else {
+			errs = append(errs, fmt.Errorf("LLM_PROVIDER must be one of bedrock, openai, ollama or anthropic, got %q", provider))
+		}
+	}
+	defaults := llmProviderDefaults[config.Provider]
+	config.BaseURL = defaults.baseURL
+	config.ModelID = defaults.modelID
+
+	if baseURL := os.Getenv("LLM_BASE_URL"); baseURL != "" {
+		if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("LLM_BASE_URL must be an http or https URL, got %q", baseURL))
+		}
+		config.BaseURL = baseURL
+	}
+	if modelID := os.Getenv("LLM_MODEL_ID"); modelID != "" {
+		config.ModelID = modelID
+	}
+	if value := os.Getenv("LLM_MAX_TOKENS"); value != "" {
+		maxTokens, err := strconv.Atoi(value)
+		if err != nil || maxTokens < 1 || maxTokens > maxLLMMaxTokens {
+			errs = append(errs, fmt.Errorf("LLM_MAX_TOKENS must be an integer between 1 and %d, got %q", maxLLMMaxTokens, value))
+		}
+		config.MaxTokens = maxTokens
+	}
+	if value := os.Getenv("LLM_TEMPERATURE"); value != "" {
+		temperature, err := strconv.ParseFloat(value, 64)
+		if err != nil || temperature < 0 || temperature > 1 {
+			errs = append(errs, fmt.Errorf("LLM_TEMPERATURE must be a number between 0 and 1, got %q", value))
+		}
+		config.Temperature = temperature
+	}
+	if value := os.Getenv("LLM_TOP_P"); value != "" {
+		topP, err := strconv.ParseFloat(value, 64)
+		if err != nil || topP < 0 || topP > 1 {
+			errs = append(errs, fmt.Errorf("LLM_TOP_P must be a number between 0 and 1, got %q", value))
+		}
+		config.TopP = &topP
+	}
+	if value := os.Getenv("LLM_MAX_RETRIES"); value != "" {
+		maxRetries, err := strconv.Atoi(value)
+		if err != nil || maxRetries < 0 {
+			errs = append(errs, fmt.Errorf("LLM_MAX_RETRIES must be a non-negative integer, got %q", value))
+		}
+		config.MaxRetries = maxRetries
+	}
+	if value := os.Getenv("LLM_BREAKER_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 1 {
+			errs = append(errs, fmt.Errorf("LLM_BREAKER_THRESHOLD must be a positive integer, got %q", value))
+		}
+		config.BreakerThreshold = threshold
+	}
+	config.DailyRequestQuota = llmQuotaFromEnv("LLM_DAILY_REQUEST_QUOTA", &errs)
+	config.DailyTokenQuota = llmQuotaFromEnv("LLM_DAILY_TOKEN_QUOTA", &errs)
+	config.Timeout = llmDurationFromEnv("LLM_TIMEOUT", config.Timeout, &errs)
+	config.BreakerCooldown = llmDurationFromEnv("LLM_BREAKER_COOLDOWN", config.BreakerCooldown, &errs)
+
+	return config, errors.Join(errs...)
+}
+
+// llmDurationFromEnv reads a positive duration from an environment variable, recording an
+// error if it is invalid
+func llmDurationFromEnv(name string, def time.Duration, errs *[]error) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		*errs = append(*errs, fmt.Errorf("%s must be a positive duration such as 30s, got %q", name, value))
+	}
+	return duration
+}
+
+// llmQuotaFromEnv reads a non-negative quota from an environment variable, recording an
+// error if it is invalid. Unset quotas are 0, meaning unlimited.
+func llmQuotaFromEnv(name string, errs *[]error) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	quota, err := strconv.Atoi(value)
+	if err != nil || quota < 0 {
+		*errs = append(*errs, fmt.Errorf("%s must be a non-negative integer, got %q", name, value))
+	}
+	return quota
+}
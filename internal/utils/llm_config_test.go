@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLLMConfigFromEnv(t *testing.T) {
+	config, err := LLMConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Expected defaults without error, got %v", err)
+	}
+	if config.Provider != LLMProviderBedrock || config.ModelID != DefaultLLMModelID || config.MaxTokens != defaultLLMMaxTokens || config.Temperature != 0 || config.TopP != nil {
+		t.Errorf("Unexpected default config %+v", config)
+	}
+
+	t.Setenv("LLM_MODEL_ID", "us.amazon.nova-pro-v1:0")
+	t.Setenv("LLM_MAX_TOKENS", "2048")
+	t.Setenv("LLM_TEMPERATURE", "0.3")
+	t.Setenv("LLM_TOP_P", "0.9")
+	t.Setenv("LLM_DAILY_TOKEN_QUOTA", "50000")
+	config, err = LLMConfigFromEnv()
+	if err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if config.ModelID != "us.amazon.nova-pro-v1:0" || config.MaxTokens != 2048 || config.Temperature != 0.3 || config.TopP == nil || *config.TopP != 0.9 || config.DailyTokenQuota != 50000 || config.DailyRequestQuota != 0 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	// Other providers default to their own API and model
+	t.Setenv("LLM_MODEL_ID", "")
+	t.Setenv("LLM_PROVIDER", "ollama")
+	config, err = LLMConfigFromEnv()
+	if err != nil || config.BaseURL != "http://localhost:11434" || config.ModelID != "llama3.1" {
+		t.Errorf("Unexpected ollama config %+v (%v)", config, err)
+	}
+
+	t.Setenv("LLM_PROVIDER", "watson")
+	t.Setenv("LLM_BASE_URL", "localhost:11434")
+	t.Setenv("LLM_MAX_TOKENS", "0")
+	t.Setenv("LLM_TEMPERATURE", "warm")
+	t.Setenv("LLM_TOP_P", "1.5")
+	t.Setenv("LLM_DAILY_REQUEST_QUOTA", "-1")
+	_, err = LLMConfigFromEnv()
+	if err == nil {
+		t.Fatal("Expected invalid values to be rejected")
+	}
+	for _, name := range []string{"LLM_PROVIDER", "LLM_BASE_URL", "LLM_MAX_TOKENS", "LLM_TEMPERATURE", "LLM_TOP_P", "LLM_DAILY_REQUEST_QUOTA"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected the error to mention %s, got %v", name, err)
+		}
+	}
+}
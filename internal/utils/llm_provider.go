@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// LLM providers selected by LLM_PROVIDER
+const (
+	LLMProviderBedrock   = "bedrock"
+	LLMProviderOpenAI    = "openai"
+	LLMProviderOllama    = "ollama"
+	LLMProviderAnthropic = "anthropic"
+)
+
+// llmRequestTimeout bounds each request to an HTTP-based provider
+const llmRequestTimeout = 2 * time.Minute
+
+// LLMProvider generates scheduled items from natural language prompts
+type LLMProvider interface {
+	// GenerateScheduledItemJSON returns the JSON of a scheduled item described by the
+	// prompt, interpreting dates and times in the user's timezone
+	GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error)
+	// ModifyScheduledItemJSON returns a JSON Merge Patch of the fields of itemJSON that
+	// change to carry out the instruction, such as "move it to Fridays at 6pm"
+	ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error)
+	// RefineScheduledItemJSON returns the JSON of the last item generated in conversation,
+	// revised as the message asks, such as "actually make it biweekly"
+	RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error)
+	// RepairJSON asks the model to correct JSON it generated that failed validation, given
+	// a description of each problem found
+	RepairJSON(ctx context.Context, output string, problems []string) (string, error)
+}
+
+// Roles of the turns in a generation conversation
+const (
+	LLMRoleUser      = "user"
+	LLMRoleAssistant = "assistant"
+)
+
+// LLMTurn is a message in a generation conversation: a user's request, or the item JSON
+// the model generated in reply
+type LLMTurn struct {
+	Role    string
+	Content string
+}
+
+// llmMessage is a chat message, in the form shared by the OpenAI, Ollama and Anthropic APIs
+type llmMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// NewLLMProvider creates the provider selected by the configuration, wrapped with its
+// timeout, retry and circuit breaker settings
+func NewLLMProvider(ctx context.Context, config LLMConfig) (LLMProvider, error) {
+	var provider LLMProvider
+	var err error
+	switch config.Provider {
+	case LLMProviderOpenAI:
+		provider, err = NewOpenAILLMClient(config)
+	case LLMProviderOllama:
+		provider, err = NewOllamaLLMClient(config)
+	case LLMProviderAnthropic:
+		provider, err = NewAnthropicLLMClient(config)
+	default:
+		provider, err = NewAWSLLMClient(ctx, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewResilientLLMProvider(provider, config), nil
+}
+
+// Files holding the system prompts, relative to the working directory
+const (
+	generationPromptFile   = "new_scheduled_item_system_prompt.txt"
+	modificationPromptFile = "modify_scheduled_item_system_prompt.txt"
+)
+
+// systemPrompts are the instructions sent ahead of the user's request
+type systemPrompts struct {
+	// generate describes how to create a scheduled item from a request
+	generate string
+	// modify describes how to change an existing scheduled item as instructed
+	modify string
+}
+
+// loadSystemPrompts reads the system prompts from their external files
+func loadSystemPrompts() (systemPrompts, error) {
+	generate, err := os.ReadFile(generationPromptFile)
+	if err != nil {
+		return systemPrompts{}, fmt.Errorf("failed to read system prompt file: %w", err)
+	}
+	modify, err := os.ReadFile(modificationPromptFile)
+	if err != nil {
+		return systemPrompts{}, fmt.Errorf("failed to read system prompt file: %w", err)
+	}
+	return systemPrompts{generate: string(generate), modify: string(modify)}, nil
+}
+
+// buildGenerationPrompt combines the system prompt, the user's current time and the
+// user's request into the prompt sent to every provider
+func buildGenerationPrompt(systemPrompt string, userPrompt string, userTimezone string) (string, error) {
+	additionalContext, err := userContext(userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n\n%s\n\nUser request: %s", systemPrompt, additionalContext, userPrompt), nil
+}
+
+// buildModificationPrompt combines the system prompt, the user's current time, the item
+// being modified and the user's instruction into the prompt sent to every provider
+func buildModificationPrompt(systemPrompt string, itemJSON string, instruction string, userTimezone string) (string, error) {
+	additionalContext, err := userContext(userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n\n%s\n\nCurrent item: %s\n\nUser instruction: %s", systemPrompt, additionalContext, itemJSON, instruction), nil
+}
+
+// buildRefinementPrompt combines the generation system prompt, the user's current time,
+// the conversation so far and the user's new message into the prompt sent to every provider
+func buildRefinementPrompt(systemPrompt string, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	additionalContext, err := userContext(userTimezone)
+	if err != nil {
+		return "", err
+	}
+
+	var transcript strings.Builder
+	for _, turn := range conversation {
+		speaker := "User"
+		if turn.Role == LLMRoleAssistant {
+			speaker = "Assistant"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, turn.Content)
+	}
+
+	return fmt.Sprintf("%s\n\n%s\n\nConversation so far:\n%s\n"+
+		"Revise the last item the assistant generated as the user now asks, keeping everything "+
+		"the user didn't ask to change, and respond with the complete revised item.\n\nUser request: %s",
+		systemPrompt, additionalContext, transcript.String(), message), nil
+}
+
+// buildRepairPrompt asks for the problems found in previously generated JSON to be fixed
+func buildRepairPrompt(output string, problems []string) string {
+	return fmt.Sprintf("The following JSON you generated is invalid:\n\n%s\n\nProblems found:\n- %s\n\n"+
+		"Fix this JSON so it has none of these problems, changing as little as possible. "+
+		"Respond with only the corrected JSON object, without code fences or any other text.",
+		output, strings.Join(problems, "\n- "))
+}
+
+// ExtractJSON returns the JSON object in a model's output, dropping any code fences
+// and prose the model added around it. Output without an object is returned trimmed,
+// for validation to reject.
+func ExtractJSON(output string) string {
+	text := strings.TrimSpace(output)
+	if _, fenced, ok := strings.Cut(text, "```"); ok {
+		// Skip the fence's language tag, such as ```json
+		if _, body, ok := strings.Cut(fenced, "\n"); ok {
+			body, _, _ = strings.Cut(body, "```")
+			text = strings.TrimSpace(body)
+		}
+	}
+
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return text
+	}
+	return text[start : end+1]
+}
+
+// userContext describes the user's timezone and current time so relative dates resolve correctly
+func userContext(userTimezone string) (string, error) {
+	// Validate and load the user's timezone
+	location, err := time.LoadLocation(userTimezone)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone '%s': %w", userTimezone, err)
+	}
+
+	// Get current time in user's timezone
+	currentDateTime := time.Now().In(location).Format("2006-01-02 15:04:05")
+
+	return fmt.Sprintf("Additional context:\n User's timezone: %s,\n User's current date and time: %s", userTimezone, currentDateTime), nil
+}
+
+// postLLMJSON POSTs a JSON request to an HTTP-based provider and decodes its JSON response
+func postLLMJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, request any, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	// Let providers behind a traced gateway continue the trace
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to invoke model: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &LLMStatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(respBody))}
+	}
+
+	if err := json.Unmarshal(respBody, response); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
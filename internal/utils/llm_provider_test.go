@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeLLMServer serves response as JSON for requests to path, recording each request body
+func newFakeLLMServer(t *testing.T, path string, response string, requests *[]map[string]any, headers *http.Header) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		*requests = append(*requests, body)
+		*headers = r.Header.Clone()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHTTPLLMProviders(t *testing.T) {
+	topP := 0.5
+	item := `{"title":"Standup"}`
+
+	tests := []struct {
+		name     string
+		path     string
+		response string
+		provider func(config LLMConfig) LLMProvider
+		check    func(t *testing.T, request map[string]any, headers http.Header)
+	}{
+		{
+			name:     "openai",
+			path:     "/v1/chat/completions",
+			response: `{"choices":[{"message":{"role":"assistant","content":` + jsonString(item) + `}}],"usage":{"prompt_tokens":30,"completion_tokens":12}}`,
+			provider: func(config LLMConfig) LLMProvider {
+				return &OpenAILLMClient{client: http.DefaultClient, config: config, prompts: systemPrompts{generate: "System", modify: "Modify"}}
+			},
+			check: func(t *testing.T, request map[string]any, headers http.Header) {
+				if headers.Get("Authorization") != "Bearer secret" || request["max_tokens"] != 256.0 || request["top_p"] != 0.5 {
+					t.Errorf("Unexpected request %v with headers %v", request, headers)
+				}
+			},
+		},
+		{
+			name:     "ollama",
+			path:     "/api/chat",
+			response: `{"message":{"role":"assistant","content":` + jsonString(item) + `},"done":true,"prompt_eval_count":30,"eval_count":12}`,
+			provider: func(config LLMConfig) LLMProvider {
+				return &OllamaLLMClient{client: http.DefaultClient, config: config, prompts: systemPrompts{generate: "System", modify: "Modify"}}
+			},
+			check: func(t *testing.T, request map[string]any, headers http.Header) {
+				options, _ := request["options"].(map[string]any)
+				if request["stream"] != false || request["format"] != "json" || options["num_predict"] != 256.0 {
+					t.Errorf("Unexpected request %v", request)
+				}
+			},
+		},
+		{
+			name:     "anthropic",
+			path:     "/v1/messages",
+			response: `{"content":[{"type":"text","text":` + jsonString(item) + `}],"usage":{"input_tokens":30,"output_tokens":12}}`,
+			provider: func(config LLMConfig) LLMProvider {
+				return &AnthropicLLMClient{client: http.DefaultClient, config: config, prompts: systemPrompts{generate: "System", modify: "Modify"}}
+			},
+			check: func(t *testing.T, request map[string]any, headers http.Header) {
+				if headers.Get("x-api-key") != "secret" || headers.Get("anthropic-version") != anthropicVersion || request["max_tokens"] != 256.0 {
+					t.Errorf("Unexpected request %v with headers %v", request, headers)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []map[string]any
+			var headers http.Header
+			server := newFakeLLMServer(t, tt.path, tt.response, &requests, &headers)
+
+			baseURL := server.URL
+			if tt.name == "openai" {
+				baseURL += "/v1"
+			}
+			provider := tt.provider(LLMConfig{
+				Provider:  tt.name,
+				BaseURL:   baseURL,
+				APIKey:    "secret",
+				ModelID:   "test-model",
+				MaxTokens: 256,
+				TopP:      &topP,
+			})
+
+			ctx, usage := WithLLMUsage(context.Background())
+			generated, err := provider.GenerateScheduledItemJSON(ctx, "Daily standup at 9", "Europe/Berlin")
+			if err != nil {
+				t.Fatalf("GenerateScheduledItemJSON failed: %v", err)
+			}
+			if generated != item {
+				t.Errorf("Expected %s, got %s", item, generated)
+			}
+			if input, output := usage.Tokens(); input != 30 || output != 12 {
+				t.Errorf("Expected 30 input and 12 output tokens, got %d and %d", input, output)
+			}
+
+			if len(requests) != 1 {
+				t.Fatalf("Expected 1 request, got %d", len(requests))
+			}
+			request := requests[0]
+			messages, _ := request["messages"].([]any)
+			message, _ := messages[0].(map[string]any)
+			content, _ := message["content"].(string)
+			if request["model"] != "test-model" || !strings.Contains(content, "Europe/Berlin") || !strings.Contains(content, "Daily standup at 9") {
+				t.Errorf("Unexpected request %v", request)
+			}
+			tt.check(t, request, headers)
+		})
+	}
+}
+
+func TestHTTPLLMProviderReportsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &OllamaLLMClient{client: http.DefaultClient, config: LLMConfig{BaseURL: server.URL, ModelID: "missing"}}
+	_, err := client.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC")
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("Expected the error status and body, got %v", err)
+	}
+}
+
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"bare object", `{"title":"Standup"}`, `{"title":"Standup"}`},
+		{"code fence", "```json\n{\"title\":\"Standup\"}\n```", `{"title":"Standup"}`},
+		{"surrounding prose", "Here is the item:\n{\"title\":\"Standup\"}\nLet me know if you need changes.", `{"title":"Standup"}`},
+		{"prose and fence", "Sure!\n```\n{\"title\":\"Standup\"}\n```\nDone.", `{"title":"Standup"}`},
+		{"no object", "  I can't schedule that.  ", "I can't schedule that."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractJSON(tt.output); got != tt.want {
+				t.Errorf("ExtractJSON(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRefinementPrompt(t *testing.T) {
+	conversation := []LLMTurn{
+		{Role: LLMRoleUser, Content: "Team sync every Monday at 9"},
+		{Role: LLMRoleAssistant, Content: `{"title":"Team sync","cronExpression":"0 9 * * 1"}`},
+	}
+	prompt, err := buildRefinementPrompt("System", conversation, "actually make it biweekly", "Europe/Berlin")
+	if err != nil {
+		t.Fatalf("buildRefinementPrompt failed: %v", err)
+	}
+	for _, want := range []string{
+		"System",
+		"Europe/Berlin",
+		"User: Team sync every Monday at 9\nAssistant: {\"title\":\"Team sync\"",
+		"User request: actually make it biweekly",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected the prompt to contain %q, got %s", want, prompt)
+		}
+	}
+
+	if _, err := buildRefinementPrompt("System", conversation, "biweekly", "Mars/Olympus_Mons"); err == nil {
+		t.Error("Expected an invalid timezone to be rejected")
+	}
+}
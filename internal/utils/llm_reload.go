@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// ReloadableLLMProvider forwards calls to a provider that can be replaced while requests
+// are being served, so a reloaded configuration can switch the model without a restart
+type ReloadableLLMProvider struct {
+	mu       sync.RWMutex
+	provider LLMProvider
+}
+
+// NewReloadableLLMProvider wraps provider so it can be replaced with Swap
+func NewReloadableLLMProvider(provider LLMProvider) *ReloadableLLMProvider {
+	return &ReloadableLLMProvider{
+		provider: provider,
+	}
+}
+
+// Swap replaces the provider; calls already in progress finish with the old one
+func (p *ReloadableLLMProvider) Swap(provider LLMProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.provider = provider
+}
+
+// current returns the provider new calls go to
+func (p *ReloadableLLMProvider) current() LLMProvider {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.provider
+}
+
+// GenerateScheduledItemJSON calls the current provider
+func (p *ReloadableLLMProvider) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	return p.current().GenerateScheduledItemJSON(ctx, userPrompt, userTimezone)
+}
+
+// ModifyScheduledItemJSON calls the current provider
+func (p *ReloadableLLMProvider) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	return p.current().ModifyScheduledItemJSON(ctx, itemJSON, instruction, userTimezone)
+}
+
+// RefineScheduledItemJSON calls the current provider
+func (p *ReloadableLLMProvider) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	return p.current().RefineScheduledItemJSON(ctx, conversation, message, userTimezone)
+}
+
+// RepairJSON calls the current provider
+func (p *ReloadableLLMProvider) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return p.current().RepairJSON(ctx, output, problems)
+}
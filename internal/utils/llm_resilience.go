@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// llmRetryBaseDelay is the upper bound of the first retry's random delay; it doubles
+	// for every further retry
+	llmRetryBaseDelay = 500 * time.Millisecond
+	// llmRetryMaxDelay caps the delay between retries
+	llmRetryMaxDelay = 10 * time.Second
+)
+
+// ErrLLMUnavailable is returned without calling the provider while the circuit breaker is
+// open after repeated failures
+var ErrLLMUnavailable = errors.New("LLM provider is unavailable")
+
+// llmTracer records a span for each call to the model, including its retries
+var llmTracer = otel.Tracer("periodic-api/internal/utils")
+
+// LLMStatusError is returned when an HTTP-based provider responds with an error status
+type LLMStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *LLMStatusError) Error() string {
+	return fmt.Sprintf("model returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// isRetryableLLMError reports whether an error is a throttling or temporary availability
+// error worth retrying
+func isRetryableLLMError(err error) bool {
+	var throttling *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	var notReady *types.ModelNotReadyException
+	var modelTimeout *types.ModelTimeoutException
+	if errors.As(err, &throttling) || errors.As(err, &unavailable) || errors.As(err, &notReady) || errors.As(err, &modelTimeout) {
+		return true
+	}
+
+	var statusErr *LLMStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+	}
+	return false
+}
+
+// ResilientLLMProvider wraps a provider with a timeout per call, retries with jitter for
+// throttling, and a circuit breaker that fails fast while the provider keeps failing
+type ResilientLLMProvider struct {
+	provider LLMProvider
+	config   LLMConfig
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+	// probing is set while a single call tests whether an open breaker can close
+	probing bool
+
+	// sleep waits between retries; tests replace it to avoid real delays
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilientLLMProvider wraps provider using the timeout, retry and breaker settings of config
+func NewResilientLLMProvider(provider LLMProvider, config LLMConfig) *ResilientLLMProvider {
+	return &ResilientLLMProvider{
+		provider: provider,
+		config:   config,
+		sleep:    sleepContext,
+	}
+}
+
+// GenerateScheduledItemJSON calls the wrapped provider's GenerateScheduledItemJSON
+func (p *ResilientLLMProvider) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	return p.call(ctx, "generate", func(ctx context.Context) (string, error) {
+		return p.provider.GenerateScheduledItemJSON(ctx, userPrompt, userTimezone)
+	})
+}
+
+// ModifyScheduledItemJSON calls the wrapped provider's ModifyScheduledItemJSON
+func (p *ResilientLLMProvider) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	return p.call(ctx, "modify", func(ctx context.Context) (string, error) {
+		return p.provider.ModifyScheduledItemJSON(ctx, itemJSON, instruction, userTimezone)
+	})
+}
+
+// RefineScheduledItemJSON calls the wrapped provider's RefineScheduledItemJSON
+func (p *ResilientLLMProvider) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	return p.call(ctx, "refine", func(ctx context.Context) (string, error) {
+		return p.provider.RefineScheduledItemJSON(ctx, conversation, message, userTimezone)
+	})
+}
+
+// RepairJSON calls the wrapped provider's RepairJSON
+func (p *ResilientLLMProvider) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return p.call(ctx, "repair", func(ctx context.Context) (string, error) {
+		return p.provider.RepairJSON(ctx, output, problems)
+	})
+}
+
+// call runs fn with a timeout, retrying retryable errors, unless the breaker is open. The
+// call and its retries are recorded as a span named after the operation.
+func (p *ResilientLLMProvider) call(ctx context.Context, operation string, fn func(ctx context.Context) (string, error)) (result string, err error) {
+	ctx, span := llmTracer.Start(ctx, "llm "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("gen_ai.operation.name", operation),
+			attribute.String("gen_ai.system", p.config.Provider),
+			attribute.String("gen_ai.request.model", p.config.ModelID),
+		))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if !p.allow() {
+		return "", ErrLLMUnavailable
+	}
+
+	for attempt := 0; ; attempt++ {
+		span.SetAttributes(attribute.Int("llm.attempts", attempt+1))
+		recordLLMCall(ctx, p.config.Provider, p.config.ModelID)
+		attemptCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+		result, err = fn(attemptCtx)
+		cancel()
+
+		if err == nil || attempt >= p.config.MaxRetries || !isRetryableLLMError(err) || ctx.Err() != nil {
+			break
+		}
+
+		delay := retryDelay(attempt)
+		logging.Warnf("LLM call failed on attempt %d, retrying in %v: %v", attempt+1, delay, err)
+		if sleepErr := p.sleep(ctx, delay); sleepErr != nil {
+			break
+		}
+	}
+
+	// A request cancelled by the client says nothing about the provider's health
+	if err != nil && ctx.Err() != nil {
+		p.release()
+		return "", err
+	}
+	p.record(err == nil)
+	return result, err
+}
+
+// allow reports whether a call may go ahead: always while the breaker is closed, and
+// only one probing call at a time once an open breaker's cooldown has passed
+func (p *ResilientLLMProvider) allow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.consecutiveFailures < p.config.BreakerThreshold {
+		return true
+	}
+	if p.probing || time.Now().Before(p.openUntil) {
+		return false
+	}
+	p.probing = true
+	return true
+}
+
+// record updates the breaker with the outcome of a call
+func (p *ResilientLLMProvider) record(success bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.probing = false
+	if success {
+		p.consecutiveFailures = 0
+		return
+	}
+
+	p.consecutiveFailures++
+	if p.consecutiveFailures >= p.config.BreakerThreshold {
+		p.openUntil = time.Now().Add(p.config.BreakerCooldown)
+		logging.Warnf("LLM circuit breaker open for %v after %d consecutive failures", p.config.BreakerCooldown, p.consecutiveFailures)
+	}
+}
+
+// release ends a probing call without counting its outcome
+func (p *ResilientLLMProvider) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probing = false
+}
+
+// retryDelay returns a random delay before the given retry, with full jitter so clients
+// throttled together don't retry together
+func retryDelay(attempt int) time.Duration {
+	limit := min(llmRetryBaseDelay<<attempt, llmRetryMaxDelay)
+	return time.Duration(rand.Int64N(int64(limit))) + time.Millisecond
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
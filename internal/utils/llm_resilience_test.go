@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// scriptedLLMProvider returns the scripted errors in turn, then succeeds
+type scriptedLLMProvider struct {
+	errs  []error
+	calls int
+	// block makes calls wait for their context to be done
+	block bool
+}
+
+func (p *scriptedLLMProvider) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	p.calls++
+	if p.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return "", err
+	}
+	return `{"title":"Standup"}`, nil
+}
+
+func (p *scriptedLLMProvider) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	return p.GenerateScheduledItemJSON(ctx, instruction, userTimezone)
+}
+
+func (p *scriptedLLMProvider) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	return p.GenerateScheduledItemJSON(ctx, message, userTimezone)
+}
+
+func (p *scriptedLLMProvider) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return p.GenerateScheduledItemJSON(ctx, output, "UTC")
+}
+
+func newTestResilientProvider(provider LLMProvider) *ResilientLLMProvider {
+	resilient := NewResilientLLMProvider(provider, LLMConfig{
+		Provider:         LLMProviderBedrock,
+		ModelID:          DefaultLLMModelID,
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Hour,
+	})
+	resilient.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return resilient
+}
+
+func TestResilientLLMProviderRetriesThrottling(t *testing.T) {
+	provider := &scriptedLLMProvider{errs: []error{
+		&types.ThrottlingException{},
+		&LLMStatusError{StatusCode: http.StatusTooManyRequests},
+	}}
+	resilient := newTestResilientProvider(provider)
+
+	ctx, usage := WithLLMUsage(context.Background())
+	if _, err := resilient.GenerateScheduledItemJSON(ctx, "Standup", "UTC"); err != nil {
+		t.Fatalf("Expected the call to succeed after retries, got %v", err)
+	}
+	if provider.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", provider.calls)
+	}
+	if usage.Calls() != 3 || usage.Provider() != LLMProviderBedrock || usage.Model() != DefaultLLMModelID {
+		t.Errorf("Expected 3 calls to be recorded against the configured model, got %d to %s %s", usage.Calls(), usage.Provider(), usage.Model())
+	}
+
+	// Other errors aren't retried
+	provider = &scriptedLLMProvider{errs: []error{&types.ValidationException{}}}
+	resilient = newTestResilientProvider(provider)
+	if _, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC"); err == nil || provider.calls != 1 {
+		t.Errorf("Expected a single failed call, got %d calls and error %v", provider.calls, err)
+	}
+}
+
+func TestResilientLLMProviderTimesOutEachCall(t *testing.T) {
+	provider := &scriptedLLMProvider{block: true}
+	resilient := newTestResilientProvider(provider)
+	resilient.config.Timeout = 10 * time.Millisecond
+
+	_, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
+
+func TestResilientLLMProviderCircuitBreaker(t *testing.T) {
+	failure := errors.New("access denied")
+	provider := &scriptedLLMProvider{errs: []error{failure, failure}}
+	resilient := newTestResilientProvider(provider)
+
+	for range 2 {
+		if _, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC"); !errors.Is(err, failure) {
+			t.Fatalf("Expected the provider error, got %v", err)
+		}
+	}
+
+	// The breaker is open, so calls fail fast without reaching the provider
+	if _, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC"); !errors.Is(err, ErrLLMUnavailable) {
+		t.Fatalf("Expected ErrLLMUnavailable, got %v", err)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected the open breaker to skip the provider, got %d calls", provider.calls)
+	}
+
+	// After the cooldown a probing call closes the breaker again
+	resilient.openUntil = time.Now().Add(-time.Second)
+	if _, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC"); err != nil {
+		t.Fatalf("Expected the probing call to succeed, got %v", err)
+	}
+	if _, err := resilient.GenerateScheduledItemJSON(context.Background(), "Standup", "UTC"); err != nil {
+		t.Errorf("Expected the breaker to be closed, got %v", err)
+	}
+}
+
+func TestReloadableLLMProviderSwap(t *testing.T) {
+	first, second := &scriptedLLMProvider{}, &scriptedLLMProvider{}
+	provider := NewReloadableLLMProvider(first)
+
+	provider.GenerateScheduledItemJSON(context.Background(), "Water the plants", "UTC")
+	provider.Swap(second)
+	provider.GenerateScheduledItemJSON(context.Background(), "Water the plants", "UTC")
+
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("Expected one call to each provider, got %d and %d", first.calls, second.calls)
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// LLMUsage accumulates the model calls made for a request and the tokens they used
+type LLMUsage struct {
+	mu           sync.Mutex
+	provider     string
+	model        string
+	calls        int
+	inputTokens  int
+	outputTokens int
+}
+
+// llmUsageKey is the context key of the usage being accumulated
+type llmUsageKey struct{}
+
+// WithLLMUsage returns a context in which every call to a model made through a
+// ResilientLLMProvider, including retries and repairs, is added to the returned usage
+func WithLLMUsage(ctx context.Context) (context.Context, *LLMUsage) {
+	usage := &LLMUsage{}
+	return context.WithValue(ctx, llmUsageKey{}, usage), usage
+}
+
+// recordLLMCall counts an attempt to call a model in the usage accumulated in ctx, if any
+func recordLLMCall(ctx context.Context, provider string, model string) {
+	usage, ok := ctx.Value(llmUsageKey{}).(*LLMUsage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.provider = provider
+	usage.model = model
+	usage.calls++
+}
+
+// recordLLMTokens adds the tokens a model reported using to the usage accumulated in ctx, if any
+func recordLLMTokens(ctx context.Context, inputTokens int, outputTokens int) {
+	usage, ok := ctx.Value(llmUsageKey{}).(*LLMUsage)
+	if !ok {
+		return
+	}
+	usage.mu.Lock()
+	defer usage.mu.Unlock()
+	usage.inputTokens += inputTokens
+	usage.outputTokens += outputTokens
+}
+
+// Provider returns the provider called, or "" if no call was made
+func (u *LLMUsage) Provider() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.provider
+}
+
+// Model returns the model called, or "" if no call was made
+func (u *LLMUsage) Model() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.model
+}
+
+// Calls returns the number of attempts to call the model, including retries
+func (u *LLMUsage) Calls() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.calls
+}
+
+// Tokens returns the input and output tokens used
+func (u *LLMUsage) Tokens() (input int, output int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.inputTokens, u.outputTokens
+}
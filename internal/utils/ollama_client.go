@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaLLMClient generates scheduled items with a local Ollama server, for development
+// without access to a hosted model
+type OllamaLLMClient struct {
+	client  *http.Client
+	config  LLMConfig
+	prompts systemPrompts
+}
+
+// NewOllamaLLMClient creates a client for the Ollama server at config.BaseURL
+func NewOllamaLLMClient(config LLMConfig) (*OllamaLLMClient, error) {
+	prompts, err := loadSystemPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OllamaLLMClient{
+		client:  &http.Client{Timeout: llmRequestTimeout},
+		config:  config,
+		prompts: prompts,
+	}, nil
+}
+
+// GenerateScheduledItemJSON sends a generation prompt to Ollama's chat API and returns JSON response
+func (c *OllamaLLMClient) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	fullPrompt, err := buildGenerationPrompt(c.prompts.generate, userPrompt, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// ModifyScheduledItemJSON sends a modification prompt to Ollama's chat API and returns the merge patch
+func (c *OllamaLLMClient) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	fullPrompt, err := buildModificationPrompt(c.prompts.modify, itemJSON, instruction, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RefineScheduledItemJSON sends a generation conversation and the user's new message to Ollama and returns the revised JSON
+func (c *OllamaLLMClient) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	fullPrompt, err := buildRefinementPrompt(c.prompts.generate, conversation, message, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RepairJSON sends previously generated JSON and its problems to Ollama and returns the corrected JSON
+func (c *OllamaLLMClient) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return c.complete(ctx, buildRepairPrompt(output, problems))
+}
+
+// complete sends a prompt to Ollama's chat API and returns the generated text
+func (c *OllamaLLMClient) complete(ctx context.Context, fullPrompt string) (string, error) {
+	options := map[string]any{
+		"num_predict": c.config.MaxTokens,
+		"temperature": c.config.Temperature,
+	}
+	if c.config.TopP != nil {
+		options["top_p"] = *c.config.TopP
+	}
+
+	// Ask for JSON output so smaller local models don't wrap the item in prose
+	request := map[string]any{
+		"model":    c.config.ModelID,
+		"messages": []llmMessage{{Role: "user", Content: fullPrompt}},
+		"stream":   false,
+		"format":   "json",
+		"options":  options,
+	}
+
+	var response struct {
+		Message         llmMessage `json:"message"`
+		PromptEvalCount int        `json:"prompt_eval_count"`
+		EvalCount       int        `json:"eval_count"`
+	}
+	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/api/chat"
+	if err := postLLMJSON(ctx, c.client, url, nil, request, &response); err != nil {
+		return "", err
+	}
+	recordLLMTokens(ctx, response.PromptEvalCount, response.EvalCount)
+
+	if response.Message.Content == "" {
+		return "", fmt.Errorf("no text in response")
+	}
+	return response.Message.Content, nil
+}
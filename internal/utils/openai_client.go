@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OpenAILLMClient generates scheduled items with an OpenAI-compatible chat completions
+// API, such as OpenAI itself, vLLM or LM Studio
+type OpenAILLMClient struct {
+	client  *http.Client
+	config  LLMConfig
+	prompts systemPrompts
+}
+
+// NewOpenAILLMClient creates a client for the chat completions API at config.BaseURL
+func NewOpenAILLMClient(config LLMConfig) (*OpenAILLMClient, error) {
+	prompts, err := loadSystemPrompts()
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenAILLMClient{
+		client:  &http.Client{Timeout: llmRequestTimeout},
+		config:  config,
+		prompts: prompts,
+	}, nil
+}
+
+// GenerateScheduledItemJSON sends a generation prompt to the chat completions API and returns JSON response
+func (c *OpenAILLMClient) GenerateScheduledItemJSON(ctx context.Context, userPrompt string, userTimezone string) (string, error) {
+	fullPrompt, err := buildGenerationPrompt(c.prompts.generate, userPrompt, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// ModifyScheduledItemJSON sends a modification prompt to the chat completions API and returns the merge patch
+func (c *OpenAILLMClient) ModifyScheduledItemJSON(ctx context.Context, itemJSON string, instruction string, userTimezone string) (string, error) {
+	fullPrompt, err := buildModificationPrompt(c.prompts.modify, itemJSON, instruction, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RefineScheduledItemJSON sends a generation conversation and the user's new message to the chat completions API and returns the revised JSON
+func (c *OpenAILLMClient) RefineScheduledItemJSON(ctx context.Context, conversation []LLMTurn, message string, userTimezone string) (string, error) {
+	fullPrompt, err := buildRefinementPrompt(c.prompts.generate, conversation, message, userTimezone)
+	if err != nil {
+		return "", err
+	}
+	return c.complete(ctx, fullPrompt)
+}
+
+// RepairJSON sends previously generated JSON and its problems to the chat completions API and returns the corrected JSON
+func (c *OpenAILLMClient) RepairJSON(ctx context.Context, output string, problems []string) (string, error) {
+	return c.complete(ctx, buildRepairPrompt(output, problems))
+}
+
+// complete sends a prompt to the chat completions API and returns the generated text
+func (c *OpenAILLMClient) complete(ctx context.Context, fullPrompt string) (string, error) {
+	request := struct {
+		Model       string       `json:"model"`
+		Messages    []llmMessage `json:"messages"`
+		MaxTokens   int          `json:"max_tokens"`
+		Temperature float64      `json:"temperature"`
+		TopP        *float64     `json:"top_p,omitempty"`
+	}{
+		Model:       c.config.ModelID,
+		Messages:    []llmMessage{{Role: "user", Content: fullPrompt}},
+		MaxTokens:   c.config.MaxTokens,
+		Temperature: c.config.Temperature,
+		TopP:        c.config.TopP,
+	}
+
+	headers := map[string]string{}
+	if c.config.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.config.APIKey
+	}
+
+	var response struct {
+		Choices []struct {
+			Message llmMessage `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	url := strings.TrimSuffix(c.config.BaseURL, "/") + "/chat/completions"
+	if err := postLLMJSON(ctx, c.client, url, headers, request, &response); err != nil {
+		return "", err
+	}
+	recordLLMTokens(ctx, response.Usage.PromptTokens, response.Usage.CompletionTokens)
+
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("unexpected response format - no choices")
+	}
+	return response.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import "testing"
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	if string(hash) == "correct horse" {
+		t.Fatal("Expected the password to be hashed")
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("Expected the password to match its hash")
+	}
+	if CheckPassword(hash, "battery staple") {
+		t.Error("Expected another password not to match the hash")
+	}
+
+	if _, err := HashPassword("short"); err == nil {
+		t.Error("Expected a password shorter than the minimum to be refused")
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	first, err := GeneratePassword()
+	if err != nil {
+		t.Fatalf("GeneratePassword failed: %v", err)
+	}
+	second, err := GeneratePassword()
+	if err != nil {
+		t.Fatalf("GeneratePassword failed: %v", err)
+	}
+	if len(first) != 24 {
+		t.Errorf("Expected 24 characters, got %d in %q", len(first), first)
+	}
+	if first == second {
+		t.Error("Expected generated passwords to differ")
+	}
+	if _, err := HashPassword(first); err != nil {
+		t.Errorf("Expected a generated password to be accepted: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package version describes the build of the running binary
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Build details, set at build time with
+//
+//	go build -ldflags "-X periodic-api/internal/version.Version=v1.2.0 -X periodic-api/internal/version.Commit=$(git rev-parse HEAD) -X periodic-api/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// String describes the build. The commit falls back to the revision Go embeds from git
+// when it wasn't set with -ldflags.
+func String() string {
+	commit, goVersion, modified := Commit, "", false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		goVersion = info.GoVersion
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && commit == "":
+				commit = setting.Value
+			case setting.Key == "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+	if modified && Commit == "" && commit != "" {
+		commit += "-dirty"
+	}
+
+	details := []string{}
+	if commit != "" {
+		details = append(details, "commit "+commit)
+	}
+	if BuildTime != "" {
+		details = append(details, "built "+BuildTime)
+	}
+	if goVersion != "" {
+		details = append(details, goVersion)
+	}
+	if len(details) == 0 {
+		return Version
+	}
+	return fmt.Sprintf("%s (%s)", Version, strings.Join(details, ", "))
+}
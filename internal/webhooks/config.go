@@ -0,0 +1,110 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"periodic-api/internal/logging"
+)
+
+const (
+	// defaultMaxAttempts is how many times a delivery is tried when WEBHOOK_MAX_ATTEMPTS is not set
+	defaultMaxAttempts = 5
+	// defaultInitialBackoff is the wait before the first retry when WEBHOOK_INITIAL_BACKOFF is not set
+	defaultInitialBackoff = time.Second
+	// defaultMaxBackoff caps the wait between retries when WEBHOOK_MAX_BACKOFF is not set
+	defaultMaxBackoff = 5 * time.Minute
+	// defaultTimeout bounds each delivery request when WEBHOOK_TIMEOUT is not set
+	defaultTimeout = 10 * time.Second
+)
+
+// Config configures how events are delivered to webhooks
+type Config struct {
+	// MaxAttempts is the number of times a delivery is tried before giving up
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles for every further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+	// Timeout bounds each delivery request
+	Timeout time.Duration
+	// AllowPrivateNetworks lets webhooks call loopback, private and link-local addresses,
+	// for development against local receivers
+	AllowPrivateNetworks bool
+}
+
+// ConfigFromEnv returns the delivery configuration from the WEBHOOK_MAX_ATTEMPTS,
+// WEBHOOK_INITIAL_BACKOFF, WEBHOOK_MAX_BACKOFF, WEBHOOK_TIMEOUT and
+// WEBHOOK_ALLOW_PRIVATE_NETWORKS environment variables.
+// Invalid values are logged and replaced with the defaults.
+func ConfigFromEnv() Config {
+	config, problems := parseConfigEnv()
+	for _, problem := range problems {
+		logging.Warnf("Webhook configuration: %v", problem)
+	}
+	return config
+}
+
+// CheckConfigEnv reports the invalid webhook settings that ConfigFromEnv would replace
+// with defaults
+func CheckConfigEnv() error {
+	_, problems := parseConfigEnv()
+	return errors.Join(problems...)
+}
+
+// parseConfigEnv reads the delivery configuration, returning the defaults used in place
+// of invalid values along with a description of each
+func parseConfigEnv() (Config, []error) {
+	config := Config{
+		MaxAttempts:    defaultMaxAttempts,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+		Timeout:        defaultTimeout,
+	}
+	var problems []error
+
+	if attemptsStr := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); attemptsStr != "" {
+		if attempts, err := strconv.Atoi(attemptsStr); err == nil && attempts > 0 {
+			config.MaxAttempts = attempts
+		} else {
+			problems = append(problems, fmt.Errorf("invalid WEBHOOK_MAX_ATTEMPTS %q, using default: %d", attemptsStr, config.MaxAttempts))
+		}
+	}
+	config.InitialBackoff = durationFromEnv("WEBHOOK_INITIAL_BACKOFF", config.InitialBackoff, &problems)
+	config.MaxBackoff = durationFromEnv("WEBHOOK_MAX_BACKOFF", config.MaxBackoff, &problems)
+	config.Timeout = durationFromEnv("WEBHOOK_TIMEOUT", config.Timeout, &problems)
+	if allowStr := os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS"); allowStr != "" {
+		if allow, err := strconv.ParseBool(allowStr); err == nil {
+			config.AllowPrivateNetworks = allow
+		} else {
+			problems = append(problems, fmt.Errorf("invalid WEBHOOK_ALLOW_PRIVATE_NETWORKS %q, using default: false", allowStr))
+		}
+	}
+	return config, problems
+}
+
+// durationFromEnv reads a positive duration from an environment variable, falling back to def
+func durationFromEnv(name string, def time.Duration, problems *[]error) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		*problems = append(*problems, fmt.Errorf("invalid %s %q, using default: %v", name, value, def))
+		return def
+	}
+	return duration
+}
+
+// backoff returns how long to wait after the given failed attempt before retrying
+func (c Config) backoff(attempt int) time.Duration {
+	backoff := c.InitialBackoff
+	for i := 1; i < attempt && backoff < c.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, c.MaxBackoff)
+}
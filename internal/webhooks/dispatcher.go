@@ -0,0 +1,205 @@
+// Package webhooks delivers events published on the bus to the registered webhooks
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"periodic-api/internal/events"
+	"periodic-api/internal/logging"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// Headers sent with every delivery
+const (
+	// EventHeader carries the event type
+	EventHeader = "X-Periodic-Event"
+	// DeliveryHeader carries the event ID, which stays the same across retries
+	DeliveryHeader = "X-Periodic-Delivery"
+	// SignatureHeader carries the signature of the body, see Sign
+	SignatureHeader = "X-Periodic-Signature"
+)
+
+// Dispatcher sends the events published on a bus to the active webhooks subscribed to
+// them, retrying failed deliveries with exponential backoff and logging every attempt
+type Dispatcher struct {
+	store  store.WebhookStore
+	items  *store.ScopedScheduledItemStore
+	client *http.Client
+	config Config
+}
+
+// NewDispatcher creates a dispatcher delivering to the webhooks in the given store, only
+// to public addresses unless the config allows private networks. Webhooks with an owner
+// are only sent the events about the scheduled items their owner may see through items;
+// with items nil, every webhook is sent every event of its tenant.
+func NewDispatcher(store store.WebhookStore, items *store.ScopedScheduledItemStore, config Config) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		items:  items,
+		client: NewClient(config.Timeout, config.AllowPrivateNetworks),
+		config: config,
+	}
+}
+
+// Run delivers the events published on bus until ctx is cancelled, then waits for the
+// deliveries in flight to stop
+func (d *Dispatcher) Run(ctx context.Context, bus *events.Bus) {
+	subscription, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	var deliveries sync.WaitGroup
+	defer deliveries.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-subscription:
+			if !ok {
+				return
+			}
+			d.dispatch(ctx, event, &deliveries)
+		}
+	}
+}
+
+// dispatch starts delivering the event to every active webhook of its tenant subscribed
+// to its type whose owner may see it
+func (d *Dispatcher) dispatch(ctx context.Context, event events.Event, deliveries *sync.WaitGroup) {
+	var body []byte
+	tenantCtx := store.WithTenant(ctx, event.Tenant)
+	for _, webhook := range d.store.GetAllWebhooks(tenantCtx) {
+		if !webhook.Active || !slices.Contains(webhook.EventTypes, event.Type) || !d.ownerSees(tenantCtx, webhook, event) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			if body, err = json.Marshal(event); err != nil {
+				logging.Errorf("Error encoding event ID=%d for webhooks: %v", event.ID, err)
+				return
+			}
+		}
+
+		deliveries.Add(1)
+		go func() {
+			defer deliveries.Done()
+			d.deliver(ctx, webhook, event, body)
+		}()
+	}
+}
+
+// ownerSees reports whether the owner of a webhook may see the event
+func (d *Dispatcher) ownerSees(ctx context.Context, webhook models.Webhook, event events.Event) bool {
+	if d.items == nil || webhook.OwnerID == nil {
+		return true
+	}
+	return d.items.SeesEvent(store.WithUser(ctx, *webhook.OwnerID), event)
+}
+
+// deliver sends the event to the webhook until it is accepted or the attempts run out
+func (d *Dispatcher) deliver(ctx context.Context, webhook models.Webhook, event events.Event, body []byte) {
+	for attempt := 1; ; attempt++ {
+		delivery := d.attempt(ctx, webhook, event, body, attempt)
+
+		// Record the attempt even if it was cut short by shutdown
+		d.store.CreateWebhookDelivery(context.WithoutCancel(ctx), delivery)
+
+		if delivery.Succeeded {
+			return
+		}
+		if attempt >= d.config.MaxAttempts {
+			logging.Errorf("Giving up delivering event ID=%d to webhook ID=%d after %d attempts",
+				event.ID, webhook.ID, attempt)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.config.backoff(attempt)):
+		}
+	}
+}
+
+// attempt makes a single delivery request and describes its outcome
+func (d *Dispatcher) attempt(ctx context.Context, webhook models.Webhook, event events.Event, body []byte, attempt int) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		WebhookID:   webhook.ID,
+		EventID:     event.ID,
+		EventType:   event.Type,
+		Attempt:     attempt,
+		DeliveredAt: time.Now(),
+	}
+
+	statusCode, err := d.send(ctx, webhook, event, body)
+	delivery.DurationMs = time.Since(delivery.DeliveredAt).Milliseconds()
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+	if err != nil {
+		errorMessage := err.Error()
+		delivery.ErrorMessage = &errorMessage
+		return delivery
+	}
+
+	delivery.Succeeded = true
+	return delivery
+}
+
+// send posts the signed event to the webhook URL and returns the response status code
+func (d *Dispatcher) send(ctx context.Context, webhook models.Webhook, event events.Event, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "periodic-api-webhooks")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(event.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature sent with a delivery body: "sha256=" followed by the hex
+// HMAC-SHA256 of the body keyed with the webhook secret. Receivers verify deliveries by
+// computing the same value and comparing it in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a random secret for signing the deliveries of a new webhook
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,169 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"periodic-api/internal/events"
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDispatcherSignsAndRetriesDeliveries(t *testing.T) {
+	var requests atomic.Int32
+	received := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+			t.Errorf("Expected signature %q, got %q", want, got)
+		}
+
+		// Fail the first attempt so the delivery is retried
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case received <- r:
+		default:
+		}
+	}))
+	defer server.Close()
+
+	webhookStore := store.NewMemoryWebhookStore()
+	webhook := webhookStore.CreateWebhook(context.Background(), models.Webhook{
+		URL:        server.URL,
+		EventTypes: []string{events.ScheduledItemExecuted},
+		Secret:     "secret",
+		Active:     true,
+	})
+
+	bus := events.NewBus()
+	dispatcher := NewDispatcher(webhookStore, nil, Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Timeout:        time.Second,
+		// The test server listens on loopback
+		AllowPrivateNetworks: true,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dispatcher.Run(ctx, bus)
+		close(done)
+	}()
+
+	// Wait for the dispatcher to subscribe before publishing
+	deadline := time.After(time.Second)
+	var delivered *http.Request
+	for delivered == nil {
+		bus.Publish(events.TodoCreated, models.TodoItem{ID: 1})
+		bus.Publish(events.ScheduledItemExecuted, models.ExecutionLog{ID: 1})
+		select {
+		case delivered = <-received:
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Timed out waiting for the webhook delivery")
+		}
+	}
+	cancel()
+	<-done
+
+	if eventType := delivered.Header.Get(EventHeader); eventType != events.ScheduledItemExecuted {
+		t.Errorf("Expected %s event, got %q", events.ScheduledItemExecuted, eventType)
+	}
+
+	deliveries := webhookStore.GetWebhookDeliveries(context.Background(), webhook.ID, 10)
+	if len(deliveries) < 2 {
+		t.Fatalf("Expected at least 2 logged attempts, got %d", len(deliveries))
+	}
+	first := deliveries[len(deliveries)-1]
+	if first.Succeeded || first.StatusCode == nil || *first.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the first attempt to fail with 503, got %+v", first)
+	}
+	for _, delivery := range deliveries {
+		if delivery.EventType != events.ScheduledItemExecuted {
+			t.Errorf("Expected only %s deliveries, got %s", events.ScheduledItemExecuted, delivery.EventType)
+		}
+	}
+}
+
+func TestConfigBackoffDoublesUpToMax(t *testing.T) {
+	config := Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := config.backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestCheckConfigEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	t.Setenv("WEBHOOK_TIMEOUT", "5s")
+	if err := CheckConfigEnv(); err != nil {
+		t.Errorf("Expected valid settings, got %v", err)
+	}
+
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "0")
+	t.Setenv("WEBHOOK_MAX_BACKOFF", "soon")
+	err := CheckConfigEnv()
+	if err == nil || !strings.Contains(err.Error(), "WEBHOOK_MAX_ATTEMPTS") || !strings.Contains(err.Error(), "WEBHOOK_MAX_BACKOFF") {
+		t.Errorf("Expected both invalid settings to be reported, got %v", err)
+	}
+	if config := ConfigFromEnv(); config.MaxAttempts != defaultMaxAttempts || config.MaxBackoff != defaultMaxBackoff || config.Timeout != 5*time.Second {
+		t.Errorf("Expected defaults in place of the invalid settings, got %+v", config)
+	}
+}
+
+func TestDispatcherOnlySendsOwnersTheEventsTheyMaySee(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	alice, bob := int64(1), int64(2)
+	items := store.NewScopedScheduledItemStore(store.NewMemoryScheduledItemStore(), store.NewMemoryItemShareStore())
+	item := items.CreateScheduledItem(context.Background(), models.ScheduledItem{Title: "Alice's secret plan", OwnerID: &alice})
+
+	webhookStore := store.NewMemoryWebhookStore()
+	for _, webhook := range []models.Webhook{
+		{URL: server.URL + "/alice", OwnerID: &alice},
+		{URL: server.URL + "/bob", OwnerID: &bob},
+		{URL: server.URL + "/tenant"},
+	} {
+		webhook.EventTypes = []string{events.ScheduledItemExecuted}
+		webhook.Secret = "secret"
+		webhook.Active = true
+		webhookStore.CreateWebhook(context.Background(), webhook)
+	}
+
+	dispatcher := NewDispatcher(webhookStore, items, Config{
+		MaxAttempts: 1,
+		Timeout:     time.Second,
+		// The test server listens on loopback
+		AllowPrivateNetworks: true,
+	})
+	var deliveries sync.WaitGroup
+	event := events.Event{ID: 1, Type: events.ScheduledItemExecuted, Data: models.ExecutionLog{ID: 1, ScheduledItemID: item.ID}}
+	dispatcher.dispatch(context.Background(), event, &deliveries)
+	deliveries.Wait()
+
+	slices.Sort(paths)
+	if !slices.Equal(paths, []string{"/alice", "/tenant"}) {
+		t.Errorf("Expected only alice's webhook and the tenant's to be sent alice's execution, got %v", paths)
+	}
+}
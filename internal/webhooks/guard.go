@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address on the
+// server's own network, such as loopback, RFC 1918 or link-local addresses
+var ErrPrivateAddress = errors.New("webhook address is not a public address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which isn't reachable
+// from the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddress reports whether addr may be called by webhooks: it is not a loopback,
+// private, link-local, shared, unspecified or multicast address. IPv4 addresses mapped to
+// IPv6 are judged as IPv4.
+func PublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// NewClient returns an HTTP client for calling webhook URLs with the given timeout. Unless
+// allowPrivateNetworks is set, it refuses to connect to addresses that aren't public. The
+// address is checked in the dialer once the host name has been resolved, so neither
+// redirects nor a DNS record changed after the URL was validated can reach the server's
+// own network. Proxies aren't used, as they would connect on the client's behalf.
+func NewClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateNetworks {
+		dialer.Control = refusePrivateAddresses
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// refusePrivateAddresses is a dialer Control hook that fails connections to addresses
+// that aren't public
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	if !PublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// CheckURL resolves the host of a webhook URL and returns ErrPrivateAddress when any of
+// its addresses isn't public. Hosts that can't be resolved are let through, as deliveries
+// to them fail anyway and the dialer checks the address again on every connection.
+func CheckURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := parsed.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !PublicAddress(addr) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !PublicAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateAddress, host, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::ffff:127.0.0.1": false,
+		"224.0.0.1":        false,
+	}
+	for address, want := range tests {
+		if got := PublicAddress(netip.MustParseAddr(address)); got != want {
+			t.Errorf("PublicAddress(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestCheckURLRejectsPrivateAddresses(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://[::1]/hooks",
+		"http://10.0.0.5/hooks",
+		"http://localhost/hooks",
+	} {
+		if err := CheckURL(context.Background(), rawURL); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("CheckURL(%s) = %v, want ErrPrivateAddress", rawURL, err)
+		}
+	}
+	if err := CheckURL(context.Background(), "https://93.184.216.34/hooks"); err != nil {
+		t.Errorf("Expected a public address to be accepted, got %v", err)
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	// The address is checked when connecting, whatever the URL looked like when validated
+	_, err := NewClient(time.Second, false).Get(server.URL)
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("Expected the loopback server to be refused, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", requests.Load())
+	}
+
+	resp, err := NewClient(time.Second, true).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected private networks to be reachable when allowed, got %v", err)
+	}
+	resp.Body.Close()
+}
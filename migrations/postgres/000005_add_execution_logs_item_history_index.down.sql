@@ -0,0 +1,3 @@
+-- Restore the single-column index and drop the composite history index
+CREATE INDEX IF NOT EXISTS idx_execution_logs_scheduled_item_id ON execution_logs (scheduled_item_id);
+DROP INDEX IF EXISTS idx_execution_logs_item_history;
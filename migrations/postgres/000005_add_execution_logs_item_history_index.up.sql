@@ -0,0 +1,6 @@
+-- Add composite index for paginating an item's execution history newest first
+CREATE INDEX IF NOT EXISTS idx_execution_logs_item_history
+ON execution_logs (scheduled_item_id, executed_at DESC, id DESC);
+
+-- The composite index covers lookups by scheduled_item_id alone
+DROP INDEX IF EXISTS idx_execution_logs_scheduled_item_id;
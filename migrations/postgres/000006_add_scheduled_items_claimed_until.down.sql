@@ -0,0 +1,2 @@
+-- Remove claim lease column from scheduled_items table
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS claimed_until;
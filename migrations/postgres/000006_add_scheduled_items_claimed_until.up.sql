@@ -0,0 +1,2 @@
+-- Add claim lease column so concurrent schedulers don't process the same item
+ALTER TABLE scheduled_items ADD COLUMN claimed_until TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Remove execution key from execution_logs table
+DROP INDEX IF EXISTS idx_execution_logs_execution_key;
+ALTER TABLE execution_logs DROP COLUMN IF EXISTS execution_key;
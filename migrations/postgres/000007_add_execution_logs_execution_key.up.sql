@@ -0,0 +1,5 @@
+-- Add execution key identifying the scheduled occurrence a log entry belongs to
+ALTER TABLE execution_logs ADD COLUMN execution_key TEXT;
+
+-- Each occurrence may only be recorded once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_execution_logs_execution_key ON execution_logs (execution_key);
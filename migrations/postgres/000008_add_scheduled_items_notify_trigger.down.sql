@@ -0,0 +1,3 @@
+-- Remove scheduled item change notifications
+DROP TRIGGER IF EXISTS trg_scheduled_items_notify ON scheduled_items;
+DROP FUNCTION IF EXISTS notify_scheduled_item_change();
@@ -0,0 +1,12 @@
+-- Notify listening schedulers when an item's next execution time is set
+CREATE OR REPLACE FUNCTION notify_scheduled_item_change() RETURNS trigger AS $$
+BEGIN
+    -- Payload is the next execution time as Unix epoch seconds
+    PERFORM pg_notify('scheduled_items', EXTRACT(EPOCH FROM NEW.next_execution_at)::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_scheduled_items_notify
+AFTER INSERT OR UPDATE OF next_execution_at ON scheduled_items
+FOR EACH ROW EXECUTE FUNCTION notify_scheduled_item_change();
@@ -0,0 +1,3 @@
+-- Remove action columns from scheduled_items table
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS action_config;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS action_type;
@@ -0,0 +1,3 @@
+-- Add configurable action executed for each scheduled item occurrence
+ALTER TABLE scheduled_items ADD COLUMN action_type TEXT NOT NULL DEFAULT 'todo';
+ALTER TABLE scheduled_items ADD COLUMN action_config JSONB;
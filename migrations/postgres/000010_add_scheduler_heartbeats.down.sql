@@ -0,0 +1,2 @@
+-- Drop scheduler_heartbeats table
+DROP TABLE IF EXISTS scheduler_heartbeats;
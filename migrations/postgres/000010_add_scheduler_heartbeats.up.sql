@@ -0,0 +1,8 @@
+-- Add scheduler_heartbeats table so operators can see running scheduler instances
+CREATE TABLE IF NOT EXISTS scheduler_heartbeats (
+    instance_id TEXT PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    last_tick_at TIMESTAMP NOT NULL,
+    items_processed BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0
+);
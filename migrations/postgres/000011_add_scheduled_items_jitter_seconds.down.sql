@@ -0,0 +1,2 @@
+-- Remove jitter window from scheduled_items table
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS jitter_seconds;
@@ -0,0 +1,2 @@
+-- Add optional window in seconds over which each execution is randomly delayed
+ALTER TABLE scheduled_items ADD COLUMN jitter_seconds INTEGER NOT NULL DEFAULT 0 CHECK (jitter_seconds >= 0);
@@ -0,0 +1,4 @@
+-- Remove creation and update timestamps
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at, DROP COLUMN IF EXISTS created_at;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS updated_at, DROP COLUMN IF EXISTS created_at;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS updated_at, DROP COLUMN IF EXISTS created_at;
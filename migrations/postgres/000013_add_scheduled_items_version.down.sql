@@ -0,0 +1,2 @@
+-- Remove version from scheduled_items table
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS version;
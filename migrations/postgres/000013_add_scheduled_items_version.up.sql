@@ -0,0 +1,2 @@
+-- Add a version that is incremented on every update so concurrent edits can be detected
+ALTER TABLE scheduled_items ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Remove llm_usage table
+DROP TABLE IF EXISTS llm_usage;
@@ -0,0 +1,2 @@
+-- Remove generation_sessions table
+DROP TABLE IF EXISTS generation_sessions;
@@ -0,0 +1,14 @@
+-- Add generation_sessions table holding conversations that refine a generated scheduled item
+CREATE TABLE IF NOT EXISTS generation_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    timezone TEXT NOT NULL,
+    messages JSONB NOT NULL,
+    item JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Expired sessions are removed when new ones are created
+CREATE INDEX IF NOT EXISTS idx_generation_sessions_expires_at ON generation_sessions (expires_at);
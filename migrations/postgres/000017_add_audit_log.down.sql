@@ -0,0 +1,2 @@
+-- Remove audit_log table
+DROP TABLE IF EXISTS audit_log;
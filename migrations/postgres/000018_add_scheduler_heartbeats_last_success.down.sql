@@ -0,0 +1,3 @@
+-- Remove last_success_at and interval_ms from scheduler_heartbeats
+ALTER TABLE scheduler_heartbeats DROP COLUMN IF EXISTS interval_ms;
+ALTER TABLE scheduler_heartbeats DROP COLUMN IF EXISTS last_success_at;
@@ -0,0 +1,4 @@
+-- Record each scheduler instance's last successful tick and its interval, so a stalled
+-- scheduler can be detected from the API
+ALTER TABLE scheduler_heartbeats ADD COLUMN IF NOT EXISTS last_success_at TIMESTAMP;
+ALTER TABLE scheduler_heartbeats ADD COLUMN IF NOT EXISTS interval_ms BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Remove request_id from scheduled_items and execution_logs
+ALTER TABLE execution_logs DROP COLUMN IF EXISTS request_id;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,4 @@
+-- Record the API request that created or last modified each scheduled item, and carry it
+-- into the execution logs of the item, so executions can be traced back to the API call
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE execution_logs ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Remove the notification settings of scheduled items and users
+ALTER TABLE users DROP COLUMN IF EXISTS notifications;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS notifications;
@@ -0,0 +1,5 @@
+-- Notification settings for scheduled items, and the address and preferences users are
+-- notified with when an item lists them
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS notifications JSONB;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notifications JSONB;
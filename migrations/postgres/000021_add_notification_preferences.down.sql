@@ -0,0 +1,15 @@
+-- Move notification preferences back into the notifications column of users; channels
+-- other than email and quiet hours are lost
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notifications JSONB;
+
+UPDATE users
+SET notifications = jsonb_build_object(
+    'onSuccess', 'execution' = ANY (p.events),
+    'onFailure', 'failure' = ANY (p.events),
+    'subject', p.subject,
+    'body', p.body
+)
+FROM notification_preferences p
+WHERE p.user_id = users.id;
+
+DROP TABLE IF EXISTS notification_preferences;
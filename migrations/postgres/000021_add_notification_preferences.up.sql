@@ -0,0 +1,31 @@
+-- Users' notification preferences: the channels and events they are notified about and
+-- their quiet hours. They replace the notifications column of users, whose settings are
+-- carried over as email preferences.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    channels TEXT[] NOT NULL,
+    events TEXT[] NOT NULL,
+    slack_user_id TEXT NOT NULL DEFAULT '',
+    quiet_hours_start TEXT NOT NULL DEFAULT '',
+    quiet_hours_end TEXT NOT NULL DEFAULT '',
+    timezone TEXT NOT NULL DEFAULT '',
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO notification_preferences (user_id, channels, events, subject, body)
+SELECT id,
+       ARRAY['email'],
+       ARRAY_REMOVE(ARRAY[
+           CASE WHEN (notifications->>'onSuccess')::BOOLEAN THEN 'execution' END,
+           CASE WHEN (notifications->>'onFailure')::BOOLEAN THEN 'failure' END
+       ], NULL),
+       COALESCE(notifications->>'subject', ''),
+       COALESCE(notifications->>'body', '')
+FROM users
+WHERE notifications IS NOT NULL
+ON CONFLICT (user_id) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS notifications;
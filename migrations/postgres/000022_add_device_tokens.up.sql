@@ -0,0 +1,10 @@
+-- Push tokens of users' mobile devices. A device registers its token once per user, so
+-- registering again only refreshes it.
+CREATE TABLE IF NOT EXISTS device_tokens (
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    platform TEXT NOT NULL,
+    token TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, token)
+);
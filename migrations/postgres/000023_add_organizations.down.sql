@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_todo_items_organization_id;
+DROP INDEX IF EXISTS idx_scheduled_items_organization_id;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS organization_id;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,25 @@
+-- Organizations are teams, such as households or on-call rotations, whose members share
+-- the scheduled items and todos that belong to the organization. Items and todos without
+-- an organization belong to no team, as before.
+CREATE TABLE IF NOT EXISTS organizations (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id INTEGER NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Organizations that still own items or todos can't be deleted
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations (id);
+ALTER TABLE todo_items ADD COLUMN IF NOT EXISTS organization_id INTEGER REFERENCES organizations (id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_organization_id ON scheduled_items (organization_id);
+CREATE INDEX IF NOT EXISTS idx_todo_items_organization_id ON todo_items (organization_id);
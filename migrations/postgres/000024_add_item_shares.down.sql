@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_item_shares_user_id;
+DROP TABLE IF EXISTS item_shares;
+DROP INDEX IF EXISTS idx_scheduled_items_owner_id;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS owner_id;
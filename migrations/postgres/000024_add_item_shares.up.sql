@@ -0,0 +1,17 @@
+-- Scheduled items created by an identified user belong to them; items without an owner
+-- stay visible to everyone, as before
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_owner_id ON scheduled_items (owner_id);
+
+-- Owners share items with other users as viewers or editors
+CREATE TABLE IF NOT EXISTS item_shares (
+    scheduled_item_id INTEGER NOT NULL REFERENCES scheduled_items (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scheduled_item_id, user_id)
+);
+
+-- Lists the items shared with a user
+CREATE INDEX IF NOT EXISTS idx_item_shares_user_id ON item_shares (user_id);
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_execution_logs_tenant_id;
+DROP INDEX IF EXISTS idx_users_tenant_id;
+DROP INDEX IF EXISTS idx_todo_items_tenant_id;
+DROP INDEX IF EXISTS idx_scheduled_items_tenant_id;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tenant_id_email_key;
+-- Tenants may have users with the same email address by now, which a deployment-wide
+-- constraint would reject, so it is only restored while the addresses are still unique
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM users WHERE email IS NOT NULL GROUP BY email HAVING COUNT(*) > 1) THEN
+        ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+    END IF;
+END $$;
+ALTER TABLE execution_logs DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE todo_items DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS tenant_id;
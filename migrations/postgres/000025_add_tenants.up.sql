@@ -0,0 +1,16 @@
+-- Every scheduled item, todo item, user and execution log belongs to a tenant. The
+-- default backfills the existing rows into the tenant of single-tenant deployments.
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE todo_items ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE execution_logs ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Email addresses only need to be unique within a tenant
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+
+-- Every query is filtered by tenant
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_tenant_id ON scheduled_items (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_todo_items_tenant_id ON todo_items (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_execution_logs_tenant_id ON execution_logs (tenant_id);
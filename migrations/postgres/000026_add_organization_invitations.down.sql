@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_organization_invitations_organization_id;
+DROP TABLE IF EXISTS organization_invitations;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS tenant_limits;
+DROP INDEX IF EXISTS idx_llm_usage_tenant_id_created_at;
+DROP INDEX IF EXISTS idx_webhooks_tenant_id;
+ALTER TABLE llm_usage DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS tenant_id;
@@ -0,0 +1,16 @@
+-- Webhooks and LLM usage belong to a tenant too, so tenants can be limited in how many
+-- webhooks they have and how many generation requests they make
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE llm_usage ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS idx_webhooks_tenant_id ON webhooks (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_llm_usage_tenant_id_created_at ON llm_usage (tenant_id, created_at);
+
+-- Limits adjusted for a tenant; 0 means unlimited. Tenants without a row get the
+-- deployment's defaults.
+CREATE TABLE IF NOT EXISTS tenant_limits (
+    tenant_id TEXT PRIMARY KEY,
+    max_scheduled_items INTEGER NOT NULL DEFAULT 0,
+    max_daily_generations INTEGER NOT NULL DEFAULT 0,
+    max_webhooks INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- Administrators can deactivate users, who can then no longer make requests or be notified
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
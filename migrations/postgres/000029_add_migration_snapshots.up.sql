@@ -0,0 +1,11 @@
+-- Snapshots taken before migration runs, for restoring the database when a migration
+-- destroys data it shouldn't have
+CREATE TABLE IF NOT EXISTS migration_snapshots (
+    id SERIAL PRIMARY KEY,
+    method TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    from_version INTEGER NOT NULL,
+    to_version INTEGER NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE scheduled_items DROP COLUMN IF EXISTS paused;
@@ -0,0 +1,2 @@
+-- Paused items keep their schedule but aren't claimed by the scheduler until resumed
+ALTER TABLE scheduled_items ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT FALSE;
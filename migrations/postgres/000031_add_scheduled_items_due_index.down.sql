@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_next_execution
+ON scheduled_items (next_execution_at);
+
+DROP INDEX IF EXISTS idx_scheduled_items_tenant_due;
+DROP INDEX IF EXISTS idx_scheduled_items_due;
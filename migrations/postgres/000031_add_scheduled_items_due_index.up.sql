@@ -0,0 +1,14 @@
+-- The scheduler polls for due items every interval. Partial indexes over the items it
+-- can claim let it read the due range instead of scanning the table; the predicate must
+-- stay implied by the WHERE clauses of ClaimDueItems and GetNextScheduledItems.
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_due
+ON scheduled_items (next_execution_at)
+WHERE NOT paused;
+
+-- GET /scheduled-items/next reads one tenant's due items in next_execution_at order
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_tenant_due
+ON scheduled_items (tenant_id, next_execution_at)
+WHERE NOT paused;
+
+-- Superseded by the partial indexes; nothing else filters or orders by next_execution_at
+DROP INDEX IF EXISTS idx_scheduled_items_next_execution;
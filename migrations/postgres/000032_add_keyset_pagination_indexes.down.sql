@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_execution_logs_tenant_keyset;
+DROP INDEX IF EXISTS idx_todo_items_tenant_keyset;
+DROP INDEX IF EXISTS idx_scheduled_items_tenant_keyset;
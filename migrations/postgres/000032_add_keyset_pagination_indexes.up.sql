@@ -0,0 +1,11 @@
+-- Cursor pagination seeks to the last row of the previous page in each list's order, so
+-- deep pages cost the same as the first; the ID breaks ties between equal timestamps
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_tenant_keyset
+ON scheduled_items (tenant_id, next_execution_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_todo_items_tenant_keyset
+ON todo_items (tenant_id, id);
+
+-- Execution logs are listed newest first, which the index serves by scanning backwards
+CREATE INDEX IF NOT EXISTS idx_execution_logs_tenant_keyset
+ON execution_logs (tenant_id, executed_at, id);
@@ -0,0 +1,3 @@
+-- Webhooks registered by an identified user are only sent the events about the scheduled
+-- items that user may see; webhooks without an owner are sent every event of their tenant
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS owner_id INTEGER;
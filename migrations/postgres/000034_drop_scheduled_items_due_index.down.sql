@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_scheduled_items_due
+ON scheduled_items (next_execution_at)
+WHERE NOT paused;
@@ -0,0 +1,3 @@
+-- ClaimDueItems now reads each tenant's due items through idx_scheduled_items_tenant_due,
+-- so nothing reads the due range across tenants any more
+DROP INDEX IF EXISTS idx_scheduled_items_due;
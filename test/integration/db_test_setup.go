@@ -110,7 +110,7 @@ func createAllTables(db *sql.DB) error {
 	// Get the path to migrations directory relative to the project root
 	// Go up from test/integration to project root
 	projectRoot := filepath.Join("..", "..")
-	migrationsPath := filepath.Join(projectRoot, "migrations")
+	migrationsPath := filepath.Join(projectRoot, "migrations", "postgres")
 
 	// Run migrations to create the database schema
 	// This replaces the old approach of using db_init.sql to ensure
@@ -223,7 +223,7 @@ func getEnvOrDefault(key, defaultValue string) string {
 }
 
 // skipIfDBNotAvailable skips the test if the database is not available
-func skipIfDBNotAvailable(t *testing.T) {
+func skipIfDBNotAvailable(t testing.TB) {
 	// If neither database is set up, try to set up based on environment
 	if TestDB == nil && TestContainerDB == nil {
 		if os.Getenv("USE_TESTCONTAINERS") == "true" {
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"periodic-api/internal/models"
 	"periodic-api/internal/store"
 	"testing"
@@ -33,13 +34,13 @@ func TestScheduledItemIntegration(t *testing.T) {
 
 	t.Run("Full CRUD Workflow", func(t *testing.T) {
 		// Create
-		created := scheduleStore.CreateScheduledItem(testItem)
+		created := scheduleStore.CreateScheduledItem(context.Background(), testItem)
 		if created.ID == 0 {
 			t.Fatal("Created item should have non-zero ID")
 		}
 
 		// Read
-		retrieved, found := scheduleStore.GetScheduledItem(created.ID)
+		retrieved, found := scheduleStore.GetScheduledItem(context.Background(), created.ID)
 		if !found {
 			t.Fatal("Should find the created item")
 		}
@@ -48,13 +49,13 @@ func TestScheduledItemIntegration(t *testing.T) {
 		}
 
 		// Delete
-		deleted := scheduleStore.DeleteScheduledItem(created.ID)
+		deleted := scheduleStore.DeleteScheduledItem(context.Background(), created.ID)
 		if !deleted {
 			t.Fatal("Delete should succeed")
 		}
 
 		// Verify deletion
-		_, found = scheduleStore.GetScheduledItem(created.ID)
+		_, found = scheduleStore.GetScheduledItem(context.Background(), created.ID)
 		if found {
 			t.Error("Item should not be found after deletion")
 		}
@@ -80,12 +81,12 @@ func TestScheduledItemIntegration(t *testing.T) {
 
 		var createdIDs []int64
 		for _, item := range items {
-			created := scheduleStore.CreateScheduledItem(item)
+			created := scheduleStore.CreateScheduledItem(context.Background(), item)
 			createdIDs = append(createdIDs, created.ID)
 		}
 
 		// Get all items
-		allItems := scheduleStore.GetAllScheduledItems()
+		allItems := scheduleStore.GetAllScheduledItems(context.Background())
 		if len(allItems) < 2 {
 			t.Errorf("Expected at least 2 items, got %d", len(allItems))
 		}
@@ -106,19 +107,19 @@ func TestScheduledItemIntegration(t *testing.T) {
 
 		// Clean up
 		for _, id := range createdIDs {
-			scheduleStore.DeleteScheduledItem(id)
+			scheduleStore.DeleteScheduledItem(context.Background(), id)
 		}
 	})
 
 	t.Run("Edge Cases", func(t *testing.T) {
 		// Test with non-existent ID
-		_, found := scheduleStore.GetScheduledItem(99999)
+		_, found := scheduleStore.GetScheduledItem(context.Background(), 99999)
 		if found {
 			t.Error("Should not find non-existent item")
 		}
 
 		// Test delete non-existent item
-		deleted := scheduleStore.DeleteScheduledItem(99999)
+		deleted := scheduleStore.DeleteScheduledItem(context.Background(), 99999)
 		if deleted {
 			t.Error("Delete of non-existent item should fail")
 		}
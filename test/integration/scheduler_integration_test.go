@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"log"
 	"testing"
 	"time"
@@ -54,17 +55,17 @@ func testProcessOneTimeItem(t *testing.T, itemStore store.ScheduledItemStore, to
 		NextExecutionAt: pastTime, // Due for execution
 	}
 
-	createdItem := itemStore.CreateScheduledItem(item)
+	createdItem := itemStore.CreateScheduledItem(context.Background(), item)
 	if createdItem.ID == 0 {
 		t.Fatal("Failed to create scheduled item")
 	}
 
 	// Count initial state
-	initialTodos := len(todoStore.GetAllTodoItems())
-	initialLogs := len(logStore.GetAllExecutionLogs())
+	initialTodos := len(todoStore.GetAllTodoItems(context.Background()))
+	initialLogs := len(logStore.GetAllExecutionLogs(context.Background()))
 
 	// Simulate scheduler processing
-	itemsDue, err := itemStore.GetNextScheduledItems(10, 0)
+	itemsDue, err := itemStore.GetNextScheduledItems(context.Background(), 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get items due: %v", err)
 	}
@@ -85,7 +86,7 @@ func testProcessOneTimeItem(t *testing.T, itemStore store.ScheduledItemStore, to
 		Checked: false,
 	}
 
-	createdTodo := todoStore.CreateTodoItem(todoItem)
+	createdTodo := todoStore.CreateTodoItem(context.Background(), todoItem)
 	if createdTodo.ID == 0 {
 		t.Fatal("Failed to create todo item")
 	}
@@ -97,26 +98,26 @@ func testProcessOneTimeItem(t *testing.T, itemStore store.ScheduledItemStore, to
 		Status:          "success",
 		TodoItemID:      &createdTodo.ID,
 	}
-	logStore.CreateExecutionLog(logExecution)
+	logStore.CreateExecutionLog(context.Background(), logExecution)
 
 	// For one-time item, delete it after processing
-	if !itemStore.DeleteScheduledItem(dueItem.ID) {
+	if !itemStore.DeleteScheduledItem(context.Background(), dueItem.ID) {
 		t.Error("Failed to delete completed one-time item")
 	}
 
 	// Verify results
-	finalTodos := todoStore.GetAllTodoItems()
+	finalTodos := todoStore.GetAllTodoItems(context.Background())
 	if len(finalTodos) != initialTodos+1 {
 		t.Errorf("Expected %d todos, got %d", initialTodos+1, len(finalTodos))
 	}
 
-	finalLogs := logStore.GetAllExecutionLogs()
+	finalLogs := logStore.GetAllExecutionLogs(context.Background())
 	if len(finalLogs) != initialLogs+1 {
 		t.Errorf("Expected %d logs, got %d", initialLogs+1, len(finalLogs))
 	}
 
 	// Verify the scheduled item was deleted
-	_, exists := itemStore.GetScheduledItem(createdItem.ID)
+	_, exists := itemStore.GetScheduledItem(context.Background(), createdItem.ID)
 	if exists {
 		t.Error("One-time item should have been deleted after processing")
 	}
@@ -143,17 +144,17 @@ func testProcessRepeatingItem(t *testing.T, itemStore store.ScheduledItemStore,
 		NextExecutionAt: pastTime, // Due for execution
 	}
 
-	createdItem := itemStore.CreateScheduledItem(item)
+	createdItem := itemStore.CreateScheduledItem(context.Background(), item)
 	if createdItem.ID == 0 {
 		t.Fatal("Failed to create scheduled item")
 	}
 
 	// Count initial state
-	initialTodos := len(todoStore.GetAllTodoItems())
-	initialLogs := len(logStore.GetAllExecutionLogs())
+	initialTodos := len(todoStore.GetAllTodoItems(context.Background()))
+	initialLogs := len(logStore.GetAllExecutionLogs(context.Background()))
 
 	// Get items due for execution
-	itemsDue, err := itemStore.GetNextScheduledItems(10, 0)
+	itemsDue, err := itemStore.GetNextScheduledItems(context.Background(), 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get items due: %v", err)
 	}
@@ -181,7 +182,7 @@ func testProcessRepeatingItem(t *testing.T, itemStore store.ScheduledItemStore,
 		Checked: false,
 	}
 
-	createdTodo := todoStore.CreateTodoItem(todoItem)
+	createdTodo := todoStore.CreateTodoItem(context.Background(), todoItem)
 	if createdTodo.ID == 0 {
 		t.Fatal("Failed to create todo item")
 	}
@@ -193,10 +194,10 @@ func testProcessRepeatingItem(t *testing.T, itemStore store.ScheduledItemStore,
 		Status:          "success",
 		TodoItemID:      &createdTodo.ID,
 	}
-	logStore.CreateExecutionLog(logExecution)
+	logStore.CreateExecutionLog(context.Background(), logExecution)
 
 	// For repeating item, update next execution time using the same logic as the scheduler
-	nextExec := utils.CalculateNextExecution(dueItem.NextExecutionAt, dueItem.Repeats, dueItem.CronExpression, dueItem.Expiration)
+	nextExec := utils.CalculateNextExecution(dueItem.NextExecutionAt, dueItem.Repeats, dueItem.CronExpression, dueItem.Expiration, dueItem.JitterSeconds)
 	if nextExec == nil {
 		t.Error("Failed to calculate next execution time")
 		return
@@ -204,23 +205,23 @@ func testProcessRepeatingItem(t *testing.T, itemStore store.ScheduledItemStore,
 	log.Printf("dueItem: %v\n", *dueItem)
 	log.Printf("nextExec: %s\n", *nextExec)
 
-	if !itemStore.UpdateNextExecutionAt(dueItem.ID, *nextExec) {
+	if !itemStore.UpdateNextExecutionAt(context.Background(), dueItem.ID, *nextExec) {
 		t.Error("Failed to update next execution time")
 	}
 
 	// Verify results
-	finalTodos := todoStore.GetAllTodoItems()
+	finalTodos := todoStore.GetAllTodoItems(context.Background())
 	if len(finalTodos) != initialTodos+1 {
 		t.Errorf("Expected %d todos, got %d", initialTodos+1, len(finalTodos))
 	}
 
-	finalLogs := logStore.GetAllExecutionLogs()
+	finalLogs := logStore.GetAllExecutionLogs(context.Background())
 	if len(finalLogs) != initialLogs+1 {
 		t.Errorf("Expected %d logs, got %d", initialLogs+1, len(finalLogs))
 	}
 
 	// Verify the scheduled item still exists with updated next execution
-	updatedItem, exists := itemStore.GetScheduledItem(createdItem.ID)
+	updatedItem, exists := itemStore.GetScheduledItem(context.Background(), createdItem.ID)
 	if !exists {
 		t.Error("Repeating item should still exist after processing")
 	}
@@ -251,13 +252,13 @@ func testProcessExpiredItem(t *testing.T, itemStore store.ScheduledItemStore, to
 		NextExecutionAt: pastTime, // Would be due, but item is expired
 	}
 
-	createdItem := itemStore.CreateScheduledItem(item)
+	createdItem := itemStore.CreateScheduledItem(context.Background(), item)
 	if createdItem.ID == 0 {
 		t.Fatal("Failed to create scheduled item")
 	}
 
 	// Get items due for execution - expired item should NOT be included
-	itemsDue, err := itemStore.GetNextScheduledItems(10, 0)
+	itemsDue, err := itemStore.GetNextScheduledItems(context.Background(), 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get items due: %v", err)
 	}
@@ -270,7 +271,7 @@ func testProcessExpiredItem(t *testing.T, itemStore store.ScheduledItemStore, to
 	}
 
 	// Clean up
-	itemStore.DeleteScheduledItem(createdItem.ID)
+	itemStore.DeleteScheduledItem(context.Background(), createdItem.ID)
 }
 
 // testProcessMultipleItems tests processing multiple items in one run
@@ -305,7 +306,7 @@ func testProcessMultipleItems(t *testing.T, itemStore store.ScheduledItemStore,
 
 	var createdIDs []int64
 	for _, item := range items {
-		created := itemStore.CreateScheduledItem(item)
+		created := itemStore.CreateScheduledItem(context.Background(), item)
 		if created.ID == 0 {
 			t.Fatal("Failed to create scheduled item")
 		}
@@ -313,11 +314,11 @@ func testProcessMultipleItems(t *testing.T, itemStore store.ScheduledItemStore,
 	}
 
 	// Count initial state
-	initialTodos := len(todoStore.GetAllTodoItems())
-	initialLogs := len(logStore.GetAllExecutionLogs())
+	initialTodos := len(todoStore.GetAllTodoItems(context.Background()))
+	initialLogs := len(logStore.GetAllExecutionLogs(context.Background()))
 
 	// Get all items due for execution
-	itemsDue, err := itemStore.GetNextScheduledItems(10, 0)
+	itemsDue, err := itemStore.GetNextScheduledItems(context.Background(), 10, 0)
 	if err != nil {
 		t.Fatalf("Failed to get items due: %v", err)
 	}
@@ -350,7 +351,7 @@ func testProcessMultipleItems(t *testing.T, itemStore store.ScheduledItemStore,
 			Checked: false,
 		}
 
-		createdTodo := todoStore.CreateTodoItem(todoItem)
+		createdTodo := todoStore.CreateTodoItem(context.Background(), todoItem)
 		if createdTodo.ID == 0 {
 			t.Errorf("Failed to create todo item for scheduled item %d", dueItem.ID)
 			continue
@@ -363,10 +364,10 @@ func testProcessMultipleItems(t *testing.T, itemStore store.ScheduledItemStore,
 			Status:          "success",
 			TodoItemID:      &createdTodo.ID,
 		}
-		logStore.CreateExecutionLog(logExecution)
+		logStore.CreateExecutionLog(context.Background(), logExecution)
 
 		// Delete one-time item
-		itemStore.DeleteScheduledItem(dueItem.ID)
+		itemStore.DeleteScheduledItem(context.Background(), dueItem.ID)
 		processedCount++
 	}
 
@@ -375,8 +376,8 @@ func testProcessMultipleItems(t *testing.T, itemStore store.ScheduledItemStore,
 	}
 
 	// Verify results
-	finalTodos := todoStore.GetAllTodoItems()
-	finalLogs := logStore.GetAllExecutionLogs()
+	finalTodos := todoStore.GetAllTodoItems(context.Background())
+	finalLogs := logStore.GetAllExecutionLogs(context.Background())
 
 	if len(finalTodos) < initialTodos+3 {
 		t.Errorf("Expected at least %d todos, got %d", initialTodos+3, len(finalTodos))
@@ -20,7 +20,7 @@ func TestCronCalculationDebug(t *testing.T) {
 	t.Logf("Expiration: %v", futureExpiration)
 
 	// Test what CalculateNextExecution returns
-	nextExec := utils.CalculateNextExecution(pastTime, true, &cronExpr, &futureExpiration)
+	nextExec := utils.CalculateNextExecution(pastTime, true, &cronExpr, &futureExpiration, 0)
 	
 	if nextExec == nil {
 		t.Error("CalculateNextExecution returned nil - this might be the issue!")
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"periodic-api/internal/models"
+	"periodic-api/internal/store"
+)
+
+// BenchmarkConcurrentTodoItemStore measures a mix of reads and updates through the
+// PostgreSQL todo item store from many goroutines at once. The "locked" variant wraps each
+// call in a process-wide RWMutex, as the PostgreSQL stores used to, to show what
+// serializing the writes costs compared with relying on database/sql's connection pool:
+//
+//	go test ./test/integration -run '^$' -bench ConcurrentTodoItemStore -cpu 1,8,32
+func BenchmarkConcurrentTodoItemStore(b *testing.B) {
+	skipIfDBNotAvailable(b)
+	cleanupTodoItems(b)
+	b.Cleanup(func() { cleanupTodoItems(b) })
+
+	todoStore := store.NewPostgresTodoItemStore(getActiveDB())
+	ctx := context.Background()
+
+	var items []models.TodoItem
+	for i := range 100 {
+		created := todoStore.CreateTodoItem(ctx, models.TodoItem{Text: fmt.Sprintf("Benchmark todo %d", i)})
+		if created.ID == 0 {
+			b.Fatal("Failed to create todo item")
+		}
+		items = append(items, created)
+	}
+
+	// One operation in four is a write
+	operation := func(i int, mu *sync.RWMutex) {
+		item := items[i%len(items)]
+		if i%4 == 0 {
+			if mu != nil {
+				mu.Lock()
+				defer mu.Unlock()
+			}
+			item.Checked = !item.Checked
+			if _, updated := todoStore.UpdateTodoItem(ctx, item.ID, item); !updated {
+				b.Errorf("Failed to update todo item ID=%d", item.ID)
+			}
+			return
+		}
+
+		if mu != nil {
+			mu.RLock()
+			defer mu.RUnlock()
+		}
+		if _, exists := todoStore.GetTodoItem(ctx, item.ID); !exists {
+			b.Errorf("Failed to get todo item ID=%d", item.ID)
+		}
+	}
+
+	b.Run("concurrent", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				operation(i, nil)
+			}
+		})
+	})
+
+	b.Run("locked", func(b *testing.B) {
+		var mu sync.RWMutex
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				operation(i, &mu)
+			}
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"periodic-api/internal/models"
 	"periodic-api/internal/store"
 	"testing"
@@ -24,7 +25,7 @@ func TestTodoItemIntegration(t *testing.T) {
 
 	t.Run("Full CRUD Workflow", func(t *testing.T) {
 		// Create
-		created := todoStore.CreateTodoItem(testItem)
+		created := todoStore.CreateTodoItem(context.Background(), testItem)
 		if created.ID == 0 {
 			t.Fatal("Created item should have non-zero ID")
 		}
@@ -34,9 +35,12 @@ func TestTodoItemIntegration(t *testing.T) {
 		if created.Checked != testItem.Checked {
 			t.Errorf("Expected checked %v, got %v", testItem.Checked, created.Checked)
 		}
+		if created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+			t.Errorf("Expected matching creation and update times, got %v and %v", created.CreatedAt, created.UpdatedAt)
+		}
 
 		// Read
-		retrieved, found := todoStore.GetTodoItem(created.ID)
+		retrieved, found := todoStore.GetTodoItem(context.Background(), created.ID)
 		if !found {
 			t.Fatal("Should find the created item")
 		}
@@ -53,7 +57,7 @@ func TestTodoItemIntegration(t *testing.T) {
 			Checked: true,
 		}
 
-		result, success := todoStore.UpdateTodoItem(created.ID, updated)
+		result, success := todoStore.UpdateTodoItem(context.Background(), created.ID, updated)
 		if !success {
 			t.Fatal("Update should succeed")
 		}
@@ -63,9 +67,15 @@ func TestTodoItemIntegration(t *testing.T) {
 		if result.Checked != updated.Checked {
 			t.Errorf("Expected updated checked %v, got %v", updated.Checked, result.Checked)
 		}
+		if !result.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("Update should keep creation time %v, got %v", created.CreatedAt, result.CreatedAt)
+		}
+		if result.UpdatedAt.Before(created.UpdatedAt) {
+			t.Errorf("Update time %v should not be before %v", result.UpdatedAt, created.UpdatedAt)
+		}
 
 		// Verify update persisted
-		verified, found := todoStore.GetTodoItem(created.ID)
+		verified, found := todoStore.GetTodoItem(context.Background(), created.ID)
 		if !found {
 			t.Fatal("Should still find the item after update")
 		}
@@ -77,13 +87,13 @@ func TestTodoItemIntegration(t *testing.T) {
 		}
 
 		// Delete
-		deleted := todoStore.DeleteTodoItem(created.ID)
+		deleted := todoStore.DeleteTodoItem(context.Background(), created.ID)
 		if !deleted {
 			t.Fatal("Delete should succeed")
 		}
 
 		// Verify deletion
-		_, found = todoStore.GetTodoItem(created.ID)
+		_, found = todoStore.GetTodoItem(context.Background(), created.ID)
 		if found {
 			t.Error("Item should not be found after deletion")
 		}
@@ -99,12 +109,12 @@ func TestTodoItemIntegration(t *testing.T) {
 
 		var createdIDs []int64
 		for _, item := range items {
-			created := todoStore.CreateTodoItem(item)
+			created := todoStore.CreateTodoItem(context.Background(), item)
 			createdIDs = append(createdIDs, created.ID)
 		}
 
 		// Get all items
-		allItems := todoStore.GetAllTodoItems()
+		allItems := todoStore.GetAllTodoItems(context.Background())
 		if len(allItems) < 3 {
 			t.Errorf("Expected at least 3 items, got %d", len(allItems))
 		}
@@ -152,7 +162,7 @@ func TestTodoItemIntegration(t *testing.T) {
 
 		// Clean up
 		for _, id := range createdIDs {
-			todoStore.DeleteTodoItem(id)
+			todoStore.DeleteTodoItem(context.Background(), id)
 		}
 	})
 
@@ -162,14 +172,14 @@ func TestTodoItemIntegration(t *testing.T) {
 			Text:    "Toggle Test Item",
 			Checked: false,
 		}
-		created := todoStore.CreateTodoItem(item)
+		created := todoStore.CreateTodoItem(context.Background(), item)
 
 		// Toggle to checked
 		updated := models.TodoItem{
 			Text:    created.Text,
 			Checked: true,
 		}
-		result, success := todoStore.UpdateTodoItem(created.ID, updated)
+		result, success := todoStore.UpdateTodoItem(context.Background(), created.ID, updated)
 		if !success {
 			t.Fatal("Should be able to update checked status")
 		}
@@ -179,7 +189,7 @@ func TestTodoItemIntegration(t *testing.T) {
 
 		// Toggle back to unchecked
 		updated.Checked = false
-		result, success = todoStore.UpdateTodoItem(created.ID, updated)
+		result, success = todoStore.UpdateTodoItem(context.Background(), created.ID, updated)
 		if !success {
 			t.Fatal("Should be able to update checked status again")
 		}
@@ -188,24 +198,24 @@ func TestTodoItemIntegration(t *testing.T) {
 		}
 
 		// Clean up
-		todoStore.DeleteTodoItem(created.ID)
+		todoStore.DeleteTodoItem(context.Background(), created.ID)
 	})
 
 	t.Run("Edge Cases", func(t *testing.T) {
 		// Test with non-existent ID
-		_, found := todoStore.GetTodoItem(99999)
+		_, found := todoStore.GetTodoItem(context.Background(), 99999)
 		if found {
 			t.Error("Should not find non-existent item")
 		}
 
 		// Test update non-existent item
-		_, success := todoStore.UpdateTodoItem(99999, testItem)
+		_, success := todoStore.UpdateTodoItem(context.Background(), 99999, testItem)
 		if success {
 			t.Error("Update of non-existent item should fail")
 		}
 
 		// Test delete non-existent item
-		deleted := todoStore.DeleteTodoItem(99999)
+		deleted := todoStore.DeleteTodoItem(context.Background(), 99999)
 		if deleted {
 			t.Error("Delete of non-existent item should fail")
 		}
@@ -215,15 +225,15 @@ func TestTodoItemIntegration(t *testing.T) {
 			Text:    "",
 			Checked: false,
 		}
-		created := todoStore.CreateTodoItem(emptyItem)
+		created := todoStore.CreateTodoItem(context.Background(), emptyItem)
 		if created.ID == 0 {
 			t.Error("Should be able to create item with empty text")
 		}
-		todoStore.DeleteTodoItem(created.ID)
+		todoStore.DeleteTodoItem(context.Background(), created.ID)
 	})
 }
 
-func cleanupTodoItems(t *testing.T) {
+func cleanupTodoItems(t testing.TB) {
 	_, err := getActiveDB().Exec("DELETE FROM todo_items")
 	if err != nil {
 		t.Logf("Failed to cleanup todo_items: %v", err)
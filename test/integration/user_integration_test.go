@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"periodic-api/internal/models"
 	"periodic-api/internal/store"
 	"bytes"
@@ -25,7 +26,7 @@ func TestUserIntegration(t *testing.T) {
 
 	t.Run("Full CRUD Workflow", func(t *testing.T) {
 		// Create
-		created := userStore.CreateUser(testUser)
+		created := userStore.CreateUser(context.Background(), testUser)
 		if created.ID == 0 {
 			t.Fatal("Created user should have non-zero ID")
 		}
@@ -37,7 +38,7 @@ func TestUserIntegration(t *testing.T) {
 		}
 
 		// Read
-		retrieved, found := userStore.GetUser(created.ID)
+		retrieved, found := userStore.GetUser(context.Background(), created.ID)
 		if !found {
 			t.Fatal("Should find the created user")
 		}
@@ -54,7 +55,7 @@ func TestUserIntegration(t *testing.T) {
 			PasswordHash: []byte("new_hashed_password_456"),
 		}
 
-		result, success := userStore.UpdateUser(created.ID, updated)
+		result, success := userStore.UpdateUser(context.Background(), created.ID, updated)
 		if !success {
 			t.Fatal("Update should succeed")
 		}
@@ -66,7 +67,7 @@ func TestUserIntegration(t *testing.T) {
 		}
 
 		// Verify update persisted
-		verified, found := userStore.GetUser(created.ID)
+		verified, found := userStore.GetUser(context.Background(), created.ID)
 		if !found {
 			t.Fatal("Should still find the user after update")
 		}
@@ -78,13 +79,13 @@ func TestUserIntegration(t *testing.T) {
 		}
 
 		// Delete
-		deleted := userStore.DeleteUser(created.ID)
+		deleted := userStore.DeleteUser(context.Background(), created.ID)
 		if !deleted {
 			t.Fatal("Delete should succeed")
 		}
 
 		// Verify deletion
-		_, found = userStore.GetUser(created.ID)
+		_, found = userStore.GetUser(context.Background(), created.ID)
 		if found {
 			t.Error("User should not be found after deletion")
 		}
@@ -100,12 +101,12 @@ func TestUserIntegration(t *testing.T) {
 
 		var createdIDs []int64
 		for _, user := range users {
-			created := userStore.CreateUser(user)
+			created := userStore.CreateUser(context.Background(), user)
 			createdIDs = append(createdIDs, created.ID)
 		}
 
 		// Get all users
-		allUsers := userStore.GetAllUsers()
+		allUsers := userStore.GetAllUsers(context.Background())
 		if len(allUsers) < 3 {
 			t.Errorf("Expected at least 3 users, got %d", len(allUsers))
 		}
@@ -136,7 +137,7 @@ func TestUserIntegration(t *testing.T) {
 
 		// Clean up
 		for _, id := range createdIDs {
-			userStore.DeleteUser(id)
+			userStore.DeleteUser(context.Background(), id)
 		}
 	})
 
@@ -146,14 +147,14 @@ func TestUserIntegration(t *testing.T) {
 			Username:     "unique_test_user",
 			PasswordHash: []byte("password1"),
 		}
-		created1 := userStore.CreateUser(user1)
+		created1 := userStore.CreateUser(context.Background(), user1)
 
 		// Try to create user with same username
 		user2 := models.User{
 			Username:     "unique_test_user",
 			PasswordHash: []byte("password2"),
 		}
-		created2 := userStore.CreateUser(user2)
+		created2 := userStore.CreateUser(context.Background(), user2)
 
 		// Both should be created (no unique constraint in current schema)
 		// But they should have different IDs
@@ -162,8 +163,8 @@ func TestUserIntegration(t *testing.T) {
 		}
 
 		// Clean up
-		userStore.DeleteUser(created1.ID)
-		userStore.DeleteUser(created2.ID)
+		userStore.DeleteUser(context.Background(), created1.ID)
+		userStore.DeleteUser(context.Background(), created2.ID)
 	})
 
 	t.Run("Password Hash Handling", func(t *testing.T) {
@@ -185,7 +186,7 @@ func TestUserIntegration(t *testing.T) {
 					Username:     "hash_test_" + tc.name,
 					PasswordHash: tc.hash,
 				}
-				created := userStore.CreateUser(user)
+				created := userStore.CreateUser(context.Background(), user)
 				createdIDs = append(createdIDs, created.ID)
 
 				if !bytes.Equal(created.PasswordHash, tc.hash) {
@@ -193,7 +194,7 @@ func TestUserIntegration(t *testing.T) {
 				}
 
 				// Verify retrieval
-				retrieved, found := userStore.GetUser(created.ID)
+				retrieved, found := userStore.GetUser(context.Background(), created.ID)
 				if !found {
 					t.Error("Should find created user")
 				}
@@ -205,25 +206,25 @@ func TestUserIntegration(t *testing.T) {
 
 		// Clean up
 		for _, id := range createdIDs {
-			userStore.DeleteUser(id)
+			userStore.DeleteUser(context.Background(), id)
 		}
 	})
 
 	t.Run("Edge Cases", func(t *testing.T) {
 		// Test with non-existent ID
-		_, found := userStore.GetUser(99999)
+		_, found := userStore.GetUser(context.Background(), 99999)
 		if found {
 			t.Error("Should not find non-existent user")
 		}
 
 		// Test update non-existent user
-		_, success := userStore.UpdateUser(99999, testUser)
+		_, success := userStore.UpdateUser(context.Background(), 99999, testUser)
 		if success {
 			t.Error("Update of non-existent user should fail")
 		}
 
 		// Test delete non-existent user
-		deleted := userStore.DeleteUser(99999)
+		deleted := userStore.DeleteUser(context.Background(), 99999)
 		if deleted {
 			t.Error("Delete of non-existent user should fail")
 		}
@@ -233,11 +234,11 @@ func TestUserIntegration(t *testing.T) {
 			Username:     "",
 			PasswordHash: []byte("some_hash"),
 		}
-		created := userStore.CreateUser(emptyUser)
+		created := userStore.CreateUser(context.Background(), emptyUser)
 		if created.ID == 0 {
 			t.Error("Should be able to create user with empty username")
 		}
-		userStore.DeleteUser(created.ID)
+		userStore.DeleteUser(context.Background(), created.ID)
 	})
 }
 